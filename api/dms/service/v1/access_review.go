@@ -0,0 +1,177 @@
+package v1
+
+import (
+	"time"
+
+	base "github.com/actiontech/dms/pkg/dms-common/api/base/v1"
+)
+
+// swagger:enum AccessReviewCampaignStatus
+type AccessReviewCampaignStatus string
+
+const (
+	AccessReviewCampaignStatusOpen      AccessReviewCampaignStatus = "open"
+	AccessReviewCampaignStatusCompleted AccessReviewCampaignStatus = "completed"
+)
+
+// swagger:enum AccessReviewDecision
+type AccessReviewDecision string
+
+const (
+	AccessReviewDecisionPending AccessReviewDecision = "pending"
+	AccessReviewDecisionApprove AccessReviewDecision = "approve"
+	AccessReviewDecisionRevoke  AccessReviewDecision = "revoke"
+	// pending access revoked automatically at the deadline
+	AccessReviewDecisionAutoRevoke AccessReviewDecision = "auto_revoke"
+)
+
+// swagger:enum AccessReviewSubjectType
+type AccessReviewSubjectType string
+
+const (
+	AccessReviewSubjectTypeMember      AccessReviewSubjectType = "member"
+	AccessReviewSubjectTypeMemberGroup AccessReviewSubjectType = "member_group"
+)
+
+// swagger:enum AccessReviewEvidenceFormat
+type AccessReviewEvidenceFormat string
+
+const (
+	AccessReviewEvidenceFormatCSV  AccessReviewEvidenceFormat = "csv"
+	AccessReviewEvidenceFormatJSON AccessReviewEvidenceFormat = "json"
+)
+
+type AddAccessReviewCampaign struct {
+	// campaign name
+	// Required: true
+	Name string `json:"name" validate:"required"`
+	// projects to review
+	// Required: true
+	ProjectUids []string `json:"project_uids" validate:"required"`
+	// only review access granted by these roles, empty means all access in the projects
+	RoleUids []string `json:"role_uids"`
+	// review deadline
+	// Required: true
+	Deadline time.Time `json:"deadline" validate:"required"`
+	// revoke access that is still pending at the deadline
+	AutoRevoke bool `json:"auto_revoke"`
+}
+
+// swagger:model
+type AddAccessReviewCampaignReq struct {
+	Campaign *AddAccessReviewCampaign `json:"campaign" validate:"required"`
+}
+
+// swagger:model AddAccessReviewCampaignReply
+type AddAccessReviewCampaignReply struct {
+	Data struct {
+		// campaign UID
+		Uid string `json:"uid"`
+	} `json:"data"`
+
+	// Generic reply
+	base.GenericResp
+}
+
+type AccessReviewCampaign struct {
+	Uid         string                     `json:"uid"`
+	Name        string                     `json:"name"`
+	Projects    []UidWithName              `json:"projects"`
+	Roles       []UidWithName              `json:"roles,omitempty"`
+	Deadline    time.Time                  `json:"deadline"`
+	AutoRevoke  bool                       `json:"auto_revoke"`
+	Status      AccessReviewCampaignStatus `json:"status"`
+	CreateUser  UidWithName                `json:"create_user"`
+	CreatedAt   time.Time                  `json:"created_at"`
+	CompletedAt *time.Time                 `json:"completed_at,omitempty"`
+}
+
+// swagger:model ListAccessReviewCampaignsReply
+type ListAccessReviewCampaignsReply struct {
+	Data  []*AccessReviewCampaign `json:"data"`
+	Total int64                   `json:"total_nums"`
+
+	// Generic reply
+	base.GenericResp
+}
+
+// swagger:parameters GetAccessReviewCampaign
+type GetAccessReviewCampaignReq struct {
+	// campaign uid
+	// Required: true
+	// in:path
+	CampaignUid string `param:"campaign_uid" json:"campaign_uid" validate:"required"`
+}
+
+type AccessReviewItem struct {
+	Uid         string                  `json:"uid"`
+	Project     UidWithName             `json:"project"`
+	SubjectType AccessReviewSubjectType `json:"subject_type"`
+	// the member, or the member group the user belongs to
+	Subject          UidWithName                 `json:"subject"`
+	User             UidWithName                 `json:"user"`
+	RoleWithOpRanges []ListMemberRoleWithOpRange `json:"role_with_op_ranges"`
+	// last activity of the user when the campaign was opened
+	LastActiveAt *time.Time           `json:"last_active_at,omitempty"`
+	Decision     AccessReviewDecision `json:"decision"`
+	Reviewer     *UidWithName         `json:"reviewer,omitempty"`
+	Comment      string               `json:"comment"`
+	ReviewedAt   *time.Time           `json:"reviewed_at,omitempty"`
+}
+
+// swagger:model GetAccessReviewCampaignReply
+type GetAccessReviewCampaignReply struct {
+	Data struct {
+		Campaign *AccessReviewCampaign `json:"campaign"`
+		// items in the projects the current user can review
+		Items []*AccessReviewItem `json:"items"`
+	} `json:"data"`
+
+	// Generic reply
+	base.GenericResp
+}
+
+// swagger:model
+type ReviewAccessReviewItemReq struct {
+	// swagger:ignore
+	CampaignUid string `param:"campaign_uid" json:"campaign_uid" validate:"required"`
+	// swagger:ignore
+	ItemUid string `param:"item_uid" json:"item_uid" validate:"required"`
+	// approve or revoke
+	// Required: true
+	Decision AccessReviewDecision `json:"decision" validate:"required,oneof=approve revoke"`
+	Comment  string               `json:"comment"`
+}
+
+// swagger:parameters ExportAccessReviewEvidence
+type ExportAccessReviewEvidenceReq struct {
+	// campaign uid
+	// Required: true
+	// in:path
+	CampaignUid string `param:"campaign_uid" json:"campaign_uid" validate:"required"`
+	// evidence format, csv by default
+	// in:query
+	Format AccessReviewEvidenceFormat `query:"format" json:"format" validate:"omitempty,oneof=csv json"`
+}
+
+type AccessReviewEvidenceSummary struct {
+	Total       int `json:"total"`
+	Approved    int `json:"approved"`
+	Revoked     int `json:"revoked"`
+	AutoRevoked int `json:"auto_revoked"`
+}
+
+// AccessReviewEvidence is the json evidence of a completed campaign, ready to be rendered as pdf
+type AccessReviewEvidence struct {
+	Campaign    *AccessReviewCampaign       `json:"campaign"`
+	Summary     AccessReviewEvidenceSummary `json:"summary"`
+	Items       []*AccessReviewItem         `json:"items"`
+	GeneratedAt time.Time                   `json:"generated_at"`
+}
+
+// swagger:response ExportAccessReviewEvidenceReply
+type ExportAccessReviewEvidenceReply struct {
+	// swagger:file
+	// in:  body
+	File []byte
+}
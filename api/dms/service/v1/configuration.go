@@ -14,6 +14,46 @@ type LoginTipsResData struct {
 	LoginButtonText      string `json:"login_button_text"`
 	DisableUserPwdLogin  bool   `json:"disable_user_pwd_login"`
 	DisableMultipleLogin bool   `json:"disable_multiple_login"`
+	// password policy, used by the front end to prompt password requirements
+	PasswordPolicy PasswordPolicy `json:"password_policy"`
+	// account lockout policy
+	LockoutPolicy LockoutPolicy `json:"lockout_policy"`
+	// login session timeout policy
+	SessionPolicy SessionPolicy `json:"session_policy"`
+}
+
+type PasswordPolicy struct {
+	// minimum password length, 0 means no limit
+	MinLength        uint `json:"min_length"`
+	RequireUppercase bool `json:"require_uppercase"`
+	RequireLowercase bool `json:"require_lowercase"`
+	RequireDigit     bool `json:"require_digit"`
+	RequireSpecial   bool `json:"require_special"`
+	// the new password must not be the same as the last N passwords, 0 means no limit
+	HistoryCount uint `json:"history_count"`
+	// password max age in days, the user must change the password at next login after it expires, 0 means never expire
+	MaxAgeDays uint `json:"max_age_days"`
+}
+
+type LockoutPolicy struct {
+	// lock the account after N failed login attempts within the window, 0 means never lock
+	MaxFailedAttempts uint `json:"max_failed_attempts"`
+	WindowMinutes     uint `json:"window_minutes"`
+	DurationMinutes   uint `json:"duration_minutes"`
+}
+
+type SessionPolicy struct {
+	// the session expires after N minutes without any request, 0 means never
+	IdleTimeoutMinutes uint `json:"idle_timeout_minutes"`
+	// the user must login again N minutes after login, 0 means no limit
+	MaxAgeMinutes uint `json:"max_age_minutes"`
+	// policies for admin and users with global management permission, 0 means the same as other users
+	AdminIdleTimeoutMinutes uint `json:"admin_idle_timeout_minutes"`
+	AdminMaxAgeMinutes      uint `json:"admin_max_age_minutes"`
+	// the access token expires after N days without use, 0 means never
+	AccessTokenIdleTimeoutDays uint `json:"access_token_idle_timeout_days"`
+	// max expiration days allowed when generating an access token, 0 means no limit
+	AccessTokenMaxAgeDays uint `json:"access_token_max_age_days"`
 }
 
 // swagger:model
@@ -22,9 +62,26 @@ type UpdateLoginConfigurationReq struct {
 }
 
 type LoginConfiguration struct {
-	LoginButtonText      *string `json:"login_button_text"`
-	DisableUserPwdLogin  *bool   `json:"disable_user_pwd_login"`
-	DisableMultipleLogin *bool   `json:"disable_multiple_login"`
+	LoginButtonText          *string `json:"login_button_text"`
+	DisableUserPwdLogin      *bool   `json:"disable_user_pwd_login"`
+	DisableMultipleLogin     *bool   `json:"disable_multiple_login"`
+	PasswordMinLength        *uint   `json:"password_min_length" validate:"omitempty,max=128"`
+	PasswordRequireUppercase *bool   `json:"password_require_uppercase"`
+	PasswordRequireLowercase *bool   `json:"password_require_lowercase"`
+	PasswordRequireDigit     *bool   `json:"password_require_digit"`
+	PasswordRequireSpecial   *bool   `json:"password_require_special"`
+	PasswordHistoryCount     *uint   `json:"password_history_count" validate:"omitempty,max=24"`
+	PasswordMaxAgeDays       *uint   `json:"password_max_age_days"`
+	LockoutMaxFailedAttempts *uint   `json:"lockout_max_failed_attempts"`
+	LockoutWindowMinutes     *uint   `json:"lockout_window_minutes" validate:"omitempty,min=1"`
+	LockoutDurationMinutes   *uint   `json:"lockout_duration_minutes" validate:"omitempty,min=1"`
+
+	SessionIdleTimeoutMinutes      *uint `json:"session_idle_timeout_minutes"`
+	SessionMaxAgeMinutes           *uint `json:"session_max_age_minutes"`
+	AdminSessionIdleTimeoutMinutes *uint `json:"admin_session_idle_timeout_minutes"`
+	AdminSessionMaxAgeMinutes      *uint `json:"admin_session_max_age_minutes"`
+	AccessTokenIdleTimeoutDays     *uint `json:"access_token_idle_timeout_days"`
+	AccessTokenMaxAgeDays          *uint `json:"access_token_max_age_days"`
 }
 
 type GetOauth2ConfigurationResData struct {
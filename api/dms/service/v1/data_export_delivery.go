@@ -0,0 +1,103 @@
+package v1
+
+import (
+	"time"
+
+	base "github.com/actiontech/dms/pkg/dms-common/api/base/v1"
+)
+
+// swagger:enum DataExportDeliveryTargetType
+type DataExportDeliveryTargetType string
+
+const (
+	DataExportDeliveryTargetTypeS3    DataExportDeliveryTargetType = "s3"
+	DataExportDeliveryTargetTypeSFTP  DataExportDeliveryTargetType = "sftp"
+	DataExportDeliveryTargetTypeLocal DataExportDeliveryTargetType = "local"
+)
+
+type DataExportDeliveryTarget struct {
+	// Required: true
+	Name string `json:"name" validate:"required,max=200"`
+	// Required: true
+	Type DataExportDeliveryTargetType `json:"type" validate:"required,oneof=s3 sftp local"`
+	// key prefix for s3, remote directory for sftp, absolute directory (e.g. nfs mount point) for local
+	Path string `json:"path"`
+	// s3 endpoint, e.g. http://minio:9000
+	Endpoint string `json:"endpoint"`
+	// s3 region, default us-east-1
+	Region string `json:"region"`
+	Bucket string `json:"bucket"`
+	// s3 access key id
+	AccessKeyID string `json:"access_key_id"`
+	// s3 secret access key, write only; empty on update keeps the current value
+	SecretAccessKey string `json:"secret_access_key"`
+	// use path style addressing, required by minio
+	PathStyle bool `json:"path_style"`
+	// sftp host
+	Host string `json:"host"`
+	// sftp port, default 22
+	Port int `json:"port" validate:"min=0,max=65535"`
+	// sftp username
+	Username string `json:"username"`
+	// sftp private key in openssh format, write only; empty on update keeps the current value
+	PrivateKey string `json:"private_key"`
+	// sftp server public key in known_hosts format, required for sftp
+	HostPublicKey string `json:"host_public_key"`
+}
+
+// swagger:model
+type AddDataExportDeliveryTargetReq struct {
+	DeliveryTarget *DataExportDeliveryTarget `json:"delivery_target" validate:"required"`
+}
+
+// swagger:model AddDataExportDeliveryTargetReply
+type AddDataExportDeliveryTargetReply struct {
+	// delivery target uid
+	Data struct {
+		Uid string `json:"uid"`
+	} `json:"data"`
+
+	// Generic reply
+	base.GenericResp
+}
+
+// swagger:model
+type UpdateDataExportDeliveryTargetReq struct {
+	// swagger:ignore
+	DeliveryTargetUid string                    `param:"delivery_target_uid" json:"delivery_target_uid" validate:"required"`
+	DeliveryTarget    *DataExportDeliveryTarget `json:"delivery_target" validate:"required"`
+}
+
+// swagger:parameters DeleteDataExportDeliveryTarget
+type DeleteDataExportDeliveryTargetReq struct {
+	// in:path
+	// Required: true
+	DeliveryTargetUid string `param:"delivery_target_uid" json:"delivery_target_uid" validate:"required"`
+}
+
+// secrets are never returned
+type ListDataExportDeliveryTarget struct {
+	Uid           string                       `json:"uid"`
+	Name          string                       `json:"name"`
+	Type          DataExportDeliveryTargetType `json:"type"`
+	Path          string                       `json:"path,omitempty"`
+	Endpoint      string                       `json:"endpoint,omitempty"`
+	Region        string                       `json:"region,omitempty"`
+	Bucket        string                       `json:"bucket,omitempty"`
+	AccessKeyID   string                       `json:"access_key_id,omitempty"`
+	PathStyle     bool                         `json:"path_style"`
+	Host          string                       `json:"host,omitempty"`
+	Port          int                          `json:"port,omitempty"`
+	Username      string                       `json:"username,omitempty"`
+	HostPublicKey string                       `json:"host_public_key,omitempty"`
+	CreatedAt     time.Time                    `json:"created_at"`
+}
+
+// swagger:model ListDataExportDeliveryTargetsReply
+type ListDataExportDeliveryTargetsReply struct {
+	Data  []*ListDataExportDeliveryTarget `json:"data"`
+	Total int64                           `json:"total_nums"`
+
+	// Generic reply
+	base.GenericResp
+}
@@ -0,0 +1,79 @@
+package v1
+
+import (
+	"time"
+
+	base "github.com/actiontech/dms/pkg/dms-common/api/base/v1"
+)
+
+// DataExportDownloadLinkRouterGroup 免登录下载链接的路由，不校验登录态
+const DataExportDownloadLinkRouterGroup = "/v1/dms/data_export_download_links"
+
+// swagger:parameters ListDataExportTaskDownloads
+type ListDataExportTaskDownloadsReq struct {
+	// project id
+	// Required: true
+	// in:path
+	ProjectUid string `param:"project_uid" json:"project_uid" validate:"required"`
+	// Required: true
+	// in:path
+	DataExportTaskUid string `param:"data_export_task_uid" json:"data_export_task_uid" validate:"required"`
+}
+
+type DataExportTaskDownload struct {
+	User UidWithName `json:"user"`
+	IP   string      `json:"ip"`
+	// bytes sent to the client
+	Bytes int64 `json:"bytes"`
+	// offset in the file of the first byte sent
+	StartOffset int64 `json:"start_offset"`
+	// downloaded with login session or a download link
+	// enum: ["session","link"]
+	Via string `json:"via"`
+	// resumed download with a range request, not counted in the download limit
+	Resumed   bool      `json:"resumed"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// swagger:model ListDataExportTaskDownloadsReply
+type ListDataExportTaskDownloadsReply struct {
+	Data  []*DataExportTaskDownload `json:"data"`
+	Total int64                     `json:"total_nums"`
+	// max downloads of the export task, 0 means unlimited
+	MaxDownloads int `json:"max_downloads"`
+
+	// Generic reply
+	base.GenericResp
+}
+
+// swagger:model
+type CreateDataExportDownloadLinkReq struct {
+	// swagger:ignore
+	ProjectUid string `param:"project_uid" json:"project_uid" validate:"required"`
+	// swagger:ignore
+	DataExportTaskUid string `param:"data_export_task_uid" json:"data_export_task_uid" validate:"required"`
+	// link expiry in seconds, default 3600, max 7 days
+	// Required: false
+	// example: 3600
+	ExpiresInSeconds int64 `json:"expires_in_seconds" validate:"min=0"`
+}
+
+// swagger:model CreateDataExportDownloadLinkReply
+type CreateDataExportDownloadLinkReply struct {
+	Data struct {
+		// single-use download url, can be fetched without login
+		DownloadUrl string    `json:"download_url"`
+		ExpiresAt   time.Time `json:"expires_at"`
+	} `json:"data"`
+
+	// Generic reply
+	base.GenericResp
+}
+
+// swagger:parameters DownloadDataExportTaskByLink
+type DownloadDataExportTaskByLinkReq struct {
+	// signed download token
+	// Required: true
+	// in:path
+	Token string `param:"token" json:"token" validate:"required"`
+}
@@ -0,0 +1,72 @@
+package v1
+
+import (
+	"time"
+
+	base "github.com/actiontech/dms/pkg/dms-common/api/base/v1"
+)
+
+// swagger:model
+type PreviewDataExportWorkflowReq struct {
+	// swagger:ignore
+	ProjectUid string `param:"project_uid" json:"project_uid" validate:"required"`
+	// swagger:ignore
+	DataExportWorkflowUid string `param:"data_export_workflow_uid" json:"data_export_workflow_uid" validate:"required"`
+	// sample rows of each export sql, default 10
+	// example: 10
+	SampleRows int `json:"sample_rows" validate:"min=0,max=100"`
+}
+
+type DataExportPreviewResult struct {
+	TaskUid      string   `json:"task_uid"`
+	DBServiceUid string   `json:"db_service_uid"`
+	SQLNumber    uint     `json:"sql_number"`
+	SQL          string   `json:"sql"`
+	Columns      []string `json:"columns"`
+	// masked sample rows, null values are null; empty when the rows cannot be masked
+	Rows [][]*string `json:"rows"`
+	// columns masked by the data masking rules
+	MaskedColumns []string `json:"masked_columns"`
+	// result rows estimated by the execution plan
+	EstimatedRows int64 `json:"estimated_rows"`
+	// export bytes estimated by the average size of the sample rows
+	EstimatedBytes int64 `json:"estimated_bytes"`
+	// preview failure of the sql, no sample rows are returned
+	Error string `json:"error,omitempty"`
+}
+
+type DataExportPreview struct {
+	Uid        string                     `json:"uid"`
+	CreateUser UidWithName                `json:"create_user"`
+	SampleRows int                        `json:"sample_rows"`
+	Results    []*DataExportPreviewResult `json:"results"`
+	CreatedAt  time.Time                  `json:"created_at"`
+}
+
+// swagger:model PreviewDataExportWorkflowReply
+type PreviewDataExportWorkflowReply struct {
+	Data *DataExportPreview `json:"data"`
+
+	// Generic reply
+	base.GenericResp
+}
+
+// swagger:parameters ListDataExportWorkflowPreviews
+type ListDataExportWorkflowPreviewsReq struct {
+	// project id
+	// Required: true
+	// in:path
+	ProjectUid string `param:"project_uid" json:"project_uid" validate:"required"`
+	// Required: true
+	// in:path
+	DataExportWorkflowUid string `param:"data_export_workflow_uid" json:"data_export_workflow_uid" validate:"required"`
+}
+
+// swagger:model ListDataExportWorkflowPreviewsReply
+type ListDataExportWorkflowPreviewsReply struct {
+	Data  []*DataExportPreview `json:"data"`
+	Total int64                `json:"total_nums"`
+
+	// Generic reply
+	base.GenericResp
+}
@@ -0,0 +1,88 @@
+package v1
+
+import (
+	base "github.com/actiontech/dms/pkg/dms-common/api/base/v1"
+)
+
+// swagger:enum DataExportQuotaScope
+type DataExportQuotaScope string
+
+const (
+	DataExportQuotaScopeGlobal    DataExportQuotaScope = "global"
+	DataExportQuotaScopeProject   DataExportQuotaScope = "project"
+	DataExportQuotaScopeDBService DataExportQuotaScope = "db_service"
+)
+
+// data export limits, 0 means unlimited
+type DataExportLimits struct {
+	// max rows exported by a single SQL
+	MaxRowsPerSQL int64 `json:"max_rows_per_sql"`
+	// max size of an export file
+	MaxFileSizeBytes int64 `json:"max_file_size_bytes"`
+	// max export tasks per user per day, not supported by db service scope
+	MaxExportsPerUserPerDay int64 `json:"max_exports_per_user_per_day"`
+	// max total bytes exported per project per month, not supported by db service scope
+	MaxBytesPerProjectPerMonth int64 `json:"max_bytes_per_project_per_month"`
+}
+
+type DataExportQuota struct {
+	// Required: true
+	Scope DataExportQuotaScope `json:"scope" validate:"required,oneof=global project db_service"`
+	// project uid or db service uid, empty for global scope
+	ScopeUid string `json:"scope_uid"`
+	DataExportLimits
+}
+
+// swagger:model
+type SetDataExportQuotaReq struct {
+	// all limits 0 removes the quota of the scope
+	Quota *DataExportQuota `json:"quota" validate:"required"`
+}
+
+// swagger:parameters ListDataExportQuotas
+type ListDataExportQuotasReq struct {
+	// list global quota and quotas in the project, empty means all quotas
+	// in:query
+	ProjectUid string `query:"project_uid" json:"project_uid"`
+}
+
+type ListDataExportQuota struct {
+	DataExportQuota
+	ProjectUid string `json:"project_uid,omitempty"`
+}
+
+// swagger:model ListDataExportQuotasReply
+type ListDataExportQuotasReply struct {
+	Data  []*ListDataExportQuota `json:"data"`
+	Total int64                  `json:"total_nums"`
+
+	// Generic reply
+	base.GenericResp
+}
+
+type DataExportQuotaUsage struct {
+	// effective limits, the strictest of global, project and db service quotas
+	Limits DataExportLimits `json:"limits"`
+	// export tasks of the user today in all projects, checked against the global limit
+	UserExportsToday int64 `json:"user_exports_today"`
+	// export tasks of the user today in this project, checked against the project limit
+	UserProjectExportsToday int64 `json:"user_project_exports_today"`
+	// bytes exported by the project this month
+	ProjectBytesThisMonth int64 `json:"project_bytes_this_month"`
+}
+
+// swagger:parameters GetDataExportQuotaUsage
+type GetDataExportQuotaUsageReq struct {
+	// project id
+	// Required: true
+	// in:path
+	ProjectUid string `param:"project_uid" json:"project_uid" validate:"required"`
+}
+
+// swagger:model GetDataExportQuotaUsageReply
+type GetDataExportQuotaUsageReply struct {
+	Data *DataExportQuotaUsage `json:"data"`
+
+	// Generic reply
+	base.GenericResp
+}
@@ -24,6 +24,15 @@ type DataExportTask struct {
 	// The exported SQL statement executed. it's necessary when ExportType is SQL
 	// SELECT * FROM DMS_test LIMIT 20;
 	ExportSQL string `json:"export_sql"`
+	// Export file type, default CSV. XLSX writes one sheet per SQL, other types write one file per SQL in a zip archive
+	// Required: false
+	// enum: ["CSV","XLSX","JSONL","SQL","PARQUET"]
+	ExportFileType string `json:"export_file_type"`
+	// Watermark mode, empty means no watermark. metadata writes a per-download fingerprint into the archive comment,
+	// marker_column adds a fingerprint column to each result, invisible appends a zero-width fingerprint to the first text value of each row
+	// Required: false
+	// enum: ["metadata","marker_column","invisible"]
+	WatermarkMode string `json:"watermark_mode"`
 }
 
 // swagger:model AddDataExportTaskReply
@@ -84,11 +93,18 @@ type GetDataExportTask struct {
 	FileName        string               `json:"file_name"` // 导出文件名
 	AuditResult     AuditTaskResult      `json:"audit_result"`
 	ExportType      string               `json:"export_type"`      // Export Type example: SQL Meta
-	ExportFileType  string               `json:"export_file_type"` // Export Content example: CSV SQL EXCEL
-	// 失败阶段 wire：task_schedule / connect / prepare / sql_execute / file_generate；非失败可省略
+	ExportFileType  string               `json:"export_file_type"` // Export Content example: CSV XLSX JSONL SQL PARQUET
+	WatermarkMode   string               `json:"watermark_mode,omitempty"`
+	// 失败阶段 wire：task_schedule / connect / prepare / sql_execute / file_generate / quota；非失败可省略
 	ExportFailStage string `json:"export_fail_stage,omitempty"`
 	// 失败人类可读原因；非失败可省略
 	ExportFailReason string `json:"export_fail_reason,omitempty"`
+	// 投递到外部存储的状态，工单未配置投递目标时省略
+	DeliveryStatus   string `json:"delivery_status,omitempty" enums:"delivering,delivered,failed"`
+	DeliveryLocation string `json:"delivery_location,omitempty"`
+	DeliveryError    string `json:"delivery_error,omitempty"`
+	// sha-256 of the export file in hex, available after the first download
+	ExportFileSHA256 string `json:"export_file_sha256,omitempty"`
 }
 
 // SQL审核结果
@@ -0,0 +1,49 @@
+package v1
+
+import (
+	"bytes"
+	"time"
+
+	base "github.com/actiontech/dms/pkg/dms-common/api/base/v1"
+)
+
+// swagger:parameters TraceDataExport
+type TraceDataExportReq struct {
+	// leaked export file, zip archive or a file extracted from it
+	//
+	// in: formData
+	//
+	// swagger:file
+	File *bytes.Buffer `json:"file"`
+	// rows copied from an export file
+	// in: formData
+	Sample string `json:"sample" form:"sample"`
+}
+
+type DataExportWatermarkRecord struct {
+	Fingerprint string      `json:"fingerprint"`
+	User        UidWithName `json:"user"`
+	CreatedAt   time.Time   `json:"created_at"`
+}
+
+type DataExportTrace struct {
+	ProjectUid            string `json:"project_uid"`
+	DataExportWorkflowUid string `json:"data_export_workflow_uid"`
+	DataExportTaskUid     string `json:"data_export_task_uid"`
+	// fingerprint kind found in the data, export means written into the file content and bound to the workflow creator,
+	// download means written into the archive comment and bound to the downloader
+	// enum: ["export","download"]
+	Kind string `json:"kind"`
+	// the matched fingerprint
+	Matched *DataExportWatermarkRecord `json:"matched"`
+	// all downloads of the export task
+	Downloads []*DataExportWatermarkRecord `json:"downloads"`
+}
+
+// swagger:model TraceDataExportReply
+type TraceDataExportReply struct {
+	Data []*DataExportTrace `json:"data"`
+
+	// Generic reply
+	base.GenericResp
+}
@@ -36,6 +36,18 @@ type DataExportWorkflow struct {
 	// Required: false
 	// example: 7002001
 	OpsTypeUID string `json:"ops_type_uid"`
+	// encrypt export files as AES-256 zip, the password is sent to the creator by email/IM notification instead of the download link
+	// Required: false
+	// example: true
+	EncryptExport bool `json:"encrypt_export"`
+	// max downloads of each export task, 0 means unlimited
+	// Required: false
+	// example: 3
+	MaxDownloads int `json:"max_downloads" validate:"min=0"`
+	// upload export files to the delivery target after export, omit or empty means no delivery
+	// Required: false
+	// example: 1834578903458732
+	DeliveryTargetUid string `json:"delivery_target_uid"`
 }
 
 // swagger:model AddDataExportWorkflowReply
@@ -203,6 +215,8 @@ type ListDataExportWorkflow struct {
 	WorkflowTemplateName string                   `json:"workflow_template_name"` // 创建时冗余保存的审批模板名称
 	// OpsType 运维类型（项目字典批量解析）；未设置或字典项已删时省略，供前端「-」约定
 	OpsType *dmsCommonV1.OpsType `json:"ops_type,omitempty"`
+	// SLAStatus 审批时效状态；项目未设置审批时效或工单不在审批中时省略
+	SLAStatus WorkflowApprovalSLAStatus `json:"sla_status,omitempty"`
 
 	CurrentStepAssigneeUsers []UidWithName                           `json:"current_step_assignee_user_list"` // 工单待操作人
 	DBServiceInfos           []*dmsCommonV1.DBServiceUidWithNameInfo `json:"db_service_info,omitempty"`       // 所属数据源信息
@@ -264,6 +278,16 @@ type GetDataExportWorkflow struct {
 	WorkflowRecordHistory []WorkflowRecord     `json:"workflow_record_history"`
 	// UnmaskingWorkflow 关联的查看原文工单摘要；无关联时为 null
 	UnmaskingWorkflow *DataExportRelatedUnmaskingWorkflow `json:"unmasking_workflow"`
+	// EncryptExport 导出文件是否加密，解压密码通过通知发送给申请人
+	EncryptExport bool `json:"encrypt_export"`
+	// MaxDownloads 每个导出任务的最大下载次数，0 表示不限制
+	MaxDownloads int `json:"max_downloads"`
+	// DeliveryTarget 导出文件的投递目标；未设置时为 null
+	DeliveryTarget *UidWithName `json:"delivery_target"`
+	// Schedule 工单设置的定时导出；未设置时为 null
+	Schedule *DataExportSchedule `json:"schedule"`
+	// QuotaUsage 申请人的导出限制及当前用量，供审批人参考
+	QuotaUsage *DataExportQuotaUsage `json:"quota_usage"`
 }
 
 // swagger:parameters CheckDataExportWorkflowTemplateUsed
@@ -363,6 +387,17 @@ type ExportDataExportWorkflowReq struct {
 	DataExportWorkflowUid string `param:"data_export_workflow_uid" json:"data_export_workflow_uid" validate:"required"`
 }
 
+// swagger:parameters ResendDataExportWorkflowPassword
+type ResendDataExportWorkflowPasswordReq struct {
+	// project id
+	// Required: true
+	// in:path
+	ProjectUid string `param:"project_uid" json:"project_uid" validate:"required"`
+	// Required: true
+	// in:path
+	DataExportWorkflowUid string `param:"data_export_workflow_uid" json:"data_export_workflow_uid" validate:"required"`
+}
+
 // swagger:parameters DownloadOriginalDataExportWorkflow
 type DownloadOriginalDataExportWorkflowReq struct {
 	// project id
@@ -410,3 +445,94 @@ type CancelDataExportWorkflowReq struct {
 	ProjectUid string                          `param:"project_uid" json:"project_uid" validate:"required"`
 	Payload    CancelDataExportWorkflowPayload `json:"payload" validate:"required"`
 }
+
+// swagger:enum DataExportScheduleStatus
+type DataExportScheduleStatus string
+
+const (
+	DataExportScheduleStatusActive DataExportScheduleStatus = "active"
+	// suspended until the creator resumes it, see suspend_reason
+	DataExportScheduleStatusSuspended DataExportScheduleStatus = "suspended"
+	DataExportScheduleStatusEnded     DataExportScheduleStatus = "ended"
+)
+
+type DataExportSchedule struct {
+	Uid         string      `json:"uid"`
+	WorkflowUid string      `json:"workflow_uid"`
+	CreateUser  UidWithName `json:"create_user"`
+	// standard 5-field cron expression, the interval must be at least one hour
+	CronExpression string                   `json:"cron_expression"`
+	EndTime        *time.Time               `json:"end_time,omitempty"`
+	Status         DataExportScheduleStatus `json:"status"`
+	SuspendReason  string                   `json:"suspend_reason,omitempty"`
+	NextRunAt      *time.Time               `json:"next_run_at,omitempty"`
+	LastRunAt      *time.Time               `json:"last_run_at,omitempty"`
+	// the workflow created by the last run
+	LastRunWorkflowUid string    `json:"last_run_workflow_uid,omitempty"`
+	LastRunError       string    `json:"last_run_error,omitempty"`
+	CreatedAt          time.Time `json:"created_at"`
+}
+
+type SetDataExportSchedulePayload struct {
+	// standard 5-field cron expression, e.g. "0 8 * * 1"
+	// Required: true
+	CronExpression string `json:"cron_expression" validate:"required"`
+	// the schedule stops after end time, empty means never
+	EndTime *time.Time `json:"end_time"`
+}
+
+// swagger:model
+type SetDataExportScheduleReq struct {
+	// swagger:ignore
+	ProjectUid string `param:"project_uid" json:"project_uid" validate:"required"`
+	// swagger:ignore
+	DataExportWorkflowUid string                       `param:"data_export_workflow_uid" json:"data_export_workflow_uid" validate:"required"`
+	Payload               SetDataExportSchedulePayload `json:"payload" validate:"required"`
+}
+
+// swagger:model SetDataExportScheduleReply
+type SetDataExportScheduleReply struct {
+	Data *DataExportSchedule `json:"data"`
+
+	// Generic reply
+	base.GenericResp
+}
+
+// swagger:parameters DeleteDataExportSchedule
+type DeleteDataExportScheduleReq struct {
+	// project id
+	// Required: true
+	// in:path
+	ProjectUid string `param:"project_uid" json:"project_uid" validate:"required"`
+	// Required: true
+	// in:path
+	DataExportWorkflowUid string `param:"data_export_workflow_uid" json:"data_export_workflow_uid" validate:"required"`
+}
+
+// swagger:parameters ResumeDataExportSchedule
+type ResumeDataExportScheduleReq struct {
+	// project id
+	// Required: true
+	// in:path
+	ProjectUid string `param:"project_uid" json:"project_uid" validate:"required"`
+	// Required: true
+	// in:path
+	DataExportWorkflowUid string `param:"data_export_workflow_uid" json:"data_export_workflow_uid" validate:"required"`
+}
+
+// swagger:parameters ListDataExportSchedules
+type ListDataExportSchedulesReq struct {
+	// project id
+	// Required: true
+	// in:path
+	ProjectUid string `param:"project_uid" json:"project_uid" validate:"required"`
+}
+
+// swagger:model ListDataExportSchedulesReply
+type ListDataExportSchedulesReply struct {
+	Data  []*DataExportSchedule `json:"data"`
+	Total int64                 `json:"total_nums"`
+
+	// Generic reply
+	base.GenericResp
+}
@@ -2,6 +2,7 @@ package v1
 
 import (
 	"fmt"
+	"time"
 
 	base "github.com/actiontech/dms/pkg/dms-common/api/base/v1"
 )
@@ -17,6 +18,8 @@ type Member struct {
 	RoleWithOpRanges []MemberRoleWithOpRange `json:"role_with_op_ranges"`
 	// member project manage permissions
 	ProjectManagePermissions []string `json:"project_manage_permissions"`
+	// the membership is revoked automatically after this time, empty means never expires
+	ExpiresAt *time.Time `json:"expires_at,omitempty"`
 }
 
 type MemberRoleWithOpRange struct {
@@ -96,6 +99,8 @@ type ListMemberRoleWithOpRange struct {
 	OpRangeType OpRangeType `json:"op_range_type" validate:"required"`
 	// op range uids
 	RangeUIDs []UidWithName `json:"range_uids" validate:"required"`
+	// db services currently matched by the selectors, only for db_service_selector range type
+	ResolvedRangeUIDs []UidWithName `json:"resolved_range_uids,omitempty"`
 	// member op permissions
 	OpPermissions []UidWithName  `json:"op_permissions"`
 	// member group
@@ -124,6 +129,8 @@ type ListMember struct {
 	PlatformRoles []UidWithName `json:"platform_roles"`
 	// member projects
 	Projects []string `json:"projects"`
+	// the membership is revoked automatically after this time, empty means never expires
+	ExpiresAt *time.Time `json:"expires_at,omitempty"`
 }
 
 type CurrentProjectAdmin struct {
@@ -231,6 +238,8 @@ type UpdateMember struct {
 	RoleWithOpRanges []MemberRoleWithOpRange `json:"role_with_op_ranges"`
 	// member project manage permissions
 	ProjectManagePermissions []string `json:"project_manage_permissions"`
+	// the membership is revoked automatically after this time, empty means never expires
+	ExpiresAt *time.Time `json:"expires_at,omitempty"`
 }
 
 // swagger:model
@@ -0,0 +1,93 @@
+package v1
+
+import (
+	"time"
+
+	base "github.com/actiontech/dms/pkg/dms-common/api/base/v1"
+)
+
+// swagger:enum MemberAccessRequestStatus
+type MemberAccessRequestStatus string
+
+const (
+	MemberAccessRequestStatusPending  MemberAccessRequestStatus = "pending"
+	MemberAccessRequestStatusApproved MemberAccessRequestStatus = "approved"
+	MemberAccessRequestStatusRejected MemberAccessRequestStatus = "rejected"
+)
+
+type AddMemberAccessRequest struct {
+	// requested roles with op ranges
+	// Required: true
+	RoleWithOpRanges []MemberRoleWithOpRange `json:"role_with_op_ranges" validate:"required"`
+	// how many hours the access lasts after approval
+	// Required: true
+	DurationHours uint `json:"duration_hours" validate:"required"`
+	// why the access is needed
+	// Required: true
+	Reason string `json:"reason" validate:"required"`
+}
+
+// swagger:model
+type AddMemberAccessRequestReq struct {
+	// swagger:ignore
+	ProjectUid    string                  `param:"project_uid" json:"project_uid" validate:"required"`
+	AccessRequest *AddMemberAccessRequest `json:"access_request" validate:"required"`
+}
+
+// swagger:model AddMemberAccessRequestReply
+type AddMemberAccessRequestReply struct {
+	Data struct {
+		// access request UID
+		Uid string `json:"uid"`
+	} `json:"data"`
+
+	// Generic reply
+	base.GenericResp
+}
+
+// swagger:parameters ListMemberAccessRequests
+type ListMemberAccessRequestsReq struct {
+	// project id
+	// Required: true
+	// in:path
+	ProjectUid string `param:"project_uid" json:"project_uid" validate:"required"`
+	// filter by status
+	// in:query
+	FilterByStatus MemberAccessRequestStatus `query:"filter_by_status" json:"filter_by_status"`
+}
+
+type MemberAccessRequest struct {
+	Uid              string                      `json:"uid"`
+	User             UidWithName                 `json:"user"`
+	RoleWithOpRanges []ListMemberRoleWithOpRange `json:"role_with_op_ranges"`
+	DurationHours    uint                        `json:"duration_hours"`
+	Reason           string                      `json:"reason"`
+	Status           MemberAccessRequestStatus   `json:"status"`
+	Reviewer         *UidWithName                `json:"reviewer,omitempty"`
+	ReviewComment    string                      `json:"review_comment"`
+	ReviewedAt       *time.Time                  `json:"reviewed_at,omitempty"`
+	// when the granted access expires, only set for approved requests
+	ExpiresAt *time.Time `json:"expires_at,omitempty"`
+	// the member group created for the granted access
+	MemberGroupUid string    `json:"member_group_uid,omitempty"`
+	CreatedAt      time.Time `json:"created_at"`
+}
+
+// swagger:model ListMemberAccessRequestsReply
+type ListMemberAccessRequestsReply struct {
+	Data  []*MemberAccessRequest `json:"data"`
+	Total int64                  `json:"total_nums"`
+
+	// Generic reply
+	base.GenericResp
+}
+
+// swagger:model
+type ReviewMemberAccessRequestReq struct {
+	// swagger:ignore
+	ProjectUid string `param:"project_uid" json:"project_uid" validate:"required"`
+	// swagger:ignore
+	AccessRequestUid string `param:"access_request_uid" json:"access_request_uid" validate:"required"`
+	// review comment, shown to the applicant
+	Comment string `json:"comment"`
+}
@@ -1,6 +1,8 @@
 package v1
 
 import (
+	"time"
+
 	base "github.com/actiontech/dms/pkg/dms-common/api/base/v1"
 )
 
@@ -46,6 +48,10 @@ type ListMemberGroup struct {
 	CurrentProjectOpPermissions []ProjectOpPermission `json:"current_project_op_permissions"`
 	// member project manage permissions
 	CurrentProjectManagePermissions []UidWithName `json:"current_project_manage_permissions"`
+	// the member group is revoked automatically after this time, empty means never expires
+	ExpiresAt *time.Time `json:"expires_at,omitempty"`
+	// the bound user group, users of the member group follow the user group
+	UserGroup *UidWithName `json:"user_group,omitempty"`
 }
 
 // swagger:model ListMemberGroupsReply
@@ -68,6 +74,10 @@ type GetMemberGroup struct {
 	IsProjectAdmin bool `json:"is_project_admin"`
 	// member op permission
 	RoleWithOpRanges []ListMemberRoleWithOpRange `json:"role_with_op_ranges"`
+	// the member group is revoked automatically after this time, empty means never expires
+	ExpiresAt *time.Time `json:"expires_at,omitempty"`
+	// the bound user group, users of the member group follow the user group
+	UserGroup *UidWithName `json:"user_group,omitempty"`
 }
 
 // swagger:parameters GetMemberGroup
@@ -95,15 +105,18 @@ type MemberGroup struct {
 	// member group name
 	// Required: true
 	Name string `json:"name" validate:"required"`
-	// member user uid
-	// Required: true
-	UserUids []string `json:"user_uids" validate:"required"`
+	// member user uid, required unless the member group is bound to a user group
+	UserUids []string `json:"user_uids" validate:"required_without=UserGroupUid"`
+	// bind a global user group, the users of the member group always follow the user group
+	UserGroupUid string `json:"user_group_uid"`
 	// Whether the member has project admin permission
 	IsProjectAdmin bool `json:"is_project_admin"`
 	// member role with op ranges
 	RoleWithOpRanges []MemberRoleWithOpRange `json:"role_with_op_ranges"`
 	// member project manage permissions
 	ProjectManagePermissions []string `json:"project_manage_permissions"`
+	// the member group is revoked automatically after this time, empty means never expires
+	ExpiresAt *time.Time `json:"expires_at,omitempty"`
 }
 
 // swagger:model
@@ -126,15 +139,18 @@ type AddMemberGroupReply struct {
 }
 
 type UpdateMemberGroup struct {
-	// member user uid
-	// Required: true
-	UserUids []string `json:"user_uids" validate:"required"`
+	// member user uid, required unless the member group is bound to a user group
+	UserUids []string `json:"user_uids" validate:"required_without=UserGroupUid"`
+	// bind a global user group, the users of the member group always follow the user group
+	UserGroupUid string `json:"user_group_uid"`
 	// Whether the member has project admin permission
 	IsProjectAdmin bool `json:"is_project_admin"`
 	// member role with op ranges
 	RoleWithOpRanges []MemberRoleWithOpRange `json:"role_with_op_ranges"`
 	// member project manage permissions
 	ProjectManagePermissions []string `json:"project_manage_permissions"`
+	// the member group is revoked automatically after this time, empty means never expires
+	ExpiresAt *time.Time `json:"expires_at,omitempty"`
 }
 
 // swagger:model
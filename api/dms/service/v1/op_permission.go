@@ -17,12 +17,28 @@ const (
 	OpRangeTypeProject OpRangeType = "project"
 	// 项目内的数据源权限: 该权限只能被成员使用
 	OpRangeTypeDBService OpRangeType = "db_service"
+	// 数据源内的库权限: 将数据源权限收窄到指定库，范围格式见 sqlop.EncodeObjectRange
+	OpRangeTypeDatabase OpRangeType = "database"
+	// 数据源内的模式权限: 将数据源权限收窄到指定模式
+	OpRangeTypeSchema OpRangeType = "schema"
+	// 数据源内的表权限: 将数据源权限收窄到指定表
+	OpRangeTypeTable OpRangeType = "table"
+	// 数据源选择器: 范围为 environment_tag=xxx 或 business_tag=xxx，校验时解析为项目内匹配的数据源
+	OpRangeTypeDBServiceSelector OpRangeType = "db_service_selector"
 )
 
 func ParseOpRangeType(typ string) (OpRangeType, error) {
 	switch typ {
 	case string(OpRangeTypeDBService):
 		return OpRangeTypeDBService, nil
+	case string(OpRangeTypeDatabase):
+		return OpRangeTypeDatabase, nil
+	case string(OpRangeTypeSchema):
+		return OpRangeTypeSchema, nil
+	case string(OpRangeTypeTable):
+		return OpRangeTypeTable, nil
+	case string(OpRangeTypeDBServiceSelector):
+		return OpRangeTypeDBServiceSelector, nil
 	case string(OpRangeTypeProject):
 		return OpRangeTypeProject, nil
 	case string(OpRangeTypeGlobal):
@@ -39,6 +39,9 @@ type GetOperationRecordListReq struct {
 	FilterOperateProjectName *string `json:"filter_operate_project_name" query:"filter_operate_project_name"`
 	// in:query
 	FuzzySearchOperateUserName string `json:"fuzzy_search_operate_user_name" query:"fuzzy_search_operate_user_name"`
+	// exact operate user name, e.g. to get the audit trail of a service account
+	// in:query
+	FilterOperateUserName string `json:"filter_operate_user_name" query:"filter_operate_user_name"`
 	// in:query
 	FilterOperateTypeName string `json:"filter_operate_type_name" query:"filter_operate_type_name"`
 	// in:query
@@ -86,6 +89,9 @@ type ExportOperationRecordListReq struct {
 	FilterOperateProjectName *string `json:"filter_operate_project_name" query:"filter_operate_project_name"`
 	// in:query
 	FuzzySearchOperateUserName string `json:"fuzzy_search_operate_user_name" query:"fuzzy_search_operate_user_name"`
+	// exact operate user name, e.g. to get the audit trail of a service account
+	// in:query
+	FilterOperateUserName string `json:"filter_operate_user_name" query:"filter_operate_user_name"`
 	// in:query
 	FilterOperateTypeName string `json:"filter_operate_type_name" query:"filter_operate_type_name"`
 	// in:query
@@ -0,0 +1,28 @@
+package v1
+
+import (
+	base "github.com/actiontech/dms/pkg/dms-common/api/base/v1"
+)
+
+type PermissionCacheStats struct {
+	// whether the permission cache is enabled
+	Enabled bool `json:"enabled"`
+	// lookups served from the cache
+	Hits uint64 `json:"hits"`
+	// lookups that went to the database
+	Misses uint64 `json:"misses"`
+	// hits / (hits + misses)
+	HitRatio float64 `json:"hit_ratio"`
+	// invalidations triggered by permission changes on this node
+	Invalidations uint64 `json:"invalidations"`
+	// cached entries
+	Entries int `json:"entries"`
+}
+
+// swagger:model GetPermissionCacheStatsReply
+type GetPermissionCacheStatsReply struct {
+	Data *PermissionCacheStats `json:"data"`
+
+	// Generic reply
+	base.GenericResp
+}
@@ -0,0 +1,93 @@
+package v1
+
+import (
+	base "github.com/actiontech/dms/pkg/dms-common/api/base/v1"
+)
+
+// swagger:parameters ExplainPermission
+type ExplainPermissionReq struct {
+	// the user whose permission is explained
+	// in:query
+	// Required: true
+	UserUid string `query:"user" json:"user" validate:"required"`
+	// project uid
+	// in:query
+	// Required: true
+	ProjectUid string `query:"project" json:"project" validate:"required"`
+	// db service uid, explain the permission on the whole project if empty
+	// in:query
+	DBServiceUid string `query:"db_service" json:"db_service"`
+	// op permission uid
+	// in:query
+	// Required: true
+	OpPermissionUid string `query:"permission" json:"permission" validate:"required"`
+	// explain as a business write operation, global privilege then requires business write permission
+	// in:query
+	BusinessWrite bool `query:"business_write" json:"business_write"`
+	// database of the accessed object, used with db_service to check database, schema and table ranges
+	// in:query
+	Database string `query:"database" json:"database"`
+	// schema of the accessed object
+	// in:query
+	Schema string `query:"schema" json:"schema"`
+	// table of the accessed object
+	// in:query
+	Table string `query:"table" json:"table"`
+}
+
+// swagger:enum PermissionExplainRule
+type PermissionExplainRule string
+
+const (
+	PermissionExplainRuleUser                         PermissionExplainRule = "user"
+	PermissionExplainRuleGlobalOp                     PermissionExplainRule = "global_op"
+	PermissionExplainRuleBusinessWrite                PermissionExplainRule = "business_write"
+	PermissionExplainRuleProjectAdmin                 PermissionExplainRule = "project_admin"
+	PermissionExplainRuleMemberRole                   PermissionExplainRule = "member_role"
+	PermissionExplainRuleMemberGroupRole              PermissionExplainRule = "member_group_role"
+	PermissionExplainRuleMemberProjectPermission      PermissionExplainRule = "member_project_permission"
+	PermissionExplainRuleMemberGroupProjectPermission PermissionExplainRule = "member_group_project_permission"
+	PermissionExplainRuleRange                        PermissionExplainRule = "range"
+)
+
+// swagger:enum PermissionExplainResult
+type PermissionExplainResult string
+
+const (
+	PermissionExplainResultMatched    PermissionExplainResult = "matched"
+	PermissionExplainResultNotMatched PermissionExplainResult = "not_matched"
+)
+
+type PermissionExplainSource struct {
+	// member or member_group
+	SourceType string `json:"source_type"`
+	// member uid or member group
+	Source UidWithName `json:"source"`
+	// role, empty if granted by the project permission of the member or member group
+	Role *UidWithName `json:"role,omitempty"`
+	// op permission
+	OpPermission UidWithName `json:"op_permission"`
+	// op range type
+	OpRangeType OpRangeType `json:"op_range_type"`
+	// op range uids
+	RangeUIDs []string `json:"range_uids"`
+}
+
+type PermissionExplainStep struct {
+	Rule   PermissionExplainRule   `json:"rule"`
+	Result PermissionExplainResult `json:"result"`
+	Detail string                  `json:"detail"`
+	// the grant this step is evaluated on
+	Source *PermissionExplainSource `json:"source,omitempty"`
+}
+
+// swagger:model ExplainPermissionReply
+type ExplainPermissionReply struct {
+	Data struct {
+		Allowed bool                     `json:"allowed"`
+		Steps   []*PermissionExplainStep `json:"steps"`
+	} `json:"data"`
+
+	// Generic reply
+	base.GenericResp
+}
@@ -16,6 +16,10 @@ type Role struct {
 	Desc string `json:"desc"`
 	// op permission uid
 	OpPermissionUids []string `json:"op_permission_uids"`
+	// uids of the roles to inherit op permissions from
+	ParentRoleUids []string `json:"parent_role_uids"`
+	// create a role template, which can not be granted directly and is instantiated by projects
+	IsTemplate bool `json:"is_template"`
 }
 
 // swagger:model
@@ -81,6 +85,15 @@ type ListRoleReq struct {
 	// the db service fuzzy keyword,include op_permission
 	// in:query
 	FuzzyKeyword string `query:"fuzzy_keyword" json:"fuzzy_keyword"`
+	// list role templates instead of roles
+	// in:query
+	FilterByIsTemplate bool `query:"filter_by_is_template" json:"filter_by_is_template"`
+	// include the roles of the project, roles of projects are excluded by default
+	// in:query
+	FilterByProjectUid string `query:"filter_by_project_uid" json:"filter_by_project_uid"`
+	// filter the instances of the role template
+	// in:query
+	FilterByTemplateUid string `query:"filter_by_template_uid" json:"filter_by_template_uid"`
 }
 
 // swagger:enum RoleOrderByField
@@ -102,12 +115,22 @@ type ListRole struct {
 	Desc string `json:"desc"`
 	// op permissions
 	OpPermissions []ListRoleOpPermission `json:"op_permissions"`
+	// roles inherited by the role
+	ParentRoles []UidWithName `json:"parent_roles"`
+	// whether the role is a role template
+	IsTemplate bool `json:"is_template"`
+	// the role template the role is instantiated from
+	Template *UidWithName `json:"template,omitempty"`
+	// the project the role belongs to, empty for global roles
+	ProjectUid string `json:"project_uid"`
 }
 
 type ListRoleOpPermission struct {
 	Uid  string `json:"uid"`
 	Name string `json:"name"`
 	Module string `json:"module"`
+	// whether the op permission is inherited from parent roles only
+	Inherited bool `json:"inherited"`
 }
 
 // swagger:model ListRoleReply
@@ -127,6 +150,8 @@ type UpdateRole struct {
 	Desc *string `json:"desc"`
 	// Op permission uids
 	OpPermissionUids *[]string `json:"op_permission_uids" validate:"required"`
+	// uids of the roles to inherit op permissions from, keep unchanged if not set
+	ParentRoleUids *[]string `json:"parent_role_uids"`
 }
 
 // swagger:model
@@ -145,3 +170,69 @@ func (u *UpdateRoleReq) String() string {
 	}
 	return fmt.Sprintf("UpdateRoleReq{Uid:%s}", u.RoleUid)
 }
+
+type RoleTemplateInstance struct {
+	// the project to instantiate the role template in
+	// Required: true
+	ProjectUid string `json:"project_uid" validate:"required"`
+	// role name
+	// Required: true
+	Name string `json:"name" validate:"required"`
+	// role description
+	Desc string `json:"desc"`
+	// op permissions granted in addition to the role template
+	OpPermissionUids []string `json:"op_permission_uids"`
+}
+
+// swagger:model
+type InstantiateRoleTemplateReq struct {
+	// swagger:ignore
+	RoleUid  string                `param:"role_uid" json:"role_uid" validate:"required"`
+	Instance *RoleTemplateInstance `json:"instance" validate:"required"`
+}
+
+func (u *InstantiateRoleTemplateReq) String() string {
+	if u == nil {
+		return "InstantiateRoleTemplateReq{nil}"
+	}
+	if u.Instance == nil {
+		return fmt.Sprintf("InstantiateRoleTemplateReq{Uid:%s}", u.RoleUid)
+	}
+	return fmt.Sprintf("InstantiateRoleTemplateReq{Uid:%s,Project:%s,Name:%s}", u.RoleUid, u.Instance.ProjectUid, u.Instance.Name)
+}
+
+// swagger:model InstantiateRoleTemplateReply
+type InstantiateRoleTemplateReply struct {
+	Data struct {
+		// uid of the role instantiated from the role template
+		Uid string `json:"uid"`
+	} `json:"data"`
+
+	// Generic reply
+	base.GenericResp
+}
+
+// swagger:model
+type PreviewUpdateRoleReq struct {
+	// swagger:ignore
+	RoleUid string      `param:"role_uid" json:"role_uid" validate:"required"`
+	Role    *UpdateRole `json:"role" validate:"required"`
+}
+
+// RoleOpPermissionsDiff the changes of the effective op permissions of a role
+type RoleOpPermissionsDiff struct {
+	Role UidWithName `json:"role"`
+	// the project the role belongs to, empty for global roles
+	ProjectUid           string        `json:"project_uid"`
+	AddedOpPermissions   []UidWithName `json:"added_op_permissions"`
+	RemovedOpPermissions []UidWithName `json:"removed_op_permissions"`
+}
+
+// swagger:model PreviewUpdateRoleReply
+type PreviewUpdateRoleReply struct {
+	// the updated role and the roles inheriting it, including instances of the role template
+	Data []*RoleOpPermissionsDiff `json:"data"`
+
+	// Generic reply
+	base.GenericResp
+}
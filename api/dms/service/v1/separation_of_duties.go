@@ -0,0 +1,120 @@
+package v1
+
+import (
+	"time"
+
+	base "github.com/actiontech/dms/pkg/dms-common/api/base/v1"
+)
+
+// swagger:enum SeparationOfDutiesRuleType
+type SeparationOfDutiesRuleType string
+
+const (
+	// the same user cannot hold both op permissions on the same db service
+	SeparationOfDutiesRuleTypeExclusivePermissions SeparationOfDutiesRuleType = "exclusive_permissions"
+	// the creator of a workflow cannot approve it
+	SeparationOfDutiesRuleTypeCreatorCannotApprove SeparationOfDutiesRuleType = "creator_cannot_approve"
+)
+
+// swagger:enum SeparationOfDutiesWorkflowType
+type SeparationOfDutiesWorkflowType string
+
+const (
+	SeparationOfDutiesWorkflowTypeDataExport SeparationOfDutiesWorkflowType = "data_export"
+)
+
+type AddSeparationOfDutiesRule struct {
+	// rule type
+	// Required: true
+	Type SeparationOfDutiesRuleType `json:"type" validate:"required"`
+	// two mutually exclusive op permissions, required by exclusive_permissions rules
+	OpPermissionUids []string `json:"op_permission_uids"`
+	// workflow type, required by creator_cannot_approve rules
+	WorkflowType SeparationOfDutiesWorkflowType `json:"workflow_type"`
+	Desc         string                         `json:"desc"`
+}
+
+// swagger:model
+type AddSeparationOfDutiesRuleReq struct {
+	// swagger:ignore
+	ProjectUid string                     `param:"project_uid" json:"project_uid" validate:"required"`
+	Rule       *AddSeparationOfDutiesRule `json:"rule" validate:"required"`
+}
+
+// swagger:model AddSeparationOfDutiesRuleReply
+type AddSeparationOfDutiesRuleReply struct {
+	Data struct {
+		// rule UID
+		Uid string `json:"uid"`
+	} `json:"data"`
+
+	// Generic reply
+	base.GenericResp
+}
+
+// swagger:parameters ListSeparationOfDutiesRules
+type ListSeparationOfDutiesRulesReq struct {
+	// project id
+	// Required: true
+	// in:path
+	ProjectUid string `param:"project_uid" json:"project_uid" validate:"required"`
+}
+
+type SeparationOfDutiesRule struct {
+	Uid           string                         `json:"uid"`
+	Type          SeparationOfDutiesRuleType     `json:"type"`
+	OpPermissions []UidWithName                  `json:"op_permissions,omitempty"`
+	WorkflowType  SeparationOfDutiesWorkflowType `json:"workflow_type,omitempty"`
+	Desc          string                         `json:"desc"`
+	CreateUser    UidWithName                    `json:"create_user"`
+	CreatedAt     time.Time                      `json:"created_at"`
+}
+
+// swagger:model ListSeparationOfDutiesRulesReply
+type ListSeparationOfDutiesRulesReply struct {
+	Data  []*SeparationOfDutiesRule `json:"data"`
+	Total int64                     `json:"total_nums"`
+
+	// Generic reply
+	base.GenericResp
+}
+
+// swagger:parameters DelSeparationOfDutiesRule
+type DelSeparationOfDutiesRuleReq struct {
+	// project id
+	// Required: true
+	// in:path
+	ProjectUid string `param:"project_uid" json:"project_uid" validate:"required"`
+	// rule uid
+	// Required: true
+	// in:path
+	RuleUid string `param:"rule_uid" json:"rule_uid" validate:"required"`
+}
+
+// swagger:parameters ListSeparationOfDutiesViolations
+type ListSeparationOfDutiesViolationsReq struct {
+	// project id
+	// Required: true
+	// in:path
+	ProjectUid string `param:"project_uid" json:"project_uid" validate:"required"`
+}
+
+type SeparationOfDutiesViolation struct {
+	Rule *SeparationOfDutiesRule `json:"rule"`
+	User UidWithName             `json:"user"`
+	// db services on which the exclusive op permissions overlap, "*" means the whole project
+	DBServices []UidWithName `json:"db_services,omitempty"`
+	// workflow approved by its own creator
+	Workflow *UidWithName `json:"workflow,omitempty"`
+	// when the workflow was approved
+	ApprovedAt *time.Time `json:"approved_at,omitempty"`
+}
+
+// swagger:model ListSeparationOfDutiesViolationsReply
+type ListSeparationOfDutiesViolationsReply struct {
+	Data  []*SeparationOfDutiesViolation `json:"data"`
+	Total int64                          `json:"total_nums"`
+
+	// Generic reply
+	base.GenericResp
+}
@@ -0,0 +1,116 @@
+package v1
+
+import (
+	"fmt"
+
+	base "github.com/actiontech/dms/pkg/dms-common/api/base/v1"
+	dmsCommonV1 "github.com/actiontech/dms/pkg/dms-common/api/dms/v1"
+)
+
+type ServiceAccount struct {
+	// service account uid
+	Uid string `json:"uid"`
+	// the user uid of the service account, bind it to projects and roles like a normal user
+	UserUid string `json:"user_uid"`
+	// service account name
+	Name string `json:"name"`
+	// service account description
+	Desc string `json:"desc"`
+	// client id used to request a token
+	ClientID string `json:"client_id"`
+	// service account status
+	Stat dmsCommonV1.Stat `json:"stat"`
+	// service account op permissions
+	OpPermissions []dmsCommonV1.UidWithName `json:"op_permissions"`
+	// time of the latest client secret rotation
+	SecretRotatedAt string `json:"secret_rotated_at" example:"RFC3339"`
+	CreatedAt       string `json:"created_at" example:"RFC3339"`
+}
+
+type AddServiceAccount struct {
+	// service account name
+	// Required: true
+	Name string `json:"name" validate:"required"`
+	// service account description
+	Desc string `json:"desc"`
+	// service account op permission uids
+	OpPermissionUids []string `json:"op_permission_uids"`
+}
+
+// swagger:model
+type AddServiceAccountReq struct {
+	ServiceAccount *AddServiceAccount `json:"service_account" validate:"required"`
+}
+
+func (r *AddServiceAccountReq) String() string {
+	if r == nil || r.ServiceAccount == nil {
+		return "AddServiceAccountReq{nil}"
+	}
+	return fmt.Sprintf("AddServiceAccountReq{Name:%s}", r.ServiceAccount.Name)
+}
+
+// swagger:model ServiceAccountCredentialReply
+type ServiceAccountCredentialReply struct {
+	Data struct {
+		// service account uid
+		Uid string `json:"uid"`
+		// client id used to request a token
+		ClientID string `json:"client_id"`
+		// client secret, only returned when created or rotated
+		ClientSecret string `json:"client_secret"`
+	} `json:"data"`
+
+	// Generic reply
+	base.GenericResp
+}
+
+func (r *ServiceAccountCredentialReply) String() string {
+	if r == nil {
+		return "ServiceAccountCredentialReply{nil}"
+	}
+	return fmt.Sprintf("ServiceAccountCredentialReply{Uid:%s,ClientID:%s}", r.Data.Uid, r.Data.ClientID)
+}
+
+// swagger:model ListServiceAccountsReply
+type ListServiceAccountsReply struct {
+	Data []*ServiceAccount `json:"data"`
+
+	// Generic reply
+	base.GenericResp
+}
+
+type UpdateServiceAccount struct {
+	// Whether the service account is disabled or not
+	IsDisabled *bool `json:"is_disabled" validate:"required"`
+	// service account op permission uids
+	OpPermissionUids []string `json:"op_permission_uids"`
+}
+
+// swagger:model
+type UpdateServiceAccountReq struct {
+	// service account uid
+	// in:path
+	ServiceAccountUid string                `param:"service_account_uid" json:"service_account_uid" validate:"required"`
+	ServiceAccount    *UpdateServiceAccount `json:"service_account" validate:"required"`
+}
+
+func (r *UpdateServiceAccountReq) String() string {
+	if r == nil {
+		return "UpdateServiceAccountReq{nil}"
+	}
+	return fmt.Sprintf("UpdateServiceAccountReq{Uid:%s}", r.ServiceAccountUid)
+}
+
+// swagger:parameters DelServiceAccount RotateServiceAccountSecret
+type ServiceAccountUidReq struct {
+	// service account uid
+	// in:path
+	ServiceAccountUid string `param:"service_account_uid" json:"service_account_uid" validate:"required"`
+}
+
+func (r *ServiceAccountUidReq) String() string {
+	if r == nil {
+		return "ServiceAccountUidReq{nil}"
+	}
+	return fmt.Sprintf("ServiceAccountUidReq{Uid:%s}", r.ServiceAccountUid)
+}
@@ -1,6 +1,10 @@
 package v1
 
-import base "github.com/actiontech/dms/pkg/dms-common/api/base/v1"
+import (
+	"fmt"
+
+	base "github.com/actiontech/dms/pkg/dms-common/api/base/v1"
+)
 
 // Use this struct to add a new session
 type AddSession struct {
@@ -63,3 +67,50 @@ type GetUserBySessionReply struct {
 	// Generic reply
 	base.GenericResp
 }
+
+// swagger:parameters ListLoginSessions
+type ListLoginSessionsReq struct {
+	// list sessions of the specified user, admin can list sessions of all users by leaving it empty,
+	// other users can only list their own sessions
+	// in:query
+	FilterByUserUid string `query:"filter_by_user_uid" json:"filter_by_user_uid"`
+}
+
+type LoginSession struct {
+	// login session uid
+	SessionUid string `json:"session_uid"`
+	UserUid    string `json:"user_uid"`
+	UserName   string `json:"user_name"`
+	// enum: ["password","oauth2"]
+	LoginType string `json:"login_type"`
+	// client ip of the latest request
+	IP         string `json:"ip"`
+	UserAgent  string `json:"user_agent"`
+	CreatedAt  string `json:"created_at" example:"RFC3339"`
+	LastSeenAt string `json:"last_seen_at" example:"RFC3339"`
+	ExpiredAt  string `json:"expired_at" example:"RFC3339"`
+	// whether the session is the one used by the current request
+	IsCurrent bool `json:"is_current"`
+}
+
+// swagger:model ListLoginSessionsReply
+type ListLoginSessionsReply struct {
+	Data []*LoginSession `json:"data"`
+
+	// Generic reply
+	base.GenericResp
+}
+
+// swagger:parameters RevokeLoginSession
+type RevokeLoginSessionReq struct {
+	// login session uid
+	// in:path
+	SessionUid string `param:"session_uid" json:"session_uid" validate:"required"`
+}
+
+func (r *RevokeLoginSessionReq) String() string {
+	if r == nil {
+		return "RevokeLoginSessionReq{nil}"
+	}
+	return fmt.Sprintf("RevokeLoginSessionReq{Uid:%s}", r.SessionUid)
+}
@@ -79,6 +79,62 @@ func (u *DelUserReq) String() string {
 	return fmt.Sprintf("DelUserReq{Uid:%s}", u.UserUid)
 }
 
+// swagger:parameters UnlockUser
+type UnlockUserReq struct {
+	// user uid
+	// in:path
+	UserUid string `param:"user_uid" json:"user_uid" validate:"required"`
+}
+
+func (u *UnlockUserReq) String() string {
+	if u == nil {
+		return "UnlockUserReq{nil}"
+	}
+	return fmt.Sprintf("UnlockUserReq{Uid:%s}", u.UserUid)
+}
+
+// A personal access token, the token itself is only returned when it is generated
+type ListAccessToken struct {
+	// access token uid
+	Uid string `json:"uid"`
+	// access token name
+	Name string `json:"name"`
+	// expired time
+	ExpiredTime string `json:"token_expired_timestamp" example:"RFC3339"`
+	IsExpired   bool   `json:"is_expired"`
+	// read-only access token can only be used for query requests
+	ReadOnly bool `json:"read_only"`
+	// the projects the access token is restricted to, empty means no restriction
+	ProjectUids []string `json:"project_uids"`
+	// last used time, empty if never used
+	LastUsedAt string `json:"last_used_at" example:"RFC3339"`
+	// last used client ip
+	LastUsedIP string `json:"last_used_ip"`
+	CreatedAt  string `json:"created_at" example:"RFC3339"`
+}
+
+// swagger:model ListAccessTokensReply
+type ListAccessTokensReply struct {
+	Data []*ListAccessToken `json:"data"`
+
+	// Generic reply
+	base.GenericResp
+}
+
+// swagger:parameters RevokeAccessToken
+type RevokeAccessTokenReq struct {
+	// access token uid
+	// in:path
+	AccessTokenUid string `param:"access_token_uid" json:"access_token_uid" validate:"required"`
+}
+
+func (u *RevokeAccessTokenReq) String() string {
+	if u == nil {
+		return "RevokeAccessTokenReq{nil}"
+	}
+	return fmt.Sprintf("RevokeAccessTokenReq{Uid:%s}", u.AccessTokenUid)
+}
+
 type UpdateUser struct {
 	// Whether the user is disabled or not
 	IsDisabled *bool `json:"is_disabled" validate:"required"`
@@ -183,6 +239,10 @@ type VerifyUserLoginReply struct {
 		UserUid          string `json:"user_uid"`
 		Phone            string `json:"phone"`
 		TwoFactorEnabled bool   `json:"two_factor_enabled"`
+		// The account is locked because of too many failed login attempts
+		AccountLocked bool `json:"account_locked"`
+		// The password has expired and must be changed after login
+		PasswordExpired bool `json:"password_expired"`
 	} `json:"data"`
 }
 
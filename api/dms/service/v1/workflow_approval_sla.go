@@ -0,0 +1,53 @@
+package v1
+
+import (
+	base "github.com/actiontech/dms/pkg/dms-common/api/base/v1"
+)
+
+// workflow approval sla of a project, counted from the time the current approval step starts waiting, 0 means disabled
+type WorkflowApprovalSLA struct {
+	// remind the approvers of the current step after the hours
+	// example: 4
+	RemindAfterHours int `json:"remind_after_hours" validate:"min=0"`
+	// notify project admins after the hours
+	// example: 24
+	EscalateAfterHours int `json:"escalate_after_hours" validate:"min=0"`
+	// reject the workflow automatically after the hours
+	// example: 72
+	AutoRejectAfterHours int `json:"auto_reject_after_hours" validate:"min=0"`
+}
+
+// swagger:model
+type SetWorkflowApprovalSLAReq struct {
+	// swagger:ignore
+	ProjectUid string `param:"project_uid" json:"project_uid" validate:"required"`
+	// all hours 0 removes the sla of the project
+	ApprovalSLA *WorkflowApprovalSLA `json:"approval_sla" validate:"required"`
+}
+
+// swagger:parameters GetWorkflowApprovalSLA
+type GetWorkflowApprovalSLAReq struct {
+	// project id
+	// Required: true
+	// in:path
+	ProjectUid string `param:"project_uid" json:"project_uid" validate:"required"`
+}
+
+// swagger:model GetWorkflowApprovalSLAReply
+type GetWorkflowApprovalSLAReply struct {
+	Data *WorkflowApprovalSLA `json:"data"`
+
+	// Generic reply
+	base.GenericResp
+}
+
+// swagger:enum WorkflowApprovalSLAStatus
+type WorkflowApprovalSLAStatus string
+
+const (
+	WorkflowApprovalSLAStatusOnTrack      WorkflowApprovalSLAStatus = "on_track"
+	WorkflowApprovalSLAStatusReminded     WorkflowApprovalSLAStatus = "reminded"
+	WorkflowApprovalSLAStatusEscalated    WorkflowApprovalSLAStatus = "escalated"
+	WorkflowApprovalSLAStatusOverdue      WorkflowApprovalSLAStatus = "overdue"
+	WorkflowApprovalSLAStatusAutoRejected WorkflowApprovalSLAStatus = "auto_rejected"
+)
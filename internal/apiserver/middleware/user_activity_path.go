@@ -23,8 +23,8 @@ type modulePrefixRule struct {
 }
 
 var defaultModulePrefixRules = []modulePrefixRule{
-	{ModuleCode: "AUTH", Prefixes: []string{"/v1/dms/sessions", "/v1/dms/oauth2/", "/v1/dms/users/verify_user_login", "/v1/dms/configurations/login"}},
-	{ModuleCode: "USER_ROLE", Prefixes: []string{"/v1/dms/users", "/v1/dms/user_groups", "/v1/dms/roles", "/v1/dms/op_permissions", "/sqle/v1/user_tips", "/sqle/v2/user_tips", "/sqle/v3/user_tips"}},
+	{ModuleCode: "AUTH", Prefixes: []string{"/v1/dms/sessions", "/v1/dms/oauth2/", "/v1/dms/users/verify_user_login", "/v1/dms/users/verify_access_token", "/v1/dms/service_accounts/token", "/v1/dms/configurations/login"}},
+	{ModuleCode: "USER_ROLE", Prefixes: []string{"/v1/dms/users", "/v1/dms/service_accounts", "/v1/dms/user_groups", "/v1/dms/roles", "/v1/dms/op_permissions", "/v1/dms/permissions", "/sqle/v1/user_tips", "/sqle/v2/user_tips", "/sqle/v3/user_tips"}},
 	{ModuleCode: "PROJECT", Prefixes: []string{"/v1/dms/projects", "/v2/dms/projects", "/sqle/v1/projects/", "/sqle/v2/projects/", "/sqle/v3/projects/"}},
 	{ModuleCode: "DB_SERVICE", Prefixes: []string{"/v1/dms/db_services", "/v2/dms/db_services", "/v1/dms/projects/", "/v2/dms/projects/", "/v1/dms/db_service_sync_tasks", "/sqle/v1/projects/", "/sqle/v2/projects/", "/sqle/v3/projects/"}},
 	{ModuleCode: "WORKFLOW", Prefixes: []string{"/sqle/v1/projects/", "/sqle/v2/projects/", "/sqle/v3/projects/", "/sqle/v1/dashboard/workflows", "/sqle/v2/dashboard/workflows", "/sqle/v1/workflows/"}},
@@ -207,6 +207,9 @@ func resolveModuleCode(normalizedPath string) string {
 	}
 	if strings.Contains(normalizedPath, "/members") ||
 		strings.Contains(normalizedPath, "/member_groups") ||
+		strings.Contains(normalizedPath, "/member_access_requests") ||
+		strings.Contains(normalizedPath, "/separation_of_duties_") ||
+		strings.Contains(normalizedPath, "/access_review_campaigns") ||
 		strings.Contains(normalizedPath, "/business_tags") ||
 		(strings.Contains(normalizedPath, "/projects/") && strings.Contains(normalizedPath, "/statistic")) {
 		return "PROJECT"
@@ -1,6 +1,7 @@
 package service
 
 import (
+	"bytes"
 	"fmt"
 	"io"
 	"net/http"
@@ -8,6 +9,7 @@ import (
 	"net/url"
 	"strings"
 	"testing"
+	"time"
 
 	utilLog "github.com/actiontech/dms/pkg/dms-common/pkg/log"
 	"github.com/labstack/echo/v4"
@@ -155,3 +157,172 @@ func TestProxyDownloadDataExportTaskUnreachable(t *testing.T) {
 		t.Fatalf("error message = %q, want contain %q", msg, "could not forward")
 	}
 }
+
+func TestParseResumeRange(t *testing.T) {
+	const etag = `"abc"`
+	tests := []struct {
+		name       string
+		rng        string
+		ifRange    string
+		wantOffset int64
+		want       bool
+	}{
+		{name: "no range", ifRange: etag},
+		{name: "from start", rng: "bytes=0-", ifRange: etag},
+		{name: "resume", rng: "bytes=1024-", ifRange: etag, wantOffset: 1024, want: true},
+		{name: "resume without if-range", rng: "bytes=1024-"},
+		{name: "closed range", rng: "bytes=1024-2047", ifRange: etag},
+		{name: "suffix", rng: "bytes=-500", ifRange: etag},
+		{name: "multi ranges", rng: "bytes=1024-,0-10", ifRange: etag},
+		{name: "unknown unit", rng: "items=1-", ifRange: etag},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			if tt.rng != "" {
+				req.Header.Set(headerRange, tt.rng)
+			}
+			if tt.ifRange != "" {
+				req.Header.Set(headerIfRange, tt.ifRange)
+			}
+			offset, ifRange, ok := parseResumeRange(req)
+			if ok != tt.want || offset != tt.wantOffset {
+				t.Fatalf("parseResumeRange() = %d, %v, want %d, %v", offset, ok, tt.wantOffset, tt.want)
+			}
+			if ok && ifRange != etag {
+				t.Fatalf("if-range = %q, want %q", ifRange, etag)
+			}
+		})
+	}
+}
+
+func TestParseDownloadETag(t *testing.T) {
+	checksum, fingerprint, ok := parseDownloadETag(downloadETag("abc", ""))
+	if !ok || checksum != "abc" || fingerprint != "" {
+		t.Fatalf("parseDownloadETag() = %q, %q, %v", checksum, fingerprint, ok)
+	}
+	checksum, fingerprint, ok = parseDownloadETag(downloadETag("abc", "0123456789abcdef"))
+	if !ok || checksum != "abc" || fingerprint != "0123456789abcdef" {
+		t.Fatalf("parseDownloadETag() = %q, %q, %v", checksum, fingerprint, ok)
+	}
+	for _, etag := range []string{"", `W/"abc"`, `"`, `""`, "Mon, 02 Jan 2006 15:04:05 GMT"} {
+		if _, _, ok := parseDownloadETag(etag); ok {
+			t.Fatalf("parseDownloadETag(%q) should fail", etag)
+		}
+	}
+}
+
+func TestContentRangeStart(t *testing.T) {
+	tests := map[string]int64{
+		"":                  0,
+		"bytes 4-9/10":      4,
+		"bytes */10":        0,
+		"bytes 0-9/10":      0,
+		"bytes 1024-2047/*": 1024,
+	}
+	for contentRange, want := range tests {
+		if got := contentRangeStart(contentRange); got != want {
+			t.Fatalf("contentRangeStart(%q) = %d, want %d", contentRange, got, want)
+		}
+	}
+}
+
+func TestServeDownloadRange(t *testing.T) {
+	const body = "0123456789"
+	const checksum = "84d89877f0d4041efb6bf91a16f0248f2fd573e6af05c19f96bedb9f882f7882"
+	serve := func(header http.Header) *httptest.ResponseRecorder {
+		e := echo.New()
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		for k, v := range header {
+			req.Header[k] = v
+		}
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+		if _, err := setDownloadChecksumHeaders(c, checksum); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		http.ServeContent(c.Response(), c.Request(), "export.zip", time.Time{}, bytes.NewReader([]byte(body)))
+		return rec
+	}
+
+	rec := serve(http.Header{headerRange: {"bytes=4-"}, headerIfRange: {`"` + checksum + `"`}})
+	if rec.Code != http.StatusPartialContent || rec.Body.String() != "456789" {
+		t.Fatalf("status = %d body = %q, want partial content", rec.Code, rec.Body.String())
+	}
+	if got := rec.Header().Get(headerReprDigest); got != "sha-256=:hNiYd/DUBB77a/kaFvAkjy/Vc+avBcGflr7bn4gveII=:" {
+		t.Fatalf("repr-digest = %q", got)
+	}
+
+	// ETag 变化后返回完整文件
+	rec = serve(http.Header{headerRange: {"bytes=4-"}, headerIfRange: {`"stale"`}})
+	if rec.Code != http.StatusOK || rec.Body.String() != body {
+		t.Fatalf("status = %d body = %q, want full content", rec.Code, rec.Body.String())
+	}
+	if rec.Header().Get(headerETag) != `"`+checksum+`"` {
+		t.Fatalf("etag = %q", rec.Header().Get(headerETag))
+	}
+}
+
+func TestServeWatermarkedDownloadRange(t *testing.T) {
+	const checksum = "84d89877f0d4041efb6bf91a16f0248f2fd573e6af05c19f96bedb9f882f7882"
+	const fingerprint = "0123456789abcdef"
+	serve := func(header http.Header) *httptest.ResponseRecorder {
+		e := echo.New()
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		for k, v := range header {
+			req.Header[k] = v
+		}
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+		if err := setDataExportDownloadHeaders(c, checksum, fingerprint, 10, time.Time{}); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		http.ServeContent(c.Response(), c.Request(), "export.zip", time.Time{}, bytes.NewReader([]byte("0123456789")))
+		return rec
+	}
+
+	etag := downloadETag(checksum, fingerprint)
+	rec := serve(http.Header{headerRange: {"bytes=4-"}, headerIfRange: {etag}})
+	if rec.Code != http.StatusPartialContent || rec.Header().Get(headerETag) != etag {
+		t.Fatalf("status = %d etag = %q, want partial content", rec.Code, rec.Header().Get(headerETag))
+	}
+	// 内容因下载指纹而异，不发布文件的校验值
+	if rec.Header().Get(headerReprDigest) != "" {
+		t.Fatalf("repr-digest = %q, want empty", rec.Header().Get(headerReprDigest))
+	}
+	// 其他下载指纹的 If-Range 返回完整内容
+	rec = serve(http.Header{headerRange: {"bytes=4-"}, headerIfRange: {downloadETag(checksum, "fedcba9876543210")}})
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want full content", rec.Code)
+	}
+}
+
+func TestSetDataExportDownloadHeadersWithoutChecksum(t *testing.T) {
+	newContext := func() echo.Context {
+		return echo.New().NewContext(httptest.NewRequest(http.MethodGet, "/", nil), httptest.NewRecorder())
+	}
+	modTime := time.Unix(0, 0x10)
+
+	// 校验值未计算时以文件大小及修改时间作为弱 ETag，不发布校验值
+	c := newContext()
+	if err := setDataExportDownloadHeaders(c, "", "", 10, modTime); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := c.Response().Header().Get(headerETag); got != `W/"a-10"` {
+		t.Fatalf("etag = %q", got)
+	}
+	if got := c.Response().Header().Get(headerReprDigest); got != "" {
+		t.Fatalf("repr-digest = %q, want empty", got)
+	}
+	if _, _, ok := parseDownloadETag(`W/"a-10"`); ok {
+		t.Fatalf("weak etag should not be accepted for resuming")
+	}
+
+	c = newContext()
+	if err := setDataExportDownloadHeaders(c, "", "0123456789abcdef", 10, time.Time{}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := c.Response().Header().Get(headerETag); got != "" {
+		t.Fatalf("etag = %q, want empty", got)
+	}
+}
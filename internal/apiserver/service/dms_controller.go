@@ -3,7 +3,10 @@ package service
 import (
 	"bytes"
 	"context"
+	"crypto/sha256"
 	"crypto/tls"
+	"encoding/base64"
+	"encoding/hex"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -13,6 +16,7 @@ import (
 	"net/http/httputil"
 	"net/url"
 	"path/filepath"
+	"strconv"
 	"strings"
 	"time"
 	"unicode/utf8"
@@ -31,6 +35,7 @@ import (
 
 	dmsV1 "github.com/actiontech/dms/pkg/dms-common/api/dms/v1"
 	"github.com/actiontech/dms/pkg/dms-common/api/jwt"
+	"github.com/actiontech/dms/pkg/dms-common/i18nPkg"
 
 	utilLog "github.com/actiontech/dms/pkg/dms-common/pkg/log"
 
@@ -697,10 +702,34 @@ func (ctl *DMSController) AddSession(c echo.Context) error {
 		return NewErrResp(c, err, apiError.DMSServiceErr)
 	}
 	if reply.Data.VerifyFailedMsg != "" {
+		if reply.Data.AccountLocked {
+			// 记录因登录失败次数过多被锁定而拒绝的登录
+			recordReq := &aV1.AddOperationRecordReq{
+				OperationRecord: &aV1.OperationRecord{
+					OperationTime:        time.Now(),
+					OperationUserName:    req.Session.UserName,
+					OperationReqIP:       c.RealIP(),
+					OperationUserAgent:   c.Request().UserAgent(),
+					OperationTypeName:    "user",
+					OperationAction:      "login_locked",
+					OperationProjectName: "",
+					OperationStatus:      "failed",
+					OperationI18nContent: locale.Bundle.LocalizeAllWithArgs(locale.OpRecordUserLoginLockedWithName, req.Session.UserName),
+				},
+			}
+			if _, err := ctl.DMS.AddOperationRecord(c.Request().Context(), recordReq); err != nil {
+				ctl.log.Errorf("failed to save login locked operation record: %v, operation_record: user_name=%s, ip=%s, user_agent=%s, action=login_locked",
+					err, req.Session.UserName, c.RealIP(), c.Request().UserAgent())
+			}
+		}
 		return NewErrResp(c, errors.New(reply.Data.VerifyFailedMsg), apiError.BadRequestErr)
 	}
 
-	loginSessionID, err := ctl.DMS.UserUsecase.CreateLoginSession(c.Request().Context(), reply.Data.UserUid)
+	loginSessionID, err := ctl.DMS.UserUsecase.CreateLoginSession(c.Request().Context(), reply.Data.UserUid, &biz.LoginSessionMeta{
+		LoginType: biz.LoginSessionTypePassword,
+		IP:        c.RealIP(),
+		UserAgent: c.Request().UserAgent(),
+	})
 	if nil != err {
 		return NewErrResp(c, err, apiError.APIServerErr)
 	}
@@ -760,12 +789,18 @@ func (ctl *DMSController) AddSession(c echo.Context) error {
 		}
 	}
 
+	var message string
+	if reply.Data.PasswordExpired {
+		message = biz.ErrPasswordExpired.Error()
+	}
+
 	return NewOkRespWithReply(c, &aV1.AddSessionReply{
 		Data: struct {
 			Token string `json:"token"`
 			Message string `json:"message"`
 		}{
 			Token: token,
+			Message: message,
 		},
 	})
 }
@@ -794,6 +829,12 @@ func (ctl *DMSController) DelSession(c echo.Context) error {
 				return NewErrResp(c, err, apiError.DMSServiceErr)
 			}
 		}
+		// dms token 过期时仍可通过 refresh token 结束当前登录会话
+		if loginSessionID, err := jwt.ParseLoginSessionIDFromRefreshToken(refreshToken.Value); err == nil {
+			if err := ctl.DMS.UserUsecase.EndLoginSession(c.Request().Context(), loginSessionID); err != nil {
+				ctl.log.Errorf("DelSession end login session failed: %v", err)
+			}
+		}
 	}
 
 	var userUid string
@@ -807,9 +848,12 @@ func (ctl *DMSController) DelSession(c echo.Context) error {
 		c.SetCookie(cookie)
 		ctl.CloudbeaverService.Logout(cookie.Value)
 		
-		// 从token中获取用户uid
-		if uid, err := jwt.ParseUidFromJwtTokenStr(cookie.Value); err == nil {
-			userUid = uid
+		// 从token中获取用户uid，并结束当前登录会话
+		if tokenDetail, err := jwt.ParseTokenDetailFromJwtTokenStr(cookie.Value); err == nil {
+			userUid = tokenDetail.UID
+			if err := ctl.DMS.UserUsecase.EndLoginSession(c.Request().Context(), tokenDetail.LoginSessionID); err != nil {
+				ctl.log.Errorf("DelSession end login session failed: %v", err)
+			}
 		}
 	}
 
@@ -892,6 +936,9 @@ func (ctl *DMSController) RefreshSession(c echo.Context) error {
 	if err = ctl.DMS.UserUsecase.ValidateLoginSession(c.Request().Context(), uid, loginSessionID); err != nil {
 		return c.String(http.StatusUnauthorized, err.Error())
 	}
+	if err = ctl.DMS.UserUsecase.RenewLoginSession(c.Request().Context(), loginSessionID); err != nil {
+		ctl.log.Errorf("renew login session %s failed: %v", loginSessionID, err)
+	}
 
 	// 签发的token包含第三方平台信息，需要同步刷新第三方平台token
 	if sub != "" || sid != "" {
@@ -1131,6 +1178,129 @@ func (ctl *DMSController) DelUser(c echo.Context) error {
 	return NewOkResp(c)
 }
 
+// swagger:route POST /v1/dms/users/{user_uid}/unlock User UnlockUser
+//
+// Unlock a user locked by too many failed login attempts.
+//
+//	responses:
+//	  200: body:GenericResp
+//	  default: body:GenericResp
+func (ctl *DMSController) UnlockUser(c echo.Context) error {
+	req := &aV1.UnlockUserReq{}
+	err := bindAndValidateReq(c, req)
+	if nil != err {
+		return NewErrResp(c, err, apiError.BadRequestErr)
+	}
+
+	// get current user id
+	currentUserUid, err := jwt.GetUserUidStrFromContext(c)
+	if err != nil {
+		return NewErrResp(c, err, apiError.DMSServiceErr)
+	}
+
+	err = ctl.DMS.UnlockUser(c.Request().Context(), currentUserUid, req)
+	if nil != err {
+		return NewErrResp(c, err, apiError.DMSServiceErr)
+	}
+
+	// 记录解锁操作
+	if currentUser, err := ctl.DMS.UserUsecase.GetUser(c.Request().Context(), currentUserUid); err == nil {
+		if user, err := ctl.DMS.UserUsecase.GetUser(c.Request().Context(), req.UserUid); err == nil {
+			recordReq := &aV1.AddOperationRecordReq{
+				OperationRecord: &aV1.OperationRecord{
+					OperationTime:        time.Now(),
+					OperationUserName:    currentUser.Name,
+					OperationReqIP:       c.RealIP(),
+					OperationUserAgent:   c.Request().UserAgent(),
+					OperationTypeName:    "user",
+					OperationAction:      "unlock",
+					OperationProjectName: "",
+					OperationStatus:      "succeeded",
+					OperationI18nContent: locale.Bundle.LocalizeAllWithArgs(locale.OpRecordUserUnlockWithName, user.Name),
+				},
+			}
+			if _, err := ctl.DMS.AddOperationRecord(c.Request().Context(), recordReq); err != nil {
+				ctl.log.Errorf("failed to save unlock operation record: %v, operation_record: user_name=%s, ip=%s, user_agent=%s, action=unlock",
+					err, currentUser.Name, c.RealIP(), c.Request().UserAgent())
+			}
+		}
+	}
+	return NewOkResp(c)
+}
+
+// swagger:route GET /v1/dms/sessions/login_sessions Session ListLoginSessions
+//
+// List active login sessions.
+//
+//	responses:
+//	  200: body:ListLoginSessionsReply
+//	  default: body:GenericResp
+func (ctl *DMSController) ListLoginSessions(c echo.Context) error {
+	req := new(aV1.ListLoginSessionsReq)
+	err := bindAndValidateReq(c, req)
+	if nil != err {
+		return NewErrResp(c, err, apiError.BadRequestErr)
+	}
+
+	tokenDetail, err := jwt.GetTokenDetailFromContext(c)
+	if err != nil {
+		return NewErrResp(c, err, apiError.DMSServiceErr)
+	}
+
+	reply, err := ctl.DMS.ListLoginSessions(c.Request().Context(), tokenDetail.UID, tokenDetail.LoginSessionID, req)
+	if nil != err {
+		return NewErrResp(c, err, apiError.DMSServiceErr)
+	}
+	return NewOkRespWithReply(c, reply)
+}
+
+// swagger:route DELETE /v1/dms/sessions/login_sessions/{session_uid} Session RevokeLoginSession
+//
+// Revoke a login session, requests with the session's token will be rejected.
+//
+//	responses:
+//	  200: body:GenericResp
+//	  default: body:GenericResp
+func (ctl *DMSController) RevokeLoginSession(c echo.Context) error {
+	req := new(aV1.RevokeLoginSessionReq)
+	err := bindAndValidateReq(c, req)
+	if nil != err {
+		return NewErrResp(c, err, apiError.BadRequestErr)
+	}
+
+	// get current user id
+	currentUserUid, err := jwt.GetUserUidStrFromContext(c)
+	if err != nil {
+		return NewErrResp(c, err, apiError.DMSServiceErr)
+	}
+
+	userName, err := ctl.DMS.RevokeLoginSession(c.Request().Context(), currentUserUid, req)
+	if nil != err {
+		return NewErrResp(c, err, apiError.DMSServiceErr)
+	}
+
+	// 记录吊销会话操作
+	if currentUser, err := ctl.DMS.UserUsecase.GetUser(c.Request().Context(), currentUserUid); err == nil {
+		recordReq := &aV1.AddOperationRecordReq{
+			OperationRecord: &aV1.OperationRecord{
+				OperationTime:        time.Now(),
+				OperationUserName:    currentUser.Name,
+				OperationReqIP:       c.RealIP(),
+				OperationUserAgent:   c.Request().UserAgent(),
+				OperationTypeName:    "user",
+				OperationAction:      "revoke_login_session",
+				OperationProjectName: "",
+				OperationStatus:      "succeeded",
+				OperationI18nContent: locale.Bundle.LocalizeAllWithArgs(locale.OpRecordUserRevokeLoginSessionWithName, userName),
+			},
+		}
+		if _, err := ctl.DMS.AddOperationRecord(c.Request().Context(), recordReq); err != nil {
+			ctl.log.Errorf("failed to save revoke login session operation record: %v, operation_record: user_name=%s, ip=%s, user_agent=%s, action=revoke_login_session",
+				err, currentUser.Name, c.RealIP(), c.Request().UserAgent())
+		}
+	}
+	return NewOkResp(c)
+}
 
 // swagger:route GET /v1/dms/users User ListUsers
 //
@@ -1173,6 +1343,27 @@ func (ctl *DMSController) GetUserOpPermission(c echo.Context) error {
 	return NewOkRespWithReply(c, reply)
 }
 
+// swagger:route POST /v1/dms/users/{user_uid}/db_objects_op_permission/check User CheckUserDBObjectsOpPermission
+//
+// Check whether the user can op the objects of a db service, This API is used by other component such as sqle to enforce
+// database, schema and table op ranges with the objects parsed from sql.
+//
+//	responses:
+//	  200: body:CheckUserDBObjectsOpPermissionReply
+//	  default: body:GenericResp
+func (ctl *DMSController) CheckUserDBObjectsOpPermission(c echo.Context) error {
+	req := new(dmsV1.CheckUserDBObjectsOpPermissionReq)
+	err := bindAndValidateReq(c, req)
+	if nil != err {
+		return NewErrResp(c, err, apiError.BadRequestErr)
+	}
+	reply, err := ctl.DMS.CheckUserDBObjectsOpPermission(c.Request().Context(), req)
+	if nil != err {
+		return NewErrResp(c, err, apiError.DMSServiceErr)
+	}
+	return NewOkRespWithReply(c, reply)
+}
+
 // swagger:route GET /v1/dms/users/{user_uid} User GetUser
 //
 // Get user info, This API is used by other component such as sqle&auth to get user info.
@@ -1233,102 +1424,141 @@ func (ctl *DMSController) GenAccessToken(c echo.Context) error {
 	return NewOkRespWithReply(c, reply)
 }
 
-// swagger:operation POST /v1/dms/user_groups UserGroup AddUserGroup
+// swagger:route GET /v1/dms/users/access_tokens User ListAccessTokens
 //
-// Add user group.
+// List access tokens of current user.
 //
-// ---
-// parameters:
-//   - name: user_group
-//     description: Add new user group
-//     in: body
-//     required: true
-//     schema:
-//       "$ref": "#/definitions/AddUserGroupReq"
-// responses:
-//   '200':
-//     description: AddUserGroupReply
-//     schema:
-//       "$ref": "#/definitions/AddUserGroupReply"
-//   default:
-//     description: GenericResp
-//     schema:
-//       "$ref": "#/definitions/GenericResp"
-func (ctl *DMSController) AddUserGroup(c echo.Context) error {
-	req := new(aV1.AddUserGroupReq)
+//	responses:
+//	  200: body:ListAccessTokensReply
+//	  default: body:GenericResp
+func (ctl *DMSController) ListAccessTokens(c echo.Context) error {
+	// get current user id
+	currentUid, err := jwt.GetUserUidStrFromContext(c)
+	if err != nil {
+		return NewErrResp(c, err, apiError.DMSServiceErr)
+	}
+
+	reply, err := ctl.DMS.ListAccessTokens(c.Request().Context(), currentUid)
+	if nil != err {
+		return NewErrResp(c, err, apiError.DMSServiceErr)
+	}
+	return NewOkRespWithReply(c, reply)
+}
+
+// swagger:route DELETE /v1/dms/users/access_tokens/{access_token_uid} User RevokeAccessToken
+//
+// Revoke an access token.
+//
+//	responses:
+//	  200: body:GenericResp
+//	  default: body:GenericResp
+func (ctl *DMSController) RevokeAccessToken(c echo.Context) error {
+	req := &aV1.RevokeAccessTokenReq{}
 	err := bindAndValidateReq(c, req)
 	if nil != err {
 		return NewErrResp(c, err, apiError.BadRequestErr)
 	}
 
 	// get current user id
-	currentUserUid, err := jwt.GetUserUidStrFromContext(c)
+	currentUid, err := jwt.GetUserUidStrFromContext(c)
 	if err != nil {
 		return NewErrResp(c, err, apiError.DMSServiceErr)
 	}
 
-	reply, err := ctl.DMS.AddUserGroup(c.Request().Context(), currentUserUid, req)
+	err = ctl.DMS.RevokeAccessToken(c.Request().Context(), currentUid, req)
 	if nil != err {
 		return NewErrResp(c, err, apiError.DMSServiceErr)
 	}
-	return NewOkRespWithReply(c, reply)
+	return NewOkResp(c)
 }
 
-// swagger:operation PUT /v1/dms/user_groups/{user_group_uid} UserGroup UpdateUserGroup
+// swagger:operation POST /v1/dms/users/verify_access_token User VerifyAccessToken
 //
-// Update a user group.
+// Verify access token.
 //
 // ---
 // parameters:
-//   - name: user_group_uid
-//     description: UserGroup uid
-//     in: path
-//     required: true
-//     type: string
-//   - name: user_group
-//     description: Update a user group
-//     required: true
+//   - name: access_token
 //     in: body
+//     required: true
 //     schema:
-//       "$ref": "#/definitions/UpdateUserGroupReq"
+//       "$ref": "#/definitions/VerifyAccessTokenReq"
 // responses:
 //   '200':
-//     description: GenericResp
+//     description: VerifyAccessTokenReply
 //     schema:
-//       "$ref": "#/definitions/GenericResp"
+//       "$ref": "#/definitions/VerifyAccessTokenReply"
 //   default:
 //     description: GenericResp
 //     schema:
 //       "$ref": "#/definitions/GenericResp"
-func (ctl *DMSController) UpdateUserGroup(c echo.Context) error {
-	req := new(aV1.UpdateUserGroupReq)
+func (ctl *DMSController) VerifyAccessToken(c echo.Context) error {
+	req := new(dmsV1.VerifyAccessTokenReq)
 	err := bindAndValidateReq(c, req)
 	if nil != err {
 		return NewErrResp(c, err, apiError.BadRequestErr)
 	}
 
-	// get current user id
-	currentUserUid, err := jwt.GetUserUidStrFromContext(c)
-	if err != nil {
+	reply, err := ctl.DMS.VerifyAccessToken(c.Request().Context(), req)
+	if nil != err {
 		return NewErrResp(c, err, apiError.DMSServiceErr)
 	}
+	return NewOkRespWithReply(c, reply)
+}
 
-	err = ctl.DMS.UpdateUserGroup(c.Request().Context(), currentUserUid, req)
-	if nil != err {
-		return NewErrResp(c, err, apiError.DMSServiceErr)
+// recordServiceAccountOperation 记录服务账号相关操作，operatorName 为服务账号自身时即为服务账号的审计记录
+func (ctl *DMSController) recordServiceAccountOperation(c echo.Context, operatorName, action string, content i18nPkg.I18nStr) {
+	recordReq := &aV1.AddOperationRecordReq{
+		OperationRecord: &aV1.OperationRecord{
+			OperationTime:        time.Now(),
+			OperationUserName:    operatorName,
+			OperationReqIP:       c.RealIP(),
+			OperationUserAgent:   c.Request().UserAgent(),
+			OperationTypeName:    "service_account",
+			OperationAction:      action,
+			OperationProjectName: "",
+			OperationStatus:      "succeeded",
+			OperationI18nContent: content,
+		},
+	}
+	if _, err := ctl.DMS.AddOperationRecord(c.Request().Context(), recordReq); err != nil {
+		ctl.log.Errorf("failed to save service account operation record: %v, operation_record: user_name=%s, ip=%s, user_agent=%s, action=%s",
+			err, operatorName, c.RealIP(), c.Request().UserAgent(), action)
 	}
-	return NewOkResp(c)
 }
 
-// swagger:route DELETE /v1/dms/user_groups/{user_group_uid} UserGroup DelUserGroup
+func (ctl *DMSController) getCurrentUserName(c echo.Context, currentUserUid string) string {
+	currentUser, err := ctl.DMS.UserUsecase.GetUser(c.Request().Context(), currentUserUid)
+	if err != nil {
+		ctl.log.Errorf("failed to get current user %s: %v", currentUserUid, err)
+		return ""
+	}
+	return currentUser.Name
+}
+
+// swagger:operation POST /v1/dms/service_accounts ServiceAccount AddServiceAccount
 //
-// Delete a user group.
+// Add a service account, the client secret is only returned once.
 //
-//	responses:
-//	  200: body:GenericResp
-//	  default: body:GenericResp
-func (ctl *DMSController) DelUserGroup(c echo.Context) error {
-	req := &aV1.DelUserGroupReq{}
+// ---
+// parameters:
+//   - name: service_account
+//     description: Add new service account
+//     in: body
+//     required: true
+//     schema:
+//       "$ref": "#/definitions/AddServiceAccountReq"
+// responses:
+//   '200':
+//     description: ServiceAccountCredentialReply
+//     schema:
+//       "$ref": "#/definitions/ServiceAccountCredentialReply"
+//   default:
+//     description: GenericResp
+//     schema:
+//       "$ref": "#/definitions/GenericResp"
+func (ctl *DMSController) AddServiceAccount(c echo.Context) error {
+	req := new(aV1.AddServiceAccountReq)
 	err := bindAndValidateReq(c, req)
 	if nil != err {
 		return NewErrResp(c, err, apiError.BadRequestErr)
@@ -1340,57 +1570,64 @@ func (ctl *DMSController) DelUserGroup(c echo.Context) error {
 		return NewErrResp(c, err, apiError.DMSServiceErr)
 	}
 
-	err = ctl.DMS.DelUserGroup(c.Request().Context(), currentUserUid, req)
+	reply, err := ctl.DMS.AddServiceAccount(c.Request().Context(), currentUserUid, req)
 	if nil != err {
 		return NewErrResp(c, err, apiError.DMSServiceErr)
 	}
-	return NewOkResp(c)
+	ctl.recordServiceAccountOperation(c, ctl.getCurrentUserName(c, currentUserUid), "create",
+		locale.Bundle.LocalizeAllWithArgs(locale.OpRecordServiceAccountCreateWithName, req.ServiceAccount.Name))
+	return NewOkRespWithReply(c, reply)
 }
 
-// swagger:route GET /v1/dms/user_groups UserGroup ListUserGroups
+// swagger:route GET /v1/dms/service_accounts ServiceAccount ListServiceAccounts
 //
-// List user groups.
+// List service accounts.
 //
 //	responses:
-//	  200: body:ListUserGroupReply
+//	  200: body:ListServiceAccountsReply
 //	  default: body:GenericResp
-func (ctl *DMSController) ListUserGroups(c echo.Context) error {
-	req := new(aV1.ListUserGroupReq)
-	err := bindAndValidateReq(c, req)
-	if nil != err {
-		return NewErrResp(c, err, apiError.BadRequestErr)
+func (ctl *DMSController) ListServiceAccounts(c echo.Context) error {
+	// get current user id
+	currentUserUid, err := jwt.GetUserUidStrFromContext(c)
+	if err != nil {
+		return NewErrResp(c, err, apiError.DMSServiceErr)
 	}
 
-	reply, err := ctl.DMS.ListUserGroups(c.Request().Context(), req)
+	reply, err := ctl.DMS.ListServiceAccounts(c.Request().Context(), currentUserUid)
 	if nil != err {
 		return NewErrResp(c, err, apiError.DMSServiceErr)
 	}
 	return NewOkRespWithReply(c, reply)
 }
 
-// swagger:operation POST /v1/dms/roles Role AddRole
+// swagger:operation PUT /v1/dms/service_accounts/{service_account_uid} ServiceAccount UpdateServiceAccount
 //
-// Add role.
+// Update a service account.
 //
 // ---
 // parameters:
-//   - name: role
-//     description: Add new role
+//   - name: service_account_uid
+//     description: service account uid
+//     in: path
+//     required: true
+//     type: string
+//   - name: service_account
+//     description: Update a service account
 //     in: body
 //     required: true
 //     schema:
-//       "$ref": "#/definitions/AddRoleReq"
+//       "$ref": "#/definitions/UpdateServiceAccountReq"
 // responses:
 //   '200':
-//     description: AddRoleReply
+//     description: GenericResp
 //     schema:
-//       "$ref": "#/definitions/AddRoleReply"
+//       "$ref": "#/definitions/GenericResp"
 //   default:
 //     description: GenericResp
 //     schema:
 //       "$ref": "#/definitions/GenericResp"
-func (ctl *DMSController) AddRole(c echo.Context) error {
-	req := new(aV1.AddRoleReq)
+func (ctl *DMSController) UpdateServiceAccount(c echo.Context) error {
+	req := new(aV1.UpdateServiceAccountReq)
 	err := bindAndValidateReq(c, req)
 	if nil != err {
 		return NewErrResp(c, err, apiError.BadRequestErr)
@@ -1402,21 +1639,300 @@ func (ctl *DMSController) AddRole(c echo.Context) error {
 		return NewErrResp(c, err, apiError.DMSServiceErr)
 	}
 
-	reply, err := ctl.DMS.AddRole(c.Request().Context(), currentUserUid, req)
+	err = ctl.DMS.UpdateServiceAccount(c.Request().Context(), currentUserUid, req)
 	if nil != err {
 		return NewErrResp(c, err, apiError.DMSServiceErr)
 	}
-	return NewOkRespWithReply(c, reply)
+	if name, err := ctl.DMS.GetServiceAccountName(c.Request().Context(), currentUserUid, req.ServiceAccountUid); err == nil {
+		ctl.recordServiceAccountOperation(c, ctl.getCurrentUserName(c, currentUserUid), "update",
+			locale.Bundle.LocalizeAllWithArgs(locale.OpRecordServiceAccountUpdateWithName, name))
+	}
+	return NewOkResp(c)
 }
 
-// swagger:operation PUT /v1/dms/roles/{role_uid} Role UpdateRole
+// swagger:route POST /v1/dms/service_accounts/{service_account_uid}/rotate_secret ServiceAccount RotateServiceAccountSecret
 //
-// Update a role.
+// Rotate the client secret of a service account, the old secret becomes invalid immediately.
 //
-// ---
-// parameters:
-//   - name: role_uid
-//     description: Role uid
+//	responses:
+//	  200: body:ServiceAccountCredentialReply
+//	  default: body:GenericResp
+func (ctl *DMSController) RotateServiceAccountSecret(c echo.Context) error {
+	req := new(aV1.ServiceAccountUidReq)
+	err := bindAndValidateReq(c, req)
+	if nil != err {
+		return NewErrResp(c, err, apiError.BadRequestErr)
+	}
+
+	// get current user id
+	currentUserUid, err := jwt.GetUserUidStrFromContext(c)
+	if err != nil {
+		return NewErrResp(c, err, apiError.DMSServiceErr)
+	}
+
+	reply, err := ctl.DMS.RotateServiceAccountSecret(c.Request().Context(), currentUserUid, req)
+	if nil != err {
+		return NewErrResp(c, err, apiError.DMSServiceErr)
+	}
+	if name, err := ctl.DMS.GetServiceAccountName(c.Request().Context(), currentUserUid, req.ServiceAccountUid); err == nil {
+		ctl.recordServiceAccountOperation(c, ctl.getCurrentUserName(c, currentUserUid), "rotate_secret",
+			locale.Bundle.LocalizeAllWithArgs(locale.OpRecordServiceAccountRotateSecretWithName, name))
+	}
+	return NewOkRespWithReply(c, reply)
+}
+
+// swagger:route DELETE /v1/dms/service_accounts/{service_account_uid} ServiceAccount DelServiceAccount
+//
+// Delete a service account.
+//
+//	responses:
+//	  200: body:GenericResp
+//	  default: body:GenericResp
+func (ctl *DMSController) DelServiceAccount(c echo.Context) error {
+	req := new(aV1.ServiceAccountUidReq)
+	err := bindAndValidateReq(c, req)
+	if nil != err {
+		return NewErrResp(c, err, apiError.BadRequestErr)
+	}
+
+	// get current user id
+	currentUserUid, err := jwt.GetUserUidStrFromContext(c)
+	if err != nil {
+		return NewErrResp(c, err, apiError.DMSServiceErr)
+	}
+
+	name, err := ctl.DMS.GetServiceAccountName(c.Request().Context(), currentUserUid, req.ServiceAccountUid)
+	if nil != err {
+		return NewErrResp(c, err, apiError.DMSServiceErr)
+	}
+	err = ctl.DMS.DelServiceAccount(c.Request().Context(), currentUserUid, req)
+	if nil != err {
+		return NewErrResp(c, err, apiError.DMSServiceErr)
+	}
+	ctl.recordServiceAccountOperation(c, ctl.getCurrentUserName(c, currentUserUid), "delete",
+		locale.Bundle.LocalizeAllWithArgs(locale.OpRecordServiceAccountDeleteWithName, name))
+	return NewOkResp(c)
+}
+
+// swagger:operation POST /v1/dms/service_accounts/token ServiceAccount IssueServiceAccountToken
+//
+// Issue a short-lived token with service account client credentials.
+//
+// ---
+// parameters:
+//   - name: client_credentials
+//     in: body
+//     required: true
+//     schema:
+//       "$ref": "#/definitions/IssueServiceAccountTokenReq"
+// responses:
+//   '200':
+//     description: IssueServiceAccountTokenReply
+//     schema:
+//       "$ref": "#/definitions/IssueServiceAccountTokenReply"
+//   default:
+//     description: GenericResp
+//     schema:
+//       "$ref": "#/definitions/GenericResp"
+func (ctl *DMSController) IssueServiceAccountToken(c echo.Context) error {
+	req := new(dmsV1.IssueServiceAccountTokenReq)
+	err := bindAndValidateReq(c, req)
+	if nil != err {
+		return NewErrResp(c, err, apiError.BadRequestErr)
+	}
+
+	reply, _, userName, err := ctl.DMS.IssueServiceAccountToken(c.Request().Context(), req)
+	if nil != err {
+		return NewErrResp(c, err, apiError.DMSServiceErr)
+	}
+	ctl.recordServiceAccountOperation(c, userName, "issue_token", locale.Bundle.LocalizeAll(locale.OpRecordServiceAccountIssueToken))
+	return NewOkRespWithReply(c, reply)
+}
+
+// swagger:operation POST /v1/dms/user_groups UserGroup AddUserGroup
+//
+// Add user group.
+//
+// ---
+// parameters:
+//   - name: user_group
+//     description: Add new user group
+//     in: body
+//     required: true
+//     schema:
+//       "$ref": "#/definitions/AddUserGroupReq"
+// responses:
+//   '200':
+//     description: AddUserGroupReply
+//     schema:
+//       "$ref": "#/definitions/AddUserGroupReply"
+//   default:
+//     description: GenericResp
+//     schema:
+//       "$ref": "#/definitions/GenericResp"
+func (ctl *DMSController) AddUserGroup(c echo.Context) error {
+	req := new(aV1.AddUserGroupReq)
+	err := bindAndValidateReq(c, req)
+	if nil != err {
+		return NewErrResp(c, err, apiError.BadRequestErr)
+	}
+
+	// get current user id
+	currentUserUid, err := jwt.GetUserUidStrFromContext(c)
+	if err != nil {
+		return NewErrResp(c, err, apiError.DMSServiceErr)
+	}
+
+	reply, err := ctl.DMS.AddUserGroup(c.Request().Context(), currentUserUid, req)
+	if nil != err {
+		return NewErrResp(c, err, apiError.DMSServiceErr)
+	}
+	return NewOkRespWithReply(c, reply)
+}
+
+// swagger:operation PUT /v1/dms/user_groups/{user_group_uid} UserGroup UpdateUserGroup
+//
+// Update a user group.
+//
+// ---
+// parameters:
+//   - name: user_group_uid
+//     description: UserGroup uid
+//     in: path
+//     required: true
+//     type: string
+//   - name: user_group
+//     description: Update a user group
+//     required: true
+//     in: body
+//     schema:
+//       "$ref": "#/definitions/UpdateUserGroupReq"
+// responses:
+//   '200':
+//     description: GenericResp
+//     schema:
+//       "$ref": "#/definitions/GenericResp"
+//   default:
+//     description: GenericResp
+//     schema:
+//       "$ref": "#/definitions/GenericResp"
+func (ctl *DMSController) UpdateUserGroup(c echo.Context) error {
+	req := new(aV1.UpdateUserGroupReq)
+	err := bindAndValidateReq(c, req)
+	if nil != err {
+		return NewErrResp(c, err, apiError.BadRequestErr)
+	}
+
+	// get current user id
+	currentUserUid, err := jwt.GetUserUidStrFromContext(c)
+	if err != nil {
+		return NewErrResp(c, err, apiError.DMSServiceErr)
+	}
+
+	err = ctl.DMS.UpdateUserGroup(c.Request().Context(), currentUserUid, req)
+	if nil != err {
+		return NewErrResp(c, err, apiError.DMSServiceErr)
+	}
+	return NewOkResp(c)
+}
+
+// swagger:route DELETE /v1/dms/user_groups/{user_group_uid} UserGroup DelUserGroup
+//
+// Delete a user group.
+//
+//	responses:
+//	  200: body:GenericResp
+//	  default: body:GenericResp
+func (ctl *DMSController) DelUserGroup(c echo.Context) error {
+	req := &aV1.DelUserGroupReq{}
+	err := bindAndValidateReq(c, req)
+	if nil != err {
+		return NewErrResp(c, err, apiError.BadRequestErr)
+	}
+
+	// get current user id
+	currentUserUid, err := jwt.GetUserUidStrFromContext(c)
+	if err != nil {
+		return NewErrResp(c, err, apiError.DMSServiceErr)
+	}
+
+	err = ctl.DMS.DelUserGroup(c.Request().Context(), currentUserUid, req)
+	if nil != err {
+		return NewErrResp(c, err, apiError.DMSServiceErr)
+	}
+	return NewOkResp(c)
+}
+
+// swagger:route GET /v1/dms/user_groups UserGroup ListUserGroups
+//
+// List user groups.
+//
+//	responses:
+//	  200: body:ListUserGroupReply
+//	  default: body:GenericResp
+func (ctl *DMSController) ListUserGroups(c echo.Context) error {
+	req := new(aV1.ListUserGroupReq)
+	err := bindAndValidateReq(c, req)
+	if nil != err {
+		return NewErrResp(c, err, apiError.BadRequestErr)
+	}
+
+	reply, err := ctl.DMS.ListUserGroups(c.Request().Context(), req)
+	if nil != err {
+		return NewErrResp(c, err, apiError.DMSServiceErr)
+	}
+	return NewOkRespWithReply(c, reply)
+}
+
+// swagger:operation POST /v1/dms/roles Role AddRole
+//
+// Add role.
+//
+// ---
+// parameters:
+//   - name: role
+//     description: Add new role
+//     in: body
+//     required: true
+//     schema:
+//       "$ref": "#/definitions/AddRoleReq"
+// responses:
+//   '200':
+//     description: AddRoleReply
+//     schema:
+//       "$ref": "#/definitions/AddRoleReply"
+//   default:
+//     description: GenericResp
+//     schema:
+//       "$ref": "#/definitions/GenericResp"
+func (ctl *DMSController) AddRole(c echo.Context) error {
+	req := new(aV1.AddRoleReq)
+	err := bindAndValidateReq(c, req)
+	if nil != err {
+		return NewErrResp(c, err, apiError.BadRequestErr)
+	}
+
+	// get current user id
+	currentUserUid, err := jwt.GetUserUidStrFromContext(c)
+	if err != nil {
+		return NewErrResp(c, err, apiError.DMSServiceErr)
+	}
+
+	reply, err := ctl.DMS.AddRole(c.Request().Context(), currentUserUid, req)
+	if nil != err {
+		return NewErrResp(c, err, apiError.DMSServiceErr)
+	}
+	return NewOkRespWithReply(c, reply)
+}
+
+// swagger:operation PUT /v1/dms/roles/{role_uid} Role UpdateRole
+//
+// Update a role.
+//
+// ---
+// parameters:
+//   - name: role_uid
+//     description: Role uid
 //     in: path
 //     required: true
 //     type: string
@@ -1425,7 +1941,669 @@ func (ctl *DMSController) AddRole(c echo.Context) error {
 //     required: true
 //     in: body
 //     schema:
-//       "$ref": "#/definitions/UpdateRoleReq"
+//       "$ref": "#/definitions/UpdateRoleReq"
+// responses:
+//   '200':
+//     description: GenericResp
+//     schema:
+//       "$ref": "#/definitions/GenericResp"
+//   default:
+//     description: GenericResp
+//     schema:
+//       "$ref": "#/definitions/GenericResp"
+func (ctl *DMSController) UpdateRole(c echo.Context) error {
+	req := new(aV1.UpdateRoleReq)
+	err := bindAndValidateReq(c, req)
+	if nil != err {
+		return NewErrResp(c, err, apiError.BadRequestErr)
+	}
+
+	// get current user id
+	currentUserUid, err := jwt.GetUserUidStrFromContext(c)
+	if err != nil {
+		return NewErrResp(c, err, apiError.DMSServiceErr)
+	}
+
+	err = ctl.DMS.UpdateRole(c.Request().Context(), currentUserUid, req)
+	if nil != err {
+		return NewErrResp(c, err, apiError.DMSServiceErr)
+	}
+	return NewOkResp(c)
+}
+
+// swagger:operation POST /v1/dms/roles/{role_uid}/instantiate Role InstantiateRoleTemplate
+//
+// Instantiate a role template in a project.
+//
+// ---
+// parameters:
+//   - name: role_uid
+//     description: Role template uid
+//     in: path
+//     required: true
+//     type: string
+//   - name: instance
+//     description: Instantiate a role template
+//     required: true
+//     in: body
+//     schema:
+//       "$ref": "#/definitions/InstantiateRoleTemplateReq"
+// responses:
+//   '200':
+//     description: InstantiateRoleTemplateReply
+//     schema:
+//       "$ref": "#/definitions/InstantiateRoleTemplateReply"
+//   default:
+//     description: GenericResp
+//     schema:
+//       "$ref": "#/definitions/GenericResp"
+func (ctl *DMSController) InstantiateRoleTemplate(c echo.Context) error {
+	req := new(aV1.InstantiateRoleTemplateReq)
+	err := bindAndValidateReq(c, req)
+	if nil != err {
+		return NewErrResp(c, err, apiError.BadRequestErr)
+	}
+
+	// get current user id
+	currentUserUid, err := jwt.GetUserUidStrFromContext(c)
+	if err != nil {
+		return NewErrResp(c, err, apiError.DMSServiceErr)
+	}
+
+	reply, err := ctl.DMS.InstantiateRoleTemplate(c.Request().Context(), currentUserUid, req)
+	if nil != err {
+		return NewErrResp(c, err, apiError.DMSServiceErr)
+	}
+	return NewOkRespWithReply(c, reply)
+}
+
+// swagger:operation POST /v1/dms/roles/{role_uid}/preview_update Role PreviewUpdateRole
+//
+// Preview the effective op permission changes of a role update, including the roles inheriting it.
+//
+// ---
+// parameters:
+//   - name: role_uid
+//     description: Role uid
+//     in: path
+//     required: true
+//     type: string
+//   - name: role
+//     description: The role update to preview
+//     required: true
+//     in: body
+//     schema:
+//       "$ref": "#/definitions/PreviewUpdateRoleReq"
+// responses:
+//   '200':
+//     description: PreviewUpdateRoleReply
+//     schema:
+//       "$ref": "#/definitions/PreviewUpdateRoleReply"
+//   default:
+//     description: GenericResp
+//     schema:
+//       "$ref": "#/definitions/GenericResp"
+func (ctl *DMSController) PreviewUpdateRole(c echo.Context) error {
+	req := new(aV1.PreviewUpdateRoleReq)
+	err := bindAndValidateReq(c, req)
+	if nil != err {
+		return NewErrResp(c, err, apiError.BadRequestErr)
+	}
+
+	// get current user id
+	currentUserUid, err := jwt.GetUserUidStrFromContext(c)
+	if err != nil {
+		return NewErrResp(c, err, apiError.DMSServiceErr)
+	}
+
+	reply, err := ctl.DMS.PreviewUpdateRole(c.Request().Context(), currentUserUid, req)
+	if nil != err {
+		return NewErrResp(c, err, apiError.DMSServiceErr)
+	}
+	return NewOkRespWithReply(c, reply)
+}
+
+// swagger:route DELETE /v1/dms/roles/{role_uid} Role DelRole
+//
+// Delete a role.
+//
+//	responses:
+//	  200: body:GenericResp
+//	  default: body:GenericResp
+func (ctl *DMSController) DelRole(c echo.Context) error {
+	req := &aV1.DelRoleReq{}
+	err := bindAndValidateReq(c, req)
+	if nil != err {
+		return NewErrResp(c, err, apiError.BadRequestErr)
+	}
+
+	// get current user id
+	currentUserUid, err := jwt.GetUserUidStrFromContext(c)
+	if err != nil {
+		return NewErrResp(c, err, apiError.DMSServiceErr)
+	}
+
+	err = ctl.DMS.DelRole(c.Request().Context(), currentUserUid, req)
+	if nil != err {
+		return NewErrResp(c, err, apiError.DMSServiceErr)
+	}
+	return NewOkResp(c)
+}
+
+// swagger:route GET /v1/dms/roles Role ListRoles
+//
+// List roles.
+//
+//	responses:
+//	  200: body:ListRoleReply
+//	  default: body:GenericResp
+func (ctl *DMSController) ListRoles(c echo.Context) error {
+	req := new(aV1.ListRoleReq)
+	err := bindAndValidateReq(c, req)
+	if nil != err {
+		return NewErrResp(c, err, apiError.BadRequestErr)
+	}
+
+	reply, err := ctl.DMS.ListRoles(c.Request().Context(), req)
+	if nil != err {
+		return NewErrResp(c, err, apiError.DMSServiceErr)
+	}
+	return NewOkRespWithReply(c, reply)
+}
+
+// swagger:operation POST /v1/dms/projects/{project_uid}/members Member AddMember
+//
+// Add member.
+//
+// ---
+// parameters:
+//   - name: project_uid
+//     description: project id
+//     in: path
+//     required: true
+//     type: string
+//   - name: member
+//     description: Add new member
+//     in: body
+//     required: true
+//     schema:
+//       "$ref": "#/definitions/AddMemberReq"
+// responses:
+//   '200':
+//     description: AddMemberReply
+//     schema:
+//       "$ref": "#/definitions/AddMemberReply"
+//   default:
+//     description: GenericResp
+//     schema:
+//       "$ref": "#/definitions/GenericResp"
+func (ctl *DMSController) AddMember(c echo.Context) error {
+	req := new(aV1.AddMemberReq)
+	err := bindAndValidateReq(c, req)
+	if nil != err {
+		return NewErrResp(c, err, apiError.BadRequestErr)
+	}
+
+	// get current user id
+	currentUserUid, err := jwt.GetUserUidStrFromContext(c)
+	if err != nil {
+		return NewErrResp(c, err, apiError.DMSServiceErr)
+	}
+
+	reply, err := ctl.DMS.AddMember(c.Request().Context(), currentUserUid, req)
+	if nil != err {
+		return NewErrResp(c, err, apiError.DMSServiceErr)
+	}
+	return NewOkRespWithReply(c, reply)
+}
+
+// swagger:route GET /v1/dms/projects/{project_uid}/members/tips Member ListMemberTips
+//
+// List member tips.
+//
+//	responses:
+//	  200: body:ListMemberTipsReply
+//	  default: body:GenericResp
+func (ctl *DMSController) ListMemberTips(c echo.Context) error {
+	req := new(aV1.ListMemberTipsReq)
+	err := bindAndValidateReq(c, req)
+	if nil != err {
+		return NewErrResp(c, err, apiError.BadRequestErr)
+	}
+
+	reply, err := ctl.DMS.ListMemberTips(c.Request().Context(), req.ProjectUid)
+	if nil != err {
+		return NewErrResp(c, err, apiError.DMSServiceErr)
+	}
+	return NewOkRespWithReply(c, reply)
+}
+
+// swagger:route GET /v1/dms/projects/{project_uid}/members Member ListMembers
+//
+// List member, for front page.
+//
+//	responses:
+//	  200: body:ListMemberReply
+//	  default: body:GenericResp
+func (ctl *DMSController) ListMembers(c echo.Context) error {
+	req := new(aV1.ListMemberReq)
+	err := bindAndValidateReq(c, req)
+	if nil != err {
+		return NewErrResp(c, err, apiError.BadRequestErr)
+	}
+	currentUserUid, err := jwt.GetUserUidStrFromContext(c)
+	if err != nil {
+		return NewErrResp(c, err, apiError.DMSServiceErr)
+	}
+	reply, err := ctl.DMS.ListMembers(c.Request().Context(), req, currentUserUid)
+	if nil != err {
+		return NewErrResp(c, err, apiError.DMSServiceErr)
+	}
+	return NewOkRespWithReply(c, reply)
+}
+
+// swagger:route GET /v1/dms/projects/{project_uid}/members/internal Member ListMembersForInternal
+//
+// List members, for internal backend service.
+//
+//	responses:
+//	  200: body:ListMembersForInternalReply
+//	  default: body:GenericResp
+func (ctl *DMSController) ListMembersForInternal(c echo.Context) error {
+	req := new(dmsV1.ListMembersForInternalReq)
+	err := bindAndValidateReq(c, req)
+	if nil != err {
+		return NewErrResp(c, err, apiError.BadRequestErr)
+	}
+
+	reply, err := ctl.DMS.ListMembersForInternal(c.Request().Context(), req)
+	if nil != err {
+		return NewErrResp(c, err, apiError.DMSServiceErr)
+	}
+	return NewOkRespWithReply(c, reply)
+}
+
+// swagger:operation PUT /v1/dms/projects/{project_uid}/members/{member_uid} Member UpdateMember
+//
+// Update a member.
+//
+// ---
+// parameters:
+//   - name: project_uid
+//     description: project id
+//     in: path
+//     required: true
+//     type: string
+//   - name: member_uid
+//     description: Member uid
+//     in: path
+//     required: true
+//     type: string
+//   - name: member
+//     description: Update a member
+//     required: true
+//     in: body
+//     schema:
+//       "$ref": "#/definitions/UpdateMemberReq"
+// responses:
+//   '200':
+//     description: GenericResp
+//     schema:
+//       "$ref": "#/definitions/GenericResp"
+//   default:
+//     description: GenericResp
+//     schema:
+//       "$ref": "#/definitions/GenericResp"
+func (ctl *DMSController) UpdateMember(c echo.Context) error {
+	req := new(aV1.UpdateMemberReq)
+	err := bindAndValidateReq(c, req)
+	if nil != err {
+		return NewErrResp(c, err, apiError.BadRequestErr)
+	}
+
+	// get current user id
+	currentUserUid, err := jwt.GetUserUidStrFromContext(c)
+	if err != nil {
+		return NewErrResp(c, err, apiError.DMSServiceErr)
+	}
+
+	err = ctl.DMS.UpdateMember(c.Request().Context(), currentUserUid, req)
+	if nil != err {
+		return NewErrResp(c, err, apiError.DMSServiceErr)
+	}
+	return NewOkResp(c)
+}
+
+// swagger:route DELETE /v1/dms/projects/{project_uid}/members/{member_uid} Member DelMember
+//
+// Delete a member.
+//
+//	responses:
+//	  200: body:GenericResp
+//	  default: body:GenericResp
+func (ctl *DMSController) DelMember(c echo.Context) error {
+	req := &aV1.DelMemberReq{}
+	err := bindAndValidateReq(c, req)
+	if nil != err {
+		return NewErrResp(c, err, apiError.BadRequestErr)
+	}
+
+	// get current user id
+	currentUserUid, err := jwt.GetUserUidStrFromContext(c)
+	if err != nil {
+		return NewErrResp(c, err, apiError.DMSServiceErr)
+	}
+
+	err = ctl.DMS.DelMember(c.Request().Context(), currentUserUid, req)
+	if nil != err {
+		return NewErrResp(c, err, apiError.DMSServiceErr)
+	}
+	return NewOkResp(c)
+}
+
+// swagger:operation POST /v1/dms/projects/{project_uid}/member_access_requests MemberAccessRequest AddMemberAccessRequest
+//
+// Request temporary access to a project.
+//
+// ---
+// parameters:
+//   - name: project_uid
+//     description: project id
+//     in: path
+//     required: true
+//     type: string
+//   - name: access_request
+//     description: roles and duration requested
+//     in: body
+//     required: true
+//     schema:
+//       "$ref": "#/definitions/AddMemberAccessRequestReq"
+// responses:
+//   '200':
+//     description: AddMemberAccessRequestReply
+//     schema:
+//       "$ref": "#/definitions/AddMemberAccessRequestReply"
+//   default:
+//     description: GenericResp
+//     schema:
+//       "$ref": "#/definitions/GenericResp"
+func (ctl *DMSController) AddMemberAccessRequest(c echo.Context) error {
+	req := new(aV1.AddMemberAccessRequestReq)
+	err := bindAndValidateReq(c, req)
+	if nil != err {
+		return NewErrResp(c, err, apiError.BadRequestErr)
+	}
+
+	currentUserUid, err := jwt.GetUserUidStrFromContext(c)
+	if err != nil {
+		return NewErrResp(c, err, apiError.DMSServiceErr)
+	}
+
+	reply, err := ctl.DMS.AddMemberAccessRequest(c.Request().Context(), currentUserUid, req)
+	if nil != err {
+		return NewErrResp(c, err, apiError.DMSServiceErr)
+	}
+	return NewOkRespWithReply(c, reply)
+}
+
+// swagger:route GET /v1/dms/projects/{project_uid}/member_access_requests MemberAccessRequest ListMemberAccessRequests
+//
+// List access requests of a project, users without member management permission only see their own requests.
+//
+//	responses:
+//	  200: body:ListMemberAccessRequestsReply
+//	  default: body:GenericResp
+func (ctl *DMSController) ListMemberAccessRequests(c echo.Context) error {
+	req := new(aV1.ListMemberAccessRequestsReq)
+	err := bindAndValidateReq(c, req)
+	if nil != err {
+		return NewErrResp(c, err, apiError.BadRequestErr)
+	}
+
+	currentUserUid, err := jwt.GetUserUidStrFromContext(c)
+	if err != nil {
+		return NewErrResp(c, err, apiError.DMSServiceErr)
+	}
+
+	reply, err := ctl.DMS.ListMemberAccessRequests(c.Request().Context(), currentUserUid, req)
+	if nil != err {
+		return NewErrResp(c, err, apiError.DMSServiceErr)
+	}
+	return NewOkRespWithReply(c, reply)
+}
+
+// swagger:operation POST /v1/dms/projects/{project_uid}/member_access_requests/{access_request_uid}/approve MemberAccessRequest ApproveMemberAccessRequest
+//
+// Approve an access request, the access is granted as a member group that expires automatically.
+//
+// ---
+// parameters:
+//   - name: project_uid
+//     description: project id
+//     in: path
+//     required: true
+//     type: string
+//   - name: access_request_uid
+//     description: access request uid
+//     in: path
+//     required: true
+//     type: string
+//   - name: review
+//     description: review comment
+//     in: body
+//     required: true
+//     schema:
+//       "$ref": "#/definitions/ReviewMemberAccessRequestReq"
+// responses:
+//   '200':
+//     description: GenericResp
+//     schema:
+//       "$ref": "#/definitions/GenericResp"
+//   default:
+//     description: GenericResp
+//     schema:
+//       "$ref": "#/definitions/GenericResp"
+func (ctl *DMSController) ApproveMemberAccessRequest(c echo.Context) error {
+	req := new(aV1.ReviewMemberAccessRequestReq)
+	err := bindAndValidateReq(c, req)
+	if nil != err {
+		return NewErrResp(c, err, apiError.BadRequestErr)
+	}
+
+	currentUserUid, err := jwt.GetUserUidStrFromContext(c)
+	if err != nil {
+		return NewErrResp(c, err, apiError.DMSServiceErr)
+	}
+
+	if err = ctl.DMS.ApproveMemberAccessRequest(c.Request().Context(), currentUserUid, req); nil != err {
+		return NewErrResp(c, err, apiError.DMSServiceErr)
+	}
+	return NewOkResp(c)
+}
+
+// swagger:operation POST /v1/dms/projects/{project_uid}/member_access_requests/{access_request_uid}/reject MemberAccessRequest RejectMemberAccessRequest
+//
+// Reject an access request.
+//
+// ---
+// parameters:
+//   - name: project_uid
+//     description: project id
+//     in: path
+//     required: true
+//     type: string
+//   - name: access_request_uid
+//     description: access request uid
+//     in: path
+//     required: true
+//     type: string
+//   - name: review
+//     description: review comment
+//     in: body
+//     required: true
+//     schema:
+//       "$ref": "#/definitions/ReviewMemberAccessRequestReq"
+// responses:
+//   '200':
+//     description: GenericResp
+//     schema:
+//       "$ref": "#/definitions/GenericResp"
+//   default:
+//     description: GenericResp
+//     schema:
+//       "$ref": "#/definitions/GenericResp"
+func (ctl *DMSController) RejectMemberAccessRequest(c echo.Context) error {
+	req := new(aV1.ReviewMemberAccessRequestReq)
+	err := bindAndValidateReq(c, req)
+	if nil != err {
+		return NewErrResp(c, err, apiError.BadRequestErr)
+	}
+
+	currentUserUid, err := jwt.GetUserUidStrFromContext(c)
+	if err != nil {
+		return NewErrResp(c, err, apiError.DMSServiceErr)
+	}
+
+	if err = ctl.DMS.RejectMemberAccessRequest(c.Request().Context(), currentUserUid, req); nil != err {
+		return NewErrResp(c, err, apiError.DMSServiceErr)
+	}
+	return NewOkResp(c)
+}
+
+// swagger:operation POST /v1/dms/projects/{project_uid}/separation_of_duties_rules SeparationOfDuties AddSeparationOfDutiesRule
+//
+// Add a separation of duties rule to a project.
+//
+// ---
+// parameters:
+//   - name: project_uid
+//     description: project id
+//     in: path
+//     required: true
+//     type: string
+//   - name: rule
+//     description: separation of duties rule
+//     in: body
+//     required: true
+//     schema:
+//       "$ref": "#/definitions/AddSeparationOfDutiesRuleReq"
+// responses:
+//   '200':
+//     description: AddSeparationOfDutiesRuleReply
+//     schema:
+//       "$ref": "#/definitions/AddSeparationOfDutiesRuleReply"
+//   default:
+//     description: GenericResp
+//     schema:
+//       "$ref": "#/definitions/GenericResp"
+func (ctl *DMSController) AddSeparationOfDutiesRule(c echo.Context) error {
+	req := new(aV1.AddSeparationOfDutiesRuleReq)
+	err := bindAndValidateReq(c, req)
+	if nil != err {
+		return NewErrResp(c, err, apiError.BadRequestErr)
+	}
+
+	currentUserUid, err := jwt.GetUserUidStrFromContext(c)
+	if err != nil {
+		return NewErrResp(c, err, apiError.DMSServiceErr)
+	}
+
+	reply, err := ctl.DMS.AddSeparationOfDutiesRule(c.Request().Context(), currentUserUid, req)
+	if nil != err {
+		return NewErrResp(c, err, apiError.DMSServiceErr)
+	}
+	return NewOkRespWithReply(c, reply)
+}
+
+// swagger:route GET /v1/dms/projects/{project_uid}/separation_of_duties_rules SeparationOfDuties ListSeparationOfDutiesRules
+//
+// List separation of duties rules of a project.
+//
+//	responses:
+//	  200: body:ListSeparationOfDutiesRulesReply
+//	  default: body:GenericResp
+func (ctl *DMSController) ListSeparationOfDutiesRules(c echo.Context) error {
+	req := new(aV1.ListSeparationOfDutiesRulesReq)
+	err := bindAndValidateReq(c, req)
+	if nil != err {
+		return NewErrResp(c, err, apiError.BadRequestErr)
+	}
+
+	currentUserUid, err := jwt.GetUserUidStrFromContext(c)
+	if err != nil {
+		return NewErrResp(c, err, apiError.DMSServiceErr)
+	}
+
+	reply, err := ctl.DMS.ListSeparationOfDutiesRules(c.Request().Context(), currentUserUid, req)
+	if nil != err {
+		return NewErrResp(c, err, apiError.DMSServiceErr)
+	}
+	return NewOkRespWithReply(c, reply)
+}
+
+// swagger:route DELETE /v1/dms/projects/{project_uid}/separation_of_duties_rules/{rule_uid} SeparationOfDuties DelSeparationOfDutiesRule
+//
+// Delete a separation of duties rule.
+//
+//	responses:
+//	  200: body:GenericResp
+//	  default: body:GenericResp
+func (ctl *DMSController) DelSeparationOfDutiesRule(c echo.Context) error {
+	req := new(aV1.DelSeparationOfDutiesRuleReq)
+	err := bindAndValidateReq(c, req)
+	if nil != err {
+		return NewErrResp(c, err, apiError.BadRequestErr)
+	}
+
+	currentUserUid, err := jwt.GetUserUidStrFromContext(c)
+	if err != nil {
+		return NewErrResp(c, err, apiError.DMSServiceErr)
+	}
+
+	if err = ctl.DMS.DelSeparationOfDutiesRule(c.Request().Context(), currentUserUid, req); nil != err {
+		return NewErrResp(c, err, apiError.DMSServiceErr)
+	}
+	return NewOkResp(c)
+}
+
+// swagger:route GET /v1/dms/projects/{project_uid}/separation_of_duties_violations SeparationOfDuties ListSeparationOfDutiesViolations
+//
+// List current members holding mutually exclusive op permissions and workflows approved by their own creators.
+//
+//	responses:
+//	  200: body:ListSeparationOfDutiesViolationsReply
+//	  default: body:GenericResp
+func (ctl *DMSController) ListSeparationOfDutiesViolations(c echo.Context) error {
+	req := new(aV1.ListSeparationOfDutiesViolationsReq)
+	err := bindAndValidateReq(c, req)
+	if nil != err {
+		return NewErrResp(c, err, apiError.BadRequestErr)
+	}
+
+	currentUserUid, err := jwt.GetUserUidStrFromContext(c)
+	if err != nil {
+		return NewErrResp(c, err, apiError.DMSServiceErr)
+	}
+
+	reply, err := ctl.DMS.ListSeparationOfDutiesViolations(c.Request().Context(), currentUserUid, req)
+	if nil != err {
+		return NewErrResp(c, err, apiError.DMSServiceErr)
+	}
+	return NewOkRespWithReply(c, reply)
+}
+
+// swagger:operation PUT /v1/dms/data_export_quotas DataExportWorkflows SetDataExportQuota
+//
+// Set data export limits of global, a project or a db service, the strictest limits of all scopes take effect.
+//
+// ---
+// parameters:
+//   - name: quota
+//     description: data export quota
+//     in: body
+//     required: true
+//     schema:
+//       "$ref": "#/definitions/SetDataExportQuotaReq"
 // responses:
 //   '200':
 //     description: GenericResp
@@ -1435,77 +2613,202 @@ func (ctl *DMSController) AddRole(c echo.Context) error {
 //     description: GenericResp
 //     schema:
 //       "$ref": "#/definitions/GenericResp"
-func (ctl *DMSController) UpdateRole(c echo.Context) error {
-	req := new(aV1.UpdateRoleReq)
+func (ctl *DMSController) SetDataExportQuota(c echo.Context) error {
+	req := new(aV1.SetDataExportQuotaReq)
 	err := bindAndValidateReq(c, req)
 	if nil != err {
 		return NewErrResp(c, err, apiError.BadRequestErr)
 	}
 
-	// get current user id
 	currentUserUid, err := jwt.GetUserUidStrFromContext(c)
 	if err != nil {
 		return NewErrResp(c, err, apiError.DMSServiceErr)
 	}
 
-	err = ctl.DMS.UpdateRole(c.Request().Context(), currentUserUid, req)
-	if nil != err {
+	if err := ctl.DMS.SetDataExportQuota(c.Request().Context(), req, currentUserUid); err != nil {
 		return NewErrResp(c, err, apiError.DMSServiceErr)
 	}
 	return NewOkResp(c)
 }
 
-// swagger:route DELETE /v1/dms/roles/{role_uid} Role DelRole
+// swagger:route GET /v1/dms/data_export_quotas DataExportWorkflows ListDataExportQuotas
 //
-// Delete a role.
+// List data export quotas.
 //
 //	responses:
-//	  200: body:GenericResp
+//	  200: body:ListDataExportQuotasReply
 //	  default: body:GenericResp
-func (ctl *DMSController) DelRole(c echo.Context) error {
-	req := &aV1.DelRoleReq{}
+func (ctl *DMSController) ListDataExportQuotas(c echo.Context) error {
+	req := new(aV1.ListDataExportQuotasReq)
 	err := bindAndValidateReq(c, req)
 	if nil != err {
 		return NewErrResp(c, err, apiError.BadRequestErr)
 	}
 
-	// get current user id
 	currentUserUid, err := jwt.GetUserUidStrFromContext(c)
 	if err != nil {
 		return NewErrResp(c, err, apiError.DMSServiceErr)
 	}
 
-	err = ctl.DMS.DelRole(c.Request().Context(), currentUserUid, req)
+	reply, err := ctl.DMS.ListDataExportQuotas(c.Request().Context(), req, currentUserUid)
+	if nil != err {
+		return NewErrResp(c, err, apiError.DMSServiceErr)
+	}
+	return NewOkRespWithReply(c, reply)
+}
+
+// swagger:route GET /v1/dms/projects/{project_uid}/data_export_workflows/quota_usage DataExportWorkflows GetDataExportQuotaUsage
+//
+// Get the effective data export limits and the usage of the current user in the project.
+//
+//	responses:
+//	  200: body:GetDataExportQuotaUsageReply
+//	  default: body:GenericResp
+func (ctl *DMSController) GetDataExportQuotaUsage(c echo.Context) error {
+	req := new(aV1.GetDataExportQuotaUsageReq)
+	err := bindAndValidateReq(c, req)
+	if nil != err {
+		return NewErrResp(c, err, apiError.BadRequestErr)
+	}
+
+	currentUserUid, err := jwt.GetUserUidStrFromContext(c)
+	if err != nil {
+		return NewErrResp(c, err, apiError.DMSServiceErr)
+	}
+
+	reply, err := ctl.DMS.GetDataExportQuotaUsage(c.Request().Context(), req, currentUserUid)
 	if nil != err {
 		return NewErrResp(c, err, apiError.DMSServiceErr)
 	}
+	return NewOkRespWithReply(c, reply)
+}
+
+// swagger:operation POST /v1/dms/data_export_delivery_targets DataExportWorkflows AddDataExportDeliveryTarget
+//
+// Add a data export delivery target, export files of workflows using it are uploaded to the target after export.
+//
+// ---
+// parameters:
+//   - name: delivery_target
+//     description: data export delivery target, secrets are stored encrypted
+//     in: body
+//     required: true
+//     schema:
+//       "$ref": "#/definitions/AddDataExportDeliveryTargetReq"
+// responses:
+//   '200':
+//     description: AddDataExportDeliveryTargetReply
+//     schema:
+//       "$ref": "#/definitions/AddDataExportDeliveryTargetReply"
+//   default:
+//     description: GenericResp
+//     schema:
+//       "$ref": "#/definitions/GenericResp"
+func (ctl *DMSController) AddDataExportDeliveryTarget(c echo.Context) error {
+	req := new(aV1.AddDataExportDeliveryTargetReq)
+	err := bindAndValidateReq(c, req)
+	if nil != err {
+		return NewErrResp(c, err, apiError.BadRequestErr)
+	}
+
+	currentUserUid, err := jwt.GetUserUidStrFromContext(c)
+	if err != nil {
+		return NewErrResp(c, err, apiError.DMSServiceErr)
+	}
+
+	reply, err := ctl.DMS.AddDataExportDeliveryTarget(c.Request().Context(), req, currentUserUid)
+	if nil != err {
+		return NewErrResp(c, err, apiError.DMSServiceErr)
+	}
+	return NewOkRespWithReply(c, reply)
+}
+
+// swagger:operation PUT /v1/dms/data_export_delivery_targets/{delivery_target_uid} DataExportWorkflows UpdateDataExportDeliveryTarget
+//
+// Update a data export delivery target, empty secrets keep the current values.
+//
+// ---
+// parameters:
+//   - name: delivery_target_uid
+//     in: path
+//     required: true
+//     type: string
+//   - name: delivery_target
+//     description: data export delivery target
+//     in: body
+//     required: true
+//     schema:
+//       "$ref": "#/definitions/UpdateDataExportDeliveryTargetReq"
+// responses:
+//   '200':
+//     description: GenericResp
+//     schema:
+//       "$ref": "#/definitions/GenericResp"
+//   default:
+//     description: GenericResp
+//     schema:
+//       "$ref": "#/definitions/GenericResp"
+func (ctl *DMSController) UpdateDataExportDeliveryTarget(c echo.Context) error {
+	req := new(aV1.UpdateDataExportDeliveryTargetReq)
+	err := bindAndValidateReq(c, req)
+	if nil != err {
+		return NewErrResp(c, err, apiError.BadRequestErr)
+	}
+
+	currentUserUid, err := jwt.GetUserUidStrFromContext(c)
+	if err != nil {
+		return NewErrResp(c, err, apiError.DMSServiceErr)
+	}
+
+	if err := ctl.DMS.UpdateDataExportDeliveryTarget(c.Request().Context(), req, currentUserUid); err != nil {
+		return NewErrResp(c, err, apiError.DMSServiceErr)
+	}
 	return NewOkResp(c)
 }
 
-// swagger:route GET /v1/dms/roles Role ListRoles
+// swagger:route DELETE /v1/dms/data_export_delivery_targets/{delivery_target_uid} DataExportWorkflows DeleteDataExportDeliveryTarget
 //
-// List roles.
+// Delete a data export delivery target.
 //
 //	responses:
-//	  200: body:ListRoleReply
+//	  200: body:GenericResp
 //	  default: body:GenericResp
-func (ctl *DMSController) ListRoles(c echo.Context) error {
-	req := new(aV1.ListRoleReq)
+func (ctl *DMSController) DeleteDataExportDeliveryTarget(c echo.Context) error {
+	req := new(aV1.DeleteDataExportDeliveryTargetReq)
 	err := bindAndValidateReq(c, req)
 	if nil != err {
 		return NewErrResp(c, err, apiError.BadRequestErr)
 	}
 
-	reply, err := ctl.DMS.ListRoles(c.Request().Context(), req)
+	currentUserUid, err := jwt.GetUserUidStrFromContext(c)
+	if err != nil {
+		return NewErrResp(c, err, apiError.DMSServiceErr)
+	}
+
+	if err := ctl.DMS.DeleteDataExportDeliveryTarget(c.Request().Context(), req, currentUserUid); err != nil {
+		return NewErrResp(c, err, apiError.DMSServiceErr)
+	}
+	return NewOkResp(c)
+}
+
+// swagger:route GET /v1/dms/data_export_delivery_targets DataExportWorkflows ListDataExportDeliveryTargets
+//
+// List data export delivery targets, secrets are not returned.
+//
+//	responses:
+//	  200: body:ListDataExportDeliveryTargetsReply
+//	  default: body:GenericResp
+func (ctl *DMSController) ListDataExportDeliveryTargets(c echo.Context) error {
+	reply, err := ctl.DMS.ListDataExportDeliveryTargets(c.Request().Context())
 	if nil != err {
 		return NewErrResp(c, err, apiError.DMSServiceErr)
 	}
 	return NewOkRespWithReply(c, reply)
 }
 
-// swagger:operation POST /v1/dms/projects/{project_uid}/members Member AddMember
+// swagger:operation PUT /v1/dms/projects/{project_uid}/workflow_approval_sla DataExportWorkflows SetWorkflowApprovalSLA
 //
-// Add member.
+// Set the approval sla of data export workflows in the project, all hours 0 removes the sla.
 //
 // ---
 // parameters:
@@ -1514,129 +2817,200 @@ func (ctl *DMSController) ListRoles(c echo.Context) error {
 //     in: path
 //     required: true
 //     type: string
-//   - name: member
-//     description: Add new member
+//   - name: approval_sla
+//     description: workflow approval sla
 //     in: body
 //     required: true
 //     schema:
-//       "$ref": "#/definitions/AddMemberReq"
+//       "$ref": "#/definitions/SetWorkflowApprovalSLAReq"
 // responses:
 //   '200':
-//     description: AddMemberReply
+//     description: GenericResp
 //     schema:
-//       "$ref": "#/definitions/AddMemberReply"
+//       "$ref": "#/definitions/GenericResp"
 //   default:
 //     description: GenericResp
 //     schema:
 //       "$ref": "#/definitions/GenericResp"
-func (ctl *DMSController) AddMember(c echo.Context) error {
-	req := new(aV1.AddMemberReq)
+func (ctl *DMSController) SetWorkflowApprovalSLA(c echo.Context) error {
+	req := new(aV1.SetWorkflowApprovalSLAReq)
 	err := bindAndValidateReq(c, req)
 	if nil != err {
 		return NewErrResp(c, err, apiError.BadRequestErr)
 	}
 
-	// get current user id
 	currentUserUid, err := jwt.GetUserUidStrFromContext(c)
 	if err != nil {
 		return NewErrResp(c, err, apiError.DMSServiceErr)
 	}
 
-	reply, err := ctl.DMS.AddMember(c.Request().Context(), currentUserUid, req)
-	if nil != err {
+	if err := ctl.DMS.SetWorkflowApprovalSLA(c.Request().Context(), req, currentUserUid); err != nil {
 		return NewErrResp(c, err, apiError.DMSServiceErr)
 	}
-	return NewOkRespWithReply(c, reply)
+	return NewOkResp(c)
 }
 
-// swagger:route GET /v1/dms/projects/{project_uid}/members/tips Member ListMemberTips
+// swagger:route GET /v1/dms/projects/{project_uid}/workflow_approval_sla DataExportWorkflows GetWorkflowApprovalSLA
 //
-// List member tips.
+// Get the approval sla of data export workflows in the project.
 //
 //	responses:
-//	  200: body:ListMemberTipsReply
+//	  200: body:GetWorkflowApprovalSLAReply
 //	  default: body:GenericResp
-func (ctl *DMSController) ListMemberTips(c echo.Context) error {
-	req := new(aV1.ListMemberTipsReq)
+func (ctl *DMSController) GetWorkflowApprovalSLA(c echo.Context) error {
+	req := new(aV1.GetWorkflowApprovalSLAReq)
 	err := bindAndValidateReq(c, req)
 	if nil != err {
 		return NewErrResp(c, err, apiError.BadRequestErr)
 	}
 
-	reply, err := ctl.DMS.ListMemberTips(c.Request().Context(), req.ProjectUid)
+	reply, err := ctl.DMS.GetWorkflowApprovalSLA(c.Request().Context(), req)
 	if nil != err {
 		return NewErrResp(c, err, apiError.DMSServiceErr)
 	}
 	return NewOkRespWithReply(c, reply)
 }
 
-// swagger:route GET /v1/dms/projects/{project_uid}/members Member ListMembers
+// swagger:route POST /v1/dms/data_export_watermarks/trace DataExportWorkflows TraceDataExport
 //
-// List member, for front page.
+// Trace the export task and the downloaders of a leaked export file or rows copied from it by the watermark fingerprints.
+//
+//	Consumes:
+//	- multipart/form-data
 //
 //	responses:
-//	  200: body:ListMemberReply
+//	  200: body:TraceDataExportReply
 //	  default: body:GenericResp
-func (ctl *DMSController) ListMembers(c echo.Context) error {
-	req := new(aV1.ListMemberReq)
+func (ctl *DMSController) TraceDataExport(c echo.Context) error {
+	currentUserUid, err := jwt.GetUserUidStrFromContext(c)
+	if err != nil {
+		return NewErrResp(c, err, apiError.DMSServiceErr)
+	}
+
+	content, fileExist, err := ReadFileContent(c, "file")
+	if err != nil {
+		return NewErrResp(c, err, apiError.BadRequestErr)
+	}
+	sample := c.FormValue("sample")
+	if !fileExist && sample == "" {
+		return NewErrResp(c, fmt.Errorf("file or sample is required"), apiError.BadRequestErr)
+	}
+
+	reply, err := ctl.DMS.TraceDataExport(c.Request().Context(), []byte(content), sample, currentUserUid)
+	if nil != err {
+		return NewErrResp(c, err, apiError.DMSServiceErr)
+	}
+	return NewOkRespWithReply(c, reply)
+}
+
+// swagger:operation POST /v1/dms/access_review_campaigns AccessReview AddAccessReviewCampaign
+//
+// Open an access review campaign, each project admin reviews the access of the members in their projects.
+//
+// ---
+// parameters:
+//   - name: campaign
+//     description: access review campaign
+//     in: body
+//     required: true
+//     schema:
+//       "$ref": "#/definitions/AddAccessReviewCampaignReq"
+// responses:
+//   '200':
+//     description: AddAccessReviewCampaignReply
+//     schema:
+//       "$ref": "#/definitions/AddAccessReviewCampaignReply"
+//   default:
+//     description: GenericResp
+//     schema:
+//       "$ref": "#/definitions/GenericResp"
+func (ctl *DMSController) AddAccessReviewCampaign(c echo.Context) error {
+	req := new(aV1.AddAccessReviewCampaignReq)
 	err := bindAndValidateReq(c, req)
 	if nil != err {
 		return NewErrResp(c, err, apiError.BadRequestErr)
 	}
+
 	currentUserUid, err := jwt.GetUserUidStrFromContext(c)
 	if err != nil {
 		return NewErrResp(c, err, apiError.DMSServiceErr)
 	}
-	reply, err := ctl.DMS.ListMembers(c.Request().Context(), req, currentUserUid)
+
+	reply, err := ctl.DMS.AddAccessReviewCampaign(c.Request().Context(), currentUserUid, req)
 	if nil != err {
 		return NewErrResp(c, err, apiError.DMSServiceErr)
 	}
 	return NewOkRespWithReply(c, reply)
 }
 
-// swagger:route GET /v1/dms/projects/{project_uid}/members/internal Member ListMembersForInternal
+// swagger:route GET /v1/dms/access_review_campaigns AccessReview ListAccessReviewCampaigns
 //
-// List members, for internal backend service.
+// List access review campaigns the current user can review.
 //
 //	responses:
-//	  200: body:ListMembersForInternalReply
+//	  200: body:ListAccessReviewCampaignsReply
 //	  default: body:GenericResp
-func (ctl *DMSController) ListMembersForInternal(c echo.Context) error {
-	req := new(dmsV1.ListMembersForInternalReq)
+func (ctl *DMSController) ListAccessReviewCampaigns(c echo.Context) error {
+	currentUserUid, err := jwt.GetUserUidStrFromContext(c)
+	if err != nil {
+		return NewErrResp(c, err, apiError.DMSServiceErr)
+	}
+
+	reply, err := ctl.DMS.ListAccessReviewCampaigns(c.Request().Context(), currentUserUid)
+	if nil != err {
+		return NewErrResp(c, err, apiError.DMSServiceErr)
+	}
+	return NewOkRespWithReply(c, reply)
+}
+
+// swagger:route GET /v1/dms/access_review_campaigns/{campaign_uid} AccessReview GetAccessReviewCampaign
+//
+// Get an access review campaign with the checklist of the current user.
+//
+//	responses:
+//	  200: body:GetAccessReviewCampaignReply
+//	  default: body:GenericResp
+func (ctl *DMSController) GetAccessReviewCampaign(c echo.Context) error {
+	req := new(aV1.GetAccessReviewCampaignReq)
 	err := bindAndValidateReq(c, req)
 	if nil != err {
 		return NewErrResp(c, err, apiError.BadRequestErr)
 	}
 
-	reply, err := ctl.DMS.ListMembersForInternal(c.Request().Context(), req)
+	currentUserUid, err := jwt.GetUserUidStrFromContext(c)
+	if err != nil {
+		return NewErrResp(c, err, apiError.DMSServiceErr)
+	}
+
+	reply, err := ctl.DMS.GetAccessReviewCampaign(c.Request().Context(), currentUserUid, req)
 	if nil != err {
 		return NewErrResp(c, err, apiError.DMSServiceErr)
 	}
 	return NewOkRespWithReply(c, reply)
 }
 
-// swagger:operation PUT /v1/dms/projects/{project_uid}/members/{member_uid} Member UpdateMember
+// swagger:operation POST /v1/dms/access_review_campaigns/{campaign_uid}/items/{item_uid}/review AccessReview ReviewAccessReviewItem
 //
-// Update a member.
+// Approve or revoke the access of an access review item.
 //
 // ---
 // parameters:
-//   - name: project_uid
-//     description: project id
+//   - name: campaign_uid
+//     description: campaign uid
 //     in: path
 //     required: true
 //     type: string
-//   - name: member_uid
-//     description: Member uid
+//   - name: item_uid
+//     description: access review item uid
 //     in: path
 //     required: true
 //     type: string
-//   - name: member
-//     description: Update a member
-//     required: true
+//   - name: review
+//     description: review decision
 //     in: body
+//     required: true
 //     schema:
-//       "$ref": "#/definitions/UpdateMemberReq"
+//       "$ref": "#/definitions/ReviewAccessReviewItemReq"
 // responses:
 //   '200':
 //     description: GenericResp
@@ -1646,51 +3020,56 @@ func (ctl *DMSController) ListMembersForInternal(c echo.Context) error {
 //     description: GenericResp
 //     schema:
 //       "$ref": "#/definitions/GenericResp"
-func (ctl *DMSController) UpdateMember(c echo.Context) error {
-	req := new(aV1.UpdateMemberReq)
+func (ctl *DMSController) ReviewAccessReviewItem(c echo.Context) error {
+	req := new(aV1.ReviewAccessReviewItemReq)
 	err := bindAndValidateReq(c, req)
 	if nil != err {
 		return NewErrResp(c, err, apiError.BadRequestErr)
 	}
 
-	// get current user id
 	currentUserUid, err := jwt.GetUserUidStrFromContext(c)
 	if err != nil {
 		return NewErrResp(c, err, apiError.DMSServiceErr)
 	}
 
-	err = ctl.DMS.UpdateMember(c.Request().Context(), currentUserUid, req)
-	if nil != err {
+	if err = ctl.DMS.ReviewAccessReviewItem(c.Request().Context(), currentUserUid, req); nil != err {
 		return NewErrResp(c, err, apiError.DMSServiceErr)
 	}
 	return NewOkResp(c)
 }
 
-// swagger:route DELETE /v1/dms/projects/{project_uid}/members/{member_uid} Member DelMember
+// swagger:route GET /v1/dms/access_review_campaigns/{campaign_uid}/export AccessReview ExportAccessReviewEvidence
 //
-// Delete a member.
+// Export the evidence of a completed access review campaign as csv or json.
 //
 //	responses:
-//	  200: body:GenericResp
+//	  200: ExportAccessReviewEvidenceReply
 //	  default: body:GenericResp
-func (ctl *DMSController) DelMember(c echo.Context) error {
-	req := &aV1.DelMemberReq{}
+func (ctl *DMSController) ExportAccessReviewEvidence(c echo.Context) error {
+	req := new(aV1.ExportAccessReviewEvidenceReq)
 	err := bindAndValidateReq(c, req)
 	if nil != err {
 		return NewErrResp(c, err, apiError.BadRequestErr)
 	}
 
-	// get current user id
 	currentUserUid, err := jwt.GetUserUidStrFromContext(c)
 	if err != nil {
 		return NewErrResp(c, err, apiError.DMSServiceErr)
 	}
 
-	err = ctl.DMS.DelMember(c.Request().Context(), currentUserUid, req)
-	if nil != err {
+	content, fileName, err := ctl.DMS.ExportAccessReviewEvidence(c.Request().Context(), currentUserUid, req)
+	if err != nil {
 		return NewErrResp(c, err, apiError.DMSServiceErr)
 	}
-	return NewOkResp(c)
+
+	c.Response().Header().Set(echo.HeaderContentDisposition,
+		mime.FormatMediaType("attachment", map[string]string{"filename": fileName}))
+
+	contentType := "text/csv"
+	if req.Format == aV1.AccessReviewEvidenceFormatJSON {
+		contentType = echo.MIMEApplicationJSON
+	}
+	return c.Blob(http.StatusOK, contentType, content)
 }
 
 // swagger:route GET /v1/dms/projects/{project_uid}/member_groups MemberGroup ListMemberGroups
@@ -1905,6 +3284,54 @@ func (ctl *DMSController) ListOpPermissions(c echo.Context) error {
 	return NewOkRespWithReply(c, reply)
 }
 
+// swagger:route GET /v1/dms/permissions/explain OpPermission ExplainPermission
+//
+// Explain whether a user has an op permission in a project or on a db service, and which rule decides it.
+//
+//	responses:
+//	  200: body:ExplainPermissionReply
+//	  default: body:GenericResp
+func (ctl *DMSController) ExplainPermission(c echo.Context) error {
+	req := new(aV1.ExplainPermissionReq)
+	err := bindAndValidateReq(c, req)
+	if nil != err {
+		return NewErrResp(c, err, apiError.BadRequestErr)
+	}
+
+	// get current user id
+	currentUserUid, err := jwt.GetUserUidStrFromContext(c)
+	if err != nil {
+		return NewErrResp(c, err, apiError.DMSServiceErr)
+	}
+
+	reply, err := ctl.DMS.ExplainPermission(c.Request().Context(), currentUserUid, req)
+	if nil != err {
+		return NewErrResp(c, err, apiError.DMSServiceErr)
+	}
+	return NewOkRespWithReply(c, reply)
+}
+
+// swagger:route GET /v1/dms/permissions/cache_stats OpPermission GetPermissionCacheStats
+//
+// Get permission cache hit ratio and size on the current node.
+//
+//	responses:
+//	  200: body:GetPermissionCacheStatsReply
+//	  default: body:GenericResp
+func (ctl *DMSController) GetPermissionCacheStats(c echo.Context) error {
+	// get current user id
+	currentUserUid, err := jwt.GetUserUidStrFromContext(c)
+	if err != nil {
+		return NewErrResp(c, err, apiError.DMSServiceErr)
+	}
+
+	reply, err := ctl.DMS.GetPermissionCacheStats(c.Request().Context(), currentUserUid)
+	if nil != err {
+		return NewErrResp(c, err, apiError.DMSServiceErr)
+	}
+	return NewOkRespWithReply(c, reply)
+}
+
 // swagger:route GET /v1/dms/projects Project ListProjects
 //
 // List projects.
@@ -2767,7 +4194,11 @@ func (ctl *DMSController) oauth2Callback(c echo.Context) error {
 	// 2. callbackData.UserExist 为false时，前端会进入手动绑定页面，绑定时调用绑定接口签发tokens
 	// 3. 没错误且用户存在时，签发tokens登录成功
 	if  callbackData.Error == "" && callbackData.UserExist {
-		loginSessionID, err := ctl.DMS.UserUsecase.CreateLoginSession(c.Request().Context(), claims.UserId)
+		loginSessionID, err := ctl.DMS.UserUsecase.CreateLoginSession(c.Request().Context(), claims.UserId, &biz.LoginSessionMeta{
+			LoginType: biz.LoginSessionTypeOAuth2,
+			IP:        c.RealIP(),
+			UserAgent: c.Request().UserAgent(),
+		})
 		if err != nil {
 			return NewErrResp(c, err, apiError.APIServerErr)
 		}
@@ -2859,7 +4290,11 @@ func (ctl *DMSController) BindOauth2User(c echo.Context) error {
 		return NewErrResp(c, err, apiError.APIServerErr)
 	}
 
-	loginSessionID, err := ctl.DMS.UserUsecase.CreateLoginSession(c.Request().Context(), claims.UserId)
+	loginSessionID, err := ctl.DMS.UserUsecase.CreateLoginSession(c.Request().Context(), claims.UserId, &biz.LoginSessionMeta{
+		LoginType: biz.LoginSessionTypeOAuth2,
+		IP:        c.RealIP(),
+		UserAgent: c.Request().UserAgent(),
+	})
 	if err != nil {
 		return NewErrResp(c, err, apiError.APIServerErr)
 	}
@@ -3869,155 +5304,382 @@ func (ctl *DMSController) ListDataExportWorkflows(c echo.Context) error {
 	if err != nil {
 		return NewErrResp(c, err, apiError.DMSServiceErr)
 	}
-	reply, err := ctl.DMS.ListDataExportWorkflow(c.Request().Context(), req, currentUserUid)
+	reply, err := ctl.DMS.ListDataExportWorkflow(c.Request().Context(), req, currentUserUid)
+	if nil != err {
+		return NewErrResp(c, err, apiError.DMSServiceErr)
+	}
+	return NewOkRespWithReply(c, reply)
+}
+
+// swagger:route GET /v1/dms/dashboard/data_export_workflows DataExportWorkflows GetGlobalDataExportWorkflows
+// 
+// Get global data export workflows.
+//
+//	responses:
+//	  200: body:GetGlobalDataExportWorkflowsReply
+//	  default: body:GenericResp
+
+func (ctl *DMSController) GetGlobalDataExportWorkflows(c echo.Context) error {
+	// 内部接口，仅允许sys用户访问
+	currentUserUid, err := jwt.GetUserUidStrFromContext(c)
+	if err != nil {
+		return NewErrResp(c, err, apiError.DMSServiceErr)
+	}
+	if currentUserUid != pkgConst.UIDOfUserSys && currentUserUid != pkgConst.UIDOfUserAdmin {
+		return NewErrResp(c, fmt.Errorf("only sys user can get global data export workflows"), apiError.DMSServiceErr)
+	}
+	req := new(aV1.FilterGlobalDataExportWorkflowReq)
+	err = bindAndValidateReq(c, req)
+	if nil != err {
+		return NewErrResp(c, err, apiError.BadRequestErr)
+	}
+	reply, err := ctl.DMS.GetGlobalWorkflowsList(c.Request().Context(), req)
+	if nil != err {
+		return NewErrResp(c, err, apiError.DMSServiceErr)
+	}
+	return NewOkRespWithReply(c, reply)
+}
+
+// swagger:route GET /v1/dms/projects/data_export_workflows DataExportWorkflows ListAllDataExportWorkflows
+//
+// List all data_export workflow.
+//
+//	responses:
+//	  200: body:ListDataExportWorkflowsReply
+//	  default: body:GenericResp
+func (ctl *DMSController) ListAllDataExportWorkflows(c echo.Context) error {
+	req := new(aV1.ListDataExportWorkflowsReq)
+	err := bindAndValidateReq(c, req)
+	if nil != err {
+		return NewErrResp(c, err, apiError.BadRequestErr)
+	}
+
+	currentUserUid, err := jwt.GetUserUidStrFromContext(c)
+	if err != nil {
+		return NewErrResp(c, err, apiError.DMSServiceErr)
+	}
+	reply, err := ctl.DMS.ListDataExportWorkflow(c.Request().Context(), req, currentUserUid)
+	if nil != err {
+		return NewErrResp(c, err, apiError.DMSServiceErr)
+	}
+	return NewOkRespWithReply(c, reply)
+}
+
+// swagger:route GET /v1/dms/projects/{project_uid}/data_export_workflows/workflow_template_used DataExportWorkflows CheckDataExportWorkflowTemplateUsed
+//
+// Check whether a data-export workflow template id is referenced by local workflows.
+// Soft-deleted SQLE templates do not block already-created workflow runtime; this is a best-effort hint.
+//
+//	responses:
+//	  200: body:CheckDataExportWorkflowTemplateUsedReply
+//	  default: body:GenericResp
+func (ctl *DMSController) CheckDataExportWorkflowTemplateUsed(c echo.Context) error {
+	req := new(aV1.CheckDataExportWorkflowTemplateUsedReq)
+	err := bindAndValidateReq(c, req)
+	if nil != err {
+		return NewErrResp(c, err, apiError.BadRequestErr)
+	}
+	reply, err := ctl.DMS.CheckDataExportWorkflowTemplateUsed(c.Request().Context(), req)
+	if nil != err {
+		return NewErrResp(c, err, apiError.DMSServiceErr)
+	}
+	return NewOkRespWithReply(c, reply)
+}
+
+// swagger:route GET /v1/dms/projects/{project_uid}/data_export_workflows/{data_export_workflow_uid} DataExportWorkflows GetDataExportWorkflow
+//
+// Get data_export workflow.
+//
+//	responses:
+//	  200: body:GetDataExportWorkflowReply
+//	  default: body:GenericResp
+func (ctl *DMSController) GetDataExportWorkflow(c echo.Context) error {
+	req := new(aV1.GetDataExportWorkflowReq)
+	err := bindAndValidateReq(c, req)
+	if nil != err {
+		return NewErrResp(c, err, apiError.BadRequestErr)
+	}
+
+	currentUserUid, err := jwt.GetUserUidStrFromContext(c)
+	if err != nil {
+		return NewErrResp(c, err, apiError.DMSServiceErr)
+	}
+	reply, err := ctl.DMS.GetDataExportWorkflow(c.Request().Context(), req, currentUserUid)
+	if nil != err {
+		return NewErrResp(c, err, apiError.DMSServiceErr)
+	}
+	return NewOkRespWithReply(c, reply)
+}
+
+// swagger:operation POST /v1/dms/projects/{project_uid}/data_export_workflows/cancel DataExportWorkflows CancelDataExportWorkflow
+//
+// Cancel data export workflows.
+//
+// ---
+// parameters:
+//   - name: project_uid
+//     description: project id
+//     in: path
+//     required: true
+//     type: string
+//   - name: payload
+//     required: true
+//     in: body
+//     schema:
+//       "$ref": "#/definitions/CancelDataExportWorkflowReq"
+// responses:
+//   '200':
+//     description: GenericResp
+//     schema:
+//       "$ref": "#/definitions/GenericResp"
+//   default:
+//     description: GenericResp
+//     schema:
+//       "$ref": "#/definitions/GenericResp"
+func (ctl *DMSController) CancelDataExportWorkflow(c echo.Context) error {
+	req := &aV1.CancelDataExportWorkflowReq{}
+	err := bindAndValidateReq(c, req)
+	if nil != err {
+		return NewErrResp(c, err, apiError.BadRequestErr)
+	}
+
+	currentUserUid, err := jwt.GetUserUidStrFromContext(c)
+	if err != nil {
+		return NewErrResp(c, err, apiError.DMSServiceErr)
+	}
+
+	if err = ctl.DMS.CancelDataExportWorkflow(c.Request().Context(), req, currentUserUid); err != nil {
+		return NewErrResp(c, err, apiError.DMSServiceErr)
+	}
+
+	return NewOkResp(c)
+}
+
+// swagger:route POST /v1/dms/projects/{project_uid}/data_export_workflows/{data_export_workflow_uid}/resend_password DataExportWorkflows ResendDataExportWorkflowPassword
+//
+// resend the password of encrypted export files to the creator by notification.
+//
+//	responses:
+//	  200: body:GenericResp
+//	  default: body:GenericResp
+func (ctl *DMSController) ResendDataExportWorkflowPassword(c echo.Context) error {
+	req := &aV1.ResendDataExportWorkflowPasswordReq{}
+	err := bindAndValidateReq(c, req)
+	if nil != err {
+		return NewErrResp(c, err, apiError.BadRequestErr)
+	}
+	// get current user id
+	currentUserUid, err := jwt.GetUserUidStrFromContext(c)
+	if err != nil {
+		return NewErrResp(c, err, apiError.DMSServiceErr)
+	}
+	err = ctl.DMS.ResendDataExportWorkflowPassword(c.Request().Context(), req, currentUserUid)
+	if nil != err {
+		return NewErrResp(c, err, apiError.DMSServiceErr)
+	}
+
+	return NewOkResp(c)
+}
+
+// swagger:operation POST /v1/dms/projects/{project_uid}/data_export_workflows/{data_export_workflow_uid}/previews DataExportWorkflows PreviewDataExportWorkflow
+//
+// Preview masked sample rows and estimated volume of a data export workflow waiting for approval, the preview is stored with the workflow.
+//
+// ---
+// parameters:
+//   - name: project_uid
+//     description: project id
+//     in: path
+//     required: true
+//     type: string
+//   - name: data_export_workflow_uid
+//     in: path
+//     required: true
+//     type: string
+//   - name: payload
+//     in: body
+//     schema:
+//       "$ref": "#/definitions/PreviewDataExportWorkflowReq"
+// responses:
+//   '200':
+//     description: PreviewDataExportWorkflowReply
+//     schema:
+//       "$ref": "#/definitions/PreviewDataExportWorkflowReply"
+//   default:
+//     description: GenericResp
+//     schema:
+//       "$ref": "#/definitions/GenericResp"
+func (ctl *DMSController) PreviewDataExportWorkflow(c echo.Context) error {
+	req := &aV1.PreviewDataExportWorkflowReq{}
+	err := bindAndValidateReq(c, req)
+	if nil != err {
+		return NewErrResp(c, err, apiError.BadRequestErr)
+	}
+
+	currentUserUid, err := jwt.GetUserUidStrFromContext(c)
+	if err != nil {
+		return NewErrResp(c, err, apiError.DMSServiceErr)
+	}
+
+	reply, err := ctl.DMS.PreviewDataExportWorkflow(c.Request().Context(), req, currentUserUid)
 	if nil != err {
 		return NewErrResp(c, err, apiError.DMSServiceErr)
 	}
 	return NewOkRespWithReply(c, reply)
 }
 
-// swagger:route GET /v1/dms/dashboard/data_export_workflows DataExportWorkflows GetGlobalDataExportWorkflows
-// 
-// Get global data export workflows.
+// swagger:route GET /v1/dms/projects/{project_uid}/data_export_workflows/{data_export_workflow_uid}/previews DataExportWorkflows ListDataExportWorkflowPreviews
+//
+// List stored previews of a data export workflow.
 //
 //	responses:
-//	  200: body:GetGlobalDataExportWorkflowsReply
+//	  200: body:ListDataExportWorkflowPreviewsReply
 //	  default: body:GenericResp
+func (ctl *DMSController) ListDataExportWorkflowPreviews(c echo.Context) error {
+	req := &aV1.ListDataExportWorkflowPreviewsReq{}
+	err := bindAndValidateReq(c, req)
+	if nil != err {
+		return NewErrResp(c, err, apiError.BadRequestErr)
+	}
 
-func (ctl *DMSController) GetGlobalDataExportWorkflows(c echo.Context) error {
-	// 内部接口，仅允许sys用户访问
 	currentUserUid, err := jwt.GetUserUidStrFromContext(c)
 	if err != nil {
 		return NewErrResp(c, err, apiError.DMSServiceErr)
 	}
-	if currentUserUid != pkgConst.UIDOfUserSys && currentUserUid != pkgConst.UIDOfUserAdmin {
-		return NewErrResp(c, fmt.Errorf("only sys user can get global data export workflows"), apiError.DMSServiceErr)
-	}
-	req := new(aV1.FilterGlobalDataExportWorkflowReq)
-	err = bindAndValidateReq(c, req)
-	if nil != err {
-		return NewErrResp(c, err, apiError.BadRequestErr)
-	}
-	reply, err := ctl.DMS.GetGlobalWorkflowsList(c.Request().Context(), req)
+
+	reply, err := ctl.DMS.ListDataExportWorkflowPreviews(c.Request().Context(), req, currentUserUid)
 	if nil != err {
 		return NewErrResp(c, err, apiError.DMSServiceErr)
 	}
 	return NewOkRespWithReply(c, reply)
 }
 
-// swagger:route GET /v1/dms/projects/data_export_workflows DataExportWorkflows ListAllDataExportWorkflows
+// swagger:operation POST /v1/dms/projects/{project_uid}/data_export_workflows/{data_export_workflow_uid}/schedule DataExportWorkflows SetDataExportSchedule
 //
-// List all data_export workflow.
+// Set a recurring schedule for an approved data export workflow, each run re-audits the SQL and exports without approval.
 //
-//	responses:
-//	  200: body:ListDataExportWorkflowsReply
-//	  default: body:GenericResp
-func (ctl *DMSController) ListAllDataExportWorkflows(c echo.Context) error {
-	req := new(aV1.ListDataExportWorkflowsReq)
+// ---
+// parameters:
+//   - name: project_uid
+//     description: project id
+//     in: path
+//     required: true
+//     type: string
+//   - name: data_export_workflow_uid
+//     description: data export workflow uid
+//     in: path
+//     required: true
+//     type: string
+//   - name: payload
+//     description: schedule info
+//     in: body
+//     required: true
+//     schema:
+//       "$ref": "#/definitions/SetDataExportSchedulePayload"
+// responses:
+//   '200':
+//     description: SetDataExportScheduleReply
+//     schema:
+//       "$ref": "#/definitions/SetDataExportScheduleReply"
+//   default:
+//     description: GenericResp
+//     schema:
+//       "$ref": "#/definitions/GenericResp"
+func (ctl *DMSController) SetDataExportSchedule(c echo.Context) error {
+	req := &aV1.SetDataExportScheduleReq{}
 	err := bindAndValidateReq(c, req)
 	if nil != err {
 		return NewErrResp(c, err, apiError.BadRequestErr)
 	}
-
+	// get current user id
 	currentUserUid, err := jwt.GetUserUidStrFromContext(c)
 	if err != nil {
 		return NewErrResp(c, err, apiError.DMSServiceErr)
 	}
-	reply, err := ctl.DMS.ListDataExportWorkflow(c.Request().Context(), req, currentUserUid)
+	reply, err := ctl.DMS.SetDataExportSchedule(c.Request().Context(), req, currentUserUid)
 	if nil != err {
 		return NewErrResp(c, err, apiError.DMSServiceErr)
 	}
+
 	return NewOkRespWithReply(c, reply)
 }
 
-// swagger:route GET /v1/dms/projects/{project_uid}/data_export_workflows/workflow_template_used DataExportWorkflows CheckDataExportWorkflowTemplateUsed
+// swagger:route DELETE /v1/dms/projects/{project_uid}/data_export_workflows/{data_export_workflow_uid}/schedule DataExportWorkflows DeleteDataExportSchedule
 //
-// Check whether a data-export workflow template id is referenced by local workflows.
-// Soft-deleted SQLE templates do not block already-created workflow runtime; this is a best-effort hint.
+// Delete the schedule of a data export workflow.
 //
 //	responses:
-//	  200: body:CheckDataExportWorkflowTemplateUsedReply
+//	  200: body:GenericResp
 //	  default: body:GenericResp
-func (ctl *DMSController) CheckDataExportWorkflowTemplateUsed(c echo.Context) error {
-	req := new(aV1.CheckDataExportWorkflowTemplateUsedReq)
+func (ctl *DMSController) DeleteDataExportSchedule(c echo.Context) error {
+	req := &aV1.DeleteDataExportScheduleReq{}
 	err := bindAndValidateReq(c, req)
 	if nil != err {
 		return NewErrResp(c, err, apiError.BadRequestErr)
 	}
-	reply, err := ctl.DMS.CheckDataExportWorkflowTemplateUsed(c.Request().Context(), req)
+	// get current user id
+	currentUserUid, err := jwt.GetUserUidStrFromContext(c)
+	if err != nil {
+		return NewErrResp(c, err, apiError.DMSServiceErr)
+	}
+	err = ctl.DMS.DeleteDataExportSchedule(c.Request().Context(), req, currentUserUid)
 	if nil != err {
 		return NewErrResp(c, err, apiError.DMSServiceErr)
 	}
-	return NewOkRespWithReply(c, reply)
+
+	return NewOkResp(c)
 }
 
-// swagger:route GET /v1/dms/projects/{project_uid}/data_export_workflows/{data_export_workflow_uid} DataExportWorkflows GetDataExportWorkflow
+// swagger:route POST /v1/dms/projects/{project_uid}/data_export_workflows/{data_export_workflow_uid}/schedule/resume DataExportWorkflows ResumeDataExportSchedule
 //
-// Get data_export workflow.
+// Resume a suspended data export schedule.
 //
 //	responses:
-//	  200: body:GetDataExportWorkflowReply
+//	  200: body:GenericResp
 //	  default: body:GenericResp
-func (ctl *DMSController) GetDataExportWorkflow(c echo.Context) error {
-	req := new(aV1.GetDataExportWorkflowReq)
+func (ctl *DMSController) ResumeDataExportSchedule(c echo.Context) error {
+	req := &aV1.ResumeDataExportScheduleReq{}
 	err := bindAndValidateReq(c, req)
 	if nil != err {
 		return NewErrResp(c, err, apiError.BadRequestErr)
 	}
-
+	// get current user id
 	currentUserUid, err := jwt.GetUserUidStrFromContext(c)
 	if err != nil {
 		return NewErrResp(c, err, apiError.DMSServiceErr)
 	}
-	reply, err := ctl.DMS.GetDataExportWorkflow(c.Request().Context(), req, currentUserUid)
+	err = ctl.DMS.ResumeDataExportSchedule(c.Request().Context(), req, currentUserUid)
 	if nil != err {
 		return NewErrResp(c, err, apiError.DMSServiceErr)
 	}
-	return NewOkRespWithReply(c, reply)
+
+	return NewOkResp(c)
 }
 
-// swagger:operation POST /v1/dms/projects/{project_uid}/data_export_workflows/cancel DataExportWorkflows CancelDataExportWorkflow
+// swagger:route GET /v1/dms/projects/{project_uid}/data_export_workflows/schedules DataExportWorkflows ListDataExportSchedules
 //
-// Cancel data export workflows.
+// List data export schedules in the project, project admins see all schedules.
 //
-// ---
-// parameters:
-//   - name: project_uid
-//     description: project id
-//     in: path
-//     required: true
-//     type: string
-//   - name: payload
-//     required: true
-//     in: body
-//     schema:
-//       "$ref": "#/definitions/CancelDataExportWorkflowReq"
-// responses:
-//   '200':
-//     description: GenericResp
-//     schema:
-//       "$ref": "#/definitions/GenericResp"
-//   default:
-//     description: GenericResp
-//     schema:
-//       "$ref": "#/definitions/GenericResp"
-func (ctl *DMSController) CancelDataExportWorkflow(c echo.Context) error {
-	req := &aV1.CancelDataExportWorkflowReq{}
+//	responses:
+//	  200: body:ListDataExportSchedulesReply
+//	  default: body:GenericResp
+func (ctl *DMSController) ListDataExportSchedules(c echo.Context) error {
+	req := &aV1.ListDataExportSchedulesReq{}
 	err := bindAndValidateReq(c, req)
 	if nil != err {
 		return NewErrResp(c, err, apiError.BadRequestErr)
 	}
-
+	// get current user id
 	currentUserUid, err := jwt.GetUserUidStrFromContext(c)
 	if err != nil {
 		return NewErrResp(c, err, apiError.DMSServiceErr)
 	}
-
-	if err = ctl.DMS.CancelDataExportWorkflow(c.Request().Context(), req, currentUserUid); err != nil {
+	reply, err := ctl.DMS.ListDataExportSchedules(c.Request().Context(), req, currentUserUid)
+	if nil != err {
 		return NewErrResp(c, err, apiError.DMSServiceErr)
 	}
 
-	return NewOkResp(c)
+	return NewOkRespWithReply(c, reply)
 }
 
 // swagger:route POST /v1/dms/projects/{project_uid}/data_export_workflows/{data_export_workflow_uid}/export DataExportWorkflows ExportDataExportWorkflow
@@ -4049,6 +5711,7 @@ func (ctl *DMSController) ExportDataExportWorkflow(c echo.Context) error {
 // swagger:route GET /v1/dms/projects/{project_uid}/data_export_workflows/{data_export_workflow_uid}/original-export/download DataExportWorkflows DownloadOriginalDataExportWorkflow
 //
 // Download unmasked SQL query results as a zip file. Each request runs export in memory; files are not persisted.
+// Range and If-Range requests are supported, the strong ETag is the sha-256 of the file.
 //
 //	responses:
 //	  200: DownloadOriginalDataExportWorkflowReply
@@ -4068,8 +5731,14 @@ func (ctl *DMSController) DownloadOriginalDataExportWorkflow(c echo.Context) err
 	}
 	c.Response().Header().Set(echo.HeaderContentDisposition,
 		mime.FormatMediaType("attachment", map[string]string{"filename": fileName}))
+	c.Response().Header().Set(echo.HeaderContentType, "application/zip")
+	checksum := sha256.Sum256(content)
+	if _, err := setDownloadChecksumHeaders(c, hex.EncodeToString(checksum[:])); err != nil {
+		return NewErrResp(c, err, apiError.DMSServiceErr)
+	}
 
-	return c.Blob(http.StatusOK, "application/zip", content)
+	http.ServeContent(c.Response(), c.Request(), fileName, time.Time{}, bytes.NewReader(content))
+	return nil
 }
 
 // swagger:operation POST /v1/dms/projects/{project_uid}/data_export_tasks DataExportTask AddDataExportTask
@@ -4194,7 +5863,11 @@ func (ctl *DMSController) DownloadDataExportTaskSQLs(c echo.Context) error {
 
 // swagger:route GET /v1/dms/projects/{project_uid}/data_export_tasks/{data_export_task_uid}/download DataExportTask DownloadDataExportTask
 //
-// download task file.
+// download task file. Range and If-Range requests are supported to resume the download, the strong ETag is the sha-256 of the file;
+// a request with a single open range "bytes=N-" and an If-Range matching the ETag resumes the latest download of the same user or link
+// when that download is incomplete and N is where it stopped, and is not counted in the download limit. Other requests are new downloads.
+// Files of watermarked tasks carry a new fingerprint in the archive comment for each new download, the ETag then also contains the
+// fingerprint so that resumed requests get the same content, and Repr-Digest is not sent.
 //
 //	responses:
 //	  200: DownloadDataExportTaskReply
@@ -4211,6 +5884,11 @@ func (ctl *DMSController) DownloadDataExportTask(c echo.Context) error {
 		return NewErrResp(c, err, apiError.DMSServiceErr)
 	}
 
+	return ctl.serveDataExportTask(c, req, currentUserUid, nil)
+}
+
+// serveDataExportTask 下载导出文件，文件在其他节点时转发请求，由文件所在节点记录下载
+func (ctl *DMSController) serveDataExportTask(c echo.Context, req *aV1.DownloadDataExportTaskReq, currentUserUid string, link *biz.DataExportDownloadLink) error {
 	isProxy, filePath, err := ctl.DMS.DownloadDataExportTask(c.Request().Context(), req, currentUserUid)
 	if nil != err {
 		ctl.log.Errorf("DownloadDataExportTask failed: task_uid=%s user_uid=%s error=%v", req.DataExportTaskUid, currentUserUid, err)
@@ -4222,12 +5900,281 @@ func (ctl *DMSController) DownloadDataExportTask(c echo.Context) error {
 		return ctl.proxyDownloadDataExportTask(c, filePath)
 	}
 
-	ctl.log.Infof("DownloadDataExportTask local: task_uid=%s user_uid=%s file=%s", req.DataExportTaskUid, currentUserUid, filePath)
+	task, err := ctl.DMS.GetDownloadDataExportTask(c.Request().Context(), req)
+	if err != nil {
+		return NewErrResp(c, err, apiError.DMSServiceErr)
+	}
+	watermarked := task.WatermarkMode != ""
+	// 多个范围的请求按完整下载处理，保证每次发送的内容在文件中连续，便于校验续传的位置
+	if strings.Contains(c.Request().Header.Get(headerRange), ",") {
+		c.Request().Header.Del(headerRange)
+	}
+	// 续传的 If-Range 须为本文件的 ETag，开启水印时沿用其中的下载指纹，续传前后内容一致；文件的 SHA-256 未计算时不支持续传
+	resumed, fingerprint := false, ""
+	if offset, ifRange, ok := parseResumeRange(c.Request()); ok && task.ExportFileChecksum != "" {
+		if checksum, fp, ok := parseDownloadETag(ifRange); ok && checksum == task.ExportFileChecksum && (fp != "") == watermarked {
+			resumed, err = ctl.DMS.CanResumeDataExportDownload(c.Request().Context(), req, currentUserUid, link, fp, offset)
+			if err != nil {
+				return NewErrResp(c, err, apiError.DMSServiceErr)
+			}
+			if resumed {
+				fingerprint = fp
+			}
+		}
+	}
+	if !resumed {
+		// 新的下载先原子地预留下载次数，并发请求不会超过下载次数限制
+		if err := ctl.DMS.ReserveDataExportDownload(c.Request().Context(), req); err != nil {
+			return NewErrResp(c, err, apiError.DMSServiceErr)
+		}
+		if link != nil {
+			if err := ctl.DMS.UseDataExportDownloadLink(c.Request().Context(), link); err != nil {
+				ctl.releaseDataExportDownload(c, req)
+				return NewErrResp(c, err, apiError.BadRequestErr)
+			}
+		}
+		fingerprint, err = ctl.DMS.PrepareDataExportDownloadWatermark(c.Request().Context(), req, task, currentUserUid)
+		if err != nil {
+			ctl.releaseDataExportDownload(c, req)
+			return NewErrResp(c, err, apiError.DMSServiceErr)
+		}
+	}
+
+	file, err := ctl.DMS.OpenDataExportDownloadFile(filePath, fingerprint)
+	if err == nil {
+		defer file.Close()
+		err = setDataExportDownloadHeaders(c, task.ExportFileChecksum, fingerprint, file.Size(), file.ModTime)
+	}
+	if err != nil {
+		if !resumed {
+			ctl.releaseDataExportDownload(c, req)
+		}
+		return NewErrResp(c, err, apiError.DMSServiceErr)
+	}
+
+	ctl.log.Infof("DownloadDataExportTask local: task_uid=%s user_uid=%s file=%s resumed=%v fingerprint=%s", req.DataExportTaskUid, currentUserUid, filePath, resumed, fingerprint)
 	fileName := filepath.Base(filePath)
 	c.Response().Header().Set(echo.HeaderContentDisposition,
 		mime.FormatMediaType("attachment", map[string]string{"filename": fileName}))
 
-	return c.File(filePath)
+	// http.ServeContent 处理 Range/If-Range
+	http.ServeContent(c.Response(), c.Request(), fileName, file.ModTime, file)
+	if status := c.Response().Status; status != http.StatusOK && status != http.StatusPartialContent {
+		if !resumed {
+			ctl.releaseDataExportDownload(c, req)
+		}
+		return nil
+	}
+	if err := ctl.DMS.RecordDataExportDownload(c.Request().Context(), req, link, &biz.DataExportDownload{
+		UserUID:     currentUserUid,
+		IP:          biz.ExtractClientIP(c.Request()),
+		Bytes:       c.Response().Size,
+		StartOffset: contentRangeStart(c.Response().Header().Get(headerContentRange)),
+		Size:        file.Size(),
+		Fingerprint: fingerprint,
+		Resumed:     resumed,
+	}); err != nil {
+		ctl.log.Errorf("RecordDataExportDownload failed: task_uid=%s user_uid=%s error=%v", req.DataExportTaskUid, currentUserUid, err)
+	}
+	return nil
+}
+
+// releaseDataExportDownload 下载未成功时归还预留的下载次数
+func (ctl *DMSController) releaseDataExportDownload(c echo.Context, req *aV1.DownloadDataExportTaskReq) {
+	if err := ctl.DMS.ReleaseDataExportDownload(c.Request().Context(), req); err != nil {
+		ctl.log.Errorf("ReleaseDataExportDownload failed: task_uid=%s error=%v", req.DataExportTaskUid, err)
+	}
+}
+
+// swagger:route GET /v1/dms/data_export_download_links/{token} DataExportTask DownloadDataExportTaskByLink
+//
+// Download task file by a signed single-use link, no login required.
+//
+//	responses:
+//	  200: DownloadDataExportTaskReply
+//	  default: body:GenericResp
+func (ctl *DMSController) DownloadDataExportTaskByLink(c echo.Context) error {
+	req := &aV1.DownloadDataExportTaskByLinkReq{}
+	err := bindAndValidateReq(c, req)
+	if nil != err {
+		return NewErrResp(c, err, apiError.BadRequestErr)
+	}
+
+	// 带 If-Range 的续传请求允许已使用的链接，下载前再确认 ETag 及续传位置，不是续传时使用链接会失败
+	_, _, resumeRequested := parseResumeRange(c.Request())
+	downloadReq, link, err := ctl.DMS.VerifyDataExportDownloadLink(c.Request().Context(), req, resumeRequested)
+	if err != nil {
+		return NewErrResp(c, err, apiError.BadRequestErr)
+	}
+	return ctl.serveDataExportTask(c, downloadReq, link.CreateUserUID, link)
+}
+
+// swagger:route GET /v1/dms/projects/{project_uid}/data_export_tasks/{data_export_task_uid}/downloads DataExportTask ListDataExportTaskDownloads
+//
+// List download records of the export task.
+//
+//	responses:
+//	  200: body:ListDataExportTaskDownloadsReply
+//	  default: body:GenericResp
+func (ctl *DMSController) ListDataExportTaskDownloads(c echo.Context) error {
+	req := &aV1.ListDataExportTaskDownloadsReq{}
+	err := bindAndValidateReq(c, req)
+	if nil != err {
+		return NewErrResp(c, err, apiError.BadRequestErr)
+	}
+
+	currentUserUid, err := jwt.GetUserUidStrFromContext(c)
+	if err != nil {
+		return NewErrResp(c, err, apiError.DMSServiceErr)
+	}
+
+	reply, err := ctl.DMS.ListDataExportTaskDownloads(c.Request().Context(), req, currentUserUid)
+	if nil != err {
+		return NewErrResp(c, err, apiError.DMSServiceErr)
+	}
+	return NewOkRespWithReply(c, reply)
+}
+
+// swagger:operation POST /v1/dms/projects/{project_uid}/data_export_tasks/{data_export_task_uid}/download_links DataExportTask CreateDataExportDownloadLink
+//
+// Create a signed single-use download link of the export task, the link can be fetched by tools without login until it expires.
+//
+// ---
+// parameters:
+//   - name: project_uid
+//     description: project id
+//     in: path
+//     required: true
+//     type: string
+//   - name: data_export_task_uid
+//     description: data export task uid
+//     in: path
+//     required: true
+//     type: string
+//   - name: download_link
+//     description: download link options
+//     in: body
+//     required: true
+//     schema:
+//       "$ref": "#/definitions/CreateDataExportDownloadLinkReq"
+// responses:
+//   '200':
+//     description: CreateDataExportDownloadLinkReply
+//     schema:
+//       "$ref": "#/definitions/CreateDataExportDownloadLinkReply"
+//   default:
+//     description: GenericResp
+//     schema:
+//       "$ref": "#/definitions/GenericResp"
+func (ctl *DMSController) CreateDataExportDownloadLink(c echo.Context) error {
+	req := &aV1.CreateDataExportDownloadLinkReq{}
+	err := bindAndValidateReq(c, req)
+	if nil != err {
+		return NewErrResp(c, err, apiError.BadRequestErr)
+	}
+
+	currentUserUid, err := jwt.GetUserUidStrFromContext(c)
+	if err != nil {
+		return NewErrResp(c, err, apiError.DMSServiceErr)
+	}
+
+	reply, err := ctl.DMS.CreateDataExportDownloadLink(c.Request().Context(), req, currentUserUid)
+	if nil != err {
+		return NewErrResp(c, err, apiError.DMSServiceErr)
+	}
+	return NewOkRespWithReply(c, reply)
+}
+
+const (
+	headerRange        = "Range"
+	headerIfRange      = "If-Range"
+	headerContentRange = "Content-Range"
+	headerETag         = "ETag"
+	headerReprDigest   = "Repr-Digest"
+)
+
+// setDownloadChecksumHeaders 以文件内容的 SHA-256 作为强 ETag，并通过 Repr-Digest 发布校验值，返回 ETag
+func setDownloadChecksumHeaders(c echo.Context, checksum string) (string, error) {
+	raw, err := hex.DecodeString(checksum)
+	if err != nil {
+		return "", fmt.Errorf("invalid checksum %q: %v", checksum, err)
+	}
+	etag := downloadETag(checksum, "")
+	c.Response().Header().Set(headerETag, etag)
+	c.Response().Header().Set(headerReprDigest, "sha-256=:"+base64.StdEncoding.EncodeToString(raw)+":")
+	return etag, nil
+}
+
+// parseResumeRange 解析续传请求：只有一个从 offset(>0) 开始到文件末尾的范围，并带有 If-Range
+func parseResumeRange(r *http.Request) (offset int64, ifRange string, ok bool) {
+	ifRange = r.Header.Get(headerIfRange)
+	rangeHeader := r.Header.Get(headerRange)
+	if ifRange == "" || !strings.HasPrefix(rangeHeader, "bytes=") {
+		return 0, "", false
+	}
+	spec := strings.TrimSpace(strings.TrimPrefix(rangeHeader, "bytes="))
+	start, ok := strings.CutSuffix(spec, "-")
+	if !ok {
+		return 0, "", false
+	}
+	offset, err := strconv.ParseInt(start, 10, 64)
+	if err != nil || offset <= 0 {
+		return 0, "", false
+	}
+	return offset, ifRange, true
+}
+
+// downloadETag 导出文件的强 ETag，开启水印时每次下载的内容不同，附加下载指纹
+func downloadETag(checksum, fingerprint string) string {
+	if fingerprint == "" {
+		return `"` + checksum + `"`
+	}
+	return `"` + checksum + "-" + fingerprint + `"`
+}
+
+// parseDownloadETag 解析 downloadETag 生成的 ETag
+func parseDownloadETag(etag string) (checksum, fingerprint string, ok bool) {
+	value, ok := strings.CutPrefix(etag, `"`)
+	if !ok {
+		return "", "", false
+	}
+	value, ok = strings.CutSuffix(value, `"`)
+	if !ok || value == "" {
+		return "", "", false
+	}
+	checksum, fingerprint, _ = strings.Cut(value, "-")
+	return checksum, fingerprint, true
+}
+
+// setDataExportDownloadHeaders 开启水印时内容因下载而异，只设置包含下载指纹的 ETag，不发布文件的校验值；
+// 文件的 SHA-256 未计算时以文件大小及修改时间作为弱 ETag，开启水印时不设置 ETag
+func setDataExportDownloadHeaders(c echo.Context, checksum, fingerprint string, size int64, modTime time.Time) error {
+	if checksum == "" {
+		if fingerprint == "" {
+			c.Response().Header().Set(headerETag, fmt.Sprintf(`W/"%x-%x"`, size, modTime.UnixNano()))
+		}
+		return nil
+	}
+	if fingerprint == "" {
+		_, err := setDownloadChecksumHeaders(c, checksum)
+		return err
+	}
+	c.Response().Header().Set(headerETag, downloadETag(checksum, fingerprint))
+	return nil
+}
+
+// contentRangeStart 返回部分内容响应在文件中的起始位置，完整响应返回 0
+func contentRangeStart(contentRange string) int64 {
+	spec, ok := strings.CutPrefix(contentRange, "bytes ")
+	if !ok {
+		return 0
+	}
+	start, _, _ := strings.Cut(spec, "-")
+	offset, err := strconv.ParseInt(start, 10, 64)
+	if err != nil {
+		return 0
+	}
+	return offset
 }
 
 // nodeProxyScheme returns the scheme used for inter-node reverse proxy.
@@ -5,6 +5,7 @@ import (
 	"net/http"
 	"strings"
 
+	aV1 "github.com/actiontech/dms/api/dms/service/v1"
 	dmsMiddleware "github.com/actiontech/dms/internal/apiserver/middleware"
 	"github.com/actiontech/dms/internal/dms/biz"
 	dmsService "github.com/actiontech/dms/internal/dms/service"
@@ -100,15 +101,30 @@ func (s *APIServer) initRouter() error {
 		userV1.DELETE("/:user_uid", s.DMSController.DelUser, s.DMSController.DMS.GatewayUsecase.Broadcast())
 		userV1.PUT("/:user_uid", s.DMSController.UpdateUser, s.DMSController.DMS.GatewayUsecase.Broadcast())
 		userV1.GET(dmsV1.GetUserOpPermissionRouterWithoutPrefix(":user_uid"), s.DMSController.GetUserOpPermission)
+		userV1.POST(dmsV1.CheckUserDBObjectsOpPermissionRouterWithoutPrefix(":user_uid"), s.DMSController.CheckUserDBObjectsOpPermission)
 		userV1.PUT("", s.DMSController.UpdateCurrentUser, s.DMSController.DMS.GatewayUsecase.Broadcast())
 		userV1.POST("/gen_token", s.DMSController.GenAccessToken)
+		userV1.GET("/access_tokens", s.DMSController.ListAccessTokens)
+		userV1.DELETE("/access_tokens/:access_token_uid", s.DMSController.RevokeAccessToken)
+		userV1.POST("/verify_access_token", s.DMSController.VerifyAccessToken)
 		userV1.POST("/verify_user_login", s.DMSController.VerifyUserLogin)
+		userV1.POST("/:user_uid/unlock", s.DMSController.UnlockUser)
+
+		serviceAccountV1 := v1.Group(dmsV1.ServiceAccountRouterGroup)
+		serviceAccountV1.POST("", s.DMSController.AddServiceAccount)
+		serviceAccountV1.GET("", s.DMSController.ListServiceAccounts)
+		serviceAccountV1.PUT("/:service_account_uid", s.DMSController.UpdateServiceAccount)
+		serviceAccountV1.DELETE("/:service_account_uid", s.DMSController.DelServiceAccount)
+		serviceAccountV1.POST("/:service_account_uid/rotate_secret", s.DMSController.RotateServiceAccountSecret)
+		serviceAccountV1.POST("/token", s.DMSController.IssueServiceAccountToken)
 
 		sessionv1 := v1.Group(dmsV1.SessionRouterGroup)
 		sessionv1.POST("", s.DMSController.AddSession)
 		sessionv1.GET("/user", s.DMSController.GetUserBySession)
 		sessionv1.DELETE("", s.DMSController.DelSession)
 		sessionv1.POST("/refresh", s.DMSController.RefreshSession)
+		sessionv1.GET("/login_sessions", s.DMSController.ListLoginSessions)
+		sessionv1.DELETE("/login_sessions/:session_uid", s.DMSController.RevokeLoginSession)
 
 		userGroupV1 := v1.Group("/dms/user_groups")
 		userGroupV1.POST("", s.DMSController.AddUserGroup)
@@ -121,6 +137,8 @@ func (s *APIServer) initRouter() error {
 		roleV1.GET("", s.DMSController.ListRoles)
 		roleV1.DELETE("/:role_uid", s.DMSController.DelRole)
 		roleV1.PUT("/:role_uid", s.DMSController.UpdateRole)
+		roleV1.POST("/:role_uid/instantiate", s.DMSController.InstantiateRoleTemplate)
+		roleV1.POST("/:role_uid/preview_update", s.DMSController.PreviewUpdateRole)
 
 		memberV1 := v1.Group(dmsV1.MemberRouterGroup)
 		memberV1.POST("", s.DMSController.AddMember)
@@ -138,9 +156,49 @@ func (s *APIServer) initRouter() error {
 		memberGroupV1.PUT("/:member_group_uid", s.DMSController.UpdateMemberGroup)
 		memberGroupV1.DELETE("/:member_group_uid", s.DMSController.DeleteMemberGroup)
 
+		memberAccessRequestV1 := v1.Group("/dms/projects/:project_uid/member_access_requests")
+		memberAccessRequestV1.POST("", s.DMSController.AddMemberAccessRequest)
+		memberAccessRequestV1.GET("", s.DMSController.ListMemberAccessRequests)
+		memberAccessRequestV1.POST("/:access_request_uid/approve", s.DMSController.ApproveMemberAccessRequest)
+		memberAccessRequestV1.POST("/:access_request_uid/reject", s.DMSController.RejectMemberAccessRequest)
+
+		separationOfDutiesV1 := v1.Group("/dms/projects/:project_uid")
+		separationOfDutiesV1.POST("/separation_of_duties_rules", s.DMSController.AddSeparationOfDutiesRule)
+		separationOfDutiesV1.GET("/separation_of_duties_rules", s.DMSController.ListSeparationOfDutiesRules)
+		separationOfDutiesV1.DELETE("/separation_of_duties_rules/:rule_uid", s.DMSController.DelSeparationOfDutiesRule)
+		separationOfDutiesV1.GET("/separation_of_duties_violations", s.DMSController.ListSeparationOfDutiesViolations)
+
+		dataExportQuotaV1 := v1.Group("/dms/data_export_quotas")
+		dataExportQuotaV1.PUT("", s.DMSController.SetDataExportQuota)
+		dataExportQuotaV1.GET("", s.DMSController.ListDataExportQuotas)
+
+		dataExportDeliveryTargetV1 := v1.Group("/dms/data_export_delivery_targets")
+		dataExportDeliveryTargetV1.POST("", s.DMSController.AddDataExportDeliveryTarget)
+		dataExportDeliveryTargetV1.GET("", s.DMSController.ListDataExportDeliveryTargets)
+		dataExportDeliveryTargetV1.PUT("/:delivery_target_uid", s.DMSController.UpdateDataExportDeliveryTarget)
+		dataExportDeliveryTargetV1.DELETE("/:delivery_target_uid", s.DMSController.DeleteDataExportDeliveryTarget)
+
+		workflowApprovalSLAV1 := v1.Group("/dms/projects/:project_uid/workflow_approval_sla")
+		workflowApprovalSLAV1.GET("", s.DMSController.GetWorkflowApprovalSLA)
+		workflowApprovalSLAV1.PUT("", s.DMSController.SetWorkflowApprovalSLA)
+
+		dataExportWatermarkV1 := v1.Group("/dms/data_export_watermarks")
+		dataExportWatermarkV1.POST("/trace", s.DMSController.TraceDataExport)
+
+		accessReviewV1 := v1.Group("/dms/access_review_campaigns")
+		accessReviewV1.POST("", s.DMSController.AddAccessReviewCampaign)
+		accessReviewV1.GET("", s.DMSController.ListAccessReviewCampaigns)
+		accessReviewV1.GET("/:campaign_uid", s.DMSController.GetAccessReviewCampaign)
+		accessReviewV1.POST("/:campaign_uid/items/:item_uid/review", s.DMSController.ReviewAccessReviewItem)
+		accessReviewV1.GET("/:campaign_uid/export", s.DMSController.ExportAccessReviewEvidence)
+
 		opPermissionV1 := v1.Group("/dms/op_permissions")
 		opPermissionV1.GET("", s.DMSController.ListOpPermissions)
 
+		permissionV1 := v1.Group("/dms/permissions")
+		permissionV1.GET("/explain", s.DMSController.ExplainPermission)
+		permissionV1.GET("/cache_stats", s.DMSController.GetPermissionCacheStats)
+
 		projectV1 := v1.Group(dmsV2.ProjectRouterGroup)
 		projectV1.GET("", s.DMSController.ListProjectsV2) // 兼容jet brain插件，临时开放v1接口
 		projectV1.POST("", s.DeprecatedBy(dmsV2.GroupV2))
@@ -246,6 +304,14 @@ func (s *APIServer) initRouter() error {
 		dataExportWorkflowsV1.POST("/:data_export_workflow_uid/approve", s.DMSController.ApproveDataExportWorkflow)
 		dataExportWorkflowsV1.POST("/:data_export_workflow_uid/reject", s.DMSController.RejectDataExportWorkflow)
 		dataExportWorkflowsV1.POST("/:data_export_workflow_uid/export", s.DMSController.ExportDataExportWorkflow)
+		dataExportWorkflowsV1.POST("/:data_export_workflow_uid/resend_password", s.DMSController.ResendDataExportWorkflowPassword)
+		dataExportWorkflowsV1.POST("/:data_export_workflow_uid/previews", s.DMSController.PreviewDataExportWorkflow)
+		dataExportWorkflowsV1.GET("/:data_export_workflow_uid/previews", s.DMSController.ListDataExportWorkflowPreviews)
+		dataExportWorkflowsV1.GET("/schedules", s.DMSController.ListDataExportSchedules)
+		dataExportWorkflowsV1.GET("/quota_usage", s.DMSController.GetDataExportQuotaUsage)
+		dataExportWorkflowsV1.POST("/:data_export_workflow_uid/schedule", s.DMSController.SetDataExportSchedule)
+		dataExportWorkflowsV1.DELETE("/:data_export_workflow_uid/schedule", s.DMSController.DeleteDataExportSchedule)
+		dataExportWorkflowsV1.POST("/:data_export_workflow_uid/schedule/resume", s.DMSController.ResumeDataExportSchedule)
 		dataExportWorkflowsV1.GET("/:data_export_workflow_uid/original-export/download", s.DMSController.DownloadOriginalDataExportWorkflow)
 		dataExportWorkflowsV1.POST("/cancel", s.DMSController.CancelDataExportWorkflow)
 
@@ -262,6 +328,11 @@ func (s *APIServer) initRouter() error {
 		dataExportTaskV1.GET("/:data_export_task_uid/data_export_task_sqls", s.DMSController.ListDataExportTaskSQLs)
 		dataExportTaskV1.GET("/:data_export_task_uid/data_export_task_sqls/download", s.DMSController.DownloadDataExportTaskSQLs)
 		dataExportTaskV1.GET("/:data_export_task_uid/download", s.DMSController.DownloadDataExportTask)
+		dataExportTaskV1.GET("/:data_export_task_uid/downloads", s.DMSController.ListDataExportTaskDownloads)
+		dataExportTaskV1.POST("/:data_export_task_uid/download_links", s.DMSController.CreateDataExportDownloadLink)
+
+		dataExportDownloadLinkV1 := v1.Group("/dms/data_export_download_links")
+		dataExportDownloadLinkV1.GET("/:token", s.DMSController.DownloadDataExportTaskByLink)
 
 		cbOperationLogsV1 := v1.Group("/dms/projects/:project_uid/cb_operation_logs")
 		cbOperationLogsV1.GET("", s.DMSController.ListCBOperationLogs)
@@ -410,6 +481,8 @@ func (s *APIServer) installMiddleware() error {
 		"/v1/dms/configurations/sms/send_code",
 		"/v1/dms/configurations/sms/verify_code",
 		"/v1/dms/basic_info",
+		aV1.DataExportDownloadLinkRouterGroup,
+		dmsV1.GetServiceAccountTokenRouter(),
 	}
 	var notSkipJWTPaths = []string{
 		sqlWorkbenchService.SQL_WORKBENCH_URL,
@@ -434,6 +507,12 @@ func (s *APIServer) installMiddleware() error {
 		TokenLookup: "cookie:dms-token,header:Authorization:Bearer ", // tell the middleware where to get token: from cookie and header,
 	}))
 	s.echo.Use(s.DMSController.DMS.AuthLoginSessionUsecase.CheckSingleActiveSession(biz.GatewayForwardedHeader))
+	// 密码过期的用户仅允许修改密码、获取当前用户信息及登出
+	s.echo.Use(s.DMSController.DMS.UserUsecase.CheckPasswordExpired(biz.GatewayForwardedHeader,
+		http.MethodPut+" "+dmsV1.CurrentGroupVersion+dmsV1.UserRouterGroup,
+		http.MethodGet+" "+dmsV1.CurrentGroupVersion+dmsV1.SessionRouterGroup+"/user",
+		http.MethodDelete+" "+dmsV1.CurrentGroupVersion+dmsV1.SessionRouterGroup,
+	))
 	s.echo.Use(s.DMSController.DMS.Oauth2ConfigurationUsecase.CheckBackChannelLogoutEvent())
 	// middleware gateway
 	s.echo.Use(middleware.ProxyWithConfig(middleware.ProxyConfig{
@@ -503,7 +582,8 @@ func (s *APIServer) installMiddleware() error {
 
 	s.echo.Use(dmsMiddleware.LicenseAdapter(s.DMSController.DMS.LicenseUsecase))
 
-	s.echo.Use(s.DMSController.DMS.AuthAccessTokenUseCase.CheckLatestAccessToken())
+	s.echo.Use(s.DMSController.DMS.AuthAccessTokenUseCase.CheckAccessToken())
+	s.echo.Use(s.DMSController.DMS.ServiceAccountUsecase.CheckServiceAccountToken())
 
 	s.echo.Use(dmsMiddleware.UserActivityMiddleware(s.DMSController.DMS))
 
@@ -555,6 +635,7 @@ func (s *APIServer) installController() error {
 		return fmt.Errorf("failed to create sql result masker: %v", err)
 	}
 	s.SqlWorkbenchController.SqlWorkbenchService.SetSqlResultMasker(masker)
+	s.DMSController.DMS.DataExportPreviewUsecase.SetResultMasker(masker)
 
 	// 策略 B：DMS 删用户时清理 SqlWorkbench 缓存/会话并禁用 ODC 对应用户
 	s.DMSController.DMS.UserUsecase.SetSqlWorkbenchLifecycle(s.SqlWorkbenchController.SqlWorkbenchService)
@@ -0,0 +1,612 @@
+package biz
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	pkgConst "github.com/actiontech/dms/internal/dms/pkg/constant"
+	pkgErr "github.com/actiontech/dms/internal/dms/pkg/errors"
+	"github.com/actiontech/dms/internal/pkg/locale"
+	utilLog "github.com/actiontech/dms/pkg/dms-common/pkg/log"
+	pkgRand "github.com/actiontech/dms/pkg/rand"
+
+	"github.com/nicksnyder/go-i18n/v2/i18n"
+)
+
+type AccessReviewCampaignStatus string
+
+const (
+	AccessReviewCampaignStatusOpen      AccessReviewCampaignStatus = "open"
+	AccessReviewCampaignStatusCompleted AccessReviewCampaignStatus = "completed"
+)
+
+type AccessReviewDecision string
+
+const (
+	AccessReviewDecisionPending AccessReviewDecision = "pending"
+	AccessReviewDecisionApprove AccessReviewDecision = "approve"
+	AccessReviewDecisionRevoke  AccessReviewDecision = "revoke"
+	// AccessReviewDecisionAutoRevoke 截止时仍未复核，按活动配置自动收回
+	AccessReviewDecisionAutoRevoke AccessReviewDecision = "auto_revoke"
+)
+
+type AccessReviewSubjectType string
+
+const (
+	AccessReviewSubjectTypeMember      AccessReviewSubjectType = "member"
+	AccessReviewSubjectTypeMemberGroup AccessReviewSubjectType = "member_group"
+)
+
+const (
+	accessReviewPageSize = 999
+
+	accessReviewOperationUser = "sys"
+)
+
+var (
+	ErrAccessReviewCampaignClosed = errors.New("access review campaign is already completed")
+	ErrAccessReviewItemReviewed   = errors.New("access review item has already been reviewed")
+)
+
+// AccessReviewCampaign 权限复核活动，创建时按项目及角色范围生成复核项快照
+type AccessReviewCampaign struct {
+	UID         string
+	Name        string
+	ProjectUIDs []string
+	// RoleUIDs 为空表示复核项目内的全部授权
+	RoleUIDs []string
+	Deadline time.Time
+	// AutoRevoke 为 true 时截止后未复核的授权自动收回
+	AutoRevoke    bool
+	Status        AccessReviewCampaignStatus
+	CreateUserUID string
+	CreatedAt     time.Time
+	CompletedAt   time.Time
+}
+
+// AccessReviewItem 一个用户在项目内的一项授权：成员本身，或其所在的某个成员组
+type AccessReviewItem struct {
+	UID         string
+	CampaignUID string
+	ProjectUID  string
+	ProjectName string
+	SubjectType AccessReviewSubjectType
+	// SubjectUID 成员或成员组的 uid，SubjectName 为成员组名称
+	SubjectUID       string
+	SubjectName      string
+	UserUID          string
+	UserName         string
+	RoleWithOpRanges []MemberRoleWithOpRange
+	// LastActiveAt 创建活动时用户的最近活跃时间，零值表示没有活跃记录
+	LastActiveAt time.Time
+	Decision     AccessReviewDecision
+	ReviewerUID  string
+	Comment      string
+	ReviewedAt   time.Time
+}
+
+type ListAccessReviewItemsOption struct {
+	CampaignUID      string
+	FilterByDecision AccessReviewDecision
+}
+
+type AccessReviewRepo interface {
+	SaveAccessReviewCampaign(ctx context.Context, campaign *AccessReviewCampaign, items []*AccessReviewItem) error
+	UpdateAccessReviewCampaign(ctx context.Context, campaign *AccessReviewCampaign) error
+	GetAccessReviewCampaign(ctx context.Context, uid string) (*AccessReviewCampaign, error)
+	ListAccessReviewCampaigns(ctx context.Context) ([]*AccessReviewCampaign, error)
+	ListDueAccessReviewCampaigns(ctx context.Context, now time.Time) ([]*AccessReviewCampaign, error)
+	GetAccessReviewItem(ctx context.Context, uid string) (*AccessReviewItem, error)
+	ListAccessReviewItems(ctx context.Context, opt *ListAccessReviewItemsOption) ([]*AccessReviewItem, error)
+	UpdateAccessReviewItem(ctx context.Context, item *AccessReviewItem) error
+}
+
+type AccessReviewUsecase struct {
+	repo                      AccessReviewRepo
+	userUsecase               *UserUsecase
+	memberUsecase             *MemberUsecase
+	memberGroupUsecase        *MemberGroupUsecase
+	projectUsecase            *ProjectUsecase
+	userActivityUsecase       *UserActivityUsecase
+	opPermissionVerifyUsecase *OpPermissionVerifyUsecase
+	operationRecordUsecase    *OperationRecordUsecase
+	clusterUsecase            *ClusterUsecase
+	log                       *utilLog.Helper
+}
+
+func NewAccessReviewUsecase(log utilLog.Logger, repo AccessReviewRepo,
+	userUsecase *UserUsecase,
+	memberUsecase *MemberUsecase,
+	memberGroupUsecase *MemberGroupUsecase,
+	projectUsecase *ProjectUsecase,
+	userActivityUsecase *UserActivityUsecase,
+	opPermissionVerifyUsecase *OpPermissionVerifyUsecase,
+	operationRecordUsecase *OperationRecordUsecase,
+	clusterUsecase *ClusterUsecase) *AccessReviewUsecase {
+	return &AccessReviewUsecase{
+		repo:                      repo,
+		userUsecase:               userUsecase,
+		memberUsecase:             memberUsecase,
+		memberGroupUsecase:        memberGroupUsecase,
+		projectUsecase:            projectUsecase,
+		userActivityUsecase:       userActivityUsecase,
+		opPermissionVerifyUsecase: opPermissionVerifyUsecase,
+		operationRecordUsecase:    operationRecordUsecase,
+		clusterUsecase:            clusterUsecase,
+		log:                       utilLog.NewHelper(log, utilLog.WithMessageKey("biz.access_review")),
+	}
+}
+
+type CreateAccessReviewCampaignArgs struct {
+	Name        string
+	ProjectUIDs []string
+	RoleUIDs    []string
+	Deadline    time.Time
+	AutoRevoke  bool
+}
+
+func (u *AccessReviewUsecase) CreateAccessReviewCampaign(ctx context.Context, currentUserUid string, args *CreateAccessReviewCampaignArgs) (string, error) {
+	if canGlobalOp, err := u.opPermissionVerifyUsecase.CanOpGlobal(ctx, currentUserUid, false); err != nil {
+		return "", fmt.Errorf("check user is admin or global management permission : %v", err)
+	} else if !canGlobalOp {
+		return "", fmt.Errorf("user is not admin or global management permission")
+	}
+	if len(args.ProjectUIDs) == 0 {
+		return "", fmt.Errorf("at least one project is required")
+	}
+	if !args.Deadline.After(time.Now()) {
+		return "", fmt.Errorf("deadline must be in the future")
+	}
+
+	uid, err := pkgRand.GenStrUid()
+	if err != nil {
+		return "", err
+	}
+	campaign := &AccessReviewCampaign{
+		UID:           uid,
+		Name:          args.Name,
+		ProjectUIDs:   args.ProjectUIDs,
+		RoleUIDs:      args.RoleUIDs,
+		Deadline:      args.Deadline,
+		AutoRevoke:    args.AutoRevoke,
+		Status:        AccessReviewCampaignStatusOpen,
+		CreateUserUID: currentUserUid,
+		CreatedAt:     time.Now(),
+	}
+
+	items := make([]*AccessReviewItem, 0)
+	for _, projectUid := range args.ProjectUIDs {
+		projectItems, err := u.snapshotProject(ctx, campaign, projectUid)
+		if err != nil {
+			return "", err
+		}
+		items = append(items, projectItems...)
+	}
+	u.fillLastActiveAt(ctx, items)
+
+	if err := u.repo.SaveAccessReviewCampaign(ctx, campaign, items); err != nil {
+		return "", fmt.Errorf("save access review campaign failed: %v", err)
+	}
+
+	u.notifyReviewers(ctx, campaign, items)
+	return uid, nil
+}
+
+// snapshotProject 生成项目内的复核项，成员组按组内用户逐一生成，以便单独收回某个用户
+func (u *AccessReviewUsecase) snapshotProject(ctx context.Context, campaign *AccessReviewCampaign, projectUid string) ([]*AccessReviewItem, error) {
+	project, err := u.projectUsecase.GetProject(ctx, projectUid)
+	if err != nil {
+		return nil, fmt.Errorf("get project %s failed: %w", projectUid, err)
+	}
+	if project.Status != ProjectStatusActive {
+		return nil, fmt.Errorf("project %s is not active", project.Name)
+	}
+
+	items := make([]*AccessReviewItem, 0)
+	newItem := func(subjectType AccessReviewSubjectType, subjectUid, subjectName, userUid, userName string, roles []MemberRoleWithOpRange) error {
+		if !campaign.coversRoles(roles) {
+			return nil
+		}
+		uid, err := pkgRand.GenStrUid()
+		if err != nil {
+			return err
+		}
+		items = append(items, &AccessReviewItem{
+			UID:              uid,
+			CampaignUID:      campaign.UID,
+			ProjectUID:       projectUid,
+			ProjectName:      project.Name,
+			SubjectType:      subjectType,
+			SubjectUID:       subjectUid,
+			SubjectName:      subjectName,
+			UserUID:          userUid,
+			UserName:         userName,
+			RoleWithOpRanges: roles,
+			Decision:         AccessReviewDecisionPending,
+		})
+		return nil
+	}
+
+	for page := uint32(1); ; page++ {
+		members, _, err := u.memberUsecase.ListMember(ctx, &ListMembersOption{
+			PageNumber:   page,
+			LimitPerPage: accessReviewPageSize,
+			OrderBy:      MemberFieldUID,
+			FilterByOptions: pkgConst.NewFilterOptions(pkgConst.FilterLogicAnd,
+				pkgConst.NewConditionGroup(pkgConst.FilterLogicAnd, pkgConst.FilterCondition{
+					Field:    string(MemberFieldProjectUID),
+					Operator: pkgConst.FilterOperatorEqual,
+					Value:    projectUid,
+				})),
+		}, projectUid)
+		if err != nil {
+			return nil, fmt.Errorf("list members of project %s failed: %v", project.Name, err)
+		}
+		for _, member := range members {
+			if err := newItem(AccessReviewSubjectTypeMember, member.UID, "", member.UserUID, u.userName(ctx, member.UserUID), member.RoleWithOpRanges); err != nil {
+				return nil, err
+			}
+		}
+		if len(members) < accessReviewPageSize {
+			break
+		}
+	}
+
+	for page := uint32(1); ; page++ {
+		memberGroups, _, err := u.memberGroupUsecase.repo.ListMemberGroups(ctx, &ListMemberGroupsOption{
+			PageNumber:   page,
+			LimitPerPage: accessReviewPageSize,
+			OrderBy:      MemberGroupFieldUID,
+			FilterByOptions: pkgConst.NewFilterOptions(pkgConst.FilterLogicAnd,
+				pkgConst.NewConditionGroup(pkgConst.FilterLogicAnd, pkgConst.FilterCondition{
+					Field:    string(MemberGroupFieldProjectUID),
+					Operator: pkgConst.FilterOperatorEqual,
+					Value:    projectUid,
+				})),
+		})
+		if err != nil {
+			return nil, fmt.Errorf("list member groups of project %s failed: %v", project.Name, err)
+		}
+		for _, mg := range memberGroups {
+			for _, user := range mg.Users {
+				if err := newItem(AccessReviewSubjectTypeMemberGroup, mg.UID, mg.Name, user.Uid, user.Name, mg.RoleWithOpRanges); err != nil {
+					return nil, err
+				}
+			}
+		}
+		if len(memberGroups) < accessReviewPageSize {
+			break
+		}
+	}
+	return items, nil
+}
+
+func (c *AccessReviewCampaign) coversRoles(roles []MemberRoleWithOpRange) bool {
+	if len(c.RoleUIDs) == 0 {
+		return true
+	}
+	for _, role := range roles {
+		for _, uid := range c.RoleUIDs {
+			if role.RoleUID == uid {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// fillLastActiveAt 活跃记录不可用时不影响活动创建，复核项的最近活跃时间留空
+func (u *AccessReviewUsecase) fillLastActiveAt(ctx context.Context, items []*AccessReviewItem) {
+	userUids := make([]string, 0, len(items))
+	for _, item := range items {
+		userUids = append(userUids, item.UserUID)
+	}
+	lastActiveAt, err := u.userActivityUsecase.ListUsersLastActiveAt(ctx, userUids)
+	if err != nil {
+		u.log.Warnf("list users last active time failed: %v", err)
+		return
+	}
+	for _, item := range items {
+		item.LastActiveAt = lastActiveAt[item.UserUID]
+	}
+}
+
+func (u *AccessReviewUsecase) userName(ctx context.Context, userUid string) string {
+	user, err := u.userUsecase.GetUser(ctx, userUid)
+	if err != nil {
+		u.log.Warnf("get user %s failed: %v", userUid, err)
+		return userUid
+	}
+	return user.Name
+}
+
+func (u *AccessReviewUsecase) isGlobalReviewer(ctx context.Context, userUid string) (bool, error) {
+	canGlobalOp, err := u.opPermissionVerifyUsecase.CanOpGlobal(ctx, userUid, false)
+	if err != nil {
+		return false, fmt.Errorf("check user is admin or global management permission : %v", err)
+	}
+	return canGlobalOp, nil
+}
+
+// reviewableProjects 返回用户可以复核的项目，系统管理员可以复核活动内的全部项目，项目管理员只能复核自己管理的项目
+func (u *AccessReviewUsecase) reviewableProjects(ctx context.Context, userUid string, campaign *AccessReviewCampaign) (map[string]struct{}, error) {
+	ret := make(map[string]struct{})
+	isGlobal, err := u.isGlobalReviewer(ctx, userUid)
+	if err != nil {
+		return nil, err
+	}
+	for _, projectUid := range campaign.ProjectUIDs {
+		if !isGlobal {
+			isAdmin, err := u.opPermissionVerifyUsecase.IsUserProjectAdmin(ctx, userUid, projectUid, false)
+			if err != nil {
+				return nil, fmt.Errorf("check user is project admin failed: %v", err)
+			}
+			if !isAdmin {
+				continue
+			}
+		}
+		ret[projectUid] = struct{}{}
+	}
+	return ret, nil
+}
+
+// ListAccessReviewCampaigns 系统管理员可查看全部活动，项目管理员只能查看包含其管理项目的活动
+func (u *AccessReviewUsecase) ListAccessReviewCampaigns(ctx context.Context, currentUserUid string) ([]*AccessReviewCampaign, error) {
+	campaigns, err := u.repo.ListAccessReviewCampaigns(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("list access review campaigns failed: %v", err)
+	}
+	ret := make([]*AccessReviewCampaign, 0, len(campaigns))
+	for _, campaign := range campaigns {
+		projects, err := u.reviewableProjects(ctx, currentUserUid, campaign)
+		if err != nil {
+			return nil, err
+		}
+		if len(projects) > 0 {
+			ret = append(ret, campaign)
+		}
+	}
+	return ret, nil
+}
+
+// GetAccessReviewCampaign 返回活动及当前用户可复核项目内的复核项
+func (u *AccessReviewUsecase) GetAccessReviewCampaign(ctx context.Context, currentUserUid, campaignUid string) (*AccessReviewCampaign, []*AccessReviewItem, error) {
+	campaign, err := u.repo.GetAccessReviewCampaign(ctx, campaignUid)
+	if err != nil {
+		return nil, nil, fmt.Errorf("get access review campaign failed: %w", err)
+	}
+	projects, err := u.reviewableProjects(ctx, currentUserUid, campaign)
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(projects) == 0 {
+		return nil, nil, fmt.Errorf("no permission to view access review campaign")
+	}
+	items, err := u.repo.ListAccessReviewItems(ctx, &ListAccessReviewItemsOption{CampaignUID: campaignUid})
+	if err != nil {
+		return nil, nil, fmt.Errorf("list access review items failed: %v", err)
+	}
+	ret := make([]*AccessReviewItem, 0, len(items))
+	for _, item := range items {
+		if _, ok := projects[item.ProjectUID]; ok {
+			ret = append(ret, item)
+		}
+	}
+	return campaign, ret, nil
+}
+
+// GetAccessReviewEvidence 仅已结束的活动可导出为复核证据
+func (u *AccessReviewUsecase) GetAccessReviewEvidence(ctx context.Context, currentUserUid, campaignUid string) (*AccessReviewCampaign, []*AccessReviewItem, error) {
+	campaign, items, err := u.GetAccessReviewCampaign(ctx, currentUserUid, campaignUid)
+	if err != nil {
+		return nil, nil, err
+	}
+	if campaign.Status != AccessReviewCampaignStatusCompleted {
+		return nil, nil, fmt.Errorf("access review campaign is not completed yet")
+	}
+	return campaign, items, nil
+}
+
+// ReviewAccessReviewItem 复核人不能复核自己的授权；收回授权会立即移除成员或将用户移出成员组
+func (u *AccessReviewUsecase) ReviewAccessReviewItem(ctx context.Context, currentUserUid, campaignUid, itemUid string, decision AccessReviewDecision, comment string) error {
+	if decision != AccessReviewDecisionApprove && decision != AccessReviewDecisionRevoke {
+		return fmt.Errorf("invalid access review decision: %s", decision)
+	}
+	campaign, err := u.repo.GetAccessReviewCampaign(ctx, campaignUid)
+	if err != nil {
+		return fmt.Errorf("get access review campaign failed: %w", err)
+	}
+	if campaign.Status != AccessReviewCampaignStatusOpen {
+		return ErrAccessReviewCampaignClosed
+	}
+	item, err := u.repo.GetAccessReviewItem(ctx, itemUid)
+	if err != nil {
+		return fmt.Errorf("get access review item failed: %w", err)
+	}
+	if item.CampaignUID != campaignUid {
+		return fmt.Errorf("access review item %s does not belong to campaign %s", itemUid, campaignUid)
+	}
+	if item.Decision != AccessReviewDecisionPending {
+		return ErrAccessReviewItemReviewed
+	}
+	if item.UserUID == currentUserUid {
+		return fmt.Errorf("reviewers cannot review their own access")
+	}
+	projects, err := u.reviewableProjects(ctx, currentUserUid, campaign)
+	if err != nil {
+		return err
+	}
+	if _, ok := projects[item.ProjectUID]; !ok {
+		return fmt.Errorf("no permission to review access of project %s", item.ProjectName)
+	}
+
+	if decision == AccessReviewDecisionRevoke {
+		if err := u.revoke(ctx, item, u.userName(ctx, currentUserUid)); err != nil {
+			return err
+		}
+	}
+	item.Decision = decision
+	item.ReviewerUID = currentUserUid
+	item.Comment = comment
+	item.ReviewedAt = time.Now()
+	if err := u.repo.UpdateAccessReviewItem(ctx, item); err != nil {
+		return fmt.Errorf("update access review item failed: %v", err)
+	}
+
+	return u.completeIfReviewed(ctx, campaign)
+}
+
+func (u *AccessReviewUsecase) completeIfReviewed(ctx context.Context, campaign *AccessReviewCampaign) error {
+	pending, err := u.repo.ListAccessReviewItems(ctx, &ListAccessReviewItemsOption{
+		CampaignUID:      campaign.UID,
+		FilterByDecision: AccessReviewDecisionPending,
+	})
+	if err != nil {
+		return fmt.Errorf("list pending access review items failed: %v", err)
+	}
+	if len(pending) > 0 {
+		return nil
+	}
+	campaign.Status = AccessReviewCampaignStatusCompleted
+	campaign.CompletedAt = time.Now()
+	if err := u.repo.UpdateAccessReviewCampaign(ctx, campaign); err != nil {
+		return fmt.Errorf("update access review campaign failed: %v", err)
+	}
+	return nil
+}
+
+// revoke 授权在复核期间已被移除时视为收回成功
+func (u *AccessReviewUsecase) revoke(ctx context.Context, item *AccessReviewItem, operator string) error {
+	switch item.SubjectType {
+	case AccessReviewSubjectTypeMember:
+		if err := u.memberUsecase.repo.DelMember(ctx, item.SubjectUID); err != nil {
+			return fmt.Errorf("revoke member %s failed: %v", item.UserName, err)
+		}
+		u.opPermissionVerifyUsecase.InvalidateProjectPermissions(ctx, item.ProjectUID)
+		u.saveRevokeRecord(ctx, operator, item.ProjectName, locale.OpRecordAccessReviewMemberRevokedWithName, item.UserName)
+	case AccessReviewSubjectTypeMemberGroup:
+		mg, err := u.memberGroupUsecase.repo.GetMemberGroup(ctx, item.SubjectUID)
+		if err != nil {
+			if errors.Is(err, pkgErr.ErrStorageNoData) {
+				return nil
+			}
+			return fmt.Errorf("get member group %s failed: %v", item.SubjectName, err)
+		}
+		// 绑定用户组的成员组不能单独移除用户，需要调整用户组或解除绑定
+		if mg.UserGroupUID != "" {
+			return fmt.Errorf("user %s is granted through user group %s, remove the user from the user group or unbind it from the project", item.UserName, mg.UserGroupName)
+		}
+		userUids := make([]string, 0, len(mg.Users))
+		for _, user := range mg.Users {
+			if user.Uid != item.UserUID {
+				userUids = append(userUids, user.Uid)
+			}
+		}
+		if len(userUids) == len(mg.Users) {
+			return nil
+		}
+		mg.UserUids = userUids
+		if err := u.memberGroupUsecase.repo.UpdateMemberGroup(ctx, mg); err != nil {
+			return fmt.Errorf("remove user %s from member group %s failed: %v", item.UserName, item.SubjectName, err)
+		}
+		u.opPermissionVerifyUsecase.InvalidateProjectPermissions(ctx, item.ProjectUID)
+		if err := u.memberGroupUsecase.pluginUsecase.UpdateMemberGroupAfterHandle(ctx, mg.UID, userUids); err != nil {
+			u.log.Errorf("handle updated member group %s failed: %v", mg.UID, err)
+		}
+		u.saveRevokeRecord(ctx, operator, item.ProjectName, locale.OpRecordAccessReviewGroupUserRevokedWithName, item.UserName, item.SubjectName)
+	default:
+		return fmt.Errorf("invalid access review subject type: %s", item.SubjectType)
+	}
+	return nil
+}
+
+func (u *AccessReviewUsecase) saveRevokeRecord(ctx context.Context, operator, projectName string, content *i18n.Message, args ...any) {
+	if err := u.operationRecordUsecase.SaveOperationRecord(ctx, &OperationRecord{
+		OperationTime:        time.Now(),
+		OperationUserName:    operator,
+		OperationTypeName:    "member",
+		OperationAction:      "access_review_revoke",
+		OperationProjectName: projectName,
+		OperationStatus:      "succeeded",
+		OperationI18nContent: locale.Bundle.LocalizeAllWithArgs(content, args...),
+	}); err != nil {
+		u.log.Errorf("save operation record of access review revoke failed: %v", err)
+	}
+}
+
+// CloseDueAccessReviewCampaigns 定时结束已到截止时间的活动，按配置收回未复核的授权，集群模式下仅由主节点执行
+func (u *AccessReviewUsecase) CloseDueAccessReviewCampaigns() {
+	if u.clusterUsecase.IsClusterMode() && !u.clusterUsecase.IsLeader() {
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
+	defer cancel()
+
+	campaigns, err := u.repo.ListDueAccessReviewCampaigns(ctx, time.Now())
+	if err != nil {
+		u.log.Errorf("list due access review campaigns failed: %v", err)
+		return
+	}
+	for _, campaign := range campaigns {
+		if campaign.AutoRevoke {
+			items, err := u.repo.ListAccessReviewItems(ctx, &ListAccessReviewItemsOption{
+				CampaignUID:      campaign.UID,
+				FilterByDecision: AccessReviewDecisionPending,
+			})
+			if err != nil {
+				u.log.Errorf("list pending items of access review campaign %s failed: %v", campaign.UID, err)
+				continue
+			}
+			for _, item := range items {
+				if err := u.revoke(ctx, item, accessReviewOperationUser); err != nil {
+					u.log.Errorf("auto revoke access review item %s failed: %v", item.UID, err)
+					continue
+				}
+				item.Decision = AccessReviewDecisionAutoRevoke
+				item.ReviewerUID = pkgConst.UIDOfUserSys
+				item.ReviewedAt = time.Now()
+				if err := u.repo.UpdateAccessReviewItem(ctx, item); err != nil {
+					u.log.Errorf("update access review item %s failed: %v", item.UID, err)
+				}
+			}
+		}
+		campaign.Status = AccessReviewCampaignStatusCompleted
+		campaign.CompletedAt = time.Now()
+		if err := u.repo.UpdateAccessReviewCampaign(ctx, campaign); err != nil {
+			u.log.Errorf("complete access review campaign %s failed: %v", campaign.UID, err)
+		}
+	}
+}
+
+// notifyReviewers 通知各项目的项目管理员复核，通知失败不影响活动创建
+func (u *AccessReviewUsecase) notifyReviewers(ctx context.Context, campaign *AccessReviewCampaign, items []*AccessReviewItem) {
+	counts := make(map[string]int)
+	projectNames := make(map[string]string)
+	for _, item := range items {
+		counts[item.ProjectUID]++
+		projectNames[item.ProjectUID] = item.ProjectName
+	}
+	for projectUid, count := range counts {
+		users, err := u.opPermissionVerifyUsecase.ListUsersInProject(ctx, projectUid)
+		if err != nil {
+			u.log.Errorf("list users in project %s failed: %v", projectUid, err)
+			continue
+		}
+		reviewers := make([]*User, 0)
+		for _, item := range users {
+			isAdmin, err := u.opPermissionVerifyUsecase.IsUserProjectAdmin(ctx, item.UserUid, projectUid, false)
+			if err != nil || !isAdmin {
+				continue
+			}
+			reviewer, err := u.userUsecase.GetUser(ctx, item.UserUid)
+			if err != nil {
+				continue
+			}
+			reviewers = append(reviewers, reviewer)
+		}
+		notifyUsersI18n(ctx, u.log, reviewers, locale.NotifyAccessReviewCampaignSubject, locale.NotifyAccessReviewCampaignBody,
+			campaign.Name, projectNames[projectUid], count, campaign.Deadline.Format("2006-01-02 15:04:05"))
+	}
+}
@@ -0,0 +1,98 @@
+package biz
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	pkgConst "github.com/actiontech/dms/internal/dms/pkg/constant"
+	"github.com/stretchr/testify/assert"
+)
+
+type mockAccessReviewRepo struct {
+	campaigns map[string]*AccessReviewCampaign
+	items     map[string]*AccessReviewItem
+}
+
+func (m *mockAccessReviewRepo) SaveAccessReviewCampaign(context.Context, *AccessReviewCampaign, []*AccessReviewItem) error {
+	return nil
+}
+func (m *mockAccessReviewRepo) UpdateAccessReviewCampaign(_ context.Context, campaign *AccessReviewCampaign) error {
+	m.campaigns[campaign.UID] = campaign
+	return nil
+}
+func (m *mockAccessReviewRepo) GetAccessReviewCampaign(_ context.Context, uid string) (*AccessReviewCampaign, error) {
+	c := *m.campaigns[uid]
+	return &c, nil
+}
+func (m *mockAccessReviewRepo) ListAccessReviewCampaigns(context.Context) ([]*AccessReviewCampaign, error) {
+	return nil, nil
+}
+func (m *mockAccessReviewRepo) ListDueAccessReviewCampaigns(context.Context, time.Time) ([]*AccessReviewCampaign, error) {
+	return nil, nil
+}
+func (m *mockAccessReviewRepo) GetAccessReviewItem(_ context.Context, uid string) (*AccessReviewItem, error) {
+	i := *m.items[uid]
+	return &i, nil
+}
+func (m *mockAccessReviewRepo) ListAccessReviewItems(_ context.Context, opt *ListAccessReviewItemsOption) ([]*AccessReviewItem, error) {
+	ret := make([]*AccessReviewItem, 0)
+	for _, i := range m.items {
+		if i.CampaignUID == opt.CampaignUID && (opt.FilterByDecision == "" || i.Decision == opt.FilterByDecision) {
+			ret = append(ret, i)
+		}
+	}
+	return ret, nil
+}
+func (m *mockAccessReviewRepo) UpdateAccessReviewItem(_ context.Context, item *AccessReviewItem) error {
+	m.items[item.UID] = item
+	return nil
+}
+
+func TestReviewAccessReviewItem(t *testing.T) {
+	repo := &mockAccessReviewRepo{
+		campaigns: map[string]*AccessReviewCampaign{
+			"c1": {UID: "c1", ProjectUIDs: []string{"p1", "p2"}, Status: AccessReviewCampaignStatusOpen},
+		},
+		items: map[string]*AccessReviewItem{
+			"i1": {UID: "i1", CampaignUID: "c1", ProjectUID: "p1", UserUID: "user_1", Decision: AccessReviewDecisionPending},
+			"i2": {UID: "i2", CampaignUID: "c1", ProjectUID: "p2", UserUID: "user_2", Decision: AccessReviewDecisionPending},
+			"i3": {UID: "i3", CampaignUID: "c1", ProjectUID: "p1", UserUID: "user_3", Decision: AccessReviewDecisionPending},
+		},
+	}
+	opRepo := &mockOpPermissionVerifyRepo{
+		projectPermissions: map[string]map[string]map[string]bool{
+			"user_1": {"p1": {pkgConst.UIDOfOpPermissionProjectAdmin: true}},
+		},
+	}
+	uc := NewAccessReviewUsecase(&noopLogger{}, repo, nil, nil, nil, nil, nil,
+		newTestOpPermissionVerifyUsecase(&mockUserRepo{}, opRepo), nil, nil)
+	ctx := context.Background()
+
+	t.Run("reviewer_cannot_review_own_access", func(t *testing.T) {
+		assert.Error(t, uc.ReviewAccessReviewItem(ctx, "user_1", "c1", "i1", AccessReviewDecisionApprove, ""))
+	})
+
+	t.Run("project_admin_only_reviews_own_projects", func(t *testing.T) {
+		assert.Error(t, uc.ReviewAccessReviewItem(ctx, "user_1", "c1", "i2", AccessReviewDecisionApprove, ""))
+	})
+
+	t.Run("campaign_completes_when_all_items_reviewed", func(t *testing.T) {
+		assert.NoError(t, uc.ReviewAccessReviewItem(ctx, "user_1", "c1", "i3", AccessReviewDecisionApprove, "still needed"))
+		assert.Equal(t, AccessReviewCampaignStatusOpen, repo.campaigns["c1"].Status)
+		assert.ErrorIs(t, uc.ReviewAccessReviewItem(ctx, "user_1", "c1", "i3", AccessReviewDecisionApprove, ""), ErrAccessReviewItemReviewed)
+
+		assert.NoError(t, uc.ReviewAccessReviewItem(ctx, pkgConst.UIDOfUserAdmin, "c1", "i1", AccessReviewDecisionApprove, ""))
+		assert.NoError(t, uc.ReviewAccessReviewItem(ctx, pkgConst.UIDOfUserAdmin, "c1", "i2", AccessReviewDecisionApprove, ""))
+		assert.Equal(t, AccessReviewCampaignStatusCompleted, repo.campaigns["c1"].Status)
+		assert.ErrorIs(t, uc.ReviewAccessReviewItem(ctx, pkgConst.UIDOfUserAdmin, "c1", "i2", AccessReviewDecisionApprove, ""), ErrAccessReviewCampaignClosed)
+	})
+}
+
+func TestAccessReviewCampaignCoversRoles(t *testing.T) {
+	roles := []MemberRoleWithOpRange{{RoleUID: "r1"}, {RoleUID: "r2"}}
+	assert.True(t, (&AccessReviewCampaign{}).coversRoles(roles))
+	assert.True(t, (&AccessReviewCampaign{RoleUIDs: []string{"r2"}}).coversRoles(roles))
+	assert.False(t, (&AccessReviewCampaign{RoleUIDs: []string{"r3"}}).coversRoles(roles))
+	assert.False(t, (&AccessReviewCampaign{RoleUIDs: []string{"r3"}}).coversRoles(nil))
+}
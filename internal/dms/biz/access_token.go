@@ -1,17 +1,91 @@
 package biz
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"net/http"
+	"strconv"
+	"time"
 
+	pkgErr "github.com/actiontech/dms/internal/dms/pkg/errors"
 	jwtPkg "github.com/actiontech/dms/pkg/dms-common/api/jwt"
 	utilLog "github.com/actiontech/dms/pkg/dms-common/pkg/log"
+	pkgRand "github.com/actiontech/dms/pkg/rand"
 
+	"github.com/golang-jwt/jwt/v4"
 	"github.com/labstack/echo/v4"
 )
 
 const AccessTokenLogin = "access_token_login"
 
+// accessTokenLastUsedUpdateInterval 限制最近使用信息的写库频率，避免每个请求都更新数据库
+const accessTokenLastUsedUpdateInterval = time.Minute
+
+var (
+	ErrAccessTokenRevoked           = errors.New("access token has been revoked or does not exist")
+	ErrAccessTokenReadOnly          = errors.New("access token is read-only")
+	ErrAccessTokenProjectNotAllowed = errors.New("access token is not allowed to access this project")
+	ErrAccessTokenProjectRequired   = errors.New("access token is restricted to projects and is not allowed to access this api")
+)
+
+// AccessTokenScope 描述 access token 的使用范围，由 CheckAccessToken 写入请求上下文，并在权限校验时生效
+type AccessTokenScope struct {
+	ReadOnly bool
+	// ProjectUIDs 为空表示不限制项目
+	ProjectUIDs []string
+}
+
+func (s *AccessTokenScope) IsProjectRestricted() bool {
+	return s != nil && len(s.ProjectUIDs) > 0
+}
+
+func (s *AccessTokenScope) AllowProject(projectUid string) bool {
+	if !s.IsProjectRestricted() {
+		return true
+	}
+	for _, uid := range s.ProjectUIDs {
+		if uid == projectUid {
+			return true
+		}
+	}
+	return false
+}
+
+// AllowGlobal 项目受限的 token 不具备全局权限，只读 token 不具备业务写权限
+func (s *AccessTokenScope) AllowGlobal(isBusinessWrite bool) bool {
+	if s == nil {
+		return true
+	}
+	if s.IsProjectRestricted() {
+		return false
+	}
+	return !(isBusinessWrite && s.ReadOnly)
+}
+
+type accessTokenScopeKey struct{}
+
+func WithAccessTokenScope(ctx context.Context, scope *AccessTokenScope) context.Context {
+	return context.WithValue(ctx, accessTokenScopeKey{}, scope)
+}
+
+// AccessTokenScopeFromContext 获取当前请求 access token 的使用范围，非 access token 请求返回 nil
+func AccessTokenScopeFromContext(ctx context.Context) *AccessTokenScope {
+	scope, _ := ctx.Value(accessTokenScopeKey{}).(*AccessTokenScope)
+	return scope
+}
+
+func (t *AccessTokenInfo) Scope() *AccessTokenScope {
+	return &AccessTokenScope{
+		ReadOnly:    t.ReadOnly,
+		ProjectUIDs: t.ProjectUIDs,
+	}
+}
+
+func (t *AccessTokenInfo) IsExpired(now time.Time) bool {
+	return t.ExpiredTime.Before(now)
+}
+
 type AuthAccessTokenUsecase struct {
 	userUsecase *UserUsecase
 	log         *utilLog.Helper
@@ -25,13 +99,167 @@ func NewAuthAccessTokenUsecase(log utilLog.Logger, usecase *UserUsecase) *AuthAc
 	return au
 }
 
-func (au *AuthAccessTokenUsecase) CheckLatestAccessToken() echo.MiddlewareFunc {
+type GenAccessTokenArgs struct {
+	Name           string
+	ExpirationDays uint64
+	ReadOnly       bool
+	ProjectUIDs    []string
+}
+
+// GenAccessToken 为用户生成一个新的 access token，已有的 token 不受影响
+func (au *AuthAccessTokenUsecase) GenAccessToken(ctx context.Context, userUid string, args *GenAccessTokenArgs) (*AccessTokenInfo, error) {
+	userId, err := strconv.ParseUint(userUid, 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("invalid user uid %s: %v", userUid, err)
+	}
+	policy, err := au.userUsecase.getAccessTokenPolicy(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("get access token policy failed: %v", err)
+	}
+	if err := policy.CheckExpirationDays(args.ExpirationDays); err != nil {
+		return nil, err
+	}
+	uid, err := pkgRand.GenStrUid()
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	name := args.Name
+	if name == "" {
+		name = fmt.Sprintf("access_token_%s", now.Format("20060102150405"))
+	}
+	projectUIDs := make([]string, 0, len(args.ProjectUIDs))
+	seen := make(map[string]struct{}, len(args.ProjectUIDs))
+	for _, projectUid := range args.ProjectUIDs {
+		if _, ok := seen[projectUid]; ok || projectUid == "" {
+			continue
+		}
+		seen[projectUid] = struct{}{}
+		projectUIDs = append(projectUIDs, projectUid)
+	}
+
+	expiredTime := now.Add(time.Duration(args.ExpirationDays) * 24 * time.Hour)
+	token, err := jwtPkg.GenJwtTokenWithExpirationTime(jwt.NewNumericDate(expiredTime),
+		jwtPkg.WithUserId(userUid), jwtPkg.WithAccessTokenMark(AccessTokenLogin), jwtPkg.WithAccessTokenID(uid))
+	if err != nil {
+		return nil, fmt.Errorf("gen access token failed: %v", err)
+	}
+
+	tokenInfo := &AccessTokenInfo{
+		UID:         uid,
+		UserID:      uint(userId),
+		Name:        name,
+		Token:       token,
+		ExpiredTime: expiredTime,
+		ReadOnly:    args.ReadOnly,
+		ProjectUIDs: projectUIDs,
+		CreatedAt:   now,
+	}
+	if err := au.userUsecase.repo.SaveAccessToken(ctx, tokenInfo); err != nil {
+		return nil, fmt.Errorf("save access token failed: %v", err)
+	}
+	return tokenInfo, nil
+}
+
+func (au *AuthAccessTokenUsecase) ListAccessTokens(ctx context.Context, userUid string) ([]*AccessTokenInfo, error) {
+	return au.userUsecase.repo.ListAccessTokensByUser(ctx, userUid)
+}
+
+// RevokeAccessToken 吊销 access token，用户只能吊销自己的 token，拥有全局管理权限的用户可吊销任意用户的 token
+func (au *AuthAccessTokenUsecase) RevokeAccessToken(ctx context.Context, currentUserUid, accessTokenUid string) error {
+	tokenInfo, err := au.userUsecase.repo.GetAccessToken(ctx, accessTokenUid)
+	if err != nil {
+		return fmt.Errorf("get access token failed: %v", err)
+	}
+	if strconv.FormatUint(uint64(tokenInfo.UserID), 10) != currentUserUid {
+		canGlobalOp, err := au.userUsecase.OpPermissionVerifyUsecase.CanOpGlobal(ctx, currentUserUid, false)
+		if err != nil {
+			return fmt.Errorf("check user is admin or global management permission : %v", err)
+		}
+		if !canGlobalOp {
+			return fmt.Errorf("user is not allowed to revoke access token of other users")
+		}
+	}
+	return au.userUsecase.repo.DelAccessToken(ctx, accessTokenUid)
+}
+
+// VerifyAccessToken 校验 access token 是否仍然有效，未携带 token id 的旧版本 token 通过 token 字符串匹配
+func (au *AuthAccessTokenUsecase) VerifyAccessToken(ctx context.Context, tokenDetail *jwtPkg.TokenDetail) (*AccessTokenInfo, error) {
+	var tokenInfo *AccessTokenInfo
+	var err error
+	if tokenDetail.AccessTokenID != "" {
+		tokenInfo, err = au.userUsecase.repo.GetAccessToken(ctx, tokenDetail.AccessTokenID)
+	} else {
+		tokenInfo, err = au.userUsecase.repo.GetAccessTokenByToken(ctx, tokenDetail.UID, tokenDetail.TokenStr)
+	}
+	if errors.Is(err, pkgErr.ErrStorageNoData) {
+		return nil, ErrAccessTokenRevoked
+	}
+	if err != nil {
+		return nil, err
+	}
+	if strconv.FormatUint(uint64(tokenInfo.UserID), 10) != tokenDetail.UID || tokenInfo.Token != tokenDetail.TokenStr {
+		return nil, ErrAccessTokenRevoked
+	}
+	now := time.Now()
+	if tokenInfo.IsExpired(now) {
+		return nil, fmt.Errorf("access token is expired")
+	}
+	policy, err := au.userUsecase.getAccessTokenPolicy(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("get access token policy failed: %v", err)
+	}
+	if err := policy.Check(tokenInfo, now); err != nil {
+		return nil, err
+	}
+	return tokenInfo, nil
+}
+
+func (au *AuthAccessTokenUsecase) recordAccessTokenUsed(ctx context.Context, tokenInfo *AccessTokenInfo, clientIP string, now time.Time) {
+	if tokenInfo.LastUsedIP == clientIP && now.Sub(tokenInfo.LastUsedAt) < accessTokenLastUsedUpdateInterval {
+		return
+	}
+	if err := au.userUsecase.repo.UpdateAccessTokenLastUsed(ctx, tokenInfo.UID, now, clientIP); err != nil {
+		au.log.Errorf("update access token %s last used failed: %v", tokenInfo.UID, err)
+	}
+}
+
+func isReadOnlyMethod(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions:
+		return true
+	default:
+		return false
+	}
+}
+
+// checkAccessTokenScope 只读 token 只能发起查询请求，项目受限的 token 只能访问路由参数 project_uid 在范围内的接口
+func checkAccessTokenScope(c echo.Context, scope *AccessTokenScope) error {
+	if scope.ReadOnly && !isReadOnlyMethod(c.Request().Method) {
+		return ErrAccessTokenReadOnly
+	}
+	if !scope.IsProjectRestricted() {
+		return nil
+	}
+	projectUid := c.Param("project_uid")
+	// 路由中无法识别项目时无法确认访问范围，项目受限的 token 不允许访问
+	if projectUid == "" {
+		return ErrAccessTokenProjectRequired
+	}
+	if !scope.AllowProject(projectUid) {
+		return ErrAccessTokenProjectNotAllowed
+	}
+	return nil
+}
+
+// CheckAccessToken 校验 access token 是否被吊销，并对只读及项目受限的 token 做访问限制
+func (au *AuthAccessTokenUsecase) CheckAccessToken() echo.MiddlewareFunc {
 	return func(next echo.HandlerFunc) echo.HandlerFunc {
 		return func(c echo.Context) error {
 			tokenDetail, err := jwtPkg.GetTokenDetailFromContext(c)
 			if err != nil {
-				echo.NewHTTPError(http.StatusUnauthorized, fmt.Sprintf("get token detail failed, err:%v", err))
-				return err
+				return echo.NewHTTPError(http.StatusUnauthorized, fmt.Sprintf("get token detail failed, err:%v", err))
 			}
 
 			// LoginType为空，不需要校验access token
@@ -39,20 +267,29 @@ func (au *AuthAccessTokenUsecase) CheckLatestAccessToken() echo.MiddlewareFunc {
 				return next(c)
 			}
 
+			// 服务账号 token 由 ServiceAccountUsecase.CheckServiceAccountToken 校验
+			if tokenDetail.LoginType == ServiceAccountLogin {
+				return next(c)
+			}
+
 			if tokenDetail.LoginType != AccessTokenLogin {
 				return echo.NewHTTPError(http.StatusUnauthorized, "access token login type is error")
 			}
 
-			accessTokenInfo, err := au.userUsecase.repo.GetAccessTokenByUser(c.Request().Context(), tokenDetail.UID)
-
+			ctx := c.Request().Context()
+			tokenInfo, err := au.VerifyAccessToken(ctx, tokenDetail)
 			if err != nil {
-				return err
+				return echo.NewHTTPError(http.StatusUnauthorized, err.Error())
 			}
 
-			if accessTokenInfo.Token != tokenDetail.TokenStr {
-				return echo.NewHTTPError(http.StatusUnauthorized, "access token is not latest")
+			scope := tokenInfo.Scope()
+			if err := checkAccessTokenScope(c, scope); err != nil {
+				return echo.NewHTTPError(http.StatusForbidden, err.Error())
 			}
 
+			au.recordAccessTokenUsed(ctx, tokenInfo, ExtractClientIP(c.Request()), time.Now())
+
+			c.SetRequest(c.Request().WithContext(WithAccessTokenScope(ctx, scope)))
 			return next(c)
 		}
 	}
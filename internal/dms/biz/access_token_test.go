@@ -0,0 +1,92 @@
+package biz
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	pkgConst "github.com/actiontech/dms/internal/dms/pkg/constant"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAccessTokenScope(t *testing.T) {
+	var noScope *AccessTokenScope
+	assert.True(t, noScope.AllowProject("p1"))
+	assert.True(t, noScope.AllowGlobal(true))
+
+	readOnly := &AccessTokenScope{ReadOnly: true}
+	assert.True(t, readOnly.AllowProject("p1"))
+	assert.True(t, readOnly.AllowGlobal(false))
+	assert.False(t, readOnly.AllowGlobal(true))
+
+	restricted := &AccessTokenScope{ProjectUIDs: []string{"p1"}}
+	assert.True(t, restricted.AllowProject("p1"))
+	assert.False(t, restricted.AllowProject("p2"))
+	assert.False(t, restricted.AllowGlobal(false))
+}
+
+// TestOpPermissionVerifyWithAccessTokenScope 项目受限的 access token 不能越过 token 范围使用用户本身的权限
+func TestOpPermissionVerifyWithAccessTokenScope(t *testing.T) {
+	userRepo := &mockUserRepo{users: map[string]*User{
+		pkgConst.UIDOfUserAdmin: {UID: pkgConst.UIDOfUserAdmin, BusinessWritePermission: true},
+	}}
+	opRepo := &mockOpPermissionVerifyRepo{
+		projectPermissions: map[string]map[string]map[string]bool{
+			"user_1": {
+				"p1": {pkgConst.UIDOfOpPermissionProjectAdmin: true},
+				"p2": {pkgConst.UIDOfOpPermissionProjectAdmin: true},
+			},
+		},
+	}
+	uc := newTestOpPermissionVerifyUsecase(userRepo, opRepo)
+
+	ctx := WithAccessTokenScope(context.Background(), &AccessTokenScope{ProjectUIDs: []string{"p1"}})
+
+	can, err := uc.CanOpProject(ctx, "user_1", "p1", false)
+	assert.NoError(t, err)
+	assert.True(t, can)
+
+	can, err = uc.CanOpProject(ctx, "user_1", "p2", false)
+	assert.NoError(t, err)
+	assert.False(t, can)
+
+	can, err = uc.CanOpProject(context.Background(), "user_1", "p2", false)
+	assert.NoError(t, err)
+	assert.True(t, can)
+
+	can, err = uc.CanOpGlobal(ctx, pkgConst.UIDOfUserAdmin, false)
+	assert.NoError(t, err)
+	assert.False(t, can, "project restricted token should not have global permission")
+
+	readOnlyCtx := WithAccessTokenScope(context.Background(), &AccessTokenScope{ReadOnly: true})
+	can, err = uc.CanOpGlobal(readOnlyCtx, pkgConst.UIDOfUserAdmin, true)
+	assert.NoError(t, err)
+	assert.False(t, can, "read-only token should not have business write permission")
+
+	can, err = uc.CanOpGlobal(readOnlyCtx, pkgConst.UIDOfUserAdmin, false)
+	assert.NoError(t, err)
+	assert.True(t, can)
+}
+
+func TestCheckAccessTokenScope(t *testing.T) {
+	newContext := func(method, projectUid string) echo.Context {
+		c := echo.New().NewContext(httptest.NewRequest(method, "/", nil), httptest.NewRecorder())
+		if projectUid != "" {
+			c.SetParamNames("project_uid")
+			c.SetParamValues(projectUid)
+		}
+		return c
+	}
+
+	assert.NoError(t, checkAccessTokenScope(newContext(http.MethodPost, ""), &AccessTokenScope{}))
+	assert.ErrorIs(t, checkAccessTokenScope(newContext(http.MethodPost, "p1"), &AccessTokenScope{ReadOnly: true}), ErrAccessTokenReadOnly)
+	assert.NoError(t, checkAccessTokenScope(newContext(http.MethodGet, ""), &AccessTokenScope{ReadOnly: true}))
+
+	restricted := &AccessTokenScope{ProjectUIDs: []string{"p1"}}
+	assert.NoError(t, checkAccessTokenScope(newContext(http.MethodGet, "p1"), restricted))
+	assert.ErrorIs(t, checkAccessTokenScope(newContext(http.MethodGet, "p2"), restricted), ErrAccessTokenProjectNotAllowed)
+	// 路由中没有 project_uid 时无法确认访问范围
+	assert.ErrorIs(t, checkAccessTokenScope(newContext(http.MethodGet, ""), restricted), ErrAccessTokenProjectRequired)
+}
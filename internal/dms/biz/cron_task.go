@@ -14,12 +14,17 @@ type CronTaskUsecase struct {
 	userActivityUsecase    *UserActivityUsecase
 	licenseUsecase         *LicenseUsecase
 	oauth2SessionUsecase   *OAuth2SessionUsecase
+	memberAccessUsecase    *MemberAccessRequestUsecase
+	accessReviewUsecase    *AccessReviewUsecase
+	opPermissionVerifyUc   *OpPermissionVerifyUsecase
+	dataExportScheduleUc   *DataExportScheduleUsecase
+	approvalSLAUc          *WorkflowApprovalSLAUsecase
 }
 type cronTask struct {
 	cron *cron.Cron
 }
 
-func NewCronTaskUsecase(log utilLog.Logger, wu *DataExportWorkflowUsecase, cu *CbOperationLogUsecase, oru *OperationRecordUsecase, uau *UserActivityUsecase, os *OAuth2SessionUsecase) *CronTaskUsecase {
+func NewCronTaskUsecase(log utilLog.Logger, wu *DataExportWorkflowUsecase, cu *CbOperationLogUsecase, oru *OperationRecordUsecase, uau *UserActivityUsecase, os *OAuth2SessionUsecase, mau *MemberAccessRequestUsecase, aru *AccessReviewUsecase, opvu *OpPermissionVerifyUsecase, desu *DataExportScheduleUsecase, asu *WorkflowApprovalSLAUsecase) *CronTaskUsecase {
 	ctu := &CronTaskUsecase{
 		log:                    utilLog.NewHelper(log, utilLog.WithMessageKey("biz.cronTask")),
 		cronTask:               &cronTask{cron: cron.New()},
@@ -28,6 +33,11 @@ func NewCronTaskUsecase(log utilLog.Logger, wu *DataExportWorkflowUsecase, cu *C
 		operationRecordUsecase: oru,
 		userActivityUsecase:    uau,
 		oauth2SessionUsecase:   os,
+		memberAccessUsecase:    mau,
+		accessReviewUsecase:    aru,
+		opPermissionVerifyUc:   opvu,
+		dataExportScheduleUc:   desu,
+		approvalSLAUc:          asu,
 	}
 	return ctu
 }
@@ -57,6 +67,30 @@ func (ctu *CronTaskUsecase) InitialTask() error {
 		return err
 	}
 
+	// 临时授权以小时为单位，到期后需要尽快收回
+	if _, err := ctu.cronTask.cron.AddFunc("@every 5m", ctu.memberAccessUsecase.RevokeExpiredGrants); err != nil {
+		return err
+	}
+
+	if _, err := ctu.cronTask.cron.AddFunc("@every 5m", ctu.accessReviewUsecase.CloseDueAccessReviewCampaigns); err != nil {
+		return err
+	}
+
+	// 定时导出的最小间隔为一小时，按分钟检查可保证按时触发
+	if _, err := ctu.cronTask.cron.AddFunc("@every 1m", ctu.dataExportScheduleUc.RunDueDataExportSchedules); err != nil {
+		return err
+	}
+
+	// 审批时效以小时为单位，按 5 分钟检查即可
+	if _, err := ctu.cronTask.cron.AddFunc("@every 5m", ctu.approvalSLAUc.CheckWorkflowApprovalSLA); err != nil {
+		return err
+	}
+
+	// 权限缓存仅在本节点失效，集群模式下需要及时感知其他节点的权限变更
+	if _, err := ctu.cronTask.cron.AddFunc("@every 5s", ctu.opPermissionVerifyUc.SyncPermissionCache); err != nil {
+		return err
+	}
+
 	if err := ctu.registerUserActivityCronTasks(); err != nil {
 		return err
 	}
@@ -0,0 +1,231 @@
+package biz
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"path"
+	"path/filepath"
+	"time"
+
+	"github.com/actiontech/dms/internal/dms/pkg/exportdelivery"
+	"github.com/actiontech/dms/internal/pkg/locale"
+	utilLog "github.com/actiontech/dms/pkg/dms-common/pkg/log"
+	pkgRand "github.com/actiontech/dms/pkg/rand"
+)
+
+type DataExportDeliveryStatus string
+
+const (
+	DataExportDeliveryStatusDelivering DataExportDeliveryStatus = "delivering"
+	DataExportDeliveryStatusDelivered  DataExportDeliveryStatus = "delivered"
+	DataExportDeliveryStatusFailed     DataExportDeliveryStatus = "failed"
+)
+
+// DataExportDeliveryTarget 管理员登记的导出文件投递目标，密钥加密存储
+type DataExportDeliveryTarget struct {
+	UID       string
+	Name      string
+	Config    exportdelivery.Config
+	CreatedAt time.Time
+}
+
+type DataExportDeliveryTargetRepo interface {
+	SaveDataExportDeliveryTarget(ctx context.Context, target *DataExportDeliveryTarget) error
+	UpdateDataExportDeliveryTarget(ctx context.Context, target *DataExportDeliveryTarget) error
+	DeleteDataExportDeliveryTarget(ctx context.Context, uid string) error
+	GetDataExportDeliveryTarget(ctx context.Context, uid string) (*DataExportDeliveryTarget, error)
+	ListDataExportDeliveryTargets(ctx context.Context) ([]*DataExportDeliveryTarget, error)
+}
+
+type DataExportDeliveryUsecase struct {
+	repo                      DataExportDeliveryTargetRepo
+	opPermissionVerifyUsecase *OpPermissionVerifyUsecase
+	log                       *utilLog.Helper
+}
+
+func NewDataExportDeliveryUsecase(log utilLog.Logger, repo DataExportDeliveryTargetRepo, opPermissionVerifyUsecase *OpPermissionVerifyUsecase) *DataExportDeliveryUsecase {
+	return &DataExportDeliveryUsecase{
+		repo:                      repo,
+		opPermissionVerifyUsecase: opPermissionVerifyUsecase,
+		log:                       utilLog.NewHelper(log, utilLog.WithMessageKey("biz.data_export_delivery")),
+	}
+}
+
+func (u *DataExportDeliveryUsecase) checkGlobalOp(ctx context.Context, currentUserUid string) error {
+	canOp, err := u.opPermissionVerifyUsecase.CanOpGlobal(ctx, currentUserUid, false)
+	if err != nil {
+		return fmt.Errorf("check user can op global failed: %v", err)
+	}
+	if !canOp {
+		return fmt.Errorf("only admin can manage data export delivery targets")
+	}
+	return nil
+}
+
+func (u *DataExportDeliveryUsecase) AddDataExportDeliveryTarget(ctx context.Context, currentUserUid string, target *DataExportDeliveryTarget) (string, error) {
+	if err := u.checkGlobalOp(ctx, currentUserUid); err != nil {
+		return "", err
+	}
+	if err := target.Config.Validate(); err != nil {
+		return "", err
+	}
+	uid, err := pkgRand.GenStrUid()
+	if err != nil {
+		return "", err
+	}
+	target.UID = uid
+	if err := u.repo.SaveDataExportDeliveryTarget(ctx, target); err != nil {
+		return "", fmt.Errorf("save data export delivery target failed: %w", err)
+	}
+	return uid, nil
+}
+
+// UpdateDataExportDeliveryTarget 密钥为空时保留原密钥，类型不可修改
+func (u *DataExportDeliveryUsecase) UpdateDataExportDeliveryTarget(ctx context.Context, currentUserUid string, target *DataExportDeliveryTarget) error {
+	if err := u.checkGlobalOp(ctx, currentUserUid); err != nil {
+		return err
+	}
+	old, err := u.repo.GetDataExportDeliveryTarget(ctx, target.UID)
+	if err != nil {
+		return fmt.Errorf("get data export delivery target failed: %w", err)
+	}
+	if target.Config.Type != old.Config.Type {
+		return fmt.Errorf("the type of data export delivery target can not be changed")
+	}
+	if target.Config.SecretAccessKey == "" {
+		target.Config.SecretAccessKey = old.Config.SecretAccessKey
+	}
+	if target.Config.PrivateKey == "" {
+		target.Config.PrivateKey = old.Config.PrivateKey
+	}
+	if err := target.Config.Validate(); err != nil {
+		return err
+	}
+	if err := u.repo.UpdateDataExportDeliveryTarget(ctx, target); err != nil {
+		return fmt.Errorf("update data export delivery target failed: %w", err)
+	}
+	return nil
+}
+
+func (u *DataExportDeliveryUsecase) DeleteDataExportDeliveryTarget(ctx context.Context, currentUserUid, uid string) error {
+	if err := u.checkGlobalOp(ctx, currentUserUid); err != nil {
+		return err
+	}
+	if err := u.repo.DeleteDataExportDeliveryTarget(ctx, uid); err != nil {
+		return fmt.Errorf("delete data export delivery target failed: %w", err)
+	}
+	return nil
+}
+
+// ListDataExportDeliveryTargets 所有用户可查看，用于创建导出工单时选择投递目标
+func (u *DataExportDeliveryUsecase) ListDataExportDeliveryTargets(ctx context.Context) ([]*DataExportDeliveryTarget, error) {
+	return u.repo.ListDataExportDeliveryTargets(ctx)
+}
+
+func (u *DataExportDeliveryUsecase) GetDataExportDeliveryTarget(ctx context.Context, uid string) (*DataExportDeliveryTarget, error) {
+	return u.repo.GetDataExportDeliveryTarget(ctx, uid)
+}
+
+// CheckDataExportDelivery 创建导出工单时检查投递目标存在
+func (d *DataExportWorkflowUsecase) CheckDataExportDelivery(ctx context.Context, workflow *Workflow) error {
+	if workflow.DeliveryTargetUID == "" {
+		return nil
+	}
+	if _, err := d.deliveryUsecase.GetDataExportDeliveryTarget(ctx, workflow.DeliveryTargetUID); err != nil {
+		return fmt.Errorf("get data export delivery target failed: %w", err)
+	}
+	return nil
+}
+
+// DeliverDataExportTask 导出文件生成后由导出执行器调用，将文件上传到工单的投递目标，
+// 上传进度保存在导出任务中，再次调用时从中断处继续；投递结果更新到导出任务并通知申请人
+func (d *DataExportWorkflowUsecase) DeliverDataExportTask(ctx context.Context, workflow *Workflow, task *DataExportTask, localPath string) error {
+	if workflow.DeliveryTargetUID == "" {
+		return nil
+	}
+	err := d.deliverDataExportTask(ctx, workflow, task, localPath)
+	if err != nil {
+		task.DeliveryStatus = DataExportDeliveryStatusFailed
+		task.DeliveryError = err.Error()
+		if updateErr := d.dataExportTaskRepo.BatchUpdateDataExportTaskByIds(ctx, []string{task.UID}, map[string]interface{}{
+			"delivery_status": string(task.DeliveryStatus),
+			"delivery_error":  task.DeliveryError,
+		}); updateErr != nil {
+			d.log.Errorf("update data export task %s delivery status failed: %v", task.UID, updateErr)
+		}
+	}
+	d.notifyDataExportDelivery(ctx, workflow, task)
+	return err
+}
+
+func (d *DataExportWorkflowUsecase) deliverDataExportTask(ctx context.Context, workflow *Workflow, task *DataExportTask, localPath string) error {
+	target, err := d.deliveryUsecase.GetDataExportDeliveryTarget(ctx, workflow.DeliveryTargetUID)
+	if err != nil {
+		return fmt.Errorf("get data export delivery target failed: %w", err)
+	}
+	destination, err := exportdelivery.New(&target.Config)
+	if err != nil {
+		return err
+	}
+
+	state := &exportdelivery.State{}
+	if task.DeliveryState != "" {
+		if err := json.Unmarshal([]byte(task.DeliveryState), state); err != nil {
+			d.log.Warnf("unmarshal data export task %s delivery state failed, restart delivery: %v", task.UID, err)
+			state = &exportdelivery.State{}
+		}
+	}
+	task.DeliveryStatus = DataExportDeliveryStatusDelivering
+	if err := d.dataExportTaskRepo.BatchUpdateDataExportTaskByIds(ctx, []string{task.UID}, map[string]interface{}{
+		"delivery_status": string(task.DeliveryStatus),
+		"delivery_error":  "",
+	}); err != nil {
+		return fmt.Errorf("update data export task delivery status failed: %v", err)
+	}
+
+	checkpoint := func(state *exportdelivery.State) error {
+		data, err := json.Marshal(state)
+		if err != nil {
+			return err
+		}
+		task.DeliveryState = string(data)
+		return d.dataExportTaskRepo.BatchUpdateDataExportTaskByIds(ctx, []string{task.UID}, map[string]interface{}{"delivery_state": task.DeliveryState})
+	}
+	// 按工单分目录，避免不同工单的同名文件相互覆盖
+	location, err := destination.Deliver(ctx, localPath, path.Join(workflow.UID, filepath.Base(localPath)), state, checkpoint)
+	if err != nil {
+		return fmt.Errorf("deliver data export file to %s failed: %w", target.Name, err)
+	}
+
+	task.DeliveryStatus = DataExportDeliveryStatusDelivered
+	task.DeliveryLocation = location
+	task.DeliveryState = ""
+	if err := d.dataExportTaskRepo.BatchUpdateDataExportTaskByIds(ctx, []string{task.UID}, map[string]interface{}{
+		"delivery_status":   string(task.DeliveryStatus),
+		"delivery_location": task.DeliveryLocation,
+		"delivery_state":    "",
+	}); err != nil {
+		return fmt.Errorf("update data export task delivery status failed: %v", err)
+	}
+	return nil
+}
+
+func (d *DataExportWorkflowUsecase) notifyDataExportDelivery(ctx context.Context, workflow *Workflow, task *DataExportTask) {
+	user, err := d.userUsecase.GetUser(ctx, workflow.CreateUserUID)
+	if err != nil {
+		d.log.Errorf("get user %s failed: %v", workflow.CreateUserUID, err)
+		return
+	}
+	projectName := workflow.ProjectUID
+	if project, err := d.projectUsecase.GetProject(ctx, workflow.ProjectUID); err == nil {
+		projectName = project.Name
+	}
+	if task.DeliveryStatus == DataExportDeliveryStatusDelivered {
+		notifyUsersI18n(ctx, d.log, []*User{user}, locale.NotifyDataExportDeliveredSubject, locale.NotifyDataExportDeliveredBody,
+			workflow.Name, projectName, task.DeliveryLocation)
+		return
+	}
+	notifyUsersI18n(ctx, d.log, []*User{user}, locale.NotifyDataExportDeliveryFailedSubject, locale.NotifyDataExportDeliveryFailedBody,
+		workflow.Name, projectName, task.DeliveryError)
+}
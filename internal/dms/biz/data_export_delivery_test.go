@@ -0,0 +1,135 @@
+package biz
+
+import (
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	pkgConst "github.com/actiontech/dms/internal/dms/pkg/constant"
+	pkgErr "github.com/actiontech/dms/internal/dms/pkg/errors"
+	"github.com/actiontech/dms/internal/dms/pkg/exportdelivery"
+	"github.com/actiontech/dms/internal/pkg/locale"
+	"github.com/actiontech/dms/pkg/dms-common/i18nPkg"
+	utilLog "github.com/actiontech/dms/pkg/dms-common/pkg/log"
+	"github.com/stretchr/testify/assert"
+)
+
+type mockDataExportDeliveryTargetRepo struct {
+	targets map[string]*DataExportDeliveryTarget
+}
+
+func (m *mockDataExportDeliveryTargetRepo) SaveDataExportDeliveryTarget(_ context.Context, target *DataExportDeliveryTarget) error {
+	m.targets[target.UID] = target
+	return nil
+}
+func (m *mockDataExportDeliveryTargetRepo) UpdateDataExportDeliveryTarget(_ context.Context, target *DataExportDeliveryTarget) error {
+	m.targets[target.UID] = target
+	return nil
+}
+func (m *mockDataExportDeliveryTargetRepo) DeleteDataExportDeliveryTarget(_ context.Context, uid string) error {
+	delete(m.targets, uid)
+	return nil
+}
+func (m *mockDataExportDeliveryTargetRepo) GetDataExportDeliveryTarget(_ context.Context, uid string) (*DataExportDeliveryTarget, error) {
+	target, ok := m.targets[uid]
+	if !ok {
+		return nil, pkgErr.ErrStorageNoData
+	}
+	copied := *target
+	return &copied, nil
+}
+func (m *mockDataExportDeliveryTargetRepo) ListDataExportDeliveryTargets(context.Context) ([]*DataExportDeliveryTarget, error) {
+	var ret []*DataExportDeliveryTarget
+	for _, t := range m.targets {
+		ret = append(ret, t)
+	}
+	return ret, nil
+}
+
+type mockDeliveryDataExportTaskRepo struct {
+	DataExportTaskRepo
+	updates []map[string]interface{}
+}
+
+func (m *mockDeliveryDataExportTaskRepo) BatchUpdateDataExportTaskByIds(_ context.Context, _ []string, args map[string]interface{}) error {
+	m.updates = append(m.updates, args)
+	return nil
+}
+
+type mockDeliveryProjectRepo struct {
+	ProjectRepo
+}
+
+func (m *mockDeliveryProjectRepo) GetProject(context.Context, string) (*Project, error) {
+	return &Project{Name: "project_1"}, nil
+}
+
+func TestDataExportDeliveryTarget(t *testing.T) {
+	repo := &mockDataExportDeliveryTargetRepo{targets: map[string]*DataExportDeliveryTarget{}}
+	uc := NewDataExportDeliveryUsecase(utilLog.NewMyLogger(io.Discard), repo, newTestOpPermissionVerifyUsecase(&mockUserRepo{}, &mockOpPermissionVerifyRepo{}))
+	ctx := context.Background()
+
+	target := &DataExportDeliveryTarget{Name: "minio", Config: exportdelivery.Config{
+		Type: exportdelivery.TypeS3, Endpoint: "http://minio:9000", Bucket: "exports", AccessKeyID: "ak", SecretAccessKey: "sk",
+	}}
+	_, err := uc.AddDataExportDeliveryTarget(ctx, "user_1", target)
+	assert.Error(t, err)
+	uid, err := uc.AddDataExportDeliveryTarget(ctx, pkgConst.UIDOfUserAdmin, target)
+	assert.NoError(t, err)
+
+	// 未填写密钥时保留原密钥
+	assert.NoError(t, uc.UpdateDataExportDeliveryTarget(ctx, pkgConst.UIDOfUserAdmin, &DataExportDeliveryTarget{UID: uid, Name: "minio", Config: exportdelivery.Config{
+		Type: exportdelivery.TypeS3, Endpoint: "http://minio:9001", Bucket: "exports", AccessKeyID: "ak",
+	}}))
+	updated, err := uc.GetDataExportDeliveryTarget(ctx, uid)
+	assert.NoError(t, err)
+	assert.Equal(t, "http://minio:9001", updated.Config.Endpoint)
+	assert.Equal(t, "sk", updated.Config.SecretAccessKey)
+
+	assert.Error(t, uc.UpdateDataExportDeliveryTarget(ctx, pkgConst.UIDOfUserAdmin, &DataExportDeliveryTarget{UID: uid, Name: "minio", Config: exportdelivery.Config{
+		Type: exportdelivery.TypeLocal, Path: "/mnt/nfs",
+	}}))
+}
+
+func TestDeliverDataExportTask(t *testing.T) {
+	locale.MustInit(&i18nPkg.StdLogger{})
+	dir := t.TempDir()
+	targetRepo := &mockDataExportDeliveryTargetRepo{targets: map[string]*DataExportDeliveryTarget{
+		"d1": {UID: "d1", Name: "nfs", Config: exportdelivery.Config{Type: exportdelivery.TypeLocal, Path: dir}},
+	}}
+	taskRepo := &mockDeliveryDataExportTaskRepo{}
+	uc := &DataExportWorkflowUsecase{
+		dataExportTaskRepo: taskRepo,
+		deliveryUsecase:    &DataExportDeliveryUsecase{repo: targetRepo},
+		userUsecase:        &UserUsecase{repo: &mockUserRepo{users: map[string]*User{"user_1": {UID: "user_1"}}}},
+		projectUsecase:     &ProjectUsecase{repo: &mockDeliveryProjectRepo{}},
+		log:                utilLog.NewHelper(utilLog.NewMyLogger(io.Discard), utilLog.WithMessageKey("test")),
+	}
+	ctx := context.Background()
+
+	localPath := filepath.Join(t.TempDir(), "export.zip")
+	assert.NoError(t, os.WriteFile(localPath, []byte("export data"), 0600))
+
+	workflow := &Workflow{UID: "w1", ProjectUID: "p1", CreateUserUID: "user_1", DeliveryTargetUID: "d1"}
+	task := &DataExportTask{UID: "t1"}
+	assert.NoError(t, uc.DeliverDataExportTask(ctx, workflow, task, localPath))
+	assert.Equal(t, DataExportDeliveryStatusDelivered, task.DeliveryStatus)
+	assert.Equal(t, "file://"+filepath.ToSlash(filepath.Join(dir, "w1", "export.zip")), task.DeliveryLocation)
+	delivered, err := os.ReadFile(filepath.Join(dir, "w1", "export.zip"))
+	assert.NoError(t, err)
+	assert.Equal(t, "export data", string(delivered))
+	assert.Equal(t, string(DataExportDeliveryStatusDelivered), taskRepo.updates[len(taskRepo.updates)-1]["delivery_status"])
+
+	workflow.DeliveryTargetUID = "deleted"
+	task = &DataExportTask{UID: "t2"}
+	assert.Error(t, uc.DeliverDataExportTask(ctx, workflow, task, localPath))
+	assert.Equal(t, DataExportDeliveryStatusFailed, task.DeliveryStatus)
+	assert.NotEmpty(t, task.DeliveryError)
+
+	// 未配置投递目标时不投递
+	task = &DataExportTask{UID: "t3"}
+	assert.NoError(t, uc.DeliverDataExportTask(ctx, &Workflow{UID: "w2"}, task, localPath))
+	assert.Empty(t, task.DeliveryStatus)
+}
@@ -0,0 +1,412 @@
+package biz
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/actiontech/dms/internal/dms/pkg/exportfile"
+	dmsCommonV1 "github.com/actiontech/dms/pkg/dms-common/api/dms/v1"
+	pkgRand "github.com/actiontech/dms/pkg/rand"
+)
+
+type DataExportDownloadVia string
+
+const (
+	DataExportDownloadViaSession DataExportDownloadVia = "session"
+	DataExportDownloadViaLink    DataExportDownloadVia = "link"
+)
+
+const (
+	DefaultDataExportDownloadLinkExpiry = time.Hour
+	MaxDataExportDownloadLinkExpiry     = 7 * 24 * time.Hour
+)
+
+var (
+	ErrDataExportDownloadLimitExceeded = errors.New("the download limit of the data export task is exceeded")
+	ErrDataExportDownloadLinkInvalid   = errors.New("the download link is invalid or expired")
+	ErrDataExportDownloadLinkUsed      = errors.New("the download link has already been used")
+)
+
+// DataExportDownload 导出文件的下载记录
+type DataExportDownload struct {
+	UID         string
+	TaskUID     string
+	WorkflowUID string
+	ProjectUID  string
+	UserUID     string
+	IP          string
+	// Bytes 发送的字节数，StartOffset 为发送内容在文件中的起始位置，Size 为下载内容的总大小
+	Bytes       int64
+	StartOffset int64
+	Size        int64
+	Via         DataExportDownloadVia
+	LinkUID     string
+	// Fingerprint 开启水印时写入归档注释的下载指纹，续传沿用原下载的指纹
+	Fingerprint string
+	// Resumed 断点续传的请求，不计入下载次数
+	Resumed   bool
+	CreatedAt time.Time
+}
+
+// DataExportDownloadLink 免登录下载链接，签名防篡改，到期或使用一次后失效
+type DataExportDownloadLink struct {
+	UID           string
+	TaskUID       string
+	ProjectUID    string
+	CreateUserUID string
+	ExpiresAt     time.Time
+	UsedAt        *time.Time
+}
+
+type DataExportDownloadRepo interface {
+	SaveDataExportDownload(ctx context.Context, download *DataExportDownload) error
+	// ListDataExportDownloads 按下载时间倒序返回
+	ListDataExportDownloads(ctx context.Context, taskUid string) ([]*DataExportDownload, error)
+	// CountDataExportDownloads 返回已预留的下载次数，不含断点续传的请求
+	CountDataExportDownloads(ctx context.Context, taskUid string) (int64, error)
+	// ReserveDataExportDownload 下载次数小于 maxDownloads 时原子地增加一次并返回 true，maxDownloads 不大于 0 时不限制
+	ReserveDataExportDownload(ctx context.Context, taskUid string, maxDownloads int) (bool, error)
+	// ReleaseDataExportDownload 归还一次预留的下载次数
+	ReleaseDataExportDownload(ctx context.Context, taskUid string) error
+	SaveDataExportDownloadLink(ctx context.Context, link *DataExportDownloadLink) error
+	GetDataExportDownloadLink(ctx context.Context, uid string) (*DataExportDownloadLink, error)
+	// UseDataExportDownloadLink 未使用过时标记为已使用并返回 true，并发下只有一次调用成功
+	UseDataExportDownloadLink(ctx context.Context, uid string, usedAt time.Time) (bool, error)
+}
+
+func (d *DataExportWorkflowUsecase) getDataExportWorkflowByTask(ctx context.Context, projectUid, taskUid string) (*Workflow, error) {
+	workflow, err := d.repo.GetDataExportWorkflowByTask(ctx, taskUid)
+	if err != nil {
+		return nil, fmt.Errorf("get data export workflow of task failed: %w", err)
+	}
+	if workflow.ProjectUID != projectUid {
+		return nil, fmt.Errorf("data export task %s not found in project %s", taskUid, projectUid)
+	}
+	return workflow, nil
+}
+
+// CheckDataExportDownloadLimit 检查导出任务已预留的下载次数是否已达到工单的下载次数限制，
+// 仅用于提前拒绝，下载时以 ReserveDataExportDownload 的结果为准
+func (d *DataExportWorkflowUsecase) CheckDataExportDownloadLimit(ctx context.Context, projectUid, taskUid string) error {
+	workflow, err := d.getDataExportWorkflowByTask(ctx, projectUid, taskUid)
+	if err != nil {
+		return err
+	}
+	if workflow.MaxDownloads <= 0 {
+		return nil
+	}
+	count, err := d.downloadRepo.CountDataExportDownloads(ctx, taskUid)
+	if err != nil {
+		return fmt.Errorf("count data export downloads failed: %v", err)
+	}
+	if count >= int64(workflow.MaxDownloads) {
+		return ErrDataExportDownloadLimitExceeded
+	}
+	return nil
+}
+
+// ReserveDataExportDownload 新的下载开始前原子地预留一次下载次数，并发的下载也不会超过工单的下载次数限制；
+// 下载未成功时由 ReleaseDataExportDownload 归还
+func (d *DataExportWorkflowUsecase) ReserveDataExportDownload(ctx context.Context, projectUid, taskUid string) error {
+	workflow, err := d.getDataExportWorkflowByTask(ctx, projectUid, taskUid)
+	if err != nil {
+		return err
+	}
+	reserved, err := d.downloadRepo.ReserveDataExportDownload(ctx, taskUid, workflow.MaxDownloads)
+	if err != nil {
+		return fmt.Errorf("reserve data export download failed: %v", err)
+	}
+	if !reserved {
+		return ErrDataExportDownloadLimitExceeded
+	}
+	return nil
+}
+
+func (d *DataExportWorkflowUsecase) ReleaseDataExportDownload(ctx context.Context, taskUid string) error {
+	if err := d.downloadRepo.ReleaseDataExportDownload(ctx, taskUid); err != nil {
+		return fmt.Errorf("release data export download failed: %v", err)
+	}
+	return nil
+}
+
+// CanResumeDataExportDownload 同一用户（通过下载链接时为同一链接）最近一次下载以同一下载指纹从文件开头连续下载到的位置
+// 未到内容末尾，且续传请求正好从该位置开始时为续传，不计入下载次数；否则应按新的下载处理
+func (d *DataExportWorkflowUsecase) CanResumeDataExportDownload(ctx context.Context, projectUid, taskUid, userUid string, link *DataExportDownloadLink, fingerprint string, offset int64) (bool, error) {
+	if offset <= 0 {
+		return false, nil
+	}
+	if _, err := d.getDataExportWorkflowByTask(ctx, projectUid, taskUid); err != nil {
+		return false, err
+	}
+	downloads, err := d.downloadRepo.ListDataExportDownloads(ctx, taskUid)
+	if err != nil {
+		return false, fmt.Errorf("list data export downloads failed: %v", err)
+	}
+	// 最近一次新的下载及其后的续传
+	ranges := make([][2]int64, 0, len(downloads))
+	var size int64
+	for _, download := range downloads {
+		if download.UserUID != userUid {
+			continue
+		}
+		if link != nil && download.LinkUID != link.UID {
+			continue
+		}
+		if link == nil && download.LinkUID != "" {
+			continue
+		}
+		if download.Fingerprint != fingerprint {
+			return false, nil
+		}
+		ranges = append(ranges, [2]int64{download.StartOffset, download.StartOffset + download.Bytes})
+		if !download.Resumed {
+			size = download.Size
+			break
+		}
+	}
+	prefix := downloadedPrefix(ranges)
+	return prefix < size && offset == prefix, nil
+}
+
+// downloadedPrefix 返回各次下载的内容从文件开头起连续覆盖到的位置
+func downloadedPrefix(ranges [][2]int64) int64 {
+	sort.Slice(ranges, func(i, j int) bool { return ranges[i][0] < ranges[j][0] })
+	var prefix int64
+	for _, r := range ranges {
+		if r[0] > prefix {
+			break
+		}
+		prefix = max(prefix, r[1])
+	}
+	return prefix
+}
+
+// GetDownloadDataExportTask 返回下载的导出任务，ExportFileChecksum 为空时文件的 SHA-256 尚未计算
+func (d *DataExportWorkflowUsecase) GetDownloadDataExportTask(ctx context.Context, taskUid string) (*DataExportTask, error) {
+	tasks, err := d.dataExportTaskRepo.GetDataExportTaskByIds(ctx, []string{taskUid})
+	if err != nil {
+		return nil, fmt.Errorf("get data export task failed: %v", err)
+	}
+	if len(tasks) == 0 {
+		return nil, fmt.Errorf("data export task %s not found", taskUid)
+	}
+	return tasks[0], nil
+}
+
+// SaveDataExportFileChecksum 导出文件生成后由导出执行器调用，计算文件的 SHA-256 并保存到导出任务，
+// 导出文件生成后不再变化，下载时不再读取整个文件
+func (d *DataExportWorkflowUsecase) SaveDataExportFileChecksum(ctx context.Context, task *DataExportTask, localPath string) error {
+	checksum, err := dataExportFileChecksum(localPath)
+	if err != nil {
+		return err
+	}
+	if err := d.dataExportTaskRepo.BatchUpdateDataExportTaskByIds(ctx, []string{task.UID}, map[string]interface{}{"export_file_checksum": checksum}); err != nil {
+		return fmt.Errorf("save data export task checksum failed: %v", err)
+	}
+	task.ExportFileChecksum = checksum
+	return nil
+}
+
+// DataExportDownloadFile 下载的导出文件内容，可按范围读取
+type DataExportDownloadFile struct {
+	*io.SectionReader
+	file *os.File
+	// ModTime 写入下载指纹时内容因下载而异，为零值
+	ModTime time.Time
+}
+
+func (f *DataExportDownloadFile) Close() error {
+	return f.file.Close()
+}
+
+// OpenDataExportDownloadFile 打开下载的导出文件，fingerprint 非空时返回在归档注释中写入下载指纹的内容，原文件不变
+func OpenDataExportDownloadFile(filePath, fingerprint string) (*DataExportDownloadFile, error) {
+	f, err := os.Open(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("open data export file failed: %v", err)
+	}
+	info, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return nil, fmt.Errorf("stat data export file failed: %v", err)
+	}
+	if fingerprint == "" {
+		return &DataExportDownloadFile{SectionReader: io.NewSectionReader(f, 0, info.Size()), file: f, ModTime: info.ModTime()}, nil
+	}
+	comment, err := exportfile.ArchiveComment(fingerprint)
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+	r, err := exportfile.WithArchiveComment(f, info.Size(), comment)
+	if err != nil {
+		f.Close()
+		return nil, fmt.Errorf("write download watermark failed: %v", err)
+	}
+	return &DataExportDownloadFile{SectionReader: r, file: f}, nil
+}
+
+func dataExportFileChecksum(filePath string) (string, error) {
+	f, err := os.Open(filePath)
+	if err != nil {
+		return "", fmt.Errorf("open data export file failed: %v", err)
+	}
+	defer f.Close()
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", fmt.Errorf("read data export file failed: %v", err)
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// RecordDataExportDownload 记录一次成功的下载
+func (d *DataExportWorkflowUsecase) RecordDataExportDownload(ctx context.Context, projectUid string, download *DataExportDownload) error {
+	workflow, err := d.getDataExportWorkflowByTask(ctx, projectUid, download.TaskUID)
+	if err != nil {
+		return err
+	}
+	uid, err := pkgRand.GenStrUid()
+	if err != nil {
+		return err
+	}
+	download.UID = uid
+	download.WorkflowUID = workflow.UID
+	download.ProjectUID = workflow.ProjectUID
+	if err := d.downloadRepo.SaveDataExportDownload(ctx, download); err != nil {
+		return fmt.Errorf("save data export download failed: %v", err)
+	}
+	return nil
+}
+
+// canManageDataExportTask 工单申请人及项目管理员可查看下载记录、生成下载链接
+func (d *DataExportWorkflowUsecase) canManageDataExportTask(ctx context.Context, workflow *Workflow, currentUserUid string) error {
+	if workflow.CreateUserUID == currentUserUid {
+		return nil
+	}
+	canOp, err := d.opPermissionVerifyUsecase.CanOpProject(ctx, currentUserUid, workflow.ProjectUID, false)
+	if err != nil {
+		return fmt.Errorf("check user can op project failed: %v", err)
+	}
+	if !canOp {
+		return fmt.Errorf("user is not the workflow creator or project admin")
+	}
+	return nil
+}
+
+func (d *DataExportWorkflowUsecase) ListDataExportDownloads(ctx context.Context, projectUid, taskUid, currentUserUid string) ([]*DataExportDownload, *Workflow, error) {
+	workflow, err := d.getDataExportWorkflowByTask(ctx, projectUid, taskUid)
+	if err != nil {
+		return nil, nil, err
+	}
+	if err := d.canManageDataExportTask(ctx, workflow, currentUserUid); err != nil {
+		return nil, nil, err
+	}
+	downloads, err := d.downloadRepo.ListDataExportDownloads(ctx, taskUid)
+	if err != nil {
+		return nil, nil, fmt.Errorf("list data export downloads failed: %v", err)
+	}
+	return downloads, workflow, nil
+}
+
+// CreateDataExportDownloadLink 生成免登录的一次性下载链接，下载时以链接创建人的身份校验权限，返回链接中的令牌
+func (d *DataExportWorkflowUsecase) CreateDataExportDownloadLink(ctx context.Context, projectUid, taskUid, currentUserUid string, expiry time.Duration) (string, *DataExportDownloadLink, error) {
+	if expiry <= 0 {
+		expiry = DefaultDataExportDownloadLinkExpiry
+	}
+	if expiry > MaxDataExportDownloadLinkExpiry {
+		return "", nil, fmt.Errorf("download link expiry should not exceed %v", MaxDataExportDownloadLinkExpiry)
+	}
+	workflow, err := d.getDataExportWorkflowByTask(ctx, projectUid, taskUid)
+	if err != nil {
+		return "", nil, err
+	}
+	if err := d.canManageDataExportTask(ctx, workflow, currentUserUid); err != nil {
+		return "", nil, err
+	}
+	if err := d.CheckDataExportDownloadLimit(ctx, projectUid, taskUid); err != nil {
+		return "", nil, err
+	}
+
+	uid, err := pkgRand.GenStrUid()
+	if err != nil {
+		return "", nil, err
+	}
+	link := &DataExportDownloadLink{
+		UID:           uid,
+		TaskUID:       taskUid,
+		ProjectUID:    projectUid,
+		CreateUserUID: currentUserUid,
+		// 签名中的过期时间精确到秒，保存时保持一致
+		ExpiresAt: time.Now().Add(expiry).Truncate(time.Second),
+	}
+	if err := d.downloadRepo.SaveDataExportDownloadLink(ctx, link); err != nil {
+		return "", nil, fmt.Errorf("save data export download link failed: %v", err)
+	}
+	return signDataExportDownloadLink(link.UID, link.ExpiresAt), link, nil
+}
+
+// VerifyDataExportDownloadLink 校验链接签名、有效期及是否已使用，不标记为已使用；续传时允许已使用的链接，
+// 调用方须再由 CanResumeDataExportDownload 确认是续传，否则以 UseDataExportDownloadLink 使用链接时失败
+func (d *DataExportWorkflowUsecase) VerifyDataExportDownloadLink(ctx context.Context, token string, resumed bool) (*DataExportDownloadLink, error) {
+	uid, expiresAt, ok := parseDataExportDownloadLink(token)
+	if !ok || time.Now().After(expiresAt) {
+		return nil, ErrDataExportDownloadLinkInvalid
+	}
+	link, err := d.downloadRepo.GetDataExportDownloadLink(ctx, uid)
+	if err != nil {
+		return nil, ErrDataExportDownloadLinkInvalid
+	}
+	if link.UsedAt != nil && !resumed {
+		return nil, ErrDataExportDownloadLinkUsed
+	}
+	return link, nil
+}
+
+// UseDataExportDownloadLink 开始下载前标记链接已使用，链接已被使用时返回错误
+func (d *DataExportWorkflowUsecase) UseDataExportDownloadLink(ctx context.Context, link *DataExportDownloadLink) error {
+	used, err := d.downloadRepo.UseDataExportDownloadLink(ctx, link.UID, time.Now())
+	if err != nil {
+		return fmt.Errorf("use data export download link failed: %v", err)
+	}
+	if !used {
+		return ErrDataExportDownloadLinkUsed
+	}
+	return nil
+}
+
+func dataExportDownloadLinkSignature(payload string) string {
+	mac := hmac.New(sha256.New, dmsCommonV1.JwtSigningKey)
+	mac.Write([]byte("dms-data-export-download:" + payload))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+func signDataExportDownloadLink(uid string, expiresAt time.Time) string {
+	payload := uid + "." + strconv.FormatInt(expiresAt.Unix(), 10)
+	return payload + "." + dataExportDownloadLinkSignature(payload)
+}
+
+func parseDataExportDownloadLink(token string) (uid string, expiresAt time.Time, ok bool) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return "", time.Time{}, false
+	}
+	payload := parts[0] + "." + parts[1]
+	if !hmac.Equal([]byte(parts[2]), []byte(dataExportDownloadLinkSignature(payload))) {
+		return "", time.Time{}, false
+	}
+	expires, err := strconv.ParseInt(parts[1], 10, 64)
+	if err != nil {
+		return "", time.Time{}, false
+	}
+	return parts[0], time.Unix(expires, 0), true
+}
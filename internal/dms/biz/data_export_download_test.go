@@ -0,0 +1,293 @@
+package biz
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	pkgConst "github.com/actiontech/dms/internal/dms/pkg/constant"
+	pkgErr "github.com/actiontech/dms/internal/dms/pkg/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+type mockDataExportDownloadRepo struct {
+	mu        sync.Mutex
+	downloads []*DataExportDownload
+	links     map[string]*DataExportDownloadLink
+	reserved  map[string]int64
+}
+
+func (m *mockDataExportDownloadRepo) SaveDataExportDownload(_ context.Context, download *DataExportDownload) error {
+	m.downloads = append(m.downloads, download)
+	return nil
+}
+func (m *mockDataExportDownloadRepo) ListDataExportDownloads(_ context.Context, taskUid string) ([]*DataExportDownload, error) {
+	var ret []*DataExportDownload
+	for i := len(m.downloads) - 1; i >= 0; i-- {
+		if m.downloads[i].TaskUID == taskUid {
+			ret = append(ret, m.downloads[i])
+		}
+	}
+	return ret, nil
+}
+func (m *mockDataExportDownloadRepo) CountDataExportDownloads(_ context.Context, taskUid string) (int64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.reserved[taskUid], nil
+}
+func (m *mockDataExportDownloadRepo) ReserveDataExportDownload(_ context.Context, taskUid string, maxDownloads int) (bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.reserved == nil {
+		m.reserved = map[string]int64{}
+	}
+	if maxDownloads > 0 && m.reserved[taskUid] >= int64(maxDownloads) {
+		return false, nil
+	}
+	m.reserved[taskUid]++
+	return true, nil
+}
+func (m *mockDataExportDownloadRepo) ReleaseDataExportDownload(_ context.Context, taskUid string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.reserved[taskUid] > 0 {
+		m.reserved[taskUid]--
+	}
+	return nil
+}
+func (m *mockDataExportDownloadRepo) SaveDataExportDownloadLink(_ context.Context, link *DataExportDownloadLink) error {
+	m.links[link.UID] = link
+	return nil
+}
+func (m *mockDataExportDownloadRepo) GetDataExportDownloadLink(_ context.Context, uid string) (*DataExportDownloadLink, error) {
+	link, ok := m.links[uid]
+	if !ok {
+		return nil, pkgErr.ErrStorageNoData
+	}
+	copied := *link
+	return &copied, nil
+}
+func (m *mockDataExportDownloadRepo) UseDataExportDownloadLink(_ context.Context, uid string, usedAt time.Time) (bool, error) {
+	link, ok := m.links[uid]
+	if !ok || link.UsedAt != nil {
+		return false, nil
+	}
+	link.UsedAt = &usedAt
+	return true, nil
+}
+
+type mockDataExportTaskWorkflowRepo struct {
+	WorkflowRepo
+	workflow *Workflow
+}
+
+func (m *mockDataExportTaskWorkflowRepo) GetDataExportWorkflowByTask(context.Context, string) (*Workflow, error) {
+	return m.workflow, nil
+}
+
+func TestDataExportDownloadLink(t *testing.T) {
+	repo := &mockDataExportDownloadRepo{links: map[string]*DataExportDownloadLink{}}
+	uc := &DataExportWorkflowUsecase{
+		repo:                      &mockDataExportTaskWorkflowRepo{workflow: &Workflow{UID: "w1", ProjectUID: "p1", CreateUserUID: "user_1", MaxDownloads: 2}},
+		downloadRepo:              repo,
+		opPermissionVerifyUsecase: newTestOpPermissionVerifyUsecase(&mockUserRepo{}, &mockOpPermissionVerifyRepo{}),
+	}
+	ctx := context.Background()
+
+	_, _, err := uc.CreateDataExportDownloadLink(ctx, "p1", "t1", "user_2", time.Hour)
+	assert.Error(t, err)
+	_, _, err = uc.CreateDataExportDownloadLink(ctx, "p1", "t1", "user_1", 8*24*time.Hour)
+	assert.Error(t, err)
+	_, _, err = uc.CreateDataExportDownloadLink(ctx, "p2", "t1", "user_1", time.Hour)
+	assert.Error(t, err)
+
+	token, _, err := uc.CreateDataExportDownloadLink(ctx, "p1", "t1", pkgConst.UIDOfUserAdmin, 0)
+	assert.NoError(t, err)
+
+	link, err := uc.VerifyDataExportDownloadLink(ctx, token, false)
+	assert.NoError(t, err)
+	assert.Equal(t, pkgConst.UIDOfUserAdmin, link.CreateUserUID)
+
+	// 篡改过期时间后签名失效
+	parts := strings.Split(token, ".")
+	_, err = uc.VerifyDataExportDownloadLink(ctx, parts[0]+".9999999999."+parts[2], false)
+	assert.ErrorIs(t, err, ErrDataExportDownloadLinkInvalid)
+
+	assert.NoError(t, uc.UseDataExportDownloadLink(ctx, link))
+	assert.ErrorIs(t, uc.UseDataExportDownloadLink(ctx, link), ErrDataExportDownloadLinkUsed)
+	_, err = uc.VerifyDataExportDownloadLink(ctx, token, false)
+	assert.ErrorIs(t, err, ErrDataExportDownloadLinkUsed)
+	// 续传时允许已使用的链接
+	_, err = uc.VerifyDataExportDownloadLink(ctx, token, true)
+	assert.NoError(t, err)
+
+	expired := signDataExportDownloadLink(link.UID, time.Now().Add(-time.Second))
+	_, err = uc.VerifyDataExportDownloadLink(ctx, expired, false)
+	assert.ErrorIs(t, err, ErrDataExportDownloadLinkInvalid)
+}
+
+func TestDataExportDownloadLimit(t *testing.T) {
+	repo := &mockDataExportDownloadRepo{links: map[string]*DataExportDownloadLink{}}
+	uc := &DataExportWorkflowUsecase{
+		repo:         &mockDataExportTaskWorkflowRepo{workflow: &Workflow{UID: "w1", ProjectUID: "p1", CreateUserUID: "user_1", MaxDownloads: 2}},
+		downloadRepo: repo,
+	}
+	ctx := context.Background()
+
+	for i := 0; i < 2; i++ {
+		assert.NoError(t, uc.CheckDataExportDownloadLimit(ctx, "p1", "t1"))
+		assert.NoError(t, uc.ReserveDataExportDownload(ctx, "p1", "t1"))
+		assert.NoError(t, uc.RecordDataExportDownload(ctx, "p1", &DataExportDownload{TaskUID: "t1", UserUID: "user_1", IP: "10.0.0.1", Bytes: 100, Via: DataExportDownloadViaSession}))
+	}
+	assert.ErrorIs(t, uc.CheckDataExportDownloadLimit(ctx, "p1", "t1"), ErrDataExportDownloadLimitExceeded)
+	assert.ErrorIs(t, uc.ReserveDataExportDownload(ctx, "p1", "t1"), ErrDataExportDownloadLimitExceeded)
+	assert.Equal(t, "w1", repo.downloads[0].WorkflowUID)
+	assert.Equal(t, "p1", repo.downloads[0].ProjectUID)
+
+	// 下载未成功时归还的次数可再次使用
+	assert.NoError(t, uc.ReleaseDataExportDownload(ctx, "t1"))
+	assert.NoError(t, uc.ReserveDataExportDownload(ctx, "p1", "t1"))
+}
+
+func TestReserveDataExportDownloadConcurrently(t *testing.T) {
+	repo := &mockDataExportDownloadRepo{links: map[string]*DataExportDownloadLink{}}
+	uc := &DataExportWorkflowUsecase{
+		repo:         &mockDataExportTaskWorkflowRepo{workflow: &Workflow{UID: "w1", ProjectUID: "p1", CreateUserUID: "user_1", MaxDownloads: 3}},
+		downloadRepo: repo,
+	}
+
+	// 并发的下载在记录下载前预留次数，成功的次数不超过限制
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	succeeded := 0
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if uc.ReserveDataExportDownload(context.Background(), "p1", "t1") == nil {
+				mu.Lock()
+				succeeded++
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+	assert.Equal(t, 3, succeeded)
+}
+
+func TestCanResumeDataExportDownload(t *testing.T) {
+	repo := &mockDataExportDownloadRepo{links: map[string]*DataExportDownloadLink{}}
+	uc := &DataExportWorkflowUsecase{
+		repo:         &mockDataExportTaskWorkflowRepo{workflow: &Workflow{UID: "w1", ProjectUID: "p1", CreateUserUID: "user_1", MaxDownloads: 1}},
+		downloadRepo: repo,
+	}
+	ctx := context.Background()
+	link := &DataExportDownloadLink{UID: "l1"}
+	canResume := func(userUid string, link *DataExportDownloadLink, offset int64) bool {
+		ok, err := uc.CanResumeDataExportDownload(ctx, "p1", "t1", userUid, link, "", offset)
+		assert.NoError(t, err)
+		return ok
+	}
+
+	// 没有下载过时不能续传
+	assert.False(t, canResume("user_1", nil, 100))
+
+	assert.NoError(t, uc.ReserveDataExportDownload(ctx, "p1", "t1"))
+	assert.NoError(t, uc.RecordDataExportDownload(ctx, "p1", &DataExportDownload{TaskUID: "t1", UserUID: "user_1", Bytes: 100, Size: 1000, Via: DataExportDownloadViaSession}))
+	assert.ErrorIs(t, uc.ReserveDataExportDownload(ctx, "p1", "t1"), ErrDataExportDownloadLimitExceeded)
+
+	// 只能从中断的位置继续，不能重复下载已下载的部分，也不能跳过未下载的部分
+	assert.True(t, canResume("user_1", nil, 100))
+	assert.False(t, canResume("user_1", nil, 1))
+	assert.False(t, canResume("user_1", nil, 101))
+	assert.False(t, canResume("user_1", nil, 0))
+
+	// 续传不受下载次数限制，也不计入下载次数，中断的位置随续传推进
+	assert.NoError(t, uc.RecordDataExportDownload(ctx, "p1", &DataExportDownload{TaskUID: "t1", UserUID: "user_1", StartOffset: 100, Bytes: 50, Size: 1000, Via: DataExportDownloadViaSession, Resumed: true}))
+	count, err := repo.CountDataExportDownloads(ctx, "t1")
+	assert.NoError(t, err)
+	assert.EqualValues(t, 1, count)
+	assert.True(t, canResume("user_1", nil, 150))
+	assert.False(t, canResume("user_1", nil, 100))
+	assert.False(t, canResume("user_1", nil, 151))
+
+	// 下载完成后不能再续传
+	assert.NoError(t, uc.RecordDataExportDownload(ctx, "p1", &DataExportDownload{TaskUID: "t1", UserUID: "user_1", StartOffset: 150, Bytes: 850, Size: 1000, Via: DataExportDownloadViaSession, Resumed: true}))
+	assert.False(t, canResume("user_1", nil, 1))
+	assert.False(t, canResume("user_1", nil, 1000))
+
+	// 只下载了文件末尾的部分时不能据此续传
+	assert.NoError(t, uc.RecordDataExportDownload(ctx, "p1", &DataExportDownload{TaskUID: "t1", UserUID: "user_2", StartOffset: 900, Bytes: 100, Size: 1000, Via: DataExportDownloadViaSession}))
+	assert.False(t, canResume("user_2", nil, 50))
+
+	assert.False(t, canResume("user_3", nil, 50))
+	assert.False(t, canResume("user_1", link, 50))
+
+	// 通过链接的下载只能由同一链接续传，不影响同一用户登录后的下载
+	assert.NoError(t, uc.RecordDataExportDownload(ctx, "p1", &DataExportDownload{TaskUID: "t1", UserUID: "user_1", Bytes: 10, Size: 1000, Via: DataExportDownloadViaLink, LinkUID: "l1"}))
+	assert.True(t, canResume("user_1", link, 10))
+	assert.False(t, canResume("user_1", &DataExportDownloadLink{UID: "l2"}, 10))
+	assert.False(t, canResume("user_4", link, 10))
+	assert.False(t, canResume("user_1", nil, 10))
+	_, err = uc.CanResumeDataExportDownload(ctx, "p2", "t1", "user_1", nil, "", 50)
+	assert.Error(t, err)
+}
+
+type mockChecksumDataExportTaskRepo struct {
+	mockDeliveryDataExportTaskRepo
+	task *DataExportTask
+}
+
+func (m *mockChecksumDataExportTaskRepo) GetDataExportTaskByIds(context.Context, []string) ([]*DataExportTask, error) {
+	copied := *m.task
+	return []*DataExportTask{&copied}, nil
+}
+
+func TestSaveDataExportFileChecksum(t *testing.T) {
+	taskRepo := &mockChecksumDataExportTaskRepo{task: &DataExportTask{UID: "t1"}}
+	uc := &DataExportWorkflowUsecase{dataExportTaskRepo: taskRepo}
+	ctx := context.Background()
+
+	// 下载时不计算校验值
+	task, err := uc.GetDownloadDataExportTask(ctx, "t1")
+	assert.NoError(t, err)
+	assert.Empty(t, task.ExportFileChecksum)
+	assert.Empty(t, taskRepo.updates)
+
+	filePath := filepath.Join(t.TempDir(), "export.zip")
+	assert.NoError(t, os.WriteFile(filePath, []byte("export data"), 0600))
+	sum := sha256.Sum256([]byte("export data"))
+
+	assert.NoError(t, uc.SaveDataExportFileChecksum(ctx, task, filePath))
+	assert.Equal(t, hex.EncodeToString(sum[:]), task.ExportFileChecksum)
+	assert.Equal(t, task.ExportFileChecksum, taskRepo.updates[0]["export_file_checksum"])
+
+	assert.Error(t, uc.SaveDataExportFileChecksum(ctx, task, filepath.Join(t.TempDir(), "missing.zip")))
+}
+
+func TestCanResumeWatermarkedDataExportDownload(t *testing.T) {
+	repo := &mockDataExportDownloadRepo{links: map[string]*DataExportDownloadLink{}}
+	uc := &DataExportWorkflowUsecase{
+		repo:         &mockDataExportTaskWorkflowRepo{workflow: &Workflow{UID: "w1", ProjectUID: "p1", CreateUserUID: "user_1"}},
+		downloadRepo: repo,
+	}
+	ctx := context.Background()
+	assert.NoError(t, uc.RecordDataExportDownload(ctx, "p1", &DataExportDownload{TaskUID: "t1", UserUID: "user_1", Bytes: 100, Size: 1000, Fingerprint: "0123456789abcdef"}))
+
+	// 只能以同一下载指纹续传，其他指纹的内容不同
+	ok, err := uc.CanResumeDataExportDownload(ctx, "p1", "t1", "user_1", nil, "0123456789abcdef", 100)
+	assert.NoError(t, err)
+	assert.True(t, ok)
+	ok, err = uc.CanResumeDataExportDownload(ctx, "p1", "t1", "user_1", nil, "fedcba9876543210", 100)
+	assert.NoError(t, err)
+	assert.False(t, ok)
+	ok, err = uc.CanResumeDataExportDownload(ctx, "p1", "t1", "user_1", nil, "", 100)
+	assert.NoError(t, err)
+	assert.False(t, ok)
+}
@@ -0,0 +1,127 @@
+package biz
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/actiontech/dms/internal/dms/pkg/exportfile"
+	"github.com/actiontech/dms/internal/pkg/locale"
+)
+
+var ErrNoExportPasswordChannel = errors.New("encrypted export requires an enabled email, wechat or feishu notification that the user has configured to receive the password")
+
+// exportPasswordNotifiers 解压密码只能通过下载链接以外的渠道发送，返回已启用且申请人配置了接收方式的通知渠道
+func exportPasswordNotifiers(ctx context.Context, user *User) ([]Notifier, error) {
+	notifiers := make([]Notifier, 0, len(Notifiers))
+	for _, n := range Notifiers {
+		availableNotifier, ok := n.(AvailableNotifier)
+		if !ok {
+			continue
+		}
+		available, err := availableNotifier.Available(ctx, user)
+		if err != nil {
+			return nil, fmt.Errorf("check notification channel failed: %v", err)
+		}
+		if available {
+			notifiers = append(notifiers, n)
+		}
+	}
+	return notifiers, nil
+}
+
+// CheckExportEncryption 创建加密导出工单前检查申请人能够收到解压密码
+func (d *DataExportWorkflowUsecase) CheckExportEncryption(ctx context.Context, currentUserUid string, workflow *Workflow) error {
+	if !workflow.EncryptExport {
+		return nil
+	}
+	user, err := d.userUsecase.GetUser(ctx, currentUserUid)
+	if err != nil {
+		return fmt.Errorf("get user failed: %v", err)
+	}
+	notifiers, err := exportPasswordNotifiers(ctx, user)
+	if err != nil {
+		return err
+	}
+	if len(notifiers) == 0 {
+		return ErrNoExportPasswordChannel
+	}
+	return nil
+}
+
+// PrepareExportPassword 导出开始前为加密导出工单生成解压密码并保存，未开启加密时返回空
+func (d *DataExportWorkflowUsecase) PrepareExportPassword(ctx context.Context, workflow *Workflow) (string, error) {
+	if !workflow.EncryptExport {
+		return "", nil
+	}
+	password, err := exportfile.GeneratePassword()
+	if err != nil {
+		return "", err
+	}
+	if err := d.repo.UpdateWorkflowExportPassword(ctx, workflow.UID, password); err != nil {
+		return "", fmt.Errorf("save export password failed: %v", err)
+	}
+	workflow.ExportPassword = password
+	return password, nil
+}
+
+// NotifyExportPassword 导出完成后通过通知渠道将解压密码发送给申请人，下载链接中不包含密码。
+// 任一渠道送达即可，全部失败时返回错误，申请人可重新发送
+func (d *DataExportWorkflowUsecase) NotifyExportPassword(ctx context.Context, workflow *Workflow) error {
+	if !workflow.EncryptExport || workflow.ExportPassword == "" {
+		return nil
+	}
+	user, err := d.userUsecase.GetUser(ctx, workflow.CreateUserUID)
+	if err != nil {
+		return fmt.Errorf("get user failed: %v", err)
+	}
+	notifiers, err := exportPasswordNotifiers(ctx, user)
+	if err != nil {
+		return err
+	}
+	if len(notifiers) == 0 {
+		return ErrNoExportPasswordChannel
+	}
+	project, err := d.projectUsecase.GetProject(ctx, workflow.ProjectUID)
+	if err != nil {
+		return fmt.Errorf("get project failed: %v", err)
+	}
+
+	langTag := locale.Bundle.MatchLangTag(user.Language)
+	subject := locale.Bundle.LocalizeMsgByLang(langTag, locale.NotifyDataWorkflowExportPasswordSubject)
+	body := fmt.Sprintf(locale.Bundle.LocalizeMsgByLang(langTag, locale.NotifyDataWorkflowExportPasswordBody), workflow.Name, project.Name, workflow.ExportPassword)
+	errs := make([]error, 0, len(notifiers))
+	for _, n := range notifiers {
+		if err := n.Notify(ctx, subject, body, []*User{user}); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if len(errs) == len(notifiers) {
+		return fmt.Errorf("send export password failed: %w", errors.Join(errs...))
+	}
+	if len(errs) > 0 {
+		d.log.Errorf("send export password of workflow %s partially failed: %v", workflow.UID, errors.Join(errs...))
+	}
+	return nil
+}
+
+// ResendExportPassword 申请人未收到解压密码时重新发送，密码仍只通过通知渠道发送
+func (d *DataExportWorkflowUsecase) ResendExportPassword(ctx context.Context, projectUid, workflowUid, currentUserUid string) error {
+	workflow, err := d.repo.GetDataExportWorkflow(ctx, workflowUid)
+	if err != nil {
+		return fmt.Errorf("get data export workflow failed: %v", err)
+	}
+	if workflow.ProjectUID != projectUid {
+		return fmt.Errorf("data export workflow %s not found in project", workflowUid)
+	}
+	if workflow.CreateUserUID != currentUserUid {
+		return fmt.Errorf("only the creator can receive the export password")
+	}
+	if !workflow.EncryptExport {
+		return fmt.Errorf("data export workflow is not encrypted")
+	}
+	if workflow.ExportPassword == "" {
+		return fmt.Errorf("data export workflow has not been exported")
+	}
+	return d.NotifyExportPassword(ctx, workflow)
+}
@@ -0,0 +1,57 @@
+package biz
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/actiontech/dms/internal/pkg/locale"
+	"github.com/actiontech/dms/pkg/dms-common/i18nPkg"
+	utilLog "github.com/actiontech/dms/pkg/dms-common/pkg/log"
+	"github.com/stretchr/testify/assert"
+)
+
+type mockAvailableNotifier struct {
+	available bool
+	notifyErr error
+	sent      int
+}
+
+func (m *mockAvailableNotifier) Available(context.Context, *User) (bool, error) {
+	return m.available, nil
+}
+
+func (m *mockAvailableNotifier) Notify(context.Context, string, string, []*User) error {
+	m.sent++
+	return m.notifyErr
+}
+
+func TestExportPasswordNotifiers(t *testing.T) {
+	locale.MustInit(&i18nPkg.StdLogger{})
+	origin := Notifiers
+	defer func() { Notifiers = origin }()
+
+	d := &DataExportWorkflowUsecase{
+		userUsecase:    &UserUsecase{repo: &mockUserRepo{users: map[string]*User{"u1": {UID: "u1", Email: "u1@example.com"}}}},
+		projectUsecase: &ProjectUsecase{repo: &mockDeliveryProjectRepo{}},
+		log:            utilLog.NewHelper(&noopLogger{}, utilLog.WithMessageKey("test")),
+	}
+	ctx := context.Background()
+	workflow := &Workflow{UID: "w1", ProjectUID: "p1", CreateUserUID: "u1", EncryptExport: true, ExportPassword: "secret"}
+
+	// 用户配置了邮箱但没有启用的通知渠道时，不受理加密导出
+	Notifiers = []Notifier{&mockRecordNotifier{}, &mockAvailableNotifier{}}
+	assert.ErrorIs(t, d.CheckExportEncryption(ctx, "u1", workflow), ErrNoExportPasswordChannel)
+	assert.ErrorIs(t, d.NotifyExportPassword(ctx, workflow), ErrNoExportPasswordChannel)
+
+	failed := &mockAvailableNotifier{available: true, notifyErr: errors.New("smtp unavailable")}
+	Notifiers = []Notifier{failed}
+	assert.NoError(t, d.CheckExportEncryption(ctx, "u1", workflow))
+	assert.ErrorContains(t, d.NotifyExportPassword(ctx, workflow), "smtp unavailable")
+
+	// 任一渠道送达即可
+	sent := &mockAvailableNotifier{available: true}
+	Notifiers = []Notifier{failed, sent}
+	assert.NoError(t, d.NotifyExportPassword(ctx, workflow))
+	assert.Equal(t, 1, sent.sent)
+}
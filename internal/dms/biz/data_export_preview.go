@@ -0,0 +1,270 @@
+package biz
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	pkgConst "github.com/actiontech/dms/internal/dms/pkg/constant"
+	"github.com/actiontech/dms/internal/dms/pkg/database"
+	"github.com/actiontech/dms/internal/dms/pkg/exportpreview"
+	"github.com/actiontech/dms/internal/sql_workbench/sqlresultmasker"
+	utilLog "github.com/actiontech/dms/pkg/dms-common/pkg/log"
+	pkgRand "github.com/actiontech/dms/pkg/rand"
+)
+
+const (
+	DefaultDataExportPreviewSampleRows = 10
+	MaxDataExportPreviewSampleRows     = 100
+	// dataExportPreviewTimeout 单条导出 SQL 预览的超时时间
+	dataExportPreviewTimeout = 30 * time.Second
+
+	// 数据源配置了这两个附加参数时，预览使用该只读账号而非数据源的管理账号连接
+	DBServiceAdditionalParam_PreviewUser     = "preview_user"
+	DBServiceAdditionalParam_PreviewPassword = "preview_password"
+)
+
+// DataExportPreview 审批时对导出 SQL 的预览，随工单记录保存用于审计
+type DataExportPreview struct {
+	UID               string
+	WorkflowUID       string
+	WorkflowRecordUID string
+	CreateUserUID     string
+	SampleRows        int
+	Results           []*DataExportPreviewResult
+	CreatedAt         time.Time
+}
+
+// DataExportPreviewResult 单条导出 SQL 的预览结果，预览失败时只记录错误
+type DataExportPreviewResult struct {
+	TaskUID      string   `json:"task_uid"`
+	DBServiceUID string   `json:"db_service_uid"`
+	SQLNumber    uint     `json:"sql_number"`
+	SQL          string   `json:"sql"`
+	Columns      []string `json:"columns"`
+	// Rows 脱敏后的样例行，值为 NULL 时为 nil；无法脱敏时不返回样例行
+	Rows           [][]any  `json:"rows"`
+	MaskedColumns  []string `json:"masked_columns"`
+	EstimatedRows  int64    `json:"estimated_rows"`
+	EstimatedBytes int64    `json:"estimated_bytes"`
+	Error          string   `json:"error,omitempty"`
+}
+
+type DataExportPreviewRepo interface {
+	SaveDataExportPreview(ctx context.Context, preview *DataExportPreview) error
+	ListDataExportPreviews(ctx context.Context, workflowUid string) ([]*DataExportPreview, error)
+}
+
+// DataExportPreviewFunc 在数据源上执行导出 SQL 的预览
+type DataExportPreviewFunc func(ctx context.Context, dbService *DBService, schema, query string, sampleRows int) (*exportpreview.Result, error)
+
+type DataExportPreviewUsecase struct {
+	repo                      DataExportPreviewRepo
+	workflowRepo              WorkflowRepo
+	dataExportTaskRepo        DataExportTaskRepo
+	dbServiceRepo             DBServiceRepo
+	opPermissionVerifyUsecase *OpPermissionVerifyUsecase
+	masker                    sqlresultmasker.SQLResultMasker
+	preview                   DataExportPreviewFunc
+	log                       *utilLog.Helper
+}
+
+func NewDataExportPreviewUsecase(log utilLog.Logger, repo DataExportPreviewRepo, workflowRepo WorkflowRepo, dataExportTaskRepo DataExportTaskRepo, dbServiceRepo DBServiceRepo, opPermissionVerifyUsecase *OpPermissionVerifyUsecase) *DataExportPreviewUsecase {
+	return &DataExportPreviewUsecase{
+		repo:                      repo,
+		workflowRepo:              workflowRepo,
+		dataExportTaskRepo:        dataExportTaskRepo,
+		dbServiceRepo:             dbServiceRepo,
+		opPermissionVerifyUsecase: opPermissionVerifyUsecase,
+		preview:                   previewMysqlDataExport,
+		log:                       utilLog.NewHelper(log, utilLog.WithMessageKey("biz.dataExportPreview")),
+	}
+}
+
+// SetResultMasker 样例行按数据源的脱敏配置脱敏，未设置时不返回样例行
+func (u *DataExportPreviewUsecase) SetResultMasker(masker sqlresultmasker.SQLResultMasker) {
+	u.masker = masker
+}
+
+// PreviewDataExportWorkflow 审批人在审批前预览导出 SQL 的样例数据及估算数据量，预览结果随工单保存
+func (u *DataExportPreviewUsecase) PreviewDataExportWorkflow(ctx context.Context, projectUid, workflowUid, currentUserUid string, sampleRows int) (*DataExportPreview, error) {
+	if sampleRows <= 0 {
+		sampleRows = DefaultDataExportPreviewSampleRows
+	}
+	if sampleRows > MaxDataExportPreviewSampleRows {
+		return nil, fmt.Errorf("sample rows should not exceed %d", MaxDataExportPreviewSampleRows)
+	}
+	w, err := u.getWorkflowForPreview(ctx, projectUid, workflowUid, currentUserUid)
+	if err != nil {
+		return nil, err
+	}
+	if w.WorkflowRecord.Status != DataExportWorkflowStatusWaitForApprove {
+		return nil, fmt.Errorf("only workflows waiting for approval can be previewed")
+	}
+
+	taskUids := make([]string, 0, len(w.WorkflowRecord.Tasks))
+	for _, task := range w.WorkflowRecord.Tasks {
+		taskUids = append(taskUids, task.UID)
+	}
+	tasks, err := u.dataExportTaskRepo.GetDataExportTaskByIds(ctx, taskUids)
+	if err != nil {
+		return nil, fmt.Errorf("get data export tasks failed: %v", err)
+	}
+
+	uid, err := pkgRand.GenStrUid()
+	if err != nil {
+		return nil, err
+	}
+	preview := &DataExportPreview{
+		UID:               uid,
+		WorkflowUID:       w.UID,
+		WorkflowRecordUID: w.WorkflowRecordUid,
+		CreateUserUID:     currentUserUid,
+		SampleRows:        sampleRows,
+		Results:           make([]*DataExportPreviewResult, 0),
+		CreatedAt:         time.Now(),
+	}
+	for _, task := range tasks {
+		preview.Results = append(preview.Results, u.previewTask(ctx, w, task, sampleRows)...)
+	}
+	if err := u.repo.SaveDataExportPreview(ctx, preview); err != nil {
+		return nil, fmt.Errorf("save data export preview failed: %v", err)
+	}
+	return preview, nil
+}
+
+// ListDataExportPreviews 按生成时间倒序返回工单的历史预览
+func (u *DataExportPreviewUsecase) ListDataExportPreviews(ctx context.Context, projectUid, workflowUid, currentUserUid string) ([]*DataExportPreview, error) {
+	if _, err := u.getWorkflowForPreview(ctx, projectUid, workflowUid, currentUserUid); err != nil {
+		return nil, err
+	}
+	previews, err := u.repo.ListDataExportPreviews(ctx, workflowUid)
+	if err != nil {
+		return nil, fmt.Errorf("list data export previews failed: %v", err)
+	}
+	return previews, nil
+}
+
+// getWorkflowForPreview 工单各步骤的审批人及项目管理员可以预览
+func (u *DataExportPreviewUsecase) getWorkflowForPreview(ctx context.Context, projectUid, workflowUid, currentUserUid string) (*Workflow, error) {
+	w, err := u.workflowRepo.GetDataExportWorkflow(ctx, workflowUid)
+	if err != nil {
+		return nil, fmt.Errorf("get data export workflow failed: %w", err)
+	}
+	if w.ProjectUID != projectUid || w.WorkflowRecord == nil {
+		return nil, fmt.Errorf("data export workflow %s not found in project %s", workflowUid, projectUid)
+	}
+	for _, step := range w.WorkflowRecord.WorkflowSteps {
+		for _, assignee := range step.Assignees {
+			if assignee == currentUserUid {
+				return w, nil
+			}
+		}
+	}
+	canOpProject, err := u.opPermissionVerifyUsecase.CanOpProject(ctx, currentUserUid, projectUid, false)
+	if err != nil {
+		return nil, fmt.Errorf("check user can op project failed: %v", err)
+	}
+	if !canOpProject {
+		return nil, fmt.Errorf("user is not the approver of the workflow or project admin")
+	}
+	return w, nil
+}
+
+func (u *DataExportPreviewUsecase) previewTask(ctx context.Context, w *Workflow, task *DataExportTask, sampleRows int) []*DataExportPreviewResult {
+	results := make([]*DataExportPreviewResult, 0, len(task.DataExportTaskRecords))
+	dbService, dbErr := u.dbServiceRepo.GetDBService(ctx, task.DBServiceUid)
+	for _, record := range task.DataExportTaskRecords {
+		result := &DataExportPreviewResult{
+			TaskUID:      task.UID,
+			DBServiceUID: task.DBServiceUid,
+			SQLNumber:    record.Number,
+			SQL:          record.ExportSQL,
+		}
+		results = append(results, result)
+		if dbErr != nil {
+			result.Error = fmt.Sprintf("get db service failed: %v", dbErr)
+			continue
+		}
+
+		previewCtx, cancel := context.WithTimeout(ctx, dataExportPreviewTimeout)
+		previewed, err := u.preview(previewCtx, dbService, task.DatabaseName, record.ExportSQL, sampleRows)
+		cancel()
+		if err != nil {
+			result.Error = err.Error()
+			continue
+		}
+		result.Columns = previewed.Columns
+		result.EstimatedRows = previewed.EstimatedRows
+		result.EstimatedBytes = previewed.EstimatedBytes
+		if u.masker == nil {
+			// 没有脱敏能力时只返回列名及估算数据量，样例行不返回也不保存
+			continue
+		}
+		maskedColumns, err := u.maskRows(ctx, w, task, record, previewed)
+		if err != nil {
+			// 脱敏失败时不返回样例行，避免泄露敏感数据
+			result.Error = err.Error()
+			continue
+		}
+		result.Rows = previewed.Rows
+		result.MaskedColumns = maskedColumns
+	}
+	return results
+}
+
+// maskRows 原地脱敏样例行，返回被脱敏的列
+func (u *DataExportPreviewUsecase) maskRows(ctx context.Context, w *Workflow, task *DataExportTask, record *DataExportTaskRecord, previewed *exportpreview.Result) ([]string, error) {
+	if len(previewed.Rows) == 0 {
+		return nil, nil
+	}
+	masked, err := u.masker.MaskSQLWorkbenchResults(ctx, &sqlresultmasker.MaskWorkbenchResultsArgs{
+		Rows:         previewed.Rows,
+		ColumnNames:  previewed.Columns,
+		SQL:          record.ExportSQL,
+		DBServiceUID: task.DBServiceUid,
+		SchemaName:   task.DatabaseName,
+		ProjectUID:   w.ProjectUID,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("mask sample rows failed: %v", err)
+	}
+	columns := make([]string, 0, len(masked))
+	for column, ok := range masked {
+		if ok {
+			columns = append(columns, column)
+		}
+	}
+	sort.Strings(columns)
+	return columns, nil
+}
+
+// previewMysqlDataExport 预览在 MySQL 协议的数据源上执行，其他类型的数据源暂不支持
+func previewMysqlDataExport(ctx context.Context, dbService *DBService, schema, query string, sampleRows int) (*exportpreview.Result, error) {
+	dbType, err := pkgConst.ParseDBType(dbService.DBType)
+	if err != nil {
+		return nil, err
+	}
+	switch dbType {
+	case pkgConst.DBTypeMySQL, pkgConst.DBTypeTiDB, pkgConst.DBTypeOceanBaseMySQL:
+	default:
+		return nil, fmt.Errorf("preview is not supported for db type %s", dbService.DBType)
+	}
+	user, password := previewAccount(dbService)
+	db, err := database.OpenMysql(dbService.Host, dbService.Port, user, password, schema)
+	if err != nil {
+		return nil, err
+	}
+	defer db.Close()
+	timeoutSQL := exportpreview.StatementTimeoutSQL(dbType == pkgConst.DBTypeOceanBaseMySQL, dataExportPreviewTimeout)
+	return exportpreview.Preview(ctx, db, timeoutSQL, query, sampleRows)
+}
+
+// previewAccount 优先使用数据源配置的只读预览账号
+func previewAccount(dbService *DBService) (user, password string) {
+	if user := dbService.AdditionalParams.GetParam(DBServiceAdditionalParam_PreviewUser).String(); user != "" {
+		return user, dbService.AdditionalParams.GetParam(DBServiceAdditionalParam_PreviewPassword).String()
+	}
+	return dbService.User, dbService.Password
+}
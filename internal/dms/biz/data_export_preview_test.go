@@ -0,0 +1,179 @@
+package biz
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"testing"
+
+	"github.com/actiontech/dms/internal/dms/pkg/exportpreview"
+	"github.com/actiontech/dms/internal/sql_workbench/sqlresultmasker"
+	utilLog "github.com/actiontech/dms/pkg/dms-common/pkg/log"
+	pkgParams "github.com/actiontech/dms/pkg/params"
+	"github.com/stretchr/testify/assert"
+)
+
+type mockDataExportPreviewRepo struct {
+	previews []*DataExportPreview
+}
+
+func (m *mockDataExportPreviewRepo) SaveDataExportPreview(_ context.Context, preview *DataExportPreview) error {
+	m.previews = append(m.previews, preview)
+	return nil
+}
+func (m *mockDataExportPreviewRepo) ListDataExportPreviews(context.Context, string) ([]*DataExportPreview, error) {
+	return m.previews, nil
+}
+
+type mockPreviewWorkflowRepo struct {
+	WorkflowRepo
+	workflow *Workflow
+}
+
+func (m *mockPreviewWorkflowRepo) GetDataExportWorkflow(context.Context, string) (*Workflow, error) {
+	return m.workflow, nil
+}
+
+type mockPreviewDataExportTaskRepo struct {
+	DataExportTaskRepo
+	tasks []*DataExportTask
+}
+
+func (m *mockPreviewDataExportTaskRepo) GetDataExportTaskByIds(context.Context, []string) ([]*DataExportTask, error) {
+	return m.tasks, nil
+}
+
+type mockPreviewDBServiceRepo struct {
+	DBServiceRepo
+}
+
+func (m *mockPreviewDBServiceRepo) GetDBService(_ context.Context, uid string) (*DBService, error) {
+	return &DBService{UID: uid, DBType: "MySQL"}, nil
+}
+
+type mockPreviewMasker struct {
+	err error
+}
+
+func (m *mockPreviewMasker) MaskSQLWorkbenchResults(_ context.Context, args *sqlresultmasker.MaskWorkbenchResultsArgs) (map[string]bool, error) {
+	if m.err != nil {
+		return nil, m.err
+	}
+	for _, row := range args.Rows {
+		row[1] = "***"
+	}
+	return map[string]bool{"id": false, "phone": true}, nil
+}
+
+func newTestDataExportPreviewUsecase(status DataExportWorkflowStatus) (*DataExportPreviewUsecase, *mockDataExportPreviewRepo) {
+	repo := &mockDataExportPreviewRepo{}
+	uc := NewDataExportPreviewUsecase(utilLog.NewMyLogger(io.Discard), repo,
+		&mockPreviewWorkflowRepo{workflow: &Workflow{UID: "w1", ProjectUID: "p1", WorkflowRecordUid: "r1", WorkflowRecord: &WorkflowRecord{
+			UID:           "r1",
+			Status:        status,
+			Tasks:         []Task{{UID: "t1"}},
+			WorkflowSteps: []*WorkflowStep{{StepId: 1, Assignees: []string{"approver"}}},
+		}}},
+		&mockPreviewDataExportTaskRepo{tasks: []*DataExportTask{{
+			UID:          "t1",
+			DBServiceUid: "db1",
+			DatabaseName: "test",
+			DataExportTaskRecords: []*DataExportTaskRecord{
+				{Number: 1, ExportSQL: "SELECT id, phone FROM users"},
+				{Number: 2, ExportSQL: "SELECT * FROM missing"},
+			},
+		}}},
+		&mockPreviewDBServiceRepo{},
+		newTestOpPermissionVerifyUsecase(&mockUserRepo{}, &mockOpPermissionVerifyRepo{}),
+	)
+	uc.preview = func(_ context.Context, _ *DBService, schema, query string, sampleRows int) (*exportpreview.Result, error) {
+		if query == "SELECT * FROM missing" {
+			return nil, fmt.Errorf("table %s.missing doesn't exist", schema)
+		}
+		return &exportpreview.Result{
+			Columns:        []string{"id", "phone"},
+			Rows:           [][]any{{"1", "13800000000"}, {"2", nil}},
+			EstimatedRows:  int64(sampleRows) * 100,
+			EstimatedBytes: 20000,
+		}, nil
+	}
+	return uc, repo
+}
+
+func TestPreviewDataExportWorkflow(t *testing.T) {
+	ctx := context.Background()
+	uc, repo := newTestDataExportPreviewUsecase(DataExportWorkflowStatusWaitForApprove)
+	uc.SetResultMasker(&mockPreviewMasker{})
+
+	_, err := uc.PreviewDataExportWorkflow(ctx, "p1", "w1", "user_1", 0)
+	assert.Error(t, err)
+	_, err = uc.PreviewDataExportWorkflow(ctx, "p2", "w1", "approver", 0)
+	assert.Error(t, err)
+	_, err = uc.PreviewDataExportWorkflow(ctx, "p1", "w1", "approver", MaxDataExportPreviewSampleRows+1)
+	assert.Error(t, err)
+
+	preview, err := uc.PreviewDataExportWorkflow(ctx, "p1", "w1", "approver", 0)
+	assert.NoError(t, err)
+	assert.Equal(t, DefaultDataExportPreviewSampleRows, preview.SampleRows)
+	assert.Equal(t, "r1", preview.WorkflowRecordUID)
+	assert.Len(t, preview.Results, 2)
+	assert.Equal(t, [][]any{{"1", "***"}, {"2", "***"}}, preview.Results[0].Rows)
+	assert.Equal(t, []string{"phone"}, preview.Results[0].MaskedColumns)
+	assert.Equal(t, int64(1000), preview.Results[0].EstimatedRows)
+	assert.Empty(t, preview.Results[0].Error)
+	assert.Contains(t, preview.Results[1].Error, "doesn't exist")
+	assert.Nil(t, preview.Results[1].Rows)
+
+	previews, err := uc.ListDataExportPreviews(ctx, "p1", "w1", "approver")
+	assert.NoError(t, err)
+	assert.Equal(t, repo.previews, previews)
+	_, err = uc.ListDataExportPreviews(ctx, "p1", "w1", "user_1")
+	assert.Error(t, err)
+}
+
+func TestPreviewDataExportWorkflowMaskFailed(t *testing.T) {
+	uc, _ := newTestDataExportPreviewUsecase(DataExportWorkflowStatusWaitForApprove)
+	uc.SetResultMasker(&mockPreviewMasker{err: fmt.Errorf("masking rule not loaded")})
+
+	// 脱敏失败时不返回样例行
+	preview, err := uc.PreviewDataExportWorkflow(context.Background(), "p1", "w1", "approver", 5)
+	assert.NoError(t, err)
+	assert.Nil(t, preview.Results[0].Rows)
+	assert.Contains(t, preview.Results[0].Error, "masking rule not loaded")
+	assert.Equal(t, int64(500), preview.Results[0].EstimatedRows)
+}
+
+func TestPreviewDataExportWorkflowWithoutMasker(t *testing.T) {
+	uc, repo := newTestDataExportPreviewUsecase(DataExportWorkflowStatusWaitForApprove)
+
+	// 没有脱敏能力时只返回列名及估算数据量
+	preview, err := uc.PreviewDataExportWorkflow(context.Background(), "p1", "w1", "approver", 5)
+	assert.NoError(t, err)
+	assert.Nil(t, preview.Results[0].Rows)
+	assert.Empty(t, preview.Results[0].Error)
+	assert.Equal(t, []string{"id", "phone"}, preview.Results[0].Columns)
+	assert.Equal(t, int64(500), preview.Results[0].EstimatedRows)
+	assert.Nil(t, repo.previews[0].Results[0].Rows)
+}
+
+func TestPreviewAccount(t *testing.T) {
+	dbService := &DBService{User: "root", Password: "root_pwd"}
+	user, password := previewAccount(dbService)
+	assert.Equal(t, "root", user)
+	assert.Equal(t, "root_pwd", password)
+
+	dbService.AdditionalParams = pkgParams.Params{
+		{Key: DBServiceAdditionalParam_PreviewUser, Value: "reader"},
+		{Key: DBServiceAdditionalParam_PreviewPassword, Value: "reader_pwd"},
+	}
+	user, password = previewAccount(dbService)
+	assert.Equal(t, "reader", user)
+	assert.Equal(t, "reader_pwd", password)
+}
+
+func TestPreviewDataExportWorkflowStatus(t *testing.T) {
+	uc, repo := newTestDataExportPreviewUsecase(DataExportWorkflowStatusWaitForExport)
+	_, err := uc.PreviewDataExportWorkflow(context.Background(), "p1", "w1", "approver", 0)
+	assert.Error(t, err)
+	assert.Empty(t, repo.previews)
+}
@@ -0,0 +1,394 @@
+package biz
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/actiontech/dms/internal/dms/pkg/exportfile"
+	"github.com/actiontech/dms/internal/pkg/locale"
+	"github.com/actiontech/dms/pkg/dms-common/i18nPkg"
+	utilLog "github.com/actiontech/dms/pkg/dms-common/pkg/log"
+	pkgRand "github.com/actiontech/dms/pkg/rand"
+	"github.com/nicksnyder/go-i18n/v2/i18n"
+)
+
+type DataExportQuotaScope string
+
+const (
+	DataExportQuotaScopeGlobal    DataExportQuotaScope = "global"
+	DataExportQuotaScopeProject   DataExportQuotaScope = "project"
+	DataExportQuotaScopeDBService DataExportQuotaScope = "db_service"
+)
+
+// DataExportLimits 导出限制，0 表示不限制
+type DataExportLimits struct {
+	MaxRowsPerSQL    int64
+	MaxFileSizeBytes int64
+	// MaxExportsPerUserPerDay 每个用户每天的导出次数，每个导出任务计一次
+	MaxExportsPerUserPerDay int64
+	// MaxBytesPerProjectPerMonth 每个项目每月导出文件的总字节数
+	MaxBytesPerProjectPerMonth int64
+}
+
+func (l DataExportLimits) isEmpty() bool {
+	return l == DataExportLimits{}
+}
+
+// merge 多个范围的限制同时生效，取更严格的值
+func (l DataExportLimits) merge(o DataExportLimits) DataExportLimits {
+	return DataExportLimits{
+		MaxRowsPerSQL:              minLimit(l.MaxRowsPerSQL, o.MaxRowsPerSQL),
+		MaxFileSizeBytes:           minLimit(l.MaxFileSizeBytes, o.MaxFileSizeBytes),
+		MaxExportsPerUserPerDay:    minLimit(l.MaxExportsPerUserPerDay, o.MaxExportsPerUserPerDay),
+		MaxBytesPerProjectPerMonth: minLimit(l.MaxBytesPerProjectPerMonth, o.MaxBytesPerProjectPerMonth),
+	}
+}
+
+func minLimit(a, b int64) int64 {
+	if a == 0 {
+		return b
+	}
+	if b == 0 {
+		return a
+	}
+	return min(a, b)
+}
+
+type DataExportQuota struct {
+	UID   string
+	Scope DataExportQuotaScope
+	// ScopeUID 项目或数据源 UID，全局配额为空
+	ScopeUID string
+	// ProjectUID 项目及数据源配额所属的项目，用于按项目查询
+	ProjectUID string
+	Limits     DataExportLimits
+	UpdatedAt  time.Time
+}
+
+// DataExportUsage 一次导出任务的用量，导出完成后记录
+type DataExportUsage struct {
+	UID          string
+	ProjectUID   string
+	UserUID      string
+	DBServiceUID string
+	TaskUID      string
+	Rows         int64
+	Bytes        int64
+	CreatedAt    time.Time
+}
+
+type DataExportUsageFilter struct {
+	// ProjectUID 为空时统计全部项目
+	ProjectUID string
+	// UserUID 为空时统计全部用户
+	UserUID string
+	Since   time.Time
+}
+
+type DataExportUsageSummary struct {
+	Exports int64
+	Bytes   int64
+}
+
+type DataExportQuotaRepo interface {
+	SaveDataExportQuota(ctx context.Context, quota *DataExportQuota) error
+	DeleteDataExportQuota(ctx context.Context, scope DataExportQuotaScope, scopeUid string) error
+	// ListDataExportQuotas 返回全局配额及项目内的项目、数据源配额，projectUid 为空时返回全部配额
+	ListDataExportQuotas(ctx context.Context, projectUid string) ([]*DataExportQuota, error)
+	SaveDataExportUsage(ctx context.Context, usage *DataExportUsage) error
+	SumDataExportUsage(ctx context.Context, filter *DataExportUsageFilter) (*DataExportUsageSummary, error)
+}
+
+type DataExportQuotaUsecase struct {
+	repo                      DataExportQuotaRepo
+	dbServiceRepo             DBServiceRepo
+	opPermissionVerifyUsecase *OpPermissionVerifyUsecase
+	log                       *utilLog.Helper
+}
+
+func NewDataExportQuotaUsecase(log utilLog.Logger, repo DataExportQuotaRepo, dbServiceRepo DBServiceRepo, opPermissionVerifyUsecase *OpPermissionVerifyUsecase) *DataExportQuotaUsecase {
+	return &DataExportQuotaUsecase{
+		repo:                      repo,
+		dbServiceRepo:             dbServiceRepo,
+		opPermissionVerifyUsecase: opPermissionVerifyUsecase,
+		log:                       utilLog.NewHelper(log, utilLog.WithMessageKey("biz.dataExportQuota")),
+	}
+}
+
+// DataExportQuotaExceededError 导出超出配额，Limit 为超出的限制值
+type DataExportQuotaExceededError struct {
+	msg   *i18n.Message
+	Limit int64
+}
+
+func (e *DataExportQuotaExceededError) Error() string {
+	return fmt.Sprintf(locale.Bundle.LocalizeMsgByLang(i18nPkg.DefaultLang, e.msg), e.Limit)
+}
+
+// Reason 按 ctx 中的语言返回展示给用户的原因，后台执行时 ctx 中没有语言则使用默认语言
+func (e *DataExportQuotaExceededError) Reason(ctx context.Context) string {
+	return fmt.Sprintf(locale.Bundle.LocalizeMsgByLang(locale.Bundle.GetLangTagFromCtx(ctx), e.msg), e.Limit)
+}
+
+// DataExportQuotaFailure 将导出执行中的配额错误转换为任务失败阶段和原因，原因按 ctx 中的语言返回，非配额错误返回 false
+func DataExportQuotaFailure(ctx context.Context, err error) (stage, reason string, ok bool) {
+	var quotaErr *DataExportQuotaExceededError
+	if errors.As(err, &quotaErr) {
+		return DataExportFailStageQuota, quotaErr.Reason(ctx), true
+	}
+	var limitErr *exportfile.LimitError
+	if errors.As(err, &limitErr) {
+		switch {
+		case errors.Is(limitErr, exportfile.ErrRowLimitExceeded):
+			return DataExportFailStageQuota, fmt.Sprintf(locale.Bundle.LocalizeMsgByLang(locale.Bundle.GetLangTagFromCtx(ctx), locale.DataExportQuotaRowsExceeded), limitErr.Limit), true
+		case errors.Is(limitErr, exportfile.ErrFileSizeLimitExceeded):
+			return DataExportFailStageQuota, fmt.Sprintf(locale.Bundle.LocalizeMsgByLang(locale.Bundle.GetLangTagFromCtx(ctx), locale.DataExportQuotaFileSizeExceeded), limitErr.Limit), true
+		}
+	}
+	return "", "", false
+}
+
+func startOfDay(t time.Time) time.Time {
+	y, m, d := t.Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, t.Location())
+}
+
+func startOfMonth(t time.Time) time.Time {
+	y, m, _ := t.Date()
+	return time.Date(y, m, 1, 0, 0, 0, 0, t.Location())
+}
+
+func (d *DataExportQuotaUsecase) checkCanManageQuota(ctx context.Context, currentUserUid string, scope DataExportQuotaScope, projectUid string) error {
+	if scope == DataExportQuotaScopeGlobal {
+		canGlobalOp, err := d.opPermissionVerifyUsecase.CanOpGlobal(ctx, currentUserUid, false)
+		if err != nil {
+			return fmt.Errorf("check user is admin or global management permission : %v", err)
+		}
+		if !canGlobalOp {
+			return fmt.Errorf("user is not admin or global management permission")
+		}
+		return nil
+	}
+	canOpProject, err := d.opPermissionVerifyUsecase.CanOpProject(ctx, currentUserUid, projectUid, false)
+	if err != nil {
+		return fmt.Errorf("check user can op project failed: %v", err)
+	}
+	if !canOpProject {
+		return fmt.Errorf("user is not project admin or global management permission")
+	}
+	return nil
+}
+
+// SetDataExportQuota 设置全局、项目或数据源的导出限制，限制全部为 0 时删除配置
+func (d *DataExportQuotaUsecase) SetDataExportQuota(ctx context.Context, currentUserUid string, scope DataExportQuotaScope, scopeUid string, limits DataExportLimits) error {
+	if limits.MaxRowsPerSQL < 0 || limits.MaxFileSizeBytes < 0 || limits.MaxExportsPerUserPerDay < 0 || limits.MaxBytesPerProjectPerMonth < 0 {
+		return fmt.Errorf("data export limits must not be negative")
+	}
+	if scope != DataExportQuotaScopeGlobal && scopeUid == "" {
+		return fmt.Errorf("scope uid is required for %s quota", scope)
+	}
+	quota := &DataExportQuota{Scope: scope, ScopeUID: scopeUid, Limits: limits}
+	switch scope {
+	case DataExportQuotaScopeGlobal:
+		quota.ScopeUID = ""
+	case DataExportQuotaScopeProject:
+		quota.ProjectUID = scopeUid
+	case DataExportQuotaScopeDBService:
+		// 用户和项目的累计用量与数据源无关，数据源只能限制单次导出
+		if limits.MaxExportsPerUserPerDay > 0 || limits.MaxBytesPerProjectPerMonth > 0 {
+			return fmt.Errorf("db service quota only supports max rows per sql and max file size")
+		}
+		dbService, err := d.dbServiceRepo.GetDBService(ctx, scopeUid)
+		if err != nil {
+			return fmt.Errorf("get db service failed: %v", err)
+		}
+		quota.ProjectUID = dbService.ProjectUID
+	default:
+		return fmt.Errorf("invalid data export quota scope: %s", scope)
+	}
+	if err := d.checkCanManageQuota(ctx, currentUserUid, scope, quota.ProjectUID); err != nil {
+		return err
+	}
+
+	if limits.isEmpty() {
+		return d.repo.DeleteDataExportQuota(ctx, quota.Scope, quota.ScopeUID)
+	}
+	uid, err := pkgRand.GenStrUid()
+	if err != nil {
+		return err
+	}
+	quota.UID = uid
+	// 同一范围只保留一份配置，已存在时覆盖限制
+	return d.repo.SaveDataExportQuota(ctx, quota)
+}
+
+// ListDataExportQuotas 查看项目生效的全局、项目及数据源导出限制
+func (d *DataExportQuotaUsecase) ListDataExportQuotas(ctx context.Context, currentUserUid, projectUid string) ([]*DataExportQuota, error) {
+	if projectUid == "" {
+		if err := d.checkCanManageQuota(ctx, currentUserUid, DataExportQuotaScopeGlobal, ""); err != nil {
+			return nil, err
+		}
+	} else if err := d.checkUserInProject(ctx, currentUserUid, projectUid); err != nil {
+		return nil, err
+	}
+	return d.repo.ListDataExportQuotas(ctx, projectUid)
+}
+
+// checkUserInProject 项目成员可查看项目的导出限制及自己的用量
+func (d *DataExportQuotaUsecase) checkUserInProject(ctx context.Context, currentUserUid, projectUid string) error {
+	canViewProject, err := d.opPermissionVerifyUsecase.CanViewProject(ctx, currentUserUid, projectUid, "")
+	if err != nil {
+		return fmt.Errorf("check user can view project failed: %v", err)
+	}
+	if canViewProject {
+		return nil
+	}
+	projects, err := d.opPermissionVerifyUsecase.GetUserProject(ctx, currentUserUid)
+	if err != nil {
+		return fmt.Errorf("get user projects failed: %v", err)
+	}
+	for _, p := range projects {
+		if p.UID == projectUid {
+			return nil
+		}
+	}
+	return fmt.Errorf("user is not in project")
+}
+
+// mergeDataExportLimits 全局、项目及数据源的限制同时生效，dbServiceUids 有多个时取最严格的限制，
+// 同时返回全局及项目的限制，用于按各自范围的用量判定累计限制
+func mergeDataExportLimits(quotas []*DataExportQuota, projectUid string, dbServiceUids []string) (limits, globalLimits, projectLimits DataExportLimits) {
+	for _, q := range quotas {
+		switch {
+		case q.Scope == DataExportQuotaScopeGlobal:
+			limits = limits.merge(q.Limits)
+			globalLimits = q.Limits
+		case q.Scope == DataExportQuotaScopeProject && q.ScopeUID == projectUid:
+			limits = limits.merge(q.Limits)
+			projectLimits = q.Limits
+		case q.Scope == DataExportQuotaScopeDBService && slices.Contains(dbServiceUids, q.ScopeUID):
+			limits = limits.merge(q.Limits)
+		}
+	}
+	return limits, globalLimits, projectLimits
+}
+
+type DataExportQuotaUsage struct {
+	Limits DataExportLimits
+	// UserExportsToday 用户当天在全部项目中的导出次数，按全局限制判定
+	UserExportsToday int64
+	// UserProjectExportsToday 用户当天在本项目中的导出次数，按项目限制判定
+	UserProjectExportsToday int64
+	// ProjectBytesThisMonth 项目当月已导出的字节数
+	ProjectBytesThisMonth int64
+
+	globalLimits  DataExportLimits
+	projectLimits DataExportLimits
+}
+
+// userExportsExceeded 全局和项目的每日导出次数分别按用户在全部项目和本项目中的导出次数判定，返回超出的限制
+func (u *DataExportQuotaUsage) userExportsExceeded() (limit int64, exceeded bool) {
+	if limit := u.globalLimits.MaxExportsPerUserPerDay; limit > 0 && u.UserExportsToday >= limit {
+		return limit, true
+	}
+	if limit := u.projectLimits.MaxExportsPerUserPerDay; limit > 0 && u.UserProjectExportsToday >= limit {
+		return limit, true
+	}
+	return 0, false
+}
+
+func (d *DataExportQuotaUsecase) getDataExportQuotaUsage(ctx context.Context, projectUid, userUid string, dbServiceUids []string, now time.Time) (*DataExportQuotaUsage, error) {
+	quotas, err := d.repo.ListDataExportQuotas(ctx, projectUid)
+	if err != nil {
+		return nil, fmt.Errorf("list data export quotas failed: %v", err)
+	}
+	limits, globalLimits, projectLimits := mergeDataExportLimits(quotas, projectUid, dbServiceUids)
+
+	userUsage, err := d.repo.SumDataExportUsage(ctx, &DataExportUsageFilter{UserUID: userUid, Since: startOfDay(now)})
+	if err != nil {
+		return nil, fmt.Errorf("sum user data export usage failed: %v", err)
+	}
+	userProjectUsage, err := d.repo.SumDataExportUsage(ctx, &DataExportUsageFilter{ProjectUID: projectUid, UserUID: userUid, Since: startOfDay(now)})
+	if err != nil {
+		return nil, fmt.Errorf("sum user data export usage in project failed: %v", err)
+	}
+	projectUsage, err := d.repo.SumDataExportUsage(ctx, &DataExportUsageFilter{ProjectUID: projectUid, Since: startOfMonth(now)})
+	if err != nil {
+		return nil, fmt.Errorf("sum project data export usage failed: %v", err)
+	}
+	return &DataExportQuotaUsage{
+		Limits:                  limits,
+		UserExportsToday:        userUsage.Exports,
+		UserProjectExportsToday: userProjectUsage.Exports,
+		ProjectBytesThisMonth:   projectUsage.Bytes,
+		globalLimits:            globalLimits,
+		projectLimits:           projectLimits,
+	}, nil
+}
+
+// GetDataExportQuotaUsage 查看当前用户在项目中的导出限制及用量
+func (d *DataExportQuotaUsecase) GetDataExportQuotaUsage(ctx context.Context, currentUserUid, projectUid string) (*DataExportQuotaUsage, error) {
+	if err := d.checkUserInProject(ctx, currentUserUid, projectUid); err != nil {
+		return nil, err
+	}
+	return d.getDataExportQuotaUsage(ctx, projectUid, currentUserUid, nil, time.Now())
+}
+
+// PrepareDataExport 导出任务执行前检查配额，返回写入导出文件时的限制，文件大小不超过项目当月剩余额度
+func (d *DataExportQuotaUsecase) PrepareDataExport(ctx context.Context, projectUid, userUid, dbServiceUid string) (exportfile.Options, error) {
+	usage, err := d.getDataExportQuotaUsage(ctx, projectUid, userUid, []string{dbServiceUid}, time.Now())
+	if err != nil {
+		return exportfile.Options{}, err
+	}
+	limits := usage.Limits
+	if limit, exceeded := usage.userExportsExceeded(); exceeded {
+		return exportfile.Options{}, &DataExportQuotaExceededError{msg: locale.DataExportQuotaUserExportsExceeded, Limit: limit}
+	}
+	maxFileSize := limits.MaxFileSizeBytes
+	if limits.MaxBytesPerProjectPerMonth > 0 {
+		remaining := limits.MaxBytesPerProjectPerMonth - usage.ProjectBytesThisMonth
+		if remaining <= 0 {
+			return exportfile.Options{}, &DataExportQuotaExceededError{msg: locale.DataExportQuotaProjectBytesExceeded, Limit: limits.MaxBytesPerProjectPerMonth}
+		}
+		maxFileSize = minLimit(maxFileSize, remaining)
+	}
+	return exportfile.Options{
+		MaxRowsPerResult: limits.MaxRowsPerSQL,
+		MaxFileSize:      maxFileSize,
+	}, nil
+}
+
+// RecordDataExportUsage 导出任务完成后记录用量，失败的导出不计入
+func (d *DataExportQuotaUsecase) RecordDataExportUsage(ctx context.Context, usage *DataExportUsage) error {
+	if usage.UID == "" {
+		uid, err := pkgRand.GenStrUid()
+		if err != nil {
+			return err
+		}
+		usage.UID = uid
+	}
+	if err := d.repo.SaveDataExportUsage(ctx, usage); err != nil {
+		return fmt.Errorf("save data export usage failed: %v", err)
+	}
+	return nil
+}
+
+// GetDataExportWorkflowQuotaUsage 审批时展示申请人的导出限制及用量，行数和文件大小取工单涉及数据源中最严格的限制
+func (d *DataExportWorkflowUsecase) GetDataExportWorkflowQuotaUsage(ctx context.Context, workflow *Workflow) (*DataExportQuotaUsage, error) {
+	taskUids := make([]string, 0, len(workflow.WorkflowRecord.Tasks))
+	for _, task := range workflow.WorkflowRecord.Tasks {
+		taskUids = append(taskUids, task.UID)
+	}
+	tasks, err := d.dataExportTaskRepo.GetDataExportTaskByIds(ctx, taskUids)
+	if err != nil {
+		return nil, fmt.Errorf("get data export tasks failed: %v", err)
+	}
+	dbServiceUids := make([]string, 0, len(tasks))
+	for _, task := range tasks {
+		dbServiceUids = append(dbServiceUids, task.DBServiceUid)
+	}
+	return d.quotaUsecase.getDataExportQuotaUsage(ctx, workflow.ProjectUID, workflow.CreateUserUID, dbServiceUids, time.Now())
+}
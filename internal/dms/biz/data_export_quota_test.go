@@ -0,0 +1,155 @@
+package biz
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/actiontech/dms/internal/dms/pkg/exportfile"
+	"github.com/actiontech/dms/internal/pkg/locale"
+	"github.com/actiontech/dms/pkg/dms-common/i18nPkg"
+	utilLog "github.com/actiontech/dms/pkg/dms-common/pkg/log"
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/text/language"
+)
+
+type mockDataExportQuotaRepo struct {
+	quotas []*DataExportQuota
+	usages []*DataExportUsage
+}
+
+func (m *mockDataExportQuotaRepo) SaveDataExportQuota(context.Context, *DataExportQuota) error {
+	return nil
+}
+func (m *mockDataExportQuotaRepo) DeleteDataExportQuota(context.Context, DataExportQuotaScope, string) error {
+	return nil
+}
+func (m *mockDataExportQuotaRepo) ListDataExportQuotas(context.Context, string) ([]*DataExportQuota, error) {
+	return m.quotas, nil
+}
+func (m *mockDataExportQuotaRepo) SaveDataExportUsage(_ context.Context, usage *DataExportUsage) error {
+	m.usages = append(m.usages, usage)
+	return nil
+}
+func (m *mockDataExportQuotaRepo) SumDataExportUsage(_ context.Context, filter *DataExportUsageFilter) (*DataExportUsageSummary, error) {
+	summary := &DataExportUsageSummary{}
+	for _, u := range m.usages {
+		if u.CreatedAt.Before(filter.Since) ||
+			(filter.ProjectUID != "" && u.ProjectUID != filter.ProjectUID) ||
+			(filter.UserUID != "" && u.UserUID != filter.UserUID) {
+			continue
+		}
+		summary.Exports++
+		summary.Bytes += u.Bytes
+	}
+	return summary, nil
+}
+
+func TestMergeDataExportLimits(t *testing.T) {
+	quotas := []*DataExportQuota{
+		{Scope: DataExportQuotaScopeGlobal, Limits: DataExportLimits{MaxRowsPerSQL: 1000, MaxExportsPerUserPerDay: 10}},
+		{Scope: DataExportQuotaScopeProject, ScopeUID: "p1", ProjectUID: "p1", Limits: DataExportLimits{MaxExportsPerUserPerDay: 5, MaxBytesPerProjectPerMonth: 1 << 30}},
+		{Scope: DataExportQuotaScopeDBService, ScopeUID: "db1", ProjectUID: "p1", Limits: DataExportLimits{MaxRowsPerSQL: 100, MaxFileSizeBytes: 1 << 20}},
+		{Scope: DataExportQuotaScopeDBService, ScopeUID: "db2", ProjectUID: "p1", Limits: DataExportLimits{MaxRowsPerSQL: 5000}},
+	}
+
+	limits, globalLimits, projectLimits := mergeDataExportLimits(quotas, "p1", []string{"db2"})
+	assert.Equal(t, DataExportLimits{MaxRowsPerSQL: 1000, MaxExportsPerUserPerDay: 5, MaxBytesPerProjectPerMonth: 1 << 30}, limits)
+	assert.Equal(t, quotas[0].Limits, globalLimits)
+	assert.Equal(t, quotas[1].Limits, projectLimits)
+
+	limits, _, _ = mergeDataExportLimits(quotas, "p1", []string{"db1", "db2"})
+	assert.Equal(t, DataExportLimits{MaxRowsPerSQL: 100, MaxFileSizeBytes: 1 << 20, MaxExportsPerUserPerDay: 5, MaxBytesPerProjectPerMonth: 1 << 30}, limits)
+
+	limits, _, projectLimits = mergeDataExportLimits(quotas, "p2", nil)
+	assert.Equal(t, DataExportLimits{MaxRowsPerSQL: 1000, MaxExportsPerUserPerDay: 10}, limits)
+	assert.Equal(t, DataExportLimits{}, projectLimits)
+}
+
+func TestPrepareDataExport(t *testing.T) {
+	locale.MustInit(&i18nPkg.StdLogger{})
+	now := time.Now()
+	repo := &mockDataExportQuotaRepo{
+		quotas: []*DataExportQuota{
+			{Scope: DataExportQuotaScopeGlobal, Limits: DataExportLimits{MaxExportsPerUserPerDay: 2}},
+			{Scope: DataExportQuotaScopeProject, ScopeUID: "p1", ProjectUID: "p1", Limits: DataExportLimits{MaxFileSizeBytes: 800, MaxBytesPerProjectPerMonth: 1000}},
+			{Scope: DataExportQuotaScopeDBService, ScopeUID: "db1", ProjectUID: "p1", Limits: DataExportLimits{MaxRowsPerSQL: 100}},
+		},
+	}
+	uc := NewDataExportQuotaUsecase(utilLog.NewMyLogger(io.Discard), repo, nil, nil)
+	ctx := context.Background()
+
+	opts, err := uc.PrepareDataExport(ctx, "p1", "u1", "db1")
+	assert.NoError(t, err)
+	assert.Equal(t, exportfile.Options{MaxRowsPerResult: 100, MaxFileSize: 800}, opts)
+
+	// 文件大小不超过项目当月剩余额度
+	assert.NoError(t, uc.RecordDataExportUsage(ctx, &DataExportUsage{ProjectUID: "p1", UserUID: "u2", Bytes: 700, CreatedAt: now}))
+	opts, err = uc.PrepareDataExport(ctx, "p1", "u1", "db2")
+	assert.NoError(t, err)
+	assert.Equal(t, exportfile.Options{MaxFileSize: 300}, opts)
+
+	// 全局的每日导出次数统计全部项目
+	assert.NoError(t, uc.RecordDataExportUsage(ctx, &DataExportUsage{ProjectUID: "p2", UserUID: "u1", CreatedAt: now}))
+	assert.NoError(t, uc.RecordDataExportUsage(ctx, &DataExportUsage{ProjectUID: "p2", UserUID: "u1", CreatedAt: now}))
+	_, err = uc.PrepareDataExport(ctx, "p1", "u1", "db1")
+	stage, _, ok := DataExportQuotaFailure(ctx, err)
+	assert.True(t, ok)
+	assert.Equal(t, DataExportFailStageQuota, stage)
+
+	assert.NoError(t, uc.RecordDataExportUsage(ctx, &DataExportUsage{ProjectUID: "p1", UserUID: "u2", Bytes: 300, CreatedAt: now}))
+	_, err = uc.PrepareDataExport(ctx, "p1", "u3", "db1")
+	_, reason, ok := DataExportQuotaFailure(ctx, err)
+	assert.True(t, ok)
+	assert.Contains(t, reason, "1000")
+}
+
+// TestPrepareDataExportUserExportsPerScope 全局和项目的每日导出次数各自按对应范围的导出次数判定
+func TestPrepareDataExportUserExportsPerScope(t *testing.T) {
+	locale.MustInit(&i18nPkg.StdLogger{})
+	now := time.Now()
+	repo := &mockDataExportQuotaRepo{
+		quotas: []*DataExportQuota{
+			{Scope: DataExportQuotaScopeGlobal, Limits: DataExportLimits{MaxExportsPerUserPerDay: 2}},
+			{Scope: DataExportQuotaScopeProject, ScopeUID: "p1", ProjectUID: "p1", Limits: DataExportLimits{MaxExportsPerUserPerDay: 2}},
+		},
+	}
+	uc := NewDataExportQuotaUsecase(utilLog.NewMyLogger(io.Discard), repo, nil, nil)
+	ctx := context.Background()
+
+	// 全局与项目限制相同时，其他项目的导出同样计入全局限制
+	assert.NoError(t, uc.RecordDataExportUsage(ctx, &DataExportUsage{ProjectUID: "p1", UserUID: "u1", CreatedAt: now}))
+	assert.NoError(t, uc.RecordDataExportUsage(ctx, &DataExportUsage{ProjectUID: "p2", UserUID: "u1", CreatedAt: now}))
+	_, err := uc.PrepareDataExport(ctx, "p1", "u1", "db1")
+	_, _, ok := DataExportQuotaFailure(ctx, err)
+	assert.True(t, ok)
+
+	usage, err := uc.getDataExportQuotaUsage(ctx, "p1", "u1", nil, now)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(2), usage.UserExportsToday)
+	assert.Equal(t, int64(1), usage.UserProjectExportsToday)
+
+	// 项目限制更严格时只统计本项目的导出
+	repo.quotas[0].Limits.MaxExportsPerUserPerDay = 10
+	repo.quotas[1].Limits.MaxExportsPerUserPerDay = 2
+	_, err = uc.PrepareDataExport(ctx, "p1", "u1", "db1")
+	assert.NoError(t, err)
+	assert.NoError(t, uc.RecordDataExportUsage(ctx, &DataExportUsage{ProjectUID: "p1", UserUID: "u1", CreatedAt: now}))
+	_, err = uc.PrepareDataExport(ctx, "p1", "u1", "db1")
+	_, reason, ok := DataExportQuotaFailure(context.WithValue(ctx, i18nPkg.AcceptLanguageKey, language.English), err)
+	assert.True(t, ok)
+	assert.Equal(t, "You have reached the daily export limit (2 exports)", reason)
+}
+
+func TestDataExportQuotaFailure(t *testing.T) {
+	locale.MustInit(&i18nPkg.StdLogger{})
+	ctx := context.Background()
+	_, reason, ok := DataExportQuotaFailure(ctx, fmt.Errorf("write row: %w", &exportfile.LimitError{Err: exportfile.ErrRowLimitExceeded, Limit: 100}))
+	assert.True(t, ok)
+	assert.Contains(t, reason, "100")
+
+	_, _, ok = DataExportQuotaFailure(ctx, fmt.Errorf("connect failed"))
+	assert.False(t, ok)
+}
@@ -0,0 +1,486 @@
+package biz
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+
+	pkgConst "github.com/actiontech/dms/internal/dms/pkg/constant"
+	pkgErr "github.com/actiontech/dms/internal/dms/pkg/errors"
+	"github.com/actiontech/dms/internal/pkg/locale"
+	dmsCommonV1 "github.com/actiontech/dms/pkg/dms-common/api/dms/v1"
+	utilLog "github.com/actiontech/dms/pkg/dms-common/pkg/log"
+	sqlop "github.com/actiontech/dms/pkg/dms-common/sql_op"
+	pkgRand "github.com/actiontech/dms/pkg/rand"
+
+	"github.com/nicksnyder/go-i18n/v2/i18n"
+	"github.com/robfig/cron/v3"
+)
+
+type DataExportScheduleStatus string
+
+const (
+	DataExportScheduleStatusActive    DataExportScheduleStatus = "active"
+	DataExportScheduleStatusSuspended DataExportScheduleStatus = "suspended"
+	DataExportScheduleStatusEnded     DataExportScheduleStatus = "ended"
+)
+
+// dataExportScheduleMinInterval 定时导出的最小间隔，避免误配置导致频繁导出
+const dataExportScheduleMinInterval = time.Hour
+
+var (
+	ErrDataExportScheduleIntervalTooShort = errors.New("data export schedule interval must be at least one hour")
+	ErrDataExportWorkflowNotApproved      = errors.New("only approved data export workflow can be scheduled")
+)
+
+// DataExportScheduleTask 设置定时导出时冻结的导出任务，每次执行按其创建新的导出任务
+type DataExportScheduleTask struct {
+	DBServiceUID   string
+	DatabaseName   string
+	ExportSQL      string
+	ExportFileType string
+	// AuditLevel 审批时的审核等级，重新审核的结果高于该等级时暂停定时导出
+	AuditLevel string
+	// DBServiceUpdatedAt 审批时数据源的更新时间，数据源变更后暂停定时导出
+	DBServiceUpdatedAt time.Time
+}
+
+// DataExportSchedule 已审批的数据导出工单的定时导出，SQL 不变时每次执行不再审批
+type DataExportSchedule struct {
+	UID           string
+	ProjectUID    string
+	WorkflowUID   string
+	CreateUserUID string
+	// CronExpression 标准 5 段 cron 表达式，如每周一 8 点为 "0 8 * * 1"
+	CronExpression string
+	// EndTime 零值表示不结束
+	EndTime       time.Time
+	EncryptExport bool
+	Tasks         []*DataExportScheduleTask
+	Status        DataExportScheduleStatus
+	SuspendReason string
+	NextRunAt     time.Time
+	LastRunAt     time.Time
+	// LastRunWorkflowUID 最近一次执行创建的导出工单，LastRunError 为最近一次执行失败的原因
+	LastRunWorkflowUID string
+	LastRunError       string
+	CreatedAt          time.Time
+}
+
+type DataExportScheduleRepo interface {
+	SaveDataExportSchedule(ctx context.Context, schedule *DataExportSchedule) error
+	UpdateDataExportSchedule(ctx context.Context, schedule *DataExportSchedule) error
+	GetDataExportScheduleByWorkflow(ctx context.Context, workflowUid string) (*DataExportSchedule, error)
+	ListDataExportSchedules(ctx context.Context, projectUid, createUserUid string) ([]*DataExportSchedule, error)
+	ListDueDataExportSchedules(ctx context.Context, now time.Time) ([]*DataExportSchedule, error)
+	DeleteDataExportSchedule(ctx context.Context, uid string) error
+}
+
+type DataExportScheduleUsecase struct {
+	repo                      DataExportScheduleRepo
+	workflowUsecase           *DataExportWorkflowUsecase
+	dbServiceUsecase          *DBServiceUsecase
+	userUsecase               *UserUsecase
+	projectUsecase            *ProjectUsecase
+	clusterUsecase            *ClusterUsecase
+	opPermissionVerifyUsecase *OpPermissionVerifyUsecase
+	log                       *utilLog.Helper
+}
+
+func NewDataExportScheduleUsecase(log utilLog.Logger, repo DataExportScheduleRepo, workflowUsecase *DataExportWorkflowUsecase, dbServiceUsecase *DBServiceUsecase,
+	userUsecase *UserUsecase, projectUsecase *ProjectUsecase, clusterUsecase *ClusterUsecase, opPermissionVerifyUsecase *OpPermissionVerifyUsecase) *DataExportScheduleUsecase {
+	return &DataExportScheduleUsecase{
+		repo:                      repo,
+		workflowUsecase:           workflowUsecase,
+		dbServiceUsecase:          dbServiceUsecase,
+		userUsecase:               userUsecase,
+		projectUsecase:            projectUsecase,
+		clusterUsecase:            clusterUsecase,
+		opPermissionVerifyUsecase: opPermissionVerifyUsecase,
+		log:                       utilLog.NewHelper(log, utilLog.WithMessageKey("biz.dataExportSchedule")),
+	}
+}
+
+// parseDataExportScheduleCron 解析 cron 表达式并检查执行间隔
+func parseDataExportScheduleCron(expr string, now time.Time) (cron.Schedule, error) {
+	schedule, err := cron.ParseStandard(expr)
+	if err != nil {
+		return nil, fmt.Errorf("invalid cron expression %q: %v", expr, err)
+	}
+	next := schedule.Next(now)
+	for i := 0; i < 10; i++ {
+		after := schedule.Next(next)
+		if after.Sub(next) < dataExportScheduleMinInterval {
+			return nil, ErrDataExportScheduleIntervalTooShort
+		}
+		next = after
+	}
+	return schedule, nil
+}
+
+// isDataExportWorkflowApproved 审批通过后工单进入待导出、导出中或已结束状态
+func isDataExportWorkflowApproved(status DataExportWorkflowStatus) bool {
+	switch status {
+	case DataExportWorkflowStatusWaitForExport, DataExportWorkflowStatusWaitForExporting, DataExportWorkflowStatusFinish:
+		return true
+	}
+	return false
+}
+
+// auditLevelExceeds 审核等级 level 是否高于 approved，未审核视为 normal
+func auditLevelExceeds(level, approved string) bool {
+	if level == "" {
+		return false
+	}
+	if approved == "" {
+		approved = string(dmsCommonV1.AuditLevelNormal)
+	}
+	order := []dmsCommonV1.SQLAllowQueryAuditLevel{dmsCommonV1.AuditLevelNormal, dmsCommonV1.AuditLevelNotice, dmsCommonV1.AuditLevelWarn, dmsCommonV1.AuditLevelError}
+	return slices.Index(order, dmsCommonV1.SQLAllowQueryAuditLevel(level)) > slices.Index(order, dmsCommonV1.SQLAllowQueryAuditLevel(approved))
+}
+
+func (d *DataExportScheduleUsecase) checkCanManageSchedule(ctx context.Context, currentUserUid string, workflow *Workflow) error {
+	if workflow.CreateUserUID == currentUserUid {
+		return nil
+	}
+	if canOpProject, err := d.opPermissionVerifyUsecase.CanOpProject(ctx, currentUserUid, workflow.ProjectUID, false); err != nil {
+		return fmt.Errorf("check user can op project failed: %v", err)
+	} else if !canOpProject {
+		return fmt.Errorf("only the creator or project admin can manage the data export schedule")
+	}
+	return nil
+}
+
+func (d *DataExportScheduleUsecase) getProjectWorkflow(ctx context.Context, projectUid, workflowUid string) (*Workflow, error) {
+	workflow, err := d.workflowUsecase.repo.GetDataExportWorkflow(ctx, workflowUid)
+	if err != nil {
+		return nil, fmt.Errorf("get data export workflow failed: %v", err)
+	}
+	if workflow.ProjectUID != projectUid {
+		return nil, fmt.Errorf("data export workflow %s not found in project", workflowUid)
+	}
+	return workflow, nil
+}
+
+type SetDataExportScheduleArgs struct {
+	ProjectUID     string
+	WorkflowUID    string
+	CronExpression string
+	EndTime        time.Time
+}
+
+// SetDataExportSchedule 为已审批的工单设置定时导出，已设置时只更新执行时间，冻结的导出任务保持审批时的内容
+func (d *DataExportScheduleUsecase) SetDataExportSchedule(ctx context.Context, currentUserUid string, args *SetDataExportScheduleArgs) (*DataExportSchedule, error) {
+	workflow, err := d.getProjectWorkflow(ctx, args.ProjectUID, args.WorkflowUID)
+	if err != nil {
+		return nil, err
+	}
+	if err := d.checkCanManageSchedule(ctx, currentUserUid, workflow); err != nil {
+		return nil, err
+	}
+	if workflow.WorkflowRecord == nil || !isDataExportWorkflowApproved(workflow.WorkflowRecord.Status) {
+		return nil, ErrDataExportWorkflowNotApproved
+	}
+
+	now := time.Now()
+	cronSchedule, err := parseDataExportScheduleCron(args.CronExpression, now)
+	if err != nil {
+		return nil, err
+	}
+	if !args.EndTime.IsZero() && !args.EndTime.After(now) {
+		return nil, fmt.Errorf("end time must be later than now")
+	}
+
+	schedule, err := d.repo.GetDataExportScheduleByWorkflow(ctx, workflow.UID)
+	if err != nil && !errors.Is(err, pkgErr.ErrStorageNoData) {
+		return nil, fmt.Errorf("get data export schedule failed: %v", err)
+	}
+	isNew := schedule == nil
+	if isNew {
+		uid, err := pkgRand.GenStrUid()
+		if err != nil {
+			return nil, err
+		}
+		tasks, err := d.freezeDataExportTasks(ctx, workflow)
+		if err != nil {
+			return nil, err
+		}
+		schedule = &DataExportSchedule{
+			UID:           uid,
+			ProjectUID:    workflow.ProjectUID,
+			WorkflowUID:   workflow.UID,
+			CreateUserUID: workflow.CreateUserUID,
+			EncryptExport: workflow.EncryptExport,
+			Tasks:         tasks,
+			Status:        DataExportScheduleStatusActive,
+		}
+		if reason := d.checkDataExportSchedule(ctx, schedule); reason != "" {
+			return nil, errors.New(reason)
+		}
+	}
+	schedule.CronExpression = args.CronExpression
+	schedule.EndTime = args.EndTime
+	schedule.NextRunAt = cronSchedule.Next(now)
+	if schedule.Status == DataExportScheduleStatusEnded {
+		schedule.Status = DataExportScheduleStatusActive
+	}
+
+	if isNew {
+		err = d.repo.SaveDataExportSchedule(ctx, schedule)
+	} else {
+		err = d.repo.UpdateDataExportSchedule(ctx, schedule)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("save data export schedule failed: %v", err)
+	}
+	return schedule, nil
+}
+
+// freezeDataExportTasks 记录审批通过的导出任务及数据源状态
+func (d *DataExportScheduleUsecase) freezeDataExportTasks(ctx context.Context, workflow *Workflow) ([]*DataExportScheduleTask, error) {
+	tasks, err := d.workflowUsecase.dataExportTaskRepo.GetDataExportTaskByIds(ctx, workflow.TaskIds)
+	if err != nil {
+		return nil, fmt.Errorf("get data export tasks failed: %v", err)
+	}
+	if len(tasks) == 0 {
+		return nil, fmt.Errorf("data export workflow has no export task")
+	}
+	ret := make([]*DataExportScheduleTask, 0, len(tasks))
+	for _, task := range tasks {
+		dbService, err := d.dbServiceUsecase.GetDBService(ctx, task.DBServiceUid)
+		if err != nil {
+			return nil, fmt.Errorf("get db service %s failed: %v", task.DBServiceUid, err)
+		}
+		ret = append(ret, &DataExportScheduleTask{
+			DBServiceUID:       task.DBServiceUid,
+			DatabaseName:       task.DatabaseName,
+			ExportSQL:          task.ExportSQL,
+			ExportFileType:     task.ExportFileType,
+			AuditLevel:         task.AuditLevel,
+			DBServiceUpdatedAt: dbService.UpdatedAt,
+		})
+	}
+	return ret, nil
+}
+
+// checkDataExportSchedule 检查数据源未变更且申请人仍有导出权限，返回暂停原因，为空表示可以执行
+func (d *DataExportScheduleUsecase) checkDataExportSchedule(ctx context.Context, schedule *DataExportSchedule) string {
+	user, err := d.userUsecase.GetUser(ctx, schedule.CreateUserUID)
+	if err != nil {
+		return fmt.Sprintf("get requester failed: %v", err)
+	}
+	if user.Stat != UserStatOK {
+		return "requester is disabled"
+	}
+	opPermissions, err := d.opPermissionVerifyUsecase.GetUserOpPermissionInProject(ctx, schedule.CreateUserUID, schedule.ProjectUID)
+	if err != nil {
+		return fmt.Sprintf("get requester permissions failed: %v", err)
+	}
+	isAdmin, err := d.opPermissionVerifyUsecase.CanOpProject(ctx, schedule.CreateUserUID, schedule.ProjectUID, false)
+	if err != nil {
+		return fmt.Sprintf("check requester permissions failed: %v", err)
+	}
+	for _, task := range schedule.Tasks {
+		dbService, err := d.dbServiceUsecase.GetDBService(ctx, task.DBServiceUID)
+		if errors.Is(err, pkgErr.ErrStorageNoData) {
+			return fmt.Sprintf("db service %s has been deleted", task.DBServiceUID)
+		} else if err != nil {
+			return fmt.Sprintf("get db service %s failed: %v", task.DBServiceUID, err)
+		}
+		if !dbService.UpdatedAt.Equal(task.DBServiceUpdatedAt) {
+			return fmt.Sprintf("db service %s has been modified since approval", dbService.Name)
+		}
+		if !isAdmin && !d.opPermissionVerifyUsecase.UserCanOpDBWithObjects(opPermissions, []string{pkgConst.UIDOfOpPermissionExportCreate}, task.DBServiceUID, dataExportTaskObjects(task.DatabaseName)) {
+			return fmt.Sprintf("requester has no export permission on db service %s", dbService.Name)
+		}
+	}
+	return ""
+}
+
+func (d *DataExportScheduleUsecase) DeleteDataExportSchedule(ctx context.Context, currentUserUid, projectUid, workflowUid string) error {
+	workflow, err := d.getProjectWorkflow(ctx, projectUid, workflowUid)
+	if err != nil {
+		return err
+	}
+	if err := d.checkCanManageSchedule(ctx, currentUserUid, workflow); err != nil {
+		return err
+	}
+	schedule, err := d.repo.GetDataExportScheduleByWorkflow(ctx, workflowUid)
+	if err != nil {
+		return fmt.Errorf("get data export schedule failed: %v", err)
+	}
+	return d.repo.DeleteDataExportSchedule(ctx, schedule.UID)
+}
+
+// ResumeDataExportSchedule 恢复暂停的定时导出，数据源变更后需要重新提交工单审批
+func (d *DataExportScheduleUsecase) ResumeDataExportSchedule(ctx context.Context, currentUserUid, projectUid, workflowUid string) error {
+	workflow, err := d.getProjectWorkflow(ctx, projectUid, workflowUid)
+	if err != nil {
+		return err
+	}
+	if err := d.checkCanManageSchedule(ctx, currentUserUid, workflow); err != nil {
+		return err
+	}
+	schedule, err := d.repo.GetDataExportScheduleByWorkflow(ctx, workflowUid)
+	if err != nil {
+		return fmt.Errorf("get data export schedule failed: %v", err)
+	}
+	if schedule.Status != DataExportScheduleStatusSuspended {
+		return fmt.Errorf("data export schedule is not suspended")
+	}
+	if reason := d.checkDataExportSchedule(ctx, schedule); reason != "" {
+		return errors.New(reason)
+	}
+	cronSchedule, err := parseDataExportScheduleCron(schedule.CronExpression, time.Now())
+	if err != nil {
+		return err
+	}
+	schedule.Status = DataExportScheduleStatusActive
+	schedule.SuspendReason = ""
+	schedule.NextRunAt = cronSchedule.Next(time.Now())
+	return d.repo.UpdateDataExportSchedule(ctx, schedule)
+}
+
+func (d *DataExportScheduleUsecase) GetDataExportSchedule(ctx context.Context, workflowUid string) (*DataExportSchedule, error) {
+	return d.repo.GetDataExportScheduleByWorkflow(ctx, workflowUid)
+}
+
+// ListDataExportSchedules 项目管理员查看项目内全部定时导出，其他用户只能查看自己的
+func (d *DataExportScheduleUsecase) ListDataExportSchedules(ctx context.Context, currentUserUid, projectUid string) ([]*DataExportSchedule, error) {
+	canOpProject, err := d.opPermissionVerifyUsecase.CanOpProject(ctx, currentUserUid, projectUid, false)
+	if err != nil {
+		return nil, fmt.Errorf("check user can op project failed: %v", err)
+	}
+	createUserUid := currentUserUid
+	if canOpProject {
+		createUserUid = ""
+	}
+	return d.repo.ListDataExportSchedules(ctx, projectUid, createUserUid)
+}
+
+// RunDueDataExportSchedules 定时执行到期的定时导出，集群模式下仅由主节点执行，避免重复导出
+func (d *DataExportScheduleUsecase) RunDueDataExportSchedules() {
+	if d.clusterUsecase.IsClusterMode() && !d.clusterUsecase.IsLeader() {
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Minute)
+	defer cancel()
+
+	now := time.Now()
+	schedules, err := d.repo.ListDueDataExportSchedules(ctx, now)
+	if err != nil {
+		d.log.Errorf("list due data export schedules failed: %v", err)
+		return
+	}
+	for _, schedule := range schedules {
+		d.runDataExportSchedule(ctx, schedule, now)
+		if err := d.repo.UpdateDataExportSchedule(ctx, schedule); err != nil {
+			d.log.Errorf("update data export schedule %s failed: %v", schedule.UID, err)
+		}
+	}
+}
+
+func (d *DataExportScheduleUsecase) runDataExportSchedule(ctx context.Context, schedule *DataExportSchedule, now time.Time) {
+	if !schedule.EndTime.IsZero() && now.After(schedule.EndTime) {
+		schedule.Status = DataExportScheduleStatusEnded
+		return
+	}
+	cronSchedule, err := parseDataExportScheduleCron(schedule.CronExpression, now)
+	if err != nil {
+		d.suspendDataExportSchedule(ctx, schedule, err.Error())
+		return
+	}
+	schedule.NextRunAt = cronSchedule.Next(now)
+	if reason := d.checkDataExportSchedule(ctx, schedule); reason != "" {
+		d.suspendDataExportSchedule(ctx, schedule, reason)
+		return
+	}
+
+	schedule.LastRunAt = now
+	schedule.LastRunError = ""
+	workflowUid, reason, err := d.runScheduledDataExport(ctx, schedule)
+	if reason != "" {
+		d.suspendDataExportSchedule(ctx, schedule, reason)
+		return
+	}
+	if err != nil {
+		d.log.Errorf("run data export schedule %s failed: %v", schedule.UID, err)
+		schedule.LastRunError = err.Error()
+		return
+	}
+	schedule.LastRunWorkflowUID = workflowUid
+	d.notifyDataExportSchedule(ctx, schedule, locale.NotifyDataExportScheduleRunSubject, locale.NotifyDataExportScheduleRunBody, workflowUid)
+}
+
+// runScheduledDataExport 按冻结的 SQL 创建导出任务并重新审核，审核结果未超出审批时的等级则直接创建待导出的工单
+func (d *DataExportScheduleUsecase) runScheduledDataExport(ctx context.Context, schedule *DataExportSchedule) (workflowUid, suspendReason string, err error) {
+	tasks := make([]*DataExportTask, 0, len(schedule.Tasks))
+	for _, t := range schedule.Tasks {
+		tasks = append(tasks, &DataExportTask{
+			DBServiceUid:   t.DBServiceUID,
+			CreateUserUID:  schedule.CreateUserUID,
+			DatabaseName:   t.DatabaseName,
+			ExportType:     "SQL",
+			ExportFileType: t.ExportFileType,
+			ExportSQL:      t.ExportSQL,
+			ExportStatus:   DataExportTaskStatusInit,
+		})
+	}
+	taskUids, err := d.workflowUsecase.AddDataExportTasks(ctx, schedule.ProjectUID, schedule.CreateUserUID, tasks)
+	if err != nil {
+		return "", "", fmt.Errorf("add data export tasks failed: %v", err)
+	}
+	audited, err := d.workflowUsecase.dataExportTaskRepo.GetDataExportTaskByIds(ctx, taskUids)
+	if err != nil {
+		return "", "", fmt.Errorf("get data export tasks failed: %v", err)
+	}
+	auditLevels := make(map[string]string, len(audited))
+	for _, task := range audited {
+		auditLevels[task.UID] = task.AuditLevel
+	}
+	for i, uid := range taskUids {
+		if i < len(schedule.Tasks) && auditLevelExceeds(auditLevels[uid], schedule.Tasks[i].AuditLevel) {
+			return "", fmt.Sprintf("audit level of export sql raised from %q to %q, approval is required again", schedule.Tasks[i].AuditLevel, auditLevels[uid]), nil
+		}
+	}
+	workflowUid, err = d.workflowUsecase.createScheduledDataExportWorkflow(ctx, schedule, taskUids)
+	if err != nil {
+		return "", "", fmt.Errorf("create data export workflow failed: %v", err)
+	}
+	return workflowUid, "", nil
+}
+
+func (d *DataExportScheduleUsecase) suspendDataExportSchedule(ctx context.Context, schedule *DataExportSchedule, reason string) {
+	schedule.Status = DataExportScheduleStatusSuspended
+	schedule.SuspendReason = reason
+	d.notifyDataExportSchedule(ctx, schedule, locale.NotifyDataExportScheduleSuspendedSubject, locale.NotifyDataExportScheduleSuspendedBody, reason)
+}
+
+func (d *DataExportScheduleUsecase) notifyDataExportSchedule(ctx context.Context, schedule *DataExportSchedule, subject, body *i18n.Message, detail string) {
+	user, err := d.userUsecase.GetUser(ctx, schedule.CreateUserUID)
+	if err != nil {
+		d.log.Errorf("get user %s failed: %v", schedule.CreateUserUID, err)
+		return
+	}
+	project, err := d.projectUsecase.GetProject(ctx, schedule.ProjectUID)
+	if err != nil {
+		d.log.Errorf("get project %s failed: %v", schedule.ProjectUID, err)
+		return
+	}
+	workflowName := schedule.WorkflowUID
+	if workflow, err := d.workflowUsecase.repo.GetDataExportWorkflow(ctx, schedule.WorkflowUID); err == nil {
+		workflowName = workflow.Name
+	}
+	notifyUsersI18n(ctx, d.log, []*User{user}, subject, body, workflowName, project.Name, detail)
+}
+
+// dataExportTaskObjects 导出任务在 databaseName 中执行，只拥有库级别导出权限的申请人按该库判定；
+// 导出 SQL 涉及的表由 SQLE 解析，DMS 无法据此放行模式或表级别的权限
+func dataExportTaskObjects(databaseName string) []*sqlop.SQLObject {
+	if databaseName == "" {
+		return nil
+	}
+	return []*sqlop.SQLObject{{Type: sqlop.SQLObjectTypeDatabase, DatabaseName: databaseName}}
+}
@@ -0,0 +1,53 @@
+package biz
+
+import (
+	"testing"
+	"time"
+
+	pkgConst "github.com/actiontech/dms/internal/dms/pkg/constant"
+	sqlop "github.com/actiontech/dms/pkg/dms-common/sql_op"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseDataExportScheduleCron(t *testing.T) {
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.Local)
+
+	schedule, err := parseDataExportScheduleCron("0 8 * * 1", now)
+	assert.NoError(t, err)
+	assert.Equal(t, time.Date(2024, 1, 1, 8, 0, 0, 0, time.Local), schedule.Next(now))
+
+	_, err = parseDataExportScheduleCron("0 * * * *", now)
+	assert.NoError(t, err)
+
+	_, err = parseDataExportScheduleCron("*/30 * * * *", now)
+	assert.ErrorIs(t, err, ErrDataExportScheduleIntervalTooShort)
+
+	// 两次执行间隔不足一小时
+	_, err = parseDataExportScheduleCron("0,10 8 * * *", now)
+	assert.ErrorIs(t, err, ErrDataExportScheduleIntervalTooShort)
+
+	_, err = parseDataExportScheduleCron("every monday", now)
+	assert.Error(t, err)
+}
+
+func TestAuditLevelExceeds(t *testing.T) {
+	assert.False(t, auditLevelExceeds("", "warn"))
+	assert.False(t, auditLevelExceeds("normal", ""))
+	assert.False(t, auditLevelExceeds("notice", "warn"))
+	assert.False(t, auditLevelExceeds("warn", "warn"))
+	assert.True(t, auditLevelExceeds("notice", ""))
+	assert.True(t, auditLevelExceeds("error", "warn"))
+}
+
+func TestDataExportTaskObjectRange(t *testing.T) {
+	uc := newTestOpPermissionVerifyUsecase(&mockUserRepo{users: map[string]*User{}}, &mockOpPermissionVerifyRepo{})
+	need := []string{pkgConst.UIDOfOpPermissionExportCreate}
+	dbRange := sqlop.EncodeObjectRange(&sqlop.ObjectRange{DBServiceUID: "db_1", DatabaseName: "report"})
+	perms := []OpPermissionWithOpRange{{OpPermissionUID: pkgConst.UIDOfOpPermissionExportCreate, OpRangeType: OpRangeTypeDatabase, RangeUIDs: []string{dbRange}}}
+
+	assert.True(t, uc.UserCanOpDBWithObjects(perms, need, "db_1", dataExportTaskObjects("report")))
+	assert.False(t, uc.UserCanOpDBWithObjects(perms, need, "db_1", dataExportTaskObjects("sales")))
+	// 未指定库的导出任务可能访问数据源内任意对象
+	assert.False(t, uc.UserCanOpDBWithObjects(perms, need, "db_1", dataExportTaskObjects("")))
+	assert.False(t, uc.UserCanOpDBWithObjects(perms, need, "db_2", dataExportTaskObjects("report")))
+}
@@ -39,6 +39,21 @@ type DataExportTask struct {
 	AuditScore        int32
 	AuditLevel        string
 
+	// WatermarkMode 导出文件的水印方式，为空时不加水印
+	WatermarkMode string
+	// WatermarkFingerprint 导出时写入文件内容的水印指纹
+	WatermarkFingerprint string
+
+	// DeliveryStatus 导出文件投递到外部存储的状态，工单未配置投递目标时为空
+	DeliveryStatus   DataExportDeliveryStatus
+	DeliveryLocation string
+	DeliveryError    string
+	// DeliveryState 投递进度，用于断点续传
+	DeliveryState string
+
+	// ExportFileChecksum 导出文件的 SHA-256，导出文件生成后计算
+	ExportFileChecksum string
+
 	ExportStatus     DataExportTaskStatus
 	ExportStartTime  *time.Time
 	ExportEndTime    *time.Time
@@ -56,6 +71,7 @@ const (
 	DataExportFailStagePrepare      = "prepare"
 	DataExportFailStageSQLExecute   = "sql_execute"
 	DataExportFailStageFileGenerate = "file_generate"
+	DataExportFailStageQuota        = "quota"
 )
 
 func (t *DataExportTask) InstanceName() string {
@@ -0,0 +1,135 @@
+package biz
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/actiontech/dms/internal/dms/pkg/exportfile"
+	pkgRand "github.com/actiontech/dms/pkg/rand"
+)
+
+type DataExportWatermarkKind string
+
+const (
+	// DataExportWatermarkKindExport 导出时写入文件内容的指纹，对应导出任务的申请人
+	DataExportWatermarkKindExport DataExportWatermarkKind = "export"
+	// DataExportWatermarkKindDownload 每次下载时写入归档注释的指纹，对应下载人
+	DataExportWatermarkKindDownload DataExportWatermarkKind = "download"
+)
+
+type DataExportWatermark struct {
+	UID         string
+	Fingerprint string
+	Kind        DataExportWatermarkKind
+	TaskUID     string
+	WorkflowUID string
+	ProjectUID  string
+	UserUID     string
+	CreatedAt   time.Time
+}
+
+type DataExportWatermarkRepo interface {
+	SaveDataExportWatermark(ctx context.Context, watermark *DataExportWatermark) error
+	GetDataExportWatermarksByFingerprints(ctx context.Context, fingerprints []string) ([]*DataExportWatermark, error)
+	ListDataExportWatermarksByTask(ctx context.Context, taskUid string, kind DataExportWatermarkKind) ([]*DataExportWatermark, error)
+}
+
+func (d *DataExportWorkflowUsecase) saveDataExportWatermark(ctx context.Context, kind DataExportWatermarkKind, workflow *Workflow, taskUid, userUid string) (string, error) {
+	uid, err := pkgRand.GenStrUid()
+	if err != nil {
+		return "", err
+	}
+	fingerprint, err := exportfile.NewFingerprint()
+	if err != nil {
+		return "", err
+	}
+	if err := d.watermarkRepo.SaveDataExportWatermark(ctx, &DataExportWatermark{
+		UID:         uid,
+		Fingerprint: fingerprint,
+		Kind:        kind,
+		TaskUID:     taskUid,
+		WorkflowUID: workflow.UID,
+		ProjectUID:  workflow.ProjectUID,
+		UserUID:     userUid,
+	}); err != nil {
+		return "", fmt.Errorf("save data export watermark failed: %v", err)
+	}
+	return fingerprint, nil
+}
+
+// PrepareExportWatermark 导出任务开始前生成写入文件内容的指纹，未开启水印或仅写入归档注释时返回空
+func (d *DataExportWorkflowUsecase) PrepareExportWatermark(ctx context.Context, workflow *Workflow, task *DataExportTask) (string, error) {
+	mode := exportfile.WatermarkMode(task.WatermarkMode)
+	if mode != exportfile.WatermarkModeMarkerColumn && mode != exportfile.WatermarkModeInvisible {
+		return "", nil
+	}
+	fingerprint, err := d.saveDataExportWatermark(ctx, DataExportWatermarkKindExport, workflow, task.UID, workflow.CreateUserUID)
+	if err != nil {
+		return "", err
+	}
+	if err := d.dataExportTaskRepo.BatchUpdateDataExportTaskByIds(ctx, []string{task.UID}, map[string]interface{}{"watermark_fingerprint": fingerprint}); err != nil {
+		return "", fmt.Errorf("save data export task fingerprint failed: %v", err)
+	}
+	task.WatermarkFingerprint = fingerprint
+	return fingerprint, nil
+}
+
+// PrepareDownloadWatermark 每次新的下载生成新的指纹，由 OpenDataExportDownloadFile 写入归档注释；未开启水印时返回空。
+// 续传沿用原下载的指纹，不调用此方法
+func (d *DataExportWorkflowUsecase) PrepareDownloadWatermark(ctx context.Context, projectUid string, task *DataExportTask, downloadUserUid string) (string, error) {
+	if task.WatermarkMode == "" {
+		return "", nil
+	}
+	workflow, err := d.getDataExportWorkflowByTask(ctx, projectUid, task.UID)
+	if err != nil {
+		return "", err
+	}
+	return d.saveDataExportWatermark(ctx, DataExportWatermarkKindDownload, workflow, task.UID, downloadUserUid)
+}
+
+// DataExportTrace 追溯到的导出任务，Watermark 为文件中匹配的指纹，Downloads 为该导出任务的全部下载记录
+type DataExportTrace struct {
+	Watermark *DataExportWatermark
+	Downloads []*DataExportWatermark
+}
+
+// TraceDataExport 从泄露的文件或复制的部分行中追溯导出任务及下载人，仅返回当前用户可管理的项目中的导出
+func (d *DataExportWorkflowUsecase) TraceDataExport(ctx context.Context, currentUserUid string, file []byte, sample string) ([]*DataExportTrace, error) {
+	fingerprints := exportfile.TraceFingerprints(file)
+	for _, fp := range exportfile.TraceFingerprints([]byte(sample)) {
+		if !slices.Contains(fingerprints, fp) {
+			fingerprints = append(fingerprints, fp)
+		}
+	}
+	if len(fingerprints) == 0 {
+		return []*DataExportTrace{}, nil
+	}
+	watermarks, err := d.watermarkRepo.GetDataExportWatermarksByFingerprints(ctx, fingerprints)
+	if err != nil {
+		return nil, fmt.Errorf("get data export watermarks failed: %v", err)
+	}
+
+	canOpProjects := map[string]bool{}
+	ret := make([]*DataExportTrace, 0, len(watermarks))
+	for _, w := range watermarks {
+		canOp, ok := canOpProjects[w.ProjectUID]
+		if !ok {
+			canOp, err = d.opPermissionVerifyUsecase.CanOpProject(ctx, currentUserUid, w.ProjectUID, false)
+			if err != nil {
+				return nil, fmt.Errorf("check user can op project failed: %v", err)
+			}
+			canOpProjects[w.ProjectUID] = canOp
+		}
+		if !canOp {
+			continue
+		}
+		downloads, err := d.watermarkRepo.ListDataExportWatermarksByTask(ctx, w.TaskUID, DataExportWatermarkKindDownload)
+		if err != nil {
+			return nil, fmt.Errorf("list data export downloads failed: %v", err)
+		}
+		ret = append(ret, &DataExportTrace{Watermark: w, Downloads: downloads})
+	}
+	return ret, nil
+}
@@ -0,0 +1,112 @@
+package biz
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	pkgConst "github.com/actiontech/dms/internal/dms/pkg/constant"
+	"github.com/actiontech/dms/internal/dms/pkg/exportfile"
+	"github.com/stretchr/testify/assert"
+)
+
+type mockDataExportWatermarkRepo struct {
+	watermarks []*DataExportWatermark
+}
+
+func (m *mockDataExportWatermarkRepo) SaveDataExportWatermark(_ context.Context, watermark *DataExportWatermark) error {
+	m.watermarks = append(m.watermarks, watermark)
+	return nil
+}
+
+func (m *mockDataExportWatermarkRepo) GetDataExportWatermarksByFingerprints(_ context.Context, fingerprints []string) ([]*DataExportWatermark, error) {
+	var ret []*DataExportWatermark
+	for _, w := range m.watermarks {
+		for _, fp := range fingerprints {
+			if w.Fingerprint == fp {
+				ret = append(ret, w)
+			}
+		}
+	}
+	return ret, nil
+}
+
+func (m *mockDataExportWatermarkRepo) ListDataExportWatermarksByTask(_ context.Context, taskUid string, kind DataExportWatermarkKind) ([]*DataExportWatermark, error) {
+	var ret []*DataExportWatermark
+	for _, w := range m.watermarks {
+		if w.TaskUID == taskUid && w.Kind == kind {
+			ret = append(ret, w)
+		}
+	}
+	return ret, nil
+}
+
+func TestTraceDataExport(t *testing.T) {
+	repo := &mockDataExportWatermarkRepo{}
+	opRepo := &mockOpPermissionVerifyRepo{
+		projectPermissions: map[string]map[string]map[string]bool{
+			"user_2": {"p2": {pkgConst.UIDOfOpPermissionProjectAdmin: true}},
+		},
+	}
+	workflow := &Workflow{UID: "w1", ProjectUID: "p1", CreateUserUID: "user_1"}
+	uc := &DataExportWorkflowUsecase{
+		repo:                      &mockDataExportTaskWorkflowRepo{workflow: workflow},
+		watermarkRepo:             repo,
+		opPermissionVerifyUsecase: newTestOpPermissionVerifyUsecase(&mockUserRepo{}, opRepo),
+	}
+	ctx := context.Background()
+	task := &DataExportTask{UID: "t1", WatermarkMode: string(exportfile.WatermarkModeMetadata)}
+
+	fingerprint, err := uc.PrepareDownloadWatermark(ctx, "p1", task, "user_3")
+	assert.NoError(t, err)
+	_, err = uc.PrepareDownloadWatermark(ctx, "p1", task, "user_4")
+	assert.NoError(t, err)
+
+	fingerprint2, err := uc.PrepareDownloadWatermark(ctx, "p1", &DataExportTask{UID: "t1"}, "user_3")
+	assert.NoError(t, err)
+	assert.Empty(t, fingerprint2)
+
+	// 下载的内容在归档注释中写入下载指纹，原文件不变
+	buf := &bytes.Buffer{}
+	zw := zip.NewWriter(buf)
+	_, err = zw.Create("users.csv")
+	assert.NoError(t, err)
+	assert.NoError(t, zw.Close())
+	filePath := filepath.Join(t.TempDir(), "export.zip")
+	assert.NoError(t, os.WriteFile(filePath, buf.Bytes(), 0600))
+	file, err := OpenDataExportDownloadFile(filePath, fingerprint)
+	assert.NoError(t, err)
+	downloaded, err := io.ReadAll(file)
+	assert.NoError(t, err)
+	assert.NoError(t, file.Close())
+	assert.True(t, file.ModTime.IsZero())
+	original, err := os.ReadFile(filePath)
+	assert.NoError(t, err)
+	assert.Equal(t, buf.Bytes(), original)
+
+	traces, err := uc.TraceDataExport(ctx, pkgConst.UIDOfUserAdmin, original, "")
+	assert.NoError(t, err)
+	assert.Empty(t, traces)
+	buf = bytes.NewBuffer(downloaded)
+
+	traces, err = uc.TraceDataExport(ctx, pkgConst.UIDOfUserAdmin, buf.Bytes(), "")
+	assert.NoError(t, err)
+	if assert.Len(t, traces, 1) {
+		assert.Equal(t, "user_3", traces[0].Watermark.UserUID)
+		assert.Equal(t, DataExportWatermarkKindDownload, traces[0].Watermark.Kind)
+		assert.Len(t, traces[0].Downloads, 2)
+	}
+
+	// 仅返回可管理项目中的导出
+	traces, err = uc.TraceDataExport(ctx, "user_2", buf.Bytes(), "")
+	assert.NoError(t, err)
+	assert.Empty(t, traces)
+
+	traces, err = uc.TraceDataExport(ctx, pkgConst.UIDOfUserAdmin, nil, "a,b,c")
+	assert.NoError(t, err)
+	assert.Empty(t, traces)
+}
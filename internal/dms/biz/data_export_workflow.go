@@ -77,8 +77,16 @@ type Workflow struct {
 	WorkflowTemplateName string
 	// OpsTypeUID 运维类型字典项标识；空表示未设置；创建后不可改
 	OpsTypeUID string
-	Tasks      []Task
-	TaskIds    []string
+	// EncryptExport 导出文件加密为 AES-256 zip，解压密码通过通知单独发送给申请人
+	EncryptExport bool
+	// ExportPassword 导出时生成的解压密码，加密存储，不通过接口返回
+	ExportPassword string
+	// MaxDownloads 每个导出任务的最大下载次数，0 表示不限制
+	MaxDownloads int
+	// DeliveryTargetUID 导出文件的投递目标，为空时不投递
+	DeliveryTargetUID string
+	Tasks             []Task
+	TaskIds           []string
 
 	WorkflowRecord *WorkflowRecord
 	DBServiceInfos []*dmsCommonV1.DBServiceUidWithNameInfo // 所属数据源信息
@@ -123,9 +131,12 @@ type WorkflowRepo interface {
 	UpdateWorkflowStatusById(ctx context.Context, dataExportWorkflowUid string, status DataExportWorkflowStatus) error
 	// UpdateWorkflowExportStatusById 更新工单导出状态与失败摘要（进入 exporting 时 summary 传空以清空上一轮）
 	UpdateWorkflowExportStatusById(ctx context.Context, dataExportWorkflowUid string, status DataExportWorkflowStatus, exportFailSummary string) error
+	// UpdateWorkflowExportPassword 保存导出时生成的解压密码
+	UpdateWorkflowExportPassword(ctx context.Context, workflowUID, password string) error
 	// UpdateWorkflowColumns 更新 workflows 行字段；强制忽略 ops_type_uid（创建后不可改）。
 	UpdateWorkflowColumns(ctx context.Context, workflowUID string, updates map[string]interface{}) error
 	GetDataExportWorkflowsByIds(ctx context.Context, dataExportWorkflowUid []string) ([]*Workflow, error)
+	GetDataExportWorkflowByTask(ctx context.Context, dataExportTaskUid string) (*Workflow, error)
 	CancelWorkflow(ctx context.Context, workflowRecordIds []string, workflowSteps []*WorkflowStep, operateId string) error
 	AuditWorkflow(ctx context.Context, dataExportWorkflowUid string, status DataExportWorkflowStatus, step *WorkflowStep, operateId, reason string) error
 	GetDataExportWorkflowsForView(ctx context.Context, userUid string) ([]string, error)
@@ -161,11 +172,16 @@ type DataExportWorkflowUsecase struct {
 	systemVariableUsecase     *SystemVariableUsecase
 	dbServiceUsecase          *DBServiceUsecase
 	unmaskingWorkflowUsecase  *dataMaskingBiz.UnmaskingWorkflowUsecase
+	quotaUsecase              *DataExportQuotaUsecase
+	watermarkRepo             DataExportWatermarkRepo
+	downloadRepo              DataExportDownloadRepo
+	deliveryUsecase           *DataExportDeliveryUsecase
+	separationOfDutiesUsecase *SeparationOfDutiesUsecase
 	log                       *utilLog.Helper
 	reportHost                string
 }
 
-func NewDataExportWorkflowUsecase(logger utilLog.Logger, tx TransactionGenerator, repo WorkflowRepo, dataExportTaskRepo DataExportTaskRepo, dbServiceRepo DBServiceRepo, maskingConfigRepo DataExportMaskingConfigRepo, maskingRuleRepo DataExportMaskingRuleRepo, opPermissionVerifyUsecase *OpPermissionVerifyUsecase, projectUsecase *ProjectUsecase, opsTypeUsecase *OpsTypeUsecase, proxyTargetRepo ProxyTargetRepo, clusterUseCase *ClusterUsecase, webhookUsecase *WebHookConfigurationUsecase, userUsecase *UserUsecase, systemVariableUsecase *SystemVariableUsecase, dbServiceUsecase *DBServiceUsecase, unmaskingWorkflowUsecase *dataMaskingBiz.UnmaskingWorkflowUsecase, reportHost string) *DataExportWorkflowUsecase {
+func NewDataExportWorkflowUsecase(logger utilLog.Logger, tx TransactionGenerator, repo WorkflowRepo, dataExportTaskRepo DataExportTaskRepo, dbServiceRepo DBServiceRepo, maskingConfigRepo DataExportMaskingConfigRepo, maskingRuleRepo DataExportMaskingRuleRepo, opPermissionVerifyUsecase *OpPermissionVerifyUsecase, projectUsecase *ProjectUsecase, opsTypeUsecase *OpsTypeUsecase, proxyTargetRepo ProxyTargetRepo, clusterUseCase *ClusterUsecase, webhookUsecase *WebHookConfigurationUsecase, userUsecase *UserUsecase, systemVariableUsecase *SystemVariableUsecase, dbServiceUsecase *DBServiceUsecase, unmaskingWorkflowUsecase *dataMaskingBiz.UnmaskingWorkflowUsecase, quotaUsecase *DataExportQuotaUsecase, watermarkRepo DataExportWatermarkRepo, downloadRepo DataExportDownloadRepo, deliveryUsecase *DataExportDeliveryUsecase, separationOfDutiesUsecase *SeparationOfDutiesUsecase, reportHost string) *DataExportWorkflowUsecase {
 	return &DataExportWorkflowUsecase{
 		tx:                        tx,
 		repo:                      repo,
@@ -183,11 +199,24 @@ func NewDataExportWorkflowUsecase(logger utilLog.Logger, tx TransactionGenerator
 		systemVariableUsecase:     systemVariableUsecase,
 		dbServiceUsecase:          dbServiceUsecase,
 		unmaskingWorkflowUsecase:  unmaskingWorkflowUsecase,
+		quotaUsecase:              quotaUsecase,
+		watermarkRepo:             watermarkRepo,
+		downloadRepo:              downloadRepo,
+		deliveryUsecase:           deliveryUsecase,
+		separationOfDutiesUsecase: separationOfDutiesUsecase,
 		log:                       utilLog.NewHelper(logger, utilLog.WithMessageKey("biz.dataExportWorkflow")),
 		reportHost:                reportHost,
 	}
 }
 
+// ApproveDataExportWorkflow 审批前校验项目的职责分离规则，工单创建人不能审批自己的工单
+func (d *DataExportWorkflowUsecase) ApproveDataExportWorkflow(ctx context.Context, projectId, workflowId, userId, reason string) error {
+	if err := d.separationOfDutiesUsecase.CheckDataExportApprover(ctx, projectId, workflowId, userId); err != nil {
+		return err
+	}
+	return d.approveDataExportWorkflow(ctx, projectId, workflowId, userId, reason)
+}
+
 // CountDataExportWorkflowsByOpsTypeUID 统计本项目数据导出工单对运维类型标识的引用数（删除字典前引用校验）。
 func (d *DataExportWorkflowUsecase) CountDataExportWorkflowsByOpsTypeUID(ctx context.Context, projectUID, opsTypeUID string) (int64, error) {
 	if opsTypeUID == "" {
@@ -23,7 +23,7 @@ func (d *DataExportWorkflowUsecase) GetDataExportWorkflow(ctx context.Context, w
 	return nil, errNotDataExportWorkflow
 }
 
-func (d *DataExportWorkflowUsecase) ApproveDataExportWorkflow(ctx context.Context, projectId, workflowId, userId, reason string) error {
+func (d *DataExportWorkflowUsecase) approveDataExportWorkflow(ctx context.Context, projectId, workflowId, userId, reason string) error {
 	return errNotDataExportWorkflow
 }
 
@@ -62,6 +62,11 @@ func (d *DataExportWorkflowUsecase) DownloadDataExportTaskSQLs(ctx context.Conte
 	return "", nil, errNotDataExportTask
 }
 
+// createScheduledDataExportWorkflow 定时导出创建无需审批的工单并开始导出
+func (d *DataExportWorkflowUsecase) createScheduledDataExportWorkflow(ctx context.Context, schedule *DataExportSchedule, taskUids []string) (string, error) {
+	return "", errNotDataExportWorkflow
+}
+
 func (d *DataExportWorkflowUsecase) RecycleWorkflow()            {}
 func (d *DataExportWorkflowUsecase) RecycleDataExportTask()      {}
 func (d *DataExportWorkflowUsecase) RecycleDataExportTaskFiles() {}
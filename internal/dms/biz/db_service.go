@@ -110,6 +110,8 @@ func (d *DBService) GetAllowQueryWhenLessThanAuditLevel() string {
 
 const (
 	DBServiceAdditionalParam_RuleTemplateName = "rule_template_name"
+	// DBServiceAdditionalParam_BusinessTag 数据源所属的业务，不依赖数据库驱动，所有类型的数据源都可以设置
+	DBServiceAdditionalParam_BusinessTag = "business_tag"
 )
 
 func newDBService(args *BizDBServiceArgs) (*DBService, error) {
@@ -496,7 +498,9 @@ func (d *DBServiceUsecase) ListDBServiceTips(ctx context.Context, req *dmsV1.Lis
 			return nil, err
 		}
 
-		if d.opPermissionVerifyUsecase.UserCanOpDB(permissions, []string{permissionId}, item.UID) {
+		// 只拥有部分库、模式或表权限的数据源同样可选，访问的对象在执行时判定
+		if d.opPermissionVerifyUsecase.UserCanOpDB(permissions, []string{permissionId}, item.UID) ||
+			d.opPermissionVerifyUsecase.UserHasDBObjectRange(permissions, []string{permissionId}, item.UID) {
 			ret = append(ret, item)
 		}
 	}
@@ -11,10 +11,56 @@ import (
 
 type LoginConfiguration struct {
 	Base
-	UID                   string
-	LoginButtonText       string
-	DisableUserPwdLogin   bool
-	DisableMultipleLogin  bool
+	UID                  string
+	LoginButtonText      string
+	DisableUserPwdLogin  bool
+	DisableMultipleLogin bool
+
+	// 密码策略，仅作用于 DMS 本地用户，值为 0 表示不限制
+	PasswordMinLength        uint
+	PasswordRequireUppercase bool
+	PasswordRequireLowercase bool
+	PasswordRequireDigit     bool
+	PasswordRequireSpecial   bool
+	PasswordHistoryCount     uint
+	PasswordMaxAgeDays       uint
+
+	// 登录锁定策略，LockoutMaxFailedAttempts 为 0 表示不锁定
+	LockoutMaxFailedAttempts uint
+	LockoutWindowMinutes     uint
+	LockoutDurationMinutes   uint
+
+	// 会话超时策略，单位分钟，值为 0 表示不限制；管理员策略为 0 时沿用普通用户策略
+	SessionIdleTimeoutMinutes      uint
+	SessionMaxAgeMinutes           uint
+	AdminSessionIdleTimeoutMinutes uint
+	AdminSessionMaxAgeMinutes      uint
+	// access token 策略，单位天，值为 0 表示不限制
+	AccessTokenIdleTimeoutDays uint
+	AccessTokenMaxAgeDays      uint
+}
+
+type UpdateLoginConfigurationArgs struct {
+	LoginButtonText          *string
+	DisableUserPwdLogin      *bool
+	DisableMultipleLogin     *bool
+	PasswordMinLength        *uint
+	PasswordRequireUppercase *bool
+	PasswordRequireLowercase *bool
+	PasswordRequireDigit     *bool
+	PasswordRequireSpecial   *bool
+	PasswordHistoryCount     *uint
+	PasswordMaxAgeDays       *uint
+	LockoutMaxFailedAttempts *uint
+	LockoutWindowMinutes     *uint
+	LockoutDurationMinutes   *uint
+
+	SessionIdleTimeoutMinutes      *uint
+	SessionMaxAgeMinutes           *uint
+	AdminSessionIdleTimeoutMinutes *uint
+	AdminSessionMaxAgeMinutes      *uint
+	AccessTokenIdleTimeoutDays     *uint
+	AccessTokenMaxAgeDays          *uint
 }
 
 func defaultLoginConfiguration() (*LoginConfiguration, error) {
@@ -27,6 +73,9 @@ func defaultLoginConfiguration() (*LoginConfiguration, error) {
 		LoginButtonText:      "登录",
 		DisableUserPwdLogin:  false,
 		DisableMultipleLogin: false,
+		// 锁定窗口与锁定时长默认值，开启锁定时生效
+		LockoutWindowMinutes:   defaultLockoutWindowMinutes,
+		LockoutDurationMinutes: defaultLockoutDurationMinutes,
 	}, nil
 }
 
@@ -67,4 +116,20 @@ func (d *LoginConfigurationUsecase) IsDisableMultipleLogin(ctx context.Context)
 
 func (d *LoginConfigurationUsecase) invalidateDisableMultipleLoginCache() {
 	d.disableMultipleLoginCache.Delete("disable_multiple_login")
+	d.disableMultipleLoginCache.Delete("login_configuration")
+}
+
+// getCachedLoginConfiguration 用于请求级别的高频校验（如密码过期中间件），避免每个请求都查询数据库
+func (d *LoginConfigurationUsecase) getCachedLoginConfiguration(ctx context.Context) (*LoginConfiguration, error) {
+	if cached, found := d.disableMultipleLoginCache.Get("login_configuration"); found {
+		if val, ok := cached.(*LoginConfiguration); ok {
+			return val, nil
+		}
+	}
+	loginC, err := d.GetLoginConfiguration(ctx)
+	if err != nil {
+		return nil, err
+	}
+	d.disableMultipleLoginCache.Set("login_configuration", loginC, cache.DefaultExpiration)
+	return loginC, nil
 }
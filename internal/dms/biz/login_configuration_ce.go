@@ -9,14 +9,16 @@ import (
 
 var errNotSupportLoginConfiguration = errors.New("login configuration related functions are enterprise version functions")
 
-func (d *LoginConfigurationUsecase) UpdateLoginConfiguration(ctx context.Context, LoginButtonText *string, DisableUserPwdLogin *bool, DisableMultipleLogin *bool) error {
+func (d *LoginConfigurationUsecase) UpdateLoginConfiguration(ctx context.Context, args *UpdateLoginConfigurationArgs) error {
 	return errNotSupportLoginConfiguration
 }
 
 func (d *LoginConfigurationUsecase) GetLoginConfiguration(ctx context.Context) (loginC *LoginConfiguration, err error) {
 	return &LoginConfiguration{
-		LoginButtonText:      "登录",
-		DisableUserPwdLogin:  false,
-		DisableMultipleLogin: false,
+		LoginButtonText:        "登录",
+		DisableUserPwdLogin:    false,
+		DisableMultipleLogin:   false,
+		LockoutWindowMinutes:   defaultLockoutWindowMinutes,
+		LockoutDurationMinutes: defaultLockoutDurationMinutes,
 	}, nil
 }
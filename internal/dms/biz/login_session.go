@@ -2,9 +2,12 @@ package biz
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"net/http"
+	"time"
 
+	pkgErr "github.com/actiontech/dms/internal/dms/pkg/errors"
 	jwtPkg "github.com/actiontech/dms/pkg/dms-common/api/jwt"
 	utilLog "github.com/actiontech/dms/pkg/dms-common/pkg/log"
 	pkgRand "github.com/actiontech/dms/pkg/rand"
@@ -12,6 +15,39 @@ import (
 	"github.com/labstack/echo/v4"
 )
 
+const (
+	LoginSessionTypePassword = "password"
+	LoginSessionTypeOAuth2   = "oauth2"
+
+	// loginSessionLastSeenUpdateInterval 限制最近活跃信息的写库频率，避免每个请求都更新数据库
+	loginSessionLastSeenUpdateInterval = time.Minute
+)
+
+var ErrLoginSessionRevoked = errors.New("login session has been revoked, please login again")
+
+// LoginSession 用户登录会话，会话 ID 写入 token 的 jti，会话被吊销后对应的 token 及 refresh token 均失效
+type LoginSession struct {
+	UID        string
+	UserUID    string
+	LoginType  string
+	IP         string
+	UserAgent  string
+	CreatedAt  time.Time
+	LastSeenAt time.Time
+	ExpiredAt  time.Time
+	RevokedAt  time.Time
+}
+
+func (s *LoginSession) IsRevoked() bool {
+	return !s.RevokedAt.IsZero()
+}
+
+type LoginSessionMeta struct {
+	LoginType string
+	IP        string
+	UserAgent string
+}
+
 type AuthLoginSessionUsecase struct {
 	userUsecase               *UserUsecase
 	loginConfigurationUsecase *LoginConfigurationUsecase
@@ -40,10 +76,14 @@ func (au *AuthLoginSessionUsecase) CheckSingleActiveSession(gatewayForwardedHead
 			if tokenDetail.UID == "" {
 				return next(c)
 			}
-			if tokenDetail.LoginType == AccessTokenLogin {
+			if tokenDetail.LoginType == AccessTokenLogin || tokenDetail.LoginType == ServiceAccountLogin {
 				return next(c)
 			}
 
+			if err := au.userUsecase.CheckLoginSessionActive(c.Request().Context(), tokenDetail.UID, tokenDetail.LoginSessionID, ExtractClientIP(c.Request())); err != nil {
+				return echo.NewHTTPError(http.StatusUnauthorized, err.Error())
+			}
+
 			disableMultipleLogin, err := au.loginConfigurationUsecase.IsDisableMultipleLogin(c.Request().Context())
 			if err != nil {
 				return err
@@ -68,7 +108,7 @@ func (au *AuthLoginSessionUsecase) CheckSingleActiveSession(gatewayForwardedHead
 	}
 }
 
-func (d *UserUsecase) CreateLoginSession(ctx context.Context, userUID string) (sessionID string, err error) {
+func (d *UserUsecase) CreateLoginSession(ctx context.Context, userUID string, meta *LoginSessionMeta) (sessionID string, err error) {
 	sessionID, err = pkgRand.GenStrUid()
 	if err != nil {
 		return "", err
@@ -76,10 +116,109 @@ func (d *UserUsecase) CreateLoginSession(ctx context.Context, userUID string) (s
 	if err = d.RecordLoginSession(ctx, userUID, sessionID); err != nil {
 		return "", err
 	}
+
+	now := time.Now()
+	if err = d.repo.SaveLoginSession(ctx, &LoginSession{
+		UID:        sessionID,
+		UserUID:    userUID,
+		LoginType:  meta.LoginType,
+		IP:         meta.IP,
+		UserAgent:  meta.UserAgent,
+		CreatedAt:  now,
+		LastSeenAt: now,
+		ExpiredAt:  now.Add(jwtPkg.DefaultDmsRefreshTokenExpHours * time.Hour),
+	}); err != nil {
+		return "", fmt.Errorf("save login session failed: %v", err)
+	}
 	return sessionID, nil
 }
 
+// CheckLoginSessionActive 校验登录会话未被吊销，并记录会话最近活跃信息；升级前签发的 token 没有会话记录，不做限制
+func (d *UserUsecase) CheckLoginSessionActive(ctx context.Context, userUID, sessionID, clientIP string) error {
+	session, err := d.checkLoginSession(ctx, userUID, sessionID)
+	if err != nil || session == nil {
+		return err
+	}
+	now := time.Now()
+	if session.IP != clientIP || now.Sub(session.LastSeenAt) >= loginSessionLastSeenUpdateInterval {
+		if err := d.repo.UpdateLoginSessionLastSeen(ctx, sessionID, now, clientIP); err != nil {
+			d.log.Errorf("update login session %s last seen failed: %v", sessionID, err)
+		}
+	}
+	return nil
+}
+
+// checkLoginSession 校验登录会话未被吊销且未超出会话策略，不记录活跃信息；会话不存在时返回 nil
+func (d *UserUsecase) checkLoginSession(ctx context.Context, userUID, sessionID string) (*LoginSession, error) {
+	if sessionID == "" {
+		return nil, nil
+	}
+	session, err := d.repo.GetLoginSession(ctx, sessionID)
+	if errors.Is(err, pkgErr.ErrStorageNoData) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("get login session failed: %v", err)
+	}
+	if session.UserUID != userUID || session.IsRevoked() {
+		return nil, ErrLoginSessionRevoked
+	}
+
+	now := time.Now()
+	policy, err := d.getSessionPolicy(ctx, userUID)
+	if err != nil {
+		return nil, fmt.Errorf("get session policy failed: %v", err)
+	}
+	// 超过空闲时长或最长有效期的会话直接吊销，刷新 token 也无法恢复
+	if err := policy.Check(session, now); err != nil {
+		if revokeErr := d.repo.RevokeLoginSession(ctx, sessionID, now); revokeErr != nil {
+			d.log.Errorf("revoke login session %s failed: %v", sessionID, revokeErr)
+		}
+		return nil, err
+	}
+	return session, nil
+}
+
+// RenewLoginSession 刷新 token 时延长会话有效期，配置了会话最长有效期时不会超过该期限
+func (d *UserUsecase) RenewLoginSession(ctx context.Context, sessionID string) error {
+	if sessionID == "" {
+		return nil
+	}
+	renewTo := time.Now().Add(jwtPkg.DefaultDmsRefreshTokenExpHours * time.Hour)
+	session, err := d.repo.GetLoginSession(ctx, sessionID)
+	if errors.Is(err, pkgErr.ErrStorageNoData) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("get login session failed: %v", err)
+	}
+	policy, err := d.getSessionPolicy(ctx, session.UserUID)
+	if err != nil {
+		return fmt.Errorf("get session policy failed: %v", err)
+	}
+	return d.repo.UpdateLoginSessionExpiredAt(ctx, sessionID, policy.ExpiredAt(session, renewTo))
+}
+
+// EndLoginSession 用户登出时结束当前会话
+func (d *UserUsecase) EndLoginSession(ctx context.Context, sessionID string) error {
+	if sessionID == "" {
+		return nil
+	}
+	return d.repo.RevokeLoginSession(ctx, sessionID, time.Now())
+}
+
+func (d *UserUsecase) RevokeUserLoginSessions(ctx context.Context, userUID string) error {
+	if err := d.repo.RevokeUserLoginSessions(ctx, userUID, time.Now()); err != nil {
+		return fmt.Errorf("revoke login sessions of user %s failed: %v", userUID, err)
+	}
+	return nil
+}
+
+// ValidateLoginSession 刷新 token 时校验登录会话，刷新不是用户操作，不更新会话最近活跃信息，避免空闲会话因自动刷新而永不超时
 func (d *UserUsecase) ValidateLoginSession(ctx context.Context, userUID, sessionID string) error {
+	if _, err := d.checkLoginSession(ctx, userUID, sessionID); err != nil {
+		return err
+	}
 	disableMultipleLogin, err := d.loginConfigurationUsecase.IsDisableMultipleLogin(ctx)
 	if err != nil {
 		return err
@@ -99,3 +238,41 @@ func (d *UserUsecase) ValidateLoginSession(ctx context.Context, userUID, session
 	}
 	return nil
 }
+
+// ListLoginSessions 列出未过期且未被吊销的登录会话，userUID 为空时列出全部用户的会话，查看他人的会话需要全局管理权限
+func (au *AuthLoginSessionUsecase) ListLoginSessions(ctx context.Context, currentUserUid, userUID string) ([]*LoginSession, error) {
+	if userUID != currentUserUid {
+		if err := au.checkCanManageOthers(ctx, currentUserUid); err != nil {
+			return nil, err
+		}
+	}
+	return au.userUsecase.repo.ListActiveLoginSessions(ctx, userUID, time.Now())
+}
+
+// RevokeLoginSession 吊销登录会话，用户只能吊销自己的会话，拥有全局管理权限的用户可吊销任意用户的会话
+func (au *AuthLoginSessionUsecase) RevokeLoginSession(ctx context.Context, currentUserUid, sessionID string) (*LoginSession, error) {
+	session, err := au.userUsecase.repo.GetLoginSession(ctx, sessionID)
+	if err != nil {
+		return nil, fmt.Errorf("get login session failed: %v", err)
+	}
+	if session.UserUID != currentUserUid {
+		if err := au.checkCanManageOthers(ctx, currentUserUid); err != nil {
+			return nil, err
+		}
+	}
+	if err := au.userUsecase.repo.RevokeLoginSession(ctx, sessionID, time.Now()); err != nil {
+		return nil, fmt.Errorf("revoke login session failed: %v", err)
+	}
+	return session, nil
+}
+
+func (au *AuthLoginSessionUsecase) checkCanManageOthers(ctx context.Context, currentUserUid string) error {
+	canGlobalOp, err := au.userUsecase.OpPermissionVerifyUsecase.CanOpGlobal(ctx, currentUserUid, false)
+	if err != nil {
+		return fmt.Errorf("check user is admin or global management permission : %v", err)
+	}
+	if !canGlobalOp {
+		return fmt.Errorf("user is not allowed to manage login sessions of other users")
+	}
+	return nil
+}
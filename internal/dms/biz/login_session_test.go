@@ -0,0 +1,97 @@
+package biz
+
+import (
+	"context"
+	"io"
+	"testing"
+	"time"
+
+	utilLog "github.com/actiontech/dms/pkg/dms-common/pkg/log"
+	"github.com/patrickmn/go-cache"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCheckLoginSessionActive(t *testing.T) {
+	userRepo := &mockUserRepo{}
+	uc := &UserUsecase{repo: userRepo, loginConfigurationUsecase: NewLoginConfigurationUsecase(utilLog.NewMyLogger(io.Discard), nil, nil)}
+	ctx := context.Background()
+
+	now := time.Now()
+	assert.NoError(t, userRepo.SaveLoginSession(ctx, &LoginSession{UID: "s1", UserUID: "u1", IP: "10.0.0.1", LastSeenAt: now, ExpiredAt: now.Add(time.Hour)}))
+	assert.NoError(t, userRepo.SaveLoginSession(ctx, &LoginSession{UID: "s2", UserUID: "u1", IP: "10.0.0.2", LastSeenAt: now, ExpiredAt: now.Add(time.Hour)}))
+
+	// 升级前签发的 token 没有会话记录
+	assert.NoError(t, uc.CheckLoginSessionActive(ctx, "u1", "", "10.0.0.1"))
+	assert.NoError(t, uc.CheckLoginSessionActive(ctx, "u1", "unknown", "10.0.0.1"))
+
+	assert.NoError(t, uc.CheckLoginSessionActive(ctx, "u1", "s1", "10.0.0.3"))
+	assert.Equal(t, "10.0.0.3", userRepo.loginSessions["s1"].IP)
+	assert.ErrorIs(t, uc.CheckLoginSessionActive(ctx, "u2", "s1", "10.0.0.3"), ErrLoginSessionRevoked)
+
+	assert.NoError(t, uc.EndLoginSession(ctx, "s1"))
+	assert.ErrorIs(t, uc.CheckLoginSessionActive(ctx, "u1", "s1", "10.0.0.3"), ErrLoginSessionRevoked)
+	assert.NoError(t, uc.CheckLoginSessionActive(ctx, "u1", "s2", "10.0.0.2"))
+
+	assert.NoError(t, uc.RevokeUserLoginSessions(ctx, "u1"))
+	assert.ErrorIs(t, uc.CheckLoginSessionActive(ctx, "u1", "s2", "10.0.0.2"), ErrLoginSessionRevoked)
+}
+
+func TestSessionPolicy(t *testing.T) {
+	loginC := &LoginConfiguration{SessionIdleTimeoutMinutes: 30, SessionMaxAgeMinutes: 720, AdminSessionIdleTimeoutMinutes: 10}
+	policy := loginC.GetSessionPolicy(false)
+	assert.Equal(t, 30*time.Minute, policy.IdleTimeout)
+	assert.Equal(t, 12*time.Hour, policy.MaxAge)
+	adminPolicy := loginC.GetSessionPolicy(true)
+	assert.Equal(t, 10*time.Minute, adminPolicy.IdleTimeout)
+	assert.Equal(t, 12*time.Hour, adminPolicy.MaxAge)
+
+	now := time.Now()
+	assert.NoError(t, policy.Check(&LoginSession{CreatedAt: now.Add(-time.Hour), LastSeenAt: now.Add(-20 * time.Minute)}, now))
+	assert.ErrorIs(t, policy.Check(&LoginSession{CreatedAt: now.Add(-time.Hour), LastSeenAt: now.Add(-31 * time.Minute)}, now), ErrLoginSessionIdleTimeout)
+	assert.ErrorIs(t, policy.Check(&LoginSession{CreatedAt: now.Add(-13 * time.Hour), LastSeenAt: now}, now), ErrLoginSessionMaxAge)
+
+	session := &LoginSession{CreatedAt: now.Add(-11 * time.Hour)}
+	assert.Equal(t, session.CreatedAt.Add(12*time.Hour), policy.ExpiredAt(session, now.Add(24*time.Hour)))
+	assert.Equal(t, now.Add(24*time.Hour), (&SessionPolicy{}).ExpiredAt(session, now.Add(24*time.Hour)))
+
+	tokenPolicy := (&LoginConfiguration{AccessTokenIdleTimeoutDays: 30, AccessTokenMaxAgeDays: 90}).GetAccessTokenPolicy()
+	assert.NoError(t, tokenPolicy.CheckExpirationDays(90))
+	assert.Error(t, tokenPolicy.CheckExpirationDays(91))
+	assert.NoError(t, tokenPolicy.Check(&AccessTokenInfo{CreatedAt: now.Add(-40 * 24 * time.Hour), LastUsedAt: now.Add(-time.Hour)}, now))
+	assert.ErrorIs(t, tokenPolicy.Check(&AccessTokenInfo{CreatedAt: now.Add(-40 * 24 * time.Hour)}, now), ErrAccessTokenIdleTimeout)
+}
+
+func TestCheckLoginSessionActiveWithPolicy(t *testing.T) {
+	userRepo := &mockUserRepo{}
+	loginConfigurationUsecase := NewLoginConfigurationUsecase(utilLog.NewMyLogger(io.Discard), nil, nil)
+	loginConfigurationUsecase.disableMultipleLoginCache.Set("login_configuration", &LoginConfiguration{SessionIdleTimeoutMinutes: 30}, cache.NoExpiration)
+	uc := &UserUsecase{repo: userRepo, loginConfigurationUsecase: loginConfigurationUsecase}
+	ctx := context.Background()
+
+	now := time.Now()
+	assert.NoError(t, userRepo.SaveLoginSession(ctx, &LoginSession{UID: "s1", UserUID: "u1", CreatedAt: now, LastSeenAt: now.Add(-time.Hour), ExpiredAt: now.Add(time.Hour)}))
+	assert.ErrorIs(t, uc.CheckLoginSessionActive(ctx, "u1", "s1", ""), ErrLoginSessionIdleTimeout)
+	// 超时的会话被吊销，之后的请求即使在空闲时长内也无法恢复
+	assert.ErrorIs(t, uc.CheckLoginSessionActive(ctx, "u1", "s1", ""), ErrLoginSessionRevoked)
+}
+
+func TestValidateLoginSessionKeepsLastSeen(t *testing.T) {
+	userRepo := &mockUserRepo{}
+	loginConfigurationUsecase := NewLoginConfigurationUsecase(utilLog.NewMyLogger(io.Discard), nil, nil)
+	loginConfigurationUsecase.disableMultipleLoginCache.Set("login_configuration", &LoginConfiguration{SessionIdleTimeoutMinutes: 30}, cache.NoExpiration)
+	loginConfigurationUsecase.disableMultipleLoginCache.Set("disable_multiple_login", false, cache.NoExpiration)
+	uc := &UserUsecase{repo: userRepo, loginConfigurationUsecase: loginConfigurationUsecase}
+	ctx := context.Background()
+
+	now := time.Now()
+	lastSeen := now.Add(-20 * time.Minute)
+	assert.NoError(t, userRepo.SaveLoginSession(ctx, &LoginSession{UID: "s1", UserUID: "u1", IP: "10.0.0.1", CreatedAt: now, LastSeenAt: lastSeen, ExpiredAt: now.Add(time.Hour)}))
+
+	// 刷新 token 不算作用户活跃，空闲会话仍会按时超时
+	assert.NoError(t, uc.ValidateLoginSession(ctx, "u1", "s1"))
+	assert.Equal(t, lastSeen, userRepo.loginSessions["s1"].LastSeenAt)
+	assert.Equal(t, "10.0.0.1", userRepo.loginSessions["s1"].IP)
+
+	userRepo.loginSessions["s1"].LastSeenAt = now.Add(-time.Hour)
+	assert.ErrorIs(t, uc.ValidateLoginSession(ctx, "u1", "s1"), ErrLoginSessionIdleTimeout)
+}
@@ -2,7 +2,9 @@ package biz
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"time"
 
 	pkgConst "github.com/actiontech/dms/internal/dms/pkg/constant"
 	pkgRand "github.com/actiontech/dms/pkg/rand"
@@ -20,6 +22,18 @@ type Member struct {
 	PlatformRoles    []string
 	RoleWithOpRanges []MemberRoleWithOpRange
 	OpPermissions    []OpPermission
+	// ExpiresAt 成员授权的到期时间，为零值表示长期有效，到期后由定时任务自动移除
+	ExpiresAt time.Time
+}
+
+var ErrMemberExpiresAtInPast = errors.New("expires at must be later than now")
+
+// checkExpiresAt 到期时间为零值表示长期有效，否则必须晚于当前时间
+func checkExpiresAt(expiresAt time.Time) error {
+	if !expiresAt.IsZero() && !expiresAt.After(time.Now()) {
+		return ErrMemberExpiresAtInPast
+	}
+	return nil
 }
 
 func (u *Member) GetUID() string {
@@ -32,7 +46,7 @@ type MemberRoleWithOpRange struct {
 	RangeUIDs   []string    // Range描述操作权限的权限范围，如涉及哪些数据源
 }
 
-func newMember(userUid, projectUid string, opPermissions []MemberRoleWithOpRange) (*Member, error) {
+func newMember(userUid, projectUid string, opPermissions []MemberRoleWithOpRange, expiresAt time.Time) (*Member, error) {
 
 	uid, err := pkgRand.GenStrUid()
 	if err != nil {
@@ -44,6 +58,7 @@ func newMember(userUid, projectUid string, opPermissions []MemberRoleWithOpRange
 		ProjectUID:       projectUid,
 		UserUID:          userUid,
 		RoleWithOpRanges: opPermissions,
+		ExpiresAt:        expiresAt,
 	}, nil
 }
 
@@ -54,6 +69,7 @@ type MemberRepo interface {
 	UpdateMember(ctx context.Context, m *Member) error
 	CheckMemberExist(ctx context.Context, memberUids []string) (exists bool, err error)
 	DelMember(ctx context.Context, memberUid string) error
+	ListExpiredMembers(ctx context.Context, now time.Time) ([]*Member, error)
 	DelRoleFromAllMembers(ctx context.Context, roleUid string) error
 	ReplaceOpPermissionsInMember(ctx context.Context, memberUid string, opPermissionUids []string) error
 }
@@ -67,6 +83,7 @@ type MemberUsecase struct {
 	opPermissionVerifyUsecase *OpPermissionVerifyUsecase
 	projectUsecase            *ProjectUsecase
 	pluginUsecase             *PluginUsecase
+	separationOfDutiesUsecase *SeparationOfDutiesUsecase
 	log                       *utilLog.Helper
 }
 
@@ -76,7 +93,8 @@ func NewMemberUsecase(log utilLog.Logger, tx TransactionGenerator, repo MemberRe
 	dbServiceUsecase *DBServiceUsecase,
 	opPermissionVerifyUsecase *OpPermissionVerifyUsecase,
 	projectUsecase *ProjectUsecase,
-	pluginUsecase *PluginUsecase) *MemberUsecase {
+	pluginUsecase *PluginUsecase,
+	separationOfDutiesUsecase *SeparationOfDutiesUsecase) *MemberUsecase {
 	return &MemberUsecase{
 		tx:                        tx,
 		repo:                      repo,
@@ -86,14 +104,19 @@ func NewMemberUsecase(log utilLog.Logger, tx TransactionGenerator, repo MemberRe
 		opPermissionVerifyUsecase: opPermissionVerifyUsecase,
 		projectUsecase:            projectUsecase,
 		pluginUsecase:             pluginUsecase,
+		separationOfDutiesUsecase: separationOfDutiesUsecase,
 		log:                       utilLog.NewHelper(log, utilLog.WithMessageKey("biz.member")),
 	}
 }
 
 func (m *MemberUsecase) CreateMember(ctx context.Context, currentUserUid string, memberUserUid string, projectUid string, isProjectAdmin bool,
-	roleAndOpRanges []MemberRoleWithOpRange, projectManagePermissions []string) (memberUid string, err error) {
+	roleAndOpRanges []MemberRoleWithOpRange, projectManagePermissions []string, expiresAt time.Time) (memberUid string, err error) {
 	// check
 	{
+		if err := checkExpiresAt(expiresAt); err != nil {
+			return "", err
+		}
+
 		// 检查项目是否归档/删除
 		if err := m.projectUsecase.isProjectActive(ctx, projectUid); err != nil {
 			return "", fmt.Errorf("create member error: %v", err)
@@ -104,7 +127,7 @@ func (m *MemberUsecase) CreateMember(ctx context.Context, currentUserUid string,
 			return "", err
 		}
 
-		if err := m.CheckRoleAndOpRanges(ctx, roleAndOpRanges); err != nil {
+		if err := m.CheckRoleAndOpRanges(ctx, projectUid, roleAndOpRanges); err != nil {
 			return "", err
 		}
 
@@ -133,7 +156,7 @@ func (m *MemberUsecase) CreateMember(ctx context.Context, currentUserUid string,
 		}
 	}
 
-	member, err := newMember(memberUserUid, projectUid, roleAndOpRanges)
+	member, err := newMember(memberUserUid, projectUid, roleAndOpRanges, expiresAt)
 	if err != nil {
 		return "", fmt.Errorf("new member failed: %v", err)
 	}
@@ -143,6 +166,10 @@ func (m *MemberUsecase) CreateMember(ctx context.Context, currentUserUid string,
 		m.FixMemberWithProjectAdmin(ctx, member, projectUid)
 	}
 
+	if err := m.separationOfDutiesUsecase.CheckMemberGrants(ctx, projectUid, memberUserUid, member.RoleWithOpRanges, projectManagePermissions); err != nil {
+		return "", err
+	}
+
 	tx := m.tx.BeginTX(ctx)
 	defer func() {
 		if err != nil {
@@ -161,6 +188,7 @@ func (m *MemberUsecase) CreateMember(ctx context.Context, currentUserUid string,
 	if err := tx.Commit(m.log); err != nil {
 		return "", fmt.Errorf("commit tx failed: %v", err)
 	}
+	m.opPermissionVerifyUsecase.InvalidateProjectPermissions(ctx, projectUid)
 	return member.GetUID(), nil
 
 }
@@ -198,7 +226,7 @@ func (m *MemberUsecase) AddUserToProjectAdminMember(ctx context.Context, userUid
 		}
 	}
 
-	member, err := newMember(userUid, projectUid, []MemberRoleWithOpRange{m.GetProjectAdminRoleWithOpRange(projectUid)})
+	member, err := newMember(userUid, projectUid, []MemberRoleWithOpRange{m.GetProjectAdminRoleWithOpRange(projectUid)}, time.Time{})
 	if err != nil {
 		return "", fmt.Errorf("new member failed: %v", err)
 	}
@@ -206,26 +234,63 @@ func (m *MemberUsecase) AddUserToProjectAdminMember(ctx context.Context, userUid
 	if err := m.repo.SaveMember(ctx, member); err != nil {
 		return "", fmt.Errorf("save member failed: %v", err)
 	}
+	m.opPermissionVerifyUsecase.InvalidateProjectPermissions(ctx, projectUid)
 
 	return member.GetUID(), nil
 
 }
 
 // CheckRoleAndOpRanges 检查角色和操作权限范围是否合法
-func (m *MemberUsecase) CheckRoleAndOpRanges(ctx context.Context, roleAndOpRanges []MemberRoleWithOpRange) error {
+func (m *MemberUsecase) CheckRoleAndOpRanges(ctx context.Context, projectUid string, roleAndOpRanges []MemberRoleWithOpRange) error {
 	for _, r := range roleAndOpRanges {
 		// 检查角色存在
 		role, err := m.roleUsecase.GetRole(ctx, r.RoleUID)
 		if err != nil {
 			return fmt.Errorf("get role exist failed: %v", err)
 		}
+		if err := role.usableInProject(projectUid); err != nil {
+			return err
+		}
 
 		// 获取角色的操作权限
 		opPermissions, err := m.roleUsecase.GetOpPermissions(ctx, role.UID)
 		if err != nil {
 			return fmt.Errorf("get op permissions failed: %v", err)
 		}
+		// 对象级别的范围是对数据源权限的收窄，需校验范围格式并检查所属数据源存在
+		if r.OpRangeType.IsDBObject() {
+			objectRanges, err := ParseDBObjectRanges(r.OpRangeType, r.RangeUIDs)
+			if err != nil {
+				return fmt.Errorf("parse object ranges failed: %v", err)
+			}
+			dbServiceUids := make([]string, 0, len(objectRanges))
+			seen := make(map[string]struct{}, len(objectRanges))
+			for _, objectRange := range objectRanges {
+				if _, ok := seen[objectRange.DBServiceUID]; ok {
+					continue
+				}
+				seen[objectRange.DBServiceUID] = struct{}{}
+				dbServiceUids = append(dbServiceUids, objectRange.DBServiceUID)
+			}
+			if exist, err := m.dbServiceUsecase.CheckDBServiceExist(ctx, dbServiceUids); err != nil {
+				return fmt.Errorf("check db service exist failed: %v", err)
+			} else if !exist {
+				return fmt.Errorf("db service not exist")
+			}
+		}
+		// 数据源选择器在校验时解析为数据源，这里只检查选择器格式
+		if r.OpRangeType == OpRangeTypeDBServiceSelector {
+			if _, err := ParseDBServiceSelectors(r.RangeUIDs); err != nil {
+				return fmt.Errorf("parse db service selectors failed: %v", err)
+			}
+		}
 		for _, op := range opPermissions {
+			if r.OpRangeType.IsDBObject() || r.OpRangeType == OpRangeTypeDBServiceSelector {
+				if op.RangeType != OpRangeTypeDBService {
+					return fmt.Errorf("range type not match, op permission range type: %v, role range type: %v", op.RangeType, r.OpRangeType)
+				}
+				continue
+			}
 			// 检查操作权限与指定的范围类型匹配
 			if op.RangeType != r.OpRangeType {
 				return fmt.Errorf("range type not match, op permission range type: %v, role range type: %v", op.RangeType, r.OpRangeType)
@@ -309,15 +374,19 @@ func (m *MemberUsecase) CheckMemberExist(ctx context.Context, memberUids []strin
 }
 
 func (m *MemberUsecase) UpdateMember(ctx context.Context, currentUserUid, updateMemberUid, projectUid string, isProjectAdmin bool,
-	roleAndOpRanges []MemberRoleWithOpRange, projectManagePermissions []string) error {
+	roleAndOpRanges []MemberRoleWithOpRange, projectManagePermissions []string, expiresAt time.Time) error {
 	// check
 	{
+		if err := checkExpiresAt(expiresAt); err != nil {
+			return err
+		}
+
 		// 检查项目是否归档/删除
 		if err := m.projectUsecase.isProjectActive(ctx, projectUid); err != nil {
 			return fmt.Errorf("update member error: %v", err)
 		}
 
-		if err := m.CheckRoleAndOpRanges(ctx, roleAndOpRanges); err != nil {
+		if err := m.CheckRoleAndOpRanges(ctx, projectUid, roleAndOpRanges); err != nil {
 			return err
 		}
 	}
@@ -327,12 +396,17 @@ func (m *MemberUsecase) UpdateMember(ctx context.Context, currentUserUid, update
 		return fmt.Errorf("get member failed: %v", err)
 	}
 	member.RoleWithOpRanges = roleAndOpRanges
+	member.ExpiresAt = expiresAt
 
 	// 如果是项目管理员，则自动添加内置的项目管理员角色
 	if isProjectAdmin {
 		m.FixMemberWithProjectAdmin(ctx, member, projectUid)
 	}
 
+	if err := m.separationOfDutiesUsecase.CheckMemberGrants(ctx, projectUid, member.UserUID, member.RoleWithOpRanges, projectManagePermissions); err != nil {
+		return err
+	}
+
 	if err := m.repo.ReplaceOpPermissionsInMember(ctx, updateMemberUid, projectManagePermissions); err != nil {
 		return fmt.Errorf("replace op permissions in member failed: %v", err)
 	}
@@ -351,16 +425,17 @@ func (m *MemberUsecase) UpdateMember(ctx context.Context, currentUserUid, update
 	if err := tx.Commit(m.log); err != nil {
 		return fmt.Errorf("commit tx failed: %v", err)
 	}
+	m.opPermissionVerifyUsecase.InvalidateProjectPermissions(ctx, projectUid)
 	return nil
 }
 
 func (m *MemberUsecase) DelMember(ctx context.Context, currentUserUid, memberUid string) (err error) {
+	member, err := m.GetMember(ctx, memberUid)
+	if err != nil {
+		return fmt.Errorf("get member failed: %v", err)
+	}
 	// check
 	{
-		member, err := m.GetMember(ctx, memberUid)
-		if err != nil {
-			return fmt.Errorf("get member failed: %v", err)
-		}
 		// 检查项目是否归档/删除
 		if err := m.projectUsecase.isProjectActive(ctx, member.ProjectUID); err != nil {
 			return fmt.Errorf("delete member error: %v", err)
@@ -381,6 +456,7 @@ func (m *MemberUsecase) DelMember(ctx context.Context, currentUserUid, memberUid
 	if err := tx.Commit(m.log); err != nil {
 		return fmt.Errorf("commit tx failed: %v", err)
 	}
+	m.opPermissionVerifyUsecase.InvalidateProjectPermissions(ctx, member.ProjectUID)
 
 	return nil
 }
@@ -0,0 +1,371 @@
+package biz
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	pkgConst "github.com/actiontech/dms/internal/dms/pkg/constant"
+	"github.com/actiontech/dms/internal/pkg/locale"
+	utilLog "github.com/actiontech/dms/pkg/dms-common/pkg/log"
+	pkgRand "github.com/actiontech/dms/pkg/rand"
+
+	"github.com/nicksnyder/go-i18n/v2/i18n"
+)
+
+type MemberAccessRequestStatus string
+
+const (
+	MemberAccessRequestStatusPending  MemberAccessRequestStatus = "pending"
+	MemberAccessRequestStatusApproved MemberAccessRequestStatus = "approved"
+	MemberAccessRequestStatusRejected MemberAccessRequestStatus = "rejected"
+)
+
+const (
+	// MaxMemberAccessRequestDurationHours 临时授权最长 30 天，更长的授权应直接添加成员
+	MaxMemberAccessRequestDurationHours = 30 * 24
+
+	memberAccessRequestGroupNamePrefix = "access_request_"
+
+	expiredGrantOperationUser = "sys"
+)
+
+var (
+	ErrMemberAccessRequestNotPending   = errors.New("access request has already been processed")
+	ErrMemberAccessRequestDuration     = fmt.Errorf("duration hours must be between 1 and %d", MaxMemberAccessRequestDurationHours)
+	ErrMemberAccessRequestSelfApproval = errors.New("access request can not be approved by the requester")
+)
+
+// MemberAccessRequest 用户自助申请的项目临时权限，审批通过后以带到期时间的成员组授予
+type MemberAccessRequest struct {
+	UID              string
+	ProjectUID       string
+	UserUID          string
+	RoleWithOpRanges []MemberRoleWithOpRange
+	DurationHours    uint
+	Reason           string
+	Status           MemberAccessRequestStatus
+	ReviewerUID      string
+	ReviewComment    string
+	ReviewedAt       time.Time
+	// 审批通过后创建的成员组
+	MemberGroupUID string
+	CreatedAt      time.Time
+}
+
+type ListMemberAccessRequestsOption struct {
+	ProjectUID     string
+	FilterUserUID  string
+	FilterByStatus MemberAccessRequestStatus
+}
+
+type MemberAccessRequestRepo interface {
+	SaveMemberAccessRequest(ctx context.Context, r *MemberAccessRequest) error
+	UpdateMemberAccessRequest(ctx context.Context, r *MemberAccessRequest) error
+	GetMemberAccessRequest(ctx context.Context, uid string) (*MemberAccessRequest, error)
+	ListMemberAccessRequests(ctx context.Context, opt *ListMemberAccessRequestsOption) ([]*MemberAccessRequest, error)
+}
+
+type MemberAccessRequestUsecase struct {
+	repo                      MemberAccessRequestRepo
+	userUsecase               *UserUsecase
+	memberUsecase             *MemberUsecase
+	memberGroupUsecase        *MemberGroupUsecase
+	projectUsecase            *ProjectUsecase
+	opPermissionVerifyUsecase *OpPermissionVerifyUsecase
+	operationRecordUsecase    *OperationRecordUsecase
+	clusterUsecase            *ClusterUsecase
+	log                       *utilLog.Helper
+}
+
+func NewMemberAccessRequestUsecase(log utilLog.Logger, repo MemberAccessRequestRepo,
+	userUsecase *UserUsecase,
+	memberUsecase *MemberUsecase,
+	memberGroupUsecase *MemberGroupUsecase,
+	projectUsecase *ProjectUsecase,
+	opPermissionVerifyUsecase *OpPermissionVerifyUsecase,
+	operationRecordUsecase *OperationRecordUsecase,
+	clusterUsecase *ClusterUsecase) *MemberAccessRequestUsecase {
+	return &MemberAccessRequestUsecase{
+		repo:                      repo,
+		userUsecase:               userUsecase,
+		memberUsecase:             memberUsecase,
+		memberGroupUsecase:        memberGroupUsecase,
+		projectUsecase:            projectUsecase,
+		opPermissionVerifyUsecase: opPermissionVerifyUsecase,
+		operationRecordUsecase:    operationRecordUsecase,
+		clusterUsecase:            clusterUsecase,
+		log:                       utilLog.NewHelper(log, utilLog.WithMessageKey("biz.member_access_request")),
+	}
+}
+
+type CreateMemberAccessRequestArgs struct {
+	ProjectUID       string
+	RoleWithOpRanges []MemberRoleWithOpRange
+	DurationHours    uint
+	Reason           string
+}
+
+func (u *MemberAccessRequestUsecase) CreateMemberAccessRequest(ctx context.Context, currentUserUid string, args *CreateMemberAccessRequestArgs) (string, error) {
+	if args.DurationHours == 0 || args.DurationHours > MaxMemberAccessRequestDurationHours {
+		return "", ErrMemberAccessRequestDuration
+	}
+	if len(args.RoleWithOpRanges) == 0 {
+		return "", fmt.Errorf("at least one role is required")
+	}
+	if err := u.projectUsecase.isProjectActive(ctx, args.ProjectUID); err != nil {
+		return "", fmt.Errorf("create access request error: %v", err)
+	}
+	if err := u.userUsecase.EnsureUserEligibleForProjectMembership(ctx, currentUserUid); err != nil {
+		return "", err
+	}
+	if err := u.memberUsecase.CheckRoleAndOpRanges(ctx, args.ProjectUID, args.RoleWithOpRanges); err != nil {
+		return "", err
+	}
+
+	uid, err := pkgRand.GenStrUid()
+	if err != nil {
+		return "", err
+	}
+	req := &MemberAccessRequest{
+		UID:              uid,
+		ProjectUID:       args.ProjectUID,
+		UserUID:          currentUserUid,
+		RoleWithOpRanges: args.RoleWithOpRanges,
+		DurationHours:    args.DurationHours,
+		Reason:           args.Reason,
+		Status:           MemberAccessRequestStatusPending,
+		CreatedAt:        time.Now(),
+	}
+	if err := u.repo.SaveMemberAccessRequest(ctx, req); err != nil {
+		return "", fmt.Errorf("save access request failed: %v", err)
+	}
+
+	u.notifyReviewers(ctx, req)
+	return uid, nil
+}
+
+func (u *MemberAccessRequestUsecase) canReview(ctx context.Context, currentUserUid, projectUid string) (bool, error) {
+	return u.opPermissionVerifyUsecase.HasManagePermission(ctx, currentUserUid, projectUid, pkgConst.UIdOfOpPermissionManageMember)
+}
+
+// ListMemberAccessRequests 拥有成员管理权限的用户可查看项目内的全部申请，其他用户只能查看自己的申请
+func (u *MemberAccessRequestUsecase) ListMemberAccessRequests(ctx context.Context, currentUserUid string, opt *ListMemberAccessRequestsOption) ([]*MemberAccessRequest, error) {
+	canReview, err := u.canReview(ctx, currentUserUid, opt.ProjectUID)
+	if err != nil {
+		return nil, fmt.Errorf("check user has permission manage member: %v", err)
+	}
+	if !canReview {
+		opt.FilterUserUID = currentUserUid
+	}
+	return u.repo.ListMemberAccessRequests(ctx, opt)
+}
+
+func (u *MemberAccessRequestUsecase) getPendingRequestForReview(ctx context.Context, currentUserUid, projectUid, uid string) (*MemberAccessRequest, error) {
+	req, err := u.repo.GetMemberAccessRequest(ctx, uid)
+	if err != nil {
+		return nil, fmt.Errorf("get access request failed: %w", err)
+	}
+	if req.ProjectUID != projectUid {
+		return nil, fmt.Errorf("access request %s does not belong to project %s", uid, projectUid)
+	}
+	canReview, err := u.canReview(ctx, currentUserUid, req.ProjectUID)
+	if err != nil {
+		return nil, fmt.Errorf("check user has permission manage member: %v", err)
+	}
+	if !canReview {
+		return nil, fmt.Errorf("no permission to review access request")
+	}
+	if req.Status != MemberAccessRequestStatusPending {
+		return nil, ErrMemberAccessRequestNotPending
+	}
+	return req, nil
+}
+
+// ApproveMemberAccessRequest 审批通过后为申请人创建带到期时间的成员组，不影响申请人已有的成员授权；申请人不能审批自己的申请
+func (u *MemberAccessRequestUsecase) ApproveMemberAccessRequest(ctx context.Context, currentUserUid, projectUid, uid, comment string) (*MemberAccessRequest, error) {
+	req, err := u.getPendingRequestForReview(ctx, currentUserUid, projectUid, uid)
+	if err != nil {
+		return nil, err
+	}
+	if req.UserUID == currentUserUid {
+		return nil, ErrMemberAccessRequestSelfApproval
+	}
+
+	now := time.Now()
+	memberGroupUid, err := u.memberGroupUsecase.CreateMemberGroup(ctx, currentUserUid, &MemberGroup{
+		Name:             memberAccessRequestGroupNamePrefix + req.UID,
+		ProjectUID:       req.ProjectUID,
+		UserUids:         []string{req.UserUID},
+		RoleWithOpRanges: req.RoleWithOpRanges,
+		ExpiresAt:        now.Add(time.Duration(req.DurationHours) * time.Hour),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("grant access request failed: %w", err)
+	}
+
+	req.Status = MemberAccessRequestStatusApproved
+	req.ReviewerUID = currentUserUid
+	req.ReviewComment = comment
+	req.ReviewedAt = now
+	req.MemberGroupUID = memberGroupUid
+	if err := u.repo.UpdateMemberAccessRequest(ctx, req); err != nil {
+		return nil, fmt.Errorf("update access request failed: %v", err)
+	}
+
+	u.notifyRequester(ctx, req)
+	return req, nil
+}
+
+func (u *MemberAccessRequestUsecase) RejectMemberAccessRequest(ctx context.Context, currentUserUid, projectUid, uid, comment string) (*MemberAccessRequest, error) {
+	req, err := u.getPendingRequestForReview(ctx, currentUserUid, projectUid, uid)
+	if err != nil {
+		return nil, err
+	}
+
+	req.Status = MemberAccessRequestStatusRejected
+	req.ReviewerUID = currentUserUid
+	req.ReviewComment = comment
+	req.ReviewedAt = time.Now()
+	if err := u.repo.UpdateMemberAccessRequest(ctx, req); err != nil {
+		return nil, fmt.Errorf("update access request failed: %v", err)
+	}
+
+	u.notifyRequester(ctx, req)
+	return req, nil
+}
+
+// notifyReviewers 通知项目内拥有成员管理权限的用户审批，通知失败不影响申请的创建
+func (u *MemberAccessRequestUsecase) notifyReviewers(ctx context.Context, req *MemberAccessRequest) {
+	project, err := u.projectUsecase.GetProject(ctx, req.ProjectUID)
+	if err != nil {
+		u.log.Errorf("get project %s failed: %v", req.ProjectUID, err)
+		return
+	}
+	requester, err := u.userUsecase.GetUser(ctx, req.UserUID)
+	if err != nil {
+		u.log.Errorf("get user %s failed: %v", req.UserUID, err)
+		return
+	}
+	items, err := u.opPermissionVerifyUsecase.ListUsersInProject(ctx, req.ProjectUID)
+	if err != nil {
+		u.log.Errorf("list users in project %s failed: %v", req.ProjectUID, err)
+		return
+	}
+	reviewers := make([]*User, 0)
+	for _, item := range items {
+		if item.UserUid == req.UserUID {
+			continue
+		}
+		canReview, err := u.canReview(ctx, item.UserUid, req.ProjectUID)
+		if err != nil || !canReview {
+			continue
+		}
+		reviewer, err := u.userUsecase.GetUser(ctx, item.UserUid)
+		if err != nil {
+			continue
+		}
+		reviewers = append(reviewers, reviewer)
+	}
+	notifyUsersI18n(ctx, u.log, reviewers, locale.NotifyMemberAccessRequestSubject,
+		locale.NotifyMemberAccessRequestBody, requester.Name, project.Name, req.DurationHours, req.Reason)
+}
+
+func (u *MemberAccessRequestUsecase) notifyRequester(ctx context.Context, req *MemberAccessRequest) {
+	project, err := u.projectUsecase.GetProject(ctx, req.ProjectUID)
+	if err != nil {
+		u.log.Errorf("get project %s failed: %v", req.ProjectUID, err)
+		return
+	}
+	requester, err := u.userUsecase.GetUser(ctx, req.UserUID)
+	if err != nil {
+		u.log.Errorf("get user %s failed: %v", req.UserUID, err)
+		return
+	}
+	subject, body := locale.NotifyMemberAccessRequestRejectedSubject, locale.NotifyMemberAccessRequestRejectedBody
+	if req.Status == MemberAccessRequestStatusApproved {
+		subject, body = locale.NotifyMemberAccessRequestApprovedSubject, locale.NotifyMemberAccessRequestApprovedBody
+	}
+	notifyUsersI18n(ctx, u.log, []*User{requester}, subject, body, project.Name, req.DurationHours, req.ReviewComment)
+}
+
+// notifyUsersI18n 按用户语言发送通知
+func notifyUsersI18n(ctx context.Context, log *utilLog.Helper, users []*User, subject, body *i18n.Message, args ...any) {
+	lang2Users := make(map[string][]*User)
+	for _, user := range users {
+		lang2Users[user.Language] = append(lang2Users[user.Language], user)
+	}
+	for lang, langUsers := range lang2Users {
+		langTag := locale.Bundle.MatchLangTag(lang)
+		subjectStr := locale.Bundle.LocalizeMsgByLang(langTag, subject)
+		bodyStr := fmt.Sprintf(locale.Bundle.LocalizeMsgByLang(langTag, body), args...)
+		for _, n := range Notifiers {
+			if err := n.Notify(ctx, subjectStr, bodyStr, langUsers); err != nil {
+				log.Errorf("send notification %s failed: %v", subject.ID, err)
+			}
+		}
+	}
+}
+
+// RevokeExpiredGrants 定时移除已到期的成员及成员组，并记录操作日志，集群模式下仅由主节点执行
+func (u *MemberAccessRequestUsecase) RevokeExpiredGrants() {
+	if u.clusterUsecase.IsClusterMode() && !u.clusterUsecase.IsLeader() {
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+	now := time.Now()
+
+	members, err := u.memberUsecase.repo.ListExpiredMembers(ctx, now)
+	if err != nil {
+		u.log.Errorf("list expired members failed: %v", err)
+	}
+	for _, member := range members {
+		// 归档项目的到期成员同样需要移除，因此不经过 DelMember 的项目状态检查
+		if err := u.memberUsecase.repo.DelMember(ctx, member.UID); err != nil {
+			u.log.Errorf("revoke expired member %s failed: %v", member.UID, err)
+			continue
+		}
+		u.opPermissionVerifyUsecase.InvalidateProjectPermissions(ctx, member.ProjectUID)
+		userName := member.UserUID
+		if user, err := u.userUsecase.GetUser(ctx, member.UserUID); err == nil {
+			userName = user.Name
+		}
+		u.saveExpiredGrantRecord(ctx, member.ProjectUID, "expire_member", locale.OpRecordMemberExpiredWithName, userName)
+	}
+
+	memberGroups, err := u.memberGroupUsecase.repo.ListExpiredMemberGroups(ctx, now)
+	if err != nil {
+		u.log.Errorf("list expired member groups failed: %v", err)
+	}
+	for _, mg := range memberGroups {
+		if err := u.memberGroupUsecase.repo.DeleteMemberGroup(ctx, mg.UID); err != nil {
+			u.log.Errorf("revoke expired member group %s failed: %v", mg.UID, err)
+			continue
+		}
+		u.opPermissionVerifyUsecase.InvalidateProjectPermissions(ctx, mg.ProjectUID)
+		if err := u.memberGroupUsecase.pluginUsecase.DelMemberGroupAfterHandle(ctx, mg.UID); err != nil {
+			u.log.Errorf("handle deleted member group %s failed: %v", mg.UID, err)
+		}
+		u.saveExpiredGrantRecord(ctx, mg.ProjectUID, "expire_member_group", locale.OpRecordMemberGroupExpiredWithName, mg.Name)
+	}
+}
+
+func (u *MemberAccessRequestUsecase) saveExpiredGrantRecord(ctx context.Context, projectUid, action string, content *i18n.Message, name string) {
+	projectName := ""
+	if project, err := u.projectUsecase.GetProject(ctx, projectUid); err == nil {
+		projectName = project.Name
+	}
+	if err := u.operationRecordUsecase.SaveOperationRecord(ctx, &OperationRecord{
+		OperationTime:        time.Now(),
+		OperationUserName:    expiredGrantOperationUser,
+		OperationTypeName:    "member",
+		OperationAction:      action,
+		OperationProjectName: projectName,
+		OperationStatus:      "succeeded",
+		OperationI18nContent: locale.Bundle.LocalizeAllWithArgs(content, name),
+	}); err != nil {
+		u.log.Errorf("save operation record of %s failed: %v", action, err)
+	}
+}
@@ -0,0 +1,113 @@
+package biz
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	pkgConst "github.com/actiontech/dms/internal/dms/pkg/constant"
+	"github.com/actiontech/dms/internal/pkg/locale"
+	"github.com/actiontech/dms/pkg/dms-common/i18nPkg"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCheckExpiresAt(t *testing.T) {
+	assert.NoError(t, checkExpiresAt(time.Time{}))
+	assert.NoError(t, checkExpiresAt(time.Now().Add(time.Hour)))
+	assert.ErrorIs(t, checkExpiresAt(time.Now().Add(-time.Minute)), ErrMemberExpiresAtInPast)
+}
+
+func TestCreateMemberAccessRequestDuration(t *testing.T) {
+	u := &MemberAccessRequestUsecase{}
+	ctx := context.Background()
+	roles := []MemberRoleWithOpRange{{RoleUID: "1", OpRangeType: OpRangeTypeDBService, RangeUIDs: []string{"1"}}}
+
+	_, err := u.CreateMemberAccessRequest(ctx, "u1", &CreateMemberAccessRequestArgs{ProjectUID: "p1", RoleWithOpRanges: roles})
+	assert.ErrorIs(t, err, ErrMemberAccessRequestDuration)
+	_, err = u.CreateMemberAccessRequest(ctx, "u1", &CreateMemberAccessRequestArgs{ProjectUID: "p1", RoleWithOpRanges: roles, DurationHours: MaxMemberAccessRequestDurationHours + 1})
+	assert.ErrorIs(t, err, ErrMemberAccessRequestDuration)
+	_, err = u.CreateMemberAccessRequest(ctx, "u1", &CreateMemberAccessRequestArgs{ProjectUID: "p1", DurationHours: 4})
+	assert.Error(t, err)
+}
+
+type mockMemberAccessRequestRepo struct {
+	MemberAccessRequestRepo
+	requests map[string]*MemberAccessRequest
+}
+
+func (m *mockMemberAccessRequestRepo) GetMemberAccessRequest(_ context.Context, uid string) (*MemberAccessRequest, error) {
+	return m.requests[uid], nil
+}
+
+func TestApproveMemberAccessRequestBySelf(t *testing.T) {
+	repo := &mockMemberAccessRequestRepo{requests: map[string]*MemberAccessRequest{
+		"r1": {UID: "r1", ProjectUID: "p1", UserUID: "u1", Status: MemberAccessRequestStatusPending},
+	}}
+	opRepo := &mockOpPermissionVerifyRepo{projectPermissions: map[string]map[string]map[string]bool{
+		"u1": {"p1": {pkgConst.UIdOfOpPermissionManageMember: true}},
+	}}
+	u := &MemberAccessRequestUsecase{
+		repo:                      repo,
+		opPermissionVerifyUsecase: newTestOpPermissionVerifyUsecase(&mockUserRepo{users: map[string]*User{"u1": {UID: "u1"}}}, opRepo),
+	}
+
+	// 拥有成员管理权限的申请人也不能审批自己的申请
+	_, err := u.ApproveMemberAccessRequest(context.Background(), "u1", "p1", "r1", "")
+	assert.ErrorIs(t, err, ErrMemberAccessRequestSelfApproval)
+	assert.Equal(t, MemberAccessRequestStatusPending, repo.requests["r1"].Status)
+}
+
+type mockExpiredMemberRepo struct {
+	MemberRepo
+	expired []*Member
+	deleted []string
+}
+
+func (m *mockExpiredMemberRepo) ListExpiredMembers(context.Context, time.Time) ([]*Member, error) {
+	return m.expired, nil
+}
+
+func (m *mockExpiredMemberRepo) DelMember(_ context.Context, memberUid string) error {
+	m.deleted = append(m.deleted, memberUid)
+	return nil
+}
+
+type mockExpiredMemberGroupRepo struct {
+	MemberGroupRepo
+}
+
+func (m *mockExpiredMemberGroupRepo) ListExpiredMemberGroups(context.Context, time.Time) ([]*MemberGroup, error) {
+	return nil, nil
+}
+
+type mockOperationRecordRepo struct {
+	OperationRecordRepo
+	records []*OperationRecord
+}
+
+func (m *mockOperationRecordRepo) SaveOperationRecord(_ context.Context, record *OperationRecord) error {
+	m.records = append(m.records, record)
+	return nil
+}
+
+func TestRevokeExpiredGrantsWithoutCluster(t *testing.T) {
+	locale.MustInit(&i18nPkg.StdLogger{})
+	memberRepo := &mockExpiredMemberRepo{expired: []*Member{{UID: "m1", ProjectUID: "p1", UserUID: "u1"}}}
+	recordRepo := &mockOperationRecordRepo{}
+	u := NewMemberAccessRequestUsecase(&noopLogger{}, nil,
+		&UserUsecase{repo: &mockUserRepo{users: map[string]*User{"u1": {UID: "u1", Name: "alice"}}}},
+		&MemberUsecase{repo: memberRepo},
+		&MemberGroupUsecase{repo: &mockExpiredMemberGroupRepo{}},
+		&ProjectUsecase{repo: &mockDeliveryProjectRepo{}},
+		newTestOpPermissionVerifyUsecase(&mockUserRepo{}, &mockOpPermissionVerifyRepo{}),
+		&OperationRecordUsecase{repo: recordRepo},
+		NewClusterUsecase(&noopLogger{}, nil, nil))
+
+	// 非集群部署没有主节点，到期授权同样需要回收
+	u.RevokeExpiredGrants()
+	assert.Equal(t, []string{"m1"}, memberRepo.deleted)
+	if assert.Len(t, recordRepo.records, 1) {
+		assert.Equal(t, "expire_member", recordRepo.records[0].OperationAction)
+		assert.Equal(t, "project_1", recordRepo.records[0].OperationProjectName)
+	}
+}
@@ -3,6 +3,7 @@ package biz
 import (
 	"context"
 	"fmt"
+	"time"
 
 	pkgConst "github.com/actiontech/dms/internal/dms/pkg/constant"
 	utilLog "github.com/actiontech/dms/pkg/dms-common/pkg/log"
@@ -21,6 +22,11 @@ type MemberGroup struct {
 	RoleWithOpRanges []MemberRoleWithOpRange
 	OpPermissions    []OpPermission
 	ProjectManagePermissions []string
+	// ExpiresAt 成员组授权的到期时间，为零值表示长期有效，到期后由定时任务自动移除
+	ExpiresAt time.Time
+	// UserGroupUID 绑定的全局用户组，绑定后成员组的用户始终与用户组保持一致，UserUids 须为空
+	UserGroupUID  string
+	UserGroupName string
 }
 
 type MemberGroupRepo interface {
@@ -29,6 +35,7 @@ type MemberGroupRepo interface {
 	CreateMemberGroup(ctx context.Context, mg *MemberGroup) error
 	UpdateMemberGroup(ctx context.Context, mg *MemberGroup) error
 	DeleteMemberGroup(ctx context.Context, memberGroupId string) error
+	ListExpiredMemberGroups(ctx context.Context, now time.Time) ([]*MemberGroup, error)
 	GetMemberGroupsByUserIDAndProjectID(ctx context.Context, userID, projectID string) ([]*MemberGroup, error)
 	ReplaceOpPermissionsInMemberGroup(ctx context.Context, memberUid string, opPermissionUids []string) error
 }
@@ -43,6 +50,7 @@ type MemberGroupUsecase struct {
 	projectUsecase            *ProjectUsecase
 	memberUsecase             *MemberUsecase
 	pluginUsecase             *PluginUsecase
+	separationOfDutiesUsecase *SeparationOfDutiesUsecase
 	log                       *utilLog.Helper
 }
 
@@ -53,7 +61,8 @@ func NewMemberGroupUsecase(log utilLog.Logger, tx TransactionGenerator, repo Mem
 	opPermissionVerifyUsecase *OpPermissionVerifyUsecase,
 	projectUsecase *ProjectUsecase,
 	memberUsecase *MemberUsecase,
-	pluginUsecase *PluginUsecase) *MemberGroupUsecase {
+	pluginUsecase *PluginUsecase,
+	separationOfDutiesUsecase *SeparationOfDutiesUsecase) *MemberGroupUsecase {
 	return &MemberGroupUsecase{
 		tx:                        tx,
 		repo:                      repo,
@@ -64,6 +73,7 @@ func NewMemberGroupUsecase(log utilLog.Logger, tx TransactionGenerator, repo Mem
 		projectUsecase:            projectUsecase,
 		pluginUsecase:             pluginUsecase,
 		memberUsecase:             memberUsecase,
+		separationOfDutiesUsecase: separationOfDutiesUsecase,
 		log:                       utilLog.NewHelper(log, utilLog.WithMessageKey("biz.member_group")),
 	}
 }
@@ -128,22 +138,45 @@ func (m *MemberGroupUsecase) CreateMemberGroup(ctx context.Context, currentUserU
 		})
 	}
 
+	userUids, err := m.effectiveUserUids(ctx, mg)
+	if err != nil {
+		return "", err
+	}
+	if err := m.separationOfDutiesUsecase.CheckMemberGroupGrants(ctx, mg.ProjectUID, "", userUids, mg.RoleWithOpRanges, mg.ProjectManagePermissions); err != nil {
+		return "", err
+	}
+
 	if err = m.repo.CreateMemberGroup(ctx, mg); err != nil {
 		return "", fmt.Errorf("save member group failed: %v", err)
 	}
 	if err = m.repo.ReplaceOpPermissionsInMemberGroup(ctx, uid, mg.ProjectManagePermissions); err != nil {
 		return "", fmt.Errorf("replace op permissions in member group failed: %v", err)
 	}
+	m.opPermissionVerifyUsecase.InvalidateProjectPermissions(ctx, mg.ProjectUID)
 
 	return uid, nil
 
 }
 
 func (m *MemberGroupUsecase) checkMemberGroupBeforeUpsert(ctx context.Context, currentUserUid string, mg *MemberGroup) error {
+	if err := checkExpiresAt(mg.ExpiresAt); err != nil {
+		return err
+	}
 	// 检查项目是否归档/删除
 	if err := m.projectUsecase.isProjectActive(ctx, mg.ProjectUID); err != nil {
 		return fmt.Errorf("create member error: %v", err)
 	}
+	if mg.UserGroupUID != "" {
+		if len(mg.UserUids) > 0 {
+			return fmt.Errorf("member group bound to a user group cannot have users")
+		}
+		if exist, err := m.userUsecase.userGroupRepo.CheckUserGroupExist(ctx, []string{mg.UserGroupUID}); err != nil {
+			return fmt.Errorf("check user group exist failed: %v", err)
+		} else if !exist {
+			return fmt.Errorf("user group not exist")
+		}
+		return m.memberUsecase.CheckRoleAndOpRanges(ctx, mg.ProjectUID, mg.RoleWithOpRanges)
+	}
 	// 检查成员组成员用户存在
 	if exist, err := m.userUsecase.CheckUserExist(ctx, mg.UserUids); err != nil {
 		return fmt.Errorf("check user exist failed: %v", err)
@@ -151,7 +184,7 @@ func (m *MemberGroupUsecase) checkMemberGroupBeforeUpsert(ctx context.Context, c
 		return fmt.Errorf("user not exist")
 	}
 
-	return m.memberUsecase.CheckRoleAndOpRanges(ctx, mg.RoleWithOpRanges)
+	return m.memberUsecase.CheckRoleAndOpRanges(ctx, mg.ProjectUID, mg.RoleWithOpRanges)
 }
 
 func (m *MemberGroupUsecase) UpdateMemberGroup(ctx context.Context, currentUserUid string, mg *MemberGroup) error {
@@ -176,6 +209,15 @@ func (m *MemberGroupUsecase) UpdateMemberGroup(ctx context.Context, currentUserU
 	mg.UID = memberGroup.UID
 	mg.Name = memberGroup.Name
 	mg.CreatedAt = memberGroup.CreatedAt
+
+	userUids, err := m.effectiveUserUids(ctx, mg)
+	if err != nil {
+		return err
+	}
+	if err := m.separationOfDutiesUsecase.CheckMemberGroupGrants(ctx, mg.ProjectUID, mg.UID, userUids, mg.RoleWithOpRanges, mg.ProjectManagePermissions); err != nil {
+		return err
+	}
+
 	tx := m.tx.BeginTX(ctx)
 	defer func() {
 		if err != nil {
@@ -189,16 +231,40 @@ func (m *MemberGroupUsecase) UpdateMemberGroup(ctx context.Context, currentUserU
 		return fmt.Errorf("update member group failed: %v", err)
 	}
 	// 调用其他服务对成员组进行更新后处理
-	if err := m.pluginUsecase.UpdateMemberGroupAfterHandle(tx, mg.UID, mg.UserUids); err != nil {
+	if err := m.pluginUsecase.UpdateMemberGroupAfterHandle(tx, mg.UID, userUids); err != nil {
 		return err
 	}
 
 	if err := tx.Commit(m.log); err != nil {
 		return fmt.Errorf("commit tx failed: %v", err)
 	}
+	m.opPermissionVerifyUsecase.InvalidateProjectPermissions(ctx, mg.ProjectUID)
 	return nil
 }
 
+// effectiveUserUids 返回成员组实际生效的用户，绑定用户组时为用户组当前的用户
+func (m *MemberGroupUsecase) effectiveUserUids(ctx context.Context, mg *MemberGroup) ([]string, error) {
+	if mg.UserGroupUID == "" {
+		return mg.UserUids, nil
+	}
+	userGroup, err := m.userUsecase.userGroupRepo.GetUserGroup(ctx, mg.UserGroupUID)
+	if err != nil {
+		return nil, fmt.Errorf("get user group failed: %v", err)
+	}
+	if userGroup.Stat == UserGroupStatDisable {
+		return []string{}, nil
+	}
+	users, err := m.userUsecase.userGroupRepo.GetUsersInUserGroup(ctx, mg.UserGroupUID)
+	if err != nil {
+		return nil, fmt.Errorf("get users in user group failed: %v", err)
+	}
+	userUids := make([]string, 0, len(users))
+	for _, user := range users {
+		userUids = append(userUids, user.UID)
+	}
+	return userUids, nil
+}
+
 func (m *MemberGroupUsecase) DeleteMemberGroup(ctx context.Context, currentUserUid, memberGroupUid, projectUid string) (err error) {
 	// check
 	{
@@ -211,6 +277,7 @@ func (m *MemberGroupUsecase) DeleteMemberGroup(ctx context.Context, currentUserU
 	if err != nil {
 		return err
 	}
+	m.opPermissionVerifyUsecase.InvalidateProjectPermissions(ctx, projectUid)
 	// 调用其他服务对成员组进行删除后处理
 	if err := m.pluginUsecase.DelMemberGroupAfterHandle(ctx, memberGroupUid); err != nil {
 		return err
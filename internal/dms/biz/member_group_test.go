@@ -0,0 +1,44 @@
+package biz
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type mockBoundUserGroupRepo struct {
+	mockUserGroupRepoForTest
+	group *UserGroup
+	users []*User
+}
+
+func (m *mockBoundUserGroupRepo) GetUserGroup(context.Context, string) (*UserGroup, error) {
+	return m.group, nil
+}
+
+func (m *mockBoundUserGroupRepo) GetUsersInUserGroup(context.Context, string) ([]*User, error) {
+	return m.users, nil
+}
+
+func TestMemberGroupEffectiveUserUids(t *testing.T) {
+	repo := &mockBoundUserGroupRepo{
+		group: &UserGroup{UID: "ug_1", Stat: UserGroupStatOK},
+		users: []*User{{UID: "user_1"}, {UID: "user_2"}},
+	}
+	uc := &MemberGroupUsecase{userUsecase: &UserUsecase{userGroupRepo: repo}}
+	ctx := context.Background()
+
+	userUids, err := uc.effectiveUserUids(ctx, &MemberGroup{UserUids: []string{"user_3"}})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"user_3"}, userUids)
+
+	userUids, err = uc.effectiveUserUids(ctx, &MemberGroup{UserGroupUID: "ug_1"})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"user_1", "user_2"}, userUids)
+
+	repo.group.Stat = UserGroupStatDisable
+	userUids, err = uc.effectiveUserUids(ctx, &MemberGroup{UserGroupUID: "ug_1"})
+	assert.NoError(t, err)
+	assert.Empty(t, userUids)
+}
@@ -31,6 +31,12 @@ type Notifier interface {
 	Notify(ctx context.Context, notificationSubject, notificationBody string, users []*User) error
 }
 
+// AvailableNotifier 可判断渠道是否已启用且用户配置了接收方式，只能通过通知送达的消息在受理前据此检查
+type AvailableNotifier interface {
+	Notifier
+	Available(ctx context.Context, user *User) (bool, error)
+}
+
 func Init(smtp *SMTPConfigurationUsecase, wechat *WeChatConfigurationUsecase, im *IMConfigurationUsecase) {
 	Notifiers = append(Notifiers, &EmailNotifier{uc: smtp}, &WeChatNotifier{uc: wechat}, &FeishuNotifier{uc: im})
 }
@@ -39,6 +45,17 @@ type EmailNotifier struct {
 	uc *SMTPConfigurationUsecase
 }
 
+func (n *EmailNotifier) Available(ctx context.Context, user *User) (bool, error) {
+	if user.Email == "" {
+		return false, nil
+	}
+	smtpC, exist, err := n.uc.GetSMTPConfiguration(ctx)
+	if err != nil {
+		return false, err
+	}
+	return exist && smtpC.EnableSMTPNotify, nil
+}
+
 func (n *EmailNotifier) Notify(ctx context.Context, notificationSubject, notificationBody string, users []*User) error {
 	if len(users) == 0 {
 		return nil
@@ -89,6 +106,17 @@ type WeChatNotifier struct {
 	uc *WeChatConfigurationUsecase
 }
 
+func (w *WeChatNotifier) Available(ctx context.Context, user *User) (bool, error) {
+	if user.WxID == "" {
+		return false, nil
+	}
+	wechatC, exist, err := w.uc.GetWeChatConfiguration(ctx)
+	if err != nil {
+		return false, err
+	}
+	return exist && wechatC.EnableWeChatNotify, nil
+}
+
 func (w *WeChatNotifier) Notify(ctx context.Context, notificationSubject, notificationBody string, users []*User) error {
 	// workflow has been finished.
 	if len(users) == 0 {
@@ -173,6 +201,18 @@ type FeishuNotifier struct {
 	uc *IMConfigurationUsecase
 }
 
+// Available 飞书通过邮箱或手机号查找用户
+func (f *FeishuNotifier) Available(ctx context.Context, user *User) (bool, error) {
+	if user.Email == "" && user.Phone == "" {
+		return false, nil
+	}
+	cfg, exist, err := f.uc.GetIMConfiguration(ctx, ImTypeFeishu)
+	if err != nil {
+		return false, fmt.Errorf("get im config failed: %v", err)
+	}
+	return exist && cfg.IsEnable, nil
+}
+
 func (f *FeishuNotifier) Notify(ctx context.Context, notificationSubject, notificationBody string, users []*User) error {
 	// workflow has been finished.
 	if len(users) == 0 {
@@ -4,13 +4,16 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"strings"
 
 	v1 "github.com/actiontech/dms/api/dms/service/v1"
 
 	pkgConst "github.com/actiontech/dms/internal/dms/pkg/constant"
 	pkgErr "github.com/actiontech/dms/internal/dms/pkg/errors"
 
+	"github.com/actiontech/dms/pkg/dms-common/i18nPkg"
 	utilLog "github.com/actiontech/dms/pkg/dms-common/pkg/log"
+	sqlop "github.com/actiontech/dms/pkg/dms-common/sql_op"
 )
 
 type OpPermission struct {
@@ -22,6 +25,12 @@ type OpPermission struct {
 	RangeType OpRangeType
 	Desc      string
 	Service   v1.Service
+	// Provider 提供该权限的注册服务，内置权限为空
+	Provider string
+	NameI18n i18nPkg.I18nStr
+	DescI18n i18nPkg.I18nStr
+	// Removed 注册服务的新版本中已不再提供该权限，不再展示及授予
+	Removed bool
 }
 
 func (o *OpPermission) GetUID() string {
@@ -38,8 +47,52 @@ const (
 	OpRangeTypeGlobal    OpRangeType = "global"
 	OpRangeTypeProject   OpRangeType = "project"
 	OpRangeTypeDBService OpRangeType = "db_service"
+	// 以下范围类型仅用于成员/成员组的角色绑定，用于将数据源级别的权限收窄到库、模式或表，
+	// RangeUIDs 为 sqlop.EncodeObjectRange 编码后的对象范围
+	OpRangeTypeDatabase OpRangeType = "database"
+	OpRangeTypeSchema   OpRangeType = "schema"
+	OpRangeTypeTable    OpRangeType = "table"
+	// OpRangeTypeDBServiceSelector 仅用于成员/成员组的角色绑定，RangeUIDs 为数据源选择器，如 environment_tag=prod，
+	// 在权限校验时解析为成员所在项目内匹配的数据源，新增的数据源无需修改绑定即可被覆盖
+	OpRangeTypeDBServiceSelector OpRangeType = "db_service_selector"
 )
 
+// IsDBObject 判断是否为数据源内对象级别的范围类型
+func (o OpRangeType) IsDBObject() bool {
+	return o == OpRangeTypeDatabase || o == OpRangeTypeSchema || o == OpRangeTypeTable
+}
+
+// ParseDBObjectRanges 解析对象级别范围类型的 RangeUIDs，并检查对象范围与范围类型的层级一致
+func ParseDBObjectRanges(typ OpRangeType, rangeUIDs []string) ([]*sqlop.ObjectRange, error) {
+	if !typ.IsDBObject() {
+		return nil, fmt.Errorf("op range type %v is not a db object range type", typ)
+	}
+	ranges := make([]*sqlop.ObjectRange, 0, len(rangeUIDs))
+	for _, uid := range rangeUIDs {
+		if uid == "" {
+			continue
+		}
+		r, err := sqlop.DecodeObjectRange(uid)
+		if err != nil {
+			return nil, err
+		}
+		var valid bool
+		switch typ {
+		case OpRangeTypeDatabase:
+			valid = r.DatabaseName != "" && r.SchemaName == "" && r.TableName == ""
+		case OpRangeTypeSchema:
+			valid = r.SchemaName != "" && r.TableName == ""
+		case OpRangeTypeTable:
+			valid = r.TableName != "" && (r.DatabaseName != "" || r.SchemaName != "")
+		}
+		if !valid {
+			return nil, fmt.Errorf("object range %s does not match op range type %v", uid, typ)
+		}
+		ranges = append(ranges, r)
+	}
+	return ranges, nil
+}
+
 type Module string
 
 const (
@@ -72,11 +125,71 @@ func ParseOpRangeType(t string) (OpRangeType, error) {
 		return OpRangeTypeProject, nil
 	case OpRangeTypeDBService.String():
 		return OpRangeTypeDBService, nil
+	case OpRangeTypeDatabase.String():
+		return OpRangeTypeDatabase, nil
+	case OpRangeTypeSchema.String():
+		return OpRangeTypeSchema, nil
+	case OpRangeTypeTable.String():
+		return OpRangeTypeTable, nil
+	case OpRangeTypeDBServiceSelector.String():
+		return OpRangeTypeDBServiceSelector, nil
 	default:
 		return "", nil
 	}
 }
 
+type DBServiceSelectorKey string
+
+const (
+	// DBServiceSelectorKeyEnvironmentTag 按数据源的环境标签名称匹配
+	DBServiceSelectorKeyEnvironmentTag DBServiceSelectorKey = "environment_tag"
+	// DBServiceSelectorKeyBusinessTag 按数据源附加参数中的业务标签匹配
+	DBServiceSelectorKeyBusinessTag DBServiceSelectorKey = "business_tag"
+)
+
+// DBServiceSelector 数据源选择器，格式为 key=value
+type DBServiceSelector struct {
+	Key   DBServiceSelectorKey
+	Value string
+}
+
+func (s *DBServiceSelector) String() string {
+	return fmt.Sprintf("%s=%s", s.Key, s.Value)
+}
+
+func ParseDBServiceSelector(selector string) (*DBServiceSelector, error) {
+	key, value, found := strings.Cut(selector, "=")
+	if !found {
+		return nil, fmt.Errorf("invalid db service selector: %s", selector)
+	}
+	key, value = strings.TrimSpace(key), strings.TrimSpace(value)
+	if value == "" {
+		return nil, fmt.Errorf("invalid db service selector %s: value is empty", selector)
+	}
+	switch DBServiceSelectorKey(key) {
+	case DBServiceSelectorKeyEnvironmentTag, DBServiceSelectorKeyBusinessTag:
+	default:
+		return nil, fmt.Errorf("invalid db service selector %s: unsupported key %s", selector, key)
+	}
+	return &DBServiceSelector{Key: DBServiceSelectorKey(key), Value: value}, nil
+}
+
+// ParseDBServiceSelectors 解析数据源选择器范围类型的 RangeUIDs，多个选择器之间为或的关系
+func ParseDBServiceSelectors(rangeUIDs []string) ([]*DBServiceSelector, error) {
+	selectors := make([]*DBServiceSelector, 0, len(rangeUIDs))
+	for _, uid := range rangeUIDs {
+		if uid == "" {
+			continue
+		}
+		selector, err := ParseDBServiceSelector(uid)
+		if err != nil {
+			return nil, err
+		}
+		selectors = append(selectors, selector)
+	}
+	return selectors, nil
+}
+
 func initOpPermission() []*OpPermission {
 	return []*OpPermission{
 		{
@@ -422,7 +535,24 @@ func (d *OpPermissionUsecase) CheckOpPermissionExist(ctx context.Context, opUids
 	return d.repo.CheckOpPermissionExist(ctx, opUids)
 }
 
+func (d *OpPermissionUsecase) GetOpPermission(ctx context.Context, opUid string) (*OpPermission, error) {
+	return d.repo.GetOpPermission(ctx, opUid)
+}
+
+// excludeRemovedOpPermissions 注册服务已移除的权限不再展示
+func excludeRemovedOpPermissions(opt *ListOpPermissionsOption) {
+	opt.FilterByOptions.Groups = append(opt.FilterByOptions.Groups, pkgConst.NewConditionGroup(
+		pkgConst.FilterLogicAnd,
+		pkgConst.FilterCondition{
+			Field:    string(OpPermissionFieldRemoved),
+			Operator: pkgConst.FilterOperatorEqual,
+			Value:    false,
+		},
+	))
+}
+
 func (d *OpPermissionUsecase) ListOpPermissions(ctx context.Context, opt *ListOpPermissionsOption) (ops []*OpPermission, total int64, err error) {
+	excludeRemovedOpPermissions(opt)
 
 	ops, total, err = d.repo.ListOpPermissions(ctx, opt)
 	if err != nil {
@@ -432,6 +562,7 @@ func (d *OpPermissionUsecase) ListOpPermissions(ctx context.Context, opt *ListOp
 }
 
 func (d *OpPermissionUsecase) ListUserOpPermissions(ctx context.Context, opt *ListOpPermissionsOption) (ops []*OpPermission, total int64, err error) {
+	excludeRemovedOpPermissions(opt)
 	// 用户只能被赋予全局权限
 	opt.FilterByOptions.Groups = append(opt.FilterByOptions.Groups, pkgConst.NewConditionGroup(
 		pkgConst.FilterLogicAnd,
@@ -450,6 +581,7 @@ func (d *OpPermissionUsecase) ListUserOpPermissions(ctx context.Context, opt *Li
 }
 
 func (d *OpPermissionUsecase) ListMemberOpPermissions(ctx context.Context, opt *ListOpPermissionsOption) (ops []*OpPermission, total int64, err error) {
+	excludeRemovedOpPermissions(opt)
 	// 成员属于项目，只能被赋予非全局权限
 	opt.FilterByOptions.Groups = append(opt.FilterByOptions.Groups, pkgConst.NewConditionGroup(
 		pkgConst.FilterLogicAnd,
@@ -479,6 +611,7 @@ func (d *OpPermissionUsecase) ListMemberOpPermissions(ctx context.Context, opt *
 }
 
 func (d *OpPermissionUsecase) ListProjectOpPermissions(ctx context.Context, opt *ListOpPermissionsOption) (ops []*OpPermission, total int64, err error) {
+	excludeRemovedOpPermissions(opt)
 	opt.FilterByOptions.Groups = append(opt.FilterByOptions.Groups, pkgConst.NewConditionGroup(
 		pkgConst.FilterLogicAnd,
 		pkgConst.FilterCondition{
@@ -3,6 +3,7 @@ package biz
 import (
 	"context"
 	"fmt"
+	"slices"
 
 	dmsV1 "github.com/actiontech/dms/api/dms/service/v1"
 	pkgConst "github.com/actiontech/dms/internal/dms/pkg/constant"
@@ -10,6 +11,7 @@ import (
 	dmsCommonV1 "github.com/actiontech/dms/pkg/dms-common/api/dms/v1"
 
 	utilLog "github.com/actiontech/dms/pkg/dms-common/pkg/log"
+	sqlop "github.com/actiontech/dms/pkg/dms-common/sql_op"
 )
 
 type OpPermissionVerifyRepo interface {
@@ -24,12 +26,15 @@ type OpPermissionVerifyRepo interface {
 	ListUsersOpPermissionInProject(ctx context.Context, projectUid string, opt *ListMembersOpPermissionOption) (items []ListMembersOpPermissionItem, total int64, err error)
 	GetUserProject(ctx context.Context, userUid string) (projects []*Project, err error)
 	ListUsersInProject(ctx context.Context, projectUid string) (items []ListMembersOpPermissionItem, err error)
+	ListDBServiceUIDsBySelector(ctx context.Context, projectUid string, selector *DBServiceSelector) (dbServiceUids []string, err error)
+	ListUserOpPermissionSourcesInProject(ctx context.Context, userUid, projectUid string) (sources []OpPermissionSource, err error)
 }
 
 type OpPermissionVerifyUsecase struct {
 	tx       TransactionGenerator
 	repo     OpPermissionVerifyRepo
 	userRepo UserRepo
+	cache    *PermissionCache
 	log      *utilLog.Helper
 }
 
@@ -42,7 +47,51 @@ func NewOpPermissionVerifyUsecase(log utilLog.Logger, tx TransactionGenerator, r
 	}
 }
 
+// SetPermissionCache 启用权限缓存，需在服务启动处理请求前调用
+func (o *OpPermissionVerifyUsecase) SetPermissionCache(cache *PermissionCache) {
+	o.cache = cache
+	o.repo = &cachedOpPermissionVerifyRepo{OpPermissionVerifyRepo: o.repo, cache: cache}
+}
+
+// InvalidateUserPermissions 用户状态、全局权限变更后调用
+func (o *OpPermissionVerifyUsecase) InvalidateUserPermissions(ctx context.Context, userUid string) {
+	if o.cache != nil {
+		o.cache.InvalidateUser(ctx, userUid)
+	}
+}
+
+// InvalidateProjectPermissions 项目成员、成员组变更后调用
+func (o *OpPermissionVerifyUsecase) InvalidateProjectPermissions(ctx context.Context, projectUid string) {
+	if o.cache != nil {
+		o.cache.InvalidateProject(ctx, projectUid)
+	}
+}
+
+// InvalidateAllPermissions 角色、用户组、项目等影响范围不确定的变更后调用
+func (o *OpPermissionVerifyUsecase) InvalidateAllPermissions(ctx context.Context) {
+	if o.cache != nil {
+		o.cache.InvalidateAll(ctx)
+	}
+}
+
+// SyncPermissionCache 集群模式下同步其他节点的权限变更
+func (o *OpPermissionVerifyUsecase) SyncPermissionCache() {
+	if o.cache != nil {
+		o.cache.SyncClusterVersion()
+	}
+}
+
+func (o *OpPermissionVerifyUsecase) PermissionCacheStats() (stats PermissionCacheStats, enabled bool) {
+	if o.cache == nil {
+		return PermissionCacheStats{}, false
+	}
+	return o.cache.Stats(), true
+}
+
 func (o *OpPermissionVerifyUsecase) IsUserProjectAdmin(ctx context.Context, userUid, projectUid string, isBusinessWrite bool) (bool, error) {
+	if !AccessTokenScopeFromContext(ctx).AllowProject(projectUid) {
+		return false, nil
+	}
 	// 内置用户admin和sys拥有所有权限
 	switch userUid {
 	case pkgConst.UIDOfUserAdmin, pkgConst.UIDOfUserSys:
@@ -93,6 +142,9 @@ func (o *OpPermissionVerifyUsecase) HasGlobalManagementOrViewPermission(ctx cont
 }
 
 func (o *OpPermissionVerifyUsecase) CanOpGlobal(ctx context.Context, userUid string, isBusinessWrite bool) (bool, error) {
+	if !AccessTokenScopeFromContext(ctx).AllowGlobal(isBusinessWrite) {
+		return false, nil
+	}
 	isUserDMSAdmin, err := o.IsUserDMSAdmin(ctx, userUid)
 	if err != nil {
 		return false, err
@@ -134,6 +186,9 @@ func (o *OpPermissionVerifyUsecase) CanOpGlobal(ctx context.Context, userUid str
 }
 
 func (o *OpPermissionVerifyUsecase) CanOpProject(ctx context.Context, userUid, projectUid string, isBusinessWrite bool) (bool, error) {
+	if !AccessTokenScopeFromContext(ctx).AllowProject(projectUid) {
+		return false, nil
+	}
 	canGlobalOp, err := o.CanOpGlobal(ctx, userUid, isBusinessWrite)
 	if err != nil {
 		return false, err
@@ -151,6 +206,9 @@ func (o *OpPermissionVerifyUsecase) CanOpProject(ctx context.Context, userUid, p
 }
 
 func (o *OpPermissionVerifyUsecase) CanViewProject(ctx context.Context, userUid, projectUid string, uIdOfPermission string) (bool, error) {
+	if !AccessTokenScopeFromContext(ctx).AllowProject(projectUid) {
+		return false, nil
+	}
 	canViewGlobal, err := o.CanViewGlobal(ctx, userUid)
 	if err != nil {
 		return false, err
@@ -208,6 +266,9 @@ func (o *OpPermissionVerifyUsecase) HasManagePermission(ctx context.Context, use
 
 // HasOpPermissionInProject 查看某用户在某项目下是否有某种权限
 func (o *OpPermissionVerifyUsecase) HasOpPermissionInProject(ctx context.Context, userUid, projectUid string, permissionUid string) (bool, error) {
+	if !AccessTokenScopeFromContext(ctx).AllowProject(projectUid) {
+		return false, nil
+	}
 	has, err := o.repo.IsUserHasOpPermissionInProject(ctx, userUid, projectUid, permissionUid)
 	if err != nil {
 		return false, fmt.Errorf("failed to check user op permission in project: %v", err)
@@ -216,6 +277,9 @@ func (o *OpPermissionVerifyUsecase) HasOpPermissionInProject(ctx context.Context
 }
 
 func (o *OpPermissionVerifyUsecase) CanViewGlobal(ctx context.Context, userUid string) (bool, error) {
+	if !AccessTokenScopeFromContext(ctx).AllowGlobal(false) {
+		return false, nil
+	}
 	isUserDMSAdmin, err := o.IsUserDMSAdmin(ctx, userUid)
 	if err != nil {
 		return false, err
@@ -240,11 +304,15 @@ func (o *OpPermissionVerifyUsecase) CanViewGlobal(ctx context.Context, userUid s
 
 type OpPermissionWithOpRange struct {
 	OpPermissionUID string      // 操作权限
-	OpRangeType     OpRangeType // OpRangeType描述操作权限的权限范围类型，目前只支持数据源
-	RangeUIDs       []string    // Range描述操作权限的权限范围，如涉及哪些数据源
+	OpRangeType     OpRangeType // OpRangeType描述操作权限的权限范围类型，如数据源或数据源内的库、模式、表
+	RangeUIDs       []string    // Range描述操作权限的权限范围，如涉及哪些数据源；对象级别范围为编码后的对象范围
+	ProjectUID      string      // 权限绑定所在的项目，用于解析数据源选择器
 }
 
 func (o *OpPermissionVerifyUsecase) GetUserGlobalOpPermission(ctx context.Context, userUid string) ([]OpPermissionWithOpRange, error) {
+	if !AccessTokenScopeFromContext(ctx).AllowGlobal(false) {
+		return nil, nil
+	}
 	opPermissionWithOpRanges, err := o.repo.GetUserGlobalOpPermission(ctx, userUid)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get user global op permission : %v", err)
@@ -262,11 +330,17 @@ func (o *OpPermissionVerifyUsecase) GetUserGlobalOpPermission(ctx context.Contex
 }
 
 func (o *OpPermissionVerifyUsecase) GetUserOpPermissionInProject(ctx context.Context, userUid, projectUid string) ([]OpPermissionWithOpRange, error) {
+	if !AccessTokenScopeFromContext(ctx).AllowProject(projectUid) {
+		return nil, nil
+	}
 
 	opPermissionWithOpRanges, err := o.repo.GetUserOpPermissionInProject(ctx, userUid, projectUid)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get user op permission in project: %v", err)
 	}
+	if opPermissionWithOpRanges, err = o.resolveDBServiceSelectors(ctx, opPermissionWithOpRanges); err != nil {
+		return nil, err
+	}
 	opProjectPermissionWithOpRanges, err := o.repo.GetUserProjectOpPermissionInProject(ctx, userUid, projectUid)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get user project op permission: %v", err)
@@ -281,7 +355,7 @@ func (o *OpPermissionVerifyUsecase) GetOneOpPermissionInProject(ctx context.Cont
 	if err != nil {
 		return nil, fmt.Errorf("failed to get user op permission in project: %v", err)
 	}
-	return opPermissionWithOpRanges, nil
+	return o.resolveDBServiceSelectors(ctx, opPermissionWithOpRanges)
 }
 
 func (o *OpPermissionVerifyUsecase) GetUserOpPermission(ctx context.Context, userUid string) ([]OpPermissionWithOpRange, error) {
@@ -289,6 +363,9 @@ func (o *OpPermissionVerifyUsecase) GetUserOpPermission(ctx context.Context, use
 	if err != nil {
 		return nil, fmt.Errorf("failed to get user op permission in project: %v", err)
 	}
+	if opPermissionWithOpRanges, err = o.resolveDBServiceSelectors(ctx, opPermissionWithOpRanges); err != nil {
+		return nil, err
+	}
 	opProjectPermissionWithOpRanges, err := o.repo.GetUserProjectOpPermission(ctx, userUid)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get user project op permission: %v", err)
@@ -297,6 +374,67 @@ func (o *OpPermissionVerifyUsecase) GetUserOpPermission(ctx context.Context, use
 	return opPermissionWithOpRanges, nil
 }
 
+// resolveDBServiceSelectors 将数据源选择器范围解析为校验时刻项目内匹配的数据源范围，
+// 解析后的范围类型为 db_service，下游的权限判定及 GetUserOpPermission 无需感知选择器
+func (o *OpPermissionVerifyUsecase) resolveDBServiceSelectors(ctx context.Context, opPermissions []OpPermissionWithOpRange) ([]OpPermissionWithOpRange, error) {
+	resolved := make(map[string][]string)
+	ret := make([]OpPermissionWithOpRange, 0, len(opPermissions))
+	for _, p := range opPermissions {
+		if p.OpRangeType == OpRangeTypeDBServiceSelector {
+			uids, err := o.resolveDBServiceSelector(ctx, p.ProjectUID, p.RangeUIDs, resolved)
+			if err != nil {
+				return nil, err
+			}
+			p.OpRangeType = OpRangeTypeDBService
+			p.RangeUIDs = uids
+		}
+		ret = append(ret, p)
+	}
+	return ret, nil
+}
+
+// ResolveDBServiceSelectors 返回项目内匹配任一选择器的数据源
+func (o *OpPermissionVerifyUsecase) ResolveDBServiceSelectors(ctx context.Context, projectUid string, selectors []string) ([]string, error) {
+	return o.resolveDBServiceSelector(ctx, projectUid, selectors, make(map[string][]string))
+}
+
+// resolveDBServiceSelector resolved 缓存同一次校验中已解析过的选择器，避免重复查询
+func (o *OpPermissionVerifyUsecase) resolveDBServiceSelector(ctx context.Context, projectUid string, selectors []string, resolved map[string][]string) ([]string, error) {
+	dbServiceUids := make([]string, 0)
+	if projectUid == "" {
+		return dbServiceUids, nil
+	}
+	seen := make(map[string]struct{})
+	for _, s := range selectors {
+		if s == "" {
+			continue
+		}
+		selector, err := ParseDBServiceSelector(s)
+		if err != nil {
+			// 选择器非法时不授予任何数据源，避免一条错误的绑定导致整个权限查询失败
+			o.log.Warnf("skip invalid db service selector in project %s: %v", projectUid, err)
+			continue
+		}
+		key := projectUid + "/" + selector.String()
+		uids, ok := resolved[key]
+		if !ok {
+			uids, err = o.repo.ListDBServiceUIDsBySelector(ctx, projectUid, selector)
+			if err != nil {
+				return nil, fmt.Errorf("failed to resolve db service selector %s: %v", selector, err)
+			}
+			resolved[key] = uids
+		}
+		for _, uid := range uids {
+			if _, ok := seen[uid]; ok {
+				continue
+			}
+			seen[uid] = struct{}{}
+			dbServiceUids = append(dbServiceUids, uid)
+		}
+	}
+	return dbServiceUids, nil
+}
+
 type ProjectOpPermissionWithOpRange struct {
 	ProjectUid              string
 	ProjectName             string
@@ -309,8 +447,21 @@ func (o *OpPermissionVerifyUsecase) GetUserProjectOpPermission(ctx context.Conte
 	if err != nil {
 		return nil, fmt.Errorf("failed to get user project with op permission : %v", err)
 	}
+	resolved := make(map[string][]string)
+	ret := make([]ProjectOpPermissionWithOpRange, 0, len(projectOpPermissionWithOpRange))
+	for _, p := range projectOpPermissionWithOpRange {
+		if p.OpPermissionWithOpRange.OpRangeType == OpRangeTypeDBServiceSelector {
+			uids, err := o.resolveDBServiceSelector(ctx, p.ProjectUid, p.OpPermissionWithOpRange.RangeUIDs, resolved)
+			if err != nil {
+				return nil, err
+			}
+			p.OpPermissionWithOpRange.OpRangeType = OpRangeTypeDBService
+			p.OpPermissionWithOpRange.RangeUIDs = uids
+		}
+		ret = append(ret, p)
+	}
 
-	return projectOpPermissionWithOpRange, nil
+	return ret, nil
 }
 
 func (o *OpPermissionVerifyUsecase) GetUserManagerProject(ctx context.Context, projectWithOpPermissions []ProjectOpPermissionWithOpRange) (userBindProjects []dmsCommonV1.UserBindProject) {
@@ -383,14 +534,26 @@ func (o *OpPermissionVerifyUsecase) ListUsersOpPermissionInProject(ctx context.C
 	if err != nil {
 		return nil, 0, fmt.Errorf("failed to list members op permission in project: %v", err)
 	}
+	ret := make([]ListMembersOpPermissionItem, 0, len(items))
+	for _, item := range items {
+		if item.OpPermissions, err = o.resolveDBServiceSelectors(ctx, item.OpPermissions); err != nil {
+			return nil, 0, err
+		}
+		ret = append(ret, item)
+	}
 
-	return items, total, nil
+	return ret, total, nil
 }
 
 func (o *OpPermissionVerifyUsecase) ListUsersInProject(ctx context.Context, projectUid string) ([]ListMembersOpPermissionItem, error) {
 	return o.repo.ListUsersInProject(ctx, projectUid)
 }
 
+// ListUserOpPermissionSourcesInProject 返回用户在项目内的全部授权及其来源，选择器范围未解析
+func (o *OpPermissionVerifyUsecase) ListUserOpPermissionSourcesInProject(ctx context.Context, userUid, projectUid string) ([]OpPermissionSource, error) {
+	return o.repo.ListUserOpPermissionSourcesInProject(ctx, userUid, projectUid)
+}
+
 func (o *OpPermissionVerifyUsecase) GetUserProject(ctx context.Context, userUid string) ([]*Project, error) {
 
 	projects, err := o.repo.GetUserProject(ctx, userUid)
@@ -398,6 +561,16 @@ func (o *OpPermissionVerifyUsecase) GetUserProject(ctx context.Context, userUid
 		return nil, fmt.Errorf("failed to get user project with op permission : %v", err)
 	}
 
+	if scope := AccessTokenScopeFromContext(ctx); scope.IsProjectRestricted() {
+		allowed := make([]*Project, 0, len(projects))
+		for _, project := range projects {
+			if scope.AllowProject(project.UID) {
+				allowed = append(allowed, project)
+			}
+		}
+		return allowed, nil
+	}
+
 	return projects, nil
 }
 
@@ -405,7 +578,9 @@ func (o *OpPermissionVerifyUsecase) GetUserProject(ctx context.Context, userUid
 //
 // 两种授权范围的判定语义不同：
 //   - db_service：授予到具体数据源，需 RangeUIDs 命中 dbServiceUid；
-//   - project：授予到整个项目，视为命中项目内全部数据源（如「脱敏审核」700038）。
+//   - project：授予到整个项目，视为命中项目内全部数据源（如「脱敏审核」700038）；
+//   - database/schema/table：只授予到数据源内的部分对象，不视为拥有整个数据源的权限，
+//     需由调用方带上访问的对象通过 UserCanOpDBWithObjects 或 CheckUserCanOpDBObjects 判定。
 //
 // project 分支不会放大数据源级权限：范围为 project 的记录只可能来自成员/成员组的
 // 「项目管理权限」槽，而该槽的可选项由 ListProjectOpPermissions 限定为注册范围是
@@ -440,6 +615,125 @@ func (o *OpPermissionVerifyUsecase) UserCanOpDB(userOpPermissions []OpPermission
 	return false
 }
 
+// objectRangesInDBService 判断对象级别的权限范围中是否有属于 dbServiceUid 的对象
+func objectRangesInDBService(userOpPermission OpPermissionWithOpRange, dbServiceUid string) bool {
+	for _, uid := range ObjectRangeDBServiceUIDs(userOpPermission) {
+		if uid == dbServiceUid {
+			return true
+		}
+	}
+	return false
+}
+
+// ObjectRangeDBServiceUIDs 返回对象级别的权限范围所属的数据源，用于列出可访问的数据源，访问的对象仍需经由 UserCanOpDBObjects 判定
+func ObjectRangeDBServiceUIDs(userOpPermission OpPermissionWithOpRange) []string {
+	if !userOpPermission.OpRangeType.IsDBObject() {
+		return nil
+	}
+	ranges, err := ParseDBObjectRanges(userOpPermission.OpRangeType, userOpPermission.RangeUIDs)
+	if err != nil {
+		return nil
+	}
+	uids := make([]string, 0, len(ranges))
+	for _, r := range ranges {
+		uids = append(uids, r.DBServiceUID)
+	}
+	return uids
+}
+
+// UserCanOpDBObjects 判断用户能否对 dbServiceUid 上的对象执行 needOpPermissionTypes 中的任一权限，
+// objects 一般来自 sqlop.SQLObjectOps 中解析出的对象。返回不在用户权限范围内的对象，返回空表示全部允许。
+// 项目管理员、项目范围及数据源范围的权限覆盖数据源内的全部对象；objects 为空时不做判定。
+// UserCanOpDB 不会因对象范围放行，只拥有对象范围的用户必须经由此方法判定。
+func (o *OpPermissionVerifyUsecase) UserCanOpDBObjects(userOpPermissions []OpPermissionWithOpRange, needOpPermissionTypes []string, dbServiceUid string, objects []*sqlop.SQLObject) []*sqlop.SQLObject {
+	objectRanges := make([]*sqlop.ObjectRange, 0)
+	for _, userOpPermission := range userOpPermissions {
+		if userOpPermission.OpPermissionUID == pkgConst.UIDOfOpPermissionProjectAdmin {
+			return nil
+		}
+		for _, needOpPermission := range needOpPermissionTypes {
+			if needOpPermission != userOpPermission.OpPermissionUID {
+				continue
+			}
+			switch userOpPermission.OpRangeType {
+			case OpRangeTypeDBService:
+				for _, id := range userOpPermission.RangeUIDs {
+					if id == dbServiceUid {
+						return nil
+					}
+				}
+			case OpRangeTypeProject:
+				return nil
+			case OpRangeTypeDatabase, OpRangeTypeSchema, OpRangeTypeTable:
+				ranges, err := ParseDBObjectRanges(userOpPermission.OpRangeType, userOpPermission.RangeUIDs)
+				if err != nil {
+					o.log.Warnf("skip invalid object range of op permission %v: %v", userOpPermission.OpPermissionUID, err)
+					continue
+				}
+				objectRanges = append(objectRanges, ranges...)
+			}
+		}
+	}
+
+	denied := make([]*sqlop.SQLObject, 0)
+	for _, object := range objects {
+		allowed := false
+		for _, r := range objectRanges {
+			if r.Contains(dbServiceUid, object) {
+				allowed = true
+				break
+			}
+		}
+		if !allowed {
+			denied = append(denied, object)
+		}
+	}
+	return denied
+}
+
+// UserHasDBObjectRange 判断用户是否以库、模式、表级别的范围拥有 dbServiceUid 上 needOpPermissionTypes 中的任一权限，
+// 用于列出可选的数据源，访问的对象仍需经由 UserCanOpDBWithObjects 判定
+func (o *OpPermissionVerifyUsecase) UserHasDBObjectRange(userOpPermissions []OpPermissionWithOpRange, needOpPermissionTypes []string, dbServiceUid string) bool {
+	for _, userOpPermission := range userOpPermissions {
+		if !slices.Contains(needOpPermissionTypes, userOpPermission.OpPermissionUID) {
+			continue
+		}
+		if objectRangesInDBService(userOpPermission, dbServiceUid) {
+			return true
+		}
+	}
+	return false
+}
+
+// UserCanOpDBWithObjects 在 UserCanOpDB 的基础上，objects 全部在用户的对象范围内时同样允许；
+// objects 为空时只拥有对象范围的用户不允许
+func (o *OpPermissionVerifyUsecase) UserCanOpDBWithObjects(userOpPermissions []OpPermissionWithOpRange, needOpPermissionTypes []string, dbServiceUid string, objects []*sqlop.SQLObject) bool {
+	if o.UserCanOpDB(userOpPermissions, needOpPermissionTypes, dbServiceUid) {
+		return true
+	}
+	return len(objects) > 0 && len(o.UserCanOpDBObjects(userOpPermissions, needOpPermissionTypes, dbServiceUid, objects)) == 0
+}
+
+// CheckUserCanOpDBObjects 判断用户能否对项目内数据源 dbServiceUid 上的 objects 执行 needOpPermissionTypes 中的任一权限，
+// 不允许时返回不在用户权限范围内的对象。可操作项目的用户不受限制，其余按 UserCanOpDBWithObjects 判定
+func (o *OpPermissionVerifyUsecase) CheckUserCanOpDBObjects(ctx context.Context, userUid, projectUid, dbServiceUid string, needOpPermissionTypes []string, objects []*sqlop.SQLObject) (allowed bool, denied []*sqlop.SQLObject, err error) {
+	canOpProject, err := o.CanOpProject(ctx, userUid, projectUid, false)
+	if err != nil {
+		return false, nil, err
+	}
+	if canOpProject {
+		return true, nil, nil
+	}
+	opPermissions, err := o.GetUserOpPermissionInProject(ctx, userUid, projectUid)
+	if err != nil {
+		return false, nil, err
+	}
+	if o.UserCanOpDBWithObjects(opPermissions, needOpPermissionTypes, dbServiceUid, objects) {
+		return true, nil, nil
+	}
+	return false, o.UserCanOpDBObjects(opPermissions, needOpPermissionTypes, dbServiceUid, objects), nil
+}
+
 func (o *OpPermissionVerifyUsecase) GetCanOpDBUsers(ctx context.Context, projectUID, dbServiceUid string, needOpPermissionTypes []string, isBusinessWrite bool) ([]string, error) {
 	members, _, err := o.ListUsersOpPermissionInProject(ctx, projectUID, &ListMembersOpPermissionOption{
 		PageNumber:   1,
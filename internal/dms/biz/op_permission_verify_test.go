@@ -4,16 +4,20 @@ import (
 	"context"
 	"fmt"
 	"testing"
+	"time"
 
 	dmsV1 "github.com/actiontech/dms/api/dms/service/v1"
 	pkgConst "github.com/actiontech/dms/internal/dms/pkg/constant"
+	pkgErr "github.com/actiontech/dms/internal/dms/pkg/errors"
 	utilLog "github.com/actiontech/dms/pkg/dms-common/pkg/log"
+	sqlop "github.com/actiontech/dms/pkg/dms-common/sql_op"
 	"github.com/stretchr/testify/assert"
 )
 
 // mockUserRepo implements UserRepo for testing
 type mockUserRepo struct {
-	users map[string]*User
+	users         map[string]*User
+	loginSessions map[string]*LoginSession
 }
 
 func (m *mockUserRepo) GetUser(_ context.Context, uid string) (*User, error) {
@@ -55,10 +59,86 @@ func (m *mockUserRepo) SaveAccessToken(context.Context, *AccessTokenInfo) error
 func (m *mockUserRepo) GetAccessTokenByUser(context.Context, string) (*AccessTokenInfo, error) {
 	return nil, nil
 }
+func (m *mockUserRepo) GetAccessToken(context.Context, string) (*AccessTokenInfo, error) {
+	return nil, nil
+}
+func (m *mockUserRepo) GetAccessTokenByToken(context.Context, string, string) (*AccessTokenInfo, error) {
+	return nil, nil
+}
+func (m *mockUserRepo) ListAccessTokensByUser(context.Context, string) ([]*AccessTokenInfo, error) {
+	return nil, nil
+}
+func (m *mockUserRepo) UpdateAccessTokenLastUsed(context.Context, string, time.Time, string) error {
+	return nil
+}
+func (m *mockUserRepo) DelAccessToken(context.Context, string) error { return nil }
 func (m *mockUserRepo) RecordLoginSession(context.Context, string, string) error { return nil }
 func (m *mockUserRepo) GetLatestLoginSession(context.Context, string) (string, bool, error) {
 	return "", false, nil
 }
+func (m *mockUserRepo) SaveLoginSession(_ context.Context, session *LoginSession) error {
+	if m.loginSessions == nil {
+		m.loginSessions = map[string]*LoginSession{}
+	}
+	m.loginSessions[session.UID] = session
+	return nil
+}
+func (m *mockUserRepo) GetLoginSession(_ context.Context, sessionID string) (*LoginSession, error) {
+	if s, ok := m.loginSessions[sessionID]; ok {
+		return s, nil
+	}
+	return nil, pkgErr.ErrStorageNoData
+}
+func (m *mockUserRepo) ListActiveLoginSessions(context.Context, string, time.Time) ([]*LoginSession, error) {
+	return nil, nil
+}
+func (m *mockUserRepo) UpdateLoginSessionLastSeen(_ context.Context, sessionID string, lastSeenAt time.Time, lastSeenIP string) error {
+	if s, ok := m.loginSessions[sessionID]; ok {
+		s.LastSeenAt, s.IP = lastSeenAt, lastSeenIP
+	}
+	return nil
+}
+func (m *mockUserRepo) UpdateLoginSessionExpiredAt(context.Context, string, time.Time) error {
+	return nil
+}
+func (m *mockUserRepo) RevokeLoginSession(_ context.Context, sessionID string, revokedAt time.Time) error {
+	if s, ok := m.loginSessions[sessionID]; ok {
+		s.RevokedAt = revokedAt
+	}
+	return nil
+}
+func (m *mockUserRepo) RevokeUserLoginSessions(_ context.Context, userUID string, revokedAt time.Time) error {
+	for _, s := range m.loginSessions {
+		if s.UserUID == userUID {
+			s.RevokedAt = revokedAt
+		}
+	}
+	return nil
+}
+func (m *mockUserRepo) SavePasswordHistory(context.Context, string, string) error { return nil }
+func (m *mockUserRepo) ListPasswordHistories(context.Context, string, uint) ([]string, error) {
+	return nil, nil
+}
+func (m *mockUserRepo) IncreaseUserLoginFailedCount(_ context.Context, userUid string, now, windowStart time.Time) (uint, time.Time, error) {
+	u, ok := m.users[userUid]
+	if !ok {
+		return 0, time.Time{}, fmt.Errorf("user %s not found", userUid)
+	}
+	if u.LoginFailedAt.IsZero() || u.LoginFailedAt.Before(windowStart) {
+		u.LoginFailedCount = 0
+		u.LoginFailedAt = now
+	}
+	u.LoginFailedCount++
+	return u.LoginFailedCount, u.LoginFailedAt, nil
+}
+func (m *mockUserRepo) LockUserLogin(_ context.Context, userUid string, minFailedCount uint, lockedUntil time.Time) error {
+	if u, ok := m.users[userUid]; ok && u.LoginFailedCount >= minFailedCount {
+		u.LockedUntil = lockedUntil
+		u.LoginFailedCount = 0
+		u.LoginFailedAt = time.Time{}
+	}
+	return nil
+}
 
 // mockOpPermissionVerifyRepo implements OpPermissionVerifyRepo for testing
 type mockOpPermissionVerifyRepo struct {
@@ -68,6 +148,12 @@ type mockOpPermissionVerifyRepo struct {
 	globalPermissions map[string][]*OpPermission
 	// projectMembers stores project members for ListUsersOpPermissionInProject
 	projectMembers map[string][]ListMembersOpPermissionItem
+	// selectorDBServices maps projectUID/selector -> db service UIDs
+	selectorDBServices map[string][]string
+	// permissionSources maps userUID/projectUID -> op permission sources
+	permissionSources map[string][]OpPermissionSource
+	// userOpPermissions maps userUID/projectUID -> op permissions with ranges
+	userOpPermissions map[string][]OpPermissionWithOpRange
 }
 
 func (m *mockOpPermissionVerifyRepo) IsUserHasOpPermissionInProject(_ context.Context, userUid, projectUid, opPermissionUid string) (bool, error) {
@@ -88,10 +174,19 @@ func (m *mockOpPermissionVerifyRepo) ListUsersOpPermissionInProject(_ context.Co
 	return items, int64(len(items)), nil
 }
 
-// Unused methods
-func (m *mockOpPermissionVerifyRepo) GetUserOpPermissionInProject(context.Context, string, string) ([]OpPermissionWithOpRange, error) {
-	return nil, nil
+func (m *mockOpPermissionVerifyRepo) ListDBServiceUIDsBySelector(_ context.Context, projectUid string, selector *DBServiceSelector) ([]string, error) {
+	return m.selectorDBServices[projectUid+"/"+selector.String()], nil
+}
+
+func (m *mockOpPermissionVerifyRepo) ListUserOpPermissionSourcesInProject(_ context.Context, userUid, projectUid string) ([]OpPermissionSource, error) {
+	return m.permissionSources[userUid+"/"+projectUid], nil
+}
+
+func (m *mockOpPermissionVerifyRepo) GetUserOpPermissionInProject(_ context.Context, userUid, projectUid string) ([]OpPermissionWithOpRange, error) {
+	return m.userOpPermissions[userUid+"/"+projectUid], nil
 }
+
+// Unused methods
 func (m *mockOpPermissionVerifyRepo) GetOneOpPermissionInProject(context.Context, string, string, string) ([]OpPermissionWithOpRange, error) {
 	return nil, nil
 }
@@ -617,3 +712,164 @@ func TestUserCanOpDB_ProjectRange(t *testing.T) {
 		assert.False(t, uc.UserCanOpDB(perms, []string{exportApproval}, "db_2"))
 	})
 }
+
+func TestUserCanOpDB_ObjectRange(t *testing.T) {
+	uc := newTestOpPermissionVerifyUsecase(&mockUserRepo{users: map[string]*User{}}, &mockOpPermissionVerifyRepo{})
+	sqlQuery := pkgConst.UIDOfOpPermissionSQLQuery
+
+	tableRange := sqlop.EncodeObjectRange(&sqlop.ObjectRange{DBServiceUID: "db_1", DatabaseName: "sales", TableName: "orders"})
+	dbRange := sqlop.EncodeObjectRange(&sqlop.ObjectRange{DBServiceUID: "db_1", DatabaseName: "report"})
+	perms := []OpPermissionWithOpRange{
+		{OpPermissionUID: sqlQuery, OpRangeType: OpRangeTypeTable, RangeUIDs: []string{tableRange}},
+		{OpPermissionUID: sqlQuery, OpRangeType: OpRangeTypeDatabase, RangeUIDs: []string{dbRange}},
+	}
+
+	t.Run("object_range_does_not_grant_db_service", func(t *testing.T) {
+		assert.False(t, uc.UserCanOpDB(perms, []string{sqlQuery}, "db_1"))
+		assert.False(t, uc.userCanOpDBWithoutAdminPrivilege(perms, []string{sqlQuery}, "db_1"))
+		assert.False(t, uc.UserCanOpDB(perms, []string{sqlQuery}, "db_2"))
+	})
+
+	t.Run("table_range_does_not_open_other_tables", func(t *testing.T) {
+		orders := &sqlop.SQLObject{Type: sqlop.SQLObjectTypeTable, DatabaseName: "sales", TableName: "orders"}
+		users := &sqlop.SQLObject{Type: sqlop.SQLObjectTypeTable, DatabaseName: "sales", TableName: "users"}
+		otherSchemaOrders := &sqlop.SQLObject{Type: sqlop.SQLObjectTypeTable, DatabaseName: "crm", TableName: "orders"}
+		tableOnly := perms[:1]
+		assert.Empty(t, uc.UserCanOpDBObjects(tableOnly, []string{sqlQuery}, "db_1", []*sqlop.SQLObject{orders}))
+		assert.Equal(t, []*sqlop.SQLObject{users, otherSchemaOrders}, uc.UserCanOpDBObjects(tableOnly, []string{sqlQuery}, "db_1", []*sqlop.SQLObject{users, otherSchemaOrders}))
+	})
+
+	t.Run("object_level_check", func(t *testing.T) {
+		orders := &sqlop.SQLObject{Type: sqlop.SQLObjectTypeTable, DatabaseName: "sales", TableName: "orders"}
+		users := &sqlop.SQLObject{Type: sqlop.SQLObjectTypeTable, DatabaseName: "sales", TableName: "users"}
+		daily := &sqlop.SQLObject{Type: sqlop.SQLObjectTypeTable, DatabaseName: "report", TableName: "daily"}
+		sales := &sqlop.SQLObject{Type: sqlop.SQLObjectTypeDatabase, DatabaseName: "sales"}
+		instance := &sqlop.SQLObject{Type: sqlop.SQLObjectTypeInstance}
+
+		assert.Empty(t, uc.UserCanOpDBObjects(perms, []string{sqlQuery}, "db_1", []*sqlop.SQLObject{orders, daily}))
+		assert.Equal(t, []*sqlop.SQLObject{users, sales, instance}, uc.UserCanOpDBObjects(perms, []string{sqlQuery}, "db_1", []*sqlop.SQLObject{orders, users, sales, instance}))
+		assert.Equal(t, []*sqlop.SQLObject{orders}, uc.UserCanOpDBObjects(perms, []string{sqlQuery}, "db_2", []*sqlop.SQLObject{orders}))
+	})
+
+	t.Run("db_service_range_covers_all_objects", func(t *testing.T) {
+		all := append(perms, OpPermissionWithOpRange{OpPermissionUID: sqlQuery, OpRangeType: OpRangeTypeDBService, RangeUIDs: []string{"db_1"}})
+		users := &sqlop.SQLObject{Type: sqlop.SQLObjectTypeTable, DatabaseName: "sales", TableName: "users"}
+		assert.Empty(t, uc.UserCanOpDBObjects(all, []string{sqlQuery}, "db_1", []*sqlop.SQLObject{users}))
+	})
+}
+
+func TestCheckUserCanOpDBObjects(t *testing.T) {
+	exportCreate := pkgConst.UIDOfOpPermissionExportCreate
+	dbRange := sqlop.EncodeObjectRange(&sqlop.ObjectRange{DBServiceUID: "db_1", DatabaseName: "report"})
+	tableRange := sqlop.EncodeObjectRange(&sqlop.ObjectRange{DBServiceUID: "db_1", DatabaseName: "sales", TableName: "orders"})
+	repo := &mockOpPermissionVerifyRepo{
+		userOpPermissions: map[string][]OpPermissionWithOpRange{
+			"user_1/project_1": {
+				{OpPermissionUID: exportCreate, OpRangeType: OpRangeTypeDatabase, RangeUIDs: []string{dbRange}, ProjectUID: "project_1"},
+				{OpPermissionUID: exportCreate, OpRangeType: OpRangeTypeTable, RangeUIDs: []string{tableRange}, ProjectUID: "project_1"},
+			},
+		},
+		projectPermissions: map[string]map[string]map[string]bool{
+			"user_admin": {"project_1": {pkgConst.UIDOfOpPermissionProjectAdmin: true}},
+		},
+	}
+	uc := newTestOpPermissionVerifyUsecase(&mockUserRepo{users: map[string]*User{}}, repo)
+	ctx := context.Background()
+	need := []string{exportCreate}
+
+	report := &sqlop.SQLObject{Type: sqlop.SQLObjectTypeDatabase, DatabaseName: "report"}
+	orders := &sqlop.SQLObject{Type: sqlop.SQLObjectTypeTable, DatabaseName: "sales", TableName: "orders"}
+	users := &sqlop.SQLObject{Type: sqlop.SQLObjectTypeTable, DatabaseName: "sales", TableName: "users"}
+
+	allowed, denied, err := uc.CheckUserCanOpDBObjects(ctx, "user_1", "project_1", "db_1", need, []*sqlop.SQLObject{report, orders})
+	assert.NoError(t, err)
+	assert.True(t, allowed)
+	assert.Empty(t, denied)
+
+	allowed, denied, err = uc.CheckUserCanOpDBObjects(ctx, "user_1", "project_1", "db_1", need, []*sqlop.SQLObject{orders, users})
+	assert.NoError(t, err)
+	assert.False(t, allowed)
+	assert.Equal(t, []*sqlop.SQLObject{users}, denied)
+
+	// 未给出访问的对象时，只拥有对象范围不能访问整个数据源
+	allowed, _, err = uc.CheckUserCanOpDBObjects(ctx, "user_1", "project_1", "db_1", need, nil)
+	assert.NoError(t, err)
+	assert.False(t, allowed)
+
+	allowed, denied, err = uc.CheckUserCanOpDBObjects(ctx, "user_1", "project_1", "db_2", need, []*sqlop.SQLObject{report})
+	assert.NoError(t, err)
+	assert.False(t, allowed)
+	assert.Equal(t, []*sqlop.SQLObject{report}, denied)
+
+	allowed, _, err = uc.CheckUserCanOpDBObjects(ctx, "user_admin", "project_1", "db_2", need, []*sqlop.SQLObject{users})
+	assert.NoError(t, err)
+	assert.True(t, allowed)
+
+	perms := repo.userOpPermissions["user_1/project_1"]
+	assert.True(t, uc.UserHasDBObjectRange(perms, need, "db_1"))
+	assert.False(t, uc.UserHasDBObjectRange(perms, need, "db_2"))
+	assert.False(t, uc.UserHasDBObjectRange(perms, []string{pkgConst.UIDOfOpPermissionSQLQuery}, "db_1"))
+}
+
+func TestParseDBObjectRanges(t *testing.T) {
+	tableRange := sqlop.EncodeObjectRange(&sqlop.ObjectRange{DBServiceUID: "db_1", DatabaseName: "a/b", TableName: "t,1"})
+	ranges, err := ParseDBObjectRanges(OpRangeTypeTable, []string{tableRange})
+	assert.NoError(t, err)
+	assert.Equal(t, []*sqlop.ObjectRange{{DBServiceUID: "db_1", DatabaseName: "a/b", TableName: "t,1"}}, ranges)
+
+	_, err = ParseDBObjectRanges(OpRangeTypeDatabase, []string{tableRange})
+	assert.Error(t, err)
+	_, err = ParseDBObjectRanges(OpRangeTypeSchema, []string{"db_1"})
+	assert.Error(t, err)
+	_, err = ParseDBObjectRanges(OpRangeTypeDBService, []string{"db_1"})
+	assert.Error(t, err)
+}
+
+func TestDBServiceSelectorRange(t *testing.T) {
+	sqlQuery := pkgConst.UIDOfOpPermissionSQLQuery
+	repo := &mockOpPermissionVerifyRepo{
+		selectorDBServices: map[string][]string{
+			"project_1/environment_tag=prod":  {"db_1", "db_2"},
+			"project_1/business_tag=payments": {"db_2", "db_3"},
+		},
+		projectMembers: map[string][]ListMembersOpPermissionItem{
+			"project_1": {{
+				UserUid: "user_1",
+				OpPermissions: []OpPermissionWithOpRange{{
+					OpPermissionUID: sqlQuery,
+					OpRangeType:     OpRangeTypeDBServiceSelector,
+					RangeUIDs:       []string{"environment_tag=prod", "business_tag=payments", "invalid"},
+					ProjectUID:      "project_1",
+				}},
+			}},
+		},
+	}
+	uc := newTestOpPermissionVerifyUsecase(&mockUserRepo{users: map[string]*User{}}, repo)
+	ctx := context.Background()
+
+	uids, err := uc.ResolveDBServiceSelectors(ctx, "project_1", []string{"environment_tag=prod", "business_tag=payments"})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"db_1", "db_2", "db_3"}, uids)
+
+	items, _, err := uc.ListUsersOpPermissionInProject(ctx, "project_1", &ListMembersOpPermissionOption{})
+	assert.NoError(t, err)
+	assert.Equal(t, OpRangeTypeDBService, items[0].OpPermissions[0].OpRangeType)
+	assert.Equal(t, []string{"db_1", "db_2", "db_3"}, items[0].OpPermissions[0].RangeUIDs)
+
+	// 新增的 prod 数据源无需修改绑定即被覆盖
+	repo.selectorDBServices["project_1/environment_tag=prod"] = []string{"db_1", "db_2", "db_4"}
+	users, err := uc.GetCanOpDBUsers(ctx, "project_1", "db_4", []string{sqlQuery}, false)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"user_1"}, users)
+}
+
+func TestParseDBServiceSelector(t *testing.T) {
+	selector, err := ParseDBServiceSelector(" environment_tag = prod ")
+	assert.NoError(t, err)
+	assert.Equal(t, &DBServiceSelector{Key: DBServiceSelectorKeyEnvironmentTag, Value: "prod"}, selector)
+
+	for _, s := range []string{"prod", "environment_tag=", "owner=alice"} {
+		_, err := ParseDBServiceSelector(s)
+		assert.Error(t, err, s)
+	}
+}
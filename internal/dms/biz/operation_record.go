@@ -36,6 +36,7 @@ type ListOperationRecordOption struct {
 	FilterOperateTimeTo        string
 	FilterOperateProjectName   *string
 	FuzzySearchOperateUserName string
+	FilterOperateUserName      string
 	FilterOperateTypeName      string
 	FilterOperateAction        string
 	// 权限相关字段
@@ -101,6 +102,11 @@ func (u *OperationRecordUsecase) DoClean() {
 	u.log.Infof("OperationRecord regular cleaned rows: %d operation time before: %s", rowsAffected, cleanTime.Format("2006-01-02 15:04:05"))
 }
 
+// SaveOperationRecord 供后台任务等没有请求上下文的场景直接记录操作
+func (u *OperationRecordUsecase) SaveOperationRecord(ctx context.Context, record *OperationRecord) error {
+	return u.repo.SaveOperationRecord(ctx, record)
+}
+
 func (u *OperationRecordUsecase) GetLog() *utilLog.Helper {
 	return u.log
 }
@@ -0,0 +1,344 @@
+package biz
+
+import (
+	"context"
+	"crypto/pbkdf2"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	pkgConst "github.com/actiontech/dms/internal/dms/pkg/constant"
+	jwtPkg "github.com/actiontech/dms/pkg/dms-common/api/jwt"
+	"github.com/labstack/echo/v4"
+)
+
+const (
+	defaultLockoutWindowMinutes   = 15
+	defaultLockoutDurationMinutes = 30
+
+	secretHashIterations = 10000
+	secretHashKeyLen     = 32
+	secretHashPrefix     = "pbkdf2_sha256"
+)
+
+var (
+	ErrUserLoginLocked = errors.New("the account is temporarily locked because of too many failed login attempts, please try again later or contact the administrator")
+	ErrPasswordExpired = errors.New("the password has expired, please change the password first")
+)
+
+// PasswordPolicy 描述 DMS 本地用户的密码复杂度、历史及有效期要求
+type PasswordPolicy struct {
+	MinLength        uint
+	RequireUppercase bool
+	RequireLowercase bool
+	RequireDigit     bool
+	RequireSpecial   bool
+	HistoryCount     uint
+	MaxAgeDays       uint
+}
+
+// LockoutPolicy 描述登录失败锁定策略：WindowMinutes 内连续失败 MaxFailedAttempts 次后锁定 DurationMinutes
+type LockoutPolicy struct {
+	MaxFailedAttempts uint
+	WindowMinutes     uint
+	DurationMinutes   uint
+}
+
+func (c *LoginConfiguration) GetPasswordPolicy() *PasswordPolicy {
+	return &PasswordPolicy{
+		MinLength:        c.PasswordMinLength,
+		RequireUppercase: c.PasswordRequireUppercase,
+		RequireLowercase: c.PasswordRequireLowercase,
+		RequireDigit:     c.PasswordRequireDigit,
+		RequireSpecial:   c.PasswordRequireSpecial,
+		HistoryCount:     c.PasswordHistoryCount,
+		MaxAgeDays:       c.PasswordMaxAgeDays,
+	}
+}
+
+func (c *LoginConfiguration) GetLockoutPolicy() *LockoutPolicy {
+	p := &LockoutPolicy{
+		MaxFailedAttempts: c.LockoutMaxFailedAttempts,
+		WindowMinutes:     c.LockoutWindowMinutes,
+		DurationMinutes:   c.LockoutDurationMinutes,
+	}
+	if p.WindowMinutes == 0 {
+		p.WindowMinutes = defaultLockoutWindowMinutes
+	}
+	if p.DurationMinutes == 0 {
+		p.DurationMinutes = defaultLockoutDurationMinutes
+	}
+	return p
+}
+
+func (p *LockoutPolicy) Enabled() bool {
+	return p.MaxFailedAttempts > 0
+}
+
+// CheckComplexity 校验密码长度及字符类型，不涉及历史密码
+func (p *PasswordPolicy) CheckComplexity(password string) error {
+	if uint(len([]rune(password))) < p.MinLength {
+		return fmt.Errorf("the password must be at least %d characters long", p.MinLength)
+	}
+
+	var hasUpper, hasLower, hasDigit, hasSpecial bool
+	for _, r := range password {
+		switch {
+		case unicode.IsUpper(r):
+			hasUpper = true
+		case unicode.IsLower(r):
+			hasLower = true
+		case unicode.IsDigit(r):
+			hasDigit = true
+		case unicode.IsPunct(r) || unicode.IsSymbol(r):
+			hasSpecial = true
+		}
+	}
+
+	missing := make([]string, 0)
+	if p.RequireUppercase && !hasUpper {
+		missing = append(missing, "uppercase letter")
+	}
+	if p.RequireLowercase && !hasLower {
+		missing = append(missing, "lowercase letter")
+	}
+	if p.RequireDigit && !hasDigit {
+		missing = append(missing, "digit")
+	}
+	if p.RequireSpecial && !hasSpecial {
+		missing = append(missing, "special character")
+	}
+	if len(missing) > 0 {
+		return fmt.Errorf("the password must contain at least one %s", strings.Join(missing, ", "))
+	}
+	return nil
+}
+
+// IsExpired 判断密码是否超过最长有效期，未记录修改时间的历史用户以创建时间为准
+func (p *PasswordPolicy) IsExpired(user *User, now time.Time) bool {
+	if p.MaxAgeDays == 0 || !user.IsPasswordPolicyApplicable() {
+		return false
+	}
+	changedAt := user.PasswordChangedAt
+	if changedAt.IsZero() {
+		changedAt = user.CreatedAt
+	}
+	if changedAt.IsZero() {
+		return false
+	}
+	return now.After(changedAt.Add(time.Duration(p.MaxAgeDays) * 24 * time.Hour))
+}
+
+// IsPasswordPolicyApplicable 密码策略与登录锁定仅作用于 DMS 本地用户，LDAP/OAuth2 用户的密码由第三方平台管理
+func (u *User) IsPasswordPolicyApplicable() bool {
+	return u.UserAuthenticationType == UserAuthenticationTypeDMS
+}
+
+func (u *User) IsLocked(now time.Time) bool {
+	return !u.LockedUntil.IsZero() && now.Before(u.LockedUntil)
+}
+
+// hashSecret 使用加盐 PBKDF2 生成摘要，用于历史密码、服务账号密钥等只需比对、无需可逆的场景
+func hashSecret(secret string) (string, error) {
+	salt := make([]byte, 16)
+	if _, err := rand.Read(salt); err != nil {
+		return "", fmt.Errorf("generate salt failed: %v", err)
+	}
+	key, err := pbkdf2.Key(sha256.New, secret, salt, secretHashIterations, secretHashKeyLen)
+	if err != nil {
+		return "", fmt.Errorf("hash secret failed: %v", err)
+	}
+	return strings.Join([]string{
+		secretHashPrefix,
+		strconv.Itoa(secretHashIterations),
+		base64.RawStdEncoding.EncodeToString(salt),
+		base64.RawStdEncoding.EncodeToString(key),
+	}, "$"), nil
+}
+
+func matchSecretHash(hash, secret string) bool {
+	parts := strings.Split(hash, "$")
+	if len(parts) != 4 || parts[0] != secretHashPrefix {
+		return false
+	}
+	iterations, err := strconv.Atoi(parts[1])
+	if err != nil || iterations <= 0 {
+		return false
+	}
+	salt, err := base64.RawStdEncoding.DecodeString(parts[2])
+	if err != nil {
+		return false
+	}
+	expected, err := base64.RawStdEncoding.DecodeString(parts[3])
+	if err != nil {
+		return false
+	}
+	key, err := pbkdf2.Key(sha256.New, secret, salt, iterations, len(expected))
+	if err != nil {
+		return false
+	}
+	return subtle.ConstantTimeCompare(key, expected) == 1
+}
+
+// checkNewPassword 校验新密码是否满足当前密码策略，包括复杂度以及不得与最近 N 次密码重复
+func (d *UserUsecase) checkNewPassword(ctx context.Context, user *User, newPassword string) error {
+	if !user.IsPasswordPolicyApplicable() {
+		return nil
+	}
+	loginC, err := d.loginConfigurationUsecase.GetLoginConfiguration(ctx)
+	if err != nil {
+		return fmt.Errorf("get login configuration failed: %v", err)
+	}
+	policy := loginC.GetPasswordPolicy()
+	if err := policy.CheckComplexity(newPassword); err != nil {
+		return err
+	}
+	if policy.HistoryCount == 0 || user.UID == "" {
+		return nil
+	}
+	if user.Password != "" && user.Password == newPassword {
+		return fmt.Errorf("the new password must not be the same as the last %d passwords", policy.HistoryCount)
+	}
+	hashes, err := d.repo.ListPasswordHistories(ctx, user.UID, policy.HistoryCount)
+	if err != nil {
+		return fmt.Errorf("list password histories failed: %v", err)
+	}
+	for _, hash := range hashes {
+		if matchSecretHash(hash, newPassword) {
+			return fmt.Errorf("the new password must not be the same as the last %d passwords", policy.HistoryCount)
+		}
+	}
+	return nil
+}
+
+// recordPasswordChange 记录密码修改时间并写入历史密码摘要，需在保存用户前调用
+func (d *UserUsecase) recordPasswordChange(ctx context.Context, user *User) error {
+	user.PasswordChangedAt = time.Now()
+	if !user.IsPasswordPolicyApplicable() {
+		return nil
+	}
+	hash, err := hashSecret(user.Password)
+	if err != nil {
+		return err
+	}
+	if err := d.repo.SavePasswordHistory(ctx, user.UID, hash); err != nil {
+		return fmt.Errorf("save password history failed: %v", err)
+	}
+	return nil
+}
+
+// recordLoginFailure 累计登录失败次数，超过阈值时锁定账号，返回本次是否触发了锁定。
+// 失败次数在存储中原子累计并以累计后的值判断，并发的失败登录不会基于同一个旧值计数而绕过锁定
+func (d *UserUsecase) recordLoginFailure(ctx context.Context, user *User, policy *LockoutPolicy, now time.Time) (locked bool, err error) {
+	if !policy.Enabled() || !user.IsPasswordPolicyApplicable() {
+		return false, nil
+	}
+	window := time.Duration(policy.WindowMinutes) * time.Minute
+	count, failedAt, err := d.repo.IncreaseUserLoginFailedCount(ctx, user.UID, now, now.Add(-window))
+	if err != nil {
+		return false, fmt.Errorf("increase user login failed count failed: %v", err)
+	}
+	user.LoginFailedCount = count
+	user.LoginFailedAt = failedAt
+	if count < policy.MaxFailedAttempts {
+		return false, nil
+	}
+
+	lockedUntil := now.Add(time.Duration(policy.DurationMinutes) * time.Minute)
+	if err := d.repo.LockUserLogin(ctx, user.UID, policy.MaxFailedAttempts, lockedUntil); err != nil {
+		return false, fmt.Errorf("lock user login failed: %v", err)
+	}
+	user.LockedUntil = lockedUntil
+	user.LoginFailedCount = 0
+	user.LoginFailedAt = time.Time{}
+	return true, nil
+}
+
+func (d *UserUsecase) resetLoginFailure(ctx context.Context, user *User) error {
+	if user.LoginFailedCount == 0 && user.LoginFailedAt.IsZero() && user.LockedUntil.IsZero() {
+		return nil
+	}
+	user.LoginFailedCount = 0
+	user.LoginFailedAt = time.Time{}
+	user.LockedUntil = time.Time{}
+	if err := d.repo.UpdateUser(ctx, user); err != nil {
+		return fmt.Errorf("reset user login failure failed: %v", err)
+	}
+	return nil
+}
+
+// UnlockUser 由管理员解除因登录失败次数过多导致的账号锁定
+func (d *UserUsecase) UnlockUser(ctx context.Context, currentUserUid, userUid string) (err error) {
+	if canGlobalOp, err := d.OpPermissionVerifyUsecase.CanOpGlobal(ctx, currentUserUid, false); err != nil {
+		return fmt.Errorf("check user is admin or global management permission : %v", err)
+	} else if !canGlobalOp {
+		return fmt.Errorf("user is not admin or global management permission")
+	}
+
+	user, err := d.GetUser(ctx, userUid)
+	if err != nil {
+		return fmt.Errorf("get user failed: %v", err)
+	}
+	return d.resetLoginFailure(ctx, user)
+}
+
+// IsUserPasswordExpired 判断用户密码是否已超过密码策略中的最长有效期
+func (d *UserUsecase) IsUserPasswordExpired(ctx context.Context, userUid string) (bool, error) {
+	loginC, err := d.loginConfigurationUsecase.getCachedLoginConfiguration(ctx)
+	if err != nil {
+		return false, err
+	}
+	policy := loginC.GetPasswordPolicy()
+	if policy.MaxAgeDays == 0 {
+		return false, nil
+	}
+	user, err := d.GetUser(ctx, userUid)
+	if err != nil {
+		return false, err
+	}
+	return policy.IsExpired(user, time.Now()), nil
+}
+
+// CheckPasswordExpired 密码过期的用户登录后只允许访问修改密码、获取当前用户及登出等接口，
+// allowedRoutes 的格式为 "METHOD /path"，如 "PUT /v1/dms/users"
+func (d *UserUsecase) CheckPasswordExpired(gatewayForwardedHeader string, allowedRoutes ...string) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			if c.Request().Header.Get(gatewayForwardedHeader) != "" {
+				return next(c)
+			}
+			route := c.Request().Method + " " + c.Request().URL.Path
+			for _, allowed := range allowedRoutes {
+				if route == allowed {
+					return next(c)
+				}
+			}
+
+			tokenDetail, err := jwtPkg.GetTokenDetailFromContext(c)
+			if err != nil {
+				return echo.NewHTTPError(http.StatusUnauthorized, fmt.Sprintf("get token detail failed, err:%v", err))
+			}
+			if tokenDetail.UID == "" || tokenDetail.UID == pkgConst.UIDOfUserSys ||
+				tokenDetail.LoginType == AccessTokenLogin || tokenDetail.LoginType == ServiceAccountLogin {
+				return next(c)
+			}
+
+			expired, err := d.IsUserPasswordExpired(c.Request().Context(), tokenDetail.UID)
+			if err != nil {
+				return err
+			}
+			if expired {
+				return echo.NewHTTPError(http.StatusForbidden, ErrPasswordExpired.Error())
+			}
+			return next(c)
+		}
+	}
+}
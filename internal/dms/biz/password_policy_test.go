@@ -0,0 +1,114 @@
+package biz
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPasswordPolicyCheckComplexity(t *testing.T) {
+	policy := &PasswordPolicy{
+		MinLength:        8,
+		RequireUppercase: true,
+		RequireLowercase: true,
+		RequireDigit:     true,
+		RequireSpecial:   true,
+	}
+
+	cases := []struct {
+		name     string
+		password string
+		wantErr  bool
+	}{
+		{name: "valid", password: "Abcdef1!", wantErr: false},
+		{name: "too_short", password: "Ab1!", wantErr: true},
+		{name: "missing_uppercase", password: "abcdef1!", wantErr: true},
+		{name: "missing_lowercase", password: "ABCDEF1!", wantErr: true},
+		{name: "missing_digit", password: "Abcdefg!", wantErr: true},
+		{name: "missing_special", password: "Abcdefg1", wantErr: true},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := policy.CheckComplexity(tc.password)
+			assert.Equal(t, tc.wantErr, err != nil, "case: %s, err: %v", tc.name, err)
+		})
+	}
+
+	assert.NoError(t, (&PasswordPolicy{}).CheckComplexity("a"), "empty policy should accept any password")
+}
+
+func TestPasswordPolicyIsExpired(t *testing.T) {
+	now := time.Now()
+	policy := &PasswordPolicy{MaxAgeDays: 90}
+
+	assert.False(t, policy.IsExpired(&User{UserAuthenticationType: UserAuthenticationTypeDMS, PasswordChangedAt: now.Add(-89 * 24 * time.Hour)}, now))
+	assert.True(t, policy.IsExpired(&User{UserAuthenticationType: UserAuthenticationTypeDMS, PasswordChangedAt: now.Add(-91 * 24 * time.Hour)}, now))
+	// 未记录修改时间时以创建时间为准
+	assert.True(t, policy.IsExpired(&User{Base: Base{CreatedAt: now.Add(-91 * 24 * time.Hour)}, UserAuthenticationType: UserAuthenticationTypeDMS}, now))
+	// LDAP 用户不受密码有效期约束
+	assert.False(t, policy.IsExpired(&User{UserAuthenticationType: UserAuthenticationTypeLDAP, PasswordChangedAt: now.Add(-91 * 24 * time.Hour)}, now))
+	assert.False(t, (&PasswordPolicy{}).IsExpired(&User{UserAuthenticationType: UserAuthenticationTypeDMS, PasswordChangedAt: now.Add(-1000 * 24 * time.Hour)}, now))
+}
+
+func TestPasswordHistoryHash(t *testing.T) {
+	hash, err := hashSecret("Abcdef1!")
+	assert.NoError(t, err)
+	assert.True(t, matchSecretHash(hash, "Abcdef1!"))
+	assert.False(t, matchSecretHash(hash, "Abcdef2!"))
+	assert.False(t, matchSecretHash("invalid", "Abcdef1!"))
+
+	another, err := hashSecret("Abcdef1!")
+	assert.NoError(t, err)
+	assert.NotEqual(t, hash, another, "hash should be salted")
+}
+
+func TestRecordLoginFailure(t *testing.T) {
+	user := &User{UID: "user_1", UserAuthenticationType: UserAuthenticationTypeDMS}
+	userRepo := &mockUserRepoForUpdate{mockUserRepo: mockUserRepo{users: map[string]*User{"user_1": user}}}
+	userUsecase := &UserUsecase{repo: userRepo}
+	policy := &LockoutPolicy{MaxFailedAttempts: 3, WindowMinutes: 15, DurationMinutes: 30}
+	now := time.Now()
+
+	for i := 0; i < 2; i++ {
+		locked, err := userUsecase.recordLoginFailure(context.Background(), user, policy, now)
+		assert.NoError(t, err)
+		assert.False(t, locked)
+	}
+	assert.Equal(t, uint(2), user.LoginFailedCount)
+
+	// 超出统计窗口后重新计数
+	later := now.Add(16 * time.Minute)
+	locked, err := userUsecase.recordLoginFailure(context.Background(), user, policy, later)
+	assert.NoError(t, err)
+	assert.False(t, locked)
+	assert.Equal(t, uint(1), user.LoginFailedCount)
+
+	for i := 0; i < 2; i++ {
+		locked, err = userUsecase.recordLoginFailure(context.Background(), user, policy, later)
+		assert.NoError(t, err)
+	}
+	assert.True(t, locked)
+	assert.True(t, user.IsLocked(later))
+	assert.False(t, user.IsLocked(later.Add(31*time.Minute)))
+
+	assert.NoError(t, userUsecase.resetLoginFailure(context.Background(), user))
+	assert.False(t, user.IsLocked(later))
+
+	// 未开启锁定策略时不记录失败次数
+	otherUser := &User{UID: "user_2", UserAuthenticationType: UserAuthenticationTypeDMS}
+	locked, err = userUsecase.recordLoginFailure(context.Background(), otherUser, &LockoutPolicy{}, now)
+	assert.NoError(t, err)
+	assert.False(t, locked)
+	assert.Equal(t, uint(0), otherUser.LoginFailedCount)
+
+	// 以存储中累计后的次数判断锁定，而不是登录时读到的旧值
+	stored := &User{UID: "user_3", UserAuthenticationType: UserAuthenticationTypeDMS, LoginFailedCount: 2, LoginFailedAt: now}
+	userRepo.users["user_3"] = stored
+	stale := &User{UID: "user_3", UserAuthenticationType: UserAuthenticationTypeDMS}
+	locked, err = userUsecase.recordLoginFailure(context.Background(), stale, policy, now)
+	assert.NoError(t, err)
+	assert.True(t, locked)
+	assert.True(t, stored.IsLocked(now))
+}
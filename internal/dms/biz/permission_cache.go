@@ -0,0 +1,271 @@
+package biz
+
+import (
+	"context"
+	"slices"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	utilLog "github.com/actiontech/dms/pkg/dms-common/pkg/log"
+)
+
+const (
+	// defaultPermissionCacheTTL 兜底的过期时间，覆盖未显式失效的变更（如直接修改数据库）
+	defaultPermissionCacheTTL = time.Minute
+	// defaultPermissionCacheMaxEntries 超过上限时清空缓存，避免用户、项目过多时无限增长
+	defaultPermissionCacheMaxEntries = 100000
+)
+
+// PermissionCacheVersionRepo 集群共享的权限版本号，任一节点的权限变更都会递增该版本号
+type PermissionCacheVersionRepo interface {
+	GetPermissionCacheVersion(ctx context.Context) (uint64, error)
+	IncrPermissionCacheVersion(ctx context.Context) error
+}
+
+type PermissionCacheStats struct {
+	Hits          uint64
+	Misses        uint64
+	Invalidations uint64
+	Entries       int
+}
+
+func (s PermissionCacheStats) HitRatio() float64 {
+	if s.Hits+s.Misses == 0 {
+		return 0
+	}
+	return float64(s.Hits) / float64(s.Hits+s.Misses)
+}
+
+// permissionCacheVersion 缓存项写入时的版本，任一版本变化即视为失效
+type permissionCacheVersion struct {
+	global  uint64
+	user    uint64
+	project uint64
+}
+
+type permissionCacheEntry struct {
+	version   permissionCacheVersion
+	expiresAt time.Time
+	value     any
+}
+
+// PermissionCache 进程内的用户权限缓存，按用户、项目维护版本号实现失效：
+// 成员、成员组变更递增项目版本，用户变更递增用户版本，角色、项目变更递增全局版本。
+// 不区分项目的缓存项（如用户在所有项目的权限）依赖 crossProject 版本，任一项目变更都会使其失效。
+type PermissionCache struct {
+	mu              sync.RWMutex
+	entries         map[string]*permissionCacheEntry
+	global          uint64
+	crossProject    uint64
+	userVersions    map[string]uint64
+	projectVersions map[string]uint64
+
+	ttl        time.Duration
+	maxEntries int
+
+	hits          atomic.Uint64
+	misses        atomic.Uint64
+	invalidations atomic.Uint64
+
+	versionRepo    PermissionCacheVersionRepo
+	clusterUsecase *ClusterUsecase
+	clusterVersion atomic.Uint64
+	log            *utilLog.Helper
+}
+
+func NewPermissionCache(log utilLog.Logger, versionRepo PermissionCacheVersionRepo, clusterUsecase *ClusterUsecase) *PermissionCache {
+	return &PermissionCache{
+		entries:         make(map[string]*permissionCacheEntry),
+		userVersions:    make(map[string]uint64),
+		projectVersions: make(map[string]uint64),
+		ttl:             defaultPermissionCacheTTL,
+		maxEntries:      defaultPermissionCacheMaxEntries,
+		versionRepo:     versionRepo,
+		clusterUsecase:  clusterUsecase,
+		log:             utilLog.NewHelper(log, utilLog.WithMessageKey("biz.permission_cache")),
+	}
+}
+
+// version 在读取数据库之前获取，读取期间发生的失效会使写入的缓存项立即过期
+func (c *PermissionCache) version(userUid, projectUid string) permissionCacheVersion {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	v := permissionCacheVersion{global: c.global, user: c.userVersions[userUid]}
+	if projectUid == "" {
+		v.project = c.crossProject
+	} else {
+		v.project = c.projectVersions[projectUid]
+	}
+	return v
+}
+
+func (c *PermissionCache) get(key, userUid, projectUid string) (any, bool) {
+	current := c.version(userUid, projectUid)
+	c.mu.RLock()
+	entry, ok := c.entries[key]
+	c.mu.RUnlock()
+	if !ok || entry.version != current || time.Now().After(entry.expiresAt) {
+		c.misses.Add(1)
+		return nil, false
+	}
+	c.hits.Add(1)
+	return entry.value, true
+}
+
+func (c *PermissionCache) set(key string, version permissionCacheVersion, value any) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if len(c.entries) >= c.maxEntries {
+		c.entries = make(map[string]*permissionCacheEntry)
+	}
+	c.entries[key] = &permissionCacheEntry{version: version, expiresAt: time.Now().Add(c.ttl), value: value}
+}
+
+func (c *PermissionCache) InvalidateUser(ctx context.Context, userUid string) {
+	c.mu.Lock()
+	c.userVersions[userUid]++
+	c.mu.Unlock()
+	c.invalidated(ctx)
+}
+
+func (c *PermissionCache) InvalidateProject(ctx context.Context, projectUid string) {
+	c.mu.Lock()
+	c.projectVersions[projectUid]++
+	c.crossProject++
+	c.mu.Unlock()
+	c.invalidated(ctx)
+}
+
+func (c *PermissionCache) InvalidateAll(ctx context.Context) {
+	c.flush()
+	c.invalidated(ctx)
+}
+
+func (c *PermissionCache) flush() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.global++
+	c.entries = make(map[string]*permissionCacheEntry)
+}
+
+// invalidated 集群模式下递增共享版本号，其他节点在下次同步时清空本地缓存
+func (c *PermissionCache) invalidated(ctx context.Context) {
+	c.invalidations.Add(1)
+	if !c.clusterUsecase.IsClusterMode() {
+		return
+	}
+	if err := c.versionRepo.IncrPermissionCacheVersion(ctx); err != nil {
+		c.log.Errorf("increase permission cache version failed: %v", err)
+	}
+}
+
+// SyncClusterVersion 定时检查共享版本号，发现其他节点有权限变更时清空本地缓存
+func (c *PermissionCache) SyncClusterVersion() {
+	if !c.clusterUsecase.IsClusterMode() {
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	version, err := c.versionRepo.GetPermissionCacheVersion(ctx)
+	if err != nil {
+		c.log.Errorf("get permission cache version failed: %v", err)
+		return
+	}
+	if c.clusterVersion.Swap(version) != version {
+		c.flush()
+	}
+}
+
+func (c *PermissionCache) Stats() PermissionCacheStats {
+	c.mu.RLock()
+	entries := len(c.entries)
+	c.mu.RUnlock()
+	return PermissionCacheStats{
+		Hits:          c.hits.Load(),
+		Misses:        c.misses.Load(),
+		Invalidations: c.invalidations.Load(),
+		Entries:       entries,
+	}
+}
+
+// cachedPermission 返回的切片为副本，调用方修改结果不会影响缓存
+func cachedPermission[T any](c *PermissionCache, key, userUid, projectUid string, load func() ([]T, error)) ([]T, error) {
+	if value, ok := c.get(key, userUid, projectUid); ok {
+		return slices.Clone(value.([]T)), nil
+	}
+	version := c.version(userUid, projectUid)
+	ret, err := load()
+	if err != nil {
+		return nil, err
+	}
+	c.set(key, version, slices.Clone(ret))
+	return ret, nil
+}
+
+var _ OpPermissionVerifyRepo = (*cachedOpPermissionVerifyRepo)(nil)
+
+// cachedOpPermissionVerifyRepo 缓存按用户查询权限的方法，按项目列出成员等低频查询直接访问数据库
+type cachedOpPermissionVerifyRepo struct {
+	OpPermissionVerifyRepo
+	cache *PermissionCache
+}
+
+func (r *cachedOpPermissionVerifyRepo) IsUserHasOpPermissionInProject(ctx context.Context, userUid, projectUid, opPermissionUid string) (bool, error) {
+	ret, err := cachedPermission(r.cache, "has/"+userUid+"/"+projectUid+"/"+opPermissionUid, userUid, projectUid, func() ([]bool, error) {
+		has, err := r.OpPermissionVerifyRepo.IsUserHasOpPermissionInProject(ctx, userUid, projectUid, opPermissionUid)
+		return []bool{has}, err
+	})
+	if err != nil {
+		return false, err
+	}
+	return ret[0], nil
+}
+
+func (r *cachedOpPermissionVerifyRepo) GetUserOpPermissionInProject(ctx context.Context, userUid, projectUid string) ([]OpPermissionWithOpRange, error) {
+	return cachedPermission(r.cache, "in_project/"+userUid+"/"+projectUid, userUid, projectUid, func() ([]OpPermissionWithOpRange, error) {
+		return r.OpPermissionVerifyRepo.GetUserOpPermissionInProject(ctx, userUid, projectUid)
+	})
+}
+
+func (r *cachedOpPermissionVerifyRepo) GetOneOpPermissionInProject(ctx context.Context, userUid, projectUid, permissionId string) ([]OpPermissionWithOpRange, error) {
+	return cachedPermission(r.cache, "one_in_project/"+userUid+"/"+projectUid+"/"+permissionId, userUid, projectUid, func() ([]OpPermissionWithOpRange, error) {
+		return r.OpPermissionVerifyRepo.GetOneOpPermissionInProject(ctx, userUid, projectUid, permissionId)
+	})
+}
+
+func (r *cachedOpPermissionVerifyRepo) GetUserProjectOpPermissionInProject(ctx context.Context, userUid, projectUid string) ([]OpPermissionWithOpRange, error) {
+	return cachedPermission(r.cache, "project_in_project/"+userUid+"/"+projectUid, userUid, projectUid, func() ([]OpPermissionWithOpRange, error) {
+		return r.OpPermissionVerifyRepo.GetUserProjectOpPermissionInProject(ctx, userUid, projectUid)
+	})
+}
+
+func (r *cachedOpPermissionVerifyRepo) GetUserOpPermission(ctx context.Context, userUid string) ([]OpPermissionWithOpRange, error) {
+	return cachedPermission(r.cache, "all/"+userUid, userUid, "", func() ([]OpPermissionWithOpRange, error) {
+		return r.OpPermissionVerifyRepo.GetUserOpPermission(ctx, userUid)
+	})
+}
+
+func (r *cachedOpPermissionVerifyRepo) GetUserProjectOpPermission(ctx context.Context, userUid string) ([]OpPermissionWithOpRange, error) {
+	return cachedPermission(r.cache, "project_all/"+userUid, userUid, "", func() ([]OpPermissionWithOpRange, error) {
+		return r.OpPermissionVerifyRepo.GetUserProjectOpPermission(ctx, userUid)
+	})
+}
+
+func (r *cachedOpPermissionVerifyRepo) GetUserGlobalOpPermission(ctx context.Context, userUid string) ([]*OpPermission, error) {
+	return cachedPermission(r.cache, "global/"+userUid, userUid, "", func() ([]*OpPermission, error) {
+		return r.OpPermissionVerifyRepo.GetUserGlobalOpPermission(ctx, userUid)
+	})
+}
+
+func (r *cachedOpPermissionVerifyRepo) GetUserProjectWithOpPermissions(ctx context.Context, userUid string) ([]ProjectOpPermissionWithOpRange, error) {
+	return cachedPermission(r.cache, "projects_with_op/"+userUid, userUid, "", func() ([]ProjectOpPermissionWithOpRange, error) {
+		return r.OpPermissionVerifyRepo.GetUserProjectWithOpPermissions(ctx, userUid)
+	})
+}
+
+func (r *cachedOpPermissionVerifyRepo) GetUserProject(ctx context.Context, userUid string) ([]*Project, error) {
+	return cachedPermission(r.cache, "projects/"+userUid, userUid, "", func() ([]*Project, error) {
+		return r.OpPermissionVerifyRepo.GetUserProject(ctx, userUid)
+	})
+}
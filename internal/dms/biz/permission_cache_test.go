@@ -0,0 +1,138 @@
+package biz
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	pkgConst "github.com/actiontech/dms/internal/dms/pkg/constant"
+	"github.com/stretchr/testify/assert"
+)
+
+// slowOpPermissionVerifyRepo 模拟数据库查询的耗时，并统计实际查询次数
+type slowOpPermissionVerifyRepo struct {
+	*mockOpPermissionVerifyRepo
+	latency time.Duration
+	calls   int
+}
+
+func (r *slowOpPermissionVerifyRepo) IsUserHasOpPermissionInProject(ctx context.Context, userUid, projectUid, opPermissionUid string) (bool, error) {
+	r.calls++
+	time.Sleep(r.latency)
+	return r.mockOpPermissionVerifyRepo.IsUserHasOpPermissionInProject(ctx, userUid, projectUid, opPermissionUid)
+}
+
+func newCachedTestOpPermissionVerifyUsecase(repo OpPermissionVerifyRepo) *OpPermissionVerifyUsecase {
+	uc := newTestOpPermissionVerifyUsecase(&mockUserRepo{}, repo)
+	uc.SetPermissionCache(NewPermissionCache(&noopLogger{}, nil, nil))
+	return uc
+}
+
+func TestPermissionCacheInvalidation(t *testing.T) {
+	repo := &slowOpPermissionVerifyRepo{mockOpPermissionVerifyRepo: &mockOpPermissionVerifyRepo{
+		projectPermissions: map[string]map[string]map[string]bool{
+			"user_1": {"p1": {pkgConst.UIDOfOpPermissionProjectAdmin: true}},
+		},
+	}}
+	uc := newCachedTestOpPermissionVerifyUsecase(repo)
+	ctx := context.Background()
+
+	isAdmin := func(projectUid string) bool {
+		ret, err := uc.IsUserProjectAdmin(ctx, "user_1", projectUid, false)
+		assert.NoError(t, err)
+		return ret
+	}
+
+	assert.True(t, isAdmin("p1"))
+	assert.True(t, isAdmin("p1"))
+	assert.Equal(t, 1, repo.calls)
+
+	t.Run("other_project_change_keeps_entry", func(t *testing.T) {
+		uc.InvalidateProjectPermissions(ctx, "p2")
+		assert.True(t, isAdmin("p1"))
+		assert.Equal(t, 1, repo.calls)
+	})
+
+	t.Run("project_change_reloads", func(t *testing.T) {
+		delete(repo.projectPermissions["user_1"], "p1")
+		uc.InvalidateProjectPermissions(ctx, "p1")
+		assert.False(t, isAdmin("p1"))
+		assert.Equal(t, 2, repo.calls)
+	})
+
+	t.Run("user_change_reloads", func(t *testing.T) {
+		repo.projectPermissions["user_1"]["p1"] = map[string]bool{pkgConst.UIDOfOpPermissionProjectAdmin: true}
+		uc.InvalidateUserPermissions(ctx, "user_1")
+		assert.True(t, isAdmin("p1"))
+		assert.Equal(t, 3, repo.calls)
+	})
+
+	t.Run("role_change_reloads", func(t *testing.T) {
+		uc.InvalidateAllPermissions(ctx)
+		assert.True(t, isAdmin("p1"))
+		assert.Equal(t, 4, repo.calls)
+	})
+
+	stats, enabled := uc.PermissionCacheStats()
+	assert.True(t, enabled)
+	assert.Equal(t, uint64(2), stats.Hits)
+	assert.Equal(t, uint64(4), stats.Misses)
+	assert.Equal(t, uint64(4), stats.Invalidations)
+	assert.InDelta(t, 1.0/3, stats.HitRatio(), 0.001)
+}
+
+func BenchmarkIsUserProjectAdmin(b *testing.B) {
+	ctx := context.Background()
+	newRepo := func() *slowOpPermissionVerifyRepo {
+		return &slowOpPermissionVerifyRepo{
+			mockOpPermissionVerifyRepo: &mockOpPermissionVerifyRepo{
+				projectPermissions: map[string]map[string]map[string]bool{
+					"user_1": {"p1": {pkgConst.UIDOfOpPermissionProjectAdmin: true}},
+				},
+			},
+			// 同机房 MySQL 单次查询的典型耗时
+			latency: 500 * time.Microsecond,
+		}
+	}
+
+	b.Run("uncached", func(b *testing.B) {
+		uc := newTestOpPermissionVerifyUsecase(&mockUserRepo{}, newRepo())
+		for i := 0; i < b.N; i++ {
+			_, _ = uc.IsUserProjectAdmin(ctx, "user_1", "p1", false)
+		}
+	})
+
+	b.Run("cached", func(b *testing.B) {
+		uc := newCachedTestOpPermissionVerifyUsecase(newRepo())
+		for i := 0; i < b.N; i++ {
+			_, _ = uc.IsUserProjectAdmin(ctx, "user_1", "p1", false)
+		}
+	})
+}
+
+type mockBusinessProjectRepo struct {
+	ProjectRepo
+}
+
+func (m *mockBusinessProjectRepo) UpdateDBServiceBusiness(context.Context, string, string, string) error {
+	return nil
+}
+
+func TestProjectChangeInvalidatesPermissionCache(t *testing.T) {
+	uc := newCachedTestOpPermissionVerifyUsecase(&mockOpPermissionVerifyRepo{
+		projectPermissions: map[string]map[string]map[string]bool{
+			"user_1": {"p1": {pkgConst.UIDOfOpPermissionProjectAdmin: true}},
+		},
+	})
+	projectUC := &ProjectUsecase{repo: &mockBusinessProjectRepo{}, opPermissionVerifyUsecase: uc}
+	ctx := context.Background()
+
+	assert.NoError(t, projectUC.UpdateDBServiceBusiness(ctx, "user_1", "p1", "a", "b"))
+	stats, _ := uc.PermissionCacheStats()
+	assert.Equal(t, uint64(1), stats.Invalidations)
+
+	// 操作失败时不需要失效缓存
+	assert.Error(t, projectUC.UpdateDBServiceBusiness(ctx, "user_2", "p1", "a", "b"))
+	stats, _ = uc.PermissionCacheStats()
+	assert.Equal(t, uint64(1), stats.Invalidations)
+}
@@ -0,0 +1,281 @@
+package biz
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	pkgConst "github.com/actiontech/dms/internal/dms/pkg/constant"
+	sqlop "github.com/actiontech/dms/pkg/dms-common/sql_op"
+)
+
+// OpPermissionSourceType 描述项目内权限的来源
+type OpPermissionSourceType string
+
+const (
+	OpPermissionSourceTypeMember      OpPermissionSourceType = "member"
+	OpPermissionSourceTypeMemberGroup OpPermissionSourceType = "member_group"
+)
+
+// OpPermissionSource 用户在项目内的一条权限及其来源，RoleUID 为空表示来自成员/成员组的项目管理权限槽
+type OpPermissionSource struct {
+	SourceType OpPermissionSourceType
+	SourceUID  string
+	SourceName string
+	RoleUID    string
+	RoleName   string
+	OpPermissionWithOpRange
+}
+
+type PermissionExplainRule string
+
+const (
+	PermissionExplainRuleUser                         PermissionExplainRule = "user"
+	PermissionExplainRuleGlobalOp                     PermissionExplainRule = "global_op"
+	PermissionExplainRuleBusinessWrite                PermissionExplainRule = "business_write"
+	PermissionExplainRuleProjectAdmin                 PermissionExplainRule = "project_admin"
+	PermissionExplainRuleMemberRole                   PermissionExplainRule = "member_role"
+	PermissionExplainRuleMemberGroupRole              PermissionExplainRule = "member_group_role"
+	PermissionExplainRuleMemberProjectPermission      PermissionExplainRule = "member_project_permission"
+	PermissionExplainRuleMemberGroupProjectPermission PermissionExplainRule = "member_group_project_permission"
+	PermissionExplainRuleRange                        PermissionExplainRule = "range"
+)
+
+type PermissionExplainResult string
+
+const (
+	PermissionExplainResultMatched    PermissionExplainResult = "matched"
+	PermissionExplainResultNotMatched PermissionExplainResult = "not_matched"
+)
+
+// PermissionExplainStep 权限判定过程中的一步，Source 为空表示该步不来自项目内的某条授权
+type PermissionExplainStep struct {
+	Rule   PermissionExplainRule
+	Result PermissionExplainResult
+	Detail string
+	Source *OpPermissionSource
+}
+
+type PermissionExplanation struct {
+	Allowed bool
+	Steps   []*PermissionExplainStep
+}
+
+func (e *PermissionExplanation) addStep(rule PermissionExplainRule, matched bool, source *OpPermissionSource, format string, args ...interface{}) {
+	result := PermissionExplainResultNotMatched
+	if matched {
+		result = PermissionExplainResultMatched
+	}
+	e.Steps = append(e.Steps, &PermissionExplainStep{
+		Rule:   rule,
+		Result: result,
+		Detail: fmt.Sprintf(format, args...),
+		Source: source,
+	})
+}
+
+type ExplainPermissionArgs struct {
+	UserUID         string
+	ProjectUID      string
+	DBServiceUID    string
+	OpPermissionUID string
+	// BusinessWrite 为 true 时按业务写操作判定，系统管理员需开启业务写权才能通过全局身份放行
+	BusinessWrite bool
+	// Objects 访问的数据源内对象，按库、模式、表级别的范围判定时使用
+	Objects []*sqlop.SQLObject
+}
+
+// ExplainPermission 按 CanOpProject、IsUserProjectAdmin 及 UserCanOpDBWithObjects 的判定顺序，
+// 给出用户在项目（及数据源）上是否具备某权限，并记录每条规则的命中情况
+func (o *OpPermissionVerifyUsecase) ExplainPermission(ctx context.Context, args *ExplainPermissionArgs) (*PermissionExplanation, error) {
+	e := &PermissionExplanation{Steps: make([]*PermissionExplainStep, 0)}
+
+	user, err := o.userRepo.GetUser(ctx, args.UserUID)
+	if err != nil {
+		return nil, fmt.Errorf("get user failed: %w", err)
+	}
+	if user.Stat != UserStatOK {
+		e.addStep(PermissionExplainRuleUser, false, nil, "user %s is disabled", user.Name)
+		return e, nil
+	}
+	e.addStep(PermissionExplainRuleUser, true, nil, "user %s is active", user.Name)
+
+	// 全局身份：内置管理员或拥有系统管理员权限
+	isDMSAdmin, err := o.IsUserDMSAdmin(ctx, args.UserUID)
+	if err != nil {
+		return nil, err
+	}
+	globalOps, err := o.repo.GetUserGlobalOpPermission(ctx, args.UserUID)
+	if err != nil {
+		return nil, fmt.Errorf("get user global op permission failed: %v", err)
+	}
+	hasGlobalManagement := false
+	for _, op := range globalOps {
+		if op.UID == pkgConst.UIDOfOpPermissionGlobalManagement {
+			hasGlobalManagement = true
+			break
+		}
+	}
+	switch {
+	case isDMSAdmin:
+		e.addStep(PermissionExplainRuleGlobalOp, true, nil, "user is a built-in administrator")
+	case hasGlobalManagement:
+		e.addStep(PermissionExplainRuleGlobalOp, true, nil, "user has the global management permission")
+	default:
+		e.addStep(PermissionExplainRuleGlobalOp, false, nil, "user has no global management permission")
+	}
+	if isDMSAdmin || hasGlobalManagement {
+		if !args.BusinessWrite {
+			e.Allowed = true
+			return e, nil
+		}
+		if user.BusinessWritePermission {
+			e.addStep(PermissionExplainRuleBusinessWrite, true, nil, "business write permission is enabled")
+			e.Allowed = true
+			return e, nil
+		}
+		e.addStep(PermissionExplainRuleBusinessWrite, false, nil, "business write permission is disabled, global privilege is not applied to business write operations")
+	}
+
+	sources, err := o.repo.ListUserOpPermissionSourcesInProject(ctx, args.UserUID, args.ProjectUID)
+	if err != nil {
+		return nil, fmt.Errorf("list user op permission sources failed: %v", err)
+	}
+
+	// 项目管理员拥有项目内全部权限
+	for i := range sources {
+		if sources[i].OpPermissionUID == pkgConst.UIDOfOpPermissionProjectAdmin {
+			e.addStep(PermissionExplainRuleProjectAdmin, true, &sources[i], "user is project admin via %s", describeOpPermissionSource(&sources[i]))
+			e.Allowed = true
+			return e, nil
+		}
+	}
+	e.addStep(PermissionExplainRuleProjectAdmin, false, nil, "user is not project admin")
+
+	resolved := make(map[string][]string)
+	objectGrants := make([]OpPermissionWithOpRange, 0)
+	granted := false
+	for i := range sources {
+		source := &sources[i]
+		if source.OpPermissionUID != args.OpPermissionUID {
+			continue
+		}
+		granted = true
+		e.addStep(sourceExplainRule(source), true, source, "permission is granted via %s", describeOpPermissionSource(source))
+
+		covered, detail, err := o.explainRange(ctx, source, args.DBServiceUID, args.Objects, resolved)
+		if err != nil {
+			return nil, err
+		}
+		e.addStep(PermissionExplainRuleRange, covered, source, "%s", detail)
+		if covered {
+			e.Allowed = true
+			return e, nil
+		}
+		if source.OpRangeType.IsDBObject() {
+			objectGrants = append(objectGrants, source.OpPermissionWithOpRange)
+		}
+	}
+	if !granted {
+		e.addStep(PermissionExplainRuleMemberRole, false, nil, "no member role, member group or project permission grants %s", args.OpPermissionUID)
+		return e, nil
+	}
+	// 访问的对象可能分别由多条对象范围的授权覆盖
+	if len(objectGrants) > 1 && args.DBServiceUID != "" && len(args.Objects) > 0 {
+		denied := o.UserCanOpDBObjects(objectGrants, []string{args.OpPermissionUID}, args.DBServiceUID, args.Objects)
+		if len(denied) == 0 {
+			e.addStep(PermissionExplainRuleRange, true, nil, "all objects are covered by the object ranges granted above")
+			e.Allowed = true
+			return e, nil
+		}
+		e.addStep(PermissionExplainRuleRange, false, nil, "objects %s are not covered by the object ranges granted above", describeSQLObjects(denied))
+	}
+	return e, nil
+}
+
+// explainRange 与 UserCanOpDBWithObjects 的范围判定保持一致，dbServiceUid 为空时只判定项目内是否拥有该权限
+func (o *OpPermissionVerifyUsecase) explainRange(ctx context.Context, source *OpPermissionSource, dbServiceUid string, objects []*sqlop.SQLObject, resolved map[string][]string) (bool, string, error) {
+	if dbServiceUid == "" {
+		return true, fmt.Sprintf("no db service specified, %s range %v applies", source.OpRangeType, source.RangeUIDs), nil
+	}
+	switch source.OpRangeType {
+	case OpRangeTypeProject:
+		return true, "project range covers all db services in the project", nil
+	case OpRangeTypeDBService:
+		for _, uid := range source.RangeUIDs {
+			if uid == dbServiceUid {
+				return true, fmt.Sprintf("db service %s is in range %v", dbServiceUid, source.RangeUIDs), nil
+			}
+		}
+		return false, fmt.Sprintf("db service %s is not in range %v", dbServiceUid, source.RangeUIDs), nil
+	case OpRangeTypeDBServiceSelector:
+		uids, err := o.resolveDBServiceSelector(ctx, source.ProjectUID, source.RangeUIDs, resolved)
+		if err != nil {
+			return false, "", err
+		}
+		for _, uid := range uids {
+			if uid == dbServiceUid {
+				return true, fmt.Sprintf("db service %s matches selectors %v", dbServiceUid, source.RangeUIDs), nil
+			}
+		}
+		return false, fmt.Sprintf("db service %s does not match selectors %v, currently resolved to %v", dbServiceUid, source.RangeUIDs, uids), nil
+	case OpRangeTypeDatabase, OpRangeTypeSchema, OpRangeTypeTable:
+		if !objectRangesInDBService(source.OpPermissionWithOpRange, dbServiceUid) {
+			return false, fmt.Sprintf("no %s object of db service %s is in range", source.OpRangeType, dbServiceUid), nil
+		}
+		if len(objects) == 0 {
+			return false, fmt.Sprintf("granted on %s objects of db service %s only, which does not cover the whole db service, specify the accessed objects to check them", source.OpRangeType, dbServiceUid), nil
+		}
+		denied := o.UserCanOpDBObjects([]OpPermissionWithOpRange{source.OpPermissionWithOpRange}, []string{source.OpPermissionUID}, dbServiceUid, objects)
+		if len(denied) == 0 {
+			return true, fmt.Sprintf("all objects are in %s range of db service %s", source.OpRangeType, dbServiceUid), nil
+		}
+		return false, fmt.Sprintf("objects %s are not in %s range of db service %s", describeSQLObjects(denied), source.OpRangeType, dbServiceUid), nil
+	default:
+		return false, fmt.Sprintf("unsupported range type %s", source.OpRangeType), nil
+	}
+}
+
+// describeSQLObjects 以 database.schema.table 的形式描述对象，为空的层级省略
+func describeSQLObjects(objects []*sqlop.SQLObject) string {
+	names := make([]string, 0, len(objects))
+	for _, object := range objects {
+		parts := make([]string, 0, 3)
+		for _, name := range []string{object.DatabaseName, object.SchemaName, object.TableName} {
+			if name != "" {
+				parts = append(parts, name)
+			}
+		}
+		if len(parts) == 0 {
+			parts = append(parts, string(object.Type))
+		}
+		names = append(names, strings.Join(parts, "."))
+	}
+	return "[" + strings.Join(names, ", ") + "]"
+}
+
+func sourceExplainRule(source *OpPermissionSource) PermissionExplainRule {
+	switch {
+	case source.SourceType == OpPermissionSourceTypeMemberGroup && source.RoleUID == "":
+		return PermissionExplainRuleMemberGroupProjectPermission
+	case source.SourceType == OpPermissionSourceTypeMemberGroup:
+		return PermissionExplainRuleMemberGroupRole
+	case source.RoleUID == "":
+		return PermissionExplainRuleMemberProjectPermission
+	default:
+		return PermissionExplainRuleMemberRole
+	}
+}
+
+func describeOpPermissionSource(source *OpPermissionSource) string {
+	var from string
+	if source.SourceType == OpPermissionSourceTypeMemberGroup {
+		from = fmt.Sprintf("member group %s", source.SourceName)
+	} else {
+		from = "member"
+	}
+	if source.RoleUID == "" {
+		return fmt.Sprintf("project permission of %s", from)
+	}
+	return fmt.Sprintf("role %s of %s", source.RoleName, from)
+}
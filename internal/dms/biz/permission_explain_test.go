@@ -0,0 +1,164 @@
+package biz
+
+import (
+	"context"
+	"testing"
+
+	pkgConst "github.com/actiontech/dms/internal/dms/pkg/constant"
+	sqlop "github.com/actiontech/dms/pkg/dms-common/sql_op"
+	"github.com/stretchr/testify/assert"
+)
+
+func explainRules(e *PermissionExplanation) []PermissionExplainRule {
+	rules := make([]PermissionExplainRule, 0, len(e.Steps))
+	for _, step := range e.Steps {
+		rules = append(rules, step.Rule)
+	}
+	return rules
+}
+
+func TestExplainPermission(t *testing.T) {
+	exportCreate := pkgConst.UIDOfOpPermissionExportCreate
+	users := map[string]*User{
+		"user_1":                   {UID: "user_1", Name: "u1", Stat: UserStatOK},
+		"user_disabled":            {UID: "user_disabled", Name: "u2", Stat: UserStatDisable},
+		pkgConst.UIDOfUserAdmin:    {UID: pkgConst.UIDOfUserAdmin, Name: "admin", Stat: UserStatOK, BusinessWritePermission: false},
+		"user_project_admin_group": {UID: "user_project_admin_group", Name: "u3", Stat: UserStatOK},
+	}
+	repo := &mockOpPermissionVerifyRepo{
+		selectorDBServices: map[string][]string{"project_1/environment_tag=prod": {"db_2"}},
+		permissionSources: map[string][]OpPermissionSource{
+			"user_1/project_1": {
+				{
+					SourceType: OpPermissionSourceTypeMember, SourceUID: "member_1", RoleUID: "role_1", RoleName: "exporter",
+					OpPermissionWithOpRange: OpPermissionWithOpRange{OpPermissionUID: exportCreate, OpRangeType: OpRangeTypeDBService, RangeUIDs: []string{"db_1"}, ProjectUID: "project_1"},
+				},
+				{
+					SourceType: OpPermissionSourceTypeMemberGroup, SourceUID: "group_1", SourceName: "prod", RoleUID: "role_1", RoleName: "exporter",
+					OpPermissionWithOpRange: OpPermissionWithOpRange{OpPermissionUID: exportCreate, OpRangeType: OpRangeTypeDBServiceSelector, RangeUIDs: []string{"environment_tag=prod"}, ProjectUID: "project_1"},
+				},
+			},
+			"user_project_admin_group/project_1": {
+				{
+					SourceType: OpPermissionSourceTypeMemberGroup, SourceUID: "group_2", SourceName: "admins", RoleUID: pkgConst.UIDOfRoleProjectAdmin, RoleName: "project admin",
+					OpPermissionWithOpRange: OpPermissionWithOpRange{OpPermissionUID: pkgConst.UIDOfOpPermissionProjectAdmin, OpRangeType: OpRangeTypeProject, RangeUIDs: []string{"project_1"}, ProjectUID: "project_1"},
+				},
+			},
+		},
+	}
+	uc := newTestOpPermissionVerifyUsecase(&mockUserRepo{users: users}, repo)
+	ctx := context.Background()
+
+	explain := func(userUid, dbServiceUid string, businessWrite bool) *PermissionExplanation {
+		e, err := uc.ExplainPermission(ctx, &ExplainPermissionArgs{
+			UserUID: userUid, ProjectUID: "project_1", DBServiceUID: dbServiceUid, OpPermissionUID: exportCreate, BusinessWrite: businessWrite,
+		})
+		assert.NoError(t, err)
+		return e
+	}
+
+	t.Run("member_role_db_service_range", func(t *testing.T) {
+		e := explain("user_1", "db_1", false)
+		assert.True(t, e.Allowed)
+		assert.Equal(t, []PermissionExplainRule{PermissionExplainRuleUser, PermissionExplainRuleGlobalOp, PermissionExplainRuleProjectAdmin, PermissionExplainRuleMemberRole, PermissionExplainRuleRange}, explainRules(e))
+	})
+
+	t.Run("member_group_selector_range", func(t *testing.T) {
+		e := explain("user_1", "db_2", false)
+		assert.True(t, e.Allowed)
+		last := e.Steps[len(e.Steps)-1]
+		assert.Equal(t, PermissionExplainRuleRange, last.Rule)
+		assert.Equal(t, OpPermissionSourceTypeMemberGroup, last.Source.SourceType)
+	})
+
+	t.Run("no_range_matched", func(t *testing.T) {
+		e := explain("user_1", "db_3", false)
+		assert.False(t, e.Allowed)
+		assert.Equal(t, PermissionExplainResultNotMatched, e.Steps[len(e.Steps)-1].Result)
+	})
+
+	t.Run("disabled_user", func(t *testing.T) {
+		e := explain("user_disabled", "db_1", false)
+		assert.False(t, e.Allowed)
+		assert.Equal(t, []PermissionExplainRule{PermissionExplainRuleUser}, explainRules(e))
+	})
+
+	t.Run("admin_without_business_write", func(t *testing.T) {
+		assert.True(t, explain(pkgConst.UIDOfUserAdmin, "db_1", false).Allowed)
+		e := explain(pkgConst.UIDOfUserAdmin, "db_1", true)
+		assert.False(t, e.Allowed)
+		assert.Contains(t, explainRules(e), PermissionExplainRuleBusinessWrite)
+	})
+
+	t.Run("project_admin_via_member_group", func(t *testing.T) {
+		e := explain("user_project_admin_group", "db_9", false)
+		assert.True(t, e.Allowed)
+		last := e.Steps[len(e.Steps)-1]
+		assert.Equal(t, PermissionExplainRuleProjectAdmin, last.Rule)
+		assert.Equal(t, "group_2", last.Source.SourceUID)
+	})
+}
+
+func TestExplainPermissionObjectRange(t *testing.T) {
+	sqlQuery := pkgConst.UIDOfOpPermissionSQLQuery
+	ordersRange := sqlop.EncodeObjectRange(&sqlop.ObjectRange{DBServiceUID: "db_1", DatabaseName: "sales", TableName: "orders"})
+	usersRange := sqlop.EncodeObjectRange(&sqlop.ObjectRange{DBServiceUID: "db_1", DatabaseName: "sales", TableName: "users"})
+	repo := &mockOpPermissionVerifyRepo{
+		permissionSources: map[string][]OpPermissionSource{
+			"user_1/project_1": {
+				{
+					SourceType: OpPermissionSourceTypeMember, SourceUID: "member_1", RoleUID: "role_1", RoleName: "orders reader",
+					OpPermissionWithOpRange: OpPermissionWithOpRange{OpPermissionUID: sqlQuery, OpRangeType: OpRangeTypeTable, RangeUIDs: []string{ordersRange}, ProjectUID: "project_1"},
+				},
+				{
+					SourceType: OpPermissionSourceTypeMemberGroup, SourceUID: "group_1", SourceName: "crm", RoleUID: "role_2", RoleName: "users reader",
+					OpPermissionWithOpRange: OpPermissionWithOpRange{OpPermissionUID: sqlQuery, OpRangeType: OpRangeTypeTable, RangeUIDs: []string{usersRange}, ProjectUID: "project_1"},
+				},
+			},
+		},
+	}
+	uc := newTestOpPermissionVerifyUsecase(&mockUserRepo{users: map[string]*User{"user_1": {UID: "user_1", Name: "u1", Stat: UserStatOK}}}, repo)
+	ctx := context.Background()
+
+	orders := &sqlop.SQLObject{Type: sqlop.SQLObjectTypeTable, DatabaseName: "sales", TableName: "orders"}
+	users := &sqlop.SQLObject{Type: sqlop.SQLObjectTypeTable, DatabaseName: "sales", TableName: "users"}
+	items := &sqlop.SQLObject{Type: sqlop.SQLObjectTypeTable, DatabaseName: "sales", TableName: "items"}
+
+	explain := func(objects ...*sqlop.SQLObject) *PermissionExplanation {
+		e, err := uc.ExplainPermission(ctx, &ExplainPermissionArgs{
+			UserUID: "user_1", ProjectUID: "project_1", DBServiceUID: "db_1", OpPermissionUID: sqlQuery, Objects: objects,
+		})
+		assert.NoError(t, err)
+		return e
+	}
+
+	t.Run("without_objects", func(t *testing.T) {
+		e := explain()
+		assert.False(t, e.Allowed)
+		assert.Equal(t, PermissionExplainResultNotMatched, e.Steps[len(e.Steps)-1].Result)
+	})
+
+	t.Run("covered_by_one_source", func(t *testing.T) {
+		e := explain(orders)
+		assert.True(t, e.Allowed)
+		last := e.Steps[len(e.Steps)-1]
+		assert.Equal(t, PermissionExplainRuleRange, last.Rule)
+		assert.Equal(t, "member_1", last.Source.SourceUID)
+	})
+
+	t.Run("covered_by_several_sources", func(t *testing.T) {
+		e := explain(orders, users)
+		assert.True(t, e.Allowed)
+		last := e.Steps[len(e.Steps)-1]
+		assert.Equal(t, PermissionExplainRuleRange, last.Rule)
+		assert.Nil(t, last.Source)
+	})
+
+	t.Run("object_not_covered", func(t *testing.T) {
+		e := explain(orders, items)
+		assert.False(t, e.Allowed)
+		last := e.Steps[len(e.Steps)-1]
+		assert.Equal(t, PermissionExplainResultNotMatched, last.Result)
+		assert.Contains(t, last.Detail, "sales.items")
+	})
+}
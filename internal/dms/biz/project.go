@@ -225,6 +225,56 @@ func (d *ProjectUsecase) InitProjects(ctx context.Context) (err error) {
 	return nil
 }
 
+// CreateProject 新建项目会影响用户可见的项目范围，成功后清空权限缓存
+func (d *ProjectUsecase) CreateProject(ctx context.Context, project *Project, createUserUID string) error {
+	if err := d.createProject(ctx, project, createUserUID); err != nil {
+		return err
+	}
+	d.opPermissionVerifyUsecase.InvalidateAllPermissions(ctx)
+	return nil
+}
+
+func (d *ProjectUsecase) ImportProjects(ctx context.Context, uid string, projects []*Project) error {
+	if err := d.importProjects(ctx, uid, projects); err != nil {
+		return err
+	}
+	d.opPermissionVerifyUsecase.InvalidateAllPermissions(ctx)
+	return nil
+}
+
+func (d *ProjectUsecase) UpdateProjectDesc(ctx context.Context, currentUserUid, projectUid string, desc *string) error {
+	if err := d.updateProjectDesc(ctx, currentUserUid, projectUid, desc); err != nil {
+		return err
+	}
+	d.opPermissionVerifyUsecase.InvalidateProjectPermissions(ctx, projectUid)
+	return nil
+}
+
+func (d *ProjectUsecase) UpdateProject(ctx context.Context, currentUserUid, projectUid string, desc *string, priority *dmsCommonV1.ProjectPriority, businessTagUID string) error {
+	if err := d.updateProject(ctx, currentUserUid, projectUid, desc, priority, businessTagUID); err != nil {
+		return err
+	}
+	d.opPermissionVerifyUsecase.InvalidateProjectPermissions(ctx, projectUid)
+	return nil
+}
+
+// ArchivedProject 归档后项目内的操作权限失效，取消归档后恢复，两者都需要让缓存失效
+func (d *ProjectUsecase) ArchivedProject(ctx context.Context, currentUserUid, projectUid string, archived bool) error {
+	if err := d.archivedProject(ctx, currentUserUid, projectUid, archived); err != nil {
+		return err
+	}
+	d.opPermissionVerifyUsecase.InvalidateProjectPermissions(ctx, projectUid)
+	return nil
+}
+
+func (d *ProjectUsecase) DeleteProject(ctx context.Context, currentUserUid, projectUid string) error {
+	if err := d.deleteProject(ctx, currentUserUid, projectUid); err != nil {
+		return err
+	}
+	d.opPermissionVerifyUsecase.InvalidateAllPermissions(ctx)
+	return nil
+}
+
 func (d *ProjectUsecase) GetProject(ctx context.Context, projectUid string) (*Project, error) {
 	return d.repo.GetProject(ctx, projectUid)
 }
@@ -246,6 +296,7 @@ func (d *ProjectUsecase) UpdateDBServiceBusiness(ctx context.Context, currentUse
 	if err != nil {
 		return fmt.Errorf("update db service business failed: %v", err)
 	}
+	d.opPermissionVerifyUsecase.InvalidateProjectPermissions(ctx, projectUid)
 
 	return nil
 }
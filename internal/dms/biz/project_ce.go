@@ -10,7 +10,7 @@ import (
 
 var errNotSupportProject = errors.New("project related functions are enterprise version functions")
 
-func (d *ProjectUsecase) CreateProject(ctx context.Context, project *Project, createUserUID string) (err error) {
+func (d *ProjectUsecase) createProject(ctx context.Context, project *Project, createUserUID string) (err error) {
 	return errNotSupportProject
 }
 
@@ -18,17 +18,17 @@ func (d *ProjectUsecase) GetProjectByName(ctx context.Context, projectName strin
 	return nil, errNotSupportProject
 }
 
-func (d *ProjectUsecase) UpdateProjectDesc(ctx context.Context, currentUserUid, projectUid string, desc *string) (err error) {
+func (d *ProjectUsecase) updateProjectDesc(ctx context.Context, currentUserUid, projectUid string, desc *string) (err error) {
 
 	return errNotSupportProject
 }
 
-func (d *ProjectUsecase) ArchivedProject(ctx context.Context, currentUserUid, projectUid string, archived bool) (err error) {
+func (d *ProjectUsecase) archivedProject(ctx context.Context, currentUserUid, projectUid string, archived bool) (err error) {
 
 	return errNotSupportProject
 }
 
-func (d *ProjectUsecase) DeleteProject(ctx context.Context, currentUserUid, projectUid string) (err error) {
+func (d *ProjectUsecase) deleteProject(ctx context.Context, currentUserUid, projectUid string) (err error) {
 	return errNotSupportProject
 }
 
@@ -36,7 +36,7 @@ func (d *ProjectUsecase) isProjectActive(ctx context.Context, projectUid string)
 	return nil
 }
 
-func (d *ProjectUsecase) ImportProjects(ctx context.Context, uid string, projects []*Project) error {
+func (d *ProjectUsecase) importProjects(ctx context.Context, uid string, projects []*Project) error {
 	return errNotSupportProject
 }
 
@@ -48,7 +48,7 @@ func (d *ProjectUsecase) PreviewImportProjects(ctx context.Context, uid, file st
 	return nil, errNotSupportProject
 }
 
-func (d *ProjectUsecase) UpdateProject(ctx context.Context, currentUserUid, projectUid string, desc *string, priority *dmsCommonV1.ProjectPriority, businessTagUID string) (err error) {
+func (d *ProjectUsecase) updateProject(ctx context.Context, currentUserUid, projectUid string, desc *string, priority *dmsCommonV1.ProjectPriority, businessTagUID string) (err error) {
 	return errNotSupportProject
 }
 
@@ -270,6 +270,8 @@ const (
 	OpPermissionFieldDesc      OpPermissionField = "description"
 	OpPermissionFieldRangeType OpPermissionField = "range_type"
 	OpPermissionFieldService   OpPermissionField = "service"
+	OpPermissionFieldProvider  OpPermissionField = "provider"
+	OpPermissionFieldRemoved   OpPermissionField = "removed"
 )
 
 const (
@@ -307,6 +309,9 @@ const (
 	RoleFieldStat          RoleField = "stat"
 	RoleFieldOpPermission  RoleField = "op_permission"
 	RoleFieldOpPermissions RoleField = "oppermissions"
+	RoleFieldIsTemplate    RoleField = "is_template"
+	RoleFieldTemplateUID   RoleField = "template_uid"
+	RoleFieldProjectUID    RoleField = "project_uid"
 )
 
 const (
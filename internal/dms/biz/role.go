@@ -40,6 +40,14 @@ type Role struct {
 	Desc          string
 	Stat          RoleStat
 	OpPermissions []*OpPermission
+	// OwnOpPermissionUIDs 角色自身声明的权限，OpPermissions 为合并继承角色后的生效权限
+	OwnOpPermissionUIDs []string
+	ParentRoleUIDs      []string
+	// IsTemplate 角色模板，不能直接授予成员，由项目实例化后使用
+	IsTemplate  bool
+	TemplateUID string
+	// ProjectUID 非空时为项目内角色，只能在该项目中授予
+	ProjectUID string
 }
 
 func newRole(name, desc string) (*Role, error) {
@@ -95,7 +103,8 @@ type RoleRepo interface {
 	SaveRole(ctx context.Context, role *Role) error
 	UpdateRole(ctx context.Context, u *Role) error
 	CheckRoleExist(ctx context.Context, roleUids []string) (exists bool, err error)
-	CheckRoleExistByRoleName(ctx context.Context, roleName string) (exists bool, err error)
+	// CheckRoleExistByRoleName 检查项目内是否已有同名角色，projectUid 为空时检查全局角色
+	CheckRoleExistByRoleName(ctx context.Context, projectUid, roleName string) (exists bool, err error)
 	ListRoles(ctx context.Context, opt *ListRolesOption) (roles []*Role, total int64, err error)
 	DelRole(ctx context.Context, roleUid string) error
 	DelRoleFromAllMemberGroups(ctx context.Context, roleUid string) error
@@ -104,6 +113,7 @@ type RoleRepo interface {
 	ReplaceOpPermissionsInRole(ctx context.Context, roleUid string, OpPermissionUids []string) error
 	GetOpPermissionsByRole(ctx context.Context, roleUid string) ([]*OpPermission, error)
 	DelAllOpPermissionsFromRole(ctx context.Context, roleUid string) error
+	ListChildRoles(ctx context.Context, roleUid string) ([]*Role, error)
 }
 
 type RoleUsecase struct {
@@ -113,11 +123,12 @@ type RoleUsecase struct {
 	memberRepo                MemberRepo
 	pluginUsecase             *PluginUsecase
 	opPermissionVerifyUsecase *OpPermissionVerifyUsecase
+	separationOfDutiesUsecase *SeparationOfDutiesUsecase
 	log                       *utilLog.Helper
 }
 
 func NewRoleUsecase(log utilLog.Logger, tx TransactionGenerator, repo RoleRepo, opPermissionRepo OpPermissionRepo, memberRepo MemberRepo, pluginUsecase *PluginUsecase,
-	opPermissionVerifyUsecase *OpPermissionVerifyUsecase) *RoleUsecase {
+	opPermissionVerifyUsecase *OpPermissionVerifyUsecase, separationOfDutiesUsecase *SeparationOfDutiesUsecase) *RoleUsecase {
 	return &RoleUsecase{
 		tx:                        tx,
 		repo:                      repo,
@@ -125,6 +136,7 @@ func NewRoleUsecase(log utilLog.Logger, tx TransactionGenerator, repo RoleRepo,
 		memberRepo:                memberRepo,
 		pluginUsecase:             pluginUsecase,
 		opPermissionVerifyUsecase: opPermissionVerifyUsecase,
+		separationOfDutiesUsecase: separationOfDutiesUsecase,
 		log:                       utilLog.NewHelper(log, utilLog.WithMessageKey("biz.role")),
 	}
 }
@@ -218,7 +230,15 @@ func (d *RoleUsecase) InitRoles(ctx context.Context) (err error) {
 	return nil
 }
 
-func (d *RoleUsecase) CreateRole(ctx context.Context, currentUserUid, name, desc string, opPermissionUids []string) (uid string, err error) {
+type CreateRoleArgs struct {
+	Name             string
+	Desc             string
+	OpPermissionUIDs []string
+	ParentRoleUIDs   []string
+	IsTemplate       bool
+}
+
+func (d *RoleUsecase) CreateRole(ctx context.Context, currentUserUid string, args *CreateRoleArgs) (uid string, err error) {
 	// check
 	{
 		if canGlobalOp, err := d.opPermissionVerifyUsecase.CanOpGlobal(ctx, currentUserUid, false); err != nil {
@@ -227,17 +247,35 @@ func (d *RoleUsecase) CreateRole(ctx context.Context, currentUserUid, name, desc
 			return "", fmt.Errorf("user is not admin or global management permission")
 		}
 	}
-	existed, err := d.repo.CheckRoleExistByRoleName(ctx, name)
+
+	u, err := newRole(args.Name, args.Desc)
 	if err != nil {
-		return "", fmt.Errorf("check role exist failed: %v", err)
+		return "", fmt.Errorf("new role failed: %v", err)
 	}
-	if existed {
-		return "", fmt.Errorf("role name already existed")
+	u.OwnOpPermissionUIDs = append([]string{}, args.OpPermissionUIDs...)
+	u.ParentRoleUIDs = args.ParentRoleUIDs
+	u.IsTemplate = args.IsTemplate
+
+	if err := d.createRole(ctx, u); err != nil {
+		return "", err
 	}
+	return u.UID, nil
+}
 
-	u, err := newRole(name, desc)
+func (d *RoleUsecase) createRole(ctx context.Context, u *Role) (err error) {
+	existed, err := d.repo.CheckRoleExistByRoleName(ctx, u.ProjectUID, u.Name)
 	if err != nil {
-		return "", fmt.Errorf("new role failed: %v", err)
+		return fmt.Errorf("check role exist failed: %v", err)
+	}
+	if existed {
+		return fmt.Errorf("role name already existed")
+	}
+	if err := d.checkParentRoles(ctx, u); err != nil {
+		return err
+	}
+	opPermissionUids, err := d.effectiveOpPermissionUIDs(ctx, u, nil)
+	if err != nil {
+		return err
 	}
 
 	tx := d.tx.BeginTX(ctx)
@@ -248,18 +286,18 @@ func (d *RoleUsecase) CreateRole(ctx context.Context, currentUserUid, name, desc
 	}()
 
 	if err := d.repo.SaveRole(tx, u); err != nil {
-		return "", fmt.Errorf("save role failed: %v", err)
+		return fmt.Errorf("save role failed: %v", err)
 	}
 
 	if err := d.InsureOpPermissionsToRole(tx, opPermissionUids, u.UID); err != nil {
-		return "", fmt.Errorf("insure op permissions in role failed: %v", err)
+		return fmt.Errorf("insure op permissions in role failed: %v", err)
 	}
 
 	if err := tx.Commit(d.log); err != nil {
-		return "", fmt.Errorf("commit tx failed: %v", err)
+		return fmt.Errorf("commit tx failed: %v", err)
 	}
 
-	return u.UID, nil
+	return nil
 }
 
 // InsureOpPermissionsToRole 确保操作权限属于指定的角色
@@ -298,10 +336,19 @@ func (d *RoleUsecase) ListRole(ctx context.Context, option *ListRolesOption) (ro
 func (d *RoleUsecase) DelRole(ctx context.Context, currentUserUid, roleUid string) (err error) {
 	// check
 	{
-		if canGlobalOp, err := d.opPermissionVerifyUsecase.CanOpGlobal(ctx, currentUserUid, false); err != nil {
-			return fmt.Errorf("check user is admin or global management permission : %v", err)
-		} else if !canGlobalOp {
-			return fmt.Errorf("user is not admin or global management permission")
+		role, err := d.GetRole(ctx, roleUid)
+		if err != nil {
+			return fmt.Errorf("get role failed: %v", err)
+		}
+		if err := d.checkCanManageRole(ctx, currentUserUid, role); err != nil {
+			return err
+		}
+		children, err := d.repo.ListChildRoles(ctx, roleUid)
+		if err != nil {
+			return fmt.Errorf("list child roles failed: %v", err)
+		}
+		if len(children) > 0 {
+			return fmt.Errorf("role is inherited by role %s, remove the inheritance first", children[0].Name)
 		}
 	}
 
@@ -331,6 +378,7 @@ func (d *RoleUsecase) DelRole(ctx context.Context, currentUserUid, roleUid strin
 	if err := tx.Commit(d.log); err != nil {
 		return fmt.Errorf("commit tx failed: %v", err)
 	}
+	d.opPermissionVerifyUsecase.InvalidateAllPermissions(ctx)
 
 	return nil
 }
@@ -351,21 +399,20 @@ func (d *RoleUsecase) CheckRoleExist(ctx context.Context, roleUids []string) (bo
 	return d.repo.CheckRoleExist(ctx, roleUids)
 }
 
-func (d *RoleUsecase) UpdateRole(ctx context.Context, currentUserUid, updateRoleUid string, isDisabled bool, desc *string, opPermissionUids []string) error {
-	// check
-	{
-		if canGlobalOp, err := d.opPermissionVerifyUsecase.CanOpGlobal(ctx, currentUserUid, false); err != nil {
-			return fmt.Errorf("check user is admin or global management permission : %v", err)
-		} else if !canGlobalOp {
-			return fmt.Errorf("user is not admin or global management permission")
-		}
-	}
-
+// UpdateRole 更新角色，parentRoleUids 为 nil 时保持继承关系不变；继承该角色的角色（包括模板的实例）同步更新生效权限
+func (d *RoleUsecase) UpdateRole(ctx context.Context, currentUserUid, updateRoleUid string, isDisabled bool, desc *string, opPermissionUids []string, parentRoleUids *[]string) (err error) {
 	role, err := d.GetRole(ctx, updateRoleUid)
 	if err != nil {
 		return fmt.Errorf("get role failed: %v", err)
 	}
 
+	// check
+	{
+		if err := d.checkCanManageRole(ctx, currentUserUid, role); err != nil {
+			return err
+		}
+	}
+
 	if isDisabled {
 		role.Stat = RoleStatDisable
 	} else {
@@ -382,6 +429,24 @@ func (d *RoleUsecase) UpdateRole(ctx context.Context, currentUserUid, updateRole
 		return fmt.Errorf("op permissions not exist")
 	}
 
+	role.OwnOpPermissionUIDs = append([]string{}, opPermissionUids...)
+	if parentRoleUids != nil {
+		role.ParentRoleUIDs = *parentRoleUids
+		if err := d.checkParentRoles(ctx, role); err != nil {
+			return err
+		}
+	}
+
+	plans, err := d.planRoleOpPermissions(ctx, role)
+	if err != nil {
+		return err
+	}
+	for _, plan := range plans {
+		if err := d.separationOfDutiesUsecase.CheckRoleOpPermissions(ctx, plan.Role.UID, plan.After); err != nil {
+			return err
+		}
+	}
+
 	tx := d.tx.BeginTX(ctx)
 	defer func() {
 		if err != nil {
@@ -389,8 +454,10 @@ func (d *RoleUsecase) UpdateRole(ctx context.Context, currentUserUid, updateRole
 		}
 	}()
 
-	if err := d.repo.ReplaceOpPermissionsInRole(tx, role.UID, opPermissionUids); err != nil {
-		return fmt.Errorf("replace op permissionss in role failed: %v", err)
+	for _, plan := range plans {
+		if err := d.repo.ReplaceOpPermissionsInRole(tx, plan.Role.UID, plan.After); err != nil {
+			return fmt.Errorf("replace op permissionss in role failed: %v", err)
+		}
 	}
 
 	if err := d.repo.UpdateRole(tx, role); nil != err {
@@ -400,5 +467,6 @@ func (d *RoleUsecase) UpdateRole(ctx context.Context, currentUserUid, updateRole
 	if err := tx.Commit(d.log); err != nil {
 		return fmt.Errorf("commit tx failed: %v", err)
 	}
+	d.opPermissionVerifyUsecase.InvalidateAllPermissions(ctx)
 	return nil
 }
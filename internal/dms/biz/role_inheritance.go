@@ -0,0 +1,290 @@
+package biz
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"sort"
+
+	pkgConst "github.com/actiontech/dms/internal/dms/pkg/constant"
+)
+
+var ErrRoleInheritanceCycle = errors.New("role inheritance cycle detected")
+
+// ownOpPermissionUIDs 角色自身声明的权限，支持继承前创建的角色未记录时以生效权限为准
+func (u *Role) ownOpPermissionUIDs() []string {
+	if u.OwnOpPermissionUIDs == nil {
+		return u.opPermissionUIDs()
+	}
+	return u.OwnOpPermissionUIDs
+}
+
+// OwnsOpPermission 权限由角色自身声明，而非仅从继承的角色获得
+func (u *Role) OwnsOpPermission(opPermissionUid string) bool {
+	return slices.Contains(u.ownOpPermissionUIDs(), opPermissionUid)
+}
+
+func (u *Role) opPermissionUIDs() []string {
+	uids := make([]string, 0, len(u.OpPermissions))
+	for _, op := range u.OpPermissions {
+		uids = append(uids, op.UID)
+	}
+	return uids
+}
+
+// usableInProject 角色模板及其他项目的角色不能在项目中授予
+func (u *Role) usableInProject(projectUid string) error {
+	if u.IsTemplate {
+		return fmt.Errorf("role %s is a role template, instantiate it in the project first", u.Name)
+	}
+	if u.ProjectUID != "" && u.ProjectUID != projectUid {
+		return fmt.Errorf("role %s belongs to another project", u.Name)
+	}
+	return nil
+}
+
+// RoleOpPermissionsPlan 角色变更前后的生效权限
+type RoleOpPermissionsPlan struct {
+	Role   *Role
+	Before []string
+	After  []string
+}
+
+func (p *RoleOpPermissionsPlan) Diff() (added, removed []string) {
+	for _, uid := range p.After {
+		if !slices.Contains(p.Before, uid) {
+			added = append(added, uid)
+		}
+	}
+	for _, uid := range p.Before {
+		if !slices.Contains(p.After, uid) {
+			removed = append(removed, uid)
+		}
+	}
+	return added, removed
+}
+
+// checkCanManageRole 项目内角色可以由项目管理员维护，其他角色需要平台管理权限
+func (d *RoleUsecase) checkCanManageRole(ctx context.Context, currentUserUid string, role *Role) error {
+	if role.ProjectUID != "" {
+		if canOpProject, err := d.opPermissionVerifyUsecase.CanOpProject(ctx, currentUserUid, role.ProjectUID, false); err != nil {
+			return fmt.Errorf("check user can op project failed: %v", err)
+		} else if !canOpProject {
+			return fmt.Errorf("user is not project admin or global management permission")
+		}
+		return nil
+	}
+	if canGlobalOp, err := d.opPermissionVerifyUsecase.CanOpGlobal(ctx, currentUserUid, false); err != nil {
+		return fmt.Errorf("check user is admin or global management permission : %v", err)
+	} else if !canGlobalOp {
+		return fmt.Errorf("user is not admin or global management permission")
+	}
+	return nil
+}
+
+// checkParentRoles 检查继承的角色存在、可见，且继承关系不成环
+func (d *RoleUsecase) checkParentRoles(ctx context.Context, role *Role) error {
+	seen := make(map[string]struct{}, len(role.ParentRoleUIDs))
+	for _, uid := range role.ParentRoleUIDs {
+		if uid == role.UID {
+			return fmt.Errorf("%w: role can not inherit itself", ErrRoleInheritanceCycle)
+		}
+		if _, ok := seen[uid]; ok {
+			return fmt.Errorf("duplicate parent role %s", uid)
+		}
+		seen[uid] = struct{}{}
+		// 项目管理员角色拥有项目内所有权限，只能直接授予
+		if uid == pkgConst.UIDOfRoleProjectAdmin {
+			return fmt.Errorf("can not inherit project admin role")
+		}
+		parent, err := d.repo.GetRole(ctx, uid)
+		if err != nil {
+			return fmt.Errorf("get parent role %s failed: %v", uid, err)
+		}
+		if parent.ProjectUID != "" && parent.ProjectUID != role.ProjectUID {
+			return fmt.Errorf("parent role %s belongs to another project", parent.Name)
+		}
+		if err := d.checkRoleInheritanceCycle(ctx, role.UID, parent, map[string]struct{}{}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// checkRoleInheritanceCycle 沿继承关系向上查找，出现角色自身即成环
+func (d *RoleUsecase) checkRoleInheritanceCycle(ctx context.Context, roleUid string, ancestor *Role, visited map[string]struct{}) error {
+	if _, ok := visited[ancestor.UID]; ok {
+		return nil
+	}
+	visited[ancestor.UID] = struct{}{}
+	for _, uid := range ancestor.ParentRoleUIDs {
+		if uid == roleUid {
+			return fmt.Errorf("%w: role %s already inherits the role", ErrRoleInheritanceCycle, ancestor.Name)
+		}
+		parent, err := d.repo.GetRole(ctx, uid)
+		if err != nil {
+			return fmt.Errorf("get parent role %s failed: %v", uid, err)
+		}
+		if err := d.checkRoleInheritanceCycle(ctx, roleUid, parent, visited); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// effectiveOpPermissionUIDs 合并角色自身声明的权限和继承角色的生效权限，planned 为本次变更中已重新计算的角色
+func (d *RoleUsecase) effectiveOpPermissionUIDs(ctx context.Context, role *Role, planned map[string][]string) ([]string, error) {
+	ret := make([]string, 0)
+	add := func(uids []string) {
+		for _, uid := range uids {
+			if !slices.Contains(ret, uid) {
+				ret = append(ret, uid)
+			}
+		}
+	}
+	add(role.ownOpPermissionUIDs())
+	for _, uid := range role.ParentRoleUIDs {
+		if uids, ok := planned[uid]; ok {
+			add(uids)
+			continue
+		}
+		parent, err := d.repo.GetRole(ctx, uid)
+		if err != nil {
+			return nil, fmt.Errorf("get parent role %s failed: %v", uid, err)
+		}
+		add(parent.opPermissionUIDs())
+	}
+	return ret, nil
+}
+
+// planRoleOpPermissions 计算角色变更后，角色自身及所有直接、间接继承它的角色的生效权限
+func (d *RoleUsecase) planRoleOpPermissions(ctx context.Context, role *Role) ([]*RoleOpPermissionsPlan, error) {
+	planned := make(map[string][]string)
+	after, err := d.effectiveOpPermissionUIDs(ctx, role, planned)
+	if err != nil {
+		return nil, err
+	}
+	planned[role.UID] = after
+	plans := []*RoleOpPermissionsPlan{{Role: role, Before: role.opPermissionUIDs(), After: after}}
+
+	descendants, err := d.listDescendantRoles(ctx, role.UID)
+	if err != nil {
+		return nil, err
+	}
+	for _, descendant := range descendants {
+		after, err := d.effectiveOpPermissionUIDs(ctx, descendant, planned)
+		if err != nil {
+			return nil, err
+		}
+		planned[descendant.UID] = after
+		plans = append(plans, &RoleOpPermissionsPlan{Role: descendant, Before: descendant.opPermissionUIDs(), After: after})
+	}
+	return plans, nil
+}
+
+// listDescendantRoles 返回直接、间接继承该角色的角色，按继承关系排序，保证计算某个角色时其继承的角色已计算
+func (d *RoleUsecase) listDescendantRoles(ctx context.Context, roleUid string) ([]*Role, error) {
+	pending := make(map[string]*Role)
+	queue := []string{roleUid}
+	for len(queue) > 0 {
+		uid := queue[0]
+		queue = queue[1:]
+		children, err := d.repo.ListChildRoles(ctx, uid)
+		if err != nil {
+			return nil, fmt.Errorf("list child roles of %s failed: %v", uid, err)
+		}
+		for _, child := range children {
+			if _, ok := pending[child.UID]; ok || child.UID == roleUid {
+				continue
+			}
+			pending[child.UID] = child
+			queue = append(queue, child.UID)
+		}
+	}
+
+	uids := make([]string, 0, len(pending))
+	for uid := range pending {
+		uids = append(uids, uid)
+	}
+	sort.Strings(uids)
+
+	ret := make([]*Role, 0, len(pending))
+	for len(uids) > 0 {
+		rest := uids[:0:0]
+		for _, uid := range uids {
+			role := pending[uid]
+			if slices.ContainsFunc(role.ParentRoleUIDs, func(p string) bool { _, ok := pending[p]; return ok }) {
+				rest = append(rest, uid)
+				continue
+			}
+			ret = append(ret, role)
+			delete(pending, uid)
+		}
+		if len(rest) == len(uids) {
+			return nil, ErrRoleInheritanceCycle
+		}
+		uids = rest
+	}
+	return ret, nil
+}
+
+// PreviewUpdateRole 预览角色变更对自身及继承它的角色（包括模板的实例）生效权限的影响，不做修改
+func (d *RoleUsecase) PreviewUpdateRole(ctx context.Context, currentUserUid, roleUid string, opPermissionUids []string, parentRoleUids *[]string) ([]*RoleOpPermissionsPlan, error) {
+	role, err := d.GetRole(ctx, roleUid)
+	if err != nil {
+		return nil, fmt.Errorf("get role failed: %v", err)
+	}
+	if err := d.checkCanManageRole(ctx, currentUserUid, role); err != nil {
+		return nil, err
+	}
+
+	role.OwnOpPermissionUIDs = append([]string{}, opPermissionUids...)
+	if parentRoleUids != nil {
+		role.ParentRoleUIDs = *parentRoleUids
+		if err := d.checkParentRoles(ctx, role); err != nil {
+			return nil, err
+		}
+	}
+	return d.planRoleOpPermissions(ctx, role)
+}
+
+type InstantiateRoleTemplateArgs struct {
+	TemplateUID string
+	ProjectUID  string
+	Name        string
+	Desc        string
+	// OpPermissionUIDs 在模板之外额外授予的权限
+	OpPermissionUIDs []string
+}
+
+// InstantiateRoleTemplate 在项目中实例化角色模板，实例继承模板，模板更新后实例随之更新
+func (d *RoleUsecase) InstantiateRoleTemplate(ctx context.Context, currentUserUid string, args *InstantiateRoleTemplateArgs) (uid string, err error) {
+	if canOpProject, err := d.opPermissionVerifyUsecase.CanOpProject(ctx, currentUserUid, args.ProjectUID, false); err != nil {
+		return "", fmt.Errorf("check user can op project failed: %v", err)
+	} else if !canOpProject {
+		return "", fmt.Errorf("user is not project admin or global management permission")
+	}
+
+	template, err := d.GetRole(ctx, args.TemplateUID)
+	if err != nil {
+		return "", fmt.Errorf("get role template failed: %v", err)
+	}
+	if !template.IsTemplate {
+		return "", fmt.Errorf("role %s is not a role template", template.Name)
+	}
+
+	role, err := newRole(args.Name, args.Desc)
+	if err != nil {
+		return "", fmt.Errorf("new role failed: %v", err)
+	}
+	role.OwnOpPermissionUIDs = append([]string{}, args.OpPermissionUIDs...)
+	role.ParentRoleUIDs = []string{template.UID}
+	role.TemplateUID = template.UID
+	role.ProjectUID = args.ProjectUID
+
+	if err := d.createRole(ctx, role); err != nil {
+		return "", err
+	}
+	return role.UID, nil
+}
@@ -0,0 +1,96 @@
+package biz
+
+import (
+	"context"
+	"slices"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// mockInheritanceRoleRepo 按 uid 保存角色，OpPermissions 为生效权限
+type mockInheritanceRoleRepo struct {
+	RoleRepo
+	roles map[string]*Role
+}
+
+func (m *mockInheritanceRoleRepo) GetRole(_ context.Context, roleUid string) (*Role, error) {
+	r := *m.roles[roleUid]
+	return &r, nil
+}
+
+func (m *mockInheritanceRoleRepo) ListChildRoles(_ context.Context, roleUid string) ([]*Role, error) {
+	ret := make([]*Role, 0)
+	for _, r := range m.roles {
+		if slices.Contains(r.ParentRoleUIDs, roleUid) {
+			c := *r
+			ret = append(ret, &c)
+		}
+	}
+	return ret, nil
+}
+
+func newInheritanceTestRole(uid string, own []string, parents ...string) *Role {
+	return &Role{UID: uid, Name: uid, OwnOpPermissionUIDs: own, ParentRoleUIDs: parents}
+}
+
+func TestPlanRoleOpPermissions(t *testing.T) {
+	// base <- dev, base <- ops, dev + ops <- lead（菱形继承）
+	repo := &mockInheritanceRoleRepo{roles: map[string]*Role{
+		"base": newInheritanceTestRole("base", []string{"query"}),
+		"dev":  newInheritanceTestRole("dev", []string{"workflow"}, "base"),
+		"ops":  newInheritanceTestRole("ops", []string{"execute"}, "base"),
+		"lead": newInheritanceTestRole("lead", []string{"audit"}, "dev", "ops"),
+	}}
+	uc := &RoleUsecase{repo: repo}
+	ctx := context.Background()
+
+	// 按继承关系写入初始生效权限
+	for _, uid := range []string{"base", "dev", "ops", "lead"} {
+		plans, err := uc.planRoleOpPermissions(ctx, repo.roles[uid])
+		assert.NoError(t, err)
+		for _, op := range plans[0].After {
+			repo.roles[uid].OpPermissions = append(repo.roles[uid].OpPermissions, &OpPermission{UID: op})
+		}
+	}
+	assert.ElementsMatch(t, []string{"audit", "workflow", "query", "execute"}, repo.roles["lead"].opPermissionUIDs())
+	assert.False(t, repo.roles["lead"].OwnsOpPermission("query"))
+
+	base, _ := repo.GetRole(ctx, "base")
+	base.OwnOpPermissionUIDs = []string{"query", "export"}
+	plans, err := uc.planRoleOpPermissions(ctx, base)
+	assert.NoError(t, err)
+	order := make([]string, 0, len(plans))
+	for _, plan := range plans {
+		order = append(order, plan.Role.UID)
+		added, removed := plan.Diff()
+		assert.Equal(t, []string{"export"}, added)
+		assert.Empty(t, removed)
+	}
+	// lead 在 dev、ops 之后计算
+	assert.Equal(t, []string{"base", "dev", "ops", "lead"}, order)
+
+	t.Run("cycle_detected", func(t *testing.T) {
+		base, _ := repo.GetRole(ctx, "base")
+		base.ParentRoleUIDs = []string{"lead"}
+		assert.ErrorIs(t, uc.checkParentRoles(ctx, base), ErrRoleInheritanceCycle)
+
+		base.ParentRoleUIDs = []string{"base"}
+		assert.ErrorIs(t, uc.checkParentRoles(ctx, base), ErrRoleInheritanceCycle)
+	})
+
+	t.Run("project_role_not_inheritable_by_other_project", func(t *testing.T) {
+		repo.roles["p1_role"] = &Role{UID: "p1_role", Name: "p1_role", ProjectUID: "p1"}
+		assert.Error(t, uc.checkParentRoles(ctx, newInheritanceTestRole("global", nil, "p1_role")))
+		role := newInheritanceTestRole("p1_other", nil, "p1_role")
+		role.ProjectUID = "p1"
+		assert.NoError(t, uc.checkParentRoles(ctx, role))
+	})
+}
+
+func TestRoleUsableInProject(t *testing.T) {
+	assert.Error(t, (&Role{IsTemplate: true}).usableInProject("p1"))
+	assert.Error(t, (&Role{ProjectUID: "p2"}).usableInProject("p1"))
+	assert.NoError(t, (&Role{ProjectUID: "p1"}).usableInProject("p1"))
+	assert.NoError(t, (&Role{}).usableInProject("p1"))
+}
@@ -0,0 +1,603 @@
+package biz
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	pkgConst "github.com/actiontech/dms/internal/dms/pkg/constant"
+	utilLog "github.com/actiontech/dms/pkg/dms-common/pkg/log"
+	pkgRand "github.com/actiontech/dms/pkg/rand"
+)
+
+type SeparationOfDutiesRuleType string
+
+const (
+	// SeparationOfDutiesRuleTypeExclusivePermissions 同一用户不能在同一数据源上同时拥有两个互斥的权限
+	SeparationOfDutiesRuleTypeExclusivePermissions SeparationOfDutiesRuleType = "exclusive_permissions"
+	// SeparationOfDutiesRuleTypeCreatorCannotApprove 工单创建人不能审批自己的工单
+	SeparationOfDutiesRuleTypeCreatorCannotApprove SeparationOfDutiesRuleType = "creator_cannot_approve"
+)
+
+func ParseSeparationOfDutiesRuleType(typ string) (SeparationOfDutiesRuleType, error) {
+	switch SeparationOfDutiesRuleType(typ) {
+	case SeparationOfDutiesRuleTypeExclusivePermissions, SeparationOfDutiesRuleTypeCreatorCannotApprove:
+		return SeparationOfDutiesRuleType(typ), nil
+	default:
+		return "", fmt.Errorf("invalid separation of duties rule type: %s", typ)
+	}
+}
+
+// SeparationOfDutiesWorkflowType 取值与 Workflow.WorkflowType 保持一致
+type SeparationOfDutiesWorkflowType string
+
+// 只接受审批时会校验规则的工单类型，其他类型的规则配置后不会生效
+const (
+	SeparationOfDutiesWorkflowTypeDataExport SeparationOfDutiesWorkflowType = "data_export"
+)
+
+func ParseSeparationOfDutiesWorkflowType(typ string) (SeparationOfDutiesWorkflowType, error) {
+	switch SeparationOfDutiesWorkflowType(typ) {
+	case SeparationOfDutiesWorkflowTypeDataExport:
+		return SeparationOfDutiesWorkflowType(typ), nil
+	default:
+		return "", fmt.Errorf("invalid separation of duties workflow type: %s", typ)
+	}
+}
+
+// sodAllDBServices 表示项目范围的授权，覆盖项目内全部数据源
+const sodAllDBServices = "*"
+
+// workflowStepStateFinish 审批通过的工单步骤状态
+const workflowStepStateFinish = "finish"
+
+var ErrSeparationOfDutiesViolation = errors.New("separation of duties violation")
+
+// SeparationOfDutiesRule 项目内的职责分离规则
+type SeparationOfDutiesRule struct {
+	UID        string
+	ProjectUID string
+	Type       SeparationOfDutiesRuleType
+	// OpPermissionUIDs 互斥的两个权限，仅 exclusive_permissions 规则使用
+	OpPermissionUIDs []string
+	// WorkflowType 规则约束的工单类型，仅 creator_cannot_approve 规则使用
+	WorkflowType  SeparationOfDutiesWorkflowType
+	Desc          string
+	CreateUserUID string
+	CreatedAt     time.Time
+}
+
+// SelfApprovedWorkflow 创建人自己审批通过的工单
+type SelfApprovedWorkflow struct {
+	WorkflowUID  string
+	WorkflowName string
+	UserUID      string
+	OperateAt    *time.Time
+}
+
+type SeparationOfDutiesRepo interface {
+	SaveSeparationOfDutiesRule(ctx context.Context, rule *SeparationOfDutiesRule) error
+	DelSeparationOfDutiesRule(ctx context.Context, ruleUid string) error
+	GetSeparationOfDutiesRule(ctx context.Context, ruleUid string) (*SeparationOfDutiesRule, error)
+	ListSeparationOfDutiesRules(ctx context.Context, projectUid string) ([]*SeparationOfDutiesRule, error)
+	// ListProjectUIDsByRole 返回成员或成员组绑定了该角色的项目
+	ListProjectUIDsByRole(ctx context.Context, roleUid string) ([]string, error)
+	ListSelfApprovedWorkflows(ctx context.Context, projectUid, workflowType string) ([]*SelfApprovedWorkflow, error)
+}
+
+type SeparationOfDutiesUsecase struct {
+	repo                      SeparationOfDutiesRepo
+	roleRepo                  RoleRepo
+	opPermissionRepo          OpPermissionRepo
+	workflowRepo              WorkflowRepo
+	opPermissionVerifyUsecase *OpPermissionVerifyUsecase
+	log                       *utilLog.Helper
+}
+
+func NewSeparationOfDutiesUsecase(log utilLog.Logger, repo SeparationOfDutiesRepo, roleRepo RoleRepo, opPermissionRepo OpPermissionRepo,
+	workflowRepo WorkflowRepo, opPermissionVerifyUsecase *OpPermissionVerifyUsecase) *SeparationOfDutiesUsecase {
+	return &SeparationOfDutiesUsecase{
+		repo:                      repo,
+		roleRepo:                  roleRepo,
+		opPermissionRepo:          opPermissionRepo,
+		workflowRepo:              workflowRepo,
+		opPermissionVerifyUsecase: opPermissionVerifyUsecase,
+		log:                       utilLog.NewHelper(log, utilLog.WithMessageKey("biz.separation_of_duties")),
+	}
+}
+
+// checkCanManageRules 职责分离规则只能由项目管理员或系统管理员维护，避免成员管理员自行放宽限制
+func (u *SeparationOfDutiesUsecase) checkCanManageRules(ctx context.Context, currentUserUid, projectUid string) error {
+	canOpProject, err := u.opPermissionVerifyUsecase.CanOpProject(ctx, currentUserUid, projectUid, false)
+	if err != nil {
+		return fmt.Errorf("check user can op project failed: %v", err)
+	}
+	if !canOpProject {
+		return fmt.Errorf("user is not project admin or global management permission")
+	}
+	return nil
+}
+
+func (u *SeparationOfDutiesUsecase) CreateSeparationOfDutiesRule(ctx context.Context, currentUserUid string, rule *SeparationOfDutiesRule) (string, error) {
+	if err := u.checkCanManageRules(ctx, currentUserUid, rule.ProjectUID); err != nil {
+		return "", err
+	}
+
+	switch rule.Type {
+	case SeparationOfDutiesRuleTypeExclusivePermissions:
+		if len(rule.OpPermissionUIDs) != 2 || rule.OpPermissionUIDs[0] == rule.OpPermissionUIDs[1] {
+			return "", fmt.Errorf("exclusive permissions rule requires two different op permissions")
+		}
+		for _, uid := range rule.OpPermissionUIDs {
+			op, err := u.opPermissionRepo.GetOpPermission(ctx, uid)
+			if err != nil {
+				return "", fmt.Errorf("get op permission %s failed: %w", uid, err)
+			}
+			if op.RangeType == OpRangeTypeGlobal {
+				return "", fmt.Errorf("op permission %s is a global permission and cannot be used in project rules", op.Name)
+			}
+		}
+		rule.WorkflowType = ""
+	case SeparationOfDutiesRuleTypeCreatorCannotApprove:
+		if _, err := ParseSeparationOfDutiesWorkflowType(string(rule.WorkflowType)); err != nil {
+			return "", err
+		}
+		rule.OpPermissionUIDs = nil
+	default:
+		return "", fmt.Errorf("invalid separation of duties rule type: %s", rule.Type)
+	}
+
+	uid, err := pkgRand.GenStrUid()
+	if err != nil {
+		return "", err
+	}
+	rule.UID = uid
+	rule.CreateUserUID = currentUserUid
+	rule.CreatedAt = time.Now()
+	if err := u.repo.SaveSeparationOfDutiesRule(ctx, rule); err != nil {
+		return "", fmt.Errorf("save separation of duties rule failed: %v", err)
+	}
+	return uid, nil
+}
+
+func (u *SeparationOfDutiesUsecase) DelSeparationOfDutiesRule(ctx context.Context, currentUserUid, projectUid, ruleUid string) error {
+	if err := u.checkCanManageRules(ctx, currentUserUid, projectUid); err != nil {
+		return err
+	}
+	rule, err := u.repo.GetSeparationOfDutiesRule(ctx, ruleUid)
+	if err != nil {
+		return fmt.Errorf("get separation of duties rule failed: %w", err)
+	}
+	if rule.ProjectUID != projectUid {
+		return fmt.Errorf("separation of duties rule %s does not belong to project %s", ruleUid, projectUid)
+	}
+	return u.repo.DelSeparationOfDutiesRule(ctx, ruleUid)
+}
+
+func (u *SeparationOfDutiesUsecase) ListSeparationOfDutiesRules(ctx context.Context, currentUserUid, projectUid string) ([]*SeparationOfDutiesRule, error) {
+	canView, err := u.opPermissionVerifyUsecase.HasViewPermission(ctx, currentUserUid, projectUid, pkgConst.UIdOfOpPermissionManageMember)
+	if err != nil {
+		return nil, fmt.Errorf("check user has view permission failed: %v", err)
+	}
+	if !canView {
+		return nil, fmt.Errorf("user has no permission to view separation of duties rules")
+	}
+	return u.repo.ListSeparationOfDutiesRules(ctx, projectUid)
+}
+
+func (u *SeparationOfDutiesUsecase) listRulesByType(ctx context.Context, projectUid string, typ SeparationOfDutiesRuleType) ([]*SeparationOfDutiesRule, error) {
+	rules, err := u.repo.ListSeparationOfDutiesRules(ctx, projectUid)
+	if err != nil {
+		return nil, fmt.Errorf("list separation of duties rules failed: %v", err)
+	}
+	ret := make([]*SeparationOfDutiesRule, 0, len(rules))
+	for _, rule := range rules {
+		if rule.Type == typ {
+			ret = append(ret, rule)
+		}
+	}
+	return ret, nil
+}
+
+// ExclusivePermissionConflict 用户同时拥有某条互斥规则中的两个权限，DBServiceUIDs 为两者范围重叠的数据源，
+// 包含 "*" 表示两个权限均为项目范围
+type ExclusivePermissionConflict struct {
+	Rule          *SeparationOfDutiesRule
+	DBServiceUIDs []string
+}
+
+// findExclusiveConflicts 计算一组授权违反的互斥规则。项目管理员拥有项目内全部权限，不受互斥规则约束
+func (u *SeparationOfDutiesUsecase) findExclusiveConflicts(ctx context.Context, projectUid string, rules []*SeparationOfDutiesRule, grants []OpPermissionWithOpRange) ([]*ExclusivePermissionConflict, error) {
+	if len(rules) == 0 {
+		return nil, nil
+	}
+	resolved := make(map[string][]string)
+	scopes := make(map[string]map[string]struct{})
+	for _, grant := range grants {
+		if grant.OpPermissionUID == pkgConst.UIDOfOpPermissionProjectAdmin {
+			return nil, nil
+		}
+		uids, err := u.grantDBServiceScope(ctx, projectUid, grant, resolved)
+		if err != nil {
+			return nil, err
+		}
+		if scopes[grant.OpPermissionUID] == nil {
+			scopes[grant.OpPermissionUID] = make(map[string]struct{})
+		}
+		for _, uid := range uids {
+			scopes[grant.OpPermissionUID][uid] = struct{}{}
+		}
+	}
+
+	conflicts := make([]*ExclusivePermissionConflict, 0)
+	for _, rule := range rules {
+		if len(rule.OpPermissionUIDs) != 2 {
+			continue
+		}
+		overlap := intersectDBServiceScopes(scopes[rule.OpPermissionUIDs[0]], scopes[rule.OpPermissionUIDs[1]])
+		if len(overlap) > 0 {
+			conflicts = append(conflicts, &ExclusivePermissionConflict{Rule: rule, DBServiceUIDs: overlap})
+		}
+	}
+	return conflicts, nil
+}
+
+// grantDBServiceScope 返回授权覆盖的数据源，对象级别的授权按其所在数据源计算
+func (u *SeparationOfDutiesUsecase) grantDBServiceScope(ctx context.Context, projectUid string, grant OpPermissionWithOpRange, resolved map[string][]string) ([]string, error) {
+	switch grant.OpRangeType {
+	case OpRangeTypeProject:
+		return []string{sodAllDBServices}, nil
+	case OpRangeTypeDBService:
+		return grant.RangeUIDs, nil
+	case OpRangeTypeDBServiceSelector:
+		return u.opPermissionVerifyUsecase.resolveDBServiceSelector(ctx, projectUid, grant.RangeUIDs, resolved)
+	case OpRangeTypeDatabase, OpRangeTypeSchema, OpRangeTypeTable:
+		objectRanges, err := ParseDBObjectRanges(grant.OpRangeType, grant.RangeUIDs)
+		if err != nil {
+			return nil, err
+		}
+		uids := make([]string, 0, len(objectRanges))
+		for _, r := range objectRanges {
+			uids = append(uids, r.DBServiceUID)
+		}
+		return uids, nil
+	default:
+		return nil, nil
+	}
+}
+
+func intersectDBServiceScopes(a, b map[string]struct{}) []string {
+	if len(a) == 0 || len(b) == 0 {
+		return nil
+	}
+	_, aAll := a[sodAllDBServices]
+	_, bAll := b[sodAllDBServices]
+	ret := make([]string, 0)
+	switch {
+	case aAll && bAll:
+		ret = append(ret, sodAllDBServices)
+	case aAll:
+		for uid := range b {
+			ret = append(ret, uid)
+		}
+	case bAll:
+		for uid := range a {
+			ret = append(ret, uid)
+		}
+	default:
+		for uid := range a {
+			if _, ok := b[uid]; ok {
+				ret = append(ret, uid)
+			}
+		}
+	}
+	sort.Strings(ret)
+	return ret
+}
+
+// expandGrants 将角色及项目管理权限展开为逐条的操作权限授权
+func (u *SeparationOfDutiesUsecase) expandGrants(ctx context.Context, projectUid string, roles []MemberRoleWithOpRange, projectManagePermissions []string, roleOps map[string][]*OpPermission) ([]OpPermissionWithOpRange, error) {
+	grants := make([]OpPermissionWithOpRange, 0)
+	for _, role := range roles {
+		if role.RoleUID == pkgConst.UIDOfRoleProjectAdmin {
+			grants = append(grants, OpPermissionWithOpRange{
+				OpPermissionUID: pkgConst.UIDOfOpPermissionProjectAdmin,
+				OpRangeType:     OpRangeTypeProject,
+				RangeUIDs:       []string{projectUid},
+				ProjectUID:      projectUid,
+			})
+			continue
+		}
+		ops, ok := roleOps[role.RoleUID]
+		if !ok {
+			var err error
+			ops, err = u.roleRepo.GetOpPermissionsByRole(ctx, role.RoleUID)
+			if err != nil {
+				return nil, fmt.Errorf("get op permissions of role %s failed: %v", role.RoleUID, err)
+			}
+			roleOps[role.RoleUID] = ops
+		}
+		for _, op := range ops {
+			grants = append(grants, OpPermissionWithOpRange{
+				OpPermissionUID: op.UID,
+				OpRangeType:     role.OpRangeType,
+				RangeUIDs:       role.RangeUIDs,
+				ProjectUID:      projectUid,
+			})
+		}
+	}
+	for _, uid := range projectManagePermissions {
+		grants = append(grants, OpPermissionWithOpRange{
+			OpPermissionUID: uid,
+			OpRangeType:     OpRangeTypeProject,
+			RangeUIDs:       []string{projectUid},
+			ProjectUID:      projectUid,
+		})
+	}
+	return grants, nil
+}
+
+// checkUserGrants 校验用户保留的已有授权与新授权合并后是否违反互斥规则，keep 决定哪些已有授权在本次变更后仍然有效
+func (u *SeparationOfDutiesUsecase) checkUserGrants(ctx context.Context, projectUid, userUid string, rules []*SeparationOfDutiesRule, keep func(*OpPermissionSource) bool, grants []OpPermissionWithOpRange) error {
+	sources, err := u.opPermissionVerifyUsecase.ListUserOpPermissionSourcesInProject(ctx, userUid, projectUid)
+	if err != nil {
+		return fmt.Errorf("list user op permission sources failed: %v", err)
+	}
+	all := make([]OpPermissionWithOpRange, 0, len(sources)+len(grants))
+	for i := range sources {
+		if keep(&sources[i]) {
+			all = append(all, sources[i].OpPermissionWithOpRange)
+		}
+	}
+	all = append(all, grants...)
+
+	conflicts, err := u.findExclusiveConflicts(ctx, projectUid, rules, all)
+	if err != nil {
+		return err
+	}
+	if len(conflicts) > 0 {
+		return u.conflictError(ctx, userUid, conflicts[0])
+	}
+	return nil
+}
+
+func (u *SeparationOfDutiesUsecase) conflictError(ctx context.Context, userUid string, conflict *ExclusivePermissionConflict) error {
+	names := make([]string, 0, len(conflict.Rule.OpPermissionUIDs))
+	for _, uid := range conflict.Rule.OpPermissionUIDs {
+		name := uid
+		if op, err := u.opPermissionRepo.GetOpPermission(ctx, uid); err == nil {
+			name = op.Name
+		}
+		names = append(names, name)
+	}
+	scope := "all db services in the project"
+	if conflict.DBServiceUIDs[0] != sodAllDBServices {
+		scope = fmt.Sprintf("db services %s", strings.Join(conflict.DBServiceUIDs, ","))
+	}
+	return fmt.Errorf("%w: user %s would hold both %s and %s on %s", ErrSeparationOfDutiesViolation, userUid, names[0], names[1], scope)
+}
+
+// CheckMemberGrants 校验成员授权变更后是否违反互斥规则，成员自身的原有授权会被本次变更整体替换
+func (u *SeparationOfDutiesUsecase) CheckMemberGrants(ctx context.Context, projectUid, userUid string, roles []MemberRoleWithOpRange, projectManagePermissions []string) error {
+	rules, err := u.listRulesByType(ctx, projectUid, SeparationOfDutiesRuleTypeExclusivePermissions)
+	if err != nil || len(rules) == 0 {
+		return err
+	}
+	grants, err := u.expandGrants(ctx, projectUid, roles, projectManagePermissions, make(map[string][]*OpPermission))
+	if err != nil {
+		return err
+	}
+	return u.checkUserGrants(ctx, projectUid, userUid, rules, func(s *OpPermissionSource) bool {
+		return s.SourceType != OpPermissionSourceTypeMember
+	}, grants)
+}
+
+// CheckMemberGroupGrants 对成员组内的每个用户校验互斥规则，memberGroupUid 为空表示新建成员组
+func (u *SeparationOfDutiesUsecase) CheckMemberGroupGrants(ctx context.Context, projectUid, memberGroupUid string, userUids []string, roles []MemberRoleWithOpRange, projectManagePermissions []string) error {
+	rules, err := u.listRulesByType(ctx, projectUid, SeparationOfDutiesRuleTypeExclusivePermissions)
+	if err != nil || len(rules) == 0 {
+		return err
+	}
+	grants, err := u.expandGrants(ctx, projectUid, roles, projectManagePermissions, make(map[string][]*OpPermission))
+	if err != nil {
+		return err
+	}
+	keep := func(s *OpPermissionSource) bool {
+		return memberGroupUid == "" || s.SourceType != OpPermissionSourceTypeMemberGroup || s.SourceUID != memberGroupUid
+	}
+	for _, userUid := range userUids {
+		if err := u.checkUserGrants(ctx, projectUid, userUid, rules, keep, grants); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// CheckBoundMemberGroupUsers 用户组的用户变更前，按绑定了该用户组的各成员组的授权校验变更后的用户
+func (u *SeparationOfDutiesUsecase) CheckBoundMemberGroupUsers(ctx context.Context, memberGroups []*MemberGroup, userUids []string) error {
+	for _, mg := range memberGroups {
+		projectManagePermissions := make([]string, 0, len(mg.OpPermissions))
+		for _, p := range mg.OpPermissions {
+			projectManagePermissions = append(projectManagePermissions, p.UID)
+		}
+		if err := u.CheckMemberGroupGrants(ctx, mg.ProjectUID, mg.UID, userUids, mg.RoleWithOpRanges, projectManagePermissions); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// CheckRoleOpPermissions 校验角色权限变更后，绑定了该角色的各项目成员是否违反互斥规则
+func (u *SeparationOfDutiesUsecase) CheckRoleOpPermissions(ctx context.Context, roleUid string, opPermissionUids []string) error {
+	projectUids, err := u.repo.ListProjectUIDsByRole(ctx, roleUid)
+	if err != nil {
+		return fmt.Errorf("list projects of role failed: %v", err)
+	}
+	for _, projectUid := range projectUids {
+		rules, err := u.listRulesByType(ctx, projectUid, SeparationOfDutiesRuleTypeExclusivePermissions)
+		if err != nil {
+			return err
+		}
+		if len(rules) == 0 {
+			continue
+		}
+		users, err := u.opPermissionVerifyUsecase.ListUsersInProject(ctx, projectUid)
+		if err != nil {
+			return fmt.Errorf("list users in project failed: %v", err)
+		}
+		for _, user := range users {
+			if err := u.checkUserRoleChange(ctx, projectUid, user.UserUid, rules, roleUid, opPermissionUids); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// checkUserRoleChange 将用户通过该角色获得的授权替换为角色的新权限后进行校验，角色的每个绑定范围保持不变
+func (u *SeparationOfDutiesUsecase) checkUserRoleChange(ctx context.Context, projectUid, userUid string, rules []*SeparationOfDutiesRule, roleUid string, opPermissionUids []string) error {
+	sources, err := u.opPermissionVerifyUsecase.ListUserOpPermissionSourcesInProject(ctx, userUid, projectUid)
+	if err != nil {
+		return fmt.Errorf("list user op permission sources failed: %v", err)
+	}
+	grants := make([]OpPermissionWithOpRange, 0, len(sources))
+	bindings := make(map[string]struct{})
+	for _, source := range sources {
+		if source.RoleUID != roleUid {
+			grants = append(grants, source.OpPermissionWithOpRange)
+			continue
+		}
+		key := fmt.Sprintf("%s/%s/%s/%s", source.SourceType, source.SourceUID, source.OpRangeType, strings.Join(source.RangeUIDs, ","))
+		if _, ok := bindings[key]; ok {
+			continue
+		}
+		bindings[key] = struct{}{}
+		for _, uid := range opPermissionUids {
+			grants = append(grants, OpPermissionWithOpRange{
+				OpPermissionUID: uid,
+				OpRangeType:     source.OpRangeType,
+				RangeUIDs:       source.RangeUIDs,
+				ProjectUID:      projectUid,
+			})
+		}
+	}
+	if len(bindings) == 0 {
+		return nil
+	}
+	conflicts, err := u.findExclusiveConflicts(ctx, projectUid, rules, grants)
+	if err != nil {
+		return err
+	}
+	if len(conflicts) > 0 {
+		return u.conflictError(ctx, userUid, conflicts[0])
+	}
+	return nil
+}
+
+// CheckApprover 校验审批人是否被 creator_cannot_approve 规则禁止审批该工单
+func (u *SeparationOfDutiesUsecase) CheckApprover(ctx context.Context, projectUid string, workflowType SeparationOfDutiesWorkflowType, creatorUid, approverUid string) error {
+	if creatorUid != approverUid {
+		return nil
+	}
+	rules, err := u.listRulesByType(ctx, projectUid, SeparationOfDutiesRuleTypeCreatorCannotApprove)
+	if err != nil {
+		return err
+	}
+	for _, rule := range rules {
+		if rule.WorkflowType == workflowType {
+			return fmt.Errorf("%w: the creator of a %s workflow cannot approve it", ErrSeparationOfDutiesViolation, workflowType)
+		}
+	}
+	return nil
+}
+
+// CheckDataExportApprover 项目未配置导出工单的职责分离规则时不读取工单，不影响原有的审批流程
+func (u *SeparationOfDutiesUsecase) CheckDataExportApprover(ctx context.Context, projectUid, workflowUid, approverUid string) error {
+	rules, err := u.listRulesByType(ctx, projectUid, SeparationOfDutiesRuleTypeCreatorCannotApprove)
+	if err != nil {
+		return err
+	}
+	enabled := false
+	for _, rule := range rules {
+		if rule.WorkflowType == SeparationOfDutiesWorkflowTypeDataExport {
+			enabled = true
+			break
+		}
+	}
+	if !enabled {
+		return nil
+	}
+	workflow, err := u.workflowRepo.GetDataExportWorkflow(ctx, workflowUid)
+	if err != nil {
+		return fmt.Errorf("get data export workflow failed: %w", err)
+	}
+	return u.CheckApprover(ctx, projectUid, SeparationOfDutiesWorkflowTypeDataExport, workflow.CreateUserUID, approverUid)
+}
+
+type SeparationOfDutiesViolation struct {
+	Rule     *SeparationOfDutiesRule
+	UserUID  string
+	UserName string
+	// DBServiceUIDs 互斥权限范围重叠的数据源，仅 exclusive_permissions 规则使用
+	DBServiceUIDs []string
+	// Workflow 创建人自己审批通过的工单，仅 creator_cannot_approve 规则使用
+	Workflow *SelfApprovedWorkflow
+}
+
+// ListSeparationOfDutiesViolations 列出项目内当前授权违反互斥规则的用户，以及规则生效前已由创建人自己审批的工单
+func (u *SeparationOfDutiesUsecase) ListSeparationOfDutiesViolations(ctx context.Context, currentUserUid, projectUid string) ([]*SeparationOfDutiesViolation, error) {
+	rules, err := u.ListSeparationOfDutiesRules(ctx, currentUserUid, projectUid)
+	if err != nil {
+		return nil, err
+	}
+	exclusiveRules := make([]*SeparationOfDutiesRule, 0)
+	violations := make([]*SeparationOfDutiesViolation, 0)
+	for _, rule := range rules {
+		switch rule.Type {
+		case SeparationOfDutiesRuleTypeExclusivePermissions:
+			exclusiveRules = append(exclusiveRules, rule)
+		case SeparationOfDutiesRuleTypeCreatorCannotApprove:
+			workflows, err := u.repo.ListSelfApprovedWorkflows(ctx, projectUid, string(rule.WorkflowType))
+			if err != nil {
+				return nil, fmt.Errorf("list self approved workflows failed: %v", err)
+			}
+			for _, w := range workflows {
+				violations = append(violations, &SeparationOfDutiesViolation{Rule: rule, UserUID: w.UserUID, Workflow: w})
+			}
+		}
+	}
+	if len(exclusiveRules) == 0 {
+		return violations, nil
+	}
+
+	users, err := u.opPermissionVerifyUsecase.ListUsersInProject(ctx, projectUid)
+	if err != nil {
+		return nil, fmt.Errorf("list users in project failed: %v", err)
+	}
+	for _, user := range users {
+		sources, err := u.opPermissionVerifyUsecase.ListUserOpPermissionSourcesInProject(ctx, user.UserUid, projectUid)
+		if err != nil {
+			return nil, fmt.Errorf("list user op permission sources failed: %v", err)
+		}
+		grants := make([]OpPermissionWithOpRange, 0, len(sources))
+		for _, source := range sources {
+			grants = append(grants, source.OpPermissionWithOpRange)
+		}
+		conflicts, err := u.findExclusiveConflicts(ctx, projectUid, exclusiveRules, grants)
+		if err != nil {
+			return nil, err
+		}
+		for _, conflict := range conflicts {
+			violations = append(violations, &SeparationOfDutiesViolation{
+				Rule:          conflict.Rule,
+				UserUID:       user.UserUid,
+				UserName:      user.UserName,
+				DBServiceUIDs: conflict.DBServiceUIDs,
+			})
+		}
+	}
+	return violations, nil
+}
@@ -0,0 +1,142 @@
+package biz
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	pkgConst "github.com/actiontech/dms/internal/dms/pkg/constant"
+	"github.com/stretchr/testify/assert"
+)
+
+type mockSeparationOfDutiesRepo struct {
+	rules []*SeparationOfDutiesRule
+}
+
+func (m *mockSeparationOfDutiesRepo) SaveSeparationOfDutiesRule(context.Context, *SeparationOfDutiesRule) error {
+	return nil
+}
+func (m *mockSeparationOfDutiesRepo) DelSeparationOfDutiesRule(context.Context, string) error {
+	return nil
+}
+func (m *mockSeparationOfDutiesRepo) GetSeparationOfDutiesRule(context.Context, string) (*SeparationOfDutiesRule, error) {
+	return nil, nil
+}
+func (m *mockSeparationOfDutiesRepo) ListSeparationOfDutiesRules(_ context.Context, projectUid string) ([]*SeparationOfDutiesRule, error) {
+	ret := make([]*SeparationOfDutiesRule, 0)
+	for _, r := range m.rules {
+		if r.ProjectUID == projectUid {
+			ret = append(ret, r)
+		}
+	}
+	return ret, nil
+}
+func (m *mockSeparationOfDutiesRepo) ListProjectUIDsByRole(context.Context, string) ([]string, error) {
+	return nil, nil
+}
+func (m *mockSeparationOfDutiesRepo) ListSelfApprovedWorkflows(context.Context, string, string) ([]*SelfApprovedWorkflow, error) {
+	return nil, nil
+}
+
+func TestSeparationOfDutiesExclusivePermissions(t *testing.T) {
+	create, approve := pkgConst.UIDOfOpPermissionExportCreate, pkgConst.UIDOfOpPermissionExportApprovalReject
+	sodRepo := &mockSeparationOfDutiesRepo{rules: []*SeparationOfDutiesRule{
+		{UID: "rule_1", ProjectUID: "project_1", Type: SeparationOfDutiesRuleTypeExclusivePermissions, OpPermissionUIDs: []string{create, approve}},
+	}}
+	opRepo := &mockOpPermissionVerifyRepo{
+		selectorDBServices: map[string][]string{"project_1/environment_tag=prod": {"db_1", "db_2"}},
+		permissionSources: map[string][]OpPermissionSource{
+			"user_1/project_1": {
+				{
+					SourceType: OpPermissionSourceTypeMember, SourceUID: "member_1", RoleUID: "role_1",
+					OpPermissionWithOpRange: OpPermissionWithOpRange{OpPermissionUID: create, OpRangeType: OpRangeTypeDBService, RangeUIDs: []string{"db_1"}, ProjectUID: "project_1"},
+				},
+				{
+					SourceType: OpPermissionSourceTypeMemberGroup, SourceUID: "group_1", RoleUID: "role_2",
+					OpPermissionWithOpRange: OpPermissionWithOpRange{OpPermissionUID: approve, OpRangeType: OpRangeTypeDBService, RangeUIDs: []string{"db_2"}, ProjectUID: "project_1"},
+				},
+			},
+		},
+	}
+	uc := NewSeparationOfDutiesUsecase(&noopLogger{}, sodRepo, nil, &mockOpPermissionRepoForTest{}, nil,
+		newTestOpPermissionVerifyUsecase(&mockUserRepo{}, opRepo))
+	ctx := context.Background()
+
+	t.Run("different_db_services_are_allowed", func(t *testing.T) {
+		assert.NoError(t, uc.CheckMemberGroupGrants(ctx, "project_1", "group_1", []string{"user_1"}, nil, nil))
+	})
+
+	t.Run("project_range_overlaps_every_db_service", func(t *testing.T) {
+		err := uc.CheckMemberGroupGrants(ctx, "project_1", "", []string{"user_1"}, nil, []string{approve})
+		assert.True(t, errors.Is(err, ErrSeparationOfDutiesViolation))
+	})
+
+	t.Run("user_group_members_are_checked_against_bound_member_groups", func(t *testing.T) {
+		bound := []*MemberGroup{{UID: "group_2", ProjectUID: "project_1", OpPermissions: []OpPermission{{UID: approve}}}}
+		err := uc.CheckBoundMemberGroupUsers(ctx, bound, []string{"user_2", "user_1"})
+		assert.True(t, errors.Is(err, ErrSeparationOfDutiesViolation))
+		assert.NoError(t, uc.CheckBoundMemberGroupUsers(ctx, bound, []string{"user_2"}))
+		// 替换成员组自身原有的授权
+		bound[0].UID = "group_1"
+		bound[0].OpPermissions = nil
+		assert.NoError(t, uc.CheckBoundMemberGroupUsers(ctx, bound, []string{"user_1"}))
+	})
+
+	t.Run("replaced_member_grants_are_ignored", func(t *testing.T) {
+		assert.NoError(t, uc.CheckMemberGrants(ctx, "project_1", "user_1", nil, []string{approve}))
+	})
+
+	t.Run("selector_range_is_resolved", func(t *testing.T) {
+		conflicts, err := uc.findExclusiveConflicts(ctx, "project_1", sodRepo.rules, []OpPermissionWithOpRange{
+			{OpPermissionUID: create, OpRangeType: OpRangeTypeDBService, RangeUIDs: []string{"db_2"}},
+			{OpPermissionUID: approve, OpRangeType: OpRangeTypeDBServiceSelector, RangeUIDs: []string{"environment_tag=prod"}},
+		})
+		assert.NoError(t, err)
+		if assert.Len(t, conflicts, 1) {
+			assert.Equal(t, []string{"db_2"}, conflicts[0].DBServiceUIDs)
+		}
+	})
+
+	t.Run("project_admin_is_exempt", func(t *testing.T) {
+		conflicts, err := uc.findExclusiveConflicts(ctx, "project_1", sodRepo.rules, []OpPermissionWithOpRange{
+			{OpPermissionUID: create, OpRangeType: OpRangeTypeProject},
+			{OpPermissionUID: approve, OpRangeType: OpRangeTypeProject},
+			{OpPermissionUID: pkgConst.UIDOfOpPermissionProjectAdmin, OpRangeType: OpRangeTypeProject},
+		})
+		assert.NoError(t, err)
+		assert.Empty(t, conflicts)
+	})
+}
+
+func TestSeparationOfDutiesCheckApprover(t *testing.T) {
+	sodRepo := &mockSeparationOfDutiesRepo{rules: []*SeparationOfDutiesRule{
+		{UID: "rule_1", ProjectUID: "project_1", Type: SeparationOfDutiesRuleTypeCreatorCannotApprove, WorkflowType: SeparationOfDutiesWorkflowTypeDataExport},
+	}}
+	uc := NewSeparationOfDutiesUsecase(&noopLogger{}, sodRepo, nil, nil, nil, nil)
+	ctx := context.Background()
+
+	err := uc.CheckApprover(ctx, "project_1", SeparationOfDutiesWorkflowTypeDataExport, "user_1", "user_1")
+	assert.True(t, errors.Is(err, ErrSeparationOfDutiesViolation))
+	assert.NoError(t, uc.CheckApprover(ctx, "project_1", SeparationOfDutiesWorkflowTypeDataExport, "user_1", "user_2"))
+	assert.NoError(t, uc.CheckApprover(ctx, "project_1", "other", "user_1", "user_1"))
+	_, err = ParseSeparationOfDutiesWorkflowType("unmasking")
+	assert.Error(t, err)
+	assert.NoError(t, uc.CheckApprover(ctx, "project_2", SeparationOfDutiesWorkflowTypeDataExport, "user_1", "user_1"))
+}
+
+func TestApproveDataExportWorkflowSeparationOfDuties(t *testing.T) {
+	sodRepo := &mockSeparationOfDutiesRepo{rules: []*SeparationOfDutiesRule{
+		{UID: "rule_1", ProjectUID: "project_1", Type: SeparationOfDutiesRuleTypeCreatorCannotApprove, WorkflowType: SeparationOfDutiesWorkflowTypeDataExport},
+	}}
+	workflowRepo := &mockPreviewWorkflowRepo{workflow: &Workflow{UID: "w1", ProjectUID: "project_1", CreateUserUID: "user_1"}}
+	uc := &DataExportWorkflowUsecase{
+		repo:                      workflowRepo,
+		separationOfDutiesUsecase: NewSeparationOfDutiesUsecase(&noopLogger{}, sodRepo, nil, nil, workflowRepo, nil),
+	}
+
+	// 审批入口在用例内校验，创建人审批自己的工单被拒绝
+	err := uc.ApproveDataExportWorkflow(context.Background(), "project_1", "w1", "user_1", "")
+	assert.True(t, errors.Is(err, ErrSeparationOfDutiesViolation))
+	err = uc.ApproveDataExportWorkflow(context.Background(), "project_1", "w1", "user_2", "")
+	assert.False(t, errors.Is(err, ErrSeparationOfDutiesViolation))
+}
@@ -0,0 +1,303 @@
+package biz
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	pkgErr "github.com/actiontech/dms/internal/dms/pkg/errors"
+	jwtPkg "github.com/actiontech/dms/pkg/dms-common/api/jwt"
+	utilLog "github.com/actiontech/dms/pkg/dms-common/pkg/log"
+	pkgRand "github.com/actiontech/dms/pkg/rand"
+
+	"github.com/labstack/echo/v4"
+)
+
+const (
+	ServiceAccountLogin = "service_account_login"
+
+	// 服务账号通过 client credentials 换取的 token 有效期较短，调用方需在过期前重新获取
+	ServiceAccountTokenTTL = time.Hour
+
+	serviceAccountClientIDPrefix = "dms-sa-"
+	serviceAccountSecretLen      = 32
+)
+
+var (
+	ErrServiceAccountInvalidClient = errors.New("invalid client id or client secret")
+	ErrServiceAccountUnavailable   = errors.New("service account is disabled or does not exist")
+)
+
+type ServiceAccount struct {
+	UID              string
+	UserUID          string
+	ClientID         string
+	ClientSecretHash string
+	SecretRotatedAt  time.Time
+	CreatedAt        time.Time
+
+	// 以下字段来自服务账号对应的用户
+	User *User
+}
+
+type ServiceAccountRepo interface {
+	SaveServiceAccount(ctx context.Context, sa *ServiceAccount) error
+	UpdateServiceAccount(ctx context.Context, sa *ServiceAccount) error
+	GetServiceAccount(ctx context.Context, uid string) (*ServiceAccount, error)
+	GetServiceAccountByClientID(ctx context.Context, clientID string) (*ServiceAccount, error)
+	ListServiceAccounts(ctx context.Context) ([]*ServiceAccount, error)
+	DelServiceAccount(ctx context.Context, uid string) error
+}
+
+type ServiceAccountUsecase struct {
+	tx          TransactionGenerator
+	repo        ServiceAccountRepo
+	userUsecase *UserUsecase
+	log         *utilLog.Helper
+}
+
+func NewServiceAccountUsecase(log utilLog.Logger, tx TransactionGenerator, repo ServiceAccountRepo, userUsecase *UserUsecase) *ServiceAccountUsecase {
+	return &ServiceAccountUsecase{
+		tx:          tx,
+		repo:        repo,
+		userUsecase: userUsecase,
+		log:         utilLog.NewHelper(log, utilLog.WithMessageKey("biz.service_account")),
+	}
+}
+
+func genServiceAccountSecret() (string, error) {
+	b := make([]byte, serviceAccountSecretLen)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("generate service account secret failed: %v", err)
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+type CreateServiceAccountArgs struct {
+	Name             string
+	Desc             string
+	OpPermissionUIDs []string
+}
+
+// CreateServiceAccount 创建服务账号，服务账号以不可登录的用户存在，可像普通用户一样绑定全局权限及项目角色，
+// 返回的 client secret 仅在创建时可见
+func (d *ServiceAccountUsecase) CreateServiceAccount(ctx context.Context, currentUserUid string, args *CreateServiceAccountArgs) (sa *ServiceAccount, clientSecret string, err error) {
+	userUid, err := d.userUsecase.AddUser(ctx, currentUserUid, &CreateUserArgs{
+		Name:                   args.Name,
+		Desc:                   args.Desc,
+		OpPermissionUIDs:       args.OpPermissionUIDs,
+		UserAuthenticationType: UserAuthenticationTypeServiceAccount,
+	})
+	if err != nil {
+		return nil, "", fmt.Errorf("create service account user failed: %w", err)
+	}
+
+	uid, err := pkgRand.GenStrUid()
+	if err != nil {
+		return nil, "", err
+	}
+	clientSecret, err = genServiceAccountSecret()
+	if err != nil {
+		return nil, "", err
+	}
+	secretHash, err := hashSecret(clientSecret)
+	if err != nil {
+		return nil, "", err
+	}
+
+	now := time.Now()
+	sa = &ServiceAccount{
+		UID:              uid,
+		UserUID:          userUid,
+		ClientID:         serviceAccountClientIDPrefix + uid,
+		ClientSecretHash: secretHash,
+		SecretRotatedAt:  now,
+		CreatedAt:        now,
+	}
+	if err := d.repo.SaveServiceAccount(ctx, sa); err != nil {
+		if delErr := d.userUsecase.DelUser(ctx, currentUserUid, userUid); delErr != nil {
+			d.log.Errorf("clean up user %s of service account failed: %v", userUid, delErr)
+		}
+		return nil, "", fmt.Errorf("save service account failed: %v", err)
+	}
+	return sa, clientSecret, nil
+}
+
+func (d *ServiceAccountUsecase) checkCanManage(ctx context.Context, currentUserUid string) error {
+	canGlobalOp, err := d.userUsecase.OpPermissionVerifyUsecase.CanOpGlobal(ctx, currentUserUid, false)
+	if err != nil {
+		return fmt.Errorf("check user is admin or global management permission : %v", err)
+	}
+	if !canGlobalOp {
+		return fmt.Errorf("user is not admin or global management permission")
+	}
+	return nil
+}
+
+func (d *ServiceAccountUsecase) fillUser(ctx context.Context, sa *ServiceAccount) error {
+	user, err := d.userUsecase.GetUser(ctx, sa.UserUID)
+	if err != nil {
+		return fmt.Errorf("get service account user failed: %v", err)
+	}
+	sa.User = user
+	return nil
+}
+
+func (d *ServiceAccountUsecase) GetServiceAccount(ctx context.Context, currentUserUid, uid string) (*ServiceAccount, error) {
+	if err := d.checkCanManage(ctx, currentUserUid); err != nil {
+		return nil, err
+	}
+	sa, err := d.repo.GetServiceAccount(ctx, uid)
+	if err != nil {
+		return nil, fmt.Errorf("get service account failed: %v", err)
+	}
+	if err := d.fillUser(ctx, sa); err != nil {
+		return nil, err
+	}
+	return sa, nil
+}
+
+func (d *ServiceAccountUsecase) ListServiceAccounts(ctx context.Context, currentUserUid string) ([]*ServiceAccount, error) {
+	if err := d.checkCanManage(ctx, currentUserUid); err != nil {
+		return nil, err
+	}
+	sas, err := d.repo.ListServiceAccounts(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("list service accounts failed: %v", err)
+	}
+	ret := make([]*ServiceAccount, 0, len(sas))
+	for _, sa := range sas {
+		if err := d.fillUser(ctx, sa); err != nil {
+			// 用户已被删除的服务账号不再展示
+			if errors.Is(err, pkgErr.ErrStorageNoData) {
+				continue
+			}
+			return nil, err
+		}
+		ret = append(ret, sa)
+	}
+	return ret, nil
+}
+
+type UpdateServiceAccountArgs struct {
+	IsDisabled       bool
+	OpPermissionUIDs []string
+}
+
+func (d *ServiceAccountUsecase) UpdateServiceAccount(ctx context.Context, currentUserUid, uid string, args *UpdateServiceAccountArgs) error {
+	sa, err := d.GetServiceAccount(ctx, currentUserUid, uid)
+	if err != nil {
+		return err
+	}
+	groups, err := d.userUsecase.GetUserGroups(ctx, sa.UserUID)
+	if err != nil {
+		return fmt.Errorf("get service account user groups failed: %v", err)
+	}
+	groupUids := make([]string, 0, len(groups))
+	for _, g := range groups {
+		groupUids = append(groupUids, g.UID)
+	}
+	return d.userUsecase.UpdateUser(ctx, currentUserUid, &UpdateUserArgs{
+		UserUID:          sa.UserUID,
+		IsDisabled:       args.IsDisabled,
+		UserGroupUIDs:    groupUids,
+		OpPermissionUIDs: args.OpPermissionUIDs,
+	})
+}
+
+// RotateServiceAccountSecret 重新生成 client secret，旧的 secret 立即失效，已签发的短期 token 在过期前仍可使用
+func (d *ServiceAccountUsecase) RotateServiceAccountSecret(ctx context.Context, currentUserUid, uid string) (sa *ServiceAccount, clientSecret string, err error) {
+	sa, err = d.GetServiceAccount(ctx, currentUserUid, uid)
+	if err != nil {
+		return nil, "", err
+	}
+	clientSecret, err = genServiceAccountSecret()
+	if err != nil {
+		return nil, "", err
+	}
+	secretHash, err := hashSecret(clientSecret)
+	if err != nil {
+		return nil, "", err
+	}
+	sa.ClientSecretHash = secretHash
+	sa.SecretRotatedAt = time.Now()
+	if err := d.repo.UpdateServiceAccount(ctx, sa); err != nil {
+		return nil, "", fmt.Errorf("update service account failed: %v", err)
+	}
+	return sa, clientSecret, nil
+}
+
+func (d *ServiceAccountUsecase) DelServiceAccount(ctx context.Context, currentUserUid, uid string) error {
+	sa, err := d.GetServiceAccount(ctx, currentUserUid, uid)
+	if err != nil {
+		return err
+	}
+	if err := d.userUsecase.DelUser(ctx, currentUserUid, sa.UserUID); err != nil {
+		return fmt.Errorf("delete service account user failed: %w", err)
+	}
+	if err := d.repo.DelServiceAccount(ctx, uid); err != nil {
+		return fmt.Errorf("delete service account failed: %v", err)
+	}
+	return nil
+}
+
+// IssueToken 使用 client credentials 为服务账号签发短期 token
+func (d *ServiceAccountUsecase) IssueToken(ctx context.Context, clientID, clientSecret string) (sa *ServiceAccount, token string, expiredTime time.Time, err error) {
+	sa, err = d.repo.GetServiceAccountByClientID(ctx, clientID)
+	if errors.Is(err, pkgErr.ErrStorageNoData) {
+		return nil, "", time.Time{}, ErrServiceAccountInvalidClient
+	}
+	if err != nil {
+		return nil, "", time.Time{}, fmt.Errorf("get service account failed: %v", err)
+	}
+	if !matchSecretHash(sa.ClientSecretHash, clientSecret) {
+		return nil, "", time.Time{}, ErrServiceAccountInvalidClient
+	}
+	if err := d.fillUser(ctx, sa); err != nil {
+		return nil, "", time.Time{}, err
+	}
+	if sa.User.Stat != UserStatOK {
+		return nil, "", time.Time{}, ErrServiceAccountUnavailable
+	}
+
+	expiredTime = time.Now().Add(ServiceAccountTokenTTL)
+	token, err = jwtPkg.GenJwtToken(jwtPkg.WithUserId(sa.UserUID), jwtPkg.WithUserName(sa.User.Name),
+		jwtPkg.WithExpiredTime(ServiceAccountTokenTTL), jwtPkg.WithAccessTokenMark(ServiceAccountLogin))
+	if err != nil {
+		return nil, "", time.Time{}, fmt.Errorf("gen service account token failed: %v", err)
+	}
+	return sa, token, expiredTime, nil
+}
+
+// VerifyServiceAccountToken 服务账号被禁用或删除后，已签发的 token 立即失效
+func (d *ServiceAccountUsecase) VerifyServiceAccountToken(ctx context.Context, tokenDetail *jwtPkg.TokenDetail) error {
+	user, err := d.userUsecase.GetUser(ctx, tokenDetail.UID)
+	if err != nil || user.UserAuthenticationType != UserAuthenticationTypeServiceAccount || user.Stat != UserStatOK {
+		return ErrServiceAccountUnavailable
+	}
+	return nil
+}
+
+// CheckServiceAccountToken 拒绝已禁用或已删除服务账号的 token
+func (d *ServiceAccountUsecase) CheckServiceAccountToken() echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			tokenDetail, err := jwtPkg.GetTokenDetailFromContext(c)
+			if err != nil {
+				return echo.NewHTTPError(http.StatusUnauthorized, fmt.Sprintf("get token detail failed, err:%v", err))
+			}
+			if tokenDetail.LoginType != ServiceAccountLogin {
+				return next(c)
+			}
+
+			if err := d.VerifyServiceAccountToken(c.Request().Context(), tokenDetail); err != nil {
+				return echo.NewHTTPError(http.StatusUnauthorized, err.Error())
+			}
+			return next(c)
+		}
+	}
+}
@@ -0,0 +1,65 @@
+package biz
+
+import (
+	"context"
+	"io"
+	"testing"
+
+	pkgErr "github.com/actiontech/dms/internal/dms/pkg/errors"
+	jwtPkg "github.com/actiontech/dms/pkg/dms-common/api/jwt"
+	utilLog "github.com/actiontech/dms/pkg/dms-common/pkg/log"
+	"github.com/stretchr/testify/assert"
+)
+
+type mockServiceAccountRepo struct {
+	ServiceAccountRepo
+	accounts map[string]*ServiceAccount
+}
+
+func (m *mockServiceAccountRepo) GetServiceAccountByClientID(_ context.Context, clientID string) (*ServiceAccount, error) {
+	for _, sa := range m.accounts {
+		if sa.ClientID == clientID {
+			copied := *sa
+			return &copied, nil
+		}
+	}
+	return nil, pkgErr.ErrStorageNoData
+}
+
+func TestServiceAccountIssueToken(t *testing.T) {
+	secretHash, err := hashSecret("secret")
+	assert.NoError(t, err)
+
+	user := &User{UID: "sa_user", Name: "ci-bot", Stat: UserStatOK, UserAuthenticationType: UserAuthenticationTypeServiceAccount}
+	userRepo := &mockUserRepo{users: map[string]*User{user.UID: user}}
+	repo := &mockServiceAccountRepo{accounts: map[string]*ServiceAccount{
+		"sa_1": {UID: "sa_1", UserUID: user.UID, ClientID: "dms-sa-sa_1", ClientSecretHash: secretHash},
+	}}
+	uc := NewServiceAccountUsecase(utilLog.NewMyLogger(io.Discard), nil, repo, &UserUsecase{repo: userRepo})
+
+	_, _, _, err = uc.IssueToken(context.Background(), "dms-sa-sa_1", "wrong")
+	assert.ErrorIs(t, err, ErrServiceAccountInvalidClient)
+	_, _, _, err = uc.IssueToken(context.Background(), "unknown", "secret")
+	assert.ErrorIs(t, err, ErrServiceAccountInvalidClient)
+
+	_, token, _, err := uc.IssueToken(context.Background(), "dms-sa-sa_1", "secret")
+	assert.NoError(t, err)
+	tokenDetail, err := jwtPkg.ParseTokenDetailFromJwtTokenStr(token)
+	assert.NoError(t, err)
+	assert.Equal(t, user.UID, tokenDetail.UID)
+	assert.Equal(t, ServiceAccountLogin, tokenDetail.LoginType)
+	assert.NoError(t, uc.VerifyServiceAccountToken(context.Background(), tokenDetail))
+
+	// 禁用后不能再获取 token，已签发的 token 也随之失效
+	user.Stat = UserStatDisable
+	_, _, _, err = uc.IssueToken(context.Background(), "dms-sa-sa_1", "secret")
+	assert.ErrorIs(t, err, ErrServiceAccountUnavailable)
+	assert.ErrorIs(t, uc.VerifyServiceAccountToken(context.Background(), tokenDetail), ErrServiceAccountUnavailable)
+}
+
+func TestServiceAccountCannotLogin(t *testing.T) {
+	uc := &UserUsecase{}
+	typ, ok := uc.getLoginVerifierType(&User{UserAuthenticationType: UserAuthenticationTypeServiceAccount}, &LDAPConfiguration{Enable: true})
+	assert.True(t, ok)
+	assert.Equal(t, loginVerifierTypeUnknown, typ)
+}
@@ -0,0 +1,254 @@
+package biz
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+
+	v1 "github.com/actiontech/dms/api/dms/service/v1"
+	pkgConst "github.com/actiontech/dms/internal/dms/pkg/constant"
+	pkgErr "github.com/actiontech/dms/internal/dms/pkg/errors"
+	"github.com/actiontech/dms/pkg/dms-common/i18nPkg"
+	utilLog "github.com/actiontech/dms/pkg/dms-common/pkg/log"
+	pkgRand "github.com/actiontech/dms/pkg/rand"
+)
+
+// ServiceOpPermissionManifest 注册服务（插件、代理服务）提供的权限清单，版本变化时重新同步
+type ServiceOpPermissionManifest struct {
+	UID           string
+	ServiceName   string
+	Version       string
+	OpPermissions []*ServiceOpPermission
+}
+
+type ServiceOpPermission struct {
+	UID             string
+	Name            i18nPkg.I18nStr
+	Desc            i18nPkg.I18nStr
+	Module          string
+	RangeType       OpRangeType
+	BuiltinRoleUIDs []string
+}
+
+type ServiceOpPermissionRepo interface {
+	GetServiceOpPermissionManifest(ctx context.Context, serviceName string) (*ServiceOpPermissionManifest, error)
+	SaveServiceOpPermissionManifest(ctx context.Context, manifest *ServiceOpPermissionManifest) error
+}
+
+// serviceOpPermissionBuiltinRoles 注册服务的权限可以加入的内置角色，项目管理员默认拥有项目内所有权限，无需加入
+var serviceOpPermissionBuiltinRoles = []string{
+	pkgConst.UIDOfRoleDevEngineer,
+	pkgConst.UIDOfRoleDevManager,
+	pkgConst.UIDOfRoleOpsEngineer,
+}
+
+type ServiceOpPermissionUsecase struct {
+	tx                        TransactionGenerator
+	repo                      ServiceOpPermissionRepo
+	opPermissionRepo          OpPermissionRepo
+	roleRepo                  RoleRepo
+	roleUsecase               *RoleUsecase
+	opPermissionVerifyUsecase *OpPermissionVerifyUsecase
+	log                       *utilLog.Helper
+}
+
+func NewServiceOpPermissionUsecase(log utilLog.Logger, tx TransactionGenerator, repo ServiceOpPermissionRepo, opPermissionRepo OpPermissionRepo,
+	roleRepo RoleRepo, roleUsecase *RoleUsecase, opPermissionVerifyUsecase *OpPermissionVerifyUsecase) *ServiceOpPermissionUsecase {
+	return &ServiceOpPermissionUsecase{
+		tx:                        tx,
+		repo:                      repo,
+		opPermissionRepo:          opPermissionRepo,
+		roleRepo:                  roleRepo,
+		roleUsecase:               roleUsecase,
+		opPermissionVerifyUsecase: opPermissionVerifyUsecase,
+		log:                       utilLog.NewHelper(log, utilLog.WithMessageKey("biz.service_op_permission")),
+	}
+}
+
+func (m *ServiceOpPermissionManifest) check() error {
+	uids := make(map[string]struct{}, len(m.OpPermissions))
+	for _, op := range m.OpPermissions {
+		if op.UID == "" {
+			return fmt.Errorf("op permission uid is empty")
+		}
+		if _, ok := uids[op.UID]; ok {
+			return fmt.Errorf("duplicate op permission uid %s", op.UID)
+		}
+		uids[op.UID] = struct{}{}
+		if op.Name.GetStrInLang(i18nPkg.DefaultLang) == "" {
+			return fmt.Errorf("op permission %s must have a name in %s", op.UID, i18nPkg.DefaultLang)
+		}
+		switch op.RangeType {
+		case OpRangeTypeProject, OpRangeTypeDBService:
+		default:
+			return fmt.Errorf("op permission %s has unsupported range type %s", op.UID, op.RangeType)
+		}
+		for _, roleUid := range op.BuiltinRoleUIDs {
+			if !slices.Contains(serviceOpPermissionBuiltinRoles, roleUid) {
+				return fmt.Errorf("op permission %s can not be granted to role %s, only built-in roles %v are allowed", op.UID, roleUid, serviceOpPermissionBuiltinRoles)
+			}
+		}
+	}
+	return nil
+}
+
+// SyncServiceOpPermissions 同步注册服务的权限清单：新增或更新清单中的权限，并加入声明的内置角色；
+// 新版本中不再提供的权限标记为已移除，不再展示及授予，已有的角色授权保留，服务回滚后重新注册即可恢复
+func (d *ServiceOpPermissionUsecase) SyncServiceOpPermissions(ctx context.Context, manifest *ServiceOpPermissionManifest) (err error) {
+	if err := manifest.check(); err != nil {
+		return fmt.Errorf("invalid op permission manifest of %s: %v", manifest.ServiceName, err)
+	}
+
+	current, err := d.repo.GetServiceOpPermissionManifest(ctx, manifest.ServiceName)
+	if err != nil && !errors.Is(err, pkgErr.ErrStorageNoData) {
+		return fmt.Errorf("get op permission manifest failed: %v", err)
+	}
+	if current != nil {
+		if current.Version == manifest.Version {
+			return nil
+		}
+		manifest.UID = current.UID
+	}
+
+	provided, _, err := d.opPermissionRepo.ListOpPermissions(ctx, &ListOpPermissionsOption{
+		PageNumber:   0,
+		LimitPerPage: 9999,
+		OrderBy:      OpPermissionFieldUID,
+		FilterByOptions: pkgConst.NewFilterOptions(pkgConst.FilterLogicAnd,
+			pkgConst.NewConditionGroup(pkgConst.FilterLogicAnd, pkgConst.FilterCondition{
+				Field:    string(OpPermissionFieldProvider),
+				Operator: pkgConst.FilterOperatorEqual,
+				Value:    manifest.ServiceName,
+			}),
+		),
+	})
+	if err != nil {
+		return fmt.Errorf("list op permissions of %s failed: %v", manifest.ServiceName, err)
+	}
+
+	tx := d.tx.BeginTX(ctx)
+	defer func() {
+		if err != nil {
+			err = tx.RollbackWithError(d.log, err)
+		}
+	}()
+
+	inManifest := make(map[string]struct{}, len(manifest.OpPermissions))
+	for _, op := range manifest.OpPermissions {
+		inManifest[op.UID] = struct{}{}
+		if err := d.saveServiceOpPermission(tx, manifest.ServiceName, op); err != nil {
+			return err
+		}
+	}
+	for _, op := range provided {
+		if _, ok := inManifest[op.UID]; ok || op.Removed {
+			continue
+		}
+		op.Removed = true
+		if err := d.opPermissionRepo.UpdateOpPermission(tx, op); err != nil {
+			return fmt.Errorf("remove op permission %s failed: %v", op.UID, err)
+		}
+		d.log.Infof("op permission %s is no longer provided by %s, mark it as removed", op.UID, manifest.ServiceName)
+	}
+
+	if err := d.grantBuiltinRoles(tx, manifest.OpPermissions); err != nil {
+		return err
+	}
+
+	if manifest.UID == "" {
+		if manifest.UID, err = pkgRand.GenStrUid(); err != nil {
+			return err
+		}
+	}
+	if err := d.repo.SaveServiceOpPermissionManifest(tx, manifest); err != nil {
+		return fmt.Errorf("save op permission manifest failed: %v", err)
+	}
+
+	if err := tx.Commit(d.log); err != nil {
+		return fmt.Errorf("commit tx failed: %v", err)
+	}
+	d.opPermissionVerifyUsecase.InvalidateAllPermissions(ctx)
+	d.log.Infof("op permission manifest of %s is synced to version %s", manifest.ServiceName, manifest.Version)
+	return nil
+}
+
+func (d *ServiceOpPermissionUsecase) saveServiceOpPermission(ctx context.Context, serviceName string, op *ServiceOpPermission) error {
+	existing, err := d.opPermissionRepo.GetOpPermission(ctx, op.UID)
+	if err != nil && !errors.Is(err, pkgErr.ErrStorageNoData) {
+		return fmt.Errorf("get op permission %s failed: %v", op.UID, err)
+	}
+	// 权限 uid 全局唯一，不允许覆盖内置权限或其他服务提供的权限
+	if existing != nil && existing.Provider != serviceName {
+		return fmt.Errorf("op permission %s conflicts with an op permission provided by %s", op.UID, opPermissionProviderName(existing))
+	}
+
+	opPermission := &OpPermission{
+		UID:       op.UID,
+		Name:      op.Name.GetStrInLang(i18nPkg.DefaultLang),
+		Module:    Module(op.Module),
+		RangeType: op.RangeType,
+		Desc:      op.Desc.GetStrInLang(i18nPkg.DefaultLang),
+		Service:   v1.Service(serviceName),
+		Provider:  serviceName,
+		NameI18n:  op.Name,
+		DescI18n:  op.Desc,
+	}
+	if existing == nil {
+		err = d.opPermissionRepo.SaveOpPermission(ctx, opPermission)
+	} else {
+		err = d.opPermissionRepo.UpdateOpPermission(ctx, opPermission)
+	}
+	if err != nil {
+		return fmt.Errorf("save op permission %s failed: %v", op.UID, err)
+	}
+	return nil
+}
+
+func opPermissionProviderName(op *OpPermission) string {
+	if op.Provider == "" {
+		return "dms"
+	}
+	return op.Provider
+}
+
+// grantBuiltinRoles 仅追加权限到内置角色，不收回管理员在内置角色上的调整；继承内置角色的角色同步更新生效权限
+func (d *ServiceOpPermissionUsecase) grantBuiltinRoles(ctx context.Context, ops []*ServiceOpPermission) error {
+	for _, roleUid := range serviceOpPermissionBuiltinRoles {
+		role, err := d.roleRepo.GetRole(ctx, roleUid)
+		if err != nil {
+			return fmt.Errorf("get role %s failed: %v", roleUid, err)
+		}
+		own := append([]string{}, role.ownOpPermissionUIDs()...)
+		granted := false
+		for _, op := range ops {
+			if !slices.Contains(op.BuiltinRoleUIDs, roleUid) || slices.Contains(own, op.UID) {
+				continue
+			}
+			own = append(own, op.UID)
+			granted = true
+		}
+		if !granted {
+			continue
+		}
+
+		// 同时记为角色自身声明的权限，避免角色重新计算继承权限时丢失
+		ownDeclared := role.OwnOpPermissionUIDs != nil
+		role.OwnOpPermissionUIDs = own
+		plans, err := d.roleUsecase.planRoleOpPermissions(ctx, role)
+		if err != nil {
+			return fmt.Errorf("plan op permissions of role %s failed: %v", roleUid, err)
+		}
+		for _, plan := range plans {
+			if err := d.roleRepo.ReplaceOpPermissionsInRole(ctx, plan.Role.UID, plan.After); err != nil {
+				return fmt.Errorf("replace op permissions in role %s failed: %v", plan.Role.UID, err)
+			}
+		}
+		if ownDeclared {
+			if err := d.roleRepo.UpdateRole(ctx, role); err != nil {
+				return fmt.Errorf("update role %s failed: %v", roleUid, err)
+			}
+		}
+	}
+	return nil
+}
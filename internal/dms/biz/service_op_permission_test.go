@@ -0,0 +1,161 @@
+package biz
+
+import (
+	"context"
+	"slices"
+	"testing"
+
+	pkgConst "github.com/actiontech/dms/internal/dms/pkg/constant"
+	pkgErr "github.com/actiontech/dms/internal/dms/pkg/errors"
+	"github.com/actiontech/dms/pkg/dms-common/i18nPkg"
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/text/language"
+)
+
+type mockServiceOpPermissionRepo struct {
+	manifests map[string]*ServiceOpPermissionManifest
+}
+
+func (m *mockServiceOpPermissionRepo) GetServiceOpPermissionManifest(_ context.Context, serviceName string) (*ServiceOpPermissionManifest, error) {
+	if manifest, ok := m.manifests[serviceName]; ok {
+		return manifest, nil
+	}
+	return nil, pkgErr.ErrStorageNoData
+}
+
+func (m *mockServiceOpPermissionRepo) SaveServiceOpPermissionManifest(_ context.Context, manifest *ServiceOpPermissionManifest) error {
+	m.manifests[manifest.ServiceName] = manifest
+	return nil
+}
+
+// mockProviderOpPermissionRepo 按 provider 过滤列出权限，其余查询按 uid 读写内存
+type mockProviderOpPermissionRepo struct {
+	mockOpPermissionRepoForTest
+}
+
+func (m *mockProviderOpPermissionRepo) SaveOpPermission(_ context.Context, op *OpPermission) error {
+	m.permissions[op.UID] = op
+	return nil
+}
+func (m *mockProviderOpPermissionRepo) UpdateOpPermission(_ context.Context, op *OpPermission) error {
+	m.permissions[op.UID] = op
+	return nil
+}
+func (m *mockProviderOpPermissionRepo) GetOpPermission(_ context.Context, uid string) (*OpPermission, error) {
+	if p, ok := m.permissions[uid]; ok {
+		return p, nil
+	}
+	return nil, pkgErr.ErrStorageNoData
+}
+func (m *mockProviderOpPermissionRepo) ListOpPermissions(_ context.Context, opt *ListOpPermissionsOption) ([]*OpPermission, int64, error) {
+	provider := opt.FilterByOptions.Groups[0].Conditions[0].Value
+	ret := make([]*OpPermission, 0)
+	for _, p := range m.permissions {
+		if p.Provider == provider {
+			op := *p
+			ret = append(ret, &op)
+		}
+	}
+	return ret, int64(len(ret)), nil
+}
+
+type mockRoleOpPermissionRepo struct {
+	RoleRepo
+	granted map[string][]string
+	parents map[string][]string
+}
+
+func (m *mockRoleOpPermissionRepo) GetRole(_ context.Context, roleUid string) (*Role, error) {
+	role := &Role{UID: roleUid, ParentRoleUIDs: m.parents[roleUid]}
+	for _, uid := range m.granted[roleUid] {
+		role.OpPermissions = append(role.OpPermissions, &OpPermission{UID: uid})
+	}
+	return role, nil
+}
+
+func (m *mockRoleOpPermissionRepo) ListChildRoles(ctx context.Context, roleUid string) ([]*Role, error) {
+	ret := make([]*Role, 0)
+	for uid, parents := range m.parents {
+		if slices.Contains(parents, roleUid) {
+			role, _ := m.GetRole(ctx, uid)
+			role.OwnOpPermissionUIDs = []string{"own_" + uid}
+			ret = append(ret, role)
+		}
+	}
+	return ret, nil
+}
+
+func (m *mockRoleOpPermissionRepo) ReplaceOpPermissionsInRole(_ context.Context, roleUid string, opPermissionUids []string) error {
+	m.granted[roleUid] = opPermissionUids
+	return nil
+}
+
+func TestSyncServiceOpPermissions(t *testing.T) {
+	opRepo := &mockProviderOpPermissionRepo{mockOpPermissionRepoForTest{permissions: map[string]*OpPermission{
+		pkgConst.UIDOfOpPermissionExportCreate: {UID: pkgConst.UIDOfOpPermissionExportCreate},
+	}}}
+	// dev_lead 继承内置的开发工程师角色
+	roleRepo := &mockRoleOpPermissionRepo{granted: map[string][]string{}, parents: map[string][]string{"dev_lead": {pkgConst.UIDOfRoleDevEngineer}}}
+	repo := &mockServiceOpPermissionRepo{manifests: map[string]*ServiceOpPermissionManifest{}}
+	uc := NewServiceOpPermissionUsecase(&noopLogger{}, &mockTx{}, repo, opRepo, roleRepo, &RoleUsecase{repo: roleRepo},
+		newTestOpPermissionVerifyUsecase(&mockUserRepo{}, &mockOpPermissionVerifyRepo{}))
+	ctx := context.Background()
+
+	newOp := func(uid string, roles ...string) *ServiceOpPermission {
+		return &ServiceOpPermission{
+			UID:             uid,
+			Name:            i18nPkg.I18nStr{language.Chinese: "执行作业", language.English: "Execute job"},
+			RangeType:       OpRangeTypeProject,
+			BuiltinRoleUIDs: roles,
+		}
+	}
+
+	t.Run("invalid_manifest", func(t *testing.T) {
+		assert.Error(t, uc.SyncServiceOpPermissions(ctx, &ServiceOpPermissionManifest{ServiceName: "svc", Version: "1",
+			OpPermissions: []*ServiceOpPermission{newOp("job_run"), newOp("job_run")}}))
+		assert.Error(t, uc.SyncServiceOpPermissions(ctx, &ServiceOpPermissionManifest{ServiceName: "svc", Version: "1",
+			OpPermissions: []*ServiceOpPermission{newOp("job_run", pkgConst.UIDOfRoleProjectAdmin)}}))
+		op := newOp("job_run")
+		op.RangeType = OpRangeTypeGlobal
+		assert.Error(t, uc.SyncServiceOpPermissions(ctx, &ServiceOpPermissionManifest{ServiceName: "svc", Version: "1",
+			OpPermissions: []*ServiceOpPermission{op}}))
+	})
+
+	t.Run("conflict_with_builtin", func(t *testing.T) {
+		assert.Error(t, uc.SyncServiceOpPermissions(ctx, &ServiceOpPermissionManifest{ServiceName: "svc", Version: "1",
+			OpPermissions: []*ServiceOpPermission{newOp(pkgConst.UIDOfOpPermissionExportCreate)}}))
+		assert.Empty(t, repo.manifests)
+	})
+
+	t.Run("sync_and_grant_builtin_role", func(t *testing.T) {
+		assert.NoError(t, uc.SyncServiceOpPermissions(ctx, &ServiceOpPermissionManifest{ServiceName: "svc", Version: "1",
+			OpPermissions: []*ServiceOpPermission{newOp("job_run", pkgConst.UIDOfRoleDevEngineer), newOp("job_view")}}))
+		assert.Equal(t, "svc", opRepo.permissions["job_run"].Provider)
+		assert.Equal(t, "执行作业", opRepo.permissions["job_run"].Name)
+		assert.Equal(t, []string{"job_run"}, roleRepo.granted[pkgConst.UIDOfRoleDevEngineer])
+		assert.Equal(t, []string{"own_dev_lead", "job_run"}, roleRepo.granted["dev_lead"])
+		assert.NotEmpty(t, repo.manifests["svc"].UID)
+	})
+
+	t.Run("other_service_cannot_take_over", func(t *testing.T) {
+		assert.Error(t, uc.SyncServiceOpPermissions(ctx, &ServiceOpPermissionManifest{ServiceName: "other", Version: "1",
+			OpPermissions: []*ServiceOpPermission{newOp("job_run")}}))
+	})
+
+	t.Run("same_version_skipped", func(t *testing.T) {
+		assert.NoError(t, uc.SyncServiceOpPermissions(ctx, &ServiceOpPermissionManifest{ServiceName: "svc", Version: "1"}))
+		assert.False(t, opRepo.permissions["job_view"].Removed)
+	})
+
+	t.Run("missing_permission_removed_and_restored", func(t *testing.T) {
+		assert.NoError(t, uc.SyncServiceOpPermissions(ctx, &ServiceOpPermissionManifest{ServiceName: "svc", Version: "2",
+			OpPermissions: []*ServiceOpPermission{newOp("job_run", pkgConst.UIDOfRoleDevEngineer)}}))
+		assert.True(t, opRepo.permissions["job_view"].Removed)
+		assert.False(t, opRepo.permissions["job_run"].Removed)
+		assert.Equal(t, []string{"job_run"}, roleRepo.granted[pkgConst.UIDOfRoleDevEngineer])
+
+		assert.NoError(t, uc.SyncServiceOpPermissions(ctx, &ServiceOpPermissionManifest{ServiceName: "svc", Version: "1",
+			OpPermissions: []*ServiceOpPermission{newOp("job_run"), newOp("job_view")}}))
+		assert.False(t, opRepo.permissions["job_view"].Removed)
+	})
+}
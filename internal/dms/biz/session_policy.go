@@ -0,0 +1,121 @@
+package biz
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+var (
+	ErrLoginSessionIdleTimeout = errors.New("login session has been idle for too long, please login again")
+	ErrLoginSessionMaxAge      = errors.New("login session has exceeded the maximum lifetime, please login again")
+	ErrAccessTokenIdleTimeout  = errors.New("access token has not been used for too long")
+)
+
+// SessionPolicy 登录会话超时策略，值为 0 表示不限制
+type SessionPolicy struct {
+	// IdleTimeout 超过该时长没有请求时会话失效
+	IdleTimeout time.Duration
+	// MaxAge 会话自登录起的最长有效期，到期后需重新登录，刷新 token 不会延长
+	MaxAge time.Duration
+}
+
+// AccessTokenPolicy access token 的有效期策略，值为 0 表示不限制
+type AccessTokenPolicy struct {
+	// IdleTimeoutDays 超过该天数未使用的 access token 失效
+	IdleTimeoutDays uint
+	// MaxAgeDays 生成 access token 时允许的最长有效期
+	MaxAgeDays uint
+}
+
+// GetSessionPolicy 管理员策略未配置时沿用普通用户的策略
+func (c *LoginConfiguration) GetSessionPolicy(isAdmin bool) *SessionPolicy {
+	idle, maxAge := c.SessionIdleTimeoutMinutes, c.SessionMaxAgeMinutes
+	if isAdmin {
+		if c.AdminSessionIdleTimeoutMinutes > 0 {
+			idle = c.AdminSessionIdleTimeoutMinutes
+		}
+		if c.AdminSessionMaxAgeMinutes > 0 {
+			maxAge = c.AdminSessionMaxAgeMinutes
+		}
+	}
+	return &SessionPolicy{
+		IdleTimeout: time.Duration(idle) * time.Minute,
+		MaxAge:      time.Duration(maxAge) * time.Minute,
+	}
+}
+
+func (c *LoginConfiguration) HasAdminSessionPolicy() bool {
+	return c.AdminSessionIdleTimeoutMinutes > 0 || c.AdminSessionMaxAgeMinutes > 0
+}
+
+func (c *LoginConfiguration) GetAccessTokenPolicy() *AccessTokenPolicy {
+	return &AccessTokenPolicy{
+		IdleTimeoutDays: c.AccessTokenIdleTimeoutDays,
+		MaxAgeDays:      c.AccessTokenMaxAgeDays,
+	}
+}
+
+func (p *SessionPolicy) Check(session *LoginSession, now time.Time) error {
+	if p.MaxAge > 0 && now.Sub(session.CreatedAt) > p.MaxAge {
+		return ErrLoginSessionMaxAge
+	}
+	if p.IdleTimeout > 0 && !session.LastSeenAt.IsZero() && now.Sub(session.LastSeenAt) > p.IdleTimeout {
+		return ErrLoginSessionIdleTimeout
+	}
+	return nil
+}
+
+// ExpiredAt 会话的有效期不超过最长有效期
+func (p *SessionPolicy) ExpiredAt(session *LoginSession, renewTo time.Time) time.Time {
+	if p.MaxAge > 0 && session.CreatedAt.Add(p.MaxAge).Before(renewTo) {
+		return session.CreatedAt.Add(p.MaxAge)
+	}
+	return renewTo
+}
+
+func (p *AccessTokenPolicy) CheckExpirationDays(expirationDays uint64) error {
+	if p.MaxAgeDays > 0 && expirationDays > uint64(p.MaxAgeDays) {
+		return fmt.Errorf("the expiration days of access token must not exceed %d days", p.MaxAgeDays)
+	}
+	return nil
+}
+
+func (p *AccessTokenPolicy) Check(tokenInfo *AccessTokenInfo, now time.Time) error {
+	if p.IdleTimeoutDays == 0 {
+		return nil
+	}
+	lastActive := tokenInfo.LastUsedAt
+	if lastActive.IsZero() {
+		lastActive = tokenInfo.CreatedAt
+	}
+	if now.Sub(lastActive) > time.Duration(p.IdleTimeoutDays)*24*time.Hour {
+		return ErrAccessTokenIdleTimeout
+	}
+	return nil
+}
+
+func (d *UserUsecase) getSessionPolicy(ctx context.Context, userUID string) (*SessionPolicy, error) {
+	loginC, err := d.loginConfigurationUsecase.getCachedLoginConfiguration(ctx)
+	if err != nil {
+		return nil, err
+	}
+	isAdmin := false
+	// 仅在配置了管理员策略时判断用户身份，避免每个请求都查询权限
+	if loginC.HasAdminSessionPolicy() {
+		isAdmin, err = d.OpPermissionVerifyUsecase.CanOpGlobal(ctx, userUID, false)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return loginC.GetSessionPolicy(isAdmin), nil
+}
+
+func (d *UserUsecase) getAccessTokenPolicy(ctx context.Context) (*AccessTokenPolicy, error) {
+	loginC, err := d.loginConfigurationUsecase.getCachedLoginConfiguration(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return loginC.GetAccessTokenPolicy(), nil
+}
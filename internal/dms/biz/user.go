@@ -5,7 +5,6 @@ import (
 	"crypto/tls"
 	"errors"
 	"fmt"
-	"strconv"
 	"time"
 
 	pkgConst "github.com/actiontech/dms/internal/dms/pkg/constant"
@@ -29,6 +28,8 @@ const (
 	UserAuthenticationTypeLDAP   UserAuthenticationType = "ldap"                  // user verify through ldap
 	UserAuthenticationTypeDMS    UserAuthenticationType = _const.DmsComponentName // user verify through dms
 	UserAuthenticationTypeOAUTH2 UserAuthenticationType = "oauth2"                // user verify through oauth2
+	// service account can not login, it gets short-lived token through client credentials
+	UserAuthenticationTypeServiceAccount UserAuthenticationType = "service_account"
 )
 
 type UserSystem string
@@ -50,6 +51,8 @@ func ParseUserAuthenticationType(typ string) (UserAuthenticationType, error) {
 		return UserAuthenticationTypeDMS, nil
 	case string(UserAuthenticationTypeOAUTH2):
 		return UserAuthenticationTypeOAUTH2, nil
+	case string(UserAuthenticationTypeServiceAccount):
+		return UserAuthenticationTypeServiceAccount, nil
 	default:
 		return "", fmt.Errorf("invalid user authentication type: %s", typ)
 	}
@@ -100,13 +103,27 @@ type User struct {
 	Deleted bool
 	// 业务写权开关，为 false 时系统管理员/admin 不通过全局身份放行业务写操作
 	BusinessWritePermission bool
+	// 密码最近一次修改时间，用于判断密码是否过期
+	PasswordChangedAt time.Time
+	// 登录失败锁定相关状态
+	LoginFailedCount uint
+	LoginFailedAt    time.Time
+	LockedUntil      time.Time
 }
 
 type AccessTokenInfo struct {
 	UID         string
 	UserID      uint
+	Name        string
 	Token       string
 	ExpiredTime time.Time
+	// 只读 token 仅允许查询类请求
+	ReadOnly bool
+	// 限定 token 可访问的项目，为空表示不限制
+	ProjectUIDs []string
+	LastUsedAt  time.Time
+	LastUsedIP  string
+	CreatedAt   time.Time
 }
 
 func initUsers() []*User {
@@ -136,7 +153,8 @@ func newUser(args *CreateUserArgs) (user *User, err error) {
 	if args.Name == "" {
 		return nil, fmt.Errorf("name is empty")
 	}
-	if !args.IsDisabled {
+	// 服务账号不可登录，无需设置密码
+	if !args.IsDisabled && args.UserAuthenticationType != UserAuthenticationTypeServiceAccount {
 		if args.Password == "" {
 			return nil, fmt.Errorf("password is empty")
 		}
@@ -202,8 +220,26 @@ type UserRepo interface {
 	GetUserByThirdPartyUserID(ctx context.Context, thirdPartyUserUID string) (*User, error)
 	SaveAccessToken(ctx context.Context, accessTokenInfo *AccessTokenInfo) error
 	GetAccessTokenByUser(ctx context.Context, UserUid string) (*AccessTokenInfo, error)
+	GetAccessToken(ctx context.Context, accessTokenUid string) (*AccessTokenInfo, error)
+	GetAccessTokenByToken(ctx context.Context, userUid, token string) (*AccessTokenInfo, error)
+	ListAccessTokensByUser(ctx context.Context, userUid string) ([]*AccessTokenInfo, error)
+	UpdateAccessTokenLastUsed(ctx context.Context, accessTokenUid string, lastUsedAt time.Time, lastUsedIP string) error
+	DelAccessToken(ctx context.Context, accessTokenUid string) error
 	RecordLoginSession(ctx context.Context, userUID, sessionID string) error
 	GetLatestLoginSession(ctx context.Context, userUID string) (sessionID string, exists bool, err error)
+	SaveLoginSession(ctx context.Context, session *LoginSession) error
+	GetLoginSession(ctx context.Context, sessionID string) (*LoginSession, error)
+	ListActiveLoginSessions(ctx context.Context, userUID string, now time.Time) ([]*LoginSession, error)
+	UpdateLoginSessionLastSeen(ctx context.Context, sessionID string, lastSeenAt time.Time, lastSeenIP string) error
+	UpdateLoginSessionExpiredAt(ctx context.Context, sessionID string, expiredAt time.Time) error
+	RevokeLoginSession(ctx context.Context, sessionID string, revokedAt time.Time) error
+	RevokeUserLoginSessions(ctx context.Context, userUID string, revokedAt time.Time) error
+	SavePasswordHistory(ctx context.Context, userUid, passwordHash string) error
+	ListPasswordHistories(ctx context.Context, userUid string, limit uint) (passwordHashes []string, err error)
+	// IncreaseUserLoginFailedCount 原子地累计登录失败次数，上次失败早于 windowStart 时从 1 重新计数，返回累计后的次数及本轮首次失败时间
+	IncreaseUserLoginFailedCount(ctx context.Context, userUid string, now, windowStart time.Time) (count uint, failedAt time.Time, err error)
+	// LockUserLogin 登录失败次数仍不少于 minFailedCount 时锁定账号并清空失败计数
+	LockUserLogin(ctx context.Context, userUid string, minFailedCount uint, lockedUntil time.Time) error
 }
 
 // SqlWorkbenchUser SqlWorkbench用户缓存
@@ -255,9 +291,15 @@ type UserUsecase struct {
 	cloudBeaverRepo           CloudbeaverRepo
 	gatewayUsecase            *GatewayUsecase
 	sqlWorkbenchLifecycle     SqlWorkbenchLifecycle
+	separationOfDutiesUsecase *SeparationOfDutiesUsecase
 	log                       *utilLog.Helper
 }
 
+// SetSeparationOfDutiesUsecase 用户加入用户组时按绑定了该用户组的成员组校验互斥规则，未设置时不校验
+func (d *UserUsecase) SetSeparationOfDutiesUsecase(separationOfDutiesUsecase *SeparationOfDutiesUsecase) {
+	d.separationOfDutiesUsecase = separationOfDutiesUsecase
+}
+
 // SetSqlWorkbenchLifecycle 注入策略 B 清理能力（由 apiserver 在 SqlWorkbenchService 就绪后接线）
 func (d *UserUsecase) SetSqlWorkbenchLifecycle(lifecycle SqlWorkbenchLifecycle) {
 	d.sqlWorkbenchLifecycle = lifecycle
@@ -288,7 +330,7 @@ func (d *UserUsecase) UserLogin(ctx context.Context, name string, password strin
 	}
 	userUid, err := loginVerifier.Verify(ctx, name, password)
 	if err != nil {
-		return "", twoFactorEnabled, phone, fmt.Errorf("verify user login failed: %v", err)
+		return "", twoFactorEnabled, phone, fmt.Errorf("verify user login failed: %w", err)
 	}
 
 	loginC, err := d.loginConfigurationUsecase.GetLoginConfiguration(ctx)
@@ -363,6 +405,11 @@ const (
 
 // determine whether the login conditions are met according to the order of login priority
 func (d *UserUsecase) getLoginVerifierType(user *User, ldapC *LDAPConfiguration) (verifyType verifierType, userExist bool) {
+	// service account can not login through account and password
+	if user != nil && user.UserAuthenticationType == UserAuthenticationTypeServiceAccount {
+		return loginVerifierTypeUnknown, true
+	}
+
 	// ldap login condition
 	if ldapC != nil && ldapC.Enable {
 		if user != nil && user.UserAuthenticationType == UserAuthenticationTypeLDAP {
@@ -398,9 +445,31 @@ type LoginBase struct {
 func (l *LoginBase) Verify(c context.Context, userName, password string) (userUID string, err error) {
 	if l.user.Stat == UserStatDisable {
 		return l.user.UID, fmt.Errorf("user %s not exist or can not login", userName)
-	} else if l.user.Password != password {
+	}
+
+	now := time.Now()
+	if l.user.IsPasswordPolicyApplicable() && l.user.IsLocked(now) {
+		return l.user.UID, ErrUserLoginLocked
+	}
+
+	if l.user.Password != password {
+		loginC, err := l.userUsecase.loginConfigurationUsecase.GetLoginConfiguration(c)
+		if err != nil {
+			return l.user.UID, err
+		}
+		locked, err := l.userUsecase.recordLoginFailure(c, l.user, loginC.GetLockoutPolicy(), now)
+		if err != nil {
+			return l.user.UID, err
+		}
+		if locked {
+			return l.user.UID, ErrUserLoginLocked
+		}
 		return l.user.UID, fmt.Errorf("user %s password not match", userName)
 	}
+
+	if err := l.userUsecase.resetLoginFailure(c, l.user); err != nil {
+		return l.user.UID, err
+	}
 	return l.user.UID, nil
 }
 
@@ -562,6 +631,11 @@ func (d *UserUsecase) AddUser(ctx context.Context, currentUserUid string, args *
 	if err != nil {
 		return "", fmt.Errorf("new user failed: %v", err)
 	}
+	if u.Password != "" {
+		if err := d.checkNewPassword(ctx, u, u.Password); err != nil {
+			return "", err
+		}
+	}
 
 	tx := d.tx.BeginTX(ctx)
 	defer func() {
@@ -570,6 +644,12 @@ func (d *UserUsecase) AddUser(ctx context.Context, currentUserUid string, args *
 		}
 	}()
 
+	if u.Password != "" {
+		if err := d.recordPasswordChange(tx, u); err != nil {
+			return "", err
+		}
+	}
+
 	if err := d.repo.SaveUser(tx, u); err != nil {
 		return "", fmt.Errorf("save user failed: %v", err)
 	}
@@ -654,6 +734,9 @@ func (d *UserUsecase) InsureUserToUserGroups(ctx context.Context, userGroupUids
 		} else if !exist {
 			return fmt.Errorf("user group not exist")
 		}
+		if err := d.checkUserGroupsSeparationOfDuties(ctx, userGroupUids, userUid); err != nil {
+			return err
+		}
 	}
 
 	if err := d.repo.ReplaceUserGroupsInUser(ctx, userUid, userGroupUids); err != nil {
@@ -663,6 +746,31 @@ func (d *UserUsecase) InsureUserToUserGroups(ctx context.Context, userGroupUids
 	return nil
 }
 
+// checkUserGroupsSeparationOfDuties 用户通过用户组获得绑定了该用户组的各成员组的授权，需满足各项目的互斥规则
+func (d *UserUsecase) checkUserGroupsSeparationOfDuties(ctx context.Context, userGroupUids []string, userUid string) error {
+	if d.separationOfDutiesUsecase == nil {
+		return nil
+	}
+	for _, userGroupUid := range userGroupUids {
+		group, err := d.userGroupRepo.GetUserGroup(ctx, userGroupUid)
+		if err != nil {
+			return fmt.Errorf("get user group failed: %v", err)
+		}
+		// 禁用的用户组不生效
+		if group.Stat != UserGroupStatOK {
+			continue
+		}
+		memberGroups, err := d.userGroupRepo.ListMemberGroupsBoundToUserGroup(ctx, userGroupUid)
+		if err != nil {
+			return fmt.Errorf("list member groups bound to user group failed: %v", err)
+		}
+		if err := d.separationOfDutiesUsecase.CheckBoundMemberGroupUsers(ctx, memberGroups, []string{userUid}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 // InsureOpPermissionsInUser 确保用户拥有指定的多个操作权限
 func (d *UserUsecase) InsureOpPermissionsInUser(ctx context.Context, opPermissionUids []string, userUid string) (err error) {
 	// check
@@ -769,6 +877,7 @@ func (d *UserUsecase) DelUser(ctx context.Context, currentUserUid, UserUid strin
 	if err := tx.Commit(d.log); err != nil {
 		return fmt.Errorf("commit tx failed: %v", err)
 	}
+	d.OpPermissionVerifyUsecase.InvalidateUserPermissions(ctx, UserUid)
 
 	return nil
 }
@@ -875,7 +984,11 @@ func (d *UserUsecase) UpdateUser(ctx context.Context, currentUserUid string, arg
 		user.Stat = UserStatOK
 	}
 
-	if args.Password != nil {
+	passwordChanged := args.Password != nil && *args.Password != user.Password
+	if passwordChanged {
+		if err := d.checkNewPassword(ctx, user, *args.Password); err != nil {
+			return err
+		}
 		user.Password = *args.Password
 	}
 	if args.Email != nil {
@@ -949,6 +1062,12 @@ func (d *UserUsecase) UpdateUser(ctx context.Context, currentUserUid string, arg
 		return fmt.Errorf("insure op permissions in user failed: %v", err)
 	}
 
+	if passwordChanged {
+		if err := d.recordPasswordChange(tx, user); err != nil {
+			return err
+		}
+	}
+
 	if err := d.repo.UpdateUser(tx, user); nil != err {
 		return fmt.Errorf("update user error: %v", err)
 	}
@@ -956,6 +1075,15 @@ func (d *UserUsecase) UpdateUser(ctx context.Context, currentUserUid string, arg
 	if err := tx.Commit(d.log); err != nil {
 		return fmt.Errorf("commit tx failed: %v", err)
 	}
+	// 用户状态、全局权限及所属用户组（绑定到项目的成员组）都会影响权限
+	d.OpPermissionVerifyUsecase.InvalidateUserPermissions(ctx, user.GetUID())
+
+	// 修改密码或禁用用户后，用户已有的登录会话全部失效
+	if passwordChanged || user.Stat == UserStatDisable {
+		if err := d.RevokeUserLoginSessions(ctx, user.GetUID()); err != nil {
+			return err
+		}
+	}
 	return nil
 }
 
@@ -973,7 +1101,13 @@ func (d *UserUsecase) UpdateCurrentUser(ctx context.Context, currentUserUid stri
 		if user.Password != *oldPassword {
 			return fmt.Errorf("old password is wrong")
 		}
+		if err := d.checkNewPassword(ctx, user, *password); err != nil {
+			return err
+		}
 		user.Password = *password
+		if err := d.recordPasswordChange(ctx, user); err != nil {
+			return err
+		}
 	}
 
 	if email != nil {
@@ -999,6 +1133,11 @@ func (d *UserUsecase) UpdateCurrentUser(ctx context.Context, currentUserUid stri
 		return fmt.Errorf("update current user error: %v", err)
 	}
 
+	if oldPassword != nil && password != nil {
+		if err := d.RevokeUserLoginSessions(ctx, user.GetUID()); err != nil {
+			return err
+		}
+	}
 	return nil
 }
 
@@ -1086,20 +1225,6 @@ func (d *UserUsecase) GetBizUserIncludeDeletedWithNameByUids(ctx context.Context
 	return ret
 }
 
-func (d *UserUsecase) SaveAccessToken(ctx context.Context, userId string, token string, expiredTime time.Time) error {
-	userIdInt, err := strconv.Atoi(userId)
-	if err != nil {
-		return err
-	}
-	uid, err := pkgRand.GenStrUid()
-	if err != nil {
-		return err
-	}
-
-	tokenInfo := &AccessTokenInfo{UID: uid, UserID: uint(userIdInt), Token: token, ExpiredTime: expiredTime}
-	return d.repo.SaveAccessToken(ctx, tokenInfo)
-}
-
 func (d *UserUsecase) GetAccessTokenByUser(ctx context.Context, UserUid string) (*AccessTokenInfo, error) {
 	accessTokenInfo, err := d.repo.GetAccessTokenByUser(ctx, UserUid)
 	if err != nil {
@@ -5,6 +5,7 @@ package biz
 import (
 	"context"
 	"errors"
+	"time"
 )
 
 var errNotSupportUserActivity = errors.New("UserActivity related functions are enterprise version functions")
@@ -36,3 +37,7 @@ func (u *UserActivityUsecase) ListHourlyDistribution(_ context.Context, _ string
 func (u *UserActivityUsecase) ListUserDailyStats(_ context.Context, _ *ListUserActivityUsersOption) ([]*UserDailyActiveStat, uint64, error) {
 	return nil, 0, errNotSupportUserActivity
 }
+
+func (u *UserActivityUsecase) ListUsersLastActiveAt(_ context.Context, _ []string) (map[string]time.Time, error) {
+	return nil, errNotSupportUserActivity
+}
@@ -77,6 +77,7 @@ type UserGroupRepo interface {
 	AddUserToUserGroup(ctx context.Context, userGroupUid string, userUid string) error
 	ReplaceUsersInUserGroup(ctx context.Context, userGroupUid string, userUids []string) error
 	GetUsersInUserGroup(ctx context.Context, userGroupUid string) ([]*User, error)
+	ListMemberGroupsBoundToUserGroup(ctx context.Context, userGroupUid string) ([]*MemberGroup, error)
 }
 
 type UserGroupUsecase struct {
@@ -85,17 +86,19 @@ type UserGroupUsecase struct {
 	userRepo                  UserRepo
 	pluginUsecase             *PluginUsecase
 	opPermissionVerifyUsecase *OpPermissionVerifyUsecase
+	separationOfDutiesUsecase *SeparationOfDutiesUsecase
 	log                       *utilLog.Helper
 }
 
 func NewUserGroupUsecase(log utilLog.Logger, tx TransactionGenerator, repo UserGroupRepo, userRepo UserRepo, pluginUsecase *PluginUsecase,
-	opPermissionVerifyUsecase *OpPermissionVerifyUsecase) *UserGroupUsecase {
+	opPermissionVerifyUsecase *OpPermissionVerifyUsecase, separationOfDutiesUsecase *SeparationOfDutiesUsecase) *UserGroupUsecase {
 	return &UserGroupUsecase{
 		tx:                        tx,
 		repo:                      repo,
 		userRepo:                  userRepo,
 		pluginUsecase:             pluginUsecase,
 		opPermissionVerifyUsecase: opPermissionVerifyUsecase,
+		separationOfDutiesUsecase: separationOfDutiesUsecase,
 		log:                       utilLog.NewHelper(log, utilLog.WithMessageKey("biz.usergroup")),
 	}
 }
@@ -177,6 +180,13 @@ func (d *UserGroupUsecase) DelUserGroup(ctx context.Context, currentUserUid, Use
 		return fmt.Errorf("get user group failed: %v", err)
 	}
 
+	// 已绑定到项目的用户组需要先在项目中解除绑定
+	if memberGroups, err := d.repo.ListMemberGroupsBoundToUserGroup(ctx, UserGroupUid); err != nil {
+		return fmt.Errorf("list member groups bound to user group failed: %v", err)
+	} else if len(memberGroups) > 0 {
+		return fmt.Errorf("user group is still bound to %d project(s), unbind it first", len(memberGroups))
+	}
+
 	// 调用其他服务对用户组进行预检查
 	if err := d.pluginUsecase.DelUserGroupPreCheck(ctx, ds.GetUID()); err != nil {
 		return fmt.Errorf("precheck del user group failed: %v", err)
@@ -231,6 +241,19 @@ func (d *UserGroupUsecase) UpdateUserGroup(ctx context.Context, currentUserUid,
 		return fmt.Errorf("user not exist")
 	}
 
+	// 绑定到项目的成员组随用户组变化，新加入的用户同样需要满足各项目的互斥规则
+	memberGroups, err := d.repo.ListMemberGroupsBoundToUserGroup(ctx, group.UID)
+	if err != nil {
+		return fmt.Errorf("list member groups bound to user group failed: %v", err)
+	}
+	effectiveUserUids := userUids
+	if isDisabled {
+		effectiveUserUids = []string{}
+	}
+	if err := d.separationOfDutiesUsecase.CheckBoundMemberGroupUsers(ctx, memberGroups, effectiveUserUids); err != nil {
+		return err
+	}
+
 	tx := d.tx.BeginTX(ctx)
 	defer func() {
 		if err != nil {
@@ -250,5 +273,13 @@ func (d *UserGroupUsecase) UpdateUserGroup(ctx context.Context, currentUserUid,
 		return fmt.Errorf("commit tx failed: %v", err)
 	}
 
+	// 通知其他服务更新绑定的成员组的用户
+	for _, mg := range memberGroups {
+		d.opPermissionVerifyUsecase.InvalidateProjectPermissions(ctx, mg.ProjectUID)
+		if err := d.pluginUsecase.UpdateMemberGroupAfterHandle(ctx, mg.UID, effectiveUserUids); err != nil {
+			return fmt.Errorf("handle member group %s bound to user group failed: %v", mg.Name, err)
+		}
+	}
+
 	return nil
 }
@@ -72,6 +72,9 @@ func (m *mockUserGroupRepoForTest) ReplaceUsersInUserGroup(context.Context, stri
 func (m *mockUserGroupRepoForTest) GetUsersInUserGroup(context.Context, string) ([]*User, error) {
 	return nil, nil
 }
+func (m *mockUserGroupRepoForTest) ListMemberGroupsBoundToUserGroup(context.Context, string) ([]*MemberGroup, error) {
+	return nil, nil
+}
 
 // mockOpPermissionRepoForTest implements OpPermissionRepo for testing
 type mockOpPermissionRepoForTest struct {
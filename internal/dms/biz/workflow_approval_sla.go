@@ -0,0 +1,358 @@
+package biz
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	pkgConst "github.com/actiontech/dms/internal/dms/pkg/constant"
+	pkgErr "github.com/actiontech/dms/internal/dms/pkg/errors"
+	"github.com/actiontech/dms/internal/pkg/locale"
+	utilLog "github.com/actiontech/dms/pkg/dms-common/pkg/log"
+	pkgRand "github.com/actiontech/dms/pkg/rand"
+
+	"github.com/nicksnyder/go-i18n/v2/i18n"
+)
+
+// WorkflowApprovalSLA 项目的工单审批时效设置，按当前审批步骤开始等待的时间计算，0 表示不启用
+type WorkflowApprovalSLA struct {
+	UID        string
+	ProjectUID string
+	// RemindAfterHours 等待超过该时长后提醒当前步骤的审批人
+	RemindAfterHours int
+	// EscalateAfterHours 等待超过该时长后通知项目管理员
+	EscalateAfterHours int
+	// AutoRejectAfterHours 等待超过该时长后自动驳回工单
+	AutoRejectAfterHours int
+	UpdatedAt            time.Time
+}
+
+func (s *WorkflowApprovalSLA) isEmpty() bool {
+	return s.RemindAfterHours == 0 && s.EscalateAfterHours == 0 && s.AutoRejectAfterHours == 0
+}
+
+func (s *WorkflowApprovalSLA) validate() error {
+	if s.RemindAfterHours < 0 || s.EscalateAfterHours < 0 || s.AutoRejectAfterHours < 0 {
+		return fmt.Errorf("approval sla hours must not be negative")
+	}
+	// 已启用的阶段须依次递增
+	last := 0
+	for _, hours := range []int{s.RemindAfterHours, s.EscalateAfterHours, s.AutoRejectAfterHours} {
+		if hours == 0 {
+			continue
+		}
+		if hours <= last {
+			return fmt.Errorf("approval sla hours must increase from reminder to escalation to auto reject")
+		}
+		last = hours
+	}
+	return nil
+}
+
+type WorkflowApprovalSLAStatus string
+
+const (
+	WorkflowApprovalSLAStatusOnTrack      WorkflowApprovalSLAStatus = "on_track"
+	WorkflowApprovalSLAStatusReminded     WorkflowApprovalSLAStatus = "reminded"
+	WorkflowApprovalSLAStatusEscalated    WorkflowApprovalSLAStatus = "escalated"
+	WorkflowApprovalSLAStatusOverdue      WorkflowApprovalSLAStatus = "overdue"
+	WorkflowApprovalSLAStatusAutoRejected WorkflowApprovalSLAStatus = "auto_rejected"
+)
+
+// WorkflowApprovalSLARecord 记录工单每个审批步骤已发送的提醒，避免重复通知
+type WorkflowApprovalSLARecord struct {
+	UID         string
+	WorkflowUID string
+	StepID      uint64
+	RemindedAt  *time.Time
+	EscalatedAt *time.Time
+}
+
+type WorkflowApprovalSLARepo interface {
+	SaveWorkflowApprovalSLA(ctx context.Context, sla *WorkflowApprovalSLA) error
+	DeleteWorkflowApprovalSLA(ctx context.Context, projectUid string) error
+	GetWorkflowApprovalSLA(ctx context.Context, projectUid string) (*WorkflowApprovalSLA, error)
+	ListWorkflowApprovalSLAs(ctx context.Context) ([]*WorkflowApprovalSLA, error)
+	GetWorkflowApprovalSLARecord(ctx context.Context, workflowUid string, stepId uint64) (*WorkflowApprovalSLARecord, error)
+	SaveWorkflowApprovalSLARecord(ctx context.Context, record *WorkflowApprovalSLARecord) error
+}
+
+type WorkflowApprovalSLAUsecase struct {
+	repo                      WorkflowApprovalSLARepo
+	workflowRepo              WorkflowRepo
+	userUsecase               *UserUsecase
+	projectUsecase            *ProjectUsecase
+	opPermissionVerifyUsecase *OpPermissionVerifyUsecase
+	clusterUsecase            *ClusterUsecase
+	log                       *utilLog.Helper
+}
+
+func NewWorkflowApprovalSLAUsecase(log utilLog.Logger, repo WorkflowApprovalSLARepo, workflowRepo WorkflowRepo, userUsecase *UserUsecase, projectUsecase *ProjectUsecase, opPermissionVerifyUsecase *OpPermissionVerifyUsecase, clusterUsecase *ClusterUsecase) *WorkflowApprovalSLAUsecase {
+	return &WorkflowApprovalSLAUsecase{
+		repo:                      repo,
+		workflowRepo:              workflowRepo,
+		userUsecase:               userUsecase,
+		projectUsecase:            projectUsecase,
+		opPermissionVerifyUsecase: opPermissionVerifyUsecase,
+		clusterUsecase:            clusterUsecase,
+		log:                       utilLog.NewHelper(log, utilLog.WithMessageKey("biz.workflowApprovalSLA")),
+	}
+}
+
+// SetWorkflowApprovalSLA 设置项目的审批时效，全部为 0 时删除设置
+func (u *WorkflowApprovalSLAUsecase) SetWorkflowApprovalSLA(ctx context.Context, currentUserUid string, sla *WorkflowApprovalSLA) error {
+	canOpProject, err := u.opPermissionVerifyUsecase.CanOpProject(ctx, currentUserUid, sla.ProjectUID, false)
+	if err != nil {
+		return fmt.Errorf("check user can op project failed: %v", err)
+	}
+	if !canOpProject {
+		return fmt.Errorf("user is not project admin or global management permission")
+	}
+	if err := sla.validate(); err != nil {
+		return err
+	}
+	if sla.isEmpty() {
+		return u.repo.DeleteWorkflowApprovalSLA(ctx, sla.ProjectUID)
+	}
+	uid, err := pkgRand.GenStrUid()
+	if err != nil {
+		return err
+	}
+	sla.UID = uid
+	if err := u.repo.SaveWorkflowApprovalSLA(ctx, sla); err != nil {
+		return fmt.Errorf("save workflow approval sla failed: %w", err)
+	}
+	return nil
+}
+
+// GetWorkflowApprovalSLA 项目未设置时返回全部为 0 的设置
+func (u *WorkflowApprovalSLAUsecase) GetWorkflowApprovalSLA(ctx context.Context, projectUid string) (*WorkflowApprovalSLA, error) {
+	sla, err := u.repo.GetWorkflowApprovalSLA(ctx, projectUid)
+	if errors.Is(err, pkgErr.ErrStorageNoData) {
+		return &WorkflowApprovalSLA{ProjectUID: projectUid}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("get workflow approval sla failed: %w", err)
+	}
+	return sla, nil
+}
+
+// GetWorkflowApprovalSLAStatuses 返回工单列表中各工单的审批时效状态，未设置时效或不在审批中的工单不返回
+func (u *WorkflowApprovalSLAUsecase) GetWorkflowApprovalSLAStatuses(ctx context.Context, workflows []*Workflow) map[string]WorkflowApprovalSLAStatus {
+	ret := make(map[string]WorkflowApprovalSLAStatus)
+	slas := make(map[string]*WorkflowApprovalSLA)
+	now := time.Now()
+	for _, w := range workflows {
+		sla, ok := slas[w.ProjectUID]
+		if !ok {
+			var err error
+			sla, err = u.GetWorkflowApprovalSLA(ctx, w.ProjectUID)
+			if err != nil {
+				u.log.Errorf("get workflow approval sla of project %s failed: %v", w.ProjectUID, err)
+			}
+			slas[w.ProjectUID] = sla
+		}
+		if sla == nil {
+			continue
+		}
+		if status := workflowApprovalSLAStatus(w, sla, now); status != "" {
+			ret[w.UID] = status
+		}
+	}
+	return ret
+}
+
+func workflowApprovalSLAStatus(w *Workflow, sla *WorkflowApprovalSLA, now time.Time) WorkflowApprovalSLAStatus {
+	if w.WorkflowRecord == nil {
+		return ""
+	}
+	if w.WorkflowRecord.Status == DataExportWorkflowStatusRejected {
+		if step := currentApprovalStep(w); step != nil && step.OperationUserUid == pkgConst.UIDOfUserSys {
+			return WorkflowApprovalSLAStatusAutoRejected
+		}
+		return ""
+	}
+	if w.WorkflowRecord.Status != DataExportWorkflowStatusWaitForApprove || sla.isEmpty() {
+		return ""
+	}
+	waited := now.Sub(approvalWaitingSince(w))
+	switch {
+	case sla.AutoRejectAfterHours > 0 && waited >= time.Duration(sla.AutoRejectAfterHours)*time.Hour:
+		return WorkflowApprovalSLAStatusOverdue
+	case sla.EscalateAfterHours > 0 && waited >= time.Duration(sla.EscalateAfterHours)*time.Hour:
+		return WorkflowApprovalSLAStatusEscalated
+	case sla.RemindAfterHours > 0 && waited >= time.Duration(sla.RemindAfterHours)*time.Hour:
+		return WorkflowApprovalSLAStatusReminded
+	}
+	return WorkflowApprovalSLAStatusOnTrack
+}
+
+func currentApprovalStep(w *Workflow) *WorkflowStep {
+	stepId := w.WorkflowRecord.CurrentWorkflowStepId
+	if stepId == 0 || int(stepId) > len(w.WorkflowRecord.WorkflowSteps) {
+		return nil
+	}
+	return w.WorkflowRecord.WorkflowSteps[stepId-1]
+}
+
+// approvalWaitingSince 当前步骤从上一步审批通过时开始等待，第一步从工单创建时开始
+func approvalWaitingSince(w *Workflow) time.Time {
+	stepId := w.WorkflowRecord.CurrentWorkflowStepId
+	if stepId > 1 && int(stepId-1) <= len(w.WorkflowRecord.WorkflowSteps) {
+		if prev := w.WorkflowRecord.WorkflowSteps[stepId-2]; prev.OperateAt != nil {
+			return *prev.OperateAt
+		}
+	}
+	return w.CreateTime
+}
+
+// CheckWorkflowApprovalSLA 定时检查待审批的数据导出工单，按项目的审批时效提醒审批人、通知项目管理员或自动驳回，
+// 集群模式下仅由主节点执行，避免重复提醒。查看原文工单由企业版实现，暂不在检查范围内
+func (u *WorkflowApprovalSLAUsecase) CheckWorkflowApprovalSLA() {
+	if u.clusterUsecase.IsClusterMode() && !u.clusterUsecase.IsLeader() {
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
+	defer cancel()
+
+	slaList, err := u.repo.ListWorkflowApprovalSLAs(ctx)
+	if err != nil {
+		u.log.Errorf("list workflow approval slas failed: %v", err)
+		return
+	}
+	if len(slaList) == 0 {
+		return
+	}
+	slas := make(map[string]*WorkflowApprovalSLA, len(slaList))
+	for _, sla := range slaList {
+		slas[sla.ProjectUID] = sla
+	}
+
+	uids, err := u.workflowRepo.GetDataExportWorkflowsByStatus(ctx, string(DataExportWorkflowStatusWaitForApprove))
+	if err != nil {
+		u.log.Errorf("get wait for approve workflows failed: %v", err)
+		return
+	}
+	if len(uids) == 0 {
+		return
+	}
+	workflows, err := u.workflowRepo.GetDataExportWorkflowsByIds(ctx, uids)
+	if err != nil {
+		u.log.Errorf("get wait for approve workflows failed: %v", err)
+		return
+	}
+	now := time.Now()
+	for _, w := range workflows {
+		sla, ok := slas[w.ProjectUID]
+		if !ok {
+			continue
+		}
+		if err := u.checkWorkflowApprovalSLA(ctx, w, sla, now); err != nil {
+			u.log.Errorf("check approval sla of workflow %s failed: %v", w.UID, err)
+		}
+	}
+}
+
+func (u *WorkflowApprovalSLAUsecase) checkWorkflowApprovalSLA(ctx context.Context, w *Workflow, sla *WorkflowApprovalSLA, now time.Time) error {
+	status := workflowApprovalSLAStatus(w, sla, now)
+	step := currentApprovalStep(w)
+	if step == nil || status == "" || status == WorkflowApprovalSLAStatusOnTrack {
+		return nil
+	}
+	waitedHours := int(now.Sub(approvalWaitingSince(w)).Hours())
+
+	if status == WorkflowApprovalSLAStatusOverdue {
+		step.State = string(DataExportWorkflowStatusRejected)
+		// 驳回原因由申请人查看，按申请人的语言记录
+		lang := ""
+		if creator, err := u.userUsecase.GetUser(ctx, w.CreateUserUID); err == nil {
+			lang = creator.Language
+		}
+		reason := fmt.Sprintf(locale.Bundle.LocalizeMsgByLang(locale.Bundle.MatchLangTag(lang), locale.DataWorkflowApprovalTimeoutRejectReason), sla.AutoRejectAfterHours)
+		if err := u.workflowRepo.AuditWorkflow(ctx, w.WorkflowRecordUid, DataExportWorkflowStatusRejected, step, pkgConst.UIDOfUserSys, reason); err != nil {
+			return fmt.Errorf("auto reject workflow failed: %v", err)
+		}
+		u.notify(ctx, w, []string{w.CreateUserUID}, locale.NotifyDataExportApprovalAutoRejectedSubject, locale.NotifyDataExportApprovalAutoRejectedBody, waitedHours)
+		return nil
+	}
+
+	record, err := u.repo.GetWorkflowApprovalSLARecord(ctx, w.UID, step.StepId)
+	if errors.Is(err, pkgErr.ErrStorageNoData) {
+		uid, err := pkgRand.GenStrUid()
+		if err != nil {
+			return err
+		}
+		record = &WorkflowApprovalSLARecord{UID: uid, WorkflowUID: w.UID, StepID: step.StepId}
+	} else if err != nil {
+		return fmt.Errorf("get workflow approval sla record failed: %v", err)
+	}
+
+	switch {
+	case status == WorkflowApprovalSLAStatusEscalated && record.EscalatedAt == nil:
+		admins, err := u.listProjectAdmins(ctx, w.ProjectUID)
+		if err != nil {
+			return err
+		}
+		// 升级时已超过提醒时间，不再单独提醒审批人
+		record.EscalatedAt, record.RemindedAt = &now, &now
+		if err := u.repo.SaveWorkflowApprovalSLARecord(ctx, record); err != nil {
+			return fmt.Errorf("save workflow approval sla record failed: %v", err)
+		}
+		u.notify(ctx, w, admins, locale.NotifyDataExportApprovalEscalatedSubject, locale.NotifyDataExportApprovalEscalatedBody, waitedHours, u.userNames(ctx, step.Assignees))
+	case status == WorkflowApprovalSLAStatusReminded && record.RemindedAt == nil:
+		record.RemindedAt = &now
+		if err := u.repo.SaveWorkflowApprovalSLARecord(ctx, record); err != nil {
+			return fmt.Errorf("save workflow approval sla record failed: %v", err)
+		}
+		u.notify(ctx, w, step.Assignees, locale.NotifyDataExportApprovalReminderSubject, locale.NotifyDataExportApprovalReminderBody, waitedHours)
+	}
+	return nil
+}
+
+func (u *WorkflowApprovalSLAUsecase) listProjectAdmins(ctx context.Context, projectUid string) ([]string, error) {
+	items, err := u.opPermissionVerifyUsecase.ListUsersInProject(ctx, projectUid)
+	if err != nil {
+		return nil, fmt.Errorf("list users in project failed: %v", err)
+	}
+	admins := make([]string, 0)
+	for _, item := range items {
+		isAdmin, err := u.opPermissionVerifyUsecase.IsUserProjectAdmin(ctx, item.UserUid, projectUid, false)
+		if err != nil || !isAdmin {
+			continue
+		}
+		admins = append(admins, item.UserUid)
+	}
+	return admins, nil
+}
+
+func (u *WorkflowApprovalSLAUsecase) userNames(ctx context.Context, userUids []string) string {
+	names := make([]string, 0, len(userUids))
+	for _, uid := range userUids {
+		user, err := u.userUsecase.GetUser(ctx, uid)
+		if err != nil {
+			names = append(names, uid)
+			continue
+		}
+		names = append(names, user.Name)
+	}
+	return strings.Join(names, ", ")
+}
+
+func (u *WorkflowApprovalSLAUsecase) notify(ctx context.Context, w *Workflow, userUids []string, subject, body *i18n.Message, args ...any) {
+	users := make([]*User, 0, len(userUids))
+	for _, uid := range userUids {
+		user, err := u.userUsecase.GetUser(ctx, uid)
+		if err != nil {
+			u.log.Errorf("get user %s failed: %v", uid, err)
+			continue
+		}
+		users = append(users, user)
+	}
+	projectName := w.ProjectUID
+	if project, err := u.projectUsecase.GetProject(ctx, w.ProjectUID); err == nil {
+		projectName = project.Name
+	}
+	notifyUsersI18n(ctx, u.log, users, subject, body, append([]any{w.Name, projectName}, args...)...)
+}
@@ -0,0 +1,142 @@
+package biz
+
+import (
+	"context"
+	"io"
+	"testing"
+	"time"
+
+	pkgConst "github.com/actiontech/dms/internal/dms/pkg/constant"
+	pkgErr "github.com/actiontech/dms/internal/dms/pkg/errors"
+	"github.com/actiontech/dms/internal/pkg/locale"
+	"github.com/actiontech/dms/pkg/dms-common/i18nPkg"
+	utilLog "github.com/actiontech/dms/pkg/dms-common/pkg/log"
+	"github.com/stretchr/testify/assert"
+)
+
+type mockWorkflowApprovalSLARepo struct {
+	WorkflowApprovalSLARepo
+	records map[string]*WorkflowApprovalSLARecord
+}
+
+func (m *mockWorkflowApprovalSLARepo) GetWorkflowApprovalSLARecord(_ context.Context, workflowUid string, _ uint64) (*WorkflowApprovalSLARecord, error) {
+	record, ok := m.records[workflowUid]
+	if !ok {
+		return nil, pkgErr.ErrStorageNoData
+	}
+	copied := *record
+	return &copied, nil
+}
+
+func (m *mockWorkflowApprovalSLARepo) SaveWorkflowApprovalSLARecord(_ context.Context, record *WorkflowApprovalSLARecord) error {
+	m.records[record.WorkflowUID] = record
+	return nil
+}
+
+type mockApprovalSLAWorkflowRepo struct {
+	WorkflowRepo
+	rejected []string
+	reasons  []string
+}
+
+func (m *mockApprovalSLAWorkflowRepo) AuditWorkflow(_ context.Context, workflowRecordUid string, status DataExportWorkflowStatus, step *WorkflowStep, operateId, reason string) error {
+	if status == DataExportWorkflowStatusRejected && operateId == pkgConst.UIDOfUserSys {
+		m.rejected = append(m.rejected, workflowRecordUid)
+		m.reasons = append(m.reasons, reason)
+	}
+	return nil
+}
+
+type mockRecordNotifier struct {
+	subjects []string
+}
+
+func (m *mockRecordNotifier) Notify(_ context.Context, subject, _ string, _ []*User) error {
+	m.subjects = append(m.subjects, subject)
+	return nil
+}
+
+func newApprovalSLATestWorkflow(waited time.Duration, now time.Time) *Workflow {
+	return &Workflow{
+		UID: "w1", Name: "export", ProjectUID: "p1", CreateUserUID: "user_1", CreateTime: now.Add(-waited), WorkflowRecordUid: "r1",
+		WorkflowRecord: &WorkflowRecord{
+			Status:                DataExportWorkflowStatusWaitForApprove,
+			CurrentWorkflowStepId: 1,
+			WorkflowSteps:         []*WorkflowStep{{StepId: 1, State: "init", Assignees: []string{"user_2"}}},
+		},
+	}
+}
+
+func TestWorkflowApprovalSLAValidate(t *testing.T) {
+	assert.NoError(t, (&WorkflowApprovalSLA{RemindAfterHours: 4, EscalateAfterHours: 24, AutoRejectAfterHours: 72}).validate())
+	assert.NoError(t, (&WorkflowApprovalSLA{RemindAfterHours: 4, AutoRejectAfterHours: 72}).validate())
+	assert.Error(t, (&WorkflowApprovalSLA{RemindAfterHours: 24, EscalateAfterHours: 24}).validate())
+	assert.Error(t, (&WorkflowApprovalSLA{EscalateAfterHours: 24, AutoRejectAfterHours: 4}).validate())
+	assert.Error(t, (&WorkflowApprovalSLA{RemindAfterHours: -1}).validate())
+}
+
+func TestWorkflowApprovalSLAStatus(t *testing.T) {
+	now := time.Now()
+	sla := &WorkflowApprovalSLA{RemindAfterHours: 4, EscalateAfterHours: 24, AutoRejectAfterHours: 72}
+
+	assert.Equal(t, WorkflowApprovalSLAStatusOnTrack, workflowApprovalSLAStatus(newApprovalSLATestWorkflow(time.Hour, now), sla, now))
+	assert.Equal(t, WorkflowApprovalSLAStatusReminded, workflowApprovalSLAStatus(newApprovalSLATestWorkflow(5*time.Hour, now), sla, now))
+	assert.Equal(t, WorkflowApprovalSLAStatusEscalated, workflowApprovalSLAStatus(newApprovalSLATestWorkflow(25*time.Hour, now), sla, now))
+	assert.Equal(t, WorkflowApprovalSLAStatusOverdue, workflowApprovalSLAStatus(newApprovalSLATestWorkflow(73*time.Hour, now), sla, now))
+	assert.Empty(t, workflowApprovalSLAStatus(newApprovalSLATestWorkflow(73*time.Hour, now), &WorkflowApprovalSLA{}, now))
+
+	// 第二步从第一步审批通过时开始计时
+	w := newApprovalSLATestWorkflow(100*time.Hour, now)
+	approvedAt := now.Add(-time.Hour)
+	w.WorkflowRecord.CurrentWorkflowStepId = 2
+	w.WorkflowRecord.WorkflowSteps = []*WorkflowStep{{StepId: 1, State: "approved", OperateAt: &approvedAt}, {StepId: 2, State: "init"}}
+	assert.Equal(t, WorkflowApprovalSLAStatusOnTrack, workflowApprovalSLAStatus(w, sla, now))
+
+	w = newApprovalSLATestWorkflow(100*time.Hour, now)
+	w.WorkflowRecord.Status = DataExportWorkflowStatusRejected
+	w.WorkflowRecord.WorkflowSteps[0].OperationUserUid = pkgConst.UIDOfUserSys
+	assert.Equal(t, WorkflowApprovalSLAStatusAutoRejected, workflowApprovalSLAStatus(w, sla, now))
+}
+
+func TestCheckWorkflowApprovalSLA(t *testing.T) {
+	locale.MustInit(&i18nPkg.StdLogger{})
+	notifier := &mockRecordNotifier{}
+	origin := Notifiers
+	Notifiers = []Notifier{notifier}
+	defer func() { Notifiers = origin }()
+
+	repo := &mockWorkflowApprovalSLARepo{records: map[string]*WorkflowApprovalSLARecord{}}
+	workflowRepo := &mockApprovalSLAWorkflowRepo{}
+	userRepo := &mockUserRepo{users: map[string]*User{"user_1": {UID: "user_1", Language: "en"}, "user_2": {UID: "user_2", Name: "approver"}}}
+	uc := &WorkflowApprovalSLAUsecase{
+		repo:                      repo,
+		workflowRepo:              workflowRepo,
+		userUsecase:               &UserUsecase{repo: userRepo},
+		projectUsecase:            &ProjectUsecase{repo: &mockDeliveryProjectRepo{}},
+		opPermissionVerifyUsecase: newTestOpPermissionVerifyUsecase(userRepo, &mockOpPermissionVerifyRepo{}),
+		log:                       utilLog.NewHelper(utilLog.NewMyLogger(io.Discard), utilLog.WithMessageKey("test")),
+	}
+	ctx := context.Background()
+	now := time.Now()
+	sla := &WorkflowApprovalSLA{RemindAfterHours: 4, EscalateAfterHours: 24, AutoRejectAfterHours: 72}
+
+	// 提醒只发送一次
+	w := newApprovalSLATestWorkflow(5*time.Hour, now)
+	assert.NoError(t, uc.checkWorkflowApprovalSLA(ctx, w, sla, now))
+	assert.NoError(t, uc.checkWorkflowApprovalSLA(ctx, w, sla, now.Add(time.Minute)))
+	assert.Len(t, notifier.subjects, 1)
+	assert.NotNil(t, repo.records["w1"].RemindedAt)
+	assert.Nil(t, repo.records["w1"].EscalatedAt)
+
+	w = newApprovalSLATestWorkflow(25*time.Hour, now)
+	assert.NoError(t, uc.checkWorkflowApprovalSLA(ctx, w, sla, now))
+	assert.NotNil(t, repo.records["w1"].EscalatedAt)
+
+	w = newApprovalSLATestWorkflow(73*time.Hour, now)
+	assert.NoError(t, uc.checkWorkflowApprovalSLA(ctx, w, sla, now))
+	assert.Equal(t, []string{"r1"}, workflowRepo.rejected)
+	// 驳回原因按申请人的语言记录
+	assert.Equal(t, []string{"Approval timed out after 72 hours, rejected automatically"}, workflowRepo.reasons)
+	assert.Equal(t, string(DataExportWorkflowStatusRejected), w.WorkflowRecord.WorkflowSteps[0].State)
+	assert.Len(t, notifier.subjects, 2)
+}
@@ -26,15 +26,35 @@ func NewMysqlManager(host, port, user, password string) ConnectorImpl {
 	}
 }
 
-func (mm *mysqlManager) IsConnectable(ctx context.Context) (bool, error) {
+func newMysqlConfig(host, port, user, password string) *mysql.Config {
 	config := mysql.NewConfig()
-	config.User = mm.user
-	config.Passwd = mm.password
-	config.Addr = net.JoinHostPort(mm.host, mm.port)
+	config.User = user
+	config.Passwd = password
+	config.Addr = net.JoinHostPort(host, port)
 	config.ParseTime = true
 	config.Loc = time.Local
 	config.Timeout = 5 * time.Second
 	config.Params = map[string]string{"charset": "utf8"}
+	return config
+}
+
+// OpenMysql 打开到指定库的连接池，不允许一次执行多条语句，调用方负责关闭
+func OpenMysql(host, port, user, password, schema string) (*sql.DB, error) {
+	config := newMysqlConfig(host, port, user, password)
+	config.DBName = schema
+	config.Params = map[string]string{"charset": "utf8mb4"}
+	driver, err := mysql.NewConnector(config)
+	if err != nil {
+		return nil, fmt.Errorf("OpenMysql connector err: %v", err)
+	}
+	pool := sql.OpenDB(driver)
+	pool.SetMaxOpenConns(1)
+	pool.SetMaxIdleConns(1)
+	return pool, nil
+}
+
+func (mm *mysqlManager) IsConnectable(ctx context.Context) (bool, error) {
+	config := newMysqlConfig(mm.host, mm.port, mm.user, mm.password)
 	driver, err := mysql.NewConnector(config)
 	if err != nil {
 		return false, fmt.Errorf("IsConnectable connector err: %v", err)
@@ -0,0 +1,137 @@
+package exportdelivery
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"path/filepath"
+	"strings"
+	"unicode"
+)
+
+type Type string
+
+const (
+	// TypeS3 S3 兼容的对象存储，如 MinIO
+	TypeS3 Type = "s3"
+	// TypeSFTP SFTP 服务器，使用 OpenSSH 的 sftp 客户端以密钥认证上传
+	TypeSFTP Type = "sftp"
+	// TypeLocal DMS 所在服务器的本地目录或挂载的 NFS 目录
+	TypeLocal Type = "local"
+)
+
+// Config 投递目标的配置，不同类型使用不同的字段
+type Config struct {
+	Type Type
+	// Path S3 的对象前缀，SFTP 的远程目录，本地/NFS 的绝对路径
+	Path string
+
+	// S3
+	Endpoint        string
+	Region          string
+	Bucket          string
+	AccessKeyID     string
+	SecretAccessKey string
+	// PathStyle 使用 endpoint/bucket/key 形式的地址，MinIO 需开启
+	PathStyle bool
+
+	// SFTP
+	Host       string
+	Port       int
+	Username   string
+	PrivateKey string
+	// HostPublicKey known_hosts 格式的服务器公钥，用于校验服务器身份
+	HostPublicKey string
+}
+
+func (c *Config) Validate() error {
+	switch c.Type {
+	case TypeS3:
+		if c.Endpoint == "" || c.Bucket == "" || c.AccessKeyID == "" || c.SecretAccessKey == "" {
+			return fmt.Errorf("s3 delivery target requires endpoint, bucket, access key id and secret access key")
+		}
+		if !strings.HasPrefix(c.Endpoint, "http://") && !strings.HasPrefix(c.Endpoint, "https://") {
+			return fmt.Errorf("s3 endpoint should start with http:// or https://")
+		}
+	case TypeSFTP:
+		if c.Host == "" || c.Username == "" || c.PrivateKey == "" || c.HostPublicKey == "" {
+			return fmt.Errorf("sftp delivery target requires host, username, private key and host public key")
+		}
+		// 主机及用户名作为 sftp 命令的参数，以 - 开头会被当作 ssh 选项
+		if !isSSHArg(c.Host) || strings.Contains(c.Host, "@") {
+			return fmt.Errorf("invalid sftp host: %s", c.Host)
+		}
+		if !isSSHArg(c.Username) {
+			return fmt.Errorf("invalid sftp username: %s", c.Username)
+		}
+		// 远程路径写入批处理文件，换行会拆出额外的命令
+		if strings.ContainsAny(c.Path, "\r\n") || strings.ContainsAny(c.HostPublicKey, "\r\n") {
+			return fmt.Errorf("sftp path and host public key should be a single line")
+		}
+	case TypeLocal:
+		if !filepath.IsAbs(c.Path) {
+			return fmt.Errorf("local delivery target requires an absolute path")
+		}
+	default:
+		return fmt.Errorf("unsupported delivery target type: %s", c.Type)
+	}
+	return nil
+}
+
+// State 断点续传状态，每上传一部分通过 Checkpoint 保存，重试时传入以继续上传
+type State struct {
+	// UploadID S3 分片上传的 ID
+	UploadID string `json:"upload_id,omitempty"`
+	Parts    []Part `json:"parts,omitempty"`
+	// Offset 本地/NFS 已写入的字节数
+	Offset int64 `json:"offset,omitempty"`
+	// Partial SFTP 远程已有未完成的文件
+	Partial bool `json:"partial,omitempty"`
+}
+
+type Part struct {
+	Number int    `json:"number"`
+	ETag   string `json:"etag"`
+}
+
+type Checkpoint func(state *State) error
+
+type Destination interface {
+	// Deliver 将本地文件上传为目标中的 name，state 为上次中断时保存的状态，返回文件在目标中的位置
+	Deliver(ctx context.Context, localPath, name string, state *State, checkpoint Checkpoint) (string, error)
+}
+
+func New(cfg *Config) (Destination, error) {
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+	switch cfg.Type {
+	case TypeS3:
+		return newS3Destination(cfg), nil
+	case TypeSFTP:
+		return newSFTPDestination(cfg), nil
+	default:
+		return newLocalDestination(cfg), nil
+	}
+}
+
+// isSSHArg 非空、不以 - 开头且不含空白及控制字符
+func isSSHArg(s string) bool {
+	if s == "" || strings.HasPrefix(s, "-") {
+		return false
+	}
+	return !strings.ContainsFunc(s, func(r rune) bool {
+		return unicode.IsSpace(r) || unicode.IsControl(r)
+	})
+}
+
+// cleanName 去除 name 中的 .. 等，避免写到目标目录之外
+func cleanName(name string) (string, error) {
+	cleaned := strings.TrimPrefix(path.Clean("/"+filepath.ToSlash(name)), "/")
+	if cleaned == "" {
+		return "", fmt.Errorf("invalid delivery file name: %s", name)
+	}
+	return cleaned, nil
+}
+
+const partSize = 16 << 20
@@ -0,0 +1,190 @@
+package exportdelivery
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/xml"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func writeTempFile(t *testing.T, size int) (string, []byte) {
+	data := make([]byte, size)
+	_, err := rand.Read(data)
+	assert.NoError(t, err)
+	p := filepath.Join(t.TempDir(), "export.zip")
+	assert.NoError(t, os.WriteFile(p, data, 0600))
+	return p, data
+}
+
+func TestLocalDeliverResume(t *testing.T) {
+	localPath, data := writeTempFile(t, partSize+1000)
+	dir := t.TempDir()
+	d, err := New(&Config{Type: TypeLocal, Path: dir})
+	assert.NoError(t, err)
+
+	// 第一个分片写入后中断
+	state := &State{}
+	ctx, cancel := context.WithCancel(context.Background())
+	_, err = d.Deliver(ctx, localPath, "../w1/export.zip", state, func(s *State) error {
+		cancel()
+		return nil
+	})
+	assert.Error(t, err)
+	assert.Equal(t, int64(partSize), state.Offset)
+
+	var checkpoints int
+	location, err := d.Deliver(context.Background(), localPath, "../w1/export.zip", state, func(s *State) error {
+		checkpoints++
+		return nil
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, 1, checkpoints)
+	assert.Equal(t, "file://"+filepath.ToSlash(filepath.Join(dir, "w1", "export.zip")), location)
+	delivered, err := os.ReadFile(filepath.Join(dir, "w1", "export.zip"))
+	assert.NoError(t, err)
+	assert.Equal(t, data, delivered)
+}
+
+func TestLocalDeliverResumeTwice(t *testing.T) {
+	localPath, data := writeTempFile(t, 2*partSize+1000)
+	dir := t.TempDir()
+	d, err := New(&Config{Type: TypeLocal, Path: dir})
+	assert.NoError(t, err)
+	interrupt := func() (context.Context, Checkpoint) {
+		ctx, cancel := context.WithCancel(context.Background())
+		return ctx, func(s *State) error {
+			cancel()
+			return nil
+		}
+	}
+
+	state := &State{}
+	ctx, checkpoint := interrupt()
+	_, err = d.Deliver(ctx, localPath, "export.zip", state, checkpoint)
+	assert.Error(t, err)
+	assert.Equal(t, int64(partSize), state.Offset)
+
+	// .part 文件丢失后进度回退到实际写入的大小，而不是在保存的进度上继续累加
+	assert.NoError(t, os.Remove(filepath.Join(dir, "export.zip.part")))
+	ctx, checkpoint = interrupt()
+	_, err = d.Deliver(ctx, localPath, "export.zip", state, checkpoint)
+	assert.Error(t, err)
+	assert.Equal(t, int64(partSize), state.Offset)
+
+	_, err = d.Deliver(context.Background(), localPath, "export.zip", state, func(s *State) error { return nil })
+	assert.NoError(t, err)
+	assert.Equal(t, int64(len(data)), state.Offset)
+	delivered, err := os.ReadFile(filepath.Join(dir, "export.zip"))
+	assert.NoError(t, err)
+	assert.Equal(t, data, delivered)
+}
+
+type fakeS3 struct {
+	mu        sync.Mutex
+	objects   map[string][]byte
+	parts     map[int][]byte
+	failParts map[int]bool
+}
+
+func (f *fakeS3) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	body, _ := io.ReadAll(r.Body)
+	if !strings.HasPrefix(r.Header.Get("Authorization"), "AWS4-HMAC-SHA256 Credential=ak/") ||
+		r.Header.Get("x-amz-content-sha256") != sha256Hex(body) {
+		w.WriteHeader(http.StatusForbidden)
+		return
+	}
+	query := r.URL.Query()
+	switch {
+	case r.Method == http.MethodPost && query.Has("uploads"):
+		f.parts = map[int][]byte{}
+		_, _ = w.Write([]byte("<InitiateMultipartUploadResult><UploadId>u1</UploadId></InitiateMultipartUploadResult>"))
+	case r.Method == http.MethodPut && query.Has("partNumber"):
+		number, _ := strconv.Atoi(query.Get("partNumber"))
+		if f.failParts[number] {
+			delete(f.failParts, number)
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		f.parts[number] = body
+		w.Header().Set("ETag", `"etag`+strconv.Itoa(number)+`"`)
+	case r.Method == http.MethodPost && query.Has("uploadId"):
+		var complete completeMultipartUpload
+		_ = xml.Unmarshal(body, &complete)
+		var object []byte
+		for i, p := range complete.Parts {
+			if p.PartNumber != i+1 || p.ETag != `"etag`+strconv.Itoa(i+1)+`"` {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			object = append(object, f.parts[p.PartNumber]...)
+		}
+		f.objects[r.URL.Path] = object
+	case r.Method == http.MethodPut:
+		f.objects[r.URL.Path] = body
+	default:
+		w.WriteHeader(http.StatusBadRequest)
+	}
+}
+
+func TestS3Deliver(t *testing.T) {
+	fake := &fakeS3{objects: map[string][]byte{}, failParts: map[int]bool{2: true}}
+	server := httptest.NewServer(fake)
+	defer server.Close()
+
+	d, err := New(&Config{Type: TypeS3, Endpoint: server.URL, Bucket: "exports", AccessKeyID: "ak", SecretAccessKey: "sk", PathStyle: true, Path: "dms/"})
+	assert.NoError(t, err)
+	s3d := d.(*s3Destination)
+	s3d.partSize = 1000
+
+	localPath, data := writeTempFile(t, 2500)
+	state := &State{}
+	_, err = d.Deliver(context.Background(), localPath, "w1/export (1).zip", state, func(*State) error { return nil })
+	assert.Error(t, err)
+	assert.Equal(t, "u1", state.UploadID)
+	assert.Len(t, state.Parts, 1)
+
+	location, err := d.Deliver(context.Background(), localPath, "w1/export (1).zip", state, func(*State) error { return nil })
+	assert.NoError(t, err)
+	assert.Equal(t, "s3://exports/dms/w1/export (1).zip", location)
+	assert.Equal(t, data, fake.objects["/exports/dms/w1/export (1).zip"])
+
+	small, smallData := writeTempFile(t, 10)
+	_, err = d.Deliver(context.Background(), small, "w2/small.zip", &State{}, func(*State) error { return nil })
+	assert.NoError(t, err)
+	assert.True(t, bytes.Equal(smallData, fake.objects["/exports/dms/w2/small.zip"]))
+}
+
+func TestConfigValidate(t *testing.T) {
+	assert.Error(t, (&Config{Type: TypeLocal, Path: "relative"}).Validate())
+	assert.Error(t, (&Config{Type: TypeS3, Endpoint: "minio:9000", Bucket: "b", AccessKeyID: "a", SecretAccessKey: "s"}).Validate())
+	assert.NoError(t, (&Config{Type: TypeS3, Endpoint: "http://minio:9000", Bucket: "b", AccessKeyID: "a", SecretAccessKey: "s"}).Validate())
+	assert.Error(t, (&Config{Type: TypeSFTP, Host: "h", Username: "u"}).Validate())
+	sftp := Config{Type: TypeSFTP, Host: "h", Username: "u", PrivateKey: "k", HostPublicKey: "ssh-ed25519 AAAA"}
+	assert.NoError(t, sftp.Validate())
+	for _, c := range []func(c *Config){
+		func(c *Config) { c.HostPublicKey = "" },
+		func(c *Config) { c.Host = "-oProxyCommand=touch /tmp/x" },
+		func(c *Config) { c.Host = "u@h" },
+		func(c *Config) { c.Username = "-F/tmp/config" },
+		func(c *Config) { c.Username = "u h" },
+		func(c *Config) { c.Path = "exports\nrm /etc" },
+	} {
+		invalid := sftp
+		c(&invalid)
+		assert.Error(t, invalid.Validate())
+	}
+	assert.Error(t, (&Config{Type: "ftp"}).Validate())
+}
@@ -0,0 +1,93 @@
+package exportdelivery
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+)
+
+type localDestination struct {
+	dir string
+}
+
+func newLocalDestination(cfg *Config) *localDestination {
+	return &localDestination{dir: cfg.Path}
+}
+
+// Deliver 先写入 .part 文件，完成后重命名，重试时从已写入的位置继续
+func (l *localDestination) Deliver(ctx context.Context, localPath, name string, state *State, checkpoint Checkpoint) (string, error) {
+	name, err := cleanName(name)
+	if err != nil {
+		return "", err
+	}
+	target := filepath.Join(l.dir, filepath.FromSlash(name))
+	if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+		return "", fmt.Errorf("create delivery directory failed: %v", err)
+	}
+
+	src, err := os.Open(localPath)
+	if err != nil {
+		return "", err
+	}
+	defer src.Close()
+
+	partPath := target + ".part"
+	dst, err := os.OpenFile(partPath, os.O_CREATE|os.O_WRONLY, 0640)
+	if err != nil {
+		return "", fmt.Errorf("open delivery file failed: %v", err)
+	}
+	defer dst.Close()
+
+	// 以保存的进度和实际写入的大小中较小的为准，丢弃可能未完整写入的部分，进度同步回退后再继续累加
+	info, err := dst.Stat()
+	if err != nil {
+		return "", err
+	}
+	offset := min(state.Offset, info.Size())
+	if err := dst.Truncate(offset); err != nil {
+		return "", err
+	}
+	state.Offset = offset
+	if _, err := dst.Seek(offset, io.SeekStart); err != nil {
+		return "", err
+	}
+	if _, err := src.Seek(offset, io.SeekStart); err != nil {
+		return "", err
+	}
+
+	buf := make([]byte, partSize)
+	for {
+		if err := ctx.Err(); err != nil {
+			return "", err
+		}
+		n, readErr := io.ReadFull(src, buf)
+		if n > 0 {
+			if _, err := dst.Write(buf[:n]); err != nil {
+				return "", fmt.Errorf("write delivery file failed: %v", err)
+			}
+			if err := dst.Sync(); err != nil {
+				return "", fmt.Errorf("sync delivery file failed: %v", err)
+			}
+			state.Offset += int64(n)
+			if err := checkpoint(state); err != nil {
+				return "", err
+			}
+		}
+		if readErr == io.EOF || readErr == io.ErrUnexpectedEOF {
+			break
+		}
+		if readErr != nil {
+			return "", readErr
+		}
+	}
+
+	if err := dst.Close(); err != nil {
+		return "", err
+	}
+	if err := os.Rename(partPath, target); err != nil {
+		return "", fmt.Errorf("rename delivery file failed: %v", err)
+	}
+	return "file://" + filepath.ToSlash(target), nil
+}
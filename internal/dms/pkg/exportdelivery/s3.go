@@ -0,0 +1,301 @@
+package exportdelivery
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const defaultS3Region = "us-east-1"
+
+// s3Destination 使用 SigV4 签名的 S3 REST 接口上传，大于一个分片的文件使用分片上传以支持断点续传
+type s3Destination struct {
+	endpoint *url.URL
+	cfg      *Config
+	client   *http.Client
+	partSize int64
+}
+
+func newS3Destination(cfg *Config) *s3Destination {
+	endpoint, _ := url.Parse(strings.TrimSuffix(cfg.Endpoint, "/"))
+	return &s3Destination{
+		endpoint: endpoint,
+		cfg:      cfg,
+		client:   &http.Client{Timeout: 30 * time.Minute},
+		partSize: partSize,
+	}
+}
+
+func (s *s3Destination) region() string {
+	if s.cfg.Region == "" {
+		return defaultS3Region
+	}
+	return s.cfg.Region
+}
+
+func (s *s3Destination) objectURL(key string, query url.Values) *url.URL {
+	u := *s.endpoint
+	objectPath := "/" + key
+	if s.cfg.PathStyle {
+		objectPath = "/" + s.cfg.Bucket + objectPath
+	} else {
+		u.Host = s.cfg.Bucket + "." + u.Host
+	}
+	// RawPath 按 SigV4 的规则编码，签名与请求使用同一路径
+	u.RawPath = u.EscapedPath() + encodePath(objectPath)
+	u.Path = u.Path + objectPath
+	u.RawQuery = canonicalQuery(query)
+	return &u
+}
+
+func (s *s3Destination) Deliver(ctx context.Context, localPath, name string, state *State, checkpoint Checkpoint) (string, error) {
+	name, err := cleanName(name)
+	if err != nil {
+		return "", err
+	}
+	key := name
+	if prefix := strings.Trim(s.cfg.Path, "/"); prefix != "" {
+		key = prefix + "/" + name
+	}
+	location := fmt.Sprintf("s3://%s/%s", s.cfg.Bucket, key)
+
+	f, err := os.Open(localPath)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	info, err := f.Stat()
+	if err != nil {
+		return "", err
+	}
+
+	if info.Size() <= s.partSize {
+		body, err := io.ReadAll(f)
+		if err != nil {
+			return "", err
+		}
+		if _, _, err := s.do(ctx, http.MethodPut, key, nil, body); err != nil {
+			return "", err
+		}
+		return location, nil
+	}
+
+	if state.UploadID == "" {
+		if err := s.createMultipartUpload(ctx, key, state, checkpoint); err != nil {
+			return "", err
+		}
+	}
+	err = s.uploadParts(ctx, key, f, info.Size(), state, checkpoint)
+	if isNoSuchUpload(err) {
+		// 上传 ID 已被清理时重新开始
+		state.UploadID, state.Parts = "", nil
+		if err := s.createMultipartUpload(ctx, key, state, checkpoint); err != nil {
+			return "", err
+		}
+		err = s.uploadParts(ctx, key, f, info.Size(), state, checkpoint)
+	}
+	if err != nil {
+		return "", err
+	}
+	if err := s.completeMultipartUpload(ctx, key, state); err != nil {
+		return "", err
+	}
+	return location, nil
+}
+
+func (s *s3Destination) createMultipartUpload(ctx context.Context, key string, state *State, checkpoint Checkpoint) error {
+	resp, _, err := s.do(ctx, http.MethodPost, key, url.Values{"uploads": {""}}, nil)
+	if err != nil {
+		return err
+	}
+	var result struct {
+		UploadID string `xml:"UploadId"`
+	}
+	if err := xml.Unmarshal(resp, &result); err != nil || result.UploadID == "" {
+		return fmt.Errorf("parse create multipart upload response failed: %s", resp)
+	}
+	state.UploadID = result.UploadID
+	return checkpoint(state)
+}
+
+func (s *s3Destination) uploadParts(ctx context.Context, key string, f io.ReaderAt, size int64, state *State, checkpoint Checkpoint) error {
+	buf := make([]byte, s.partSize)
+	for number := len(state.Parts) + 1; int64(number-1)*s.partSize < size; number++ {
+		offset := int64(number-1) * s.partSize
+		n, err := f.ReadAt(buf[:min(s.partSize, size-offset)], offset)
+		if err != nil && err != io.EOF {
+			return err
+		}
+		_, header, err := s.do(ctx, http.MethodPut, key, url.Values{
+			"partNumber": {strconv.Itoa(number)},
+			"uploadId":   {state.UploadID},
+		}, buf[:n])
+		if err != nil {
+			return err
+		}
+		state.Parts = append(state.Parts, Part{Number: number, ETag: header.Get("ETag")})
+		if err := checkpoint(state); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+type completeMultipartUpload struct {
+	XMLName xml.Name       `xml:"CompleteMultipartUpload"`
+	Parts   []completePart `xml:"Part"`
+}
+
+type completePart struct {
+	PartNumber int    `xml:"PartNumber"`
+	ETag       string `xml:"ETag"`
+}
+
+func (s *s3Destination) completeMultipartUpload(ctx context.Context, key string, state *State) error {
+	body := completeMultipartUpload{}
+	for _, p := range state.Parts {
+		body.Parts = append(body.Parts, completePart{PartNumber: p.Number, ETag: p.ETag})
+	}
+	data, err := xml.Marshal(body)
+	if err != nil {
+		return err
+	}
+	resp, _, err := s.do(ctx, http.MethodPost, key, url.Values{"uploadId": {state.UploadID}}, data)
+	if err != nil {
+		return err
+	}
+	// 合并失败时也可能返回 200，错误信息在响应体中
+	if bytes.Contains(resp, []byte("<Error>")) {
+		return parseS3Error(http.StatusOK, resp)
+	}
+	return nil
+}
+
+func (s *s3Destination) do(ctx context.Context, method, key string, query url.Values, body []byte) ([]byte, http.Header, error) {
+	u := s.objectURL(key, query)
+	req, err := http.NewRequestWithContext(ctx, method, u.String(), bytes.NewReader(body))
+	if err != nil {
+		return nil, nil, err
+	}
+	s.sign(req, u, body)
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nil, nil, fmt.Errorf("s3 request failed: %v", err)
+	}
+	defer resp.Body.Close()
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, nil, err
+	}
+	if resp.StatusCode >= 300 {
+		return nil, nil, parseS3Error(resp.StatusCode, data)
+	}
+	return data, resp.Header, nil
+}
+
+// sign 按 AWS Signature Version 4 签名请求
+func (s *s3Destination) sign(req *http.Request, u *url.URL, body []byte) {
+	now := time.Now().UTC()
+	amzDate := now.Format("20060102T150405Z")
+	date := now.Format("20060102")
+	payloadHash := sha256Hex(body)
+
+	req.Header.Set("x-amz-date", amzDate)
+	req.Header.Set("x-amz-content-sha256", payloadHash)
+
+	signedHeaders := "host;x-amz-content-sha256;x-amz-date"
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		u.EscapedPath(),
+		u.RawQuery,
+		"host:" + u.Host + "\n" + "x-amz-content-sha256:" + payloadHash + "\n" + "x-amz-date:" + amzDate + "\n",
+		signedHeaders,
+		payloadHash,
+	}, "\n")
+	scope := date + "/" + s.region() + "/s3/aws4_request"
+	stringToSign := "AWS4-HMAC-SHA256\n" + amzDate + "\n" + scope + "\n" + sha256Hex([]byte(canonicalRequest))
+
+	key := hmacSHA256([]byte("AWS4"+s.cfg.SecretAccessKey), date)
+	key = hmacSHA256(key, s.region())
+	key = hmacSHA256(key, "s3")
+	key = hmacSHA256(key, "aws4_request")
+	signature := hex.EncodeToString(hmacSHA256(key, stringToSign))
+
+	req.Header.Set("Authorization", fmt.Sprintf("AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		s.cfg.AccessKeyID, scope, signedHeaders, signature))
+}
+
+// canonicalQuery 按 SigV4 要求排序并编码查询参数，空格编码为 %20
+func canonicalQuery(query url.Values) string {
+	keys := make([]string, 0, len(query))
+	for k := range query {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	parts := make([]string, 0, len(keys))
+	for _, k := range keys {
+		for _, v := range query[k] {
+			parts = append(parts, uriEncode(k)+"="+uriEncode(v))
+		}
+	}
+	return strings.Join(parts, "&")
+}
+
+func encodePath(p string) string {
+	segments := strings.Split(p, "/")
+	for i, segment := range segments {
+		segments[i] = uriEncode(segment)
+	}
+	return strings.Join(segments, "/")
+}
+
+func uriEncode(s string) string {
+	return strings.ReplaceAll(url.QueryEscape(s), "+", "%20")
+}
+
+func sha256Hex(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
+
+type s3Error struct {
+	StatusCode int
+	Code       string `xml:"Code"`
+	Message    string `xml:"Message"`
+}
+
+func (e *s3Error) Error() string {
+	return fmt.Sprintf("s3 request failed: status %d, %s: %s", e.StatusCode, e.Code, e.Message)
+}
+
+func parseS3Error(statusCode int, body []byte) error {
+	e := &s3Error{StatusCode: statusCode}
+	if err := xml.Unmarshal(body, e); err != nil {
+		e.Message = string(body)
+	}
+	return e
+}
+
+func isNoSuchUpload(err error) bool {
+	e, ok := err.(*s3Error)
+	return ok && e.Code == "NoSuchUpload"
+}
@@ -0,0 +1,138 @@
+package exportdelivery
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// sftpCommand OpenSSH 的 sftp 客户端，DMS 所在服务器需已安装
+var sftpCommand = "sftp"
+
+type sftpDestination struct {
+	cfg *Config
+}
+
+func newSFTPDestination(cfg *Config) *sftpDestination {
+	return &sftpDestination{cfg: cfg}
+}
+
+// Deliver 先上传为 .part 文件，完成后重命名；重试时使用 reput 从远程文件已有的大小继续上传
+func (s *sftpDestination) Deliver(ctx context.Context, localPath, name string, state *State, checkpoint Checkpoint) (string, error) {
+	name, err := cleanName(name)
+	if err != nil {
+		return "", err
+	}
+	if strings.ContainsAny(name, "\r\n") {
+		return "", fmt.Errorf("invalid delivery file name: %s", name)
+	}
+	remote := path.Join(s.cfg.Path, name)
+	if !path.IsAbs(remote) {
+		remote = "./" + remote
+	}
+	partRemote := remote + ".part"
+
+	tmpDir, err := os.MkdirTemp("", "dms-sftp-")
+	if err != nil {
+		return "", err
+	}
+	defer os.RemoveAll(tmpDir)
+
+	keyPath := filepath.Join(tmpDir, "id")
+	key := s.cfg.PrivateKey
+	if !strings.HasSuffix(key, "\n") {
+		key += "\n"
+	}
+	if err := os.WriteFile(keyPath, []byte(key), 0600); err != nil {
+		return "", err
+	}
+
+	knownHostsPath := filepath.Join(tmpDir, "known_hosts")
+	if err := os.WriteFile(knownHostsPath, []byte(s.knownHostsLine()), 0600); err != nil {
+		return "", err
+	}
+
+	port := s.cfg.Port
+	if port == 0 {
+		port = 22
+	}
+	run := func(resume bool) error {
+		// 以 - 开头的命令失败时不中止，用于创建已存在的目录及删除不存在的文件
+		var batch strings.Builder
+		for _, d := range parentDirs(path.Dir(remote)) {
+			fmt.Fprintf(&batch, "-mkdir %s\n", quoteSFTPPath(d))
+		}
+		upload := "put"
+		if resume {
+			upload = "reput"
+		}
+		fmt.Fprintf(&batch, "%s %s %s\n", upload, quoteSFTPPath(localPath), quoteSFTPPath(partRemote))
+		fmt.Fprintf(&batch, "-rm %s\n", quoteSFTPPath(remote))
+		fmt.Fprintf(&batch, "rename %s %s\n", quoteSFTPPath(partRemote), quoteSFTPPath(remote))
+		batchPath := filepath.Join(tmpDir, "batch")
+		if err := os.WriteFile(batchPath, []byte(batch.String()), 0600); err != nil {
+			return err
+		}
+
+		cmd := exec.CommandContext(ctx, sftpCommand,
+			"-b", batchPath,
+			"-P", strconv.Itoa(port),
+			"-i", keyPath,
+			"-o", "IdentitiesOnly=yes",
+			"-o", "StrictHostKeyChecking=yes",
+			"-o", "UserKnownHostsFile="+knownHostsPath,
+			"--",
+			s.cfg.Username+"@"+s.cfg.Host,
+		)
+		if output, err := cmd.CombinedOutput(); err != nil {
+			return fmt.Errorf("sftp upload failed: %v: %s", err, strings.TrimSpace(string(output)))
+		}
+		return nil
+	}
+
+	resume := state.Partial
+	// 开始上传后远程即可能存在未完成的文件，之后的重试需要续传
+	state.Partial = true
+	if err := checkpoint(state); err != nil {
+		return "", err
+	}
+	err = run(resume)
+	if err != nil && resume && ctx.Err() == nil {
+		// 上次中断时远程文件可能尚未创建，续传失败时重新上传
+		err = run(false)
+	}
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("sftp://%s:%d/%s", s.cfg.Host, port, strings.TrimPrefix(remote, "/")), nil
+}
+
+// knownHostsLine 服务器公钥可只填写 "类型 公钥"，此时补充主机名
+func (s *sftpDestination) knownHostsLine() string {
+	line := strings.TrimSpace(s.cfg.HostPublicKey)
+	if strings.HasPrefix(line, "ssh-") || strings.HasPrefix(line, "ecdsa-") {
+		host := s.cfg.Host
+		if s.cfg.Port != 0 && s.cfg.Port != 22 {
+			host = fmt.Sprintf("[%s]:%d", s.cfg.Host, s.cfg.Port)
+		}
+		line = host + " " + line
+	}
+	return line + "\n"
+}
+
+func parentDirs(dir string) []string {
+	var dirs []string
+	for d := dir; d != "." && d != "/" && d != ""; d = path.Dir(d) {
+		dirs = append([]string{d}, dirs...)
+	}
+	return dirs
+}
+
+func quoteSFTPPath(p string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(p) + `"`
+}
@@ -0,0 +1,37 @@
+package exportfile
+
+import (
+	"database/sql"
+	"encoding/csv"
+	"fmt"
+	"io"
+)
+
+type csvWriter struct {
+	w      *csv.Writer
+	record []string
+}
+
+func newCSVWriter(w io.Writer, columns []string) (*csvWriter, error) {
+	// 写入 BOM，避免 Excel 打开中文乱码
+	if _, err := io.WriteString(w, "\xEF\xBB\xBF"); err != nil {
+		return nil, fmt.Errorf("write csv bom failed: %v", err)
+	}
+	c := &csvWriter{w: csv.NewWriter(w), record: make([]string, len(columns))}
+	if err := c.w.Write(columns); err != nil {
+		return nil, fmt.Errorf("write csv header failed: %v", err)
+	}
+	return c, nil
+}
+
+func (c *csvWriter) WriteRow(row []sql.NullString) error {
+	for i, v := range row {
+		c.record[i] = v.String
+	}
+	return c.w.Write(c.record[:len(row)])
+}
+
+func (c *csvWriter) Close() error {
+	c.w.Flush()
+	return c.w.Error()
+}
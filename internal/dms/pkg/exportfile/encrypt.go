@@ -0,0 +1,175 @@
+package exportfile
+
+import (
+	"archive/zip"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/pbkdf2"
+	"crypto/rand"
+	"crypto/sha1"
+	"encoding/binary"
+	"fmt"
+	"hash"
+	"io"
+	"math/big"
+	"path"
+	"strings"
+	"time"
+)
+
+// 加密归档使用 WinZip AES 格式（AE-2，AES-256），7-Zip、WinRAR、macOS 归档工具等均可解压
+const (
+	winZipAESMethod       = 99
+	winZipAESExtraID      = 0x9901
+	winZipAESVersionAE2   = 2
+	winZipAESStrength256  = 3
+	winZipAESKeyLen       = 32
+	winZipAESSaltLen      = 16
+	winZipAESVerifierLen  = 2
+	winZipAESMACLen       = 10
+	winZipAESIterations   = 1000
+	zipFlagEncrypted      = 0x1
+	zipFlagDataDescriptor = 0x8
+
+	passwordLength = 20
+	// passwordChars 去除了易混淆的字符，便于用户从通知中手动输入
+	passwordChars = "ABCDEFGHJKLMNPQRSTUVWXYZabcdefghijkmnpqrstuvwxyz23456789"
+)
+
+// GeneratePassword 生成导出文件的随机解压密码
+func GeneratePassword() (string, error) {
+	max := big.NewInt(int64(len(passwordChars)))
+	b := make([]byte, passwordLength)
+	for i := range b {
+		n, err := rand.Int(rand.Reader, max)
+		if err != nil {
+			return "", fmt.Errorf("generate password failed: %v", err)
+		}
+		b[i] = passwordChars[n.Int64()]
+	}
+	return string(b), nil
+}
+
+// EncryptedFileName 加密归档的文件名，归档中保留原导出文件
+func EncryptedFileName(fileName string) string {
+	return strings.TrimSuffix(fileName, path.Ext(fileName)) + ".encrypted.zip"
+}
+
+// NewEncryptedWriter 将写入的内容作为 fileName 流式加密写入 zip 归档，Close 时写入校验码并结束归档
+func NewEncryptedWriter(w io.Writer, fileName, password string) (io.WriteCloser, error) {
+	if password == "" {
+		return nil, fmt.Errorf("password is empty")
+	}
+	salt := make([]byte, winZipAESSaltLen)
+	if _, err := rand.Read(salt); err != nil {
+		return nil, fmt.Errorf("generate salt failed: %v", err)
+	}
+	key, err := pbkdf2.Key(sha1.New, password, salt, winZipAESIterations, 2*winZipAESKeyLen+winZipAESVerifierLen)
+	if err != nil {
+		return nil, fmt.Errorf("derive key failed: %v", err)
+	}
+	block, err := aes.NewCipher(key[:winZipAESKeyLen])
+	if err != nil {
+		return nil, err
+	}
+
+	extra := make([]byte, 11)
+	binary.LittleEndian.PutUint16(extra[0:], winZipAESExtraID)
+	binary.LittleEndian.PutUint16(extra[2:], 7)
+	binary.LittleEndian.PutUint16(extra[4:], winZipAESVersionAE2)
+	copy(extra[6:], "AE")
+	extra[8] = winZipAESStrength256
+	// 导出文件本身已是压缩格式，归档中不再压缩
+	binary.LittleEndian.PutUint16(extra[9:], uint16(zip.Store))
+
+	fh := &zip.FileHeader{
+		Name:     fileName,
+		Method:   winZipAESMethod,
+		Flags:    zipFlagEncrypted | zipFlagDataDescriptor,
+		Extra:    extra,
+		Modified: time.Now(),
+	}
+	zw := zip.NewWriter(w)
+	f, err := zw.CreateRaw(fh)
+	if err != nil {
+		return nil, fmt.Errorf("create encrypted file failed: %v", err)
+	}
+	e := &encryptedWriter{
+		zw:     zw,
+		f:      f,
+		header: fh,
+		stream: newWinZipAESCTR(block),
+		mac:    hmac.New(sha1.New, key[winZipAESKeyLen:2*winZipAESKeyLen]),
+	}
+	if _, err := f.Write(salt); err != nil {
+		return nil, err
+	}
+	if _, err := f.Write(key[2*winZipAESKeyLen:]); err != nil {
+		return nil, err
+	}
+	return e, nil
+}
+
+type encryptedWriter struct {
+	zw     *zip.Writer
+	f      io.Writer
+	header *zip.FileHeader
+	stream cipher.Stream
+	mac    hash.Hash
+	buf    []byte
+	size   uint64
+}
+
+func (e *encryptedWriter) Write(p []byte) (int, error) {
+	if cap(e.buf) < len(p) {
+		e.buf = make([]byte, len(p))
+	}
+	buf := e.buf[:len(p)]
+	e.stream.XORKeyStream(buf, p)
+	e.mac.Write(buf)
+	n, err := e.f.Write(buf)
+	e.size += uint64(n)
+	return n, err
+}
+
+func (e *encryptedWriter) Close() error {
+	if _, err := e.f.Write(e.mac.Sum(nil)[:winZipAESMACLen]); err != nil {
+		return err
+	}
+	// AE-2 不记录 CRC，由校验码保证完整性；大小在数据描述符及中央目录中写入
+	e.header.UncompressedSize64 = e.size
+	e.header.CompressedSize64 = e.size + winZipAESSaltLen + winZipAESVerifierLen + winZipAESMACLen
+	e.header.UncompressedSize = uint32(min(e.header.UncompressedSize64, 0xffffffff))
+	e.header.CompressedSize = uint32(min(e.header.CompressedSize64, 0xffffffff))
+	return e.zw.Close()
+}
+
+// winZipAESCTR WinZip AES 使用小端序计数器、从 1 开始的 CTR 模式，与 cipher.NewCTR 的大端序计数器不同
+type winZipAESCTR struct {
+	block   cipher.Block
+	counter [aes.BlockSize]byte
+	stream  [aes.BlockSize]byte
+	used    int
+}
+
+func newWinZipAESCTR(block cipher.Block) *winZipAESCTR {
+	return &winZipAESCTR{block: block, used: aes.BlockSize}
+}
+
+func (c *winZipAESCTR) XORKeyStream(dst, src []byte) {
+	for i := range src {
+		if c.used == aes.BlockSize {
+			for j := range c.counter {
+				c.counter[j]++
+				if c.counter[j] != 0 {
+					break
+				}
+			}
+			c.block.Encrypt(c.stream[:], c.counter[:])
+			c.used = 0
+		}
+		dst[i] = src[i] ^ c.stream[c.used]
+		c.used++
+	}
+}
@@ -0,0 +1,121 @@
+package exportfile
+
+import (
+	"archive/zip"
+	"bytes"
+	"crypto/aes"
+	"crypto/hmac"
+	"crypto/pbkdf2"
+	"crypto/sha1"
+	"encoding/binary"
+	"io"
+	"strings"
+	"testing"
+)
+
+// decryptWinZipAES 按 WinZip AES 规范解密归档中的文件
+func decryptWinZipAES(t *testing.T, data []byte, password string) (string, []byte, error) {
+	t.Helper()
+	zr, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		t.Fatalf("open zip: %v", err)
+	}
+	if len(zr.File) != 1 {
+		t.Fatalf("expect 1 file, got %d", len(zr.File))
+	}
+	f := zr.File[0]
+	if f.Method != winZipAESMethod || f.Flags&zipFlagEncrypted == 0 {
+		t.Fatalf("unexpected method %d flags %x", f.Method, f.Flags)
+	}
+	if binary.LittleEndian.Uint16(f.Extra) != winZipAESExtraID || f.Extra[8] != winZipAESStrength256 {
+		t.Fatalf("unexpected extra %x", f.Extra)
+	}
+	rc, err := f.OpenRaw()
+	if err != nil {
+		t.Fatalf("open raw: %v", err)
+	}
+	raw, err := io.ReadAll(rc)
+	if err != nil {
+		t.Fatalf("read raw: %v", err)
+	}
+	if uint64(len(raw)) != f.CompressedSize64 {
+		t.Fatalf("raw size %d, header %d", len(raw), f.CompressedSize64)
+	}
+
+	salt, verifier := raw[:winZipAESSaltLen], raw[winZipAESSaltLen:winZipAESSaltLen+winZipAESVerifierLen]
+	content, mac := raw[winZipAESSaltLen+winZipAESVerifierLen:len(raw)-winZipAESMACLen], raw[len(raw)-winZipAESMACLen:]
+	key, _ := pbkdf2.Key(sha1.New, password, salt, winZipAESIterations, 2*winZipAESKeyLen+winZipAESVerifierLen)
+	if !bytes.Equal(key[2*winZipAESKeyLen:], verifier) {
+		return "", nil, io.ErrUnexpectedEOF
+	}
+	h := hmac.New(sha1.New, key[winZipAESKeyLen:2*winZipAESKeyLen])
+	h.Write(content)
+	if !hmac.Equal(h.Sum(nil)[:winZipAESMACLen], mac) {
+		t.Fatalf("authentication code mismatch")
+	}
+	block, _ := aes.NewCipher(key[:winZipAESKeyLen])
+	plain := make([]byte, len(content))
+	newWinZipAESCTR(block).XORKeyStream(plain, content)
+	if uint64(len(plain)) != f.UncompressedSize64 {
+		t.Fatalf("plain size %d, header %d", len(plain), f.UncompressedSize64)
+	}
+	return f.Name, plain, nil
+}
+
+func TestEncryptedWriter(t *testing.T) {
+	password, err := GeneratePassword()
+	if err != nil {
+		t.Fatalf("generate password: %v", err)
+	}
+	if len(password) != passwordLength || strings.ContainsAny(password, "0O1lI") {
+		t.Fatalf("unexpected password %q", password)
+	}
+
+	buf := &bytes.Buffer{}
+	enc, err := NewEncryptedWriter(buf, "export.zip", password)
+	if err != nil {
+		t.Fatalf("new encrypted writer: %v", err)
+	}
+	// 通过导出格式写入，验证加密可以与流式导出组合
+	w, err := NewWriter(FileTypeCSV, enc, Options{})
+	if err != nil {
+		t.Fatalf("new writer: %v", err)
+	}
+	if err := w.BeginResult(&Result{Name: "users", Columns: []string{"id"}}); err != nil {
+		t.Fatalf("begin result: %v", err)
+	}
+	if err := w.WriteRow(nil); err != nil {
+		t.Fatalf("write row: %v", err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatalf("close writer: %v", err)
+	}
+	if err := enc.Close(); err != nil {
+		t.Fatalf("close encrypted writer: %v", err)
+	}
+	if bytes.Contains(buf.Bytes(), []byte("users.csv")) {
+		t.Fatalf("encrypted archive contains plain content")
+	}
+
+	name, plain, err := decryptWinZipAES(t, buf.Bytes(), password)
+	if err != nil {
+		t.Fatalf("decrypt: %v", err)
+	}
+	if name != "export.zip" {
+		t.Errorf("name = %s", name)
+	}
+	files := readZip(t, plain)
+	if files["users.csv"] != "\xEF\xBB\xBFid\n\n" {
+		t.Errorf("users.csv = %q", files["users.csv"])
+	}
+
+	if _, _, err := decryptWinZipAES(t, buf.Bytes(), password+"x"); err == nil {
+		t.Errorf("decrypt with wrong password should fail")
+	}
+}
+
+func TestEncryptedFileName(t *testing.T) {
+	if got := EncryptedFileName("export_20240101.zip"); got != "export_20240101.encrypted.zip" {
+		t.Errorf("EncryptedFileName = %s", got)
+	}
+}
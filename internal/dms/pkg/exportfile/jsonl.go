@@ -0,0 +1,63 @@
+package exportfile
+
+import (
+	"bufio"
+	"bytes"
+	"database/sql"
+	"encoding/json"
+	"io"
+)
+
+// jsonlWriter 每行一个 JSON 对象，按查询结果的列顺序输出字段
+type jsonlWriter struct {
+	w    *bufio.Writer
+	keys [][]byte
+	buf  *bytes.Buffer
+	enc  *json.Encoder
+}
+
+func newJSONLWriter(w io.Writer, columns []string) *jsonlWriter {
+	j := &jsonlWriter{w: bufio.NewWriter(w), buf: &bytes.Buffer{}}
+	// 导出数据按原样输出，不转义 HTML 字符
+	j.enc = json.NewEncoder(j.buf)
+	j.enc.SetEscapeHTML(false)
+	for _, c := range columns {
+		key, _ := j.marshal(c)
+		j.keys = append(j.keys, append([]byte{}, key...))
+	}
+	return j
+}
+
+func (j *jsonlWriter) marshal(v string) ([]byte, error) {
+	j.buf.Reset()
+	if err := j.enc.Encode(v); err != nil {
+		return nil, err
+	}
+	return bytes.TrimSuffix(j.buf.Bytes(), []byte("\n")), nil
+}
+
+func (j *jsonlWriter) WriteRow(row []sql.NullString) error {
+	j.w.WriteByte('{')
+	for i, v := range row {
+		if i > 0 {
+			j.w.WriteByte(',')
+		}
+		j.w.Write(j.keys[i])
+		j.w.WriteByte(':')
+		if !v.Valid {
+			j.w.WriteString("null")
+			continue
+		}
+		value, err := j.marshal(v.String)
+		if err != nil {
+			return err
+		}
+		j.w.Write(value)
+	}
+	j.w.WriteByte('}')
+	return j.w.WriteByte('\n')
+}
+
+func (j *jsonlWriter) Close() error {
+	return j.w.Flush()
+}
@@ -0,0 +1,63 @@
+package exportfile
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"io"
+)
+
+var (
+	ErrRowLimitExceeded      = errors.New("export rows exceed the limit")
+	ErrFileSizeLimitExceeded = errors.New("export file size exceeds the limit")
+)
+
+// LimitError 导出超出行数或文件大小限制，Limit 为触发的限制值
+type LimitError struct {
+	Err   error
+	Limit int64
+}
+
+func (e *LimitError) Error() string {
+	return fmt.Sprintf("%v: %d", e.Err, e.Limit)
+}
+
+func (e *LimitError) Unwrap() error {
+	return e.Err
+}
+
+// rowLimitWriter 限制单个结果集（即单条导出 SQL）的行数
+type rowLimitWriter struct {
+	Writer
+	max  int64
+	rows int64
+}
+
+func (r *rowLimitWriter) BeginResult(result *Result) error {
+	r.rows = 0
+	return r.Writer.BeginResult(result)
+}
+
+func (r *rowLimitWriter) WriteRow(row []sql.NullString) error {
+	if r.rows >= r.max {
+		return &LimitError{Err: ErrRowLimitExceeded, Limit: r.max}
+	}
+	r.rows++
+	return r.Writer.WriteRow(row)
+}
+
+// sizeLimitWriter 限制写出的字节数，超出后不再写入，避免生成超过限制的文件
+type sizeLimitWriter struct {
+	w       io.Writer
+	max     int64
+	written int64
+}
+
+func (s *sizeLimitWriter) Write(p []byte) (int, error) {
+	if s.written+int64(len(p)) > s.max {
+		return 0, &LimitError{Err: ErrFileSizeLimitExceeded, Limit: s.max}
+	}
+	n, err := s.w.Write(p)
+	s.written += int64(n)
+	return n, err
+}
@@ -0,0 +1,60 @@
+package exportfile
+
+import (
+	"bytes"
+	"crypto/rand"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"testing"
+)
+
+func TestRowLimit(t *testing.T) {
+	w, err := NewWriter(FileTypeCSV, &bytes.Buffer{}, Options{MaxRowsPerResult: 2})
+	if err != nil {
+		t.Fatalf("new writer: %v", err)
+	}
+	row := []sql.NullString{{String: "1", Valid: true}}
+	for _, name := range []string{"a", "b"} {
+		if err := w.BeginResult(&Result{Name: name, Columns: []string{"id"}}); err != nil {
+			t.Fatalf("begin result: %v", err)
+		}
+		// 行数限制按结果集计算
+		for i := 0; i < 2; i++ {
+			if err := w.WriteRow(row); err != nil {
+				t.Fatalf("write row %d of %s: %v", i, name, err)
+			}
+		}
+	}
+	err = w.WriteRow(row)
+	var limitErr *LimitError
+	if !errors.As(err, &limitErr) || !errors.Is(err, ErrRowLimitExceeded) || limitErr.Limit != 2 {
+		t.Fatalf("expect row limit error, got %v", err)
+	}
+}
+
+func TestFileSizeLimit(t *testing.T) {
+	buf := &bytes.Buffer{}
+	w, err := NewWriter(FileTypeCSV, buf, Options{MaxFileSize: 1024})
+	if err != nil {
+		t.Fatalf("new writer: %v", err)
+	}
+	if err := w.BeginResult(&Result{Name: "a", Columns: []string{"v"}}); err != nil {
+		t.Fatalf("begin result: %v", err)
+	}
+	// 随机内容无法被压缩
+	value := make([]byte, 100)
+	for i := 0; i < 100 && err == nil; i++ {
+		rand.Read(value)
+		err = w.WriteRow([]sql.NullString{{String: hex.EncodeToString(value), Valid: true}})
+	}
+	if err == nil {
+		err = w.Close()
+	}
+	if !errors.Is(err, ErrFileSizeLimitExceeded) {
+		t.Fatalf("expect file size limit error, got %v", err)
+	}
+	if buf.Len() > 1024 {
+		t.Errorf("written %d bytes over the limit", buf.Len())
+	}
+}
@@ -0,0 +1,316 @@
+package exportfile
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/binary"
+	"fmt"
+	"io"
+)
+
+const (
+	// parquetRowGroupRows、parquetRowGroupBytes 行组的行数及缓存大小上限，达到任一上限即写出行组，控制导出时的内存占用
+	parquetRowGroupRows  = 10000
+	parquetRowGroupBytes = 8 << 20
+
+	parquetMagic = "PAR1"
+)
+
+// parquet 元数据中使用的枚举值，见 parquet-format 的 parquet.thrift
+const (
+	parquetTypeByteArray         = 6
+	parquetRepetitionOptional    = 1
+	parquetConvertedTypeUTF8     = 0
+	parquetEncodingPlain         = 0
+	parquetEncodingRLE           = 3
+	parquetCodecUncompressed     = 0
+	parquetPageTypeDataPage      = 0
+	parquetFileMetaDataVersion   = 1
+	parquetCreatedBy             = "dms data export"
+	parquetMaxDefinitionLevelBit = 1
+)
+
+// parquetWriter 所有列按可为 NULL 的 UTF8 字符串写出，不压缩，每个行组每列一个数据页
+type parquetWriter struct {
+	w       *countingWriter
+	columns []string
+
+	// 当前行组中每列的定义级别（非 NULL 为 1）和 PLAIN 编码的值
+	defLevels [][]bool
+	values    []*bytes.Buffer
+	rows      int
+	size      int
+
+	totalRows int64
+	rowGroups []*parquetRowGroup
+}
+
+type parquetRowGroup struct {
+	columns       []*parquetColumnChunk
+	totalByteSize int64
+	numRows       int64
+}
+
+type parquetColumnChunk struct {
+	offset    int64
+	size      int64
+	numValues int64
+}
+
+func newParquetWriter(w io.Writer, columns []string) (*parquetWriter, error) {
+	p := &parquetWriter{
+		w:         &countingWriter{w: w},
+		columns:   columns,
+		defLevels: make([][]bool, len(columns)),
+		values:    make([]*bytes.Buffer, len(columns)),
+	}
+	for i := range columns {
+		p.values[i] = &bytes.Buffer{}
+	}
+	if _, err := io.WriteString(p.w, parquetMagic); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+func (p *parquetWriter) WriteRow(row []sql.NullString) error {
+	if len(row) != len(p.columns) {
+		return fmt.Errorf("row has %d values, expect %d", len(row), len(p.columns))
+	}
+	for i, v := range row {
+		p.defLevels[i] = append(p.defLevels[i], v.Valid)
+		if !v.Valid {
+			continue
+		}
+		var l [4]byte
+		binary.LittleEndian.PutUint32(l[:], uint32(len(v.String)))
+		p.values[i].Write(l[:])
+		p.values[i].WriteString(v.String)
+		p.size += len(v.String) + 4
+	}
+	p.rows++
+	if p.rows >= parquetRowGroupRows || p.size >= parquetRowGroupBytes {
+		return p.flushRowGroup()
+	}
+	return nil
+}
+
+func (p *parquetWriter) flushRowGroup() error {
+	if p.rows == 0 {
+		return nil
+	}
+	rg := &parquetRowGroup{numRows: int64(p.rows)}
+	for i := range p.columns {
+		chunk, err := p.writeColumnChunk(p.defLevels[i], p.values[i].Bytes())
+		if err != nil {
+			return fmt.Errorf("write parquet column %s failed: %v", p.columns[i], err)
+		}
+		rg.columns = append(rg.columns, chunk)
+		rg.totalByteSize += chunk.size
+		p.defLevels[i] = p.defLevels[i][:0]
+		p.values[i].Reset()
+	}
+	p.rowGroups = append(p.rowGroups, rg)
+	p.totalRows += int64(p.rows)
+	p.rows = 0
+	p.size = 0
+	return nil
+}
+
+func (p *parquetWriter) writeColumnChunk(defLevels []bool, values []byte) (*parquetColumnChunk, error) {
+	levels := encodeParquetDefinitionLevels(defLevels)
+	pageSize := 4 + len(levels) + len(values)
+
+	header := &thriftCompactWriter{}
+	header.i32(1, parquetPageTypeDataPage)
+	header.i32(2, int32(pageSize))
+	header.i32(3, int32(pageSize))
+	header.beginStruct(5)
+	header.i32(1, int32(len(defLevels)))
+	header.i32(2, parquetEncodingPlain)
+	header.i32(3, parquetEncodingRLE)
+	header.i32(4, parquetEncodingRLE)
+	header.endStruct()
+	header.stop()
+
+	chunk := &parquetColumnChunk{offset: p.w.n, numValues: int64(len(defLevels))}
+	var l [4]byte
+	binary.LittleEndian.PutUint32(l[:], uint32(len(levels)))
+	for _, b := range [][]byte{header.bytes(), l[:], levels, values} {
+		if _, err := p.w.Write(b); err != nil {
+			return nil, err
+		}
+	}
+	chunk.size = p.w.n - chunk.offset
+	return chunk, nil
+}
+
+// encodeParquetDefinitionLevels 以 RLE/bit-packing 混合编码中的单个 bit-packed 段编码定义级别
+func encodeParquetDefinitionLevels(levels []bool) []byte {
+	groups := (len(levels) + 7) / 8
+	buf := binary.AppendUvarint(nil, uint64(groups)<<1|1)
+	packed := make([]byte, groups*parquetMaxDefinitionLevelBit)
+	for i, defined := range levels {
+		if defined {
+			packed[i/8] |= 1 << (i % 8)
+		}
+	}
+	return append(buf, packed...)
+}
+
+func (p *parquetWriter) Close() error {
+	if err := p.flushRowGroup(); err != nil {
+		return err
+	}
+
+	meta := &thriftCompactWriter{}
+	meta.i32(1, parquetFileMetaDataVersion)
+	meta.listHeader(2, thriftTypeStruct, len(p.columns)+1)
+	meta.beginListStruct()
+	meta.binary(4, "schema")
+	meta.i32(5, int32(len(p.columns)))
+	meta.endStruct()
+	for _, c := range p.columns {
+		meta.beginListStruct()
+		meta.i32(1, parquetTypeByteArray)
+		meta.i32(3, parquetRepetitionOptional)
+		meta.binary(4, c)
+		meta.i32(6, parquetConvertedTypeUTF8)
+		meta.endStruct()
+	}
+	meta.i64(3, p.totalRows)
+	meta.listHeader(4, thriftTypeStruct, len(p.rowGroups))
+	for _, rg := range p.rowGroups {
+		meta.beginListStruct()
+		meta.listHeader(1, thriftTypeStruct, len(rg.columns))
+		for i, chunk := range rg.columns {
+			meta.beginListStruct()
+			meta.i64(2, chunk.offset)
+			meta.beginStruct(3)
+			meta.i32(1, parquetTypeByteArray)
+			meta.listHeader(2, thriftTypeI32, 2)
+			meta.listI32(parquetEncodingPlain)
+			meta.listI32(parquetEncodingRLE)
+			meta.listHeader(3, thriftTypeBinary, 1)
+			meta.listBinary(p.columns[i])
+			meta.i32(4, parquetCodecUncompressed)
+			meta.i64(5, chunk.numValues)
+			meta.i64(6, chunk.size)
+			meta.i64(7, chunk.size)
+			meta.i64(9, chunk.offset)
+			meta.endStruct()
+			meta.endStruct()
+		}
+		meta.i64(2, rg.totalByteSize)
+		meta.i64(3, rg.numRows)
+		meta.endStruct()
+	}
+	meta.binary(6, parquetCreatedBy)
+	meta.stop()
+
+	footer := meta.bytes()
+	var l [4]byte
+	binary.LittleEndian.PutUint32(l[:], uint32(len(footer)))
+	for _, b := range [][]byte{footer, l[:], []byte(parquetMagic)} {
+		if _, err := p.w.Write(b); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+type countingWriter struct {
+	w io.Writer
+	n int64
+}
+
+func (c *countingWriter) Write(b []byte) (int, error) {
+	n, err := c.w.Write(b)
+	c.n += int64(n)
+	return n, err
+}
+
+const (
+	thriftTypeI32    = 5
+	thriftTypeI64    = 6
+	thriftTypeBinary = 8
+	thriftTypeList   = 9
+	thriftTypeStruct = 12
+)
+
+// thriftCompactWriter 写出 parquet 元数据所需的 thrift compact protocol 子集
+type thriftCompactWriter struct {
+	buf []byte
+	// lastFieldIDs 嵌套结构体中各层上一个字段的 ID，字段头按增量编码
+	lastFieldIDs []int16
+	lastFieldID  int16
+}
+
+func (t *thriftCompactWriter) bytes() []byte {
+	return t.buf
+}
+
+func (t *thriftCompactWriter) fieldHeader(id int16, typ byte) {
+	if delta := id - t.lastFieldID; delta > 0 && delta <= 15 {
+		t.buf = append(t.buf, byte(delta)<<4|typ)
+	} else {
+		t.buf = append(t.buf, typ)
+		t.buf = binary.AppendVarint(t.buf, int64(id))
+	}
+	t.lastFieldID = id
+}
+
+func (t *thriftCompactWriter) i32(id int16, v int32) {
+	t.fieldHeader(id, thriftTypeI32)
+	t.buf = binary.AppendVarint(t.buf, int64(v))
+}
+
+func (t *thriftCompactWriter) i64(id int16, v int64) {
+	t.fieldHeader(id, thriftTypeI64)
+	t.buf = binary.AppendVarint(t.buf, v)
+}
+
+func (t *thriftCompactWriter) binary(id int16, v string) {
+	t.fieldHeader(id, thriftTypeBinary)
+	t.listBinary(v)
+}
+
+func (t *thriftCompactWriter) listHeader(id int16, elemType byte, size int) {
+	t.fieldHeader(id, thriftTypeList)
+	if size < 15 {
+		t.buf = append(t.buf, byte(size)<<4|elemType)
+		return
+	}
+	t.buf = append(t.buf, 0xf0|elemType)
+	t.buf = binary.AppendUvarint(t.buf, uint64(size))
+}
+
+func (t *thriftCompactWriter) listI32(v int32) {
+	t.buf = binary.AppendVarint(t.buf, int64(v))
+}
+
+func (t *thriftCompactWriter) listBinary(v string) {
+	t.buf = binary.AppendUvarint(t.buf, uint64(len(v)))
+	t.buf = append(t.buf, v...)
+}
+
+func (t *thriftCompactWriter) beginStruct(id int16) {
+	t.fieldHeader(id, thriftTypeStruct)
+	t.beginListStruct()
+}
+
+// beginListStruct 开始列表中的结构体元素，元素本身没有字段头
+func (t *thriftCompactWriter) beginListStruct() {
+	t.lastFieldIDs = append(t.lastFieldIDs, t.lastFieldID)
+	t.lastFieldID = 0
+}
+
+func (t *thriftCompactWriter) endStruct() {
+	t.stop()
+	t.lastFieldID = t.lastFieldIDs[len(t.lastFieldIDs)-1]
+	t.lastFieldIDs = t.lastFieldIDs[:len(t.lastFieldIDs)-1]
+}
+
+func (t *thriftCompactWriter) stop() {
+	t.buf = append(t.buf, 0)
+}
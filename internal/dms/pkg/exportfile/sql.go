@@ -0,0 +1,67 @@
+package exportfile
+
+import (
+	"bufio"
+	"database/sql"
+	"io"
+	"strings"
+
+	pkgConst "github.com/actiontech/dms/internal/dms/pkg/constant"
+)
+
+// sqlWriter 每行生成一条 INSERT 语句，用于导入其他环境
+type sqlWriter struct {
+	w       *bufio.Writer
+	prefix  string
+	escaper *strings.Replacer
+}
+
+func newSQLWriter(w io.Writer, table string, columns []string, dbType string) *sqlWriter {
+	quote, escaper := `"`, strings.NewReplacer("'", "''")
+	if isMySQLCompatible(dbType) {
+		quote, escaper = "`", strings.NewReplacer(`\`, `\\`, "'", `\'`, "\x00", `\0`, "\n", `\n`, "\r", `\r`, "\x1a", `\Z`)
+	}
+	quoteIdent := func(name string) string {
+		return quote + strings.ReplaceAll(name, quote, quote+quote) + quote
+	}
+	quoted := make([]string, 0, len(columns))
+	for _, c := range columns {
+		quoted = append(quoted, quoteIdent(c))
+	}
+	return &sqlWriter{
+		w:       bufio.NewWriter(w),
+		prefix:  "INSERT INTO " + quoteIdent(table) + " (" + strings.Join(quoted, ", ") + ") VALUES (",
+		escaper: escaper,
+	}
+}
+
+func isMySQLCompatible(dbType string) bool {
+	switch pkgConst.DBType(dbType) {
+	case pkgConst.DBTypeMySQL, pkgConst.DBTypeTiDB, pkgConst.DBTypeTDSQLForInnoDB, pkgConst.DBTypeOceanBaseMySQL, pkgConst.DBTypeGoldenDB,
+		pkgConst.DBTypeGaussDBForMySQL, pkgConst.DBTypePolarDBForMySQL:
+		return true
+	}
+	return false
+}
+
+func (s *sqlWriter) WriteRow(row []sql.NullString) error {
+	s.w.WriteString(s.prefix)
+	for i, v := range row {
+		if i > 0 {
+			s.w.WriteString(", ")
+		}
+		if !v.Valid {
+			s.w.WriteString("NULL")
+			continue
+		}
+		s.w.WriteByte('\'')
+		s.escaper.WriteString(s.w, v.String)
+		s.w.WriteByte('\'')
+	}
+	_, err := s.w.WriteString(");\n")
+	return err
+}
+
+func (s *sqlWriter) Close() error {
+	return s.w.Flush()
+}
@@ -0,0 +1,300 @@
+package exportfile
+
+import (
+	"archive/zip"
+	"bytes"
+	"crypto/rand"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+	"unicode"
+)
+
+type WatermarkMode string
+
+const (
+	// WatermarkModeMetadata 仅在归档注释中写入每次下载的指纹
+	WatermarkModeMetadata WatermarkMode = "metadata"
+	// WatermarkModeMarkerColumn 每个结果集末尾增加一列写入指纹
+	WatermarkModeMarkerColumn WatermarkMode = "marker_column"
+	// WatermarkModeInvisible 在每行第一个文本值末尾追加零宽字符编码的指纹，复制部分行也可追溯
+	WatermarkModeInvisible WatermarkMode = "invisible"
+)
+
+func ParseWatermarkMode(m string) (WatermarkMode, error) {
+	switch WatermarkMode(m) {
+	case "", WatermarkModeMetadata, WatermarkModeMarkerColumn, WatermarkModeInvisible:
+		return WatermarkMode(m), nil
+	default:
+		return "", fmt.Errorf("unsupported watermark mode: %s", m)
+	}
+}
+
+const (
+	fingerprintLen = 8
+	checksumLen    = 2
+	// MarkerColumn 水印列的列名
+	MarkerColumn = "_dms_row_tag"
+
+	markerPrefix   = "wm"
+	commentPrefix  = "dms-watermark:"
+	invisibleStart = '\u2063'
+)
+
+// invisibleAlphabet 每个零宽字符编码 2 位
+var invisibleAlphabet = []rune{'\u200b', '\u200c', '\u200d', '\u2060'}
+
+var markerPattern = regexp.MustCompile(markerPrefix + `([0-9a-f]{16})([0-9a-f]{4})`)
+
+// NewFingerprint 生成水印指纹
+func NewFingerprint() (string, error) {
+	b := make([]byte, fingerprintLen)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("generate fingerprint failed: %v", err)
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// fingerprintChecksum 校验码用于在追溯时排除偶然匹配的文本
+func fingerprintChecksum(fingerprint []byte) []byte {
+	sum := sha256.Sum256(append([]byte(commentPrefix), fingerprint...))
+	return sum[:checksumLen]
+}
+
+func markerToken(fingerprint []byte) string {
+	return markerPrefix + hex.EncodeToString(fingerprint) + hex.EncodeToString(fingerprintChecksum(fingerprint))
+}
+
+func invisibleToken(fingerprint []byte) string {
+	payload := append(append([]byte{}, fingerprint...), fingerprintChecksum(fingerprint)...)
+	var b strings.Builder
+	b.WriteRune(invisibleStart)
+	for _, c := range payload {
+		for shift := 6; shift >= 0; shift -= 2 {
+			b.WriteRune(invisibleAlphabet[(c>>shift)&0x3])
+		}
+	}
+	return b.String()
+}
+
+// ArchiveComment 写入归档注释的下载指纹
+func ArchiveComment(fingerprint string) (string, error) {
+	b, err := decodeFingerprint(fingerprint)
+	if err != nil {
+		return "", err
+	}
+	return commentPrefix + markerToken(b), nil
+}
+
+func decodeFingerprint(fingerprint string) ([]byte, error) {
+	b, err := hex.DecodeString(fingerprint)
+	if err != nil || len(b) != fingerprintLen {
+		return nil, fmt.Errorf("invalid fingerprint: %s", fingerprint)
+	}
+	return b, nil
+}
+
+// NewWatermarkWriter 在结果中写入指纹，应在脱敏之后写入，即由 NewMaskingWriter 包装，避免水印被脱敏规则改写
+func NewWatermarkWriter(w Writer, mode WatermarkMode, fingerprint string) (Writer, error) {
+	b, err := decodeFingerprint(fingerprint)
+	if err != nil {
+		return nil, err
+	}
+	switch mode {
+	case WatermarkModeMarkerColumn:
+		return &markerColumnWriter{Writer: w, token: markerToken(b)}, nil
+	case WatermarkModeInvisible:
+		return &invisibleWriter{Writer: w, token: invisibleToken(b)}, nil
+	default:
+		return w, nil
+	}
+}
+
+type markerColumnWriter struct {
+	Writer
+	token string
+	row   []sql.NullString
+}
+
+func (m *markerColumnWriter) BeginResult(result *Result) error {
+	r := *result
+	r.Columns = append(append([]string{}, result.Columns...), MarkerColumn)
+	return m.Writer.BeginResult(&r)
+}
+
+func (m *markerColumnWriter) WriteRow(row []sql.NullString) error {
+	m.row = append(append(m.row[:0], row...), sql.NullString{String: m.token, Valid: true})
+	return m.Writer.WriteRow(m.row)
+}
+
+type invisibleWriter struct {
+	Writer
+	token string
+	row   []sql.NullString
+}
+
+func (iw *invisibleWriter) WriteRow(row []sql.NullString) error {
+	iw.row = append(iw.row[:0], row...)
+	for i, v := range iw.row {
+		// 数字、日期等值追加字符后无法导入，只在包含文字的值中写入
+		if v.Valid && strings.IndexFunc(v.String, unicode.IsLetter) >= 0 {
+			iw.row[i].String = v.String + iw.token
+			break
+		}
+	}
+	return iw.Writer.WriteRow(iw.row)
+}
+
+// WithArchiveComment 返回替换了归档注释的 zip 归档内容，用于下载时写入每次下载的指纹而无需重新生成文件；
+// 内容由原归档注释之前的部分与新的注释拼接而成，可按范围读取以支持断点续传
+func WithArchiveComment(src io.ReaderAt, size int64, comment string) (*io.SectionReader, error) {
+	if len(comment) > 0xffff {
+		return nil, fmt.Errorf("archive comment too long")
+	}
+	// 目录结束记录固定 22 字节，之后为最长 65535 字节的注释
+	tailLen := min(size, 22+0xffff)
+	tail := make([]byte, tailLen)
+	if _, err := src.ReadAt(tail, size-tailLen); err != nil && err != io.EOF {
+		return nil, fmt.Errorf("read archive failed: %v", err)
+	}
+	eocd := -1
+	for i := len(tail) - 22; i >= 0; i-- {
+		if binary.LittleEndian.Uint32(tail[i:]) == 0x06054b50 && int(binary.LittleEndian.Uint16(tail[i+20:]))+i+22 == len(tail) {
+			eocd = i
+			break
+		}
+	}
+	if eocd < 0 {
+		return nil, fmt.Errorf("not a zip archive")
+	}
+	commentAt := &archiveWithComment{
+		src:    src,
+		offset: size - tailLen + int64(eocd) + 20,
+		tail:   binary.LittleEndian.AppendUint16(nil, uint16(len(comment))),
+	}
+	commentAt.tail = append(commentAt.tail, comment...)
+	return io.NewSectionReader(commentAt, 0, commentAt.offset+int64(len(commentAt.tail))), nil
+}
+
+// archiveWithComment offset 之前读取原归档，之后为注释长度及注释
+type archiveWithComment struct {
+	src    io.ReaderAt
+	offset int64
+	tail   []byte
+}
+
+func (a *archiveWithComment) ReadAt(p []byte, off int64) (int, error) {
+	n := 0
+	if off < a.offset {
+		head := p[:min(int64(len(p)), a.offset-off)]
+		k, err := a.src.ReadAt(head, off)
+		n += k
+		if k < len(head) {
+			if err == nil || err == io.EOF {
+				err = io.ErrUnexpectedEOF
+			}
+			return n, err
+		}
+		p = p[k:]
+		off += int64(k)
+	}
+	if len(p) == 0 {
+		return n, nil
+	}
+	i := off - a.offset
+	if i >= int64(len(a.tail)) {
+		return n, io.EOF
+	}
+	k := copy(p, a.tail[i:])
+	n += k
+	if k < len(p) {
+		return n, io.EOF
+	}
+	return n, nil
+}
+
+// maxTraceEntrySize 追溯时每个归档内文件读取的最大字节数
+const maxTraceEntrySize = 256 << 20
+
+// TraceFingerprints 从泄露的文件或复制的部分行中提取指纹，data 为 zip 归档时同时读取归档注释和归档内的文件
+func TraceFingerprints(data []byte) []string {
+	found := map[string]struct{}{}
+	var ret []string
+	add := func(fp []byte) {
+		s := hex.EncodeToString(fp)
+		if _, ok := found[s]; !ok {
+			found[s] = struct{}{}
+			ret = append(ret, s)
+		}
+	}
+
+	if zr, err := zip.NewReader(bytes.NewReader(data), int64(len(data))); err == nil {
+		scanFingerprints([]byte(zr.Comment), add)
+		for _, f := range zr.File {
+			rc, err := f.Open()
+			if err != nil {
+				continue
+			}
+			content, _ := io.ReadAll(io.LimitReader(rc, maxTraceEntrySize))
+			rc.Close()
+			scanFingerprints(content, add)
+		}
+		return ret
+	}
+	scanFingerprints(data, add)
+	return ret
+}
+
+func scanFingerprints(data []byte, add func([]byte)) {
+	for _, m := range markerPattern.FindAllSubmatch(data, -1) {
+		fp, _ := hex.DecodeString(string(m[1]))
+		sum, _ := hex.DecodeString(string(m[2]))
+		if bytes.Equal(fingerprintChecksum(fp), sum) {
+			add(fp)
+		}
+	}
+
+	start := []byte(string(invisibleStart))
+	for i := bytes.Index(data, start); i >= 0; {
+		if fp, ok := decodeInvisible(data[i+len(start):]); ok {
+			add(fp)
+		}
+		next := bytes.Index(data[i+len(start):], start)
+		if next < 0 {
+			break
+		}
+		i += len(start) + next
+	}
+}
+
+func decodeInvisible(data []byte) ([]byte, bool) {
+	payload := make([]byte, fingerprintLen+checksumLen)
+	r := bytes.NewReader(data)
+	for i := 0; i < len(payload)*4; i++ {
+		c, _, err := r.ReadRune()
+		if err != nil {
+			return nil, false
+		}
+		v := -1
+		for j, a := range invisibleAlphabet {
+			if c == a {
+				v = j
+				break
+			}
+		}
+		if v < 0 {
+			return nil, false
+		}
+		payload[i/4] = payload[i/4]<<2 | byte(v)
+	}
+	fp := payload[:fingerprintLen]
+	if !bytes.Equal(fingerprintChecksum(fp), payload[fingerprintLen:]) {
+		return nil, false
+	}
+	return fp, true
+}
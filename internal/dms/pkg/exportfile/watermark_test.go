@@ -0,0 +1,120 @@
+package exportfile
+
+import (
+	"bytes"
+	"database/sql"
+	"io"
+	"strings"
+	"testing"
+)
+
+func writeWatermarked(t *testing.T, fileType FileType, mode WatermarkMode, fingerprint string) []byte {
+	t.Helper()
+	buf := &bytes.Buffer{}
+	w, err := NewWriter(fileType, buf, Options{})
+	if err != nil {
+		t.Fatalf("new writer: %v", err)
+	}
+	if w, err = NewWatermarkWriter(w, mode, fingerprint); err != nil {
+		t.Fatalf("new watermark writer: %v", err)
+	}
+	if err := w.BeginResult(&Result{Name: "users", Columns: []string{"id", "name"}}); err != nil {
+		t.Fatalf("begin result: %v", err)
+	}
+	rows := [][]sql.NullString{
+		{{String: "1", Valid: true}, {String: "alice", Valid: true}},
+		{{String: "2", Valid: true}, {}},
+	}
+	for _, row := range rows {
+		if err := w.WriteRow(row); err != nil {
+			t.Fatalf("write row: %v", err)
+		}
+	}
+	if err := w.Close(); err != nil {
+		t.Fatalf("close: %v", err)
+	}
+	return buf.Bytes()
+}
+
+func TestWatermarkTrace(t *testing.T) {
+	fingerprint, err := NewFingerprint()
+	if err != nil {
+		t.Fatalf("new fingerprint: %v", err)
+	}
+	for _, mode := range []WatermarkMode{WatermarkModeMarkerColumn, WatermarkModeInvisible} {
+		for _, fileType := range []FileType{FileTypeCSV, FileTypeJSONL, FileTypeSQL, FileTypeParquet, FileTypeXLSX} {
+			data := writeWatermarked(t, fileType, mode, fingerprint)
+			if got := TraceFingerprints(data); len(got) != 1 || got[0] != fingerprint {
+				t.Errorf("%s %s: trace = %v, want %s", mode, fileType, got, fingerprint)
+			}
+		}
+	}
+
+	// 复制的部分行也能追溯
+	files := readZip(t, writeWatermarked(t, FileTypeCSV, WatermarkModeInvisible, fingerprint))
+	lines := strings.Split(files["users.csv"], "\n")
+	if got := TraceFingerprints([]byte(lines[1])); len(got) != 1 || got[0] != fingerprint {
+		t.Errorf("trace sample row = %v", got)
+	}
+	if strings.Contains(lines[1], "wm") || !strings.HasPrefix(lines[1], "1,alice") {
+		t.Errorf("unexpected watermarked row %q", lines[1])
+	}
+	// 数字值不写入水印
+	if lines[2] != "2," {
+		t.Errorf("unexpected row without text %q", lines[2])
+	}
+
+	if got := TraceFingerprints([]byte("wm0123456789abcdef0000 no watermark")); len(got) != 0 {
+		t.Errorf("trace plain text = %v", got)
+	}
+}
+
+func TestWithArchiveComment(t *testing.T) {
+	data := writeResults(t, FileTypeCSV, Options{}, nil)
+	download, _ := NewFingerprint()
+	comment, err := ArchiveComment(download)
+	if err != nil {
+		t.Fatalf("archive comment: %v", err)
+	}
+
+	r, err := WithArchiveComment(bytes.NewReader(data), int64(len(data)), comment)
+	if err != nil {
+		t.Fatalf("with comment: %v", err)
+	}
+	first, err := io.ReadAll(r)
+	if err != nil {
+		t.Fatalf("read: %v", err)
+	}
+	if int64(len(first)) != r.Size() {
+		t.Fatalf("size = %d, read %d", r.Size(), len(first))
+	}
+	// 按范围读取的内容与完整读取一致，用于断点续传
+	for _, off := range []int64{0, int64(len(first)) - int64(len(comment)) - 3, int64(len(first)) - 5} {
+		part := make([]byte, int64(len(first))-off)
+		if _, err := r.ReadAt(part, off); err != nil {
+			t.Fatalf("read at %d: %v", off, err)
+		}
+		if !bytes.Equal(part, first[off:]) {
+			t.Fatalf("read at %d mismatch", off)
+		}
+	}
+
+	// 已有注释的归档再次下载时替换注释
+	other, _ := NewFingerprint()
+	otherComment, _ := ArchiveComment(other)
+	r, err = WithArchiveComment(bytes.NewReader(first), int64(len(first)), otherComment)
+	if err != nil {
+		t.Fatalf("with comment: %v", err)
+	}
+	second, _ := io.ReadAll(r)
+	if got := TraceFingerprints(second); len(got) != 1 || got[0] != other {
+		t.Errorf("trace = %v, want %s", got, other)
+	}
+	if files := readZip(t, second); files["users.csv"] != readZip(t, data)["users.csv"] {
+		t.Errorf("archive content changed")
+	}
+
+	if _, err := WithArchiveComment(strings.NewReader("not a zip"), 9, comment); err == nil {
+		t.Errorf("non zip should fail")
+	}
+}
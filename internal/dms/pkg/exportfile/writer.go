@@ -0,0 +1,230 @@
+package exportfile
+
+import (
+	"archive/zip"
+	"database/sql"
+	"fmt"
+	"io"
+	"strings"
+)
+
+type FileType string
+
+const (
+	FileTypeCSV     FileType = "CSV"
+	FileTypeXLSX    FileType = "XLSX"
+	FileTypeJSONL   FileType = "JSONL"
+	FileTypeSQL     FileType = "SQL"
+	FileTypeParquet FileType = "PARQUET"
+)
+
+// ParseFileType 未指定时默认导出 CSV，兼容旧版本的 EXCEL
+func ParseFileType(t string) (FileType, error) {
+	switch FileType(strings.ToUpper(t)) {
+	case "", FileTypeCSV:
+		return FileTypeCSV, nil
+	case FileTypeXLSX, "EXCEL":
+		return FileTypeXLSX, nil
+	case FileTypeJSONL:
+		return FileTypeJSONL, nil
+	case FileTypeSQL:
+		return FileTypeSQL, nil
+	case FileTypeParquet:
+		return FileTypeParquet, nil
+	default:
+		return "", fmt.Errorf("unsupported export file type: %s", t)
+	}
+}
+
+// FileExt 导出文件的扩展名，XLSX 每个结果集一个工作表，其他格式每个结果集一个文件并打包为 zip
+func (t FileType) FileExt() string {
+	if t == FileTypeXLSX {
+		return ".xlsx"
+	}
+	return ".zip"
+}
+
+func (t FileType) resultExt() string {
+	switch t {
+	case FileTypeJSONL:
+		return ".jsonl"
+	case FileTypeSQL:
+		return ".sql"
+	case FileTypeParquet:
+		return ".parquet"
+	default:
+		return ".csv"
+	}
+}
+
+// Result 一条导出 SQL 的结果集
+type Result struct {
+	// Name 结果集名称，用作文件名或工作表名
+	Name string
+	// Table 生成 INSERT 语句时的目标表，为空时使用 Name
+	Table   string
+	Columns []string
+}
+
+// Writer 流式写入导出结果，按结果集依次写入，不在内存中保留已写入的行
+type Writer interface {
+	BeginResult(result *Result) error
+	// WriteRow 值为 NULL 时 Valid 为 false
+	WriteRow(row []sql.NullString) error
+	Close() error
+}
+
+type Options struct {
+	// DBType 数据源类型，生成 INSERT 语句时据此选择标识符的引用方式
+	DBType string
+	// MaxRowsPerResult 单个结果集的最大行数，0 表示不限制
+	MaxRowsPerResult int64
+	// MaxFileSize 导出文件的最大字节数，0 表示不限制
+	MaxFileSize int64
+}
+
+// NewWriter 超出 Options 中的限制时写入返回 *LimitError
+func NewWriter(fileType FileType, w io.Writer, opts Options) (Writer, error) {
+	if opts.MaxFileSize > 0 {
+		w = &sizeLimitWriter{w: w, max: opts.MaxFileSize}
+	}
+	var writer Writer
+	switch fileType {
+	case FileTypeXLSX:
+		writer = newXLSXWriter(w)
+	case FileTypeCSV, FileTypeJSONL, FileTypeSQL, FileTypeParquet:
+		writer = &zipWriter{zw: zip.NewWriter(w), fileType: fileType, opts: opts, names: map[string]int{}}
+	default:
+		return nil, fmt.Errorf("unsupported export file type: %s", fileType)
+	}
+	if opts.MaxRowsPerResult > 0 {
+		writer = &rowLimitWriter{Writer: writer, max: opts.MaxRowsPerResult}
+	}
+	return writer, nil
+}
+
+// resultWriter 写入单个结果集文件
+type resultWriter interface {
+	WriteRow(row []sql.NullString) error
+	Close() error
+}
+
+// zipWriter 每个结果集写为 zip 中的一个文件
+type zipWriter struct {
+	zw       *zip.Writer
+	fileType FileType
+	opts     Options
+	names    map[string]int
+	current  resultWriter
+}
+
+func (z *zipWriter) BeginResult(result *Result) error {
+	if err := z.closeCurrent(); err != nil {
+		return err
+	}
+	f, err := z.zw.Create(uniqueName(z.names, sanitizeFileName(result.Name)) + z.fileType.resultExt())
+	if err != nil {
+		return fmt.Errorf("create export file failed: %v", err)
+	}
+	columns := uniqueColumns(result.Columns)
+	switch z.fileType {
+	case FileTypeJSONL:
+		z.current = newJSONLWriter(f, columns)
+	case FileTypeSQL:
+		table := result.Table
+		if table == "" {
+			table = result.Name
+		}
+		z.current = newSQLWriter(f, table, result.Columns, z.opts.DBType)
+	case FileTypeParquet:
+		z.current, err = newParquetWriter(f, columns)
+	default:
+		z.current, err = newCSVWriter(f, result.Columns)
+	}
+	return err
+}
+
+func (z *zipWriter) WriteRow(row []sql.NullString) error {
+	if z.current == nil {
+		return fmt.Errorf("write row before begin result")
+	}
+	return z.current.WriteRow(row)
+}
+
+func (z *zipWriter) closeCurrent() error {
+	if z.current == nil {
+		return nil
+	}
+	err := z.current.Close()
+	z.current = nil
+	return err
+}
+
+func (z *zipWriter) Close() error {
+	if err := z.closeCurrent(); err != nil {
+		return err
+	}
+	return z.zw.Close()
+}
+
+// MaskFunc 脱敏单个非 NULL 的值，column 为列在结果集中的下标
+type MaskFunc func(result *Result, column int, value string) string
+
+// NewMaskingWriter 在写入各格式之前统一脱敏，保证不同格式的导出结果一致
+func NewMaskingWriter(w Writer, mask MaskFunc) Writer {
+	return &maskingWriter{Writer: w, mask: mask}
+}
+
+type maskingWriter struct {
+	Writer
+	mask    MaskFunc
+	current *Result
+	row     []sql.NullString
+}
+
+func (m *maskingWriter) BeginResult(result *Result) error {
+	m.current = result
+	return m.Writer.BeginResult(result)
+}
+
+func (m *maskingWriter) WriteRow(row []sql.NullString) error {
+	m.row = append(m.row[:0], row...)
+	for i, v := range m.row {
+		if v.Valid {
+			m.row[i].String = m.mask(m.current, i, v.String)
+		}
+	}
+	return m.Writer.WriteRow(m.row)
+}
+
+// uniqueColumns 多表关联查询可能出现同名列，JSON 和 Parquet 要求列名唯一
+func uniqueColumns(columns []string) []string {
+	seen := make(map[string]int, len(columns))
+	ret := make([]string, 0, len(columns))
+	for _, c := range columns {
+		ret = append(ret, uniqueName(seen, c))
+	}
+	return ret
+}
+
+func uniqueName(seen map[string]int, name string) string {
+	seen[name]++
+	if n := seen[name]; n > 1 {
+		return fmt.Sprintf("%s_%d", name, n)
+	}
+	return name
+}
+
+func sanitizeFileName(name string) string {
+	name = strings.Map(func(r rune) rune {
+		switch r {
+		case '/', '\\', ':', '*', '?', '"', '<', '>', '|':
+			return '_'
+		}
+		return r
+	}, strings.TrimSpace(name))
+	if name == "" {
+		return "result"
+	}
+	return name
+}
@@ -0,0 +1,215 @@
+package exportfile
+
+import (
+	"archive/zip"
+	"bytes"
+	"database/sql"
+	"encoding/binary"
+	"io"
+	"strings"
+	"testing"
+)
+
+func writeResults(t *testing.T, fileType FileType, opts Options, mask MaskFunc) []byte {
+	t.Helper()
+	buf := &bytes.Buffer{}
+	w, err := NewWriter(fileType, buf, opts)
+	if err != nil {
+		t.Fatalf("new writer: %v", err)
+	}
+	if mask != nil {
+		w = NewMaskingWriter(w, mask)
+	}
+	results := []struct {
+		result *Result
+		rows   [][]sql.NullString
+	}{
+		{
+			result: &Result{Name: "users", Table: "users", Columns: []string{"id", "name", "id"}},
+			rows: [][]sql.NullString{
+				{{String: "1", Valid: true}, {String: "O'Brien\n<a&b>", Valid: true}, {String: "1", Valid: true}},
+				{{String: "2", Valid: true}, {}, {String: "2", Valid: true}},
+			},
+		},
+		{
+			result: &Result{Name: "users", Columns: []string{"phone"}},
+			rows:   [][]sql.NullString{{{String: "13800000000", Valid: true}}},
+		},
+	}
+	for _, r := range results {
+		if err := w.BeginResult(r.result); err != nil {
+			t.Fatalf("begin result: %v", err)
+		}
+		for _, row := range r.rows {
+			if err := w.WriteRow(row); err != nil {
+				t.Fatalf("write row: %v", err)
+			}
+		}
+	}
+	if err := w.Close(); err != nil {
+		t.Fatalf("close: %v", err)
+	}
+	return buf.Bytes()
+}
+
+func readZip(t *testing.T, data []byte) map[string]string {
+	t.Helper()
+	zr, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		t.Fatalf("open zip: %v", err)
+	}
+	files := make(map[string]string)
+	for _, f := range zr.File {
+		rc, err := f.Open()
+		if err != nil {
+			t.Fatalf("open %s: %v", f.Name, err)
+		}
+		b, err := io.ReadAll(rc)
+		rc.Close()
+		if err != nil {
+			t.Fatalf("read %s: %v", f.Name, err)
+		}
+		files[f.Name] = string(b)
+	}
+	return files
+}
+
+func TestParseFileType(t *testing.T) {
+	cases := map[string]FileType{"": FileTypeCSV, "csv": FileTypeCSV, "EXCEL": FileTypeXLSX, "xlsx": FileTypeXLSX, "jsonl": FileTypeJSONL, "SQL": FileTypeSQL, "parquet": FileTypeParquet}
+	for in, want := range cases {
+		if got, err := ParseFileType(in); err != nil || got != want {
+			t.Errorf("ParseFileType(%q) = %v, %v, want %v", in, got, err, want)
+		}
+	}
+	if _, err := ParseFileType("PDF"); err == nil {
+		t.Errorf("ParseFileType(PDF) should fail")
+	}
+}
+
+func TestWriteCSV(t *testing.T) {
+	files := readZip(t, writeResults(t, FileTypeCSV, Options{}, nil))
+	want := map[string]string{
+		"users.csv":   "\xEF\xBB\xBFid,name,id\n1,\"O'Brien\n<a&b>\",1\n2,,2\n",
+		"users_2.csv": "\xEF\xBB\xBFphone\n13800000000\n",
+	}
+	for name, content := range want {
+		if files[name] != content {
+			t.Errorf("%s = %q, want %q", name, files[name], content)
+		}
+	}
+}
+
+func TestWriteJSONL(t *testing.T) {
+	files := readZip(t, writeResults(t, FileTypeJSONL, Options{}, nil))
+	want := `{"id":"1","name":"O'Brien\n<a&b>","id_2":"1"}` + "\n" + `{"id":"2","name":null,"id_2":"2"}` + "\n"
+	if files["users.jsonl"] != want {
+		t.Errorf("users.jsonl = %q, want %q", files["users.jsonl"], want)
+	}
+}
+
+func TestWriteSQL(t *testing.T) {
+	files := readZip(t, writeResults(t, FileTypeSQL, Options{DBType: "MySQL"}, nil))
+	want := "INSERT INTO `users` (`id`, `name`, `id`) VALUES ('1', 'O\\'Brien\\n<a&b>', '1');\n" +
+		"INSERT INTO `users` (`id`, `name`, `id`) VALUES ('2', NULL, '2');\n"
+	if files["users.sql"] != want {
+		t.Errorf("users.sql = %q, want %q", files["users.sql"], want)
+	}
+
+	files = readZip(t, writeResults(t, FileTypeSQL, Options{DBType: "PostgreSQL"}, nil))
+	if !strings.HasPrefix(files["users.sql"], `INSERT INTO "users" ("id", "name", "id") VALUES ('1', 'O''Brien`) {
+		t.Errorf("users.sql = %q", files["users.sql"])
+	}
+	if !strings.HasPrefix(files["users_2.sql"], `INSERT INTO "users" ("phone") VALUES ('13800000000');`) {
+		t.Errorf("users_2.sql = %q", files["users_2.sql"])
+	}
+}
+
+func TestWriteXLSX(t *testing.T) {
+	files := readZip(t, writeResults(t, FileTypeXLSX, Options{}, nil))
+	for _, name := range []string{"[Content_Types].xml", "_rels/.rels", "xl/workbook.xml", "xl/_rels/workbook.xml.rels", "xl/worksheets/sheet1.xml", "xl/worksheets/sheet2.xml"} {
+		if _, ok := files[name]; !ok {
+			t.Fatalf("missing %s", name)
+		}
+	}
+	if !strings.Contains(files["xl/workbook.xml"], `<sheet name="users" sheetId="1" r:id="rId1"/><sheet name="users_2" sheetId="2" r:id="rId2"/>`) {
+		t.Errorf("workbook.xml = %s", files["xl/workbook.xml"])
+	}
+	sheet := files["xl/worksheets/sheet1.xml"]
+	for _, s := range []string{
+		`<c r="B2" t="inlineStr"><is><t xml:space="preserve">O&#39;Brien&#xA;&lt;a&amp;b&gt;</t></is></c>`,
+		// NULL 不输出单元格
+		`<row r="3"><c r="A3" t="inlineStr"><is><t xml:space="preserve">2</t></is></c><c r="C3"`,
+	} {
+		if !strings.Contains(sheet, s) {
+			t.Errorf("sheet1.xml does not contain %s: %s", s, sheet)
+		}
+	}
+}
+
+func TestXLSXSheetName(t *testing.T) {
+	x := newXLSXWriter(io.Discard)
+	for in, want := range map[string]string{
+		"a/b:c":                                "a_b_c",
+		"":                                     "Sheet",
+		"select * from a_very_long_table_name": "select _ from a_very_long_table",
+	} {
+		if got := x.sheetName(in); got != want {
+			t.Errorf("sheetName(%q) = %q, want %q", in, got, want)
+		}
+	}
+	if got := x.sheetName("SHEET"); got != "SHEET_2" {
+		t.Errorf("sheetName(SHEET) = %q, want SHEET_2", got)
+	}
+	if got := xlsxColumnName(27); got != "AB" {
+		t.Errorf("xlsxColumnName(27) = %q, want AB", got)
+	}
+}
+
+func TestWriteParquet(t *testing.T) {
+	files := readZip(t, writeResults(t, FileTypeParquet, Options{}, nil))
+	data := []byte(files["users.parquet"])
+	if !strings.HasPrefix(string(data), parquetMagic) || !strings.HasSuffix(string(data), parquetMagic) {
+		t.Fatalf("invalid parquet magic")
+	}
+	footerLen := int(binary.LittleEndian.Uint32(data[len(data)-8:]))
+	footer := string(data[len(data)-8-footerLen : len(data)-8])
+	for _, s := range []string{"schema", "id", "name", "id_2", parquetCreatedBy} {
+		if !strings.Contains(footer, s) {
+			t.Errorf("footer does not contain %s", s)
+		}
+	}
+	// 三列各一个数据页，NULL 值不写入数据页
+	if strings.Count(string(data), "O'Brien") != 1 || strings.Count(string(data), "\x01\x00\x00\x002") != 2 {
+		t.Errorf("unexpected parquet data pages: %q", data)
+	}
+}
+
+func TestParquetDefinitionLevels(t *testing.T) {
+	got := encodeParquetDefinitionLevels([]bool{true, false, true, true, false, false, false, false, true})
+	want := []byte{2<<1 | 1, 0b00001101, 0b00000001}
+	if !bytes.Equal(got, want) {
+		t.Errorf("encodeParquetDefinitionLevels = %08b, want %08b", got, want)
+	}
+}
+
+func TestMaskingWriter(t *testing.T) {
+	mask := func(result *Result, column int, value string) string {
+		if result.Columns[column] == "phone" {
+			return value[:3] + "****" + value[7:]
+		}
+		return value
+	}
+	for _, fileType := range []FileType{FileTypeCSV, FileTypeJSONL, FileTypeSQL, FileTypeParquet, FileTypeXLSX} {
+		data := writeResults(t, fileType, Options{}, mask)
+		for name, content := range readZip(t, data) {
+			if strings.Contains(content, "13800000000") {
+				t.Errorf("%s %s contains unmasked value", fileType, name)
+			}
+			if strings.Contains(name, "users_2") || name == "xl/worksheets/sheet2.xml" {
+				if !strings.Contains(content, "138****0000") {
+					t.Errorf("%s %s does not contain masked value", fileType, name)
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,224 @@
+package exportfile
+
+import (
+	"archive/zip"
+	"bufio"
+	"database/sql"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+const (
+	// xlsxMaxRows Excel 单个工作表的最大行数，超出时续写到新的工作表
+	xlsxMaxRows = 1048576
+	// xlsxMaxSheetNameLen Excel 工作表名称的最大长度
+	xlsxMaxSheetNameLen = 31
+	// xlsxMaxCellLen Excel 单元格的最大字符数
+	xlsxMaxCellLen = 32767
+)
+
+// xlsxWriter 按结果集依次写入工作表，每个工作表写完后不再保留在内存中
+type xlsxWriter struct {
+	zw     *zip.Writer
+	sheets []string
+	names  map[string]int
+
+	result  *Result
+	part    int
+	sheet   *bufio.Writer
+	rows    int
+	columns []string
+}
+
+func newXLSXWriter(w io.Writer) *xlsxWriter {
+	return &xlsxWriter{zw: zip.NewWriter(w), names: map[string]int{}}
+}
+
+func (x *xlsxWriter) BeginResult(result *Result) error {
+	if err := x.closeSheet(); err != nil {
+		return err
+	}
+	x.result = result
+	x.part = 0
+	x.columns = make([]string, len(result.Columns))
+	for i := range result.Columns {
+		x.columns[i] = xlsxColumnName(i)
+	}
+	return x.beginSheet()
+}
+
+func (x *xlsxWriter) beginSheet() error {
+	x.part++
+	name := x.result.Name
+	if x.part > 1 {
+		name = fmt.Sprintf("%s (%d)", name, x.part)
+	}
+	x.sheets = append(x.sheets, x.sheetName(name))
+	f, err := x.zw.Create(fmt.Sprintf("xl/worksheets/sheet%d.xml", len(x.sheets)))
+	if err != nil {
+		return fmt.Errorf("create xlsx sheet failed: %v", err)
+	}
+	x.sheet = bufio.NewWriter(f)
+	x.rows = 0
+	x.sheet.WriteString(xml.Header)
+	x.sheet.WriteString(`<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main"><sheetData>`)
+
+	header := make([]sql.NullString, 0, len(x.result.Columns))
+	for _, c := range x.result.Columns {
+		header = append(header, sql.NullString{String: c, Valid: true})
+	}
+	return x.writeRow(header)
+}
+
+func (x *xlsxWriter) WriteRow(row []sql.NullString) error {
+	if x.sheet == nil {
+		return fmt.Errorf("write row before begin result")
+	}
+	if x.rows >= xlsxMaxRows {
+		if err := x.closeSheet(); err != nil {
+			return err
+		}
+		if err := x.beginSheet(); err != nil {
+			return err
+		}
+	}
+	return x.writeRow(row)
+}
+
+func (x *xlsxWriter) writeRow(row []sql.NullString) error {
+	x.rows++
+	r := strconv.Itoa(x.rows)
+	x.sheet.WriteString(`<row r="` + r + `">`)
+	for i, v := range row {
+		// NULL 输出为空单元格
+		if !v.Valid {
+			continue
+		}
+		value := v.String
+		if len(value) > xlsxMaxCellLen && utf8.RuneCountInString(value) > xlsxMaxCellLen {
+			value = string([]rune(value)[:xlsxMaxCellLen])
+		}
+		x.sheet.WriteString(`<c r="` + x.columns[i] + r + `" t="inlineStr"><is><t xml:space="preserve">`)
+		if err := xml.EscapeText(x.sheet, []byte(value)); err != nil {
+			return err
+		}
+		x.sheet.WriteString(`</t></is></c>`)
+	}
+	_, err := x.sheet.WriteString(`</row>`)
+	return err
+}
+
+func (x *xlsxWriter) closeSheet() error {
+	if x.sheet == nil {
+		return nil
+	}
+	x.sheet.WriteString(`</sheetData></worksheet>`)
+	err := x.sheet.Flush()
+	x.sheet = nil
+	return err
+}
+
+// sheetName 去除 Excel 不允许的字符并截断，重名时追加序号
+func (x *xlsxWriter) sheetName(name string) string {
+	name = strings.Map(func(r rune) rune {
+		switch r {
+		case '[', ']', ':', '*', '?', '/', '\\':
+			return '_'
+		}
+		return r
+	}, strings.Trim(strings.TrimSpace(name), "'"))
+	if name == "" {
+		name = "Sheet"
+	}
+	truncate := func(s string, n int) string {
+		if runes := []rune(s); len(runes) > n {
+			return string(runes[:n])
+		}
+		return s
+	}
+	name = truncate(name, xlsxMaxSheetNameLen)
+	// Excel 工作表名称不区分大小写
+	for {
+		key := strings.ToLower(name)
+		x.names[key]++
+		n := x.names[key]
+		if n == 1 {
+			return name
+		}
+		suffix := fmt.Sprintf("_%d", n)
+		candidate := truncate(name, xlsxMaxSheetNameLen-len(suffix)) + suffix
+		if _, ok := x.names[strings.ToLower(candidate)]; !ok {
+			x.names[strings.ToLower(candidate)]++
+			return candidate
+		}
+	}
+}
+
+func (x *xlsxWriter) Close() error {
+	if err := x.closeSheet(); err != nil {
+		return err
+	}
+	// 没有结果集时保留一个空工作表，Excel 不能打开没有工作表的文件
+	if len(x.sheets) == 0 {
+		if err := x.BeginResult(&Result{Name: "Sheet1"}); err != nil {
+			return err
+		}
+		if err := x.closeSheet(); err != nil {
+			return err
+		}
+	}
+
+	var contentTypes, workbook, workbookRels strings.Builder
+	contentTypes.WriteString(xml.Header + `<Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types">` +
+		`<Default Extension="rels" ContentType="application/vnd.openxmlformats-package.relationships+xml"/>` +
+		`<Default Extension="xml" ContentType="application/xml"/>` +
+		`<Override PartName="/xl/workbook.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.sheet.main+xml"/>`)
+	workbook.WriteString(xml.Header + `<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" ` +
+		`xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships"><sheets>`)
+	workbookRels.WriteString(xml.Header + `<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">`)
+	for i, name := range x.sheets {
+		id := i + 1
+		fmt.Fprintf(&contentTypes, `<Override PartName="/xl/worksheets/sheet%d.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.worksheet+xml"/>`, id)
+		workbook.WriteString(`<sheet name="`)
+		if err := xml.EscapeText(&workbook, []byte(name)); err != nil {
+			return err
+		}
+		fmt.Fprintf(&workbook, `" sheetId="%d" r:id="rId%d"/>`, id, id)
+		fmt.Fprintf(&workbookRels, `<Relationship Id="rId%d" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/worksheet" Target="worksheets/sheet%d.xml"/>`, id, id)
+	}
+	contentTypes.WriteString(`</Types>`)
+	workbook.WriteString(`</sheets></workbook>`)
+	workbookRels.WriteString(`</Relationships>`)
+
+	parts := []struct{ name, content string }{
+		{"[Content_Types].xml", contentTypes.String()},
+		{"_rels/.rels", xml.Header + `<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+			`<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/officeDocument" Target="xl/workbook.xml"/>` +
+			`</Relationships>`},
+		{"xl/workbook.xml", workbook.String()},
+		{"xl/_rels/workbook.xml.rels", workbookRels.String()},
+	}
+	for _, part := range parts {
+		f, err := x.zw.Create(part.name)
+		if err != nil {
+			return fmt.Errorf("create xlsx part %s failed: %v", part.name, err)
+		}
+		if _, err := io.WriteString(f, part.content); err != nil {
+			return fmt.Errorf("write xlsx part %s failed: %v", part.name, err)
+		}
+	}
+	return x.zw.Close()
+}
+
+// xlsxColumnName 列下标转换为 Excel 列名，如 0 -> A，26 -> AA
+func xlsxColumnName(i int) string {
+	name := ""
+	for i++; i > 0; i = (i - 1) / 26 {
+		name = string(rune('A'+(i-1)%26)) + name
+	}
+	return name
+}
@@ -0,0 +1,214 @@
+package exportpreview
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var ErrNotSelect = errors.New("only select statements can be previewed")
+
+// Result 导出 SQL 的预览结果
+type Result struct {
+	Columns []string
+	// Rows 样例行，值为 NULL 时为 nil
+	Rows [][]any
+	// EstimatedRows 按执行计划估算的结果行数，无法估算时为 0
+	EstimatedRows int64
+	// EstimatedBytes 按样例行的平均大小估算的导出数据量
+	EstimatedBytes int64
+}
+
+// SampleSQL 以子查询包装导出 SQL 并限制行数，非查询语句放在子查询中无法执行
+func SampleSQL(query string, limit int) (string, error) {
+	q := trimStatement(query)
+	if !isSelect(q) {
+		return "", ErrNotSelect
+	}
+	// 换行避免导出 SQL 末尾的单行注释注释掉外层语句
+	return fmt.Sprintf("SELECT * FROM (\n%s\n) AS dms_preview LIMIT %d", q, limit), nil
+}
+
+func trimStatement(query string) string {
+	return strings.TrimSpace(strings.TrimRight(strings.TrimSpace(query), ";"))
+}
+
+func isSelect(query string) bool {
+	upper := strings.ToUpper(query)
+	return strings.HasPrefix(upper, "SELECT") || strings.HasPrefix(upper, "WITH") || strings.HasPrefix(upper, "(")
+}
+
+// queryer 预览在只读事务中执行，EXPLAIN 与取样共用同一事务
+type queryer interface {
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+}
+
+// StatementTimeoutSQL 返回限制当前会话单条语句执行时间的语句，OceanBase 使用 ob_query_timeout（微秒），
+// MySQL 及 TiDB 使用 max_execution_time（毫秒）
+func StatementTimeoutSQL(oceanBase bool, timeout time.Duration) string {
+	if oceanBase {
+		return fmt.Sprintf("SET SESSION ob_query_timeout = %d", timeout.Microseconds())
+	}
+	return fmt.Sprintf("SET SESSION max_execution_time = %d", timeout.Milliseconds())
+}
+
+// Preview 在 MySQL 协议的数据源上取样例行并按执行计划估算数据量，db 不应允许一次执行多条语句。
+// 导出 SQL 未经审批，在同一连接上先执行 timeoutSQL 限制服务端执行时间，再在只读事务中执行，结束后回滚
+func Preview(ctx context.Context, db *sql.DB, timeoutSQL, query string, sampleRows int) (*Result, error) {
+	sampleSQL, err := SampleSQL(query, sampleRows)
+	if err != nil {
+		return nil, err
+	}
+	conn, err := db.Conn(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("get connection failed: %v", err)
+	}
+	defer conn.Close()
+	if _, err := conn.ExecContext(ctx, timeoutSQL); err != nil {
+		return nil, fmt.Errorf("set statement timeout failed: %v", err)
+	}
+	tx, err := conn.BeginTx(ctx, &sql.TxOptions{ReadOnly: true})
+	if err != nil {
+		return nil, fmt.Errorf("start read only transaction failed: %v", err)
+	}
+	defer tx.Rollback() //nolint:errcheck
+	return preview(ctx, tx, sampleSQL, query, sampleRows)
+}
+
+func preview(ctx context.Context, db queryer, sampleSQL, query string, sampleRows int) (*Result, error) {
+	rows, err := db.QueryContext(ctx, sampleSQL)
+	if err != nil {
+		return nil, fmt.Errorf("query sample rows failed: %v", err)
+	}
+	defer rows.Close()
+	columns, err := rows.Columns()
+	if err != nil {
+		return nil, fmt.Errorf("get columns failed: %v", err)
+	}
+
+	result := &Result{Columns: columns}
+	var sampleBytes int64
+	values := make([]sql.NullString, len(columns))
+	dest := make([]any, len(columns))
+	for i := range values {
+		dest[i] = &values[i]
+	}
+	for rows.Next() {
+		if err := rows.Scan(dest...); err != nil {
+			return nil, fmt.Errorf("scan sample row failed: %v", err)
+		}
+		row := make([]any, len(columns))
+		for i, v := range values {
+			if v.Valid {
+				row[i] = v.String
+				sampleBytes += int64(len(v.String))
+			}
+		}
+		result.Rows = append(result.Rows, row)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("read sample rows failed: %v", err)
+	}
+
+	// 样例未取满时结果已完整，以实际行数为准
+	if len(result.Rows) < sampleRows {
+		result.EstimatedRows = int64(len(result.Rows))
+		result.EstimatedBytes = sampleBytes
+		return result, nil
+	}
+	// 先关闭样例结果集，同一事务上才能执行 EXPLAIN
+	rows.Close()
+	result.EstimatedRows, err = estimateRows(ctx, db, query)
+	if err != nil {
+		return nil, err
+	}
+	if len(result.Rows) > 0 {
+		result.EstimatedBytes = sampleBytes / int64(len(result.Rows)) * result.EstimatedRows
+	}
+	return result, nil
+}
+
+// estimateRows 通过 EXPLAIN 估算导出 SQL 的结果行数
+func estimateRows(ctx context.Context, db queryer, query string) (int64, error) {
+	rows, err := db.QueryContext(ctx, "EXPLAIN "+trimStatement(query))
+	if err != nil {
+		return 0, fmt.Errorf("explain failed: %v", err)
+	}
+	defer rows.Close()
+	columns, err := rows.Columns()
+	if err != nil {
+		return 0, fmt.Errorf("get explain columns failed: %v", err)
+	}
+	var plan [][]sql.NullString
+	for rows.Next() {
+		values := make([]sql.NullString, len(columns))
+		dest := make([]any, len(columns))
+		for i := range values {
+			dest[i] = &values[i]
+		}
+		if err := rows.Scan(dest...); err != nil {
+			return 0, fmt.Errorf("scan explain row failed: %v", err)
+		}
+		plan = append(plan, values)
+	}
+	if err := rows.Err(); err != nil {
+		return 0, fmt.Errorf("read explain rows failed: %v", err)
+	}
+	return EstimateRowsFromPlan(columns, plan), nil
+}
+
+// EstimateRowsFromPlan 从执行计划估算结果行数：TiDB 取根算子的 estRows；
+// MySQL 将最外层查询（id 为 1）各表的 rows × filtered% 相乘；无法识别的执行计划返回 0
+func EstimateRowsFromPlan(columns []string, plan [][]sql.NullString) int64 {
+	index := make(map[string]int, len(columns))
+	for i, c := range columns {
+		index[strings.ToLower(c)] = i
+	}
+	if i, ok := index["estrows"]; ok {
+		if len(plan) == 0 {
+			return 0
+		}
+		return int64(math.Ceil(parseFloat(plan[0][i], 0)))
+	}
+
+	rowsIdx, ok := index["rows"]
+	if !ok {
+		return 0
+	}
+	idIdx, hasId := index["id"]
+	filteredIdx, hasFiltered := index["filtered"]
+	estimated, found := 1.0, false
+	for _, row := range plan {
+		if hasId && row[idIdx].Valid && row[idIdx].String != "1" {
+			continue
+		}
+		if !row[rowsIdx].Valid {
+			continue
+		}
+		found = true
+		estimated *= parseFloat(row[rowsIdx], 0)
+		if hasFiltered {
+			estimated *= parseFloat(row[filteredIdx], 100) / 100
+		}
+	}
+	if !found {
+		return 0
+	}
+	return int64(math.Ceil(estimated))
+}
+
+func parseFloat(v sql.NullString, def float64) float64 {
+	if !v.Valid {
+		return def
+	}
+	f, err := strconv.ParseFloat(v.String, 64)
+	if err != nil {
+		return def
+	}
+	return f
+}
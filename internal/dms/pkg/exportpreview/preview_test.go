@@ -0,0 +1,56 @@
+package exportpreview
+
+import (
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSampleSQL(t *testing.T) {
+	q, err := SampleSQL(" select * from t1 where id > 1; ", 10)
+	assert.NoError(t, err)
+	assert.Equal(t, "SELECT * FROM (\nselect * from t1 where id > 1\n) AS dms_preview LIMIT 10", q)
+
+	q, err = SampleSQL("SELECT * FROM t1 -- comment", 5)
+	assert.NoError(t, err)
+	assert.Equal(t, "SELECT * FROM (\nSELECT * FROM t1 -- comment\n) AS dms_preview LIMIT 5", q)
+
+	_, err = SampleSQL("delete from t1", 10)
+	assert.ErrorIs(t, err, ErrNotSelect)
+}
+
+func nullStrings(values ...string) []sql.NullString {
+	ret := make([]sql.NullString, len(values))
+	for i, v := range values {
+		ret[i] = sql.NullString{String: v, Valid: v != "NULL"}
+	}
+	return ret
+}
+
+func TestEstimateRowsFromPlan(t *testing.T) {
+	mysqlColumns := []string{"id", "select_type", "table", "type", "rows", "filtered", "Extra"}
+	assert.EqualValues(t, 500, EstimateRowsFromPlan(mysqlColumns, [][]sql.NullString{
+		nullStrings("1", "SIMPLE", "t1", "ALL", "1000", "50.00", "Using where"),
+	}))
+	// 关联查询各表的行数相乘，子查询不计入
+	assert.EqualValues(t, 2000, EstimateRowsFromPlan(mysqlColumns, [][]sql.NullString{
+		nullStrings("1", "PRIMARY", "t1", "ALL", "1000", "100.00", ""),
+		nullStrings("1", "PRIMARY", "t2", "ref", "2", "NULL", ""),
+		nullStrings("2", "SUBQUERY", "t3", "ALL", "30", "100.00", ""),
+	}))
+
+	tidbColumns := []string{"id", "estRows", "task", "access object", "operator info"}
+	assert.EqualValues(t, 3334, EstimateRowsFromPlan(tidbColumns, [][]sql.NullString{
+		nullStrings("TableReader_7", "3333.33", "root", "", "data:Selection_6"),
+		nullStrings("└─Selection_6", "3333.33", "cop[tikv]", "", "gt(test.t1.id, 1)"),
+	}))
+
+	assert.EqualValues(t, 0, EstimateRowsFromPlan([]string{"Query Plan"}, [][]sql.NullString{nullStrings("...")}))
+}
+
+func TestStatementTimeoutSQL(t *testing.T) {
+	assert.Equal(t, "SET SESSION max_execution_time = 30000", StatementTimeoutSQL(false, 30*time.Second))
+	assert.Equal(t, "SET SESSION ob_query_timeout = 30000000", StatementTimeoutSQL(true, 30*time.Second))
+}
@@ -0,0 +1,245 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	dmsV1 "github.com/actiontech/dms/api/dms/service/v1"
+	"github.com/actiontech/dms/internal/dms/biz"
+)
+
+func (d *DMSService) AddAccessReviewCampaign(ctx context.Context, currentUserUid string, req *dmsV1.AddAccessReviewCampaignReq) (reply *dmsV1.AddAccessReviewCampaignReply, err error) {
+	d.log.Infof("AddAccessReviewCampaign.req=%v", req)
+	defer func() {
+		d.log.Infof("AddAccessReviewCampaign.req=%v;reply=%v;error=%v", req, reply, err)
+	}()
+
+	uid, err := d.AccessReviewUsecase.CreateAccessReviewCampaign(ctx, currentUserUid, &biz.CreateAccessReviewCampaignArgs{
+		Name:        req.Campaign.Name,
+		ProjectUIDs: req.Campaign.ProjectUids,
+		RoleUIDs:    req.Campaign.RoleUids,
+		Deadline:    req.Campaign.Deadline,
+		AutoRevoke:  req.Campaign.AutoRevoke,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("create access review campaign failed: %w", err)
+	}
+
+	reply = &dmsV1.AddAccessReviewCampaignReply{}
+	reply.Data.Uid = uid
+	return reply, nil
+}
+
+func (d *DMSService) ListAccessReviewCampaigns(ctx context.Context, currentUserUid string) (*dmsV1.ListAccessReviewCampaignsReply, error) {
+	campaigns, err := d.AccessReviewUsecase.ListAccessReviewCampaigns(ctx, currentUserUid)
+	if err != nil {
+		return nil, fmt.Errorf("list access review campaigns failed: %w", err)
+	}
+
+	ret := make([]*dmsV1.AccessReviewCampaign, 0, len(campaigns))
+	for _, campaign := range campaigns {
+		ret = append(ret, d.convertBizAccessReviewCampaign(ctx, campaign))
+	}
+	return &dmsV1.ListAccessReviewCampaignsReply{
+		Data:  ret,
+		Total: int64(len(ret)),
+	}, nil
+}
+
+func (d *DMSService) GetAccessReviewCampaign(ctx context.Context, currentUserUid string, req *dmsV1.GetAccessReviewCampaignReq) (*dmsV1.GetAccessReviewCampaignReply, error) {
+	campaign, items, err := d.AccessReviewUsecase.GetAccessReviewCampaign(ctx, currentUserUid, req.CampaignUid)
+	if err != nil {
+		return nil, fmt.Errorf("get access review campaign failed: %w", err)
+	}
+
+	reply := &dmsV1.GetAccessReviewCampaignReply{}
+	reply.Data.Campaign = d.convertBizAccessReviewCampaign(ctx, campaign)
+	reply.Data.Items = d.convertBizAccessReviewItems(ctx, items)
+	return reply, nil
+}
+
+func (d *DMSService) ReviewAccessReviewItem(ctx context.Context, currentUserUid string, req *dmsV1.ReviewAccessReviewItemReq) (err error) {
+	d.log.Infof("ReviewAccessReviewItem.req=%v", req)
+	defer func() {
+		d.log.Infof("ReviewAccessReviewItem.req=%v;error=%v", req, err)
+	}()
+
+	if err := d.AccessReviewUsecase.ReviewAccessReviewItem(ctx, currentUserUid, req.CampaignUid, req.ItemUid, biz.AccessReviewDecision(req.Decision), req.Comment); err != nil {
+		return fmt.Errorf("review access review item failed: %w", err)
+	}
+	return nil
+}
+
+// ExportAccessReviewEvidence 导出已结束活动的复核证据，返回文件内容及文件名
+func (d *DMSService) ExportAccessReviewEvidence(ctx context.Context, currentUserUid string, req *dmsV1.ExportAccessReviewEvidenceReq) ([]byte, string, error) {
+	campaign, items, err := d.AccessReviewUsecase.GetAccessReviewEvidence(ctx, currentUserUid, req.CampaignUid)
+	if err != nil {
+		return nil, "", fmt.Errorf("get access review evidence failed: %w", err)
+	}
+
+	evidence := &dmsV1.AccessReviewEvidence{
+		Campaign:    d.convertBizAccessReviewCampaign(ctx, campaign),
+		Items:       d.convertBizAccessReviewItems(ctx, items),
+		GeneratedAt: time.Now(),
+	}
+	for _, item := range evidence.Items {
+		evidence.Summary.Total++
+		switch item.Decision {
+		case dmsV1.AccessReviewDecisionApprove:
+			evidence.Summary.Approved++
+		case dmsV1.AccessReviewDecisionRevoke:
+			evidence.Summary.Revoked++
+		case dmsV1.AccessReviewDecisionAutoRevoke:
+			evidence.Summary.AutoRevoked++
+		}
+	}
+
+	fileName := fmt.Sprintf("access_review_%s_%s", campaign.Name, campaign.CompletedAt.Format("20060102150405"))
+	switch req.Format {
+	case dmsV1.AccessReviewEvidenceFormatJSON:
+		content, err := json.MarshalIndent(evidence, "", "  ")
+		if err != nil {
+			return nil, "", fmt.Errorf("marshal access review evidence failed: %v", err)
+		}
+		return content, fileName + ".json", nil
+	default:
+		content, err := convertAccessReviewEvidenceToCSV(evidence)
+		if err != nil {
+			return nil, "", err
+		}
+		return content, fileName + ".csv", nil
+	}
+}
+
+func convertAccessReviewEvidenceToCSV(evidence *dmsV1.AccessReviewEvidence) ([]byte, error) {
+	formatTime := func(t *time.Time) string {
+		if t == nil {
+			return ""
+		}
+		return t.Format(time.RFC3339)
+	}
+
+	buf := new(bytes.Buffer)
+	// 写入 BOM，避免 Excel 打开中文乱码
+	buf.WriteString("\xEF\xBB\xBF")
+	w := csv.NewWriter(buf)
+	records := [][]string{{"project", "subject_type", "subject", "user", "roles", "last_active_at", "decision", "reviewer", "comment", "reviewed_at"}}
+	for _, item := range evidence.Items {
+		roles := make([]string, 0, len(item.RoleWithOpRanges))
+		for _, r := range item.RoleWithOpRanges {
+			ranges := make([]string, 0, len(r.RangeUIDs))
+			for _, rg := range r.RangeUIDs {
+				ranges = append(ranges, rg.Name)
+			}
+			roles = append(roles, fmt.Sprintf("%s(%s:%s)", r.RoleUID.Name, r.OpRangeType, strings.Join(ranges, ",")))
+		}
+		reviewer := ""
+		if item.Reviewer != nil {
+			reviewer = item.Reviewer.Name
+		}
+		records = append(records, []string{
+			item.Project.Name,
+			string(item.SubjectType),
+			item.Subject.Name,
+			item.User.Name,
+			strings.Join(roles, "; "),
+			formatTime(item.LastActiveAt),
+			string(item.Decision),
+			reviewer,
+			item.Comment,
+			formatTime(item.ReviewedAt),
+		})
+	}
+	if err := w.WriteAll(records); err != nil {
+		return nil, fmt.Errorf("write access review evidence csv failed: %v", err)
+	}
+	return buf.Bytes(), nil
+}
+
+func (d *DMSService) convertBizAccessReviewCampaign(ctx context.Context, campaign *biz.AccessReviewCampaign) *dmsV1.AccessReviewCampaign {
+	ret := &dmsV1.AccessReviewCampaign{
+		Uid:         campaign.UID,
+		Name:        campaign.Name,
+		Deadline:    campaign.Deadline,
+		AutoRevoke:  campaign.AutoRevoke,
+		Status:      dmsV1.AccessReviewCampaignStatus(campaign.Status),
+		CreateUser:  dmsV1.UidWithName{Uid: campaign.CreateUserUID, Name: d.getUserNameOrUid(ctx, campaign.CreateUserUID)},
+		CreatedAt:   campaign.CreatedAt,
+		CompletedAt: convertBizExpiresAt(campaign.CompletedAt),
+	}
+	for _, uid := range campaign.ProjectUIDs {
+		name := uid
+		if project, err := d.ProjectUsecase.GetProject(ctx, uid); err == nil {
+			name = project.Name
+		}
+		ret.Projects = append(ret.Projects, dmsV1.UidWithName{Uid: uid, Name: name})
+	}
+	for _, uid := range campaign.RoleUIDs {
+		ret.Roles = append(ret.Roles, dmsV1.UidWithName{Uid: uid, Name: d.getRoleNameOrUid(ctx, uid)})
+	}
+	return ret
+}
+
+func (d *DMSService) convertBizAccessReviewItems(ctx context.Context, items []*biz.AccessReviewItem) []*dmsV1.AccessReviewItem {
+	ret := make([]*dmsV1.AccessReviewItem, 0, len(items))
+	for _, item := range items {
+		subjectName := item.SubjectName
+		if item.SubjectType == biz.AccessReviewSubjectTypeMember {
+			subjectName = item.UserName
+		}
+		v := &dmsV1.AccessReviewItem{
+			Uid:              item.UID,
+			Project:          dmsV1.UidWithName{Uid: item.ProjectUID, Name: item.ProjectName},
+			SubjectType:      dmsV1.AccessReviewSubjectType(item.SubjectType),
+			Subject:          dmsV1.UidWithName{Uid: item.SubjectUID, Name: subjectName},
+			User:             dmsV1.UidWithName{Uid: item.UserUID, Name: item.UserName},
+			RoleWithOpRanges: d.convertAccessReviewRoles(ctx, item.RoleWithOpRanges),
+			LastActiveAt:     convertBizExpiresAt(item.LastActiveAt),
+			Decision:         dmsV1.AccessReviewDecision(item.Decision),
+			Comment:          item.Comment,
+			ReviewedAt:       convertBizExpiresAt(item.ReviewedAt),
+		}
+		if item.ReviewerUID != "" {
+			v.Reviewer = &dmsV1.UidWithName{Uid: item.ReviewerUID, Name: d.getUserNameOrUid(ctx, item.ReviewerUID)}
+		}
+		ret = append(ret, v)
+	}
+	return ret
+}
+
+// convertAccessReviewRoles 复核项是历史快照，角色或数据源可能已被删除，查不到名称时展示 uid
+func (d *DMSService) convertAccessReviewRoles(ctx context.Context, roles []biz.MemberRoleWithOpRange) []dmsV1.ListMemberRoleWithOpRange {
+	ret := make([]dmsV1.ListMemberRoleWithOpRange, 0, len(roles))
+	for _, r := range roles {
+		rangeUidWithNames := make([]dmsV1.UidWithName, 0, len(r.RangeUIDs))
+		for _, uid := range r.RangeUIDs {
+			name := uid
+			if r.OpRangeType == biz.OpRangeTypeDBService {
+				if dbService, err := d.DBServiceUsecase.GetDBService(ctx, uid); err == nil {
+					name = dbService.Name
+				}
+			}
+			rangeUidWithNames = append(rangeUidWithNames, dmsV1.UidWithName{Uid: uid, Name: name})
+		}
+		ret = append(ret, dmsV1.ListMemberRoleWithOpRange{
+			RoleUID:     dmsV1.UidWithName{Uid: r.RoleUID, Name: d.getRoleNameOrUid(ctx, r.RoleUID)},
+			OpRangeType: dmsV1.OpRangeType(r.OpRangeType.String()),
+			RangeUIDs:   rangeUidWithNames,
+		})
+	}
+	return ret
+}
+
+func (d *DMSService) getRoleNameOrUid(ctx context.Context, roleUid string) string {
+	role, err := d.RoleUsecase.GetRole(ctx, roleUid)
+	if err != nil {
+		d.log.Warnf("get role %s failed: %v", roleUid, err)
+		return roleUid
+	}
+	return role.Name
+}
@@ -26,6 +26,28 @@ func (d *DMSService) GetLoginTips(ctx context.Context) (reply *dmsV1.GetLoginTip
 			LoginButtonText:      loginConfiguration.LoginButtonText,
 			DisableUserPwdLogin:  loginConfiguration.DisableUserPwdLogin,
 			DisableMultipleLogin: loginConfiguration.DisableMultipleLogin,
+			PasswordPolicy: dmsV1.PasswordPolicy{
+				MinLength:        loginConfiguration.PasswordMinLength,
+				RequireUppercase: loginConfiguration.PasswordRequireUppercase,
+				RequireLowercase: loginConfiguration.PasswordRequireLowercase,
+				RequireDigit:     loginConfiguration.PasswordRequireDigit,
+				RequireSpecial:   loginConfiguration.PasswordRequireSpecial,
+				HistoryCount:     loginConfiguration.PasswordHistoryCount,
+				MaxAgeDays:       loginConfiguration.PasswordMaxAgeDays,
+			},
+			LockoutPolicy: dmsV1.LockoutPolicy{
+				MaxFailedAttempts: loginConfiguration.LockoutMaxFailedAttempts,
+				WindowMinutes:     loginConfiguration.LockoutWindowMinutes,
+				DurationMinutes:   loginConfiguration.LockoutDurationMinutes,
+			},
+			SessionPolicy: dmsV1.SessionPolicy{
+				IdleTimeoutMinutes:         loginConfiguration.SessionIdleTimeoutMinutes,
+				MaxAgeMinutes:              loginConfiguration.SessionMaxAgeMinutes,
+				AdminIdleTimeoutMinutes:    loginConfiguration.AdminSessionIdleTimeoutMinutes,
+				AdminMaxAgeMinutes:         loginConfiguration.AdminSessionMaxAgeMinutes,
+				AccessTokenIdleTimeoutDays: loginConfiguration.AccessTokenIdleTimeoutDays,
+				AccessTokenMaxAgeDays:      loginConfiguration.AccessTokenMaxAgeDays,
+			},
 		},
 	}, nil
 }
@@ -44,7 +66,28 @@ func (d *DMSService) UpdateLoginConfiguration(ctx context.Context, userId string
 	}
 
 	loginConfiguration := req.LoginConfiguration
-	err = d.LoginConfigurationUsecase.UpdateLoginConfiguration(ctx, loginConfiguration.LoginButtonText, loginConfiguration.DisableUserPwdLogin, loginConfiguration.DisableMultipleLogin)
+	err = d.LoginConfigurationUsecase.UpdateLoginConfiguration(ctx, &biz.UpdateLoginConfigurationArgs{
+		LoginButtonText:          loginConfiguration.LoginButtonText,
+		DisableUserPwdLogin:      loginConfiguration.DisableUserPwdLogin,
+		DisableMultipleLogin:     loginConfiguration.DisableMultipleLogin,
+		PasswordMinLength:        loginConfiguration.PasswordMinLength,
+		PasswordRequireUppercase: loginConfiguration.PasswordRequireUppercase,
+		PasswordRequireLowercase: loginConfiguration.PasswordRequireLowercase,
+		PasswordRequireDigit:     loginConfiguration.PasswordRequireDigit,
+		PasswordRequireSpecial:   loginConfiguration.PasswordRequireSpecial,
+		PasswordHistoryCount:     loginConfiguration.PasswordHistoryCount,
+		PasswordMaxAgeDays:       loginConfiguration.PasswordMaxAgeDays,
+		LockoutMaxFailedAttempts: loginConfiguration.LockoutMaxFailedAttempts,
+		LockoutWindowMinutes:     loginConfiguration.LockoutWindowMinutes,
+		LockoutDurationMinutes:   loginConfiguration.LockoutDurationMinutes,
+
+		SessionIdleTimeoutMinutes:      loginConfiguration.SessionIdleTimeoutMinutes,
+		SessionMaxAgeMinutes:           loginConfiguration.SessionMaxAgeMinutes,
+		AdminSessionIdleTimeoutMinutes: loginConfiguration.AdminSessionIdleTimeoutMinutes,
+		AdminSessionMaxAgeMinutes:      loginConfiguration.AdminSessionMaxAgeMinutes,
+		AccessTokenIdleTimeoutDays:     loginConfiguration.AccessTokenIdleTimeoutDays,
+		AccessTokenMaxAgeDays:          loginConfiguration.AccessTokenMaxAgeDays,
+	})
 	return
 }
 
@@ -0,0 +1,118 @@
+package service
+
+import (
+	"context"
+	"fmt"
+
+	dmsV1 "github.com/actiontech/dms/api/dms/service/v1"
+	"github.com/actiontech/dms/internal/dms/biz"
+	"github.com/actiontech/dms/internal/dms/pkg/exportdelivery"
+)
+
+func (d *DMSService) AddDataExportDeliveryTarget(ctx context.Context, req *dmsV1.AddDataExportDeliveryTargetReq, currentUserUid string) (reply *dmsV1.AddDataExportDeliveryTargetReply, err error) {
+	d.log.Infof("AddDataExportDeliveryTarget.req=%v", req.DeliveryTarget.Name)
+	defer func() {
+		d.log.Infof("AddDataExportDeliveryTarget.req=%v;error=%v", req.DeliveryTarget.Name, err)
+	}()
+
+	uid, err := d.DataExportDeliveryUsecase.AddDataExportDeliveryTarget(ctx, currentUserUid, convertApiDataExportDeliveryTarget(req.DeliveryTarget))
+	if err != nil {
+		return nil, fmt.Errorf("add data export delivery target failed: %w", err)
+	}
+	reply = &dmsV1.AddDataExportDeliveryTargetReply{}
+	reply.Data.Uid = uid
+	return reply, nil
+}
+
+func (d *DMSService) UpdateDataExportDeliveryTarget(ctx context.Context, req *dmsV1.UpdateDataExportDeliveryTargetReq, currentUserUid string) (err error) {
+	d.log.Infof("UpdateDataExportDeliveryTarget.req=%v", req.DeliveryTargetUid)
+	defer func() {
+		d.log.Infof("UpdateDataExportDeliveryTarget.req=%v;error=%v", req.DeliveryTargetUid, err)
+	}()
+
+	target := convertApiDataExportDeliveryTarget(req.DeliveryTarget)
+	target.UID = req.DeliveryTargetUid
+	if err := d.DataExportDeliveryUsecase.UpdateDataExportDeliveryTarget(ctx, currentUserUid, target); err != nil {
+		return fmt.Errorf("update data export delivery target failed: %w", err)
+	}
+	return nil
+}
+
+func (d *DMSService) DeleteDataExportDeliveryTarget(ctx context.Context, req *dmsV1.DeleteDataExportDeliveryTargetReq, currentUserUid string) (err error) {
+	d.log.Infof("DeleteDataExportDeliveryTarget.req=%v", req)
+	defer func() {
+		d.log.Infof("DeleteDataExportDeliveryTarget.req=%v;error=%v", req, err)
+	}()
+
+	if err := d.DataExportDeliveryUsecase.DeleteDataExportDeliveryTarget(ctx, currentUserUid, req.DeliveryTargetUid); err != nil {
+		return fmt.Errorf("delete data export delivery target failed: %w", err)
+	}
+	return nil
+}
+
+func (d *DMSService) ListDataExportDeliveryTargets(ctx context.Context) (*dmsV1.ListDataExportDeliveryTargetsReply, error) {
+	targets, err := d.DataExportDeliveryUsecase.ListDataExportDeliveryTargets(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("list data export delivery targets failed: %w", err)
+	}
+
+	ret := make([]*dmsV1.ListDataExportDeliveryTarget, 0, len(targets))
+	for _, t := range targets {
+		ret = append(ret, &dmsV1.ListDataExportDeliveryTarget{
+			Uid:           t.UID,
+			Name:          t.Name,
+			Type:          dmsV1.DataExportDeliveryTargetType(t.Config.Type),
+			Path:          t.Config.Path,
+			Endpoint:      t.Config.Endpoint,
+			Region:        t.Config.Region,
+			Bucket:        t.Config.Bucket,
+			AccessKeyID:   t.Config.AccessKeyID,
+			PathStyle:     t.Config.PathStyle,
+			Host:          t.Config.Host,
+			Port:          t.Config.Port,
+			Username:      t.Config.Username,
+			HostPublicKey: t.Config.HostPublicKey,
+			CreatedAt:     t.CreatedAt,
+		})
+	}
+	return &dmsV1.ListDataExportDeliveryTargetsReply{
+		Data:  ret,
+		Total: int64(len(ret)),
+	}, nil
+}
+
+// getDataExportWorkflowDeliveryTarget 投递目标已删除时仍返回标识，名称为空
+func (d *DMSService) getDataExportWorkflowDeliveryTarget(ctx context.Context, w *biz.Workflow) *dmsV1.UidWithName {
+	if w.DeliveryTargetUID == "" {
+		return nil
+	}
+	ret := &dmsV1.UidWithName{Uid: w.DeliveryTargetUID}
+	target, err := d.DataExportDeliveryUsecase.GetDataExportDeliveryTarget(ctx, w.DeliveryTargetUID)
+	if err != nil {
+		d.log.Errorf("get data export delivery target %s of workflow %s failed: %v", w.DeliveryTargetUID, w.UID, err)
+		return ret
+	}
+	ret.Name = target.Name
+	return ret
+}
+
+func convertApiDataExportDeliveryTarget(t *dmsV1.DataExportDeliveryTarget) *biz.DataExportDeliveryTarget {
+	return &biz.DataExportDeliveryTarget{
+		Name: t.Name,
+		Config: exportdelivery.Config{
+			Type:            exportdelivery.Type(t.Type),
+			Path:            t.Path,
+			Endpoint:        t.Endpoint,
+			Region:          t.Region,
+			Bucket:          t.Bucket,
+			AccessKeyID:     t.AccessKeyID,
+			SecretAccessKey: t.SecretAccessKey,
+			PathStyle:       t.PathStyle,
+			Host:            t.Host,
+			Port:            t.Port,
+			Username:        t.Username,
+			PrivateKey:      t.PrivateKey,
+			HostPublicKey:   t.HostPublicKey,
+		},
+	}
+}
@@ -0,0 +1,103 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	dmsV1 "github.com/actiontech/dms/api/dms/service/v1"
+	"github.com/actiontech/dms/internal/dms/biz"
+)
+
+func (d *DMSService) ListDataExportTaskDownloads(ctx context.Context, req *dmsV1.ListDataExportTaskDownloadsReq, currentUserUid string) (reply *dmsV1.ListDataExportTaskDownloadsReply, err error) {
+	downloads, workflow, err := d.DataExportWorkflowUsecase.ListDataExportDownloads(ctx, req.ProjectUid, req.DataExportTaskUid, currentUserUid)
+	if err != nil {
+		return nil, fmt.Errorf("list data export task downloads failed: %w", err)
+	}
+
+	ret := make([]*dmsV1.DataExportTaskDownload, 0, len(downloads))
+	for _, download := range downloads {
+		ret = append(ret, &dmsV1.DataExportTaskDownload{
+			User:        dmsV1.UidWithName{Uid: download.UserUID, Name: d.getUserNameOrUid(ctx, download.UserUID)},
+			IP:          download.IP,
+			Bytes:       download.Bytes,
+			StartOffset: download.StartOffset,
+			Via:         string(download.Via),
+			Resumed:     download.Resumed,
+			CreatedAt:   download.CreatedAt,
+		})
+	}
+	return &dmsV1.ListDataExportTaskDownloadsReply{
+		Data:         ret,
+		Total:        int64(len(ret)),
+		MaxDownloads: workflow.MaxDownloads,
+	}, nil
+}
+
+func (d *DMSService) CreateDataExportDownloadLink(ctx context.Context, req *dmsV1.CreateDataExportDownloadLinkReq, currentUserUid string) (reply *dmsV1.CreateDataExportDownloadLinkReply, err error) {
+	d.log.Infof("CreateDataExportDownloadLink.req=%v", req)
+	defer func() {
+		d.log.Infof("CreateDataExportDownloadLink.req=%v;error=%v", req, err)
+	}()
+
+	token, link, err := d.DataExportWorkflowUsecase.CreateDataExportDownloadLink(ctx, req.ProjectUid, req.DataExportTaskUid, currentUserUid, time.Duration(req.ExpiresInSeconds)*time.Second)
+	if err != nil {
+		return nil, fmt.Errorf("create data export download link failed: %w", err)
+	}
+
+	reply = &dmsV1.CreateDataExportDownloadLinkReply{}
+	reply.Data.DownloadUrl = fmt.Sprintf("%s/%s", dmsV1.DataExportDownloadLinkRouterGroup, token)
+	reply.Data.ExpiresAt = link.ExpiresAt
+	return reply, nil
+}
+
+// VerifyDataExportDownloadLink 校验下载链接，返回下载请求及链接创建人
+func (d *DMSService) VerifyDataExportDownloadLink(ctx context.Context, req *dmsV1.DownloadDataExportTaskByLinkReq, resumed bool) (*dmsV1.DownloadDataExportTaskReq, *biz.DataExportDownloadLink, error) {
+	link, err := d.DataExportWorkflowUsecase.VerifyDataExportDownloadLink(ctx, req.Token, resumed)
+	if err != nil {
+		return nil, nil, err
+	}
+	return &dmsV1.DownloadDataExportTaskReq{ProjectUid: link.ProjectUID, DataExportTaskUid: link.TaskUID}, link, nil
+}
+
+// ReserveDataExportDownload 下载本节点的导出文件前预留一次下载次数
+func (d *DMSService) ReserveDataExportDownload(ctx context.Context, req *dmsV1.DownloadDataExportTaskReq) error {
+	return d.DataExportWorkflowUsecase.ReserveDataExportDownload(ctx, req.ProjectUid, req.DataExportTaskUid)
+}
+
+func (d *DMSService) ReleaseDataExportDownload(ctx context.Context, req *dmsV1.DownloadDataExportTaskReq) error {
+	return d.DataExportWorkflowUsecase.ReleaseDataExportDownload(ctx, req.DataExportTaskUid)
+}
+
+// CanResumeDataExportDownload 判断以 fingerprint 下载、从 offset 开始的请求是否为续传
+func (d *DMSService) CanResumeDataExportDownload(ctx context.Context, req *dmsV1.DownloadDataExportTaskReq, userUid string, link *biz.DataExportDownloadLink, fingerprint string, offset int64) (bool, error) {
+	return d.DataExportWorkflowUsecase.CanResumeDataExportDownload(ctx, req.ProjectUid, req.DataExportTaskUid, userUid, link, fingerprint, offset)
+}
+
+func (d *DMSService) GetDownloadDataExportTask(ctx context.Context, req *dmsV1.DownloadDataExportTaskReq) (*biz.DataExportTask, error) {
+	return d.DataExportWorkflowUsecase.GetDownloadDataExportTask(ctx, req.DataExportTaskUid)
+}
+
+// PrepareDataExportDownloadWatermark 新的下载生成写入归档注释的下载指纹，未开启水印时返回空
+func (d *DMSService) PrepareDataExportDownloadWatermark(ctx context.Context, req *dmsV1.DownloadDataExportTaskReq, task *biz.DataExportTask, userUid string) (string, error) {
+	return d.DataExportWorkflowUsecase.PrepareDownloadWatermark(ctx, req.ProjectUid, task, userUid)
+}
+
+func (d *DMSService) OpenDataExportDownloadFile(filePath, fingerprint string) (*biz.DataExportDownloadFile, error) {
+	return biz.OpenDataExportDownloadFile(filePath, fingerprint)
+}
+
+func (d *DMSService) UseDataExportDownloadLink(ctx context.Context, link *biz.DataExportDownloadLink) error {
+	return d.DataExportWorkflowUsecase.UseDataExportDownloadLink(ctx, link)
+}
+
+// RecordDataExportDownload download 中由调用方填写下载人、IP、发送的范围、下载指纹及是否续传
+func (d *DMSService) RecordDataExportDownload(ctx context.Context, req *dmsV1.DownloadDataExportTaskReq, link *biz.DataExportDownloadLink, download *biz.DataExportDownload) error {
+	download.TaskUID = req.DataExportTaskUid
+	download.Via = biz.DataExportDownloadViaSession
+	if link != nil {
+		download.Via = biz.DataExportDownloadViaLink
+		download.LinkUID = link.UID
+	}
+	return d.DataExportWorkflowUsecase.RecordDataExportDownload(ctx, req.ProjectUid, download)
+}
@@ -0,0 +1,75 @@
+package service
+
+import (
+	"context"
+	"fmt"
+
+	dmsV1 "github.com/actiontech/dms/api/dms/service/v1"
+	"github.com/actiontech/dms/internal/dms/biz"
+)
+
+func (d *DMSService) PreviewDataExportWorkflow(ctx context.Context, req *dmsV1.PreviewDataExportWorkflowReq, currentUserUid string) (reply *dmsV1.PreviewDataExportWorkflowReply, err error) {
+	d.log.Infof("PreviewDataExportWorkflow.req=%v", req)
+	defer func() {
+		d.log.Infof("PreviewDataExportWorkflow.req=%v;error=%v", req, err)
+	}()
+
+	preview, err := d.DataExportPreviewUsecase.PreviewDataExportWorkflow(ctx, req.ProjectUid, req.DataExportWorkflowUid, currentUserUid, req.SampleRows)
+	if err != nil {
+		return nil, fmt.Errorf("preview data export workflow failed: %w", err)
+	}
+	return &dmsV1.PreviewDataExportWorkflowReply{Data: d.convertDataExportPreview(ctx, preview)}, nil
+}
+
+func (d *DMSService) ListDataExportWorkflowPreviews(ctx context.Context, req *dmsV1.ListDataExportWorkflowPreviewsReq, currentUserUid string) (*dmsV1.ListDataExportWorkflowPreviewsReply, error) {
+	previews, err := d.DataExportPreviewUsecase.ListDataExportPreviews(ctx, req.ProjectUid, req.DataExportWorkflowUid, currentUserUid)
+	if err != nil {
+		return nil, fmt.Errorf("list data export workflow previews failed: %w", err)
+	}
+	ret := make([]*dmsV1.DataExportPreview, 0, len(previews))
+	for _, preview := range previews {
+		ret = append(ret, d.convertDataExportPreview(ctx, preview))
+	}
+	return &dmsV1.ListDataExportWorkflowPreviewsReply{
+		Data:  ret,
+		Total: int64(len(ret)),
+	}, nil
+}
+
+func (d *DMSService) convertDataExportPreview(ctx context.Context, preview *biz.DataExportPreview) *dmsV1.DataExportPreview {
+	results := make([]*dmsV1.DataExportPreviewResult, 0, len(preview.Results))
+	for _, r := range preview.Results {
+		rows := make([][]*string, 0, len(r.Rows))
+		for _, row := range r.Rows {
+			values := make([]*string, 0, len(row))
+			for _, v := range row {
+				if v == nil {
+					values = append(values, nil)
+					continue
+				}
+				s := fmt.Sprint(v)
+				values = append(values, &s)
+			}
+			rows = append(rows, values)
+		}
+		results = append(results, &dmsV1.DataExportPreviewResult{
+			TaskUid:        r.TaskUID,
+			DBServiceUid:   r.DBServiceUID,
+			SQLNumber:      r.SQLNumber,
+			SQL:            r.SQL,
+			Columns:        r.Columns,
+			Rows:           rows,
+			MaskedColumns:  r.MaskedColumns,
+			EstimatedRows:  r.EstimatedRows,
+			EstimatedBytes: r.EstimatedBytes,
+			Error:          r.Error,
+		})
+	}
+	return &dmsV1.DataExportPreview{
+		Uid:        preview.UID,
+		CreateUser: dmsV1.UidWithName{Uid: preview.CreateUserUID, Name: d.getUserNameOrUid(ctx, preview.CreateUserUID)},
+		SampleRows: preview.SampleRows,
+		Results:    results,
+		CreatedAt:  preview.CreatedAt,
+	}
+}
@@ -0,0 +1,91 @@
+package service
+
+import (
+	"context"
+	"fmt"
+
+	dmsV1 "github.com/actiontech/dms/api/dms/service/v1"
+	"github.com/actiontech/dms/internal/dms/biz"
+)
+
+func (d *DMSService) SetDataExportQuota(ctx context.Context, req *dmsV1.SetDataExportQuotaReq, currentUserUid string) (err error) {
+	d.log.Infof("SetDataExportQuota.req=%v", req)
+	defer func() {
+		d.log.Infof("SetDataExportQuota.req=%v;error=%v", req, err)
+	}()
+
+	if err := d.DataExportQuotaUsecase.SetDataExportQuota(ctx, currentUserUid, biz.DataExportQuotaScope(req.Quota.Scope), req.Quota.ScopeUid, convertApiDataExportLimits(req.Quota.DataExportLimits)); err != nil {
+		return fmt.Errorf("set data export quota failed: %w", err)
+	}
+	return nil
+}
+
+func (d *DMSService) ListDataExportQuotas(ctx context.Context, req *dmsV1.ListDataExportQuotasReq, currentUserUid string) (*dmsV1.ListDataExportQuotasReply, error) {
+	quotas, err := d.DataExportQuotaUsecase.ListDataExportQuotas(ctx, currentUserUid, req.ProjectUid)
+	if err != nil {
+		return nil, fmt.Errorf("list data export quotas failed: %w", err)
+	}
+
+	ret := make([]*dmsV1.ListDataExportQuota, 0, len(quotas))
+	for _, q := range quotas {
+		ret = append(ret, &dmsV1.ListDataExportQuota{
+			DataExportQuota: dmsV1.DataExportQuota{
+				Scope:            dmsV1.DataExportQuotaScope(q.Scope),
+				ScopeUid:         q.ScopeUID,
+				DataExportLimits: convertBizDataExportLimits(q.Limits),
+			},
+			ProjectUid: q.ProjectUID,
+		})
+	}
+	return &dmsV1.ListDataExportQuotasReply{
+		Data:  ret,
+		Total: int64(len(ret)),
+	}, nil
+}
+
+func (d *DMSService) GetDataExportQuotaUsage(ctx context.Context, req *dmsV1.GetDataExportQuotaUsageReq, currentUserUid string) (*dmsV1.GetDataExportQuotaUsageReply, error) {
+	usage, err := d.DataExportQuotaUsecase.GetDataExportQuotaUsage(ctx, currentUserUid, req.ProjectUid)
+	if err != nil {
+		return nil, fmt.Errorf("get data export quota usage failed: %w", err)
+	}
+	return &dmsV1.GetDataExportQuotaUsageReply{
+		Data: convertBizDataExportQuotaUsage(usage),
+	}, nil
+}
+
+// getDataExportWorkflowQuotaUsage 工单详情中展示申请人的导出限制及用量，获取失败时不影响工单详情
+func (d *DMSService) getDataExportWorkflowQuotaUsage(ctx context.Context, w *biz.Workflow) *dmsV1.DataExportQuotaUsage {
+	usage, err := d.DataExportWorkflowUsecase.GetDataExportWorkflowQuotaUsage(ctx, w)
+	if err != nil {
+		d.log.Errorf("get data export quota usage of workflow %s failed: %v", w.UID, err)
+		return nil
+	}
+	return convertBizDataExportQuotaUsage(usage)
+}
+
+func convertApiDataExportLimits(l dmsV1.DataExportLimits) biz.DataExportLimits {
+	return biz.DataExportLimits{
+		MaxRowsPerSQL:              l.MaxRowsPerSQL,
+		MaxFileSizeBytes:           l.MaxFileSizeBytes,
+		MaxExportsPerUserPerDay:    l.MaxExportsPerUserPerDay,
+		MaxBytesPerProjectPerMonth: l.MaxBytesPerProjectPerMonth,
+	}
+}
+
+func convertBizDataExportLimits(l biz.DataExportLimits) dmsV1.DataExportLimits {
+	return dmsV1.DataExportLimits{
+		MaxRowsPerSQL:              l.MaxRowsPerSQL,
+		MaxFileSizeBytes:           l.MaxFileSizeBytes,
+		MaxExportsPerUserPerDay:    l.MaxExportsPerUserPerDay,
+		MaxBytesPerProjectPerMonth: l.MaxBytesPerProjectPerMonth,
+	}
+}
+
+func convertBizDataExportQuotaUsage(usage *biz.DataExportQuotaUsage) *dmsV1.DataExportQuotaUsage {
+	return &dmsV1.DataExportQuotaUsage{
+		Limits:                  convertBizDataExportLimits(usage.Limits),
+		UserExportsToday:        usage.UserExportsToday,
+		UserProjectExportsToday: usage.UserProjectExportsToday,
+		ProjectBytesThisMonth:   usage.ProjectBytesThisMonth,
+	}
+}
@@ -0,0 +1,103 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	dmsV1 "github.com/actiontech/dms/api/dms/service/v1"
+	"github.com/actiontech/dms/internal/dms/biz"
+	pkgErr "github.com/actiontech/dms/internal/dms/pkg/errors"
+)
+
+func (d *DMSService) SetDataExportSchedule(ctx context.Context, req *dmsV1.SetDataExportScheduleReq, currentUserUid string) (reply *dmsV1.SetDataExportScheduleReply, err error) {
+	d.log.Infof("SetDataExportSchedule.req=%v", req)
+	defer func() {
+		d.log.Infof("SetDataExportSchedule.req=%v;reply=%v;error=%v", req, reply, err)
+	}()
+
+	args := &biz.SetDataExportScheduleArgs{
+		ProjectUID:     req.ProjectUid,
+		WorkflowUID:    req.DataExportWorkflowUid,
+		CronExpression: req.Payload.CronExpression,
+	}
+	if req.Payload.EndTime != nil {
+		args.EndTime = *req.Payload.EndTime
+	}
+	schedule, err := d.DataExportScheduleUsecase.SetDataExportSchedule(ctx, currentUserUid, args)
+	if err != nil {
+		return nil, fmt.Errorf("set data export schedule failed: %w", err)
+	}
+	return &dmsV1.SetDataExportScheduleReply{
+		Data: d.convertBizDataExportSchedule(ctx, schedule),
+	}, nil
+}
+
+func (d *DMSService) DeleteDataExportSchedule(ctx context.Context, req *dmsV1.DeleteDataExportScheduleReq, currentUserUid string) (err error) {
+	d.log.Infof("DeleteDataExportSchedule.req=%v", req)
+	defer func() {
+		d.log.Infof("DeleteDataExportSchedule.req=%v;error=%v", req, err)
+	}()
+
+	if err := d.DataExportScheduleUsecase.DeleteDataExportSchedule(ctx, currentUserUid, req.ProjectUid, req.DataExportWorkflowUid); err != nil {
+		return fmt.Errorf("delete data export schedule failed: %w", err)
+	}
+	return nil
+}
+
+func (d *DMSService) ResumeDataExportSchedule(ctx context.Context, req *dmsV1.ResumeDataExportScheduleReq, currentUserUid string) (err error) {
+	d.log.Infof("ResumeDataExportSchedule.req=%v", req)
+	defer func() {
+		d.log.Infof("ResumeDataExportSchedule.req=%v;error=%v", req, err)
+	}()
+
+	if err := d.DataExportScheduleUsecase.ResumeDataExportSchedule(ctx, currentUserUid, req.ProjectUid, req.DataExportWorkflowUid); err != nil {
+		return fmt.Errorf("resume data export schedule failed: %w", err)
+	}
+	return nil
+}
+
+func (d *DMSService) ListDataExportSchedules(ctx context.Context, req *dmsV1.ListDataExportSchedulesReq, currentUserUid string) (*dmsV1.ListDataExportSchedulesReply, error) {
+	schedules, err := d.DataExportScheduleUsecase.ListDataExportSchedules(ctx, currentUserUid, req.ProjectUid)
+	if err != nil {
+		return nil, fmt.Errorf("list data export schedules failed: %w", err)
+	}
+
+	ret := make([]*dmsV1.DataExportSchedule, 0, len(schedules))
+	for _, schedule := range schedules {
+		ret = append(ret, d.convertBizDataExportSchedule(ctx, schedule))
+	}
+	return &dmsV1.ListDataExportSchedulesReply{
+		Data:  ret,
+		Total: int64(len(ret)),
+	}, nil
+}
+
+// getDataExportWorkflowSchedule 工单详情中展示定时导出，未设置时返回 nil
+func (d *DMSService) getDataExportWorkflowSchedule(ctx context.Context, workflowUid string) *dmsV1.DataExportSchedule {
+	schedule, err := d.DataExportScheduleUsecase.GetDataExportSchedule(ctx, workflowUid)
+	if err != nil {
+		if !errors.Is(err, pkgErr.ErrStorageNoData) {
+			d.log.Errorf("get data export schedule of workflow %s failed: %v", workflowUid, err)
+		}
+		return nil
+	}
+	return d.convertBizDataExportSchedule(ctx, schedule)
+}
+
+func (d *DMSService) convertBizDataExportSchedule(ctx context.Context, schedule *biz.DataExportSchedule) *dmsV1.DataExportSchedule {
+	return &dmsV1.DataExportSchedule{
+		Uid:                schedule.UID,
+		WorkflowUid:        schedule.WorkflowUID,
+		CreateUser:         dmsV1.UidWithName{Uid: schedule.CreateUserUID, Name: d.getUserNameOrUid(ctx, schedule.CreateUserUID)},
+		CronExpression:     schedule.CronExpression,
+		EndTime:            convertBizExpiresAt(schedule.EndTime),
+		Status:             dmsV1.DataExportScheduleStatus(schedule.Status),
+		SuspendReason:      schedule.SuspendReason,
+		NextRunAt:          convertBizExpiresAt(schedule.NextRunAt),
+		LastRunAt:          convertBizExpiresAt(schedule.LastRunAt),
+		LastRunWorkflowUid: schedule.LastRunWorkflowUID,
+		LastRunError:       schedule.LastRunError,
+		CreatedAt:          schedule.CreatedAt,
+	}
+}
@@ -0,0 +1,41 @@
+package service
+
+import (
+	"context"
+	"fmt"
+
+	dmsV1 "github.com/actiontech/dms/api/dms/service/v1"
+	"github.com/actiontech/dms/internal/dms/biz"
+)
+
+func (d *DMSService) TraceDataExport(ctx context.Context, file []byte, sample string, currentUserUid string) (reply *dmsV1.TraceDataExportReply, err error) {
+	traces, err := d.DataExportWorkflowUsecase.TraceDataExport(ctx, currentUserUid, file, sample)
+	if err != nil {
+		return nil, fmt.Errorf("trace data export failed: %w", err)
+	}
+
+	ret := make([]*dmsV1.DataExportTrace, 0, len(traces))
+	for _, t := range traces {
+		downloads := make([]*dmsV1.DataExportWatermarkRecord, 0, len(t.Downloads))
+		for _, w := range t.Downloads {
+			downloads = append(downloads, d.convertDataExportWatermarkRecord(ctx, w))
+		}
+		ret = append(ret, &dmsV1.DataExportTrace{
+			ProjectUid:            t.Watermark.ProjectUID,
+			DataExportWorkflowUid: t.Watermark.WorkflowUID,
+			DataExportTaskUid:     t.Watermark.TaskUID,
+			Kind:                  string(t.Watermark.Kind),
+			Matched:               d.convertDataExportWatermarkRecord(ctx, t.Watermark),
+			Downloads:             downloads,
+		})
+	}
+	return &dmsV1.TraceDataExportReply{Data: ret}, nil
+}
+
+func (d *DMSService) convertDataExportWatermarkRecord(ctx context.Context, w *biz.DataExportWatermark) *dmsV1.DataExportWatermarkRecord {
+	return &dmsV1.DataExportWatermarkRecord{
+		Fingerprint: w.Fingerprint,
+		User:        dmsV1.UidWithName{Uid: w.UserUID, Name: d.getUserNameOrUid(ctx, w.UserUID)},
+		CreatedAt:   w.CreatedAt,
+	}
+}
@@ -8,6 +8,7 @@ import (
 	dmsV1 "github.com/actiontech/dms/api/dms/service/v1"
 	"github.com/actiontech/dms/internal/dms/biz"
 	pkgConst "github.com/actiontech/dms/internal/dms/pkg/constant"
+	"github.com/actiontech/dms/internal/dms/pkg/exportfile"
 	"github.com/actiontech/dms/internal/pkg/locale"
 	dmsCommonV1 "github.com/actiontech/dms/pkg/dms-common/api/dms/v1"
 )
@@ -25,6 +26,15 @@ func (d *DMSService) AddDataExportWorkflow(ctx context.Context, req *dmsV1.AddDa
 		ProjectUID:         req.ProjectUid,
 		WorkflowTemplateId: req.DataExportWorkflow.WorkflowTemplateId,
 		OpsTypeUID:         req.DataExportWorkflow.OpsTypeUID,
+		EncryptExport:      req.DataExportWorkflow.EncryptExport,
+		MaxDownloads:       req.DataExportWorkflow.MaxDownloads,
+		DeliveryTargetUID:  req.DataExportWorkflow.DeliveryTargetUid,
+	}
+	if err := d.DataExportWorkflowUsecase.CheckExportEncryption(ctx, currentUserUid, args); err != nil {
+		return nil, err
+	}
+	if err := d.DataExportWorkflowUsecase.CheckDataExportDelivery(ctx, args); err != nil {
+		return nil, err
 	}
 	uid, err := d.DataExportWorkflowUsecase.AddDataExportWorkflow(ctx, currentUserUid, args)
 	if err != nil {
@@ -153,6 +163,8 @@ func (d *DMSService) ListDataExportWorkflow(ctx context.Context, req *dmsV1.List
 	// 按项目批量解析运维类型名称（D8：禁止逐条请求字典）
 	opsTypeNameByProject := d.buildOpsTypeNameMapsByProjects(ctx, projectUIDs)
 
+	slaStatuses := d.WorkflowApprovalSLAUsecase.GetWorkflowApprovalSLAStatuses(ctx, workflows)
+
 	ret := make([]*dmsV1.ListDataExportWorkflow, len(workflows))
 	for i, w := range workflows {
 		ret[i] = &dmsV1.ListDataExportWorkflow{
@@ -166,6 +178,7 @@ func (d *DMSService) ListDataExportWorkflow(ctx context.Context, req *dmsV1.List
 			WorkflowTemplateId:   w.WorkflowTemplateId,
 			WorkflowTemplateName: w.WorkflowTemplateName,
 			OpsType:              resolveOpsTypeFromNameMap(w.OpsTypeUID, opsTypeNameByProject[w.ProjectUID]),
+			SLAStatus:            dmsV1.WorkflowApprovalSLAStatus(slaStatuses[w.UID]),
 		}
 		creater := convertBizUidWithName(d.UserUsecase.GetBizUserWithNameByUids(ctx, []string{w.CreateUserUID}))
 		if len(creater) > 0 {
@@ -242,6 +255,8 @@ func (d *DMSService) GetDataExportWorkflow(ctx context.Context, req *dmsV1.GetDa
 		WorkflowTemplateId:   w.WorkflowTemplateId,
 		WorkflowTemplateName: w.WorkflowTemplateName,
 		OpsType:              d.resolveDataExportWorkflowOpsType(ctx, w.ProjectUID, w.OpsTypeUID),
+		EncryptExport:        w.EncryptExport,
+		MaxDownloads:         w.MaxDownloads,
 		WorkflowRecord: dmsV1.WorkflowRecord{
 			CurrentStepNumber: uint(w.WorkflowRecord.CurrentWorkflowStepId),
 			Status:            dmsV1.DataExportWorkflowStatus(w.WorkflowRecord.Status),
@@ -269,6 +284,9 @@ func (d *DMSService) GetDataExportWorkflow(ctx context.Context, req *dmsV1.GetDa
 	}
 
 	d.fillGetDataExportUnmaskingWorkflowSummary(ctx, req.DataExportWorkflowUid, data)
+	data.Schedule = d.getDataExportWorkflowSchedule(ctx, req.DataExportWorkflowUid)
+	data.QuotaUsage = d.getDataExportWorkflowQuotaUsage(ctx, w)
+	data.DeliveryTarget = d.getDataExportWorkflowDeliveryTarget(ctx, w)
 
 	return &dmsV1.GetDataExportWorkflowReply{
 		Data: data,
@@ -343,16 +361,29 @@ func (d *DMSService) ExportDataExportWorkflow(ctx context.Context, req *dmsV1.Ex
 	return d.DataExportWorkflowUsecase.ExportDataExportWorkflow(ctx, req.ProjectUid, req.DataExportWorkflowUid, currentUserUid)
 
 }
+func (d *DMSService) ResendDataExportWorkflowPassword(ctx context.Context, req *dmsV1.ResendDataExportWorkflowPasswordReq, currentUserUid string) error {
+	return d.DataExportWorkflowUsecase.ResendExportPassword(ctx, req.ProjectUid, req.DataExportWorkflowUid, currentUserUid)
+}
+
 func (d *DMSService) AddDataExportTask(ctx context.Context, req *dmsV1.AddDataExportTaskReq, currentUserUid string) (reply *dmsV1.AddDataExportTaskReply, err error) {
 	// generate biz arg
 	args := make([]*biz.DataExportTask, 0)
 	for _, task := range req.DataExportTasks {
+		fileType, err := exportfile.ParseFileType(task.ExportFileType)
+		if err != nil {
+			return nil, err
+		}
+		watermarkMode, err := exportfile.ParseWatermarkMode(task.WatermarkMode)
+		if err != nil {
+			return nil, err
+		}
 		args = append(args, &biz.DataExportTask{
 			DBServiceUid:   task.DBServiceUid,
 			CreateUserUID:  currentUserUid,
 			DatabaseName:   task.DatabaseName,
 			ExportType:     "SQL",
-			ExportFileType: "CSV",
+			ExportFileType: string(fileType),
+			WatermarkMode:  string(watermarkMode),
 			ExportSQL:      task.ExportSQL,
 			ExportStatus:   biz.DataExportTaskStatusInit,
 		})
@@ -389,8 +420,13 @@ func (d *DMSService) BatchGetDataExportTask(ctx context.Context, req *dmsV1.Batc
 			FileName:         task.ExportFileName,
 			ExportType:       task.ExportType,
 			ExportFileType:   task.ExportFileType,
+			WatermarkMode:    task.WatermarkMode,
 			ExportFailStage:  task.ExportFailStage,
 			ExportFailReason: task.ExportFailReason,
+			DeliveryStatus:   string(task.DeliveryStatus),
+			DeliveryLocation: task.DeliveryLocation,
+			DeliveryError:    task.DeliveryError,
+			ExportFileSHA256: task.ExportFileChecksum,
 			AuditResult: dmsV1.AuditTaskResult{
 				AuditLevel: task.AuditLevel,
 				Score:      task.AuditScore,
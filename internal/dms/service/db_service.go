@@ -47,6 +47,10 @@ func (d *DMSService) UpdateDBService(ctx context.Context, req *dmsV2.UpdateDBSer
 			}
 			continue
 		}
+		if additionalParam.Name == biz.DBServiceAdditionalParam_BusinessTag {
+			setDBServiceBusinessTagParam(&additionalParams, additionalParam.Value)
+			continue
+		}
 		err = additionalParams.SetParamValue(additionalParam.Name, additionalParam.Value)
 		if err != nil {
 			return fmt.Errorf("set param value failed,invalid db type: %s", req.DBService.DBType)
@@ -238,6 +242,20 @@ const DefaultBackupMaxRows uint64 = 1000
 //
 // 返回值:
 //   - int64: 最终确定的备份最大行数。
+// setDBServiceBusinessTagParam 业务标签不属于数据库驱动定义的参数，驱动参数中没有时追加
+func setDBServiceBusinessTagParam(additionalParams *params.Params, value string) {
+	if param := additionalParams.GetParam(biz.DBServiceAdditionalParam_BusinessTag); param != nil {
+		param.Value = value
+		return
+	}
+	*additionalParams = append(*additionalParams, &params.Param{
+		Key:   biz.DBServiceAdditionalParam_BusinessTag,
+		Value: value,
+		Desc:  "business tag",
+		Type:  params.ParamTypeString,
+	})
+}
+
 func autoChooseBackupMaxRows(enableBackup bool, backupMaxRows *uint64) uint64 {
 	// 如果启用了备份并且备份最大行数的设置不为 nil，则返回设置的备份最大行数。
 	if enableBackup && backupMaxRows != nil {
@@ -265,6 +283,10 @@ func (d *DMSService) AddDBService(ctx context.Context, req *dmsV1.AddDBServiceRe
 			}
 			continue
 		}
+		if additionalParam.Name == biz.DBServiceAdditionalParam_BusinessTag {
+			setDBServiceBusinessTagParam(&additionalParams, additionalParam.Value)
+			continue
+		}
 		err = additionalParams.SetParamValue(additionalParam.Name, additionalParam.Value)
 		if err != nil {
 			return nil, fmt.Errorf("set param value failed,invalid db type: %s", req.DBService.DBType)
@@ -335,6 +357,10 @@ func (d *DMSService) AddDBServiceV2(ctx context.Context, req *dmsV2.AddDBService
 			}
 			continue
 		}
+		if additionalParam.Name == biz.DBServiceAdditionalParam_BusinessTag {
+			setDBServiceBusinessTagParam(&additionalParams, additionalParam.Value)
+			continue
+		}
 		err = additionalParams.SetParamValue(additionalParam.Name, additionalParam.Value)
 		if err != nil {
 			return nil, fmt.Errorf("set param value failed,invalid db type: %s", req.DBService.DBType)
@@ -0,0 +1,21 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/actiontech/dms/internal/dms/biz"
+	"github.com/actiontech/dms/pkg/params"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSetDBServiceBusinessTagParam(t *testing.T) {
+	additionalParams := params.Params{{Key: "charset", Value: "utf8mb4", Type: params.ParamTypeString}}
+
+	setDBServiceBusinessTagParam(&additionalParams, "payments")
+	assert.Len(t, additionalParams, 2)
+	assert.Equal(t, "payments", additionalParams.GetParam(biz.DBServiceAdditionalParam_BusinessTag).String())
+
+	setDBServiceBusinessTagParam(&additionalParams, "billing")
+	assert.Len(t, additionalParams, 2)
+	assert.Equal(t, "billing", additionalParams.GetParam(biz.DBServiceAdditionalParam_BusinessTag).String())
+}
@@ -0,0 +1,82 @@
+package service
+
+import (
+	"context"
+	"fmt"
+
+	dmsV1 "github.com/actiontech/dms/api/dms/service/v1"
+	"github.com/actiontech/dms/internal/dms/biz"
+)
+
+func (d *DMSService) ListLoginSessions(ctx context.Context, currentUserUid, currentSessionID string, req *dmsV1.ListLoginSessionsReq) (reply *dmsV1.ListLoginSessionsReply, err error) {
+	userUid := req.FilterByUserUid
+	if userUid == "" {
+		canGlobalOp, err := d.OpPermissionVerifyUsecase.CanOpGlobal(ctx, currentUserUid, false)
+		if err != nil {
+			return nil, err
+		}
+		if !canGlobalOp {
+			userUid = currentUserUid
+		}
+	}
+
+	sessions, err := d.AuthLoginSessionUsecase.ListLoginSessions(ctx, currentUserUid, userUid)
+	if err != nil {
+		return nil, fmt.Errorf("list login sessions failed: %w", err)
+	}
+
+	userNames := make(map[string]string)
+	ret := make([]*dmsV1.LoginSession, 0, len(sessions))
+	for _, s := range sessions {
+		name, ok := userNames[s.UserUID]
+		if !ok {
+			if user, err := d.UserUsecase.GetUser(ctx, s.UserUID); err == nil {
+				name = user.Name
+			} else {
+				d.log.Warnf("get user %s of login session failed: %v", s.UserUID, err)
+			}
+			userNames[s.UserUID] = name
+		}
+		ret = append(ret, convertBizLoginSession(s, name, currentSessionID))
+	}
+
+	return &dmsV1.ListLoginSessionsReply{
+		Data: ret,
+	}, nil
+}
+
+func convertBizLoginSession(s *biz.LoginSession, userName, currentSessionID string) *dmsV1.LoginSession {
+	item := &dmsV1.LoginSession{
+		SessionUid: s.UID,
+		UserUid:    s.UserUID,
+		UserName:   userName,
+		LoginType:  s.LoginType,
+		IP:         s.IP,
+		UserAgent:  s.UserAgent,
+		CreatedAt:  s.CreatedAt.Format("2006-01-02T15:04:05-07:00"),
+		ExpiredAt:  s.ExpiredAt.Format("2006-01-02T15:04:05-07:00"),
+		IsCurrent:  s.UID == currentSessionID,
+	}
+	if !s.LastSeenAt.IsZero() {
+		item.LastSeenAt = s.LastSeenAt.Format("2006-01-02T15:04:05-07:00")
+	}
+	return item
+}
+
+func (d *DMSService) RevokeLoginSession(ctx context.Context, currentUserUid string, req *dmsV1.RevokeLoginSessionReq) (userName string, err error) {
+	d.log.Infof("RevokeLoginSession.req=%v", req)
+	defer func() {
+		d.log.Infof("RevokeLoginSession.req=%v;error=%v", req, err)
+	}()
+
+	session, err := d.AuthLoginSessionUsecase.RevokeLoginSession(ctx, currentUserUid, req.SessionUid)
+	if err != nil {
+		return "", fmt.Errorf("revoke login session failed: %w", err)
+	}
+	user, getErr := d.UserUsecase.GetUser(ctx, session.UserUID)
+	if getErr != nil {
+		d.log.Warnf("get user %s of login session failed: %v", session.UserUID, getErr)
+		return "", nil
+	}
+	return user.Name, nil
+}
@@ -3,6 +3,8 @@ package service
 import (
 	"context"
 	"fmt"
+	"time"
+
 	"github.com/actiontech/dms/internal/pkg/locale"
 
 	dmsV1 "github.com/actiontech/dms/api/dms/service/v1"
@@ -38,7 +40,7 @@ func (d *DMSService) AddMember(ctx context.Context, currentUserUid string, req *
 		})
 	}
 
-	uid, err := d.MemberUsecase.CreateMember(ctx, currentUserUid, req.Member.UserUid, req.ProjectUid, req.Member.IsProjectAdmin, roles, req.Member.ProjectManagePermissions)
+	uid, err := d.MemberUsecase.CreateMember(ctx, currentUserUid, req.Member.UserUid, req.ProjectUid, req.Member.IsProjectAdmin, roles, req.Member.ProjectManagePermissions, convertApiExpiresAt(req.Member.ExpiresAt))
 	if err != nil {
 		return nil, fmt.Errorf("create member failed: %w", err)
 	}
@@ -51,6 +53,20 @@ func (d *DMSService) AddMember(ctx context.Context, currentUserUid string, req *
 	}, nil
 }
 
+func convertApiExpiresAt(expiresAt *time.Time) time.Time {
+	if expiresAt == nil {
+		return time.Time{}
+	}
+	return *expiresAt
+}
+
+func convertBizExpiresAt(expiresAt time.Time) *time.Time {
+	if expiresAt.IsZero() {
+		return nil
+	}
+	return &expiresAt
+}
+
 func (d *DMSService) ListMemberTips(ctx context.Context, projectId string) (reply *dmsV1.ListMemberTipsReply, err error) {
 	members, err := d.OpPermissionVerifyUsecase.ListUsersInProject(ctx, projectId)
 	if nil != err {
@@ -168,7 +184,7 @@ func (d *DMSService) ListMembers(ctx context.Context, req *dmsV1.ListMemberReq,
 			isGroupMember = true
 		}
 
-		roleWithOpRanges, err := d.buildRoleWithOpRanges(ctx, m.RoleWithOpRanges, nil)
+		roleWithOpRanges, err := d.buildRoleWithOpRanges(ctx, req.ProjectUid, m.RoleWithOpRanges, nil)
 		if err != nil {
 			return nil, err
 		}
@@ -177,7 +193,7 @@ func (d *DMSService) ListMembers(ctx context.Context, req *dmsV1.ListMemberReq,
 		memberGroupRoleWithOpRanges := make([]dmsV1.ListMemberRoleWithOpRange, 0)
 		// 转换所有用户组的RoleWithOpRanges
 		for _, memberGroup := range memberGroups {
-			memberRoleWithOpRanges, err := d.buildRoleWithOpRanges(ctx, memberGroup.RoleWithOpRanges, memberGroup)
+			memberRoleWithOpRanges, err := d.buildRoleWithOpRanges(ctx, req.ProjectUid, memberGroup.RoleWithOpRanges, memberGroup)
 			if err != nil {
 				return nil, err
 			}
@@ -212,6 +228,7 @@ func (d *DMSService) ListMembers(ctx context.Context, req *dmsV1.ListMemberReq,
 			CurrentProjectOpPermissions: projectOpPermissions,
 			CurrentProjectAdmin: d.buildMemberCurrentProjectAdmin(m, memberGroups),
 			CurrentProjectManagePermissions: projectManagePermissions,
+			ExpiresAt:                       convertBizExpiresAt(m.ExpiresAt),
 		}
 
 		for _, r := range m.RoleWithOpRanges {
@@ -346,7 +363,7 @@ func (d *DMSService) UpdateMember(ctx context.Context, currentUserUid string, re
 	}
 
 	if err = d.MemberUsecase.UpdateMember(ctx, currentUserUid, req.MemberUid, pkgConst.UIDOfProjectDefault, /*暂时只支持默认project*/
-		req.Member.IsProjectAdmin, roles, req.Member.ProjectManagePermissions); nil != err {
+		req.Member.IsProjectAdmin, roles, req.Member.ProjectManagePermissions, convertApiExpiresAt(req.Member.ExpiresAt)); nil != err {
 		return fmt.Errorf("update member failed: %v", err)
 	}
 
@@ -390,29 +407,15 @@ func (d *DMSService) ListMembersForInternal(ctx context.Context, req *dmsCommonV
 		var opPermission []dmsCommonV1.OpPermissionItem
 
 		for _, op := range m.OpPermissions {
-			opTyp, err := convertBizOpPermission(op.OpPermissionUID)
-			if err != nil {
-				return nil, fmt.Errorf("get user op permission error: %v", err)
-			}
-			dmsCommonOpTyp, err := dmsCommonV1.ParseOpPermissionType(string(opTyp))
+			item, ok, err := convertOpPermissionItem(op)
 			if err != nil {
-				return nil, fmt.Errorf("get dms common user op permission error: %v", err)
-			}
-
-			rangeTyp, err := convertBizOpRangeType(op.OpRangeType)
-			if err != nil {
-				return nil, fmt.Errorf("get user op range type error: %v", err)
+				return nil, err
 			}
-			dmsCommonRangeTyp, err := dmsCommonV1.ParseOpRangeType(string(rangeTyp))
-			if err != nil {
-				return nil, fmt.Errorf("get dms common user op range type error: %v", err)
+			if !ok {
+				d.log.Debugf("skip op permission %v not defined in dms common", op.OpPermissionUID)
+				continue
 			}
-
-			opPermission = append(opPermission, dmsCommonV1.OpPermissionItem{
-				OpPermissionType: dmsCommonOpTyp,
-				RangeType:        dmsCommonRangeTyp,
-				RangeUids:        op.RangeUIDs,
-			})
+			opPermission = append(opPermission, item)
 		}
 
 		isAdmin, err := d.OpPermissionVerifyUsecase.IsUserProjectAdmin(ctx, m.UserUid, req.ProjectUid, true)
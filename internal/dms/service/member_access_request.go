@@ -0,0 +1,119 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	dmsV1 "github.com/actiontech/dms/api/dms/service/v1"
+	"github.com/actiontech/dms/internal/dms/biz"
+)
+
+func (d *DMSService) AddMemberAccessRequest(ctx context.Context, currentUserUid string, req *dmsV1.AddMemberAccessRequestReq) (reply *dmsV1.AddMemberAccessRequestReply, err error) {
+	d.log.Infof("AddMemberAccessRequest.req=%v", req)
+	defer func() {
+		d.log.Infof("AddMemberAccessRequest.req=%v;reply=%v;error=%v", req, reply, err)
+	}()
+
+	roles := make([]biz.MemberRoleWithOpRange, 0, len(req.AccessRequest.RoleWithOpRanges))
+	for _, r := range req.AccessRequest.RoleWithOpRanges {
+		typ, err := biz.ParseOpRangeType(string(r.OpRangeType))
+		if err != nil {
+			return nil, fmt.Errorf("parse op range type failed: %v", err)
+		}
+		roles = append(roles, biz.MemberRoleWithOpRange{
+			RoleUID:     r.RoleUID,
+			OpRangeType: typ,
+			RangeUIDs:   r.RangeUIDs,
+		})
+	}
+
+	uid, err := d.MemberAccessRequestUsecase.CreateMemberAccessRequest(ctx, currentUserUid, &biz.CreateMemberAccessRequestArgs{
+		ProjectUID:       req.ProjectUid,
+		RoleWithOpRanges: roles,
+		DurationHours:    req.AccessRequest.DurationHours,
+		Reason:           req.AccessRequest.Reason,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("create member access request failed: %w", err)
+	}
+
+	reply = &dmsV1.AddMemberAccessRequestReply{}
+	reply.Data.Uid = uid
+	return reply, nil
+}
+
+func (d *DMSService) ListMemberAccessRequests(ctx context.Context, currentUserUid string, req *dmsV1.ListMemberAccessRequestsReq) (reply *dmsV1.ListMemberAccessRequestsReply, err error) {
+	requests, err := d.MemberAccessRequestUsecase.ListMemberAccessRequests(ctx, currentUserUid, &biz.ListMemberAccessRequestsOption{
+		ProjectUID:     req.ProjectUid,
+		FilterByStatus: biz.MemberAccessRequestStatus(req.FilterByStatus),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("list member access requests failed: %v", err)
+	}
+
+	ret := make([]*dmsV1.MemberAccessRequest, 0, len(requests))
+	for _, r := range requests {
+		roleWithOpRanges, err := d.buildRoleWithOpRanges(ctx, req.ProjectUid, r.RoleWithOpRanges, nil)
+		if err != nil {
+			return nil, err
+		}
+		item := &dmsV1.MemberAccessRequest{
+			Uid:              r.UID,
+			User:             dmsV1.UidWithName{Uid: r.UserUID, Name: d.getUserNameOrUid(ctx, r.UserUID)},
+			RoleWithOpRanges: roleWithOpRanges,
+			DurationHours:    r.DurationHours,
+			Reason:           r.Reason,
+			Status:           dmsV1.MemberAccessRequestStatus(r.Status),
+			ReviewComment:    r.ReviewComment,
+			MemberGroupUid:   r.MemberGroupUID,
+			CreatedAt:        r.CreatedAt,
+		}
+		if r.ReviewerUID != "" {
+			item.Reviewer = &dmsV1.UidWithName{Uid: r.ReviewerUID, Name: d.getUserNameOrUid(ctx, r.ReviewerUID)}
+			item.ReviewedAt = convertBizExpiresAt(r.ReviewedAt)
+		}
+		if r.Status == biz.MemberAccessRequestStatusApproved {
+			item.ExpiresAt = convertBizExpiresAt(r.ReviewedAt.Add(time.Duration(r.DurationHours) * time.Hour))
+		}
+		ret = append(ret, item)
+	}
+
+	return &dmsV1.ListMemberAccessRequestsReply{
+		Data:  ret,
+		Total: int64(len(ret)),
+	}, nil
+}
+
+func (d *DMSService) getUserNameOrUid(ctx context.Context, userUid string) string {
+	user, err := d.UserUsecase.GetUser(ctx, userUid)
+	if err != nil {
+		d.log.Warnf("get user %s failed: %v", userUid, err)
+		return userUid
+	}
+	return user.Name
+}
+
+func (d *DMSService) ApproveMemberAccessRequest(ctx context.Context, currentUserUid string, req *dmsV1.ReviewMemberAccessRequestReq) (err error) {
+	d.log.Infof("ApproveMemberAccessRequest.req=%v", req)
+	defer func() {
+		d.log.Infof("ApproveMemberAccessRequest.req=%v;error=%v", req, err)
+	}()
+
+	if _, err := d.MemberAccessRequestUsecase.ApproveMemberAccessRequest(ctx, currentUserUid, req.ProjectUid, req.AccessRequestUid, req.Comment); err != nil {
+		return fmt.Errorf("approve member access request failed: %w", err)
+	}
+	return nil
+}
+
+func (d *DMSService) RejectMemberAccessRequest(ctx context.Context, currentUserUid string, req *dmsV1.ReviewMemberAccessRequestReq) (err error) {
+	d.log.Infof("RejectMemberAccessRequest.req=%v", req)
+	defer func() {
+		d.log.Infof("RejectMemberAccessRequest.req=%v;error=%v", req, err)
+	}()
+
+	if _, err := d.MemberAccessRequestUsecase.RejectMemberAccessRequest(ctx, currentUserUid, req.ProjectUid, req.AccessRequestUid, req.Comment); err != nil {
+		return fmt.Errorf("reject member access request failed: %w", err)
+	}
+	return nil
+}
@@ -3,11 +3,13 @@ package service
 import (
 	"context"
 	"fmt"
+	"strings"
 
 	dmsV1 "github.com/actiontech/dms/api/dms/service/v1"
 	"github.com/actiontech/dms/internal/dms/biz"
 	pkgConst "github.com/actiontech/dms/internal/dms/pkg/constant"
 	"github.com/actiontech/dms/internal/pkg/locale"
+	sqlop "github.com/actiontech/dms/pkg/dms-common/sql_op"
 )
 
 func (d *DMSService) ListMemberGroups(ctx context.Context, req *dmsV1.ListMemberGroupsReq, currentUserId string) (reply *dmsV1.ListMemberGroupsReply, err error) {
@@ -76,7 +78,7 @@ func (d *DMSService) ListMemberGroups(ctx context.Context, req *dmsV1.ListMember
 			})
 		}
 
-		roleWithOpRanges, err := d.buildRoleWithOpRanges(ctx, memberGroup.RoleWithOpRanges, memberGroup)
+		roleWithOpRanges, err := d.buildRoleWithOpRanges(ctx, req.ProjectUid, memberGroup.RoleWithOpRanges, memberGroup)
 		if err != nil {
 			return nil, err
 		}
@@ -99,6 +101,8 @@ func (d *DMSService) ListMemberGroups(ctx context.Context, req *dmsV1.ListMember
 			RoleWithOpRanges: roleWithOpRanges,
 			CurrentProjectOpPermissions: projectOpPermissions,
 			CurrentProjectManagePermissions: projectManagePermissions,
+			ExpiresAt:                       convertBizExpiresAt(memberGroup.ExpiresAt),
+			UserGroup:                       convertBizBoundUserGroup(memberGroup),
 		}
 
 		ret = append(ret, item)
@@ -110,7 +114,7 @@ func (d *DMSService) ListMemberGroups(ctx context.Context, req *dmsV1.ListMember
 	}, nil
 }
 
-func (d *DMSService) buildRoleWithOpRanges(ctx context.Context, roleWithOpRanges []biz.MemberRoleWithOpRange, memberGroup *biz.MemberGroup) ([]dmsV1.ListMemberRoleWithOpRange, error) {
+func (d *DMSService) buildRoleWithOpRanges(ctx context.Context, projectUid string, roleWithOpRanges []biz.MemberRoleWithOpRange, memberGroup *biz.MemberGroup) ([]dmsV1.ListMemberRoleWithOpRange, error) {
 	ret := make([]dmsV1.ListMemberRoleWithOpRange, 0, len(roleWithOpRanges))
 
 	// 遍历成员的角色&权限范围用于展示
@@ -143,6 +147,22 @@ func (d *DMSService) buildRoleWithOpRanges(ctx context.Context, roleWithOpRanges
 					}
 					rangeUidWithNames = append(rangeUidWithNames, dmsV1.UidWithName{Uid: dbService.GetUID(), Name: dbService.Name})
 				}
+			case biz.OpRangeTypeDatabase, biz.OpRangeTypeSchema, biz.OpRangeTypeTable:
+				if uid != "" {
+					objectRange, err := sqlop.DecodeObjectRange(uid)
+					if err != nil {
+						return nil, fmt.Errorf("decode object range failed: %v", err)
+					}
+					dbService, err := d.DBServiceUsecase.GetDBService(ctx, objectRange.DBServiceUID)
+					if err != nil {
+						return nil, fmt.Errorf("get db service failed: %v", err)
+					}
+					rangeUidWithNames = append(rangeUidWithNames, dmsV1.UidWithName{Uid: uid, Name: buildObjectRangeName(dbService.Name, objectRange)})
+				}
+			case biz.OpRangeTypeDBServiceSelector:
+				if uid != "" {
+					rangeUidWithNames = append(rangeUidWithNames, dmsV1.UidWithName{Uid: uid, Name: uid})
+				}
 			// 成员目前只支持配置数据源范围的权限
 			case biz.OpRangeTypeProject, biz.OpRangeTypeGlobal:
 				//return nil, fmt.Errorf("member currently only support the db service op range type, but got type: %v", r.OpRangeType)
@@ -150,6 +170,22 @@ func (d *DMSService) buildRoleWithOpRanges(ctx context.Context, roleWithOpRanges
 				return nil, fmt.Errorf("unsupported op range type: %v", r.OpRangeType)
 			}
 		}
+		// 数据源选择器同时展示当前解析出的数据源
+		var resolvedRangeUidWithNames []dmsV1.UidWithName
+		if r.OpRangeType == biz.OpRangeTypeDBServiceSelector {
+			dbServiceUids, err := d.OpPermissionVerifyUsecase.ResolveDBServiceSelectors(ctx, projectUid, r.RangeUIDs)
+			if err != nil {
+				return nil, fmt.Errorf("resolve db service selectors failed: %v", err)
+			}
+			resolvedRangeUidWithNames = make([]dmsV1.UidWithName, 0, len(dbServiceUids))
+			for _, dbServiceUid := range dbServiceUids {
+				dbService, err := d.DBServiceUsecase.GetDBService(ctx, dbServiceUid)
+				if err != nil {
+					return nil, fmt.Errorf("get db service failed: %v", err)
+				}
+				resolvedRangeUidWithNames = append(resolvedRangeUidWithNames, dmsV1.UidWithName{Uid: dbService.GetUID(), Name: dbService.Name})
+			}
+		}
 		if role.UID == pkgConst.UIDOfRoleProjectAdmin || role.UID == pkgConst.UIDOfRoleDevEngineer || role.UID == pkgConst.UIDOfRoleDevManager || role.UID == pkgConst.UIDOfRoleOpsEngineer {
 			// built in role, localize name and desc
 			role.Name = locale.Bundle.LocalizeMsgByCtx(ctx, RoleNameByUID[role.GetUID()])
@@ -159,7 +195,7 @@ func (d *DMSService) buildRoleWithOpRanges(ctx context.Context, roleWithOpRanges
 		for _, permission := range role.OpPermissions {
 			opPermissions = append(opPermissions, dmsV1.UidWithName{
 				Uid:  permission.GetUID(),
-				Name: locale.Bundle.LocalizeMsgByCtx(ctx, OpPermissionNameByUID[permission.GetUID()]),
+				Name: localizeOpPermission(ctx, permission),
 			})
 		}
 		memberGroupOpPermissions := make([]dmsV1.UidWithName, 0)
@@ -173,7 +209,7 @@ func (d *DMSService) buildRoleWithOpRanges(ctx context.Context, roleWithOpRanges
 				for _, permission := range memberRole.OpPermissions {
 					memberGroupOpPermissions = append(memberGroupOpPermissions, dmsV1.UidWithName{
 						Uid:  permission.GetUID(),
-						Name: locale.Bundle.LocalizeMsgByCtx(ctx, OpPermissionNameByUID[permission.GetUID()]),
+						Name: localizeOpPermission(ctx, permission),
 					})
 				}
 			}
@@ -182,6 +218,7 @@ func (d *DMSService) buildRoleWithOpRanges(ctx context.Context, roleWithOpRanges
 			RoleUID:     dmsV1.UidWithName{Uid: role.GetUID(), Name: role.Name},
 			OpRangeType: opRangeTyp,
 			RangeUIDs:   rangeUidWithNames,
+			ResolvedRangeUIDs: resolvedRangeUidWithNames,
 			OpPermissions: opPermissions,
 			MemberGroup: convert2ProjectMemberGroup(memberGroup, memberGroupOpPermissions),
 		})
@@ -190,6 +227,17 @@ func (d *DMSService) buildRoleWithOpRanges(ctx context.Context, roleWithOpRanges
 	return ret, nil
 }
 
+// buildObjectRangeName 生成对象级别权限范围的展示名称，如 db_service.database.schema.table
+func buildObjectRangeName(dbServiceName string, objectRange *sqlop.ObjectRange) string {
+	names := []string{dbServiceName}
+	for _, name := range []string{objectRange.DatabaseName, objectRange.SchemaName, objectRange.TableName} {
+		if name != "" {
+			names = append(names, name)
+		}
+	}
+	return strings.Join(names, ".")
+}
+
 func (d *DMSService) GetMemberGroup(ctx context.Context, req *dmsV1.GetMemberGroupReq) (reply *dmsV1.GetMemberGroupReply, err error) {
 	memberGroup, err := d.MemberGroupUsecase.GetMemberGroup(ctx, req.MemberGroupUid, req.ProjectUid)
 	if err != nil {
@@ -209,7 +257,7 @@ func (d *DMSService) GetMemberGroup(ctx context.Context, req *dmsV1.GetMemberGro
 		})
 	}
 
-	roleWithOpRanges, err := d.buildRoleWithOpRanges(ctx, memberGroup.RoleWithOpRanges, memberGroup)
+	roleWithOpRanges, err := d.buildRoleWithOpRanges(ctx, req.ProjectUid, memberGroup.RoleWithOpRanges, memberGroup)
 	if err != nil {
 		return nil, err
 	}
@@ -220,6 +268,8 @@ func (d *DMSService) GetMemberGroup(ctx context.Context, req *dmsV1.GetMemberGro
 		IsProjectAdmin:   isAdmin,
 		Users:            users,
 		RoleWithOpRanges: roleWithOpRanges,
+		ExpiresAt:        convertBizExpiresAt(memberGroup.ExpiresAt),
+		UserGroup:        convertBizBoundUserGroup(memberGroup),
 	}
 
 	if err != nil {
@@ -260,6 +310,8 @@ func (d *DMSService) AddMemberGroup(ctx context.Context, currentUserUid string,
 		UserUids:         req.MemberGroup.UserUids,
 		RoleWithOpRanges: roles,
 		ProjectManagePermissions: req.MemberGroup.ProjectManagePermissions,
+		ExpiresAt:                convertApiExpiresAt(req.MemberGroup.ExpiresAt),
+		UserGroupUID:             req.MemberGroup.UserGroupUid,
 	}
 
 	uid, err := d.MemberGroupUsecase.CreateMemberGroup(ctx, currentUserUid, params)
@@ -305,6 +357,8 @@ func (d *DMSService) UpdateMemberGroup(ctx context.Context, currentUserUid strin
 		UserUids:         req.MemberGroup.UserUids,
 		RoleWithOpRanges: roles,
 		ProjectManagePermissions: req.MemberGroup.ProjectManagePermissions,
+		ExpiresAt:                convertApiExpiresAt(req.MemberGroup.ExpiresAt),
+		UserGroupUID:             req.MemberGroup.UserGroupUid,
 	}
 
 	err = d.MemberGroupUsecase.UpdateMemberGroup(ctx, currentUserUid, params)
@@ -330,3 +384,10 @@ func (d *DMSService) DeleteMemberGroup(ctx context.Context, currentUserUid strin
 
 	return nil
 }
+
+func convertBizBoundUserGroup(memberGroup *biz.MemberGroup) *dmsV1.UidWithName {
+	if memberGroup.UserGroupUID == "" {
+		return nil
+	}
+	return &dmsV1.UidWithName{Uid: memberGroup.UserGroupUID, Name: memberGroup.UserGroupName}
+}
@@ -0,0 +1,65 @@
+package service
+
+import (
+	"context"
+	"io"
+	"testing"
+
+	"github.com/actiontech/dms/internal/dms/biz"
+	pkgConst "github.com/actiontech/dms/internal/dms/pkg/constant"
+	dmsCommonV1 "github.com/actiontech/dms/pkg/dms-common/api/dms/v1"
+	utilLog "github.com/actiontech/dms/pkg/dms-common/pkg/log"
+	"github.com/stretchr/testify/assert"
+)
+
+type mockOpPermissionVerifyRepo struct {
+	biz.OpPermissionVerifyRepo
+	items       []biz.ListMembersOpPermissionItem
+	permissions []biz.OpPermissionWithOpRange
+}
+
+func (m *mockOpPermissionVerifyRepo) ListUsersOpPermissionInProject(context.Context, string, *biz.ListMembersOpPermissionOption) ([]biz.ListMembersOpPermissionItem, int64, error) {
+	return m.items, int64(len(m.items)), nil
+}
+
+func (m *mockOpPermissionVerifyRepo) IsUserHasOpPermissionInProject(context.Context, string, string, string) (bool, error) {
+	return false, nil
+}
+
+func (m *mockOpPermissionVerifyRepo) GetUserGlobalOpPermission(context.Context, string) ([]*biz.OpPermission, error) {
+	return nil, nil
+}
+
+func (m *mockOpPermissionVerifyRepo) GetUserOpPermissionInProject(context.Context, string, string) ([]biz.OpPermissionWithOpRange, error) {
+	return m.permissions, nil
+}
+
+func (m *mockOpPermissionVerifyRepo) GetUserProjectOpPermissionInProject(context.Context, string, string) ([]biz.OpPermissionWithOpRange, error) {
+	return nil, nil
+}
+
+func TestListMembersForInternalSkipsServiceOpPermissions(t *testing.T) {
+	logger := utilLog.NewMyLogger(io.Discard)
+	// 内置角色「开发工程师」同时包含 dms 权限和服务注册的 job_run 权限
+	repo := &mockOpPermissionVerifyRepo{items: []biz.ListMembersOpPermissionItem{{
+		UserUid:  "user_1",
+		UserName: "dev",
+		OpPermissions: []biz.OpPermissionWithOpRange{
+			{OpPermissionUID: pkgConst.UIDOfOpPermissionCreateWorkflow, OpRangeType: biz.OpRangeTypeDBService, RangeUIDs: []string{"db_1"}},
+			{OpPermissionUID: "job_run", OpRangeType: biz.OpRangeTypeDBService, RangeUIDs: []string{"db_1"}},
+		},
+	}}}
+	d := &DMSService{
+		OpPermissionVerifyUsecase: biz.NewOpPermissionVerifyUsecase(logger, nil, repo, nil),
+		log:                       utilLog.NewHelper(logger, utilLog.WithMessageKey("test")),
+	}
+
+	reply, err := d.ListMembersForInternal(context.Background(), &dmsCommonV1.ListMembersForInternalReq{ProjectUid: "project_1", PageIndex: 1, PageSize: 10})
+	assert.NoError(t, err)
+	assert.Len(t, reply.Data, 1)
+	assert.Equal(t, []dmsCommonV1.OpPermissionItem{{
+		OpPermissionType: dmsCommonV1.OpPermissionTypeCreateWorkflow,
+		RangeType:        dmsCommonV1.OpRangeTypeDBService,
+		RangeUids:        []string{"db_1"},
+	}}, reply.Data[0].MemberOpPermissionList)
+}
@@ -11,6 +11,7 @@ import (
 
 	dmsV1 "github.com/actiontech/dms/api/dms/service/v1"
 	"github.com/actiontech/dms/internal/dms/biz"
+	sqlop "github.com/actiontech/dms/pkg/dms-common/sql_op"
 )
 
 var OpPermissionNameByUID = map[string]*i18n.Message{
@@ -184,22 +185,12 @@ func (d *DMSService) ListOpPermissions(ctx context.Context, req *dmsV1.ListOpPer
 			return nil, fmt.Errorf("parse op range type failed: %v", err)
 		}
 
-		opPermissionName := o.Name
-		if msg, ok := OpPermissionNameByUID[o.GetUID()]; ok && msg != nil {
-			opPermissionName = locale.Bundle.LocalizeMsgByCtx(ctx, msg)
-		}
-
-		opPermissionDesc := ""
-		if msg, ok := OpPermissionDescByUID[o.GetUID()]; ok && msg != nil {
-			opPermissionDesc = locale.Bundle.LocalizeMsgByCtx(ctx, msg)
-		}
-
 		ret[i] = &dmsV1.ListOpPermission{
 			OpPermission: dmsV1.UidWithName{
 				Uid:  o.GetUID(),
-				Name: opPermissionName,
+				Name: localizeOpPermission(ctx, o),
 			},
-			Description: opPermissionDesc,
+			Description: localizeOpPermissionDesc(ctx, o),
 			RangeType:   opRangeTyp,
 			Module:      string(o.Module),
 			Service:     o.Service,
@@ -210,3 +201,118 @@ func (d *DMSService) ListOpPermissions(ctx context.Context, req *dmsV1.ListOpPer
 		Data: ret, Total: total,
 	}, nil
 }
+
+func (d *DMSService) ExplainPermission(ctx context.Context, currentUserUid string, req *dmsV1.ExplainPermissionReq) (reply *dmsV1.ExplainPermissionReply, err error) {
+	// 用户可以查看自己的权限判定，查看他人的需要项目成员管理的查看权限
+	if currentUserUid != req.UserUid {
+		hasPermission, err := d.OpPermissionVerifyUsecase.HasViewPermission(ctx, currentUserUid, req.ProjectUid, pkgConst.UIdOfOpPermissionManageMember)
+		if err != nil {
+			return nil, fmt.Errorf("check user has permission manage member: %v", err)
+		}
+		if !hasPermission {
+			return nil, fmt.Errorf("no permission to explain other user's permission")
+		}
+	}
+
+	explanation, err := d.OpPermissionVerifyUsecase.ExplainPermission(ctx, &biz.ExplainPermissionArgs{
+		UserUID:         req.UserUid,
+		ProjectUID:      req.ProjectUid,
+		DBServiceUID:    req.DBServiceUid,
+		OpPermissionUID: req.OpPermissionUid,
+		BusinessWrite:   req.BusinessWrite,
+		Objects:         explainPermissionObjects(req),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("explain permission failed: %w", err)
+	}
+
+	reply = &dmsV1.ExplainPermissionReply{}
+	reply.Data.Allowed = explanation.Allowed
+	reply.Data.Steps = make([]*dmsV1.PermissionExplainStep, 0, len(explanation.Steps))
+	for _, step := range explanation.Steps {
+		item := &dmsV1.PermissionExplainStep{
+			Rule:   dmsV1.PermissionExplainRule(step.Rule),
+			Result: dmsV1.PermissionExplainResult(step.Result),
+			Detail: step.Detail,
+		}
+		if source := step.Source; source != nil {
+			item.Source = &dmsV1.PermissionExplainSource{
+				SourceType:   string(source.SourceType),
+				Source:       dmsV1.UidWithName{Uid: source.SourceUID, Name: source.SourceName},
+				OpPermission: dmsV1.UidWithName{Uid: source.OpPermissionUID, Name: localizeOpPermissionName(ctx, source.OpPermissionUID)},
+				OpRangeType:  dmsV1.OpRangeType(source.OpRangeType),
+				RangeUIDs:    source.RangeUIDs,
+			}
+			if source.RoleUID != "" {
+				item.Source.Role = &dmsV1.UidWithName{Uid: source.RoleUID, Name: source.RoleName}
+			}
+		}
+		reply.Data.Steps = append(reply.Data.Steps, item)
+	}
+	return reply, nil
+}
+
+// localizeOpPermission 内置权限使用内置的翻译，注册服务提供的权限使用清单中的多语言名称
+func localizeOpPermission(ctx context.Context, op *biz.OpPermission) string {
+	if msg, ok := OpPermissionNameByUID[op.GetUID()]; ok && msg != nil {
+		return locale.Bundle.LocalizeMsgByCtx(ctx, msg)
+	}
+	if name := op.NameI18n.GetStrInLang(locale.Bundle.GetLangTagFromCtx(ctx)); name != "" {
+		return name
+	}
+	return op.Name
+}
+
+func localizeOpPermissionDesc(ctx context.Context, op *biz.OpPermission) string {
+	if msg, ok := OpPermissionDescByUID[op.GetUID()]; ok && msg != nil {
+		return locale.Bundle.LocalizeMsgByCtx(ctx, msg)
+	}
+	if desc := op.DescI18n.GetStrInLang(locale.Bundle.GetLangTagFromCtx(ctx)); desc != "" {
+		return desc
+	}
+	return op.Desc
+}
+
+func localizeOpPermissionName(ctx context.Context, opPermissionUid string) string {
+	if msg, ok := OpPermissionNameByUID[opPermissionUid]; ok {
+		return locale.Bundle.LocalizeMsgByCtx(ctx, msg)
+	}
+	return opPermissionUid
+}
+
+// GetPermissionCacheStats 返回当前节点的权限缓存统计
+func (d *DMSService) GetPermissionCacheStats(ctx context.Context, currentUserUid string) (*dmsV1.GetPermissionCacheStatsReply, error) {
+	if canGlobalOp, err := d.OpPermissionVerifyUsecase.CanOpGlobal(ctx, currentUserUid, false); err != nil {
+		return nil, fmt.Errorf("check user is admin or global management permission : %v", err)
+	} else if !canGlobalOp {
+		return nil, fmt.Errorf("user is not admin or global management permission")
+	}
+
+	stats, enabled := d.OpPermissionVerifyUsecase.PermissionCacheStats()
+	return &dmsV1.GetPermissionCacheStatsReply{
+		Data: &dmsV1.PermissionCacheStats{
+			Enabled:       enabled,
+			Hits:          stats.Hits,
+			Misses:        stats.Misses,
+			HitRatio:      stats.HitRatio(),
+			Invalidations: stats.Invalidations,
+			Entries:       stats.Entries,
+		},
+	}, nil
+}
+
+// explainPermissionObjects 按指定的最细层级构造访问的对象，未指定时返回空
+func explainPermissionObjects(req *dmsV1.ExplainPermissionReq) []*sqlop.SQLObject {
+	object := &sqlop.SQLObject{DatabaseName: req.Database, SchemaName: req.Schema, TableName: req.Table}
+	switch {
+	case req.Table != "":
+		object.Type = sqlop.SQLObjectTypeTable
+	case req.Schema != "":
+		object.Type = sqlop.SQLObjectTypeSchema
+	case req.Database != "":
+		object.Type = sqlop.SQLObjectTypeDatabase
+	default:
+		return nil
+	}
+	return []*sqlop.SQLObject{object}
+}
@@ -23,6 +23,9 @@ func (d *DMSService) RegisterDMSPlugin(ctx context.Context, currentUserUid strin
 	}, currentUserUid); err != nil {
 		return fmt.Errorf("register dms plugin failed: %v", err)
 	}
+	if err := d.syncServiceOpPermissions(ctx, req.Plugin.Name, req.Plugin.OpPermissionManifest); err != nil {
+		return err
+	}
 	// 当有plugin注册时，初始化切片，重新调用接口获取数据库选项
 	d.PluginUsecase.ClearDatabaseDriverOptionsCache()
 	return nil
@@ -27,6 +27,9 @@ func (d *DMSService) RegisterDMSProxyTarget(ctx context.Context, currentUserUid
 	}); err != nil {
 		return fmt.Errorf("register dms proxy target failed: %v", err)
 	}
+	if err := d.syncServiceOpPermissions(ctx, req.DMSProxyTarget.Name, req.DMSProxyTarget.OpPermissionManifest); err != nil {
+		return err
+	}
 	return nil
 }
 
@@ -34,7 +34,13 @@ func (d *DMSService) AddRole(ctx context.Context, currentUserUid string, req *dm
 		d.log.Infof("AddRoles.req=%v;reply=%v;error=%v", req, reply, err)
 	}()
 
-	uid, err := d.RoleUsecase.CreateRole(ctx, currentUserUid, req.Role.Name, req.Role.Desc, req.Role.OpPermissionUids)
+	uid, err := d.RoleUsecase.CreateRole(ctx, currentUserUid, &biz.CreateRoleArgs{
+		Name:             req.Role.Name,
+		Desc:             req.Role.Desc,
+		OpPermissionUIDs: req.Role.OpPermissionUids,
+		ParentRoleUIDs:   req.Role.ParentRoleUids,
+		IsTemplate:       req.Role.IsTemplate,
+	})
 	if err != nil {
 		return nil, fmt.Errorf("create role failed: %w", err)
 	}
@@ -54,7 +60,7 @@ func (d *DMSService) UpdateRole(ctx context.Context, currentUserUid string, req
 	}()
 
 	if err = d.RoleUsecase.UpdateRole(ctx, currentUserUid, req.RoleUid, *req.Role.IsDisabled,
-		req.Role.Desc, *req.Role.OpPermissionUids); nil != err {
+		req.Role.Desc, *req.Role.OpPermissionUids, req.Role.ParentRoleUids); nil != err {
 		return fmt.Errorf("update role failed: %v", err)
 	}
 
@@ -98,11 +104,40 @@ func (d *DMSService) ListRoles(ctx context.Context, req *dmsV1.ListRoleReq) (rep
 			Value:    req.FilterByName,
 		})
 	}
+	andConditions = append(andConditions, pkgConst.FilterCondition{
+		Field:    string(biz.RoleFieldIsTemplate),
+		Operator: pkgConst.FilterOperatorEqual,
+		Value:    req.FilterByIsTemplate,
+	})
+	if req.FilterByTemplateUid != "" {
+		andConditions = append(andConditions, pkgConst.FilterCondition{
+			Field:    string(biz.RoleFieldTemplateUID),
+			Operator: pkgConst.FilterOperatorEqual,
+			Value:    req.FilterByTemplateUid,
+		})
+	}
 
 	if len(andConditions) > 0 {
 		filterByOptions.Groups = append(filterByOptions.Groups, pkgConst.NewConditionGroup(pkgConst.FilterLogicAnd, andConditions...))
 	}
 
+	// 项目内角色只在指定项目时展示，查询模板的实例时展示所有项目的实例
+	if req.FilterByTemplateUid == "" {
+		projectConditions := []pkgConst.FilterCondition{{
+			Field:    string(biz.RoleFieldProjectUID),
+			Operator: pkgConst.FilterOperatorEqual,
+			Value:    "",
+		}}
+		if req.FilterByProjectUid != "" {
+			projectConditions = append(projectConditions, pkgConst.FilterCondition{
+				Field:    string(biz.RoleFieldProjectUID),
+				Operator: pkgConst.FilterOperatorEqual,
+				Value:    req.FilterByProjectUid,
+			})
+		}
+		filterByOptions.Groups = append(filterByOptions.Groups, pkgConst.NewConditionGroup(pkgConst.FilterLogicOr, projectConditions...))
+	}
+
 	if req.FuzzyKeyword != "" {
 		filterByOptions.Groups = append(filterByOptions.Groups, pkgConst.NewConditionGroup(
 			pkgConst.FilterLogicOr,
@@ -133,9 +168,17 @@ func (d *DMSService) ListRoles(ctx context.Context, req *dmsV1.ListRoleReq) (rep
 			r.Name = locale.Bundle.LocalizeMsgByCtx(ctx, RoleNameByUID[r.GetUID()])
 		}
 		ret[i] = &dmsV1.ListRole{
-			RoleUid: r.GetUID(),
-			Name:    r.Name,
-			Desc:    r.Desc,
+			RoleUid:    r.GetUID(),
+			Name:       r.Name,
+			Desc:       r.Desc,
+			IsTemplate: r.IsTemplate,
+			ProjectUid: r.ProjectUID,
+		}
+		for _, uid := range r.ParentRoleUIDs {
+			ret[i].ParentRoles = append(ret[i].ParentRoles, dmsV1.UidWithName{Uid: uid, Name: d.getRoleNameOrUid(ctx, uid)})
+		}
+		if r.TemplateUID != "" {
+			ret[i].Template = &dmsV1.UidWithName{Uid: r.TemplateUID, Name: d.getRoleNameOrUid(ctx, r.TemplateUID)}
 		}
 
 		// 获取角色状态
@@ -159,10 +202,15 @@ func (d *DMSService) ListRoles(ctx context.Context, req *dmsV1.ListRoleReq) (rep
 				(op.UID == pkgConst.UIDOfOpPermissionCreateOptimization || op.UID == pkgConst.UIDOfOpPermissionViewOthersOptimization) {
 				continue
 			}
+			// 注册服务不再提供的权限不再展示
+			if op.Removed {
+				continue
+			}
 			ret[i].OpPermissions = append(ret[i].OpPermissions, dmsV1.ListRoleOpPermission{
 				Uid:  op.GetUID(),
-				Name: locale.Bundle.LocalizeMsgByCtx(ctx, OpPermissionNameByUID[op.GetUID()]),
+				Name: localizeOpPermission(ctx, op),
 				Module: string(op.Module),
+				Inherited: !r.OwnsOpPermission(op.GetUID()),
 			})
 		}
 
@@ -172,3 +220,67 @@ func (d *DMSService) ListRoles(ctx context.Context, req *dmsV1.ListRoleReq) (rep
 		Data: ret, Total: total,
 	}, nil
 }
+
+func (d *DMSService) InstantiateRoleTemplate(ctx context.Context, currentUserUid string, req *dmsV1.InstantiateRoleTemplateReq) (reply *dmsV1.InstantiateRoleTemplateReply, err error) {
+	d.log.Infof("InstantiateRoleTemplate.req=%v", req)
+	defer func() {
+		d.log.Infof("InstantiateRoleTemplate.req=%v;reply=%v;error=%v", req, reply, err)
+	}()
+
+	uid, err := d.RoleUsecase.InstantiateRoleTemplate(ctx, currentUserUid, &biz.InstantiateRoleTemplateArgs{
+		TemplateUID:      req.RoleUid,
+		ProjectUID:       req.Instance.ProjectUid,
+		Name:             req.Instance.Name,
+		Desc:             req.Instance.Desc,
+		OpPermissionUIDs: req.Instance.OpPermissionUids,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("instantiate role template failed: %w", err)
+	}
+
+	reply = &dmsV1.InstantiateRoleTemplateReply{}
+	reply.Data.Uid = uid
+	return reply, nil
+}
+
+// PreviewUpdateRole 返回角色变更后自身及继承它的角色生效权限的变化，继承角色中无变化的不返回
+func (d *DMSService) PreviewUpdateRole(ctx context.Context, currentUserUid string, req *dmsV1.PreviewUpdateRoleReq) (*dmsV1.PreviewUpdateRoleReply, error) {
+	plans, err := d.RoleUsecase.PreviewUpdateRole(ctx, currentUserUid, req.RoleUid, *req.Role.OpPermissionUids, req.Role.ParentRoleUids)
+	if err != nil {
+		return nil, fmt.Errorf("preview update role failed: %w", err)
+	}
+
+	ret := make([]*dmsV1.RoleOpPermissionsDiff, 0, len(plans))
+	for i, plan := range plans {
+		added, removed := plan.Diff()
+		if i > 0 && len(added) == 0 && len(removed) == 0 {
+			continue
+		}
+		diff := &dmsV1.RoleOpPermissionsDiff{
+			Role:                 dmsV1.UidWithName{Uid: plan.Role.UID, Name: plan.Role.Name},
+			ProjectUid:           plan.Role.ProjectUID,
+			AddedOpPermissions:   make([]dmsV1.UidWithName, 0, len(added)),
+			RemovedOpPermissions: make([]dmsV1.UidWithName, 0, len(removed)),
+		}
+		if msg, ok := RoleNameByUID[plan.Role.UID]; ok {
+			diff.Role.Name = locale.Bundle.LocalizeMsgByCtx(ctx, msg)
+		}
+		for _, uid := range added {
+			diff.AddedOpPermissions = append(diff.AddedOpPermissions, dmsV1.UidWithName{Uid: uid, Name: d.getOpPermissionNameOrUid(ctx, uid)})
+		}
+		for _, uid := range removed {
+			diff.RemovedOpPermissions = append(diff.RemovedOpPermissions, dmsV1.UidWithName{Uid: uid, Name: d.getOpPermissionNameOrUid(ctx, uid)})
+		}
+		ret = append(ret, diff)
+	}
+	return &dmsV1.PreviewUpdateRoleReply{Data: ret}, nil
+}
+
+func (d *DMSService) getOpPermissionNameOrUid(ctx context.Context, opPermissionUid string) string {
+	op, err := d.OpPermissionUsecase.GetOpPermission(ctx, opPermissionUid)
+	if err != nil {
+		d.log.Warnf("get op permission %s failed: %v", opPermissionUid, err)
+		return localizeOpPermissionName(ctx, opPermissionUid)
+	}
+	return localizeOpPermission(ctx, op)
+}
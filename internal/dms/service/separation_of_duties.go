@@ -0,0 +1,119 @@
+package service
+
+import (
+	"context"
+	"fmt"
+
+	dmsV1 "github.com/actiontech/dms/api/dms/service/v1"
+	"github.com/actiontech/dms/internal/dms/biz"
+)
+
+func (d *DMSService) AddSeparationOfDutiesRule(ctx context.Context, currentUserUid string, req *dmsV1.AddSeparationOfDutiesRuleReq) (reply *dmsV1.AddSeparationOfDutiesRuleReply, err error) {
+	d.log.Infof("AddSeparationOfDutiesRule.req=%v", req)
+	defer func() {
+		d.log.Infof("AddSeparationOfDutiesRule.req=%v;reply=%v;error=%v", req, reply, err)
+	}()
+
+	typ, err := biz.ParseSeparationOfDutiesRuleType(string(req.Rule.Type))
+	if err != nil {
+		return nil, err
+	}
+	uid, err := d.SeparationOfDutiesUsecase.CreateSeparationOfDutiesRule(ctx, currentUserUid, &biz.SeparationOfDutiesRule{
+		ProjectUID:       req.ProjectUid,
+		Type:             typ,
+		OpPermissionUIDs: req.Rule.OpPermissionUids,
+		WorkflowType:     biz.SeparationOfDutiesWorkflowType(req.Rule.WorkflowType),
+		Desc:             req.Rule.Desc,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("create separation of duties rule failed: %w", err)
+	}
+
+	reply = &dmsV1.AddSeparationOfDutiesRuleReply{}
+	reply.Data.Uid = uid
+	return reply, nil
+}
+
+func (d *DMSService) DelSeparationOfDutiesRule(ctx context.Context, currentUserUid string, req *dmsV1.DelSeparationOfDutiesRuleReq) (err error) {
+	d.log.Infof("DelSeparationOfDutiesRule.req=%v", req)
+	defer func() {
+		d.log.Infof("DelSeparationOfDutiesRule.req=%v;error=%v", req, err)
+	}()
+
+	if err := d.SeparationOfDutiesUsecase.DelSeparationOfDutiesRule(ctx, currentUserUid, req.ProjectUid, req.RuleUid); err != nil {
+		return fmt.Errorf("delete separation of duties rule failed: %w", err)
+	}
+	return nil
+}
+
+func (d *DMSService) ListSeparationOfDutiesRules(ctx context.Context, currentUserUid string, req *dmsV1.ListSeparationOfDutiesRulesReq) (*dmsV1.ListSeparationOfDutiesRulesReply, error) {
+	rules, err := d.SeparationOfDutiesUsecase.ListSeparationOfDutiesRules(ctx, currentUserUid, req.ProjectUid)
+	if err != nil {
+		return nil, fmt.Errorf("list separation of duties rules failed: %w", err)
+	}
+
+	ret := make([]*dmsV1.SeparationOfDutiesRule, 0, len(rules))
+	for _, rule := range rules {
+		ret = append(ret, d.convertBizSeparationOfDutiesRule(ctx, rule))
+	}
+	return &dmsV1.ListSeparationOfDutiesRulesReply{
+		Data:  ret,
+		Total: int64(len(ret)),
+	}, nil
+}
+
+func (d *DMSService) ListSeparationOfDutiesViolations(ctx context.Context, currentUserUid string, req *dmsV1.ListSeparationOfDutiesViolationsReq) (*dmsV1.ListSeparationOfDutiesViolationsReply, error) {
+	violations, err := d.SeparationOfDutiesUsecase.ListSeparationOfDutiesViolations(ctx, currentUserUid, req.ProjectUid)
+	if err != nil {
+		return nil, fmt.Errorf("list separation of duties violations failed: %w", err)
+	}
+
+	rules := make(map[string]*dmsV1.SeparationOfDutiesRule)
+	ret := make([]*dmsV1.SeparationOfDutiesViolation, 0, len(violations))
+	for _, v := range violations {
+		rule, ok := rules[v.Rule.UID]
+		if !ok {
+			rule = d.convertBizSeparationOfDutiesRule(ctx, v.Rule)
+			rules[v.Rule.UID] = rule
+		}
+		userName := v.UserName
+		if userName == "" {
+			userName = d.getUserNameOrUid(ctx, v.UserUID)
+		}
+		item := &dmsV1.SeparationOfDutiesViolation{
+			Rule: rule,
+			User: dmsV1.UidWithName{Uid: v.UserUID, Name: userName},
+		}
+		for _, uid := range v.DBServiceUIDs {
+			name := uid
+			if dbService, err := d.DBServiceUsecase.GetDBService(ctx, uid); err == nil {
+				name = dbService.Name
+			}
+			item.DBServices = append(item.DBServices, dmsV1.UidWithName{Uid: uid, Name: name})
+		}
+		if v.Workflow != nil {
+			item.Workflow = &dmsV1.UidWithName{Uid: v.Workflow.WorkflowUID, Name: v.Workflow.WorkflowName}
+			item.ApprovedAt = v.Workflow.OperateAt
+		}
+		ret = append(ret, item)
+	}
+	return &dmsV1.ListSeparationOfDutiesViolationsReply{
+		Data:  ret,
+		Total: int64(len(ret)),
+	}, nil
+}
+
+func (d *DMSService) convertBizSeparationOfDutiesRule(ctx context.Context, rule *biz.SeparationOfDutiesRule) *dmsV1.SeparationOfDutiesRule {
+	ret := &dmsV1.SeparationOfDutiesRule{
+		Uid:          rule.UID,
+		Type:         dmsV1.SeparationOfDutiesRuleType(rule.Type),
+		WorkflowType: dmsV1.SeparationOfDutiesWorkflowType(rule.WorkflowType),
+		Desc:         rule.Desc,
+		CreateUser:   dmsV1.UidWithName{Uid: rule.CreateUserUID, Name: d.getUserNameOrUid(ctx, rule.CreateUserUID)},
+		CreatedAt:    rule.CreatedAt,
+	}
+	for _, uid := range rule.OpPermissionUIDs {
+		ret.OpPermissions = append(ret.OpPermissions, dmsV1.UidWithName{Uid: uid, Name: localizeOpPermissionName(ctx, uid)})
+	}
+	return ret
+}
@@ -48,6 +48,16 @@ type DMSService struct {
 	FunctionSupportRegistry     *biz.FunctionSupportRegistry
 	AuthAccessTokenUseCase      *biz.AuthAccessTokenUsecase
 	AuthLoginSessionUsecase     *biz.AuthLoginSessionUsecase
+	ServiceAccountUsecase       *biz.ServiceAccountUsecase
+	MemberAccessRequestUsecase  *biz.MemberAccessRequestUsecase
+	SeparationOfDutiesUsecase   *biz.SeparationOfDutiesUsecase
+	AccessReviewUsecase         *biz.AccessReviewUsecase
+	DataExportScheduleUsecase   *biz.DataExportScheduleUsecase
+	DataExportQuotaUsecase      *biz.DataExportQuotaUsecase
+	DataExportDeliveryUsecase   *biz.DataExportDeliveryUsecase
+	WorkflowApprovalSLAUsecase  *biz.WorkflowApprovalSLAUsecase
+	DataExportPreviewUsecase    *biz.DataExportPreviewUsecase
+	ServiceOpPermissionUsecase  *biz.ServiceOpPermissionUsecase
 	SwaggerUseCase              *biz.SwaggerUseCase
 	GatewayUsecase              *biz.GatewayUsecase
 	SystemVariableUsecase       *biz.SystemVariableUsecase
@@ -113,15 +123,19 @@ func NewAndInitDMSService(logger utilLog.Logger, opts *conf.DMSOptions) (*DMSSer
 	}
 
 	userUsecase := biz.NewUserUsecase(logger, tx, userRepo, userGroupRepo, pluginUseCase, opPermissionUsecase, opPermissionVerifyUsecase, loginConfigurationUsecase, ldapConfigurationUsecase, cloudbeaverRepo, gatewayUsecase)
-	userGroupUsecase := biz.NewUserGroupUsecase(logger, tx, userGroupRepo, userRepo, pluginUseCase, opPermissionVerifyUsecase)
 	roleRepo := storage.NewRoleRepo(logger, st)
 	memberRepo := storage.NewMemberRepo(logger, st)
-	roleUsecase := biz.NewRoleUsecase(logger, tx, roleRepo, opPermissionRepo, memberRepo, pluginUseCase, opPermissionVerifyUsecase)
+	workflowRepo := storage.NewWorkflowRepo(logger, st)
+	separationOfDutiesUsecase := biz.NewSeparationOfDutiesUsecase(logger, storage.NewSeparationOfDutiesRepo(logger, st), roleRepo, opPermissionRepo, workflowRepo, opPermissionVerifyUsecase)
+	userUsecase.SetSeparationOfDutiesUsecase(separationOfDutiesUsecase)
+	userGroupUsecase := biz.NewUserGroupUsecase(logger, tx, userGroupRepo, userRepo, pluginUseCase, opPermissionVerifyUsecase, separationOfDutiesUsecase)
+	roleUsecase := biz.NewRoleUsecase(logger, tx, roleRepo, opPermissionRepo, memberRepo, pluginUseCase, opPermissionVerifyUsecase, separationOfDutiesUsecase)
+	serviceOpPermissionUsecase := biz.NewServiceOpPermissionUsecase(logger, tx, storage.NewServiceOpPermissionRepo(logger, st), opPermissionRepo, roleRepo, roleUsecase, opPermissionVerifyUsecase)
 	dmsConfigRepo := storage.NewDMSConfigRepo(logger, st)
 	dmsConfigUsecase := biz.NewDMSConfigUseCase(logger, dmsConfigRepo)
-	memberUsecase = *biz.NewMemberUsecase(logger, tx, memberRepo, userUsecase, roleUsecase, dbServiceUseCase, opPermissionVerifyUsecase, projectUsecase, pluginUseCase)
+	memberUsecase = *biz.NewMemberUsecase(logger, tx, memberRepo, userUsecase, roleUsecase, dbServiceUseCase, opPermissionVerifyUsecase, projectUsecase, pluginUseCase, separationOfDutiesUsecase)
 	memberGroupRepo := storage.NewMemberGroupRepo(logger, st)
-	memberGroupUsecase := biz.NewMemberGroupUsecase(logger, tx, memberGroupRepo, userUsecase, roleUsecase, dbServiceUseCase, opPermissionVerifyUsecase, projectUsecase, &memberUsecase, pluginUseCase)
+	memberGroupUsecase := biz.NewMemberGroupUsecase(logger, tx, memberGroupRepo, userUsecase, roleUsecase, dbServiceUseCase, opPermissionVerifyUsecase, projectUsecase, &memberUsecase, pluginUseCase, separationOfDutiesUsecase)
 	dmsProxyUsecase, err := biz.NewDmsProxyUsecase(logger, dmsProxyTargetRepo, opts.APIServiceOpts, opPermissionUsecase, roleUsecase)
 	if err != nil {
 		return nil, fmt.Errorf("failed to new dms proxy usecase: %v", err)
@@ -146,6 +160,7 @@ func NewAndInitDMSService(logger utilLog.Logger, opts *conf.DMSOptions) (*DMSSer
 	basicUsecase := biz.NewBasicInfoUsecase(logger, dmsProxyUsecase, basicConfigRepo)
 	clusterRepo := storage.NewClusterRepo(logger, st)
 	clusterUsecase := biz.NewClusterUsecase(logger, tx, clusterRepo)
+	opPermissionVerifyUsecase.SetPermissionCache(biz.NewPermissionCache(logger, storage.NewPermissionCacheVersionRepo(logger, st), clusterUsecase))
 	licenseRepo := storage.NewLicenseRepo(logger, st)
 	LicenseUsecase := biz.NewLicenseUsecase(logger, tx, licenseRepo, userUsecase, dbServiceUseCase, clusterUsecase)
 	dataExportTaskRepo := storage.NewDataExportTaskRepo(logger, st)
@@ -160,14 +175,15 @@ func NewAndInitDMSService(logger utilLog.Logger, opts *conf.DMSOptions) (*DMSSer
 	userActivityUsecase := biz.NewUserActivityUsecase(logger, userActivityRepo, systemVariableUsecase)
 	cbOperationRepo := storage.NewCbOperationLogRepo(logger, st)
 	CbOperationLogUsecase := biz.NewCbOperationLogUsecase(logger, cbOperationRepo, opPermissionVerifyUsecase, dmsProxyTargetRepo, systemVariableUsecase)
-	workflowRepo := storage.NewWorkflowRepo(logger, st)
 	dataExportMaskingConfigRepo := initDataExportMaskingConfigRepo(logger, st)
 	dataExportMaskingRuleRepo := initDataExportMaskingRuleRepo(logger, st)
 	unmaskingWorkflowUsecase, err := initUnmaskingWorkflowUsecase(logger, st, dmsProxyTargetRepo, opPermissionVerifyUsecase, userUsecase, dbServiceUseCase)
 	if err != nil {
 		return nil, fmt.Errorf("failed to initialize unmasking workflow usecase: %v", err)
 	}
-	DataExportWorkflowUsecase := biz.NewDataExportWorkflowUsecase(logger, tx, workflowRepo, dataExportTaskRepo, dbServiceRepo, dataExportMaskingConfigRepo, dataExportMaskingRuleRepo, opPermissionVerifyUsecase, projectUsecase, &opsTypeUsecase, dmsProxyTargetRepo, clusterUsecase, webhookConfigurationUsecase, userUsecase, systemVariableUsecase, dbServiceUseCase, unmaskingWorkflowUsecase, fmt.Sprintf("%s:%d", opts.ReportHost, opts.APIServiceOpts.Port))
+	dataExportQuotaUsecase := biz.NewDataExportQuotaUsecase(logger, storage.NewDataExportQuotaRepo(logger, st), dbServiceRepo, opPermissionVerifyUsecase)
+	dataExportDeliveryUsecase := biz.NewDataExportDeliveryUsecase(logger, storage.NewDataExportDeliveryTargetRepo(logger, st), opPermissionVerifyUsecase)
+	DataExportWorkflowUsecase := biz.NewDataExportWorkflowUsecase(logger, tx, workflowRepo, dataExportTaskRepo, dbServiceRepo, dataExportMaskingConfigRepo, dataExportMaskingRuleRepo, opPermissionVerifyUsecase, projectUsecase, &opsTypeUsecase, dmsProxyTargetRepo, clusterUsecase, webhookConfigurationUsecase, userUsecase, systemVariableUsecase, dbServiceUseCase, unmaskingWorkflowUsecase, dataExportQuotaUsecase, storage.NewDataExportWatermarkRepo(logger, st), storage.NewDataExportDownloadRepo(logger, st), dataExportDeliveryUsecase, separationOfDutiesUsecase, fmt.Sprintf("%s:%d", opts.ReportHost, opts.APIServiceOpts.Port))
 	dataMaskingUsecase, stopDataMaskingScheduler, err := initDataMaskingUsecase(logger, st, dbServiceUseCase, clusterUsecase, dmsProxyTargetRepo)
 	if err != nil {
 		return nil, fmt.Errorf("failed to initialize data masking usecase: %v", err)
@@ -180,8 +196,16 @@ func NewAndInitDMSService(logger utilLog.Logger, opts *conf.DMSOptions) (*DMSSer
 
 	authAccessTokenUsecase := biz.NewAuthAccessTokenUsecase(logger, userUsecase)
 	authLoginSessionUsecase := biz.NewAuthLoginSessionUsecase(logger, userUsecase, loginConfigurationUsecase)
+	serviceAccountRepo := storage.NewServiceAccountRepo(logger, st)
+	serviceAccountUsecase := biz.NewServiceAccountUsecase(logger, tx, serviceAccountRepo, userUsecase)
+	memberAccessRequestRepo := storage.NewMemberAccessRequestRepo(logger, st)
+	memberAccessRequestUsecase := biz.NewMemberAccessRequestUsecase(logger, memberAccessRequestRepo, userUsecase, &memberUsecase, memberGroupUsecase, projectUsecase, opPermissionVerifyUsecase, operationRecordUsecase, clusterUsecase)
 
-	cronTask := biz.NewCronTaskUsecase(logger, DataExportWorkflowUsecase, CbOperationLogUsecase, operationRecordUsecase, userActivityUsecase, oauth2SessionUsecase)
+	accessReviewUsecase := biz.NewAccessReviewUsecase(logger, storage.NewAccessReviewRepo(logger, st), userUsecase, &memberUsecase, memberGroupUsecase, projectUsecase, userActivityUsecase, opPermissionVerifyUsecase, operationRecordUsecase, clusterUsecase)
+	dataExportScheduleUsecase := biz.NewDataExportScheduleUsecase(logger, storage.NewDataExportScheduleRepo(logger, st), DataExportWorkflowUsecase, dbServiceUseCase, userUsecase, projectUsecase, clusterUsecase, opPermissionVerifyUsecase)
+	workflowApprovalSLAUsecase := biz.NewWorkflowApprovalSLAUsecase(logger, storage.NewWorkflowApprovalSLARepo(logger, st), workflowRepo, userUsecase, projectUsecase, opPermissionVerifyUsecase, clusterUsecase)
+	dataExportPreviewUsecase := biz.NewDataExportPreviewUsecase(logger, storage.NewDataExportPreviewRepo(logger, st), workflowRepo, dataExportTaskRepo, dbServiceRepo, opPermissionVerifyUsecase)
+	cronTask := biz.NewCronTaskUsecase(logger, DataExportWorkflowUsecase, CbOperationLogUsecase, operationRecordUsecase, userActivityUsecase, oauth2SessionUsecase, memberAccessRequestUsecase, accessReviewUsecase, opPermissionVerifyUsecase, dataExportScheduleUsecase, workflowApprovalSLAUsecase)
 	err = cronTask.InitialTask()
 	if err != nil {
 		return nil, fmt.Errorf("failed to new cron task: %v", err)
@@ -224,6 +248,16 @@ func NewAndInitDMSService(logger utilLog.Logger, opts *conf.DMSOptions) (*DMSSer
 		FunctionSupportRegistry:     functionSupportRegistry,
 		AuthAccessTokenUseCase:      authAccessTokenUsecase,
 		AuthLoginSessionUsecase:     authLoginSessionUsecase,
+		ServiceAccountUsecase:       serviceAccountUsecase,
+		MemberAccessRequestUsecase:  memberAccessRequestUsecase,
+		SeparationOfDutiesUsecase:   separationOfDutiesUsecase,
+		AccessReviewUsecase:         accessReviewUsecase,
+		DataExportScheduleUsecase:   dataExportScheduleUsecase,
+		DataExportQuotaUsecase:      dataExportQuotaUsecase,
+		DataExportDeliveryUsecase:   dataExportDeliveryUsecase,
+		WorkflowApprovalSLAUsecase:  workflowApprovalSLAUsecase,
+		DataExportPreviewUsecase:    dataExportPreviewUsecase,
+		ServiceOpPermissionUsecase:  serviceOpPermissionUsecase,
 		SwaggerUseCase:              swaggerUseCase,
 		GatewayUsecase:              gatewayUsecase,
 		SystemVariableUsecase:       systemVariableUsecase,
@@ -0,0 +1,146 @@
+package service
+
+import (
+	"context"
+	"fmt"
+
+	dmsV1 "github.com/actiontech/dms/api/dms/service/v1"
+	"github.com/actiontech/dms/internal/dms/biz"
+	"github.com/actiontech/dms/internal/pkg/locale"
+	dmsCommonV1 "github.com/actiontech/dms/pkg/dms-common/api/dms/v1"
+)
+
+func (d *DMSService) AddServiceAccount(ctx context.Context, currentUserUid string, req *dmsV1.AddServiceAccountReq) (reply *dmsV1.ServiceAccountCredentialReply, err error) {
+	d.log.Infof("AddServiceAccount.req=%v", req)
+	defer func() {
+		d.log.Infof("AddServiceAccount.req=%v;reply=%v;error=%v", req, reply, err)
+	}()
+
+	sa, secret, err := d.ServiceAccountUsecase.CreateServiceAccount(ctx, currentUserUid, &biz.CreateServiceAccountArgs{
+		Name:             req.ServiceAccount.Name,
+		Desc:             req.ServiceAccount.Desc,
+		OpPermissionUIDs: req.ServiceAccount.OpPermissionUids,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("create service account failed: %w", err)
+	}
+	return newServiceAccountCredentialReply(sa, secret), nil
+}
+
+func newServiceAccountCredentialReply(sa *biz.ServiceAccount, secret string) *dmsV1.ServiceAccountCredentialReply {
+	reply := &dmsV1.ServiceAccountCredentialReply{}
+	reply.Data.Uid = sa.UID
+	reply.Data.ClientID = sa.ClientID
+	reply.Data.ClientSecret = secret
+	return reply
+}
+
+func (d *DMSService) ListServiceAccounts(ctx context.Context, currentUserUid string) (reply *dmsV1.ListServiceAccountsReply, err error) {
+	sas, err := d.ServiceAccountUsecase.ListServiceAccounts(ctx, currentUserUid)
+	if err != nil {
+		return nil, fmt.Errorf("list service accounts failed: %w", err)
+	}
+
+	ret := make([]*dmsV1.ServiceAccount, 0, len(sas))
+	for _, sa := range sas {
+		item := &dmsV1.ServiceAccount{
+			Uid:             sa.UID,
+			UserUid:         sa.UserUID,
+			Name:            sa.User.Name,
+			Desc:            sa.User.Desc,
+			ClientID:        sa.ClientID,
+			SecretRotatedAt: sa.SecretRotatedAt.Format("2006-01-02T15:04:05-07:00"),
+			CreatedAt:       sa.CreatedAt.Format("2006-01-02T15:04:05-07:00"),
+		}
+		switch sa.User.Stat {
+		case biz.UserStatOK:
+			item.Stat = dmsCommonV1.Stat(locale.Bundle.LocalizeMsgByCtx(ctx, locale.StatOK))
+		case biz.UserStatDisable:
+			item.Stat = dmsCommonV1.Stat(locale.Bundle.LocalizeMsgByCtx(ctx, locale.StatDisable))
+		default:
+			item.Stat = dmsCommonV1.Stat(locale.Bundle.LocalizeMsgByCtx(ctx, locale.StatUnknown))
+		}
+
+		ops, err := d.UserUsecase.GetUserOpPermissions(ctx, sa.UserUID)
+		if err != nil {
+			return nil, err
+		}
+		for _, op := range ops {
+			item.OpPermissions = append(item.OpPermissions, dmsCommonV1.UidWithName{
+				Uid:  op.GetUID(),
+				Name: locale.Bundle.LocalizeMsgByCtx(ctx, OpPermissionNameByUID[op.GetUID()]),
+			})
+		}
+		ret = append(ret, item)
+	}
+
+	return &dmsV1.ListServiceAccountsReply{
+		Data: ret,
+	}, nil
+}
+
+func (d *DMSService) UpdateServiceAccount(ctx context.Context, currentUserUid string, req *dmsV1.UpdateServiceAccountReq) (err error) {
+	d.log.Infof("UpdateServiceAccount.req=%v", req)
+	defer func() {
+		d.log.Infof("UpdateServiceAccount.req=%v;error=%v", req, err)
+	}()
+
+	if err := d.ServiceAccountUsecase.UpdateServiceAccount(ctx, currentUserUid, req.ServiceAccountUid, &biz.UpdateServiceAccountArgs{
+		IsDisabled:       *req.ServiceAccount.IsDisabled,
+		OpPermissionUIDs: req.ServiceAccount.OpPermissionUids,
+	}); err != nil {
+		return fmt.Errorf("update service account failed: %w", err)
+	}
+	return nil
+}
+
+func (d *DMSService) RotateServiceAccountSecret(ctx context.Context, currentUserUid string, req *dmsV1.ServiceAccountUidReq) (reply *dmsV1.ServiceAccountCredentialReply, err error) {
+	d.log.Infof("RotateServiceAccountSecret.req=%v", req)
+	defer func() {
+		d.log.Infof("RotateServiceAccountSecret.req=%v;reply=%v;error=%v", req, reply, err)
+	}()
+
+	sa, secret, err := d.ServiceAccountUsecase.RotateServiceAccountSecret(ctx, currentUserUid, req.ServiceAccountUid)
+	if err != nil {
+		return nil, fmt.Errorf("rotate service account secret failed: %w", err)
+	}
+	return newServiceAccountCredentialReply(sa, secret), nil
+}
+
+func (d *DMSService) DelServiceAccount(ctx context.Context, currentUserUid string, req *dmsV1.ServiceAccountUidReq) (err error) {
+	d.log.Infof("DelServiceAccount.req=%v", req)
+	defer func() {
+		d.log.Infof("DelServiceAccount.req=%v;error=%v", req, err)
+	}()
+
+	if err := d.ServiceAccountUsecase.DelServiceAccount(ctx, currentUserUid, req.ServiceAccountUid); err != nil {
+		return fmt.Errorf("delete service account failed: %w", err)
+	}
+	return nil
+}
+
+// GetServiceAccountName 用于记录操作日志
+func (d *DMSService) GetServiceAccountName(ctx context.Context, currentUserUid, uid string) (string, error) {
+	sa, err := d.ServiceAccountUsecase.GetServiceAccount(ctx, currentUserUid, uid)
+	if err != nil {
+		return "", err
+	}
+	return sa.User.Name, nil
+}
+
+func (d *DMSService) IssueServiceAccountToken(ctx context.Context, req *dmsCommonV1.IssueServiceAccountTokenReq) (reply *dmsCommonV1.IssueServiceAccountTokenReply, userUid, userName string, err error) {
+	d.log.Infof("IssueServiceAccountToken.req=%v", req)
+	defer func() {
+		d.log.Infof("IssueServiceAccountToken.req=%v;reply=%v;error=%v", req, reply, err)
+	}()
+
+	sa, token, _, err := d.ServiceAccountUsecase.IssueToken(ctx, req.ClientID, req.ClientSecret)
+	if err != nil {
+		return nil, "", "", err
+	}
+
+	reply = &dmsCommonV1.IssueServiceAccountTokenReply{}
+	reply.Data.Token = token
+	reply.Data.ExpiresIn = int64(biz.ServiceAccountTokenTTL.Seconds())
+	return reply, sa.UserUID, sa.User.Name, nil
+}
@@ -0,0 +1,56 @@
+package service
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/actiontech/dms/internal/dms/biz"
+	dmsV1 "github.com/actiontech/dms/pkg/dms-common/api/dms/v1"
+	"github.com/actiontech/dms/pkg/dms-common/i18nPkg"
+)
+
+// syncServiceOpPermissions 注册服务携带权限清单时同步权限，未携带时保持已有权限不变，兼容旧版本服务
+func (d *DMSService) syncServiceOpPermissions(ctx context.Context, serviceName string, manifest *dmsV1.OpPermissionManifest) error {
+	if manifest == nil {
+		return nil
+	}
+	m, err := convertServiceOpPermissionManifest(serviceName, manifest)
+	if err != nil {
+		return err
+	}
+	if err := d.ServiceOpPermissionUsecase.SyncServiceOpPermissions(ctx, m); err != nil {
+		return fmt.Errorf("sync op permissions of %s failed: %w", serviceName, err)
+	}
+	return nil
+}
+
+func convertServiceOpPermissionManifest(serviceName string, manifest *dmsV1.OpPermissionManifest) (*biz.ServiceOpPermissionManifest, error) {
+	ret := &biz.ServiceOpPermissionManifest{
+		ServiceName:   serviceName,
+		Version:       manifest.Version,
+		OpPermissions: make([]*biz.ServiceOpPermission, 0, len(manifest.OpPermissions)),
+	}
+	for _, op := range manifest.OpPermissions {
+		name, err := i18nPkg.ConvertStrMap2I18nStr(op.Name)
+		if err != nil {
+			return nil, fmt.Errorf("invalid name of op permission %s: %v", op.Uid, err)
+		}
+		desc, err := i18nPkg.ConvertStrMap2I18nStr(op.Desc)
+		if err != nil {
+			return nil, fmt.Errorf("invalid desc of op permission %s: %v", op.Uid, err)
+		}
+		rangeType, err := biz.ParseOpRangeType(op.RangeType)
+		if err != nil {
+			return nil, fmt.Errorf("invalid range type of op permission %s: %v", op.Uid, err)
+		}
+		ret.OpPermissions = append(ret.OpPermissions, &biz.ServiceOpPermission{
+			UID:             op.Uid,
+			Name:            name,
+			Desc:            desc,
+			Module:          op.Module,
+			RangeType:       rangeType,
+			BuiltinRoleUIDs: op.BuiltinRoleUids,
+		})
+	}
+	return ret, nil
+}
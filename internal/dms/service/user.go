@@ -2,6 +2,7 @@ package service
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"strconv"
 	"strings"
@@ -14,7 +15,6 @@ import (
 	dmsCommonV1 "github.com/actiontech/dms/pkg/dms-common/api/dms/v1"
 
 	jwtPkg "github.com/actiontech/dms/pkg/dms-common/api/jwt"
-	"github.com/golang-jwt/jwt/v4"
 )
 
 func (d *DMSService) VerifyUserLogin(ctx context.Context, req *dmsV1.VerifyUserLoginReq) (reply *dmsV1.VerifyUserLoginReply, err error) {
@@ -28,6 +28,14 @@ func (d *DMSService) VerifyUserLogin(ctx context.Context, req *dmsV1.VerifyUserL
 	if nil != err {
 		verifyFailedMsg = err.Error()
 	}
+
+	reply = &dmsV1.VerifyUserLoginReply{}
+	reply.Data.UserUid = uid
+	reply.Data.Phone = phone
+	reply.Data.TwoFactorEnabled = twoFactorEnabled
+	reply.Data.VerifyFailedMsg = verifyFailedMsg
+	reply.Data.AccountLocked = errors.Is(err, biz.ErrUserLoginLocked)
+
 	// TODO: 这里应该只根据twoFactorEnabled判断是否执行验证码校验
 	// 目前这个VerifyUserLogin方法被controller层的VerifyUserLogin调用，前侧不传递验证码的时候只校验用户名密码
 	if twoFactorEnabled && req.VerifyCode != nil {
@@ -37,29 +45,19 @@ func (d *DMSService) VerifyUserLogin(ctx context.Context, req *dmsV1.VerifyUserL
 			Username: req.UserName,
 		})
 		if !verifyCodeReply.Data.IsVerifyNormally {
-			return &dmsV1.VerifyUserLoginReply{
-				Data: struct {
-					// If verify Successful, return empty string, otherwise return error message
-					VerifyFailedMsg string `json:"verify_failed_msg"`
-					// If verify Successful, return user uid
-					UserUid          string `json:"user_uid"`
-					Phone            string `json:"phone"`
-					TwoFactorEnabled bool   `json:"two_factor_enabled"`
-				}{UserUid: uid, VerifyFailedMsg: verifyCodeReply.Data.VerifyErrorMessage, Phone: phone, TwoFactorEnabled: twoFactorEnabled},
-			}, nil
+			reply.Data.VerifyFailedMsg = verifyCodeReply.Data.VerifyErrorMessage
+			return reply, nil
 		}
 	}
 
-	return &dmsV1.VerifyUserLoginReply{
-		Data: struct {
-			// If verify Successful, return empty string, otherwise return error message
-			VerifyFailedMsg string `json:"verify_failed_msg"`
-			// If verify Successful, return user uid
-			UserUid          string `json:"user_uid"`
-			Phone            string `json:"phone"`
-			TwoFactorEnabled bool   `json:"two_factor_enabled"`
-		}{UserUid: uid, VerifyFailedMsg: verifyFailedMsg, Phone: phone, TwoFactorEnabled: twoFactorEnabled},
-	}, nil
+	if verifyFailedMsg == "" {
+		reply.Data.PasswordExpired, err = d.UserUsecase.IsUserPasswordExpired(ctx, uid)
+		if err != nil {
+			return nil, fmt.Errorf("check user password expired failed: %v", err)
+		}
+	}
+
+	return reply, nil
 }
 
 func (d *DMSService) AfterUserLogin(ctx context.Context, req *dmsV1.AfterUserLoginReq) (err error) {
@@ -96,6 +94,9 @@ func (d *DMSService) AddUser(ctx context.Context, currentUserUid string, req *dm
 	defer func() {
 		d.log.Infof("AddUsers.req=%v;reply=%v;error=%v", req, reply, err)
 	}()
+	if biz.UserAuthenticationType(req.User.UserAuthenticationType) == biz.UserAuthenticationTypeServiceAccount {
+		return nil, fmt.Errorf("service account should be created through the service account api")
+	}
 	// 如果 BusinessWritePermission 为 nil，如果为系统管理员权限，默认有业务写权限
 	businessWritePermission := req.User.BusinessWritePermission
 	if businessWritePermission == nil {
@@ -356,6 +357,8 @@ func (d *DMSService) ListUsers(ctx context.Context, req *dmsCommonV1.ListUserReq
 			ret[i].AuthenticationType = dmsCommonV1.UserAuthenticationTypeLDAP
 		case biz.UserAuthenticationTypeOAUTH2:
 			ret[i].AuthenticationType = dmsCommonV1.UserAuthenticationTypeOAUTH2
+		case biz.UserAuthenticationTypeServiceAccount:
+			ret[i].AuthenticationType = dmsCommonV1.UserAuthenticationTypeServiceAccount
 		default:
 			ret[i].AuthenticationType = dmsCommonV1.UserAuthenticationTypeUnknown
 		}
@@ -545,30 +548,15 @@ func (d *DMSService) GetUserOpPermission(ctx context.Context, req *dmsCommonV1.G
 
 	var replyOpPermission = make([]dmsCommonV1.OpPermissionItem, 0, len(permissions))
 	for _, p := range permissions {
-
-		opTyp, err := convertBizOpPermission(p.OpPermissionUID)
+		item, ok, err := convertOpPermissionItem(p)
 		if err != nil {
-			return nil, fmt.Errorf("get user op permission error: %v", err)
-		}
-		dmsCommonOpTyp, err := dmsCommonV1.ParseOpPermissionType(string(opTyp))
-		if err != nil {
-			return nil, fmt.Errorf("get dms common user op permission error: %v", err)
-		}
-
-		rangeTyp, err := convertBizOpRangeType(p.OpRangeType)
-		if err != nil {
-			return nil, fmt.Errorf("get user op range type error: %v", err)
+			return nil, err
 		}
-		dmsCommonRangeTyp, err := dmsCommonV1.ParseOpRangeType(string(rangeTyp))
-		if err != nil {
-			return nil, fmt.Errorf("get dms common user op range type error: %v", err)
+		if !ok {
+			d.log.Debugf("skip op permission %v not defined in dms common", p.OpPermissionUID)
+			continue
 		}
-
-		replyOpPermission = append(replyOpPermission, dmsCommonV1.OpPermissionItem{
-			OpPermissionType: dmsCommonOpTyp,
-			RangeType:        dmsCommonRangeTyp,
-			RangeUids:        p.RangeUIDs,
-		})
+		replyOpPermission = append(replyOpPermission, item)
 	}
 
 	// Get user's BusinessWritePermission for the response
@@ -633,6 +621,8 @@ func (d *DMSService) GetUser(ctx context.Context, req *dmsCommonV1.GetUserReq) (
 		dmsCommonUser.AuthenticationType = dmsCommonV1.UserAuthenticationTypeLDAP
 	case biz.UserAuthenticationTypeOAUTH2:
 		dmsCommonUser.AuthenticationType = dmsCommonV1.UserAuthenticationTypeOAUTH2
+	case biz.UserAuthenticationTypeServiceAccount:
+		dmsCommonUser.AuthenticationType = dmsCommonV1.UserAuthenticationTypeServiceAccount
 	default:
 		dmsCommonUser.AuthenticationType = dmsCommonV1.UserAuthenticationTypeUnknown
 	}
@@ -738,6 +728,8 @@ func (d *DMSService) GetUser(ctx context.Context, req *dmsCommonV1.GetUserReq) (
 	}
 	accessToken := dmsCommonV1.AccessTokenInfo{}
 	accessToken.AccessToken = tokenInfo.Token
+	accessToken.AccessTokenUid = tokenInfo.UID
+	accessToken.Name = tokenInfo.Name
 	accessToken.ExpiredTime = tokenInfo.ExpiredTime.Format("2006-01-02T15:04:05-07:00")
 	if tokenInfo.ExpiredTime.Before(time.Now()) {
 		accessToken.IsExpired = true
@@ -757,25 +749,155 @@ func (d *DMSService) GenAccessToken(ctx context.Context, currentUserUid string,
 		return nil, err
 	}
 
-	expiredTime := time.Now().Add(time.Duration(days) * 24 * time.Hour)
-	token, err := jwtPkg.GenJwtTokenWithExpirationTime(jwt.NewNumericDate(expiredTime), jwtPkg.WithUserId(currentUserUid), jwtPkg.WithAccessTokenMark(biz.AccessTokenLogin))
+	tokenInfo, err := d.AuthAccessTokenUseCase.GenAccessToken(ctx, currentUserUid, &biz.GenAccessTokenArgs{
+		Name:           req.Name,
+		ExpirationDays: days,
+		ReadOnly:       req.ReadOnly,
+		ProjectUIDs:    req.ProjectUids,
+	})
 	if err != nil {
-		return nil, fmt.Errorf("gen access token failed: %v", err)
-	}
-	if err := d.UserUsecase.SaveAccessToken(ctx, currentUserUid, token, expiredTime); err != nil {
-		return nil, fmt.Errorf("save access token failed: %v", err)
+		return nil, err
 	}
 
 	reply = &dmsCommonV1.GenAccessTokenReply{
 		Data: &dmsCommonV1.AccessTokenInfo{
-			AccessToken: token,
-			ExpiredTime: expiredTime.Format("2006-01-02T15:04:05-07:00"),
+			AccessToken:    tokenInfo.Token,
+			ExpiredTime:    tokenInfo.ExpiredTime.Format("2006-01-02T15:04:05-07:00"),
+			AccessTokenUid: tokenInfo.UID,
+			Name:           tokenInfo.Name,
 		},
 	}
 
 	return reply, nil
 }
 
+func (d *DMSService) ListAccessTokens(ctx context.Context, currentUserUid string) (reply *dmsV1.ListAccessTokensReply, err error) {
+	tokens, err := d.AuthAccessTokenUseCase.ListAccessTokens(ctx, currentUserUid)
+	if err != nil {
+		return nil, fmt.Errorf("list access tokens failed: %v", err)
+	}
+
+	now := time.Now()
+	ret := make([]*dmsV1.ListAccessToken, 0, len(tokens))
+	for _, t := range tokens {
+		item := &dmsV1.ListAccessToken{
+			Uid:         t.UID,
+			Name:        t.Name,
+			ExpiredTime: t.ExpiredTime.Format("2006-01-02T15:04:05-07:00"),
+			IsExpired:   t.IsExpired(now),
+			ReadOnly:    t.ReadOnly,
+			ProjectUids: t.ProjectUIDs,
+			LastUsedIP:  t.LastUsedIP,
+			CreatedAt:   t.CreatedAt.Format("2006-01-02T15:04:05-07:00"),
+		}
+		if !t.LastUsedAt.IsZero() {
+			item.LastUsedAt = t.LastUsedAt.Format("2006-01-02T15:04:05-07:00")
+		}
+		ret = append(ret, item)
+	}
+
+	return &dmsV1.ListAccessTokensReply{
+		Data: ret,
+	}, nil
+}
+
+func (d *DMSService) RevokeAccessToken(ctx context.Context, currentUserUid string, req *dmsV1.RevokeAccessTokenReq) (err error) {
+	d.log.Infof("RevokeAccessToken.req=%v", req)
+	defer func() {
+		d.log.Infof("RevokeAccessToken.req=%v;error=%v", req, err)
+	}()
+
+	if err := d.AuthAccessTokenUseCase.RevokeAccessToken(ctx, currentUserUid, req.AccessTokenUid); err != nil {
+		return fmt.Errorf("revoke access token failed: %v", err)
+	}
+	return nil
+}
+
+// VerifyAccessToken 供其他服务校验 access token，校验失败时通过 VerifyFailedMsg 返回原因
+func (d *DMSService) VerifyAccessToken(ctx context.Context, req *dmsCommonV1.VerifyAccessTokenReq) (reply *dmsCommonV1.VerifyAccessTokenReply, err error) {
+	reply = &dmsCommonV1.VerifyAccessTokenReply{}
+
+	tokenDetail, err := jwtPkg.ParseTokenDetailFromJwtTokenStr(req.Token)
+	if err != nil {
+		reply.Data.VerifyFailedMsg = err.Error()
+		return reply, nil
+	}
+	if tokenDetail.LoginType == biz.ServiceAccountLogin {
+		if err := d.ServiceAccountUsecase.VerifyServiceAccountToken(ctx, tokenDetail); err != nil {
+			reply.Data.VerifyFailedMsg = err.Error()
+			return reply, nil
+		}
+		reply.Data.UserUid = tokenDetail.UID
+		return reply, nil
+	}
+	if tokenDetail.LoginType != biz.AccessTokenLogin {
+		reply.Data.VerifyFailedMsg = "access token login type is error"
+		return reply, nil
+	}
+	tokenInfo, err := d.AuthAccessTokenUseCase.VerifyAccessToken(ctx, tokenDetail)
+	if err != nil {
+		reply.Data.VerifyFailedMsg = err.Error()
+		return reply, nil
+	}
+
+	reply.Data.UserUid = tokenDetail.UID
+	reply.Data.ReadOnly = tokenInfo.ReadOnly
+	reply.Data.ProjectUids = tokenInfo.ProjectUIDs
+	for _, projectUid := range tokenInfo.ProjectUIDs {
+		project, err := d.ProjectUsecase.GetProject(ctx, projectUid)
+		if err != nil {
+			d.log.Warnf("get project %s of access token failed: %v", projectUid, err)
+			continue
+		}
+		reply.Data.ProjectNames = append(reply.Data.ProjectNames, project.Name)
+	}
+	return reply, nil
+}
+
+func (d *DMSService) CheckUserDBObjectsOpPermission(ctx context.Context, req *dmsCommonV1.CheckUserDBObjectsOpPermissionReq) (reply *dmsCommonV1.CheckUserDBObjectsOpPermissionReply, err error) {
+	check := req.DBObjectsOpPermission
+	opPermissionUid, err := pkgConst.ConvertPermissionTypeToId(check.OpPermissionType)
+	if err != nil {
+		return nil, err
+	}
+	allowed, denied, err := d.OpPermissionVerifyUsecase.CheckUserCanOpDBObjects(ctx, req.UserUid, check.ProjectUid, check.DBServiceUid, []string{opPermissionUid}, check.Objects)
+	if err != nil {
+		return nil, fmt.Errorf("check user db objects op permission error: %v", err)
+	}
+
+	reply = &dmsCommonV1.CheckUserDBObjectsOpPermissionReply{}
+	reply.Data.Allowed = allowed
+	reply.Data.DeniedObjects = denied
+	return reply, nil
+}
+
+// convertOpPermissionItem 转换为内部接口返回的权限项，服务注册的权限不属于 dms-common 定义的权限类型，ok 返回 false 由调用方跳过
+func convertOpPermissionItem(p biz.OpPermissionWithOpRange) (item dmsCommonV1.OpPermissionItem, ok bool, err error) {
+	opTyp, err := convertBizOpPermission(p.OpPermissionUID)
+	if err != nil {
+		return item, false, nil
+	}
+	dmsCommonOpTyp, err := dmsCommonV1.ParseOpPermissionType(string(opTyp))
+	if err != nil {
+		return item, false, fmt.Errorf("get dms common user op permission error: %v", err)
+	}
+
+	rangeTyp, err := convertBizOpRangeType(p.OpRangeType)
+	if err != nil {
+		return item, false, fmt.Errorf("get user op range type error: %v", err)
+	}
+	dmsCommonRangeTyp, err := dmsCommonV1.ParseOpRangeType(string(rangeTyp))
+	if err != nil {
+		return item, false, fmt.Errorf("get dms common user op range type error: %v", err)
+	}
+
+	return dmsCommonV1.OpPermissionItem{
+		OpPermissionType: dmsCommonOpTyp,
+		RangeType:        dmsCommonRangeTyp,
+		RangeUids:        p.RangeUIDs,
+	}, true, nil
+}
+
 func convertBizOpPermission(opPermissionUid string) (apiOpPermissionTyp dmsCommonV1.OpPermissionType, err error) {
 	switch opPermissionUid {
 	case pkgConst.UIDOfOpPermissionCreateWorkflow:
@@ -867,6 +989,14 @@ func convertBizOpRangeType(bizOpRangeTyp biz.OpRangeType) (typ dmsV1.OpRangeType
 		typ = dmsV1.OpRangeTypeProject
 	case biz.OpRangeTypeDBService:
 		typ = dmsV1.OpRangeTypeDBService
+	case biz.OpRangeTypeDatabase:
+		typ = dmsV1.OpRangeTypeDatabase
+	case biz.OpRangeTypeSchema:
+		typ = dmsV1.OpRangeTypeSchema
+	case biz.OpRangeTypeTable:
+		typ = dmsV1.OpRangeTypeTable
+	case biz.OpRangeTypeDBServiceSelector:
+		typ = dmsV1.OpRangeTypeDBServiceSelector
 	default:
 		return dmsV1.OpRangeTypeUnknown, fmt.Errorf("get user op range type error: invalid op range type: %v", bizOpRangeTyp)
 	}
@@ -884,3 +1014,15 @@ func convertBizUidWithName(bizUidWithNames []biz.UIdWithName) []dmsV1.UidWithNam
 	}
 	return ret
 }
+
+func (d *DMSService) UnlockUser(ctx context.Context, currentUserUid string, req *dmsV1.UnlockUserReq) (err error) {
+	d.log.Infof("UnlockUser.req=%v", req)
+	defer func() {
+		d.log.Infof("UnlockUser.req=%v;error=%v", req, err)
+	}()
+
+	if err := d.UserUsecase.UnlockUser(ctx, currentUserUid, req.UserUid); err != nil {
+		return fmt.Errorf("unlock user failed: %v", err)
+	}
+	return nil
+}
@@ -0,0 +1,47 @@
+package service
+
+import (
+	"context"
+	"io"
+	"testing"
+
+	"github.com/actiontech/dms/internal/dms/biz"
+	pkgConst "github.com/actiontech/dms/internal/dms/pkg/constant"
+	dmsCommonV1 "github.com/actiontech/dms/pkg/dms-common/api/dms/v1"
+	utilLog "github.com/actiontech/dms/pkg/dms-common/pkg/log"
+	sqlop "github.com/actiontech/dms/pkg/dms-common/sql_op"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCheckUserDBObjectsOpPermission(t *testing.T) {
+	logger := utilLog.NewMyLogger(io.Discard)
+	tableRange := sqlop.EncodeObjectRange(&sqlop.ObjectRange{DBServiceUID: "db_1", DatabaseName: "sales", TableName: "orders"})
+	repo := &mockOpPermissionVerifyRepo{permissions: []biz.OpPermissionWithOpRange{
+		{OpPermissionUID: pkgConst.UIDOfOpPermissionSQLQuery, OpRangeType: biz.OpRangeTypeTable, RangeUIDs: []string{tableRange}, ProjectUID: "project_1"},
+	}}
+	d := &DMSService{
+		OpPermissionVerifyUsecase: biz.NewOpPermissionVerifyUsecase(logger, nil, repo, nil),
+		log:                       utilLog.NewHelper(logger, utilLog.WithMessageKey("test")),
+	}
+
+	orders := &sqlop.SQLObject{Type: sqlop.SQLObjectTypeTable, DatabaseName: "sales", TableName: "orders"}
+	users := &sqlop.SQLObject{Type: sqlop.SQLObjectTypeTable, DatabaseName: "sales", TableName: "users"}
+	check := func(objects ...*sqlop.SQLObject) *dmsCommonV1.CheckUserDBObjectsOpPermissionReply {
+		reply, err := d.CheckUserDBObjectsOpPermission(context.Background(), &dmsCommonV1.CheckUserDBObjectsOpPermissionReq{
+			UserUid: "user_1",
+			DBObjectsOpPermission: &dmsCommonV1.DBObjectsOpPermission{
+				ProjectUid: "project_1", DBServiceUid: "db_1", OpPermissionType: dmsCommonV1.OpPermissionTypeSQLQuery, Objects: objects,
+			},
+		})
+		assert.NoError(t, err)
+		return reply
+	}
+
+	reply := check(orders)
+	assert.True(t, reply.Data.Allowed)
+	assert.Empty(t, reply.Data.DeniedObjects)
+
+	reply = check(orders, users)
+	assert.False(t, reply.Data.Allowed)
+	assert.Equal(t, []*sqlop.SQLObject{users}, reply.Data.DeniedObjects)
+}
@@ -0,0 +1,40 @@
+package service
+
+import (
+	"context"
+	"fmt"
+
+	dmsV1 "github.com/actiontech/dms/api/dms/service/v1"
+	"github.com/actiontech/dms/internal/dms/biz"
+)
+
+func (d *DMSService) SetWorkflowApprovalSLA(ctx context.Context, req *dmsV1.SetWorkflowApprovalSLAReq, currentUserUid string) (err error) {
+	d.log.Infof("SetWorkflowApprovalSLA.req=%v", req)
+	defer func() {
+		d.log.Infof("SetWorkflowApprovalSLA.req=%v;error=%v", req, err)
+	}()
+
+	if err := d.WorkflowApprovalSLAUsecase.SetWorkflowApprovalSLA(ctx, currentUserUid, &biz.WorkflowApprovalSLA{
+		ProjectUID:           req.ProjectUid,
+		RemindAfterHours:     req.ApprovalSLA.RemindAfterHours,
+		EscalateAfterHours:   req.ApprovalSLA.EscalateAfterHours,
+		AutoRejectAfterHours: req.ApprovalSLA.AutoRejectAfterHours,
+	}); err != nil {
+		return fmt.Errorf("set workflow approval sla failed: %w", err)
+	}
+	return nil
+}
+
+func (d *DMSService) GetWorkflowApprovalSLA(ctx context.Context, req *dmsV1.GetWorkflowApprovalSLAReq) (*dmsV1.GetWorkflowApprovalSLAReply, error) {
+	sla, err := d.WorkflowApprovalSLAUsecase.GetWorkflowApprovalSLA(ctx, req.ProjectUid)
+	if err != nil {
+		return nil, fmt.Errorf("get workflow approval sla failed: %w", err)
+	}
+	return &dmsV1.GetWorkflowApprovalSLAReply{
+		Data: &dmsV1.WorkflowApprovalSLA{
+			RemindAfterHours:     sla.RemindAfterHours,
+			EscalateAfterHours:   sla.EscalateAfterHours,
+			AutoRejectAfterHours: sla.AutoRejectAfterHours,
+		},
+	}, nil
+}
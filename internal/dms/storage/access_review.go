@@ -0,0 +1,163 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/actiontech/dms/internal/dms/biz"
+	pkgErr "github.com/actiontech/dms/internal/dms/pkg/errors"
+	"github.com/actiontech/dms/internal/dms/storage/model"
+	utilLog "github.com/actiontech/dms/pkg/dms-common/pkg/log"
+
+	"gorm.io/gorm"
+)
+
+var _ biz.AccessReviewRepo = (*AccessReviewRepo)(nil)
+
+type AccessReviewRepo struct {
+	*Storage
+	log *utilLog.Helper
+}
+
+func NewAccessReviewRepo(log utilLog.Logger, s *Storage) *AccessReviewRepo {
+	return &AccessReviewRepo{Storage: s, log: utilLog.NewHelper(log, utilLog.WithMessageKey("storage.access_review"))}
+}
+
+func (d *AccessReviewRepo) SaveAccessReviewCampaign(ctx context.Context, campaign *biz.AccessReviewCampaign, items []*biz.AccessReviewItem) error {
+	return transaction(d.log, ctx, d.db, func(tx *gorm.DB) error {
+		if err := tx.WithContext(ctx).Create(convertBizAccessReviewCampaign(campaign)).Error; err != nil {
+			return pkgErr.WrapStorageErr(d.log, fmt.Errorf("failed to save access review campaign: %v", err))
+		}
+		if len(items) == 0 {
+			return nil
+		}
+		models := make([]*model.AccessReviewItem, 0, len(items))
+		for _, item := range items {
+			models = append(models, convertBizAccessReviewItem(item))
+		}
+		if err := tx.WithContext(ctx).CreateInBatches(models, 500).Error; err != nil {
+			return pkgErr.WrapStorageErr(d.log, fmt.Errorf("failed to save access review items: %v", err))
+		}
+		return nil
+	})
+}
+
+func (d *AccessReviewRepo) UpdateAccessReviewCampaign(ctx context.Context, campaign *biz.AccessReviewCampaign) error {
+	return transaction(d.log, ctx, d.db, func(tx *gorm.DB) error {
+		if err := tx.WithContext(ctx).Model(&model.AccessReviewCampaign{}).Where("uid = ?", campaign.UID).Updates(map[string]interface{}{
+			"status":       string(campaign.Status),
+			"completed_at": convertBizTimeToModel(campaign.CompletedAt),
+		}).Error; err != nil {
+			return pkgErr.WrapStorageErr(d.log, fmt.Errorf("failed to update access review campaign: %v", err))
+		}
+		return nil
+	})
+}
+
+func (d *AccessReviewRepo) GetAccessReviewCampaign(ctx context.Context, uid string) (*biz.AccessReviewCampaign, error) {
+	var m model.AccessReviewCampaign
+	if err := transaction(d.log, ctx, d.db, func(tx *gorm.DB) error {
+		if err := tx.WithContext(ctx).Where("uid = ?", uid).First(&m).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return pkgErr.ErrStorageNoData
+			}
+			return pkgErr.WrapStorageErr(d.log, fmt.Errorf("failed to get access review campaign: %v", err))
+		}
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+	return convertModelAccessReviewCampaign(&m), nil
+}
+
+func (d *AccessReviewRepo) ListAccessReviewCampaigns(ctx context.Context) ([]*biz.AccessReviewCampaign, error) {
+	return d.listAccessReviewCampaigns(ctx, func(db *gorm.DB) *gorm.DB {
+		return db.Order("created_at DESC")
+	})
+}
+
+func (d *AccessReviewRepo) ListDueAccessReviewCampaigns(ctx context.Context, now time.Time) ([]*biz.AccessReviewCampaign, error) {
+	return d.listAccessReviewCampaigns(ctx, func(db *gorm.DB) *gorm.DB {
+		return db.Where("status = ? AND deadline <= ?", string(biz.AccessReviewCampaignStatusOpen), now)
+	})
+}
+
+func (d *AccessReviewRepo) listAccessReviewCampaigns(ctx context.Context, scope func(db *gorm.DB) *gorm.DB) ([]*biz.AccessReviewCampaign, error) {
+	var models []*model.AccessReviewCampaign
+	if err := transaction(d.log, ctx, d.db, func(tx *gorm.DB) error {
+		if err := scope(tx.WithContext(ctx)).Find(&models).Error; err != nil {
+			return pkgErr.WrapStorageErr(d.log, fmt.Errorf("failed to list access review campaigns: %v", err))
+		}
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+
+	ret := make([]*biz.AccessReviewCampaign, 0, len(models))
+	for _, m := range models {
+		ret = append(ret, convertModelAccessReviewCampaign(m))
+	}
+	return ret, nil
+}
+
+func (d *AccessReviewRepo) GetAccessReviewItem(ctx context.Context, uid string) (*biz.AccessReviewItem, error) {
+	var m model.AccessReviewItem
+	if err := transaction(d.log, ctx, d.db, func(tx *gorm.DB) error {
+		if err := tx.WithContext(ctx).Where("uid = ?", uid).First(&m).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return pkgErr.ErrStorageNoData
+			}
+			return pkgErr.WrapStorageErr(d.log, fmt.Errorf("failed to get access review item: %v", err))
+		}
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+	ret, err := convertModelAccessReviewItem(&m)
+	if err != nil {
+		return nil, pkgErr.WrapStorageErr(d.log, fmt.Errorf("failed to convert access review item: %v", err))
+	}
+	return ret, nil
+}
+
+func (d *AccessReviewRepo) ListAccessReviewItems(ctx context.Context, opt *biz.ListAccessReviewItemsOption) ([]*biz.AccessReviewItem, error) {
+	var models []*model.AccessReviewItem
+	if err := transaction(d.log, ctx, d.db, func(tx *gorm.DB) error {
+		db := tx.WithContext(ctx).Where("campaign_uid = ?", opt.CampaignUID)
+		if opt.FilterByDecision != "" {
+			db = db.Where("decision = ?", string(opt.FilterByDecision))
+		}
+		if err := db.Order("project_name, user_name, id").Find(&models).Error; err != nil {
+			return pkgErr.WrapStorageErr(d.log, fmt.Errorf("failed to list access review items: %v", err))
+		}
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+
+	ret := make([]*biz.AccessReviewItem, 0, len(models))
+	for _, m := range models {
+		item, err := convertModelAccessReviewItem(m)
+		if err != nil {
+			return nil, pkgErr.WrapStorageErr(d.log, fmt.Errorf("failed to convert access review item: %v", err))
+		}
+		ret = append(ret, item)
+	}
+	return ret, nil
+}
+
+func (d *AccessReviewRepo) UpdateAccessReviewItem(ctx context.Context, item *biz.AccessReviewItem) error {
+	return transaction(d.log, ctx, d.db, func(tx *gorm.DB) error {
+		if err := tx.WithContext(ctx).Model(&model.AccessReviewItem{}).Where("uid = ?", item.UID).Updates(map[string]interface{}{
+			"decision":     string(item.Decision),
+			"reviewer_uid": item.ReviewerUID,
+			"comment":      item.Comment,
+			"reviewed_at":  convertBizTimeToModel(item.ReviewedAt),
+		}).Error; err != nil {
+			return pkgErr.WrapStorageErr(d.log, fmt.Errorf("failed to update access review item: %v", err))
+		}
+		return nil
+	})
+}
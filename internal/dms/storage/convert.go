@@ -2,6 +2,7 @@ package storage
 
 import (
 	"database/sql"
+	"encoding/json"
 	"fmt"
 	"net/url"
 	"strings"
@@ -10,10 +11,12 @@ import (
 	v1 "github.com/actiontech/dms/api/dms/service/v1"
 
 	"github.com/actiontech/dms/internal/dms/biz"
+	"github.com/actiontech/dms/internal/dms/pkg/exportdelivery"
 	"github.com/actiontech/dms/internal/dms/storage/model"
 	"github.com/labstack/echo/v4/middleware"
 
 	dmsCommonV1 "github.com/actiontech/dms/pkg/dms-common/api/dms/v1"
+	"github.com/actiontech/dms/pkg/dms-common/i18nPkg"
 	pkgAes "github.com/actiontech/dms/pkg/dms-common/pkg/aes"
 	"github.com/labstack/echo/v4"
 )
@@ -192,6 +195,10 @@ func convertBizUser(u *biz.User) (*model.User, error) {
 		System:                  string(u.System),
 		LastLoginAt:             lastLoginAt,
 		BusinessWritePermission: u.BusinessWritePermission,
+		PasswordChangedAt:       convertBizTimeToModel(u.PasswordChangedAt),
+		LoginFailedCount:        u.LoginFailedCount,
+		LoginFailedAt:           convertBizTimeToModel(u.LoginFailedAt),
+		LockedUntil:             convertBizTimeToModel(u.LockedUntil),
 	}, nil
 }
 
@@ -280,9 +287,27 @@ func convertModelUser(u *model.User) (*biz.User, error) {
 		Password:                decrypted,
 		Deleted:                 u.DeletedAt.Valid,
 		BusinessWritePermission: u.BusinessWritePermission,
+		PasswordChangedAt:       convertModelTimeToBiz(u.PasswordChangedAt),
+		LoginFailedCount:        u.LoginFailedCount,
+		LoginFailedAt:           convertModelTimeToBiz(u.LoginFailedAt),
+		LockedUntil:             convertModelTimeToBiz(u.LockedUntil),
 	}, nil
 }
 
+func convertBizTimeToModel(t time.Time) *time.Time {
+	if t.IsZero() {
+		return nil
+	}
+	return &t
+}
+
+func convertModelTimeToBiz(t *time.Time) time.Time {
+	if t == nil {
+		return time.Time{}
+	}
+	return *t
+}
+
 func convertModelCloudbeaverUser(u *model.CloudbeaverUserCache) *biz.CloudbeaverUser {
 	return &biz.CloudbeaverUser{
 		DMSUserID:         u.DMSUserID,
@@ -347,8 +372,16 @@ func convertModelMemberGroup(mg *model.MemberGroup) (*biz.MemberGroup, error) {
 		})
 	}
 
-	users := make([]biz.UIdWithName, 0, len(mg.Users))
-	for _, user := range mg.Users {
+	groupUsers, userGroupName := mg.Users, ""
+	// 绑定用户组的成员组以用户组当前的用户为准，用户组禁用时没有生效的用户
+	if mg.UserGroup != nil {
+		groupUsers, userGroupName = nil, mg.UserGroup.Name
+		if biz.UserGroupStat(mg.UserGroup.Stat) == biz.UserGroupStatOK {
+			groupUsers = mg.UserGroup.Users
+		}
+	}
+	users := make([]biz.UIdWithName, 0, len(groupUsers))
+	for _, user := range groupUsers {
 		users = append(users, biz.UIdWithName{
 			Uid:  user.UID,
 			Name: user.Name,
@@ -372,6 +405,9 @@ func convertModelMemberGroup(mg *model.MemberGroup) (*biz.MemberGroup, error) {
 		Users:            users,
 		RoleWithOpRanges: roles,
 		OpPermissions:    opPermissions,
+		ExpiresAt:        convertModelTimeToBiz(mg.ExpiresAt),
+		UserGroupUID:     mg.UserGroupUID,
+		UserGroupName:    userGroupName,
 	}, nil
 }
 
@@ -381,9 +417,14 @@ func convertBizRole(u *biz.Role) (*model.Role, error) {
 		Model: model.Model{
 			UID: u.UID,
 		},
-		Name: u.Name,
-		Desc: u.Desc,
-		Stat: u.Stat.Uint(),
+		Name:                u.Name,
+		Desc:                u.Desc,
+		Stat:                u.Stat.Uint(),
+		OwnOpPermissionUIDs: u.OwnOpPermissionUIDs,
+		ParentRoleUIDs:      u.ParentRoleUIDs,
+		IsTemplate:          u.IsTemplate,
+		TemplateUID:         u.TemplateUID,
+		ProjectUID:          u.ProjectUID,
 	}, nil
 }
 
@@ -402,12 +443,17 @@ func convertModelRole(u *model.Role) (*biz.Role, error) {
 	}
 
 	return &biz.Role{
-		Base:          convertBase(u.Model),
-		UID:           u.UID,
-		Name:          u.Name,
-		Desc:          u.Desc,
-		Stat:          stat,
-		OpPermissions: opPermissions,
+		Base:                convertBase(u.Model),
+		UID:                 u.UID,
+		Name:                u.Name,
+		Desc:                u.Desc,
+		Stat:                stat,
+		OpPermissions:       opPermissions,
+		OwnOpPermissionUIDs: u.OwnOpPermissionUIDs,
+		ParentRoleUIDs:      u.ParentRoleUIDs,
+		IsTemplate:          u.IsTemplate,
+		TemplateUID:         u.TemplateUID,
+		ProjectUID:          u.ProjectUID,
 	}, nil
 }
 
@@ -422,6 +468,10 @@ func convertBizOpPermission(u *biz.OpPermission) (*model.OpPermission, error) {
 		RangeType: u.RangeType.String(),
 		Module:    string(u.Module),
 		Service:   string(u.Service),
+		Provider:  u.Provider,
+		NameI18n:  u.NameI18n,
+		DescI18n:  u.DescI18n,
+		Removed:   u.Removed,
 	}, nil
 }
 
@@ -434,6 +484,10 @@ func convertModelOpPermission(u *model.OpPermission) (*biz.OpPermission, error)
 		RangeType: biz.OpRangeType(u.RangeType),
 		Module:    biz.Module(u.Module),
 		Service:   v1.Service(u.Service),
+		Provider:  u.Provider,
+		NameI18n:  u.NameI18n,
+		DescI18n:  u.DescI18n,
+		Removed:   u.Removed,
 	}, nil
 }
 
@@ -481,6 +535,7 @@ func convertBizMember(m *biz.Member) (*model.Member, error) {
 		UserUID:          m.UserUID,
 		ProjectUID:       m.ProjectUID,
 		RoleWithOpRanges: roles,
+		ExpiresAt:        convertBizTimeToModel(m.ExpiresAt),
 	}, nil
 }
 
@@ -514,6 +569,8 @@ func convertBizMemberGroup(m *biz.MemberGroup) *model.MemberGroup {
 		RoleWithOpRanges: roles,
 		Users:            users,
 		OpPermissions:    opPermissions,
+		ExpiresAt:        convertBizTimeToModel(m.ExpiresAt),
+		UserGroupUID:     m.UserGroupUID,
 	}
 }
 
@@ -554,6 +611,7 @@ func convertModelMember(m *model.Member) (*biz.Member, error) {
 		UserUID:          m.UserUID,
 		RoleWithOpRanges: roles,
 		OpPermissions:    opPermissions,
+		ExpiresAt:        convertModelTimeToBiz(m.ExpiresAt),
 	}, nil
 }
 
@@ -701,6 +759,24 @@ func convertBizLoginConfiguration(b *biz.LoginConfiguration) (*model.LoginConfig
 		LoginButtonText:      b.LoginButtonText,
 		DisableUserPwdLogin:  b.DisableUserPwdLogin,
 		DisableMultipleLogin: b.DisableMultipleLogin,
+
+		PasswordMinLength:        b.PasswordMinLength,
+		PasswordRequireUppercase: b.PasswordRequireUppercase,
+		PasswordRequireLowercase: b.PasswordRequireLowercase,
+		PasswordRequireDigit:     b.PasswordRequireDigit,
+		PasswordRequireSpecial:   b.PasswordRequireSpecial,
+		PasswordHistoryCount:     b.PasswordHistoryCount,
+		PasswordMaxAgeDays:       b.PasswordMaxAgeDays,
+		LockoutMaxFailedAttempts: b.LockoutMaxFailedAttempts,
+		LockoutWindowMinutes:     b.LockoutWindowMinutes,
+		LockoutDurationMinutes:   b.LockoutDurationMinutes,
+
+		SessionIdleTimeoutMinutes:      b.SessionIdleTimeoutMinutes,
+		SessionMaxAgeMinutes:           b.SessionMaxAgeMinutes,
+		AdminSessionIdleTimeoutMinutes: b.AdminSessionIdleTimeoutMinutes,
+		AdminSessionMaxAgeMinutes:      b.AdminSessionMaxAgeMinutes,
+		AccessTokenIdleTimeoutDays:     b.AccessTokenIdleTimeoutDays,
+		AccessTokenMaxAgeDays:          b.AccessTokenMaxAgeDays,
 	}, nil
 }
 
@@ -711,6 +787,24 @@ func convertModelLoginConfiguration(m *model.LoginConfiguration) (*biz.LoginConf
 		LoginButtonText:      m.LoginButtonText,
 		DisableUserPwdLogin:  m.DisableUserPwdLogin,
 		DisableMultipleLogin: m.DisableMultipleLogin,
+
+		PasswordMinLength:        m.PasswordMinLength,
+		PasswordRequireUppercase: m.PasswordRequireUppercase,
+		PasswordRequireLowercase: m.PasswordRequireLowercase,
+		PasswordRequireDigit:     m.PasswordRequireDigit,
+		PasswordRequireSpecial:   m.PasswordRequireSpecial,
+		PasswordHistoryCount:     m.PasswordHistoryCount,
+		PasswordMaxAgeDays:       m.PasswordMaxAgeDays,
+		LockoutMaxFailedAttempts: m.LockoutMaxFailedAttempts,
+		LockoutWindowMinutes:     m.LockoutWindowMinutes,
+		LockoutDurationMinutes:   m.LockoutDurationMinutes,
+
+		SessionIdleTimeoutMinutes:      m.SessionIdleTimeoutMinutes,
+		SessionMaxAgeMinutes:           m.SessionMaxAgeMinutes,
+		AdminSessionIdleTimeoutMinutes: m.AdminSessionIdleTimeoutMinutes,
+		AdminSessionMaxAgeMinutes:      m.AdminSessionMaxAgeMinutes,
+		AccessTokenIdleTimeoutDays:     m.AccessTokenIdleTimeoutDays,
+		AccessTokenMaxAgeDays:          m.AccessTokenMaxAgeDays,
 	}, nil
 }
 
@@ -1052,6 +1146,9 @@ func convertBizWorkflow(b *biz.Workflow) *model.Workflow {
 		WorkflowTemplateId:   b.WorkflowTemplateId,
 		WorkflowTemplateName: b.WorkflowTemplateName,
 		OpsTypeUID:           b.OpsTypeUID,
+		EncryptExport:        b.EncryptExport,
+		MaxDownloads:         b.MaxDownloads,
+		DeliveryTargetUID:    b.DeliveryTargetUID,
 	}
 	if b.WorkflowRecord != nil {
 		workflow.WorkflowRecord = convertBizWorkflowRecord(b.WorkflowRecord)
@@ -1074,8 +1171,17 @@ func convertModelWorkflow(m *model.Workflow) (w *biz.Workflow, err error) {
 		WorkflowTemplateId:   m.WorkflowTemplateId,
 		WorkflowTemplateName: m.WorkflowTemplateName,
 		OpsTypeUID:           m.OpsTypeUID,
+		EncryptExport:        m.EncryptExport,
+		MaxDownloads:         m.MaxDownloads,
+		DeliveryTargetUID:    m.DeliveryTargetUID,
 		TaskIds:              m.GetTaskIds(),
 	}
+	if m.ExportPassword != "" {
+		w.ExportPassword, err = pkgAes.AesDecrypt(m.ExportPassword)
+		if err != nil {
+			return w, fmt.Errorf("decrypt export password failed: %v", err)
+		}
+	}
 	if m.WorkflowRecord != nil {
 		w.WorkflowRecord, err = convertModelWorkflowRecord(m.WorkflowRecord)
 		if err != nil {
@@ -1176,22 +1282,29 @@ func convertModelWorkflowStep(workflowRecord *model.WorkflowRecord, currentWorkf
 
 func convertBizDataExportTask(b *biz.DataExportTask) *model.DataExportTask {
 	dataExportTask := &model.DataExportTask{
-		Model:             model.Model{UID: b.UID},
-		CreateUserUID:     b.CreateUserUID,
-		DBServiceUid:      b.DBServiceUid,
-		DatabaseName:      b.DatabaseName,
-		WorkFlowRecordUid: b.WorkFlowRecordUid,
-		ExportType:        b.ExportType,
-		ExportFileType:    b.ExportFileType,
-		ExportFileName:    b.ExportFileName,
-		ExportStatus:      b.ExportStatus.String(),
-		ExportStartTime:   b.ExportStartTime,
-		ExportEndTime:     b.ExportEndTime,
-		ExportFailStage:   b.ExportFailStage,
-		ExportFailReason:  b.ExportFailReason,
-		AuditPassRate:     b.AuditPassRate,
-		AuditScore:        b.AuditScore,
-		AuditLevel:        b.AuditLevel,
+		Model:                model.Model{UID: b.UID},
+		CreateUserUID:        b.CreateUserUID,
+		DBServiceUid:         b.DBServiceUid,
+		DatabaseName:         b.DatabaseName,
+		WorkFlowRecordUid:    b.WorkFlowRecordUid,
+		ExportType:           b.ExportType,
+		ExportFileType:       b.ExportFileType,
+		ExportFileName:       b.ExportFileName,
+		WatermarkMode:        b.WatermarkMode,
+		WatermarkFingerprint: b.WatermarkFingerprint,
+		ExportStatus:         b.ExportStatus.String(),
+		ExportStartTime:      b.ExportStartTime,
+		ExportEndTime:        b.ExportEndTime,
+		ExportFailStage:      b.ExportFailStage,
+		ExportFailReason:     b.ExportFailReason,
+		DeliveryStatus:       string(b.DeliveryStatus),
+		DeliveryLocation:     b.DeliveryLocation,
+		DeliveryError:        b.DeliveryError,
+		DeliveryState:        b.DeliveryState,
+		ExportFileChecksum:   b.ExportFileChecksum,
+		AuditPassRate:        b.AuditPassRate,
+		AuditScore:           b.AuditScore,
+		AuditLevel:           b.AuditLevel,
 	}
 	if b.DataExportTaskRecords != nil {
 		for _, record := range b.DataExportTaskRecords {
@@ -1204,23 +1317,30 @@ func convertBizDataExportTask(b *biz.DataExportTask) *model.DataExportTask {
 
 func convertModelDataExportTask(m *model.DataExportTask) *biz.DataExportTask {
 	w := &biz.DataExportTask{
-		Base:              convertBase(m.Model),
-		UID:               m.UID,
-		DBServiceUid:      m.DBServiceUid,
-		CreateUserUID:     m.CreateUserUID,
-		DatabaseName:      m.DatabaseName,
-		WorkFlowRecordUid: m.WorkFlowRecordUid,
-		ExportType:        m.ExportType,
-		ExportFileType:    m.ExportFileType,
-		ExportFileName:    m.ExportFileName,
-		AuditPassRate:     m.AuditPassRate,
-		AuditScore:        m.AuditScore,
-		AuditLevel:        m.AuditLevel,
-		ExportStatus:      biz.DataExportTaskStatus(m.ExportStatus),
-		ExportStartTime:   m.ExportStartTime,
-		ExportEndTime:     m.ExportEndTime,
-		ExportFailStage:   m.ExportFailStage,
-		ExportFailReason:  m.ExportFailReason,
+		Base:                 convertBase(m.Model),
+		UID:                  m.UID,
+		DBServiceUid:         m.DBServiceUid,
+		CreateUserUID:        m.CreateUserUID,
+		DatabaseName:         m.DatabaseName,
+		WorkFlowRecordUid:    m.WorkFlowRecordUid,
+		ExportType:           m.ExportType,
+		ExportFileType:       m.ExportFileType,
+		ExportFileName:       m.ExportFileName,
+		WatermarkMode:        m.WatermarkMode,
+		WatermarkFingerprint: m.WatermarkFingerprint,
+		AuditPassRate:        m.AuditPassRate,
+		AuditScore:           m.AuditScore,
+		AuditLevel:           m.AuditLevel,
+		ExportStatus:         biz.DataExportTaskStatus(m.ExportStatus),
+		ExportStartTime:      m.ExportStartTime,
+		ExportEndTime:        m.ExportEndTime,
+		ExportFailStage:      m.ExportFailStage,
+		ExportFailReason:     m.ExportFailReason,
+		DeliveryStatus:       biz.DataExportDeliveryStatus(m.DeliveryStatus),
+		DeliveryLocation:     m.DeliveryLocation,
+		DeliveryError:        m.DeliveryError,
+		DeliveryState:        m.DeliveryState,
+		ExportFileChecksum:   m.ExportFileChecksum,
 	}
 	if m.DataExportTaskRecords != nil {
 		for _, r := range m.DataExportTaskRecords {
@@ -1399,3 +1519,665 @@ func toBizDBServiceSyncTask(m *model.DBServiceSyncTask) *biz.DBServiceSyncTask {
 	}
 	return ret
 }
+
+func convertBizAccessToken(b *biz.AccessTokenInfo) *model.UserAccessToken {
+	return &model.UserAccessToken{
+		Model: model.Model{
+			UID: b.UID,
+		},
+		Name:        b.Name,
+		Token:       b.Token,
+		ExpiredTime: b.ExpiredTime,
+		UserID:      b.UserID,
+		ReadOnly:    b.ReadOnly,
+		ProjectUIDs: b.ProjectUIDs,
+		LastUsedAt:  convertBizTimeToModel(b.LastUsedAt),
+		LastUsedIP:  b.LastUsedIP,
+	}
+}
+
+func convertModelAccessToken(m *model.UserAccessToken) *biz.AccessTokenInfo {
+	return &biz.AccessTokenInfo{
+		UID:         m.UID,
+		UserID:      m.UserID,
+		Name:        m.Name,
+		Token:       m.Token,
+		ExpiredTime: m.ExpiredTime,
+		ReadOnly:    m.ReadOnly,
+		ProjectUIDs: m.ProjectUIDs,
+		LastUsedAt:  convertModelTimeToBiz(m.LastUsedAt),
+		LastUsedIP:  m.LastUsedIP,
+		CreatedAt:   m.CreatedAt,
+	}
+}
+
+func convertBizServiceAccount(b *biz.ServiceAccount) *model.ServiceAccount {
+	return &model.ServiceAccount{
+		Model: model.Model{
+			UID:       b.UID,
+			CreatedAt: b.CreatedAt,
+		},
+		UserUID:          b.UserUID,
+		ClientID:         b.ClientID,
+		ClientSecretHash: b.ClientSecretHash,
+		SecretRotatedAt:  b.SecretRotatedAt,
+	}
+}
+
+func convertModelServiceAccount(m *model.ServiceAccount) *biz.ServiceAccount {
+	return &biz.ServiceAccount{
+		UID:              m.UID,
+		UserUID:          m.UserUID,
+		ClientID:         m.ClientID,
+		ClientSecretHash: m.ClientSecretHash,
+		SecretRotatedAt:  m.SecretRotatedAt,
+		CreatedAt:        m.CreatedAt,
+	}
+}
+
+func convertBizLoginSession(b *biz.LoginSession) *model.LoginSession {
+	return &model.LoginSession{
+		Model: model.Model{
+			UID:       b.UID,
+			CreatedAt: b.CreatedAt,
+		},
+		UserUID:    b.UserUID,
+		LoginType:  b.LoginType,
+		IP:         b.IP,
+		UserAgent:  b.UserAgent,
+		LastSeenAt: convertBizTimeToModel(b.LastSeenAt),
+		ExpiredAt:  b.ExpiredAt,
+		RevokedAt:  convertBizTimeToModel(b.RevokedAt),
+	}
+}
+
+func convertModelLoginSession(m *model.LoginSession) *biz.LoginSession {
+	return &biz.LoginSession{
+		UID:        m.UID,
+		UserUID:    m.UserUID,
+		LoginType:  m.LoginType,
+		IP:         m.IP,
+		UserAgent:  m.UserAgent,
+		CreatedAt:  m.CreatedAt,
+		LastSeenAt: convertModelTimeToBiz(m.LastSeenAt),
+		ExpiredAt:  m.ExpiredAt,
+		RevokedAt:  convertModelTimeToBiz(m.RevokedAt),
+	}
+}
+
+func convertBizMemberAccessRequest(r *biz.MemberAccessRequest) *model.MemberAccessRequest {
+	roles := make(model.RoleWithOpRanges, 0, len(r.RoleWithOpRanges))
+	for _, role := range r.RoleWithOpRanges {
+		roles = append(roles, model.RoleWithOpRange{
+			RoleUID:     role.RoleUID,
+			OpRangeType: role.OpRangeType.String(),
+			RangeUIDs:   role.RangeUIDs,
+		})
+	}
+	return &model.MemberAccessRequest{
+		Model: model.Model{
+			UID:       r.UID,
+			CreatedAt: r.CreatedAt,
+		},
+		ProjectUID:       r.ProjectUID,
+		UserUID:          r.UserUID,
+		RoleWithOpRanges: roles,
+		DurationHours:    r.DurationHours,
+		Reason:           r.Reason,
+		Status:           string(r.Status),
+		ReviewerUID:      r.ReviewerUID,
+		ReviewComment:    r.ReviewComment,
+		ReviewedAt:       convertBizTimeToModel(r.ReviewedAt),
+		MemberGroupUID:   r.MemberGroupUID,
+	}
+}
+
+func convertModelMemberAccessRequest(m *model.MemberAccessRequest) (*biz.MemberAccessRequest, error) {
+	roles := make([]biz.MemberRoleWithOpRange, 0, len(m.RoleWithOpRanges))
+	for _, role := range m.RoleWithOpRanges {
+		typ, err := biz.ParseOpRangeType(role.OpRangeType)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse op range type: %v", err)
+		}
+		roles = append(roles, biz.MemberRoleWithOpRange{
+			RoleUID:     role.RoleUID,
+			OpRangeType: typ,
+			RangeUIDs:   role.RangeUIDs,
+		})
+	}
+	return &biz.MemberAccessRequest{
+		UID:              m.UID,
+		ProjectUID:       m.ProjectUID,
+		UserUID:          m.UserUID,
+		RoleWithOpRanges: roles,
+		DurationHours:    m.DurationHours,
+		Reason:           m.Reason,
+		Status:           biz.MemberAccessRequestStatus(m.Status),
+		ReviewerUID:      m.ReviewerUID,
+		ReviewComment:    m.ReviewComment,
+		ReviewedAt:       convertModelTimeToBiz(m.ReviewedAt),
+		MemberGroupUID:   m.MemberGroupUID,
+		CreatedAt:        m.CreatedAt,
+	}, nil
+}
+
+func convertBizSeparationOfDutiesRule(r *biz.SeparationOfDutiesRule) *model.SeparationOfDutiesRule {
+	return &model.SeparationOfDutiesRule{
+		Model: model.Model{
+			UID:       r.UID,
+			CreatedAt: r.CreatedAt,
+		},
+		ProjectUID:       r.ProjectUID,
+		Type:             string(r.Type),
+		OpPermissionUIDs: r.OpPermissionUIDs,
+		WorkflowType:     string(r.WorkflowType),
+		Desc:             r.Desc,
+		CreateUserUID:    r.CreateUserUID,
+	}
+}
+
+func convertModelSeparationOfDutiesRule(m *model.SeparationOfDutiesRule) *biz.SeparationOfDutiesRule {
+	return &biz.SeparationOfDutiesRule{
+		UID:              m.UID,
+		ProjectUID:       m.ProjectUID,
+		Type:             biz.SeparationOfDutiesRuleType(m.Type),
+		OpPermissionUIDs: m.OpPermissionUIDs,
+		WorkflowType:     biz.SeparationOfDutiesWorkflowType(m.WorkflowType),
+		Desc:             m.Desc,
+		CreateUserUID:    m.CreateUserUID,
+		CreatedAt:        m.CreatedAt,
+	}
+}
+
+func convertBizAccessReviewCampaign(c *biz.AccessReviewCampaign) *model.AccessReviewCampaign {
+	return &model.AccessReviewCampaign{
+		Model: model.Model{
+			UID:       c.UID,
+			CreatedAt: c.CreatedAt,
+		},
+		Name:          c.Name,
+		ProjectUIDs:   c.ProjectUIDs,
+		RoleUIDs:      c.RoleUIDs,
+		Deadline:      c.Deadline,
+		AutoRevoke:    c.AutoRevoke,
+		Status:        string(c.Status),
+		CreateUserUID: c.CreateUserUID,
+		CompletedAt:   convertBizTimeToModel(c.CompletedAt),
+	}
+}
+
+func convertModelAccessReviewCampaign(m *model.AccessReviewCampaign) *biz.AccessReviewCampaign {
+	return &biz.AccessReviewCampaign{
+		UID:           m.UID,
+		Name:          m.Name,
+		ProjectUIDs:   m.ProjectUIDs,
+		RoleUIDs:      m.RoleUIDs,
+		Deadline:      m.Deadline,
+		AutoRevoke:    m.AutoRevoke,
+		Status:        biz.AccessReviewCampaignStatus(m.Status),
+		CreateUserUID: m.CreateUserUID,
+		CreatedAt:     m.CreatedAt,
+		CompletedAt:   convertModelTimeToBiz(m.CompletedAt),
+	}
+}
+
+func convertBizAccessReviewItem(i *biz.AccessReviewItem) *model.AccessReviewItem {
+	roles := make(model.RoleWithOpRanges, 0, len(i.RoleWithOpRanges))
+	for _, role := range i.RoleWithOpRanges {
+		roles = append(roles, model.RoleWithOpRange{
+			RoleUID:     role.RoleUID,
+			OpRangeType: role.OpRangeType.String(),
+			RangeUIDs:   role.RangeUIDs,
+		})
+	}
+	return &model.AccessReviewItem{
+		Model: model.Model{
+			UID: i.UID,
+		},
+		CampaignUID:      i.CampaignUID,
+		ProjectUID:       i.ProjectUID,
+		ProjectName:      i.ProjectName,
+		SubjectType:      string(i.SubjectType),
+		SubjectUID:       i.SubjectUID,
+		SubjectName:      i.SubjectName,
+		UserUID:          i.UserUID,
+		UserName:         i.UserName,
+		RoleWithOpRanges: roles,
+		LastActiveAt:     convertBizTimeToModel(i.LastActiveAt),
+		Decision:         string(i.Decision),
+		ReviewerUID:      i.ReviewerUID,
+		Comment:          i.Comment,
+		ReviewedAt:       convertBizTimeToModel(i.ReviewedAt),
+	}
+}
+
+func convertModelAccessReviewItem(m *model.AccessReviewItem) (*biz.AccessReviewItem, error) {
+	roles := make([]biz.MemberRoleWithOpRange, 0, len(m.RoleWithOpRanges))
+	for _, role := range m.RoleWithOpRanges {
+		typ, err := biz.ParseOpRangeType(role.OpRangeType)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse op range type: %v", err)
+		}
+		roles = append(roles, biz.MemberRoleWithOpRange{
+			RoleUID:     role.RoleUID,
+			OpRangeType: typ,
+			RangeUIDs:   role.RangeUIDs,
+		})
+	}
+	return &biz.AccessReviewItem{
+		UID:              m.UID,
+		CampaignUID:      m.CampaignUID,
+		ProjectUID:       m.ProjectUID,
+		ProjectName:      m.ProjectName,
+		SubjectType:      biz.AccessReviewSubjectType(m.SubjectType),
+		SubjectUID:       m.SubjectUID,
+		SubjectName:      m.SubjectName,
+		UserUID:          m.UserUID,
+		UserName:         m.UserName,
+		RoleWithOpRanges: roles,
+		LastActiveAt:     convertModelTimeToBiz(m.LastActiveAt),
+		Decision:         biz.AccessReviewDecision(m.Decision),
+		ReviewerUID:      m.ReviewerUID,
+		Comment:          m.Comment,
+		ReviewedAt:       convertModelTimeToBiz(m.ReviewedAt),
+	}, nil
+}
+
+func convertBizServiceOpPermissionManifest(m *biz.ServiceOpPermissionManifest) *model.ServiceOpPermissionManifest {
+	ops := make(model.ServiceOpPermissions, 0, len(m.OpPermissions))
+	for _, op := range m.OpPermissions {
+		ops = append(ops, model.ServiceOpPermission{
+			UID:             op.UID,
+			Name:            op.Name.StrMap(),
+			Desc:            op.Desc.StrMap(),
+			Module:          op.Module,
+			RangeType:       op.RangeType.String(),
+			BuiltinRoleUIDs: op.BuiltinRoleUIDs,
+		})
+	}
+	return &model.ServiceOpPermissionManifest{
+		Model: model.Model{
+			UID: m.UID,
+		},
+		ServiceName:   m.ServiceName,
+		Version:       m.Version,
+		OpPermissions: ops,
+	}
+}
+
+func convertModelServiceOpPermissionManifest(m *model.ServiceOpPermissionManifest) (*biz.ServiceOpPermissionManifest, error) {
+	ops := make([]*biz.ServiceOpPermission, 0, len(m.OpPermissions))
+	for _, op := range m.OpPermissions {
+		name, err := i18nPkg.ConvertStrMap2I18nStr(op.Name)
+		if err != nil {
+			return nil, fmt.Errorf("convert name of op permission %s failed: %v", op.UID, err)
+		}
+		desc, err := i18nPkg.ConvertStrMap2I18nStr(op.Desc)
+		if err != nil {
+			return nil, fmt.Errorf("convert desc of op permission %s failed: %v", op.UID, err)
+		}
+		ops = append(ops, &biz.ServiceOpPermission{
+			UID:             op.UID,
+			Name:            name,
+			Desc:            desc,
+			Module:          op.Module,
+			RangeType:       biz.OpRangeType(op.RangeType),
+			BuiltinRoleUIDs: op.BuiltinRoleUIDs,
+		})
+	}
+	return &biz.ServiceOpPermissionManifest{
+		UID:           m.UID,
+		ServiceName:   m.ServiceName,
+		Version:       m.Version,
+		OpPermissions: ops,
+	}, nil
+}
+
+func convertBizDataExportSchedule(s *biz.DataExportSchedule) *model.DataExportSchedule {
+	tasks := make(model.DataExportScheduleTasks, 0, len(s.Tasks))
+	for _, t := range s.Tasks {
+		tasks = append(tasks, model.DataExportScheduleTask{
+			DBServiceUID:       t.DBServiceUID,
+			DatabaseName:       t.DatabaseName,
+			ExportSQL:          t.ExportSQL,
+			ExportFileType:     t.ExportFileType,
+			AuditLevel:         t.AuditLevel,
+			DBServiceUpdatedAt: t.DBServiceUpdatedAt,
+		})
+	}
+	return &model.DataExportSchedule{
+		Model: model.Model{
+			UID:       s.UID,
+			CreatedAt: s.CreatedAt,
+		},
+		ProjectUID:         s.ProjectUID,
+		WorkflowUID:        s.WorkflowUID,
+		CreateUserUID:      s.CreateUserUID,
+		CronExpression:     s.CronExpression,
+		EndTime:            convertBizTimeToModel(s.EndTime),
+		EncryptExport:      s.EncryptExport,
+		Tasks:              tasks,
+		Status:             string(s.Status),
+		SuspendReason:      s.SuspendReason,
+		NextRunAt:          convertBizTimeToModel(s.NextRunAt),
+		LastRunAt:          convertBizTimeToModel(s.LastRunAt),
+		LastRunWorkflowUID: s.LastRunWorkflowUID,
+		LastRunError:       s.LastRunError,
+	}
+}
+
+func convertModelDataExportSchedule(m *model.DataExportSchedule) *biz.DataExportSchedule {
+	tasks := make([]*biz.DataExportScheduleTask, 0, len(m.Tasks))
+	for _, t := range m.Tasks {
+		tasks = append(tasks, &biz.DataExportScheduleTask{
+			DBServiceUID:       t.DBServiceUID,
+			DatabaseName:       t.DatabaseName,
+			ExportSQL:          t.ExportSQL,
+			ExportFileType:     t.ExportFileType,
+			AuditLevel:         t.AuditLevel,
+			DBServiceUpdatedAt: t.DBServiceUpdatedAt,
+		})
+	}
+	return &biz.DataExportSchedule{
+		UID:                m.UID,
+		ProjectUID:         m.ProjectUID,
+		WorkflowUID:        m.WorkflowUID,
+		CreateUserUID:      m.CreateUserUID,
+		CronExpression:     m.CronExpression,
+		EndTime:            convertModelTimeToBiz(m.EndTime),
+		EncryptExport:      m.EncryptExport,
+		Tasks:              tasks,
+		Status:             biz.DataExportScheduleStatus(m.Status),
+		SuspendReason:      m.SuspendReason,
+		NextRunAt:          convertModelTimeToBiz(m.NextRunAt),
+		LastRunAt:          convertModelTimeToBiz(m.LastRunAt),
+		LastRunWorkflowUID: m.LastRunWorkflowUID,
+		LastRunError:       m.LastRunError,
+		CreatedAt:          m.CreatedAt,
+	}
+}
+
+func convertBizDataExportQuota(q *biz.DataExportQuota) *model.DataExportQuota {
+	return &model.DataExportQuota{
+		Model: model.Model{
+			UID: q.UID,
+		},
+		Scope:                      string(q.Scope),
+		ScopeUID:                   q.ScopeUID,
+		ProjectUID:                 q.ProjectUID,
+		MaxRowsPerSQL:              q.Limits.MaxRowsPerSQL,
+		MaxFileSizeBytes:           q.Limits.MaxFileSizeBytes,
+		MaxExportsPerUserPerDay:    q.Limits.MaxExportsPerUserPerDay,
+		MaxBytesPerProjectPerMonth: q.Limits.MaxBytesPerProjectPerMonth,
+	}
+}
+
+func convertModelDataExportQuota(m *model.DataExportQuota) *biz.DataExportQuota {
+	return &biz.DataExportQuota{
+		UID:        m.UID,
+		Scope:      biz.DataExportQuotaScope(m.Scope),
+		ScopeUID:   m.ScopeUID,
+		ProjectUID: m.ProjectUID,
+		Limits: biz.DataExportLimits{
+			MaxRowsPerSQL:              m.MaxRowsPerSQL,
+			MaxFileSizeBytes:           m.MaxFileSizeBytes,
+			MaxExportsPerUserPerDay:    m.MaxExportsPerUserPerDay,
+			MaxBytesPerProjectPerMonth: m.MaxBytesPerProjectPerMonth,
+		},
+		UpdatedAt: m.UpdatedAt,
+	}
+}
+
+func convertBizDataExportUsage(u *biz.DataExportUsage) *model.DataExportUsage {
+	return &model.DataExportUsage{
+		Model: model.Model{
+			UID:       u.UID,
+			CreatedAt: u.CreatedAt,
+		},
+		ProjectUID:   u.ProjectUID,
+		UserUID:      u.UserUID,
+		DBServiceUID: u.DBServiceUID,
+		TaskUID:      u.TaskUID,
+		Rows:         u.Rows,
+		Bytes:        u.Bytes,
+	}
+}
+
+func convertBizDataExportWatermark(w *biz.DataExportWatermark) *model.DataExportWatermark {
+	return &model.DataExportWatermark{
+		Model: model.Model{
+			UID: w.UID,
+		},
+		Fingerprint: w.Fingerprint,
+		Kind:        string(w.Kind),
+		TaskUID:     w.TaskUID,
+		WorkflowUID: w.WorkflowUID,
+		ProjectUID:  w.ProjectUID,
+		UserUID:     w.UserUID,
+	}
+}
+
+func convertModelDataExportWatermark(m *model.DataExportWatermark) *biz.DataExportWatermark {
+	return &biz.DataExportWatermark{
+		UID:         m.UID,
+		Fingerprint: m.Fingerprint,
+		Kind:        biz.DataExportWatermarkKind(m.Kind),
+		TaskUID:     m.TaskUID,
+		WorkflowUID: m.WorkflowUID,
+		ProjectUID:  m.ProjectUID,
+		UserUID:     m.UserUID,
+		CreatedAt:   m.CreatedAt,
+	}
+}
+
+func convertBizDataExportDownload(d *biz.DataExportDownload) *model.DataExportDownload {
+	return &model.DataExportDownload{
+		Model: model.Model{
+			UID: d.UID,
+		},
+		TaskUID:     d.TaskUID,
+		WorkflowUID: d.WorkflowUID,
+		ProjectUID:  d.ProjectUID,
+		UserUID:     d.UserUID,
+		IP:          d.IP,
+		Bytes:       d.Bytes,
+		StartOffset: d.StartOffset,
+		Size:        d.Size,
+		Via:         string(d.Via),
+		LinkUID:     d.LinkUID,
+		Fingerprint: d.Fingerprint,
+		Resumed:     d.Resumed,
+	}
+}
+
+func convertModelDataExportDownload(m *model.DataExportDownload) *biz.DataExportDownload {
+	return &biz.DataExportDownload{
+		UID:         m.UID,
+		TaskUID:     m.TaskUID,
+		WorkflowUID: m.WorkflowUID,
+		ProjectUID:  m.ProjectUID,
+		UserUID:     m.UserUID,
+		IP:          m.IP,
+		Bytes:       m.Bytes,
+		StartOffset: m.StartOffset,
+		Size:        m.Size,
+		Via:         biz.DataExportDownloadVia(m.Via),
+		LinkUID:     m.LinkUID,
+		Fingerprint: m.Fingerprint,
+		Resumed:     m.Resumed,
+		CreatedAt:   m.CreatedAt,
+	}
+}
+
+func convertBizDataExportDownloadLink(l *biz.DataExportDownloadLink) *model.DataExportDownloadLink {
+	return &model.DataExportDownloadLink{
+		Model: model.Model{
+			UID: l.UID,
+		},
+		TaskUID:       l.TaskUID,
+		ProjectUID:    l.ProjectUID,
+		CreateUserUID: l.CreateUserUID,
+		ExpiresAt:     l.ExpiresAt,
+		UsedAt:        l.UsedAt,
+	}
+}
+
+func convertModelDataExportDownloadLink(m *model.DataExportDownloadLink) *biz.DataExportDownloadLink {
+	return &biz.DataExportDownloadLink{
+		UID:           m.UID,
+		TaskUID:       m.TaskUID,
+		ProjectUID:    m.ProjectUID,
+		CreateUserUID: m.CreateUserUID,
+		ExpiresAt:     m.ExpiresAt,
+		UsedAt:        m.UsedAt,
+	}
+}
+
+func convertBizDataExportDeliveryTarget(b *biz.DataExportDeliveryTarget) (*model.DataExportDeliveryTarget, error) {
+	m := &model.DataExportDeliveryTarget{
+		Model: model.Model{
+			UID: b.UID,
+		},
+		Name:          b.Name,
+		Type:          string(b.Config.Type),
+		Path:          b.Config.Path,
+		Endpoint:      b.Config.Endpoint,
+		Region:        b.Config.Region,
+		Bucket:        b.Config.Bucket,
+		AccessKeyID:   b.Config.AccessKeyID,
+		PathStyle:     b.Config.PathStyle,
+		Host:          b.Config.Host,
+		Port:          b.Config.Port,
+		Username:      b.Config.Username,
+		HostPublicKey: b.Config.HostPublicKey,
+	}
+	if b.Config.SecretAccessKey != "" {
+		secret, err := pkgAes.AesEncrypt(b.Config.SecretAccessKey)
+		if err != nil {
+			return nil, fmt.Errorf("encrypt secret access key failed: %v", err)
+		}
+		m.SecretAccessKey = secret
+	}
+	if b.Config.PrivateKey != "" {
+		privateKey, err := pkgAes.AesEncrypt(b.Config.PrivateKey)
+		if err != nil {
+			return nil, fmt.Errorf("encrypt private key failed: %v", err)
+		}
+		m.PrivateKey = privateKey
+	}
+	return m, nil
+}
+
+func convertModelDataExportDeliveryTarget(m *model.DataExportDeliveryTarget) (*biz.DataExportDeliveryTarget, error) {
+	b := &biz.DataExportDeliveryTarget{
+		UID:  m.UID,
+		Name: m.Name,
+		Config: exportdelivery.Config{
+			Type:          exportdelivery.Type(m.Type),
+			Path:          m.Path,
+			Endpoint:      m.Endpoint,
+			Region:        m.Region,
+			Bucket:        m.Bucket,
+			AccessKeyID:   m.AccessKeyID,
+			PathStyle:     m.PathStyle,
+			Host:          m.Host,
+			Port:          m.Port,
+			Username:      m.Username,
+			HostPublicKey: m.HostPublicKey,
+		},
+		CreatedAt: m.CreatedAt,
+	}
+	if m.SecretAccessKey != "" {
+		secret, err := pkgAes.AesDecrypt(m.SecretAccessKey)
+		if err != nil {
+			return nil, fmt.Errorf("decrypt secret access key failed: %v", err)
+		}
+		b.Config.SecretAccessKey = secret
+	}
+	if m.PrivateKey != "" {
+		privateKey, err := pkgAes.AesDecrypt(m.PrivateKey)
+		if err != nil {
+			return nil, fmt.Errorf("decrypt private key failed: %v", err)
+		}
+		b.Config.PrivateKey = privateKey
+	}
+	return b, nil
+}
+
+func convertBizWorkflowApprovalSLA(b *biz.WorkflowApprovalSLA) *model.WorkflowApprovalSLA {
+	return &model.WorkflowApprovalSLA{
+		Model: model.Model{
+			UID: b.UID,
+		},
+		ProjectUID:           b.ProjectUID,
+		RemindAfterHours:     b.RemindAfterHours,
+		EscalateAfterHours:   b.EscalateAfterHours,
+		AutoRejectAfterHours: b.AutoRejectAfterHours,
+	}
+}
+
+func convertModelWorkflowApprovalSLA(m *model.WorkflowApprovalSLA) *biz.WorkflowApprovalSLA {
+	return &biz.WorkflowApprovalSLA{
+		UID:                  m.UID,
+		ProjectUID:           m.ProjectUID,
+		RemindAfterHours:     m.RemindAfterHours,
+		EscalateAfterHours:   m.EscalateAfterHours,
+		AutoRejectAfterHours: m.AutoRejectAfterHours,
+		UpdatedAt:            m.UpdatedAt,
+	}
+}
+
+func convertBizWorkflowApprovalSLARecord(b *biz.WorkflowApprovalSLARecord) *model.WorkflowApprovalSLARecord {
+	return &model.WorkflowApprovalSLARecord{
+		Model: model.Model{
+			UID: b.UID,
+		},
+		WorkflowUID: b.WorkflowUID,
+		StepID:      b.StepID,
+		RemindedAt:  b.RemindedAt,
+		EscalatedAt: b.EscalatedAt,
+	}
+}
+
+func convertModelWorkflowApprovalSLARecord(m *model.WorkflowApprovalSLARecord) *biz.WorkflowApprovalSLARecord {
+	return &biz.WorkflowApprovalSLARecord{
+		UID:         m.UID,
+		WorkflowUID: m.WorkflowUID,
+		StepID:      m.StepID,
+		RemindedAt:  m.RemindedAt,
+		EscalatedAt: m.EscalatedAt,
+	}
+}
+
+func convertBizDataExportPreview(b *biz.DataExportPreview) (*model.DataExportPreview, error) {
+	results, err := json.Marshal(b.Results)
+	if err != nil {
+		return nil, err
+	}
+	return &model.DataExportPreview{
+		Model: model.Model{
+			UID:       b.UID,
+			CreatedAt: b.CreatedAt,
+		},
+		WorkflowUID:       b.WorkflowUID,
+		WorkflowRecordUID: b.WorkflowRecordUID,
+		CreateUserUID:     b.CreateUserUID,
+		SampleRows:        b.SampleRows,
+		Results:           string(results),
+	}, nil
+}
+
+func convertModelDataExportPreview(m *model.DataExportPreview) (*biz.DataExportPreview, error) {
+	b := &biz.DataExportPreview{
+		UID:               m.UID,
+		WorkflowUID:       m.WorkflowUID,
+		WorkflowRecordUID: m.WorkflowRecordUID,
+		CreateUserUID:     m.CreateUserUID,
+		SampleRows:        m.SampleRows,
+		CreatedAt:         m.CreatedAt,
+	}
+	if err := json.Unmarshal([]byte(m.Results), &b.Results); err != nil {
+		return nil, err
+	}
+	return b, nil
+}
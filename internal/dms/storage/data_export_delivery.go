@@ -0,0 +1,101 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/actiontech/dms/internal/dms/biz"
+	pkgErr "github.com/actiontech/dms/internal/dms/pkg/errors"
+	"github.com/actiontech/dms/internal/dms/storage/model"
+	utilLog "github.com/actiontech/dms/pkg/dms-common/pkg/log"
+
+	"gorm.io/gorm"
+)
+
+var _ biz.DataExportDeliveryTargetRepo = (*DataExportDeliveryTargetRepo)(nil)
+
+type DataExportDeliveryTargetRepo struct {
+	*Storage
+	log *utilLog.Helper
+}
+
+func NewDataExportDeliveryTargetRepo(log utilLog.Logger, s *Storage) *DataExportDeliveryTargetRepo {
+	return &DataExportDeliveryTargetRepo{Storage: s, log: utilLog.NewHelper(log, utilLog.WithMessageKey("storage.data_export_delivery"))}
+}
+
+func (d *DataExportDeliveryTargetRepo) SaveDataExportDeliveryTarget(ctx context.Context, target *biz.DataExportDeliveryTarget) error {
+	m, err := convertBizDataExportDeliveryTarget(target)
+	if err != nil {
+		return pkgErr.WrapStorageErr(d.log, fmt.Errorf("failed to convert data export delivery target: %v", err))
+	}
+	return transaction(d.log, ctx, d.db, func(tx *gorm.DB) error {
+		if err := tx.WithContext(ctx).Create(m).Error; err != nil {
+			return pkgErr.WrapStorageErr(d.log, fmt.Errorf("failed to save data export delivery target: %v", err))
+		}
+		return nil
+	})
+}
+
+func (d *DataExportDeliveryTargetRepo) UpdateDataExportDeliveryTarget(ctx context.Context, target *biz.DataExportDeliveryTarget) error {
+	m, err := convertBizDataExportDeliveryTarget(target)
+	if err != nil {
+		return pkgErr.WrapStorageErr(d.log, fmt.Errorf("failed to convert data export delivery target: %v", err))
+	}
+	return transaction(d.log, ctx, d.db, func(tx *gorm.DB) error {
+		if err := tx.WithContext(ctx).Model(&model.DataExportDeliveryTarget{}).Where("uid = ?", m.UID).Omit("created_at").Save(m).Error; err != nil {
+			return pkgErr.WrapStorageErr(d.log, fmt.Errorf("failed to update data export delivery target: %v", err))
+		}
+		return nil
+	})
+}
+
+func (d *DataExportDeliveryTargetRepo) DeleteDataExportDeliveryTarget(ctx context.Context, uid string) error {
+	return transaction(d.log, ctx, d.db, func(tx *gorm.DB) error {
+		if err := tx.WithContext(ctx).Where("uid = ?", uid).Delete(&model.DataExportDeliveryTarget{}).Error; err != nil {
+			return pkgErr.WrapStorageErr(d.log, fmt.Errorf("failed to delete data export delivery target: %v", err))
+		}
+		return nil
+	})
+}
+
+func (d *DataExportDeliveryTargetRepo) GetDataExportDeliveryTarget(ctx context.Context, uid string) (*biz.DataExportDeliveryTarget, error) {
+	var target *model.DataExportDeliveryTarget
+	if err := transaction(d.log, ctx, d.db, func(tx *gorm.DB) error {
+		if err := tx.WithContext(ctx).First(&target, "uid = ?", uid).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return pkgErr.WrapStorageErr(d.log, pkgErr.ErrStorageNoData)
+			}
+			return pkgErr.WrapStorageErr(d.log, fmt.Errorf("failed to get data export delivery target: %v", err))
+		}
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+	ret, err := convertModelDataExportDeliveryTarget(target)
+	if err != nil {
+		return nil, pkgErr.WrapStorageErr(d.log, fmt.Errorf("failed to convert data export delivery target: %v", err))
+	}
+	return ret, nil
+}
+
+func (d *DataExportDeliveryTargetRepo) ListDataExportDeliveryTargets(ctx context.Context) ([]*biz.DataExportDeliveryTarget, error) {
+	var models []*model.DataExportDeliveryTarget
+	if err := transaction(d.log, ctx, d.db, func(tx *gorm.DB) error {
+		if err := tx.WithContext(ctx).Order("name").Find(&models).Error; err != nil {
+			return pkgErr.WrapStorageErr(d.log, fmt.Errorf("failed to list data export delivery targets: %v", err))
+		}
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+	ret := make([]*biz.DataExportDeliveryTarget, 0, len(models))
+	for _, m := range models {
+		target, err := convertModelDataExportDeliveryTarget(m)
+		if err != nil {
+			return nil, pkgErr.WrapStorageErr(d.log, fmt.Errorf("failed to convert data export delivery target: %v", err))
+		}
+		ret = append(ret, target)
+	}
+	return ret, nil
+}
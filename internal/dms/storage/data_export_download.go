@@ -0,0 +1,135 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/actiontech/dms/internal/dms/biz"
+	pkgErr "github.com/actiontech/dms/internal/dms/pkg/errors"
+	"github.com/actiontech/dms/internal/dms/storage/model"
+	utilLog "github.com/actiontech/dms/pkg/dms-common/pkg/log"
+
+	"gorm.io/gorm"
+)
+
+var _ biz.DataExportDownloadRepo = (*DataExportDownloadRepo)(nil)
+
+type DataExportDownloadRepo struct {
+	*Storage
+	log *utilLog.Helper
+}
+
+func NewDataExportDownloadRepo(log utilLog.Logger, s *Storage) *DataExportDownloadRepo {
+	return &DataExportDownloadRepo{Storage: s, log: utilLog.NewHelper(log, utilLog.WithMessageKey("storage.data_export_download"))}
+}
+
+func (d *DataExportDownloadRepo) SaveDataExportDownload(ctx context.Context, download *biz.DataExportDownload) error {
+	return transaction(d.log, ctx, d.db, func(tx *gorm.DB) error {
+		if err := tx.WithContext(ctx).Create(convertBizDataExportDownload(download)).Error; err != nil {
+			return pkgErr.WrapStorageErr(d.log, fmt.Errorf("failed to save data export download: %v", err))
+		}
+		return nil
+	})
+}
+
+func (d *DataExportDownloadRepo) ListDataExportDownloads(ctx context.Context, taskUid string) ([]*biz.DataExportDownload, error) {
+	var models []*model.DataExportDownload
+	if err := transaction(d.log, ctx, d.db, func(tx *gorm.DB) error {
+		if err := tx.WithContext(ctx).Where("task_uid = ?", taskUid).Order("created_at DESC").Find(&models).Error; err != nil {
+			return pkgErr.WrapStorageErr(d.log, fmt.Errorf("failed to list data export downloads: %v", err))
+		}
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+	ret := make([]*biz.DataExportDownload, 0, len(models))
+	for _, m := range models {
+		ret = append(ret, convertModelDataExportDownload(m))
+	}
+	return ret, nil
+}
+
+func (d *DataExportDownloadRepo) CountDataExportDownloads(ctx context.Context, taskUid string) (int64, error) {
+	var count int64
+	if err := transaction(d.log, ctx, d.db, func(tx *gorm.DB) error {
+		if err := tx.WithContext(ctx).Model(&model.DataExportTask{}).Where("uid = ?", taskUid).Pluck("download_count", &count).Error; err != nil {
+			return pkgErr.WrapStorageErr(d.log, fmt.Errorf("failed to count data export downloads: %v", err))
+		}
+		return nil
+	}); err != nil {
+		return 0, err
+	}
+	return count, nil
+}
+
+func (d *DataExportDownloadRepo) ReserveDataExportDownload(ctx context.Context, taskUid string, maxDownloads int) (bool, error) {
+	var reserved bool
+	if err := transaction(d.log, ctx, d.db, func(tx *gorm.DB) error {
+		db := tx.WithContext(ctx).Model(&model.DataExportTask{}).Where("uid = ?", taskUid)
+		if maxDownloads > 0 {
+			db = db.Where("download_count < ?", maxDownloads)
+		}
+		result := db.UpdateColumn("download_count", gorm.Expr("download_count + ?", 1))
+		if result.Error != nil {
+			return pkgErr.WrapStorageErr(d.log, fmt.Errorf("failed to reserve data export download: %v", result.Error))
+		}
+		reserved = result.RowsAffected == 1
+		return nil
+	}); err != nil {
+		return false, err
+	}
+	return reserved, nil
+}
+
+func (d *DataExportDownloadRepo) ReleaseDataExportDownload(ctx context.Context, taskUid string) error {
+	return transaction(d.log, ctx, d.db, func(tx *gorm.DB) error {
+		if err := tx.WithContext(ctx).Model(&model.DataExportTask{}).Where("uid = ? AND download_count > 0", taskUid).
+			UpdateColumn("download_count", gorm.Expr("download_count - ?", 1)).Error; err != nil {
+			return pkgErr.WrapStorageErr(d.log, fmt.Errorf("failed to release data export download: %v", err))
+		}
+		return nil
+	})
+}
+
+func (d *DataExportDownloadRepo) SaveDataExportDownloadLink(ctx context.Context, link *biz.DataExportDownloadLink) error {
+	return transaction(d.log, ctx, d.db, func(tx *gorm.DB) error {
+		if err := tx.WithContext(ctx).Create(convertBizDataExportDownloadLink(link)).Error; err != nil {
+			return pkgErr.WrapStorageErr(d.log, fmt.Errorf("failed to save data export download link: %v", err))
+		}
+		return nil
+	})
+}
+
+func (d *DataExportDownloadRepo) GetDataExportDownloadLink(ctx context.Context, uid string) (*biz.DataExportDownloadLink, error) {
+	var link *model.DataExportDownloadLink
+	if err := transaction(d.log, ctx, d.db, func(tx *gorm.DB) error {
+		if err := tx.WithContext(ctx).First(&link, "uid = ?", uid).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return pkgErr.WrapStorageErr(d.log, pkgErr.ErrStorageNoData)
+			}
+			return pkgErr.WrapStorageErr(d.log, fmt.Errorf("failed to get data export download link: %v", err))
+		}
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+	return convertModelDataExportDownloadLink(link), nil
+}
+
+func (d *DataExportDownloadRepo) UseDataExportDownloadLink(ctx context.Context, uid string, usedAt time.Time) (bool, error) {
+	var used bool
+	if err := transaction(d.log, ctx, d.db, func(tx *gorm.DB) error {
+		result := tx.WithContext(ctx).Model(&model.DataExportDownloadLink{}).
+			Where("uid = ? AND used_at IS NULL", uid).Update("used_at", usedAt)
+		if result.Error != nil {
+			return pkgErr.WrapStorageErr(d.log, fmt.Errorf("failed to use data export download link: %v", result.Error))
+		}
+		used = result.RowsAffected == 1
+		return nil
+	}); err != nil {
+		return false, err
+	}
+	return used, nil
+}
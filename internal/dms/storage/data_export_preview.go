@@ -0,0 +1,58 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/actiontech/dms/internal/dms/biz"
+	pkgErr "github.com/actiontech/dms/internal/dms/pkg/errors"
+	"github.com/actiontech/dms/internal/dms/storage/model"
+	utilLog "github.com/actiontech/dms/pkg/dms-common/pkg/log"
+
+	"gorm.io/gorm"
+)
+
+var _ biz.DataExportPreviewRepo = (*DataExportPreviewRepo)(nil)
+
+type DataExportPreviewRepo struct {
+	*Storage
+	log *utilLog.Helper
+}
+
+func NewDataExportPreviewRepo(log utilLog.Logger, s *Storage) *DataExportPreviewRepo {
+	return &DataExportPreviewRepo{Storage: s, log: utilLog.NewHelper(log, utilLog.WithMessageKey("storage.data_export_preview"))}
+}
+
+func (d *DataExportPreviewRepo) SaveDataExportPreview(ctx context.Context, preview *biz.DataExportPreview) error {
+	m, err := convertBizDataExportPreview(preview)
+	if err != nil {
+		return pkgErr.WrapStorageErr(d.log, fmt.Errorf("failed to convert data export preview: %v", err))
+	}
+	return transaction(d.log, ctx, d.db, func(tx *gorm.DB) error {
+		if err := tx.WithContext(ctx).Create(m).Error; err != nil {
+			return pkgErr.WrapStorageErr(d.log, fmt.Errorf("failed to save data export preview: %v", err))
+		}
+		return nil
+	})
+}
+
+func (d *DataExportPreviewRepo) ListDataExportPreviews(ctx context.Context, workflowUid string) ([]*biz.DataExportPreview, error) {
+	var models []*model.DataExportPreview
+	if err := transaction(d.log, ctx, d.db, func(tx *gorm.DB) error {
+		if err := tx.WithContext(ctx).Where("workflow_uid = ?", workflowUid).Order("created_at DESC").Find(&models).Error; err != nil {
+			return pkgErr.WrapStorageErr(d.log, fmt.Errorf("failed to list data export previews: %v", err))
+		}
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+	ret := make([]*biz.DataExportPreview, 0, len(models))
+	for _, m := range models {
+		preview, err := convertModelDataExportPreview(m)
+		if err != nil {
+			return nil, pkgErr.WrapStorageErr(d.log, fmt.Errorf("failed to convert data export preview: %v", err))
+		}
+		ret = append(ret, preview)
+	}
+	return ret, nil
+}
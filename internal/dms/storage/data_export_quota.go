@@ -0,0 +1,96 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/actiontech/dms/internal/dms/biz"
+	pkgErr "github.com/actiontech/dms/internal/dms/pkg/errors"
+	"github.com/actiontech/dms/internal/dms/storage/model"
+	utilLog "github.com/actiontech/dms/pkg/dms-common/pkg/log"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var _ biz.DataExportQuotaRepo = (*DataExportQuotaRepo)(nil)
+
+type DataExportQuotaRepo struct {
+	*Storage
+	log *utilLog.Helper
+}
+
+func NewDataExportQuotaRepo(log utilLog.Logger, s *Storage) *DataExportQuotaRepo {
+	return &DataExportQuotaRepo{Storage: s, log: utilLog.NewHelper(log, utilLog.WithMessageKey("storage.data_export_quota"))}
+}
+
+func (d *DataExportQuotaRepo) SaveDataExportQuota(ctx context.Context, quota *biz.DataExportQuota) error {
+	return transaction(d.log, ctx, d.db, func(tx *gorm.DB) error {
+		if err := tx.WithContext(ctx).Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "scope"}, {Name: "scope_uid"}},
+			DoUpdates: clause.AssignmentColumns([]string{"project_uid", "max_rows_per_sql", "max_file_size_bytes", "max_exports_per_user_per_day", "max_bytes_per_project_per_month", "updated_at"}),
+		}).Create(convertBizDataExportQuota(quota)).Error; err != nil {
+			return pkgErr.WrapStorageErr(d.log, fmt.Errorf("failed to save data export quota: %v", err))
+		}
+		return nil
+	})
+}
+
+func (d *DataExportQuotaRepo) DeleteDataExportQuota(ctx context.Context, scope biz.DataExportQuotaScope, scopeUid string) error {
+	return transaction(d.log, ctx, d.db, func(tx *gorm.DB) error {
+		if err := tx.WithContext(ctx).Where("scope = ? AND scope_uid = ?", scope, scopeUid).Delete(&model.DataExportQuota{}).Error; err != nil {
+			return pkgErr.WrapStorageErr(d.log, fmt.Errorf("failed to delete data export quota: %v", err))
+		}
+		return nil
+	})
+}
+
+func (d *DataExportQuotaRepo) ListDataExportQuotas(ctx context.Context, projectUid string) ([]*biz.DataExportQuota, error) {
+	var models []*model.DataExportQuota
+	if err := transaction(d.log, ctx, d.db, func(tx *gorm.DB) error {
+		db := tx.WithContext(ctx)
+		if projectUid != "" {
+			db = db.Where("scope = ? OR project_uid = ?", biz.DataExportQuotaScopeGlobal, projectUid)
+		}
+		if err := db.Order("scope, scope_uid").Find(&models).Error; err != nil {
+			return pkgErr.WrapStorageErr(d.log, fmt.Errorf("failed to list data export quotas: %v", err))
+		}
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+	ret := make([]*biz.DataExportQuota, 0, len(models))
+	for _, m := range models {
+		ret = append(ret, convertModelDataExportQuota(m))
+	}
+	return ret, nil
+}
+
+func (d *DataExportQuotaRepo) SaveDataExportUsage(ctx context.Context, usage *biz.DataExportUsage) error {
+	return transaction(d.log, ctx, d.db, func(tx *gorm.DB) error {
+		if err := tx.WithContext(ctx).Create(convertBizDataExportUsage(usage)).Error; err != nil {
+			return pkgErr.WrapStorageErr(d.log, fmt.Errorf("failed to save data export usage: %v", err))
+		}
+		return nil
+	})
+}
+
+func (d *DataExportQuotaRepo) SumDataExportUsage(ctx context.Context, filter *biz.DataExportUsageFilter) (*biz.DataExportUsageSummary, error) {
+	summary := &biz.DataExportUsageSummary{}
+	if err := transaction(d.log, ctx, d.db, func(tx *gorm.DB) error {
+		db := tx.WithContext(ctx).Model(&model.DataExportUsage{}).Where("created_at >= ?", filter.Since)
+		if filter.ProjectUID != "" {
+			db = db.Where("project_uid = ?", filter.ProjectUID)
+		}
+		if filter.UserUID != "" {
+			db = db.Where("user_uid = ?", filter.UserUID)
+		}
+		if err := db.Select("COUNT(*) AS exports, COALESCE(SUM(export_bytes), 0) AS bytes").Scan(summary).Error; err != nil {
+			return pkgErr.WrapStorageErr(d.log, fmt.Errorf("failed to sum data export usage: %v", err))
+		}
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+	return summary, nil
+}
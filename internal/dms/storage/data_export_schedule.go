@@ -0,0 +1,112 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/actiontech/dms/internal/dms/biz"
+	pkgErr "github.com/actiontech/dms/internal/dms/pkg/errors"
+	"github.com/actiontech/dms/internal/dms/storage/model"
+	utilLog "github.com/actiontech/dms/pkg/dms-common/pkg/log"
+
+	"gorm.io/gorm"
+)
+
+var _ biz.DataExportScheduleRepo = (*DataExportScheduleRepo)(nil)
+
+type DataExportScheduleRepo struct {
+	*Storage
+	log *utilLog.Helper
+}
+
+func NewDataExportScheduleRepo(log utilLog.Logger, s *Storage) *DataExportScheduleRepo {
+	return &DataExportScheduleRepo{Storage: s, log: utilLog.NewHelper(log, utilLog.WithMessageKey("storage.data_export_schedule"))}
+}
+
+func (d *DataExportScheduleRepo) SaveDataExportSchedule(ctx context.Context, schedule *biz.DataExportSchedule) error {
+	return transaction(d.log, ctx, d.db, func(tx *gorm.DB) error {
+		if err := tx.WithContext(ctx).Create(convertBizDataExportSchedule(schedule)).Error; err != nil {
+			return pkgErr.WrapStorageErr(d.log, fmt.Errorf("failed to save data export schedule: %v", err))
+		}
+		return nil
+	})
+}
+
+func (d *DataExportScheduleRepo) UpdateDataExportSchedule(ctx context.Context, schedule *biz.DataExportSchedule) error {
+	m := convertBizDataExportSchedule(schedule)
+	return transaction(d.log, ctx, d.db, func(tx *gorm.DB) error {
+		if err := tx.WithContext(ctx).Model(&model.DataExportSchedule{}).Where("uid = ?", schedule.UID).Updates(map[string]interface{}{
+			"cron_expression":       m.CronExpression,
+			"end_time":              m.EndTime,
+			"status":                m.Status,
+			"suspend_reason":        m.SuspendReason,
+			"next_run_at":           m.NextRunAt,
+			"last_run_at":           m.LastRunAt,
+			"last_run_workflow_uid": m.LastRunWorkflowUID,
+			"last_run_error":        m.LastRunError,
+		}).Error; err != nil {
+			return pkgErr.WrapStorageErr(d.log, fmt.Errorf("failed to update data export schedule: %v", err))
+		}
+		return nil
+	})
+}
+
+func (d *DataExportScheduleRepo) GetDataExportScheduleByWorkflow(ctx context.Context, workflowUid string) (*biz.DataExportSchedule, error) {
+	var m model.DataExportSchedule
+	if err := transaction(d.log, ctx, d.db, func(tx *gorm.DB) error {
+		if err := tx.WithContext(ctx).Where("workflow_uid = ?", workflowUid).First(&m).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return pkgErr.ErrStorageNoData
+			}
+			return pkgErr.WrapStorageErr(d.log, fmt.Errorf("failed to get data export schedule: %v", err))
+		}
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+	return convertModelDataExportSchedule(&m), nil
+}
+
+func (d *DataExportScheduleRepo) ListDataExportSchedules(ctx context.Context, projectUid, createUserUid string) ([]*biz.DataExportSchedule, error) {
+	return d.listDataExportSchedules(ctx, func(db *gorm.DB) *gorm.DB {
+		db = db.Where("project_uid = ?", projectUid)
+		if createUserUid != "" {
+			db = db.Where("create_user_uid = ?", createUserUid)
+		}
+		return db.Order("created_at DESC")
+	})
+}
+
+func (d *DataExportScheduleRepo) ListDueDataExportSchedules(ctx context.Context, now time.Time) ([]*biz.DataExportSchedule, error) {
+	return d.listDataExportSchedules(ctx, func(db *gorm.DB) *gorm.DB {
+		return db.Where("status = ? AND next_run_at <= ?", biz.DataExportScheduleStatusActive, now).Order("next_run_at")
+	})
+}
+
+func (d *DataExportScheduleRepo) listDataExportSchedules(ctx context.Context, scope func(db *gorm.DB) *gorm.DB) ([]*biz.DataExportSchedule, error) {
+	var models []*model.DataExportSchedule
+	if err := transaction(d.log, ctx, d.db, func(tx *gorm.DB) error {
+		if err := scope(tx.WithContext(ctx)).Find(&models).Error; err != nil {
+			return pkgErr.WrapStorageErr(d.log, fmt.Errorf("failed to list data export schedules: %v", err))
+		}
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+	ret := make([]*biz.DataExportSchedule, 0, len(models))
+	for _, m := range models {
+		ret = append(ret, convertModelDataExportSchedule(m))
+	}
+	return ret, nil
+}
+
+func (d *DataExportScheduleRepo) DeleteDataExportSchedule(ctx context.Context, uid string) error {
+	return transaction(d.log, ctx, d.db, func(tx *gorm.DB) error {
+		if err := tx.WithContext(ctx).Where("uid = ?", uid).Delete(&model.DataExportSchedule{}).Error; err != nil {
+			return pkgErr.WrapStorageErr(d.log, fmt.Errorf("failed to delete data export schedule: %v", err))
+		}
+		return nil
+	})
+}
@@ -0,0 +1,62 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/actiontech/dms/internal/dms/biz"
+	pkgErr "github.com/actiontech/dms/internal/dms/pkg/errors"
+	"github.com/actiontech/dms/internal/dms/storage/model"
+	utilLog "github.com/actiontech/dms/pkg/dms-common/pkg/log"
+
+	"gorm.io/gorm"
+)
+
+var _ biz.DataExportWatermarkRepo = (*DataExportWatermarkRepo)(nil)
+
+type DataExportWatermarkRepo struct {
+	*Storage
+	log *utilLog.Helper
+}
+
+func NewDataExportWatermarkRepo(log utilLog.Logger, s *Storage) *DataExportWatermarkRepo {
+	return &DataExportWatermarkRepo{Storage: s, log: utilLog.NewHelper(log, utilLog.WithMessageKey("storage.data_export_watermark"))}
+}
+
+func (d *DataExportWatermarkRepo) SaveDataExportWatermark(ctx context.Context, watermark *biz.DataExportWatermark) error {
+	return transaction(d.log, ctx, d.db, func(tx *gorm.DB) error {
+		if err := tx.WithContext(ctx).Create(convertBizDataExportWatermark(watermark)).Error; err != nil {
+			return pkgErr.WrapStorageErr(d.log, fmt.Errorf("failed to save data export watermark: %v", err))
+		}
+		return nil
+	})
+}
+
+func (d *DataExportWatermarkRepo) GetDataExportWatermarksByFingerprints(ctx context.Context, fingerprints []string) ([]*biz.DataExportWatermark, error) {
+	return d.listDataExportWatermarks(ctx, func(db *gorm.DB) *gorm.DB {
+		return db.Where("fingerprint IN (?)", fingerprints)
+	})
+}
+
+func (d *DataExportWatermarkRepo) ListDataExportWatermarksByTask(ctx context.Context, taskUid string, kind biz.DataExportWatermarkKind) ([]*biz.DataExportWatermark, error) {
+	return d.listDataExportWatermarks(ctx, func(db *gorm.DB) *gorm.DB {
+		return db.Where("task_uid = ? AND kind = ?", taskUid, kind)
+	})
+}
+
+func (d *DataExportWatermarkRepo) listDataExportWatermarks(ctx context.Context, scope func(db *gorm.DB) *gorm.DB) ([]*biz.DataExportWatermark, error) {
+	var models []*model.DataExportWatermark
+	if err := transaction(d.log, ctx, d.db, func(tx *gorm.DB) error {
+		if err := scope(tx.WithContext(ctx)).Order("created_at").Find(&models).Error; err != nil {
+			return pkgErr.WrapStorageErr(d.log, fmt.Errorf("failed to list data export watermarks: %v", err))
+		}
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+	ret := make([]*biz.DataExportWatermark, 0, len(models))
+	for _, m := range models {
+		ret = append(ret, convertModelDataExportWatermark(m))
+	}
+	return ret, nil
+}
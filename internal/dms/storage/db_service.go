@@ -3,6 +3,7 @@ package storage
 import (
 	"context"
 	"fmt"
+	"net/url"
 	"strings"
 
 	"github.com/actiontech/dms/internal/dms/biz"
@@ -11,6 +12,7 @@ import (
 	"github.com/actiontech/dms/internal/dms/storage/model"
 
 	utilLog "github.com/actiontech/dms/pkg/dms-common/pkg/log"
+	sqlop "github.com/actiontech/dms/pkg/dms-common/sql_op"
 	"gorm.io/gorm"
 )
 
@@ -173,10 +175,58 @@ func (d *DBServiceRepo) DelDBService(ctx context.Context, dbServiceUid string) e
 			}
 		}
 
+		// 库、模式、表级别的权限范围中包含所属数据源，同样需要移除
+		objectRangeTypes := []string{biz.OpRangeTypeDatabase.String(), biz.OpRangeTypeSchema.String(), biz.OpRangeTypeTable.String()}
+		objectRangePattern := "%" + url.QueryEscape(dbServiceUid) + "/%"
+
+		var memberObjectItems []*model.MemberRoleOpRange
+		if err := tx.WithContext(ctx).Where("op_range_type in (?) and range_uids like ?", objectRangeTypes, objectRangePattern).Find(&memberObjectItems).Error; err != nil {
+			return pkgErr.WrapStorageErr(d.log, fmt.Errorf("failed to find member_role_op_range err: %v", err))
+		}
+
+		for _, item := range memberObjectItems {
+			rangeUIDs, changed := removeObjectRangesOfDBService(item.RangeUIDs, dbServiceUid)
+			if !changed {
+				continue
+			}
+			if err := tx.WithContext(ctx).Model(&model.MemberRoleOpRange{}).Where("member_uid = ? and role_uid = ? and op_range_type = ?", item.MemberUID, item.RoleUID, item.OpRangeType).Update("range_uids", rangeUIDs).Error; err != nil {
+				return pkgErr.WrapStorageErr(d.log, fmt.Errorf("failed to update member_role_op_range err: %v", err))
+			}
+		}
+
+		var memberGroupObjectItems []*model.MemberGroupRoleOpRange
+		if err := tx.WithContext(ctx).Where("op_range_type in (?) and range_uids like ?", objectRangeTypes, objectRangePattern).Find(&memberGroupObjectItems).Error; err != nil {
+			return pkgErr.WrapStorageErr(d.log, fmt.Errorf("failed to find member_group_role_op_range err: %v", err))
+		}
+
+		for _, item := range memberGroupObjectItems {
+			rangeUIDs, changed := removeObjectRangesOfDBService(item.RangeUIDs, dbServiceUid)
+			if !changed {
+				continue
+			}
+			if err := tx.WithContext(ctx).Model(&model.MemberGroupRoleOpRange{}).Where("member_group_uid = ? and role_uid = ? and op_range_type = ?", item.MemberGroupUID, item.RoleUID, item.OpRangeType).Update("range_uids", rangeUIDs).Error; err != nil {
+				return pkgErr.WrapStorageErr(d.log, fmt.Errorf("failed to update member_group_role_op_range err: %v", err))
+			}
+		}
+
 		return nil
 	})
 }
 
+// removeObjectRangesOfDBService 移除属于 dbServiceUid 的对象范围，返回移除后的范围及是否有变化
+func removeObjectRangesOfDBService(rangeUIDs string, dbServiceUid string) (string, bool) {
+	var remains []string
+	changed := false
+	for _, uid := range strings.Split(rangeUIDs, ",") {
+		if objectRange, err := sqlop.DecodeObjectRange(uid); err == nil && objectRange.DBServiceUID == dbServiceUid {
+			changed = true
+			continue
+		}
+		remains = append(remains, uid)
+	}
+	return strings.Join(remains, ","), changed
+}
+
 func (d *DBServiceRepo) GetDBServices(ctx context.Context, conditions []pkgConst.FilterCondition) (services []*biz.DBService, err error) {
 	var models []*model.DBService
 	if err := transaction(d.log, ctx, d.db, func(tx *gorm.DB) error {
@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"time"
 
 	"github.com/actiontech/dms/internal/dms/biz"
 	pkgErr "github.com/actiontech/dms/internal/dms/pkg/errors"
@@ -154,6 +155,28 @@ func (d *MemberRepo) DelMember(ctx context.Context, memberUid string) error {
 	})
 }
 
+func (d *MemberRepo) ListExpiredMembers(ctx context.Context, now time.Time) ([]*biz.Member, error) {
+	var models []*model.Member
+	if err := transaction(d.log, ctx, d.db, func(tx *gorm.DB) error {
+		if err := tx.WithContext(ctx).Preload("RoleWithOpRanges").Where("expires_at IS NOT NULL AND expires_at <= ?", now).Find(&models).Error; err != nil {
+			return fmt.Errorf("failed to list expired members: %v", err)
+		}
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+
+	members := make([]*biz.Member, 0, len(models))
+	for _, m := range models {
+		member, err := convertModelMember(m)
+		if err != nil {
+			return nil, pkgErr.WrapStorageErr(d.log, fmt.Errorf("failed to convert model member: %v", err))
+		}
+		members = append(members, member)
+	}
+	return members, nil
+}
+
 func (d *MemberRepo) DelRoleFromAllMembers(ctx context.Context, roleUid string) error {
 	return transaction(d.log, ctx, d.db, func(tx *gorm.DB) error {
 		if err := tx.WithContext(ctx).Where("role_uid = ?", roleUid).Delete(&model.MemberRoleOpRange{}).Error; err != nil {
@@ -0,0 +1,98 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/actiontech/dms/internal/dms/biz"
+	pkgErr "github.com/actiontech/dms/internal/dms/pkg/errors"
+	"github.com/actiontech/dms/internal/dms/storage/model"
+	utilLog "github.com/actiontech/dms/pkg/dms-common/pkg/log"
+
+	"gorm.io/gorm"
+)
+
+var _ biz.MemberAccessRequestRepo = (*MemberAccessRequestRepo)(nil)
+
+type MemberAccessRequestRepo struct {
+	*Storage
+	log *utilLog.Helper
+}
+
+func NewMemberAccessRequestRepo(log utilLog.Logger, s *Storage) *MemberAccessRequestRepo {
+	return &MemberAccessRequestRepo{Storage: s, log: utilLog.NewHelper(log, utilLog.WithMessageKey("storage.member_access_request"))}
+}
+
+func (d *MemberAccessRequestRepo) SaveMemberAccessRequest(ctx context.Context, r *biz.MemberAccessRequest) error {
+	return transaction(d.log, ctx, d.db, func(tx *gorm.DB) error {
+		if err := tx.WithContext(ctx).Create(convertBizMemberAccessRequest(r)).Error; err != nil {
+			return pkgErr.WrapStorageErr(d.log, fmt.Errorf("failed to save member access request: %v", err))
+		}
+		return nil
+	})
+}
+
+func (d *MemberAccessRequestRepo) UpdateMemberAccessRequest(ctx context.Context, r *biz.MemberAccessRequest) error {
+	return transaction(d.log, ctx, d.db, func(tx *gorm.DB) error {
+		if err := tx.WithContext(ctx).Model(&model.MemberAccessRequest{}).Where("uid = ?", r.UID).Updates(map[string]interface{}{
+			"status":           string(r.Status),
+			"reviewer_uid":     r.ReviewerUID,
+			"review_comment":   r.ReviewComment,
+			"reviewed_at":      convertBizTimeToModel(r.ReviewedAt),
+			"member_group_uid": r.MemberGroupUID,
+		}).Error; err != nil {
+			return pkgErr.WrapStorageErr(d.log, fmt.Errorf("failed to update member access request: %v", err))
+		}
+		return nil
+	})
+}
+
+func (d *MemberAccessRequestRepo) GetMemberAccessRequest(ctx context.Context, uid string) (*biz.MemberAccessRequest, error) {
+	var m model.MemberAccessRequest
+	if err := transaction(d.log, ctx, d.db, func(tx *gorm.DB) error {
+		if err := tx.WithContext(ctx).Where("uid = ?", uid).First(&m).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return pkgErr.ErrStorageNoData
+			}
+			return pkgErr.WrapStorageErr(d.log, fmt.Errorf("failed to get member access request: %v", err))
+		}
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+	ret, err := convertModelMemberAccessRequest(&m)
+	if err != nil {
+		return nil, pkgErr.WrapStorageErr(d.log, fmt.Errorf("failed to convert member access request: %v", err))
+	}
+	return ret, nil
+}
+
+func (d *MemberAccessRequestRepo) ListMemberAccessRequests(ctx context.Context, opt *biz.ListMemberAccessRequestsOption) ([]*biz.MemberAccessRequest, error) {
+	var models []*model.MemberAccessRequest
+	if err := transaction(d.log, ctx, d.db, func(tx *gorm.DB) error {
+		db := tx.WithContext(ctx).Where("project_uid = ?", opt.ProjectUID)
+		if opt.FilterUserUID != "" {
+			db = db.Where("user_uid = ?", opt.FilterUserUID)
+		}
+		if opt.FilterByStatus != "" {
+			db = db.Where("status = ?", string(opt.FilterByStatus))
+		}
+		if err := db.Order("created_at DESC").Find(&models).Error; err != nil {
+			return pkgErr.WrapStorageErr(d.log, fmt.Errorf("failed to list member access requests: %v", err))
+		}
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+
+	ret := make([]*biz.MemberAccessRequest, 0, len(models))
+	for _, m := range models {
+		r, err := convertModelMemberAccessRequest(m)
+		if err != nil {
+			return nil, pkgErr.WrapStorageErr(d.log, fmt.Errorf("failed to convert member access request: %v", err))
+		}
+		ret = append(ret, r)
+	}
+	return ret, nil
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"time"
 
 	"github.com/actiontech/dms/internal/dms/biz"
 	pkgErr "github.com/actiontech/dms/internal/dms/pkg/errors"
@@ -30,7 +31,7 @@ func (d *MemberGroupRepo) ListMemberGroups(ctx context.Context, opt *biz.ListMem
 	if err = transaction(d.log, ctx, d.db, func(tx *gorm.DB) error {
 		// find models
 		{
-			db := tx.WithContext(ctx).Preload("RoleWithOpRanges").Preload("Users").Preload("OpPermissions").Order(opt.OrderBy)
+			db := tx.WithContext(ctx).Preload("RoleWithOpRanges").Preload("Users").Preload("UserGroup.Users").Preload("OpPermissions").Order(opt.OrderBy)
 			db = gormWheresWithOptions(ctx, db, opt.FilterByOptions)
 			db = db.Limit(int(opt.LimitPerPage)).Offset(int(opt.LimitPerPage * (uint32(fixPageIndices(opt.PageNumber)))))
 			if err = db.Find(&models).Error; err != nil {
@@ -67,7 +68,7 @@ func (d *MemberGroupRepo) ListMemberGroups(ctx context.Context, opt *biz.ListMem
 func (d *MemberGroupRepo) GetMemberGroup(ctx context.Context, memberGroupId string) (*biz.MemberGroup, error) {
 	var memberGroup model.MemberGroup
 	if err := transaction(d.log, ctx, d.db, func(tx *gorm.DB) error {
-		if err := tx.Preload("RoleWithOpRanges").Preload("Users").Where("uid = ?", memberGroupId).First(&memberGroup).Error; err != nil {
+		if err := tx.Preload("RoleWithOpRanges").Preload("Users").Preload("UserGroup.Users").Where("uid = ?", memberGroupId).First(&memberGroup).Error; err != nil {
 			return fmt.Errorf("failed to get member group: %v", err)
 		}
 
@@ -136,13 +137,37 @@ func (d *MemberGroupRepo) DeleteMemberGroup(ctx context.Context, memberGroupUid
 	})
 }
 
+func (d *MemberGroupRepo) ListExpiredMemberGroups(ctx context.Context, now time.Time) ([]*biz.MemberGroup, error) {
+	var models []*model.MemberGroup
+	if err := transaction(d.log, ctx, d.db, func(tx *gorm.DB) error {
+		if err := tx.WithContext(ctx).Preload("RoleWithOpRanges").Preload("Users").Preload("UserGroup.Users").Where("expires_at IS NOT NULL AND expires_at <= ?", now).Find(&models).Error; err != nil {
+			return fmt.Errorf("failed to list expired member groups: %v", err)
+		}
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+
+	memberGroups := make([]*biz.MemberGroup, 0, len(models))
+	for _, mg := range models {
+		memberGroup, err := convertModelMemberGroup(mg)
+		if err != nil {
+			return nil, pkgErr.WrapStorageErr(d.log, fmt.Errorf("failed to convert model member group: %v", err))
+		}
+		memberGroups = append(memberGroups, memberGroup)
+	}
+	return memberGroups, nil
+}
+
 func (d *MemberGroupRepo) GetMemberGroupsByUserIDAndProjectID(ctx context.Context, userID, projectID string) ([]*biz.MemberGroup, error) {
 	var memberGroups []*model.MemberGroup
 	if err := transaction(d.log, ctx, d.db, func(tx *gorm.DB) error {
 		// 查询关联的用户组
-		if err := tx.WithContext(ctx).Preload("RoleWithOpRanges").Preload("Users").Preload("OpPermissions").
-			Joins("JOIN member_group_users ON member_groups.uid = member_group_users.member_group_uid").
-			Where("member_group_users.user_uid = ? AND member_groups.project_uid = ?", userID, projectID).
+		if err := tx.WithContext(ctx).Preload("RoleWithOpRanges").Preload("Users").Preload("UserGroup.Users").Preload("OpPermissions").
+			Where("member_groups.project_uid = ?", projectID).
+			// 直接加入的成员组与绑定了用户所在用户组的成员组分别查询，禁用的用户组不生效
+			Where("(member_groups.uid IN (SELECT member_group_uid FROM member_group_users WHERE user_uid = ?) OR "+
+				"member_groups.user_group_uid IN (SELECT ugu.user_group_uid FROM user_group_users AS ugu JOIN user_groups AS ug ON ugu.user_group_uid = ug.uid AND ug.stat = 0 WHERE ugu.user_uid = ?))", userID, userID).
 			Find(&memberGroups).Error; err != nil {
 			return fmt.Errorf("failed to get member groups by user id and project id: %v", err)
 		}
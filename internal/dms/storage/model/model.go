@@ -27,6 +27,22 @@ var AutoMigrateList = []interface{}{
 	MemberRoleOpRange{},
 	MemberGroup{},
 	MemberGroupRoleOpRange{},
+	MemberAccessRequest{},
+	SeparationOfDutiesRule{},
+	AccessReviewCampaign{},
+	AccessReviewItem{},
+	DataExportSchedule{},
+	DataExportQuota{},
+	DataExportUsage{},
+	DataExportWatermark{},
+	DataExportDownload{},
+	DataExportDownloadLink{},
+	DataExportDeliveryTarget{},
+	WorkflowApprovalSLA{},
+	WorkflowApprovalSLARecord{},
+	DataExportPreview{},
+	PermissionCacheVersion{},
+	ServiceOpPermissionManifest{},
 	BusinessTag{},
 	Project{},
 	ProxyTarget{},
@@ -56,6 +72,9 @@ var AutoMigrateList = []interface{}{
 	DataExportTaskRecord{},
 	UserAccessToken{},
 	UserLoginSession{},
+	LoginSession{},
+	UserPasswordHistory{},
+	ServiceAccount{},
 	CbOperationLog{},
 	EnvironmentTag{},
 	OpsType{},
@@ -162,6 +181,11 @@ type User struct {
 	// 业务写权开关，默认 false
 	BusinessWritePermission bool `json:"business_write_permission" gorm:"column:business_write_permission;default:false;not null"`
 
+	PasswordChangedAt *time.Time `json:"password_changed_at" gorm:"column:password_changed_at"`
+	LoginFailedCount  uint       `json:"login_failed_count" gorm:"column:login_failed_count;default:0;not null"`
+	LoginFailedAt     *time.Time `json:"login_failed_at" gorm:"column:login_failed_at"`
+	LockedUntil       *time.Time `json:"locked_until" gorm:"column:locked_until"`
+
 	Members       []*Member       `gorm:"foreignKey:UserUID"`
 	UserGroups    []*UserGroup    `gorm:"many2many:user_group_users"`
 	OpPermissions []*OpPermission `gorm:"many2many:user_op_permissions"`
@@ -178,9 +202,16 @@ type UserGroup struct {
 
 type Role struct {
 	Model
-	Name string `json:"name" gorm:"size:200;uniqueIndex"`
+	// 全局角色的 project_uid 为空，角色名在同一项目（或全局）内唯一
+	Name string `json:"name" gorm:"size:200;uniqueIndex:idx_roles_project_uid_name,priority:2"`
 	Desc string `json:"desc" gorm:"column:description"`
 	Stat uint   `json:"stat" gorm:"size:32;not null"`
+	// 角色自身声明的权限，role_op_permissions 中为合并继承角色后的生效权限
+	OwnOpPermissionUIDs Strings `json:"own_op_permission_uids" gorm:"type:json"`
+	ParentRoleUIDs      Strings `json:"parent_role_uids" gorm:"type:json"`
+	IsTemplate          bool    `json:"is_template" gorm:"not null;default:false"`
+	TemplateUID         string  `json:"template_uid" gorm:"size:32;index;default:''"`
+	ProjectUID          string  `json:"project_uid" gorm:"size:32;index;uniqueIndex:idx_roles_project_uid_name,priority:1;default:''"`
 
 	OpPermissions []*OpPermission `gorm:"many2many:role_op_permissions"`
 }
@@ -192,17 +223,71 @@ type OpPermission struct {
 	Desc      string `json:"desc" gorm:"column:description"`
 	RangeType string `json:"range_type" gorm:"size:255;column:range_type"`
 	Service   string `json:"service" gorm:"size:255;column:service"`
+	// 以下字段仅用于注册服务提供的权限，内置权限的多语言名称在代码中维护
+	Provider string          `json:"provider" gorm:"size:255;column:provider;index;default:''"`
+	NameI18n i18nPkg.I18nStr `json:"name_i18n" gorm:"column:name_i18n;type:json"`
+	DescI18n i18nPkg.I18nStr `json:"desc_i18n" gorm:"column:desc_i18n;type:json"`
+	Removed  bool            `json:"removed" gorm:"column:removed;default:false"`
+}
+
+// ServiceOpPermissionManifest 注册服务最近一次同步的权限清单
+type ServiceOpPermissionManifest struct {
+	Model
+	ServiceName   string               `json:"service_name" gorm:"size:200;column:service_name;uniqueIndex;not null"`
+	Version       string               `json:"version" gorm:"size:255;column:version;not null"`
+	OpPermissions ServiceOpPermissions `json:"op_permissions" gorm:"type:json"`
+}
+
+type ServiceOpPermission struct {
+	UID             string            `json:"uid"`
+	Name            map[string]string `json:"name"`
+	Desc            map[string]string `json:"desc"`
+	Module          string            `json:"module"`
+	RangeType       string            `json:"range_type"`
+	BuiltinRoleUIDs []string          `json:"builtin_role_uids"`
+}
+
+type ServiceOpPermissions []ServiceOpPermission
+
+func (s ServiceOpPermissions) Value() (driver.Value, error) {
+	b, err := json.Marshal(s)
+	return string(b), err
+}
+
+func (s *ServiceOpPermissions) Scan(input interface{}) error {
+	if input == nil {
+		return nil
+	}
+	switch v := input.(type) {
+	case []byte:
+		return json.Unmarshal(v, s)
+	case string:
+		return json.Unmarshal([]byte(v), s)
+	default:
+		return fmt.Errorf("failed to scan ServiceOpPermissions: expected []byte or string, got %T", input)
+	}
 }
 
 type UserAccessToken struct {
 	Model
-	Token       string    `json:"token" gorm:"size:255"`
-	ExpiredTime time.Time `json:"expired_time" example:"2018-10-21T16:40:23+08:00"`
-	UserID      uint      `json:"user_id" gorm:"size:32;index:user_id,unique"`
+	Name        string     `json:"name" gorm:"size:200"`
+	Token       string     `json:"token" gorm:"type:text"`
+	ExpiredTime time.Time  `json:"expired_time" example:"2018-10-21T16:40:23+08:00"`
+	UserID      uint       `json:"user_id" gorm:"size:32;index"`
+	ReadOnly    bool       `json:"read_only" gorm:"not null;default:false"`
+	ProjectUIDs Strings    `json:"project_uids" gorm:"type:json"`
+	LastUsedAt  *time.Time `json:"last_used_at"`
+	LastUsedIP  string     `json:"last_used_ip" gorm:"size:64"`
 
 	User *User `json:"user" gorm:"foreignkey:user_id"`
 }
 
+// UserAccessTokenLegacyUniqueIndex 早期版本每个用户仅允许一个 access token，迁移时需移除该唯一索引
+const UserAccessTokenLegacyUniqueIndex = "user_id"
+
+// RoleLegacyNameUniqueIndex 早期版本角色名全局唯一，项目内角色允许与其他项目重名，迁移时需移除该唯一索引
+const RoleLegacyNameUniqueIndex = "idx_roles_name"
+
 type DMSConfig struct {
 	Model
 	NeedInitOpPermissions          bool `json:"need_init_op_permissions" gorm:"column:need_init_op_permissions"`
@@ -220,6 +305,7 @@ type Member struct {
 	User             *User               `json:"user" gorm:"foreignkey:UserUID"`
 	RoleWithOpRanges []MemberRoleOpRange `json:"role_with_op_ranges" gorm:"foreignKey:MemberUID;references:UID"`
 	OpPermissions    []*OpPermission     `json:"op_permissions" gorm:"many2many:member_op_permissions"`
+	ExpiresAt        *time.Time          `json:"expires_at" gorm:"column:expires_at;index"`
 }
 
 type MemberRoleOpRange struct {
@@ -240,6 +326,9 @@ type MemberGroup struct {
 	Users            []*User                  `gorm:"many2many:member_group_users"`
 	RoleWithOpRanges []MemberGroupRoleOpRange `json:"role_with_op_ranges" gorm:"foreignKey:MemberGroupUID;references:UID"`
 	OpPermissions    []OpPermission           `json:"op_permissions" gorm:"many2many:member_group_op_permissions"`
+	ExpiresAt        *time.Time               `json:"expires_at" gorm:"column:expires_at;index"`
+	UserGroupUID     string                   `json:"user_group_uid" gorm:"size:32;column:user_group_uid;index"`
+	UserGroup        *UserGroup               `gorm:"foreignKey:UserGroupUID;references:UID"`
 }
 
 type MemberGroupRoleOpRange struct {
@@ -253,6 +342,91 @@ func (mg *MemberGroupRoleOpRange) AfterSave(tx *gorm.DB) error {
 	return tx.Delete(&MemberGroupRoleOpRange{}, "member_group_uid IS NULL").Error
 }
 
+// MemberAccessRequest 项目临时权限申请，申请的角色及范围以 json 保存，审批通过后才写入成员组
+type MemberAccessRequest struct {
+	Model
+	ProjectUID       string           `json:"project_uid" gorm:"size:32;column:project_uid;index;not null"`
+	UserUID          string           `json:"user_uid" gorm:"size:32;column:user_uid;index;not null"`
+	RoleWithOpRanges RoleWithOpRanges `json:"role_with_op_ranges" gorm:"type:json"`
+	DurationHours    uint             `json:"duration_hours" gorm:"column:duration_hours;not null"`
+	Reason           string           `json:"reason" gorm:"size:512;column:reason"`
+	Status           string           `json:"status" gorm:"size:32;column:status;index;not null"`
+	ReviewerUID      string           `json:"reviewer_uid" gorm:"size:32;column:reviewer_uid"`
+	ReviewComment    string           `json:"review_comment" gorm:"size:512;column:review_comment"`
+	ReviewedAt       *time.Time       `json:"reviewed_at" gorm:"column:reviewed_at"`
+	MemberGroupUID   string           `json:"member_group_uid" gorm:"size:32;column:member_group_uid"`
+}
+
+// SeparationOfDutiesRule 项目内的职责分离规则
+type SeparationOfDutiesRule struct {
+	Model
+	ProjectUID       string  `json:"project_uid" gorm:"size:32;column:project_uid;index;not null"`
+	Type             string  `json:"type" gorm:"size:64;column:type;not null"`
+	OpPermissionUIDs Strings `json:"op_permission_uids" gorm:"type:json;column:op_permission_uids"`
+	WorkflowType     string  `json:"workflow_type" gorm:"size:64;column:workflow_type"`
+	Desc             string  `json:"desc" gorm:"size:512;column:desc"`
+	CreateUserUID    string  `json:"create_user_uid" gorm:"size:32;column:create_user_uid"`
+}
+
+// AccessReviewCampaign 权限复核活动
+type AccessReviewCampaign struct {
+	Model
+	Name          string     `json:"name" gorm:"size:200;column:name;not null"`
+	ProjectUIDs   Strings    `json:"project_uids" gorm:"type:json;column:project_uids"`
+	RoleUIDs      Strings    `json:"role_uids" gorm:"type:json;column:role_uids"`
+	Deadline      time.Time  `json:"deadline" gorm:"column:deadline;index;not null"`
+	AutoRevoke    bool       `json:"auto_revoke" gorm:"column:auto_revoke;not null"`
+	Status        string     `json:"status" gorm:"size:32;column:status;index;not null"`
+	CreateUserUID string     `json:"create_user_uid" gorm:"size:32;column:create_user_uid"`
+	CompletedAt   *time.Time `json:"completed_at" gorm:"column:completed_at"`
+}
+
+// AccessReviewItem 权限复核项，保存创建活动时的授权快照及复核结果
+type AccessReviewItem struct {
+	Model
+	CampaignUID      string           `json:"campaign_uid" gorm:"size:32;column:campaign_uid;index;not null"`
+	ProjectUID       string           `json:"project_uid" gorm:"size:32;column:project_uid;not null"`
+	ProjectName      string           `json:"project_name" gorm:"size:200;column:project_name"`
+	SubjectType      string           `json:"subject_type" gorm:"size:32;column:subject_type;not null"`
+	SubjectUID       string           `json:"subject_uid" gorm:"size:32;column:subject_uid;not null"`
+	SubjectName      string           `json:"subject_name" gorm:"size:200;column:subject_name"`
+	UserUID          string           `json:"user_uid" gorm:"size:32;column:user_uid;not null"`
+	UserName         string           `json:"user_name" gorm:"size:200;column:user_name"`
+	RoleWithOpRanges RoleWithOpRanges `json:"role_with_op_ranges" gorm:"type:json"`
+	LastActiveAt     *time.Time       `json:"last_active_at" gorm:"column:last_active_at"`
+	Decision         string           `json:"decision" gorm:"size:32;column:decision;index;not null"`
+	ReviewerUID      string           `json:"reviewer_uid" gorm:"size:32;column:reviewer_uid"`
+	Comment          string           `json:"comment" gorm:"size:512;column:comment"`
+	ReviewedAt       *time.Time       `json:"reviewed_at" gorm:"column:reviewed_at"`
+}
+
+type RoleWithOpRange struct {
+	RoleUID     string   `json:"role_uid"`
+	OpRangeType string   `json:"op_range_type"`
+	RangeUIDs   []string `json:"range_uids"`
+}
+
+type RoleWithOpRanges []RoleWithOpRange
+
+func (r RoleWithOpRanges) Value() (driver.Value, error) {
+	b, err := json.Marshal(r)
+	return string(b), err
+}
+
+func (r *RoleWithOpRanges) Scan(input interface{}) error {
+	if input == nil {
+		return nil
+	}
+	switch v := input.(type) {
+	case []byte:
+		return json.Unmarshal(v, r)
+	case string:
+		return json.Unmarshal([]byte(v), r)
+	default:
+		return fmt.Errorf("failed to scan RoleWithOpRanges: expected []byte or string, got %T", input)
+	}
+}
+
 type Project struct {
 	Model
 	Name           string `json:"name" gorm:"size:200;column:name;index:name,unique"`
@@ -308,6 +482,24 @@ type LoginConfiguration struct {
 	LoginButtonText      string `json:"login_button_text" gorm:"column:login_button_text;size:255;default:'登录';not null"`
 	DisableUserPwdLogin  bool   `json:"disable_user_pwd_login" gorm:"column:disable_user_pwd_login;default:false;not null"`
 	DisableMultipleLogin bool   `json:"disable_multiple_login" gorm:"column:disable_multiple_login;default:false;not null"`
+
+	PasswordMinLength        uint `json:"password_min_length" gorm:"column:password_min_length;default:0;not null"`
+	PasswordRequireUppercase bool `json:"password_require_uppercase" gorm:"column:password_require_uppercase;default:false;not null"`
+	PasswordRequireLowercase bool `json:"password_require_lowercase" gorm:"column:password_require_lowercase;default:false;not null"`
+	PasswordRequireDigit     bool `json:"password_require_digit" gorm:"column:password_require_digit;default:false;not null"`
+	PasswordRequireSpecial   bool `json:"password_require_special" gorm:"column:password_require_special;default:false;not null"`
+	PasswordHistoryCount     uint `json:"password_history_count" gorm:"column:password_history_count;default:0;not null"`
+	PasswordMaxAgeDays       uint `json:"password_max_age_days" gorm:"column:password_max_age_days;default:0;not null"`
+	LockoutMaxFailedAttempts uint `json:"lockout_max_failed_attempts" gorm:"column:lockout_max_failed_attempts;default:0;not null"`
+	LockoutWindowMinutes     uint `json:"lockout_window_minutes" gorm:"column:lockout_window_minutes;default:15;not null"`
+	LockoutDurationMinutes   uint `json:"lockout_duration_minutes" gorm:"column:lockout_duration_minutes;default:30;not null"`
+
+	SessionIdleTimeoutMinutes      uint `json:"session_idle_timeout_minutes" gorm:"column:session_idle_timeout_minutes;default:0;not null"`
+	SessionMaxAgeMinutes           uint `json:"session_max_age_minutes" gorm:"column:session_max_age_minutes;default:0;not null"`
+	AdminSessionIdleTimeoutMinutes uint `json:"admin_session_idle_timeout_minutes" gorm:"column:admin_session_idle_timeout_minutes;default:0;not null"`
+	AdminSessionMaxAgeMinutes      uint `json:"admin_session_max_age_minutes" gorm:"column:admin_session_max_age_minutes;default:0;not null"`
+	AccessTokenIdleTimeoutDays     uint `json:"access_token_idle_timeout_days" gorm:"column:access_token_idle_timeout_days;default:0;not null"`
+	AccessTokenMaxAgeDays          uint `json:"access_token_max_age_days" gorm:"column:access_token_max_age_days;default:0;not null"`
 }
 
 // UserLoginSession stores the latest active login session ID per user.
@@ -316,6 +508,35 @@ type UserLoginSession struct {
 	UserUID   string `json:"user_uid" gorm:"size:32;column:user_uid;uniqueIndex"`
 	SessionID string `json:"session_id" gorm:"size:32;column:session_id;not null"`
 }
+
+// LoginSession 用户登录会话记录，UID 即 token 中的会话 ID
+type LoginSession struct {
+	Model
+	UserUID    string     `json:"user_uid" gorm:"size:32;column:user_uid;index"`
+	LoginType  string     `json:"login_type" gorm:"size:32;column:login_type"`
+	IP         string     `json:"ip" gorm:"size:64;column:ip"`
+	UserAgent  string     `json:"user_agent" gorm:"size:512;column:user_agent"`
+	LastSeenAt *time.Time `json:"last_seen_at" gorm:"column:last_seen_at"`
+	ExpiredAt  time.Time  `json:"expired_at" gorm:"column:expired_at;index"`
+	RevokedAt  *time.Time `json:"revoked_at" gorm:"column:revoked_at"`
+}
+
+// UserPasswordHistory stores salted hashes of passwords previously used by a user.
+type UserPasswordHistory struct {
+	Model
+	UserUID      string `json:"user_uid" gorm:"size:32;column:user_uid;index"`
+	PasswordHash string `json:"password_hash" gorm:"size:255;column:password_hash;not null"`
+}
+
+// ServiceAccount 服务账号的 client credentials，服务账号本身以 users 表中的用户存在
+type ServiceAccount struct {
+	Model
+	UserUID          string    `json:"user_uid" gorm:"size:32;column:user_uid;uniqueIndex;not null"`
+	ClientID         string    `json:"client_id" gorm:"size:64;column:client_id;uniqueIndex;not null"`
+	ClientSecretHash string    `json:"client_secret_hash" gorm:"size:255;column:client_secret_hash;not null"`
+	SecretRotatedAt  time.Time `json:"secret_rotated_at" gorm:"column:secret_rotated_at"`
+}
+
 // Oauth2Configuration store oauth2 server configuration.
 type Oauth2Configuration struct {
 	Model
@@ -483,6 +704,12 @@ type ClusterLeader struct {
 	LastSeenTime time.Time `gorm:"not null"`
 }
 
+// PermissionCacheVersion 集群各节点共享的权限缓存版本号，权限变更时递增
+type PermissionCacheVersion struct {
+	Anchor  int    `gorm:"primary_key"` // 常量值，保证该表仅有一行不重复记录。无其他意义。
+	Version uint64 `gorm:"not null;default:0"`
+}
+
 type ClusterNodeInfo struct {
 	ServerId     string    `json:"server_id" gorm:"primary_key"`
 	HardwareSign string    `json:"hardware_sign" gorm:"type:varchar(3000)"`
@@ -502,6 +729,14 @@ type Workflow struct {
 	WorkflowTemplateName string     `json:"workflow_template_name" gorm:"size:255;column:workflow_template_name" example:""`
 	// OpsTypeUID 运维类型字典项标识（引用式存储；空表示未设置；创建后不可改）
 	OpsTypeUID string `json:"ops_type_uid" gorm:"size:32;column:ops_type_uid;default:''"`
+	// EncryptExport 导出文件是否加密
+	EncryptExport bool `json:"encrypt_export" gorm:"column:encrypt_export;default:false"`
+	// ExportPassword 加密后的解压密码
+	ExportPassword string `json:"export_password" gorm:"size:255;column:export_password"`
+	// MaxDownloads 每个导出任务的最大下载次数，0 表示不限制
+	MaxDownloads int `json:"max_downloads" gorm:"column:max_downloads;default:0"`
+	// DeliveryTargetUID 导出文件的投递目标
+	DeliveryTargetUID string `json:"delivery_target_uid" gorm:"size:32;column:delivery_target_uid"`
 
 	WorkflowRecord *WorkflowRecord `gorm:"foreignkey:WorkflowUid"`
 }
@@ -574,22 +809,195 @@ type WorkflowStep struct {
 	Assignees         Strings    `json:"assignees" gorm:"type:json"`
 }
 
+// DataExportSchedule 数据导出工单的定时导出，Tasks 为设置时冻结的导出任务
+type DataExportSchedule struct {
+	Model
+	ProjectUID         string                  `json:"project_uid" gorm:"size:32;column:project_uid;index;not null"`
+	WorkflowUID        string                  `json:"workflow_uid" gorm:"size:32;column:workflow_uid;uniqueIndex;not null"`
+	CreateUserUID      string                  `json:"create_user_uid" gorm:"size:32;column:create_user_uid;index;not null"`
+	CronExpression     string                  `json:"cron_expression" gorm:"size:64;column:cron_expression;not null"`
+	EndTime            *time.Time              `json:"end_time" gorm:"column:end_time"`
+	EncryptExport      bool                    `json:"encrypt_export" gorm:"column:encrypt_export;default:false"`
+	Tasks              DataExportScheduleTasks `json:"tasks" gorm:"type:json;column:tasks"`
+	Status             string                  `json:"status" gorm:"size:32;column:status;index;not null"`
+	SuspendReason      string                  `json:"suspend_reason" gorm:"size:512;column:suspend_reason"`
+	NextRunAt          *time.Time              `json:"next_run_at" gorm:"column:next_run_at;index"`
+	LastRunAt          *time.Time              `json:"last_run_at" gorm:"column:last_run_at"`
+	LastRunWorkflowUID string                  `json:"last_run_workflow_uid" gorm:"size:32;column:last_run_workflow_uid"`
+	LastRunError       string                  `json:"last_run_error" gorm:"size:512;column:last_run_error"`
+}
+
+type DataExportScheduleTask struct {
+	DBServiceUID       string    `json:"db_service_uid"`
+	DatabaseName       string    `json:"database_name"`
+	ExportSQL          string    `json:"export_sql"`
+	ExportFileType     string    `json:"export_file_type"`
+	AuditLevel         string    `json:"audit_level"`
+	DBServiceUpdatedAt time.Time `json:"db_service_updated_at"`
+}
+
+type DataExportScheduleTasks []DataExportScheduleTask
+
+func (t DataExportScheduleTasks) Value() (driver.Value, error) {
+	b, err := json.Marshal(t)
+	return string(b), err
+}
+
+func (t *DataExportScheduleTasks) Scan(input interface{}) error {
+	if input == nil {
+		return nil
+	}
+	switch v := input.(type) {
+	case []byte:
+		return json.Unmarshal(v, t)
+	case string:
+		return json.Unmarshal([]byte(v), t)
+	default:
+		return fmt.Errorf("failed to scan DataExportScheduleTasks: expected []byte or string, got %T", input)
+	}
+}
+
+// DataExportQuota 数据导出限制，每个范围（全局、项目、数据源）一份配置
+type DataExportQuota struct {
+	Model
+	Scope                      string `json:"scope" gorm:"size:32;column:scope;uniqueIndex:idx_data_export_quota_scope;not null"`
+	ScopeUID                   string `json:"scope_uid" gorm:"size:32;column:scope_uid;uniqueIndex:idx_data_export_quota_scope"`
+	ProjectUID                 string `json:"project_uid" gorm:"size:32;column:project_uid;index"`
+	MaxRowsPerSQL              int64  `json:"max_rows_per_sql" gorm:"column:max_rows_per_sql"`
+	MaxFileSizeBytes           int64  `json:"max_file_size_bytes" gorm:"column:max_file_size_bytes"`
+	MaxExportsPerUserPerDay    int64  `json:"max_exports_per_user_per_day" gorm:"column:max_exports_per_user_per_day"`
+	MaxBytesPerProjectPerMonth int64  `json:"max_bytes_per_project_per_month" gorm:"column:max_bytes_per_project_per_month"`
+}
+
+// DataExportUsage 导出任务完成后记录的用量，用于统计每日导出次数和每月导出数据量
+type DataExportUsage struct {
+	Model
+	ProjectUID   string `json:"project_uid" gorm:"size:32;column:project_uid;index"`
+	UserUID      string `json:"user_uid" gorm:"size:32;column:user_uid;index"`
+	DBServiceUID string `json:"db_service_uid" gorm:"size:32;column:db_service_uid"`
+	TaskUID      string `json:"task_uid" gorm:"size:32;column:task_uid"`
+	// ROWS 为 MySQL 保留字
+	Rows  int64 `json:"rows" gorm:"column:export_rows"`
+	Bytes int64 `json:"bytes" gorm:"column:export_bytes"`
+}
+
+// DataExportWatermark 导出和下载时写入文件的水印指纹，用于追溯泄露的文件
+type DataExportWatermark struct {
+	Model
+	Fingerprint string `json:"fingerprint" gorm:"size:16;column:fingerprint;uniqueIndex;not null"`
+	Kind        string `json:"kind" gorm:"size:32;column:kind;not null"`
+	TaskUID     string `json:"task_uid" gorm:"size:32;column:task_uid;index"`
+	WorkflowUID string `json:"workflow_uid" gorm:"size:32;column:workflow_uid"`
+	ProjectUID  string `json:"project_uid" gorm:"size:32;column:project_uid"`
+	UserUID     string `json:"user_uid" gorm:"size:32;column:user_uid"`
+}
+
+// DataExportDownload 导出文件的下载记录
+type DataExportDownload struct {
+	Model
+	TaskUID     string `json:"task_uid" gorm:"size:32;column:task_uid;index;not null"`
+	WorkflowUID string `json:"workflow_uid" gorm:"size:32;column:workflow_uid"`
+	ProjectUID  string `json:"project_uid" gorm:"size:32;column:project_uid;index"`
+	UserUID     string `json:"user_uid" gorm:"size:32;column:user_uid"`
+	IP          string `json:"ip" gorm:"size:64;column:ip"`
+	Bytes       int64  `json:"bytes" gorm:"column:export_bytes"`
+	StartOffset int64  `json:"start_offset" gorm:"column:start_offset;not null;default:0"`
+	Size        int64  `json:"size" gorm:"column:content_size;not null;default:0"`
+	Via         string `json:"via" gorm:"size:32;column:via"`
+	LinkUID     string `json:"link_uid" gorm:"size:32;column:link_uid"`
+	Fingerprint string `json:"fingerprint" gorm:"size:16;column:fingerprint"`
+	// Resumed 断点续传的请求，不计入下载次数
+	Resumed bool `json:"resumed" gorm:"column:resumed;not null;default:false"`
+}
+
+// DataExportDownloadLink 一次性下载链接，UsedAt 非空表示已使用
+type DataExportDownloadLink struct {
+	Model
+	TaskUID       string     `json:"task_uid" gorm:"size:32;column:task_uid;index;not null"`
+	ProjectUID    string     `json:"project_uid" gorm:"size:32;column:project_uid"`
+	CreateUserUID string     `json:"create_user_uid" gorm:"size:32;column:create_user_uid"`
+	ExpiresAt     time.Time  `json:"expires_at" gorm:"column:expires_at"`
+	UsedAt        *time.Time `json:"used_at" gorm:"column:used_at"`
+}
+
+// WorkflowApprovalSLA 项目的工单审批时效设置，单位为小时，0 表示不启用
+type WorkflowApprovalSLA struct {
+	Model
+	ProjectUID           string `json:"project_uid" gorm:"size:32;column:project_uid;uniqueIndex;not null"`
+	RemindAfterHours     int    `json:"remind_after_hours" gorm:"column:remind_after_hours;default:0"`
+	EscalateAfterHours   int    `json:"escalate_after_hours" gorm:"column:escalate_after_hours;default:0"`
+	AutoRejectAfterHours int    `json:"auto_reject_after_hours" gorm:"column:auto_reject_after_hours;default:0"`
+}
+
+// WorkflowApprovalSLARecord 工单审批步骤已发送的提醒及升级通知
+type WorkflowApprovalSLARecord struct {
+	Model
+	WorkflowUID string     `json:"workflow_uid" gorm:"size:32;column:workflow_uid;uniqueIndex:idx_workflow_approval_sla_step;not null"`
+	StepID      uint64     `json:"step_id" gorm:"column:step_id;uniqueIndex:idx_workflow_approval_sla_step"`
+	RemindedAt  *time.Time `json:"reminded_at" gorm:"column:reminded_at"`
+	EscalatedAt *time.Time `json:"escalated_at" gorm:"column:escalated_at"`
+}
+
+// DataExportPreview 审批时生成的导出预览，随工单记录保存用于审计
+type DataExportPreview struct {
+	Model
+	WorkflowUID       string `json:"workflow_uid" gorm:"size:32;column:workflow_uid;index;not null"`
+	WorkflowRecordUID string `json:"workflow_record_uid" gorm:"size:32;column:workflow_record_uid"`
+	CreateUserUID     string `json:"create_user_uid" gorm:"size:32;column:create_user_uid"`
+	SampleRows        int    `json:"sample_rows" gorm:"column:sample_rows"`
+	// Results 各导出 SQL 的预览结果，JSON 格式，样例行已脱敏
+	Results string `json:"results" gorm:"column:results;type:longtext"`
+}
+
+// DataExportDeliveryTarget 导出文件投递目标，密钥加密存储
+type DataExportDeliveryTarget struct {
+	Model
+	Name            string `json:"name" gorm:"size:200;column:name;uniqueIndex;not null"`
+	Type            string `json:"type" gorm:"size:32;column:type;not null"`
+	Path            string `json:"path" gorm:"size:1024;column:path"`
+	Endpoint        string `json:"endpoint" gorm:"size:255;column:endpoint"`
+	Region          string `json:"region" gorm:"size:64;column:region"`
+	Bucket          string `json:"bucket" gorm:"size:255;column:bucket"`
+	AccessKeyID     string `json:"access_key_id" gorm:"size:255;column:access_key_id"`
+	SecretAccessKey string `json:"secret_access_key" gorm:"size:1024;column:secret_access_key"`
+	PathStyle       bool   `json:"path_style" gorm:"column:path_style;default:false"`
+	Host            string `json:"host" gorm:"size:255;column:host"`
+	Port            int    `json:"port" gorm:"column:port;default:0"`
+	Username        string `json:"username" gorm:"size:255;column:username"`
+	PrivateKey      string `json:"private_key" gorm:"column:private_key;type:text"`
+	HostPublicKey   string `json:"host_public_key" gorm:"column:host_public_key;type:text"`
+}
+
 type DataExportTask struct {
 	Model
-	DBServiceUid      string     `json:"db_service_uid" gorm:"size:32"`
-	DatabaseName      string     `json:"database_name" gorm:"size:32"`
-	WorkFlowRecordUid string     `json:"workflow_record_uid" gorm:"size:255"`
-	ExportType        string     `json:"export_type" gorm:"size:32"`
-	ExportFileType    string     `json:"export_file_type" gorm:"size:32"`
-	ExportFileName    string     `json:"export_file_name" gorm:"column:export_file_name;size:255"`
-	ExportStatus      string     `json:"export_status" gorm:"column:export_status;size:32"`
-	ExportStartTime   *time.Time `json:"export_start_time" gorm:"column:export_start_time"`
-	ExportEndTime     *time.Time `json:"export_end_time" gorm:"column:export_end_time"`
-	// ExportFailStage 任务失败阶段 wire（connect/prepare/sql_execute/file_generate/...）；成功为空
+	DBServiceUid      string `json:"db_service_uid" gorm:"size:32"`
+	DatabaseName      string `json:"database_name" gorm:"size:32"`
+	WorkFlowRecordUid string `json:"workflow_record_uid" gorm:"size:255"`
+	ExportType        string `json:"export_type" gorm:"size:32"`
+	ExportFileType    string `json:"export_file_type" gorm:"size:32"`
+	ExportFileName    string `json:"export_file_name" gorm:"column:export_file_name;size:255"`
+	// WatermarkMode 导出文件的水印方式，为空时不加水印
+	WatermarkMode string `json:"watermark_mode" gorm:"column:watermark_mode;size:32"`
+	// WatermarkFingerprint 导出时写入文件内容的水印指纹
+	WatermarkFingerprint string     `json:"watermark_fingerprint" gorm:"column:watermark_fingerprint;size:16"`
+	ExportStatus         string     `json:"export_status" gorm:"column:export_status;size:32"`
+	ExportStartTime      *time.Time `json:"export_start_time" gorm:"column:export_start_time"`
+	ExportEndTime        *time.Time `json:"export_end_time" gorm:"column:export_end_time"`
+	// ExportFailStage 任务失败阶段 wire（connect/prepare/sql_execute/file_generate/quota/...）；成功为空
 	ExportFailStage string `json:"export_fail_stage" gorm:"column:export_fail_stage;size:32"`
 	// ExportFailReason 任务失败人类可读原因；成功为空
 	ExportFailReason string `json:"export_fail_reason" gorm:"column:export_fail_reason;type:text"`
 	CreateUserUID    string     `json:"create_user_uid" gorm:"size:32;column:create_user_uid"`
+	// DeliveryStatus 投递到外部存储的状态
+	DeliveryStatus   string `json:"delivery_status" gorm:"column:delivery_status;size:32"`
+	DeliveryLocation string `json:"delivery_location" gorm:"column:delivery_location;size:1024"`
+	DeliveryError    string `json:"delivery_error" gorm:"column:delivery_error;type:text"`
+	// DeliveryState 投递进度，用于断点续传
+	DeliveryState string `json:"delivery_state" gorm:"column:delivery_state;type:text"`
+	// ExportFileChecksum 导出文件的 SHA-256
+	ExportFileChecksum string `json:"export_file_checksum" gorm:"column:export_file_checksum;size:64"`
+	// DownloadCount 已预留的下载次数，新的下载开始前原子地增加，用于下载次数限制
+	DownloadCount int64 `json:"download_count" gorm:"column:download_count;not null;default:0"`
 	// Audit Result
 	AuditPassRate float64 `json:"audit_pass_rate"`
 	AuditScore    int32   `json:"audit_score"`
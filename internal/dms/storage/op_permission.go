@@ -45,7 +45,7 @@ func (d *OpPermissionRepo) SaveOpPermission(ctx context.Context, u *biz.OpPermis
 func (d *OpPermissionRepo) CheckOpPermissionExist(ctx context.Context, opPermissionUids []string) (exists bool, err error) {
 	var count int64
 	if err := transaction(d.log, ctx, d.db, func(tx *gorm.DB) error {
-		if err := tx.WithContext(ctx).Model(&model.OpPermission{}).Where("uid in (?)", opPermissionUids).Count(&count).Error; err != nil {
+		if err := tx.WithContext(ctx).Model(&model.OpPermission{}).Where("uid in (?) AND removed = ?", opPermissionUids, false).Count(&count).Error; err != nil {
 			return fmt.Errorf("failed to check opPermission exist: %v", err)
 		}
 		return nil
@@ -60,13 +60,10 @@ func (d *OpPermissionRepo) CheckOpPermissionExist(ctx context.Context, opPermiss
 }
 
 func (d *OpPermissionRepo) UpdateOpPermission(ctx context.Context, u *biz.OpPermission) error {
-	exist, err := d.CheckOpPermissionExist(ctx, []string{u.UID})
-	if err != nil {
+	// 已移除的权限重新被注册服务提供时需要更新，因此不使用 CheckOpPermissionExist
+	if _, err := d.GetOpPermission(ctx, u.UID); err != nil {
 		return err
 	}
-	if !exist {
-		return pkgErr.WrapStorageErr(d.log, fmt.Errorf("opPermission not exist"))
-	}
 
 	opPermission, err := convertBizOpPermission(u)
 	if err != nil {
@@ -19,6 +19,31 @@ type OpPermissionVerifyRepo struct {
 	log *utilLog.Helper
 }
 
+// memberGroupUsersJoin 关联 member_groups AS mg 的用户：未绑定用户组的成员组经 member_group_users，
+// 绑定了用户组的成员组经用户组当前的用户，禁用的用户组不生效。两条路径都按成员组走索引关联，
+// 用户取 memberGroupUserUid，没有用户的成员组为 NULL，需以等值条件过滤。
+// 过滤和关联用户时使用 memberGroupUserIs、memberGroupUserIn、memberGroupUserIsUser，
+// 对两列分别比较才能走 user_uid 上的索引，绑定用户组的成员组没有 member_group_users 记录，结果与 COALESCE 一致
+const memberGroupUsersJoin = `
+		LEFT JOIN member_group_users AS mgu ON mg.uid = mgu.member_group_uid
+		LEFT JOIN user_groups AS ug ON mg.user_group_uid = ug.uid AND ug.stat = 0
+		LEFT JOIN user_group_users AS ugu ON ug.uid = ugu.user_group_uid`
+
+const memberGroupUserUid = "COALESCE(mgu.user_uid, ugu.user_uid)"
+
+// memberGroupUserIs、memberGroupUserIn 的用户参数需传两次
+const (
+	memberGroupUserIs     = "(mgu.user_uid = ? OR ugu.user_uid = ?)"
+	memberGroupUserIn     = "(mgu.user_uid IN (?) OR ugu.user_uid IN (?))"
+	memberGroupUserIsUser = "(mgu.user_uid = u.uid OR ugu.user_uid = u.uid)"
+)
+
+// memberNotExpired、memberGroupNotExpired 过滤已到期的临时成员及成员组，到期回收任务延迟执行时授权同样不再生效
+const (
+	memberNotExpired      = " AND (m.expires_at IS NULL OR m.expires_at > NOW())"
+	memberGroupNotExpired = " AND (mg.expires_at IS NULL OR mg.expires_at > NOW())"
+)
+
 func NewOpPermissionVerifyRepo(log utilLog.Logger, s *Storage) *OpPermissionVerifyRepo {
 	return &OpPermissionVerifyRepo{Storage: s, log: utilLog.NewHelper(log, utilLog.WithMessageKey("storage.op_permission_verify"))}
 }
@@ -33,7 +58,7 @@ func (o *OpPermissionVerifyRepo) IsUserHasOpPermissionInProject(ctx context.Cont
 		SELECT 
 		    count(*) 
 		FROM members AS m 
-		JOIN member_role_op_ranges AS r ON m.uid=r.member_uid AND m.user_uid=? AND m.project_uid=? 
+		JOIN member_role_op_ranges AS r ON m.uid=r.member_uid AND m.user_uid=? AND m.project_uid=?` + memberNotExpired + `
 		JOIN role_op_permissions AS p ON r.role_uid = p.role_uid AND p.op_permission_uid = ?`, userUid, projectUid, opPermissionUid).Count(&count).Error; err != nil {
 			return fmt.Errorf("failed to check user has op permission in project: %v", err)
 		}
@@ -41,10 +66,10 @@ func (o *OpPermissionVerifyRepo) IsUserHasOpPermissionInProject(ctx context.Cont
 		if err := tx.WithContext(ctx).Raw(`
 		SELECT 
     		count(*) 
-		FROM member_groups AS mg
-		JOIN member_group_users AS mgu ON mg.uid = mgu.member_group_uid and mgu.user_uid = ? AND mg.project_uid = ? 
+		FROM member_groups AS mg` + memberGroupUsersJoin + `
 		JOIN member_group_role_op_ranges AS mgrop ON mg.uid = mgrop.member_group_uid 
-		JOIN role_op_permissions AS rop ON mgrop.role_uid = rop.role_uid AND rop.op_permission_uid = ?`, userUid, projectUid, opPermissionUid).Count(&memberGroupCount).Error; err != nil {
+		JOIN role_op_permissions AS rop ON mgrop.role_uid = rop.role_uid AND rop.op_permission_uid = ?
+		WHERE ` + memberGroupUserIs + ` AND mg.project_uid = ?` + memberGroupNotExpired, opPermissionUid, userUid, userUid, projectUid).Count(&memberGroupCount).Error; err != nil {
 			return fmt.Errorf("failed to check user has op permission in project: %v", err)
 		}
 
@@ -52,7 +77,7 @@ func (o *OpPermissionVerifyRepo) IsUserHasOpPermissionInProject(ctx context.Cont
 		SELECT 
 		    count(*) 
 		FROM members AS m 
-		JOIN member_op_permissions AS p ON m.uid = p.member_uid AND p.op_permission_uid = ?
+		JOIN member_op_permissions AS p ON m.uid = p.member_uid AND p.op_permission_uid = ?` + memberNotExpired + `
 		WHERE m.user_uid=? AND m.project_uid=?`,opPermissionUid,userUid,projectUid).Count(&projectPermissionMemberCount).Error; err != nil {
 			return fmt.Errorf("failed to check user has op permission in project: %v", err)
 		}
@@ -60,9 +85,9 @@ func (o *OpPermissionVerifyRepo) IsUserHasOpPermissionInProject(ctx context.Cont
 		if err := tx.WithContext(ctx).Raw(`
 		SELECT 
 		    count(*) 
-		FROM member_groups AS mg 
-		JOIN member_group_users AS mgu ON mg.uid = mgu.member_group_uid and mgu.user_uid = ? AND mg.project_uid = ? 
-		JOIN member_group_op_permissions AS p ON mg.uid = p.member_group_uid AND p.op_permission_uid = ?`,userUid,projectUid,opPermissionUid).Count(&projectPermissionMemberGroupCount).Error; err != nil {
+		FROM member_groups AS mg` + memberGroupUsersJoin + `
+		JOIN member_group_op_permissions AS p ON mg.uid = p.member_group_uid AND p.op_permission_uid = ?
+		WHERE ` + memberGroupUserIs + ` AND mg.project_uid = ?` + memberGroupNotExpired,opPermissionUid,userUid,userUid,projectUid).Count(&projectPermissionMemberGroupCount).Error; err != nil {
 			return fmt.Errorf("failed to check user has op permission in project: %v", err)
 		}
 
@@ -85,7 +110,7 @@ func (o *OpPermissionVerifyRepo) GetUserProjectWithOpPermissions(ctx context.Con
 		SELECT 
 		    n.uid, n.name, p.op_permission_uid, r.op_range_type, r.range_uids 
 		FROM projects AS n
-		JOIN members AS m ON n.uid = m.project_uid
+		JOIN members AS m ON n.uid = m.project_uid` + memberNotExpired + `
 		JOIN users AS u ON m.user_uid = u.uid AND u.uid = ?
 		LEFT JOIN member_role_op_ranges AS r ON m.uid=r.member_uid
 		LEFT JOIN role_op_permissions AS p ON r.role_uid = p.role_uid
@@ -94,9 +119,8 @@ func (o *OpPermissionVerifyRepo) GetUserProjectWithOpPermissions(ctx context.Con
 		SELECT 
 			distinct n.uid, n.name, rop.op_permission_uid, mgrop.op_range_type, mgrop.range_uids 
 		FROM projects AS n
-		JOIN member_groups AS mg ON n.uid = mg.project_uid
-		JOIN member_group_users AS mgu ON mg.uid = mgu.member_group_uid
-		JOIN users AS u ON mgu.user_uid = u.uid AND u.uid = ?
+		JOIN member_groups AS mg ON n.uid = mg.project_uid` + memberGroupUsersJoin + `
+		JOIN users AS u ON ` + memberGroupUserIsUser + ` AND u.uid = ?` + memberGroupNotExpired + `
 		LEFT JOIN member_group_role_op_ranges AS mgrop ON mg.uid=mgrop.member_group_uid
 		LEFT JOIN role_op_permissions AS rop ON mgrop.role_uid = rop.role_uid
 		WHERE n.status = 'active'
@@ -121,6 +145,7 @@ func (o *OpPermissionVerifyRepo) GetUserProjectWithOpPermissions(ctx context.Con
 				OpPermissionUID: r.OpPermissionUid,
 				OpRangeType:     typ,
 				RangeUIDs:       convertModelRangeUIDs(r.RangeUids),
+				ProjectUID:      r.Uid,
 			},
 		})
 	}
@@ -139,18 +164,17 @@ func (o *OpPermissionVerifyRepo) GetUserOpPermissionInProject(ctx context.Contex
 		SELECT 
 		    p.op_permission_uid, mror.op_range_type, mror.range_uids 
 		FROM members AS m 
-		JOIN member_role_op_ranges AS mror ON m.uid=mror.member_uid AND m.user_uid=? AND m.project_uid=? 
+		JOIN member_role_op_ranges AS mror ON m.uid=mror.member_uid AND m.user_uid=? AND m.project_uid=?` + memberNotExpired + `
 		JOIN role_op_permissions AS p ON mror.role_uid = p.role_uid
 		JOIN roles AS r ON r.uid = p.role_uid AND r.stat = 0
 		UNION 
 		SELECT
 			DISTINCT rop.op_permission_uid, mgror.op_range_type, mgror.range_uids 
-		FROM member_groups mg
-		JOIN member_group_users mgu ON mg.uid = mgu.member_group_uid
-		JOIN member_group_role_op_ranges mgror ON mgu.member_group_uid = mgror.member_group_uid
+		FROM member_groups mg` + memberGroupUsersJoin + `
+		JOIN member_group_role_op_ranges mgror ON mg.uid = mgror.member_group_uid
 		JOIN role_op_permissions rop ON mgror.role_uid = rop.role_uid
 		JOIN roles AS r ON r.uid = rop.role_uid AND r.stat = 0
-		WHERE mg.project_uid = ? and mgu.user_uid = ?`, userUid, projectUid, projectUid, userUid).Scan(&results).Error; err != nil {
+		WHERE mg.project_uid = ? and ` + memberGroupUserIs + memberGroupNotExpired, userUid, projectUid, projectUid, userUid, userUid).Scan(&results).Error; err != nil {
 			return fmt.Errorf("failed to get user op permission in project: %v", err)
 		}
 		return nil
@@ -169,6 +193,7 @@ func (o *OpPermissionVerifyRepo) GetUserOpPermissionInProject(ctx context.Contex
 			OpPermissionUID: r.OpPermissionUid,
 			OpRangeType:     typ,
 			RangeUIDs:       convertModelRangeUIDs(r.RangeUids),
+			ProjectUID:      projectUid,
 		})
 	}
 
@@ -187,18 +212,17 @@ func (o *OpPermissionVerifyRepo) GetOneOpPermissionInProject(ctx context.Context
 		SELECT 
 		    p.op_permission_uid, mror.op_range_type, mror.range_uids 
 		FROM members AS m 
-		JOIN member_role_op_ranges AS mror ON m.uid=mror.member_uid AND m.user_uid=? AND m.project_uid=? 
+		JOIN member_role_op_ranges AS mror ON m.uid=mror.member_uid AND m.user_uid=? AND m.project_uid=?` + memberNotExpired + `
 		JOIN role_op_permissions AS p ON mror.role_uid = p.role_uid AND p.op_permission_uid=?
 		JOIN roles AS r ON r.uid = p.role_uid AND r.stat = 0
 		UNION 
 		SELECT
 			DISTINCT rop.op_permission_uid, mgror.op_range_type, mgror.range_uids 
-		FROM member_groups mg
-		JOIN member_group_users mgu ON mg.uid = mgu.member_group_uid
-		JOIN member_group_role_op_ranges mgror ON mgu.member_group_uid = mgror.member_group_uid
+		FROM member_groups mg` + memberGroupUsersJoin + `
+		JOIN member_group_role_op_ranges mgror ON mg.uid = mgror.member_group_uid
 		JOIN role_op_permissions rop ON mgror.role_uid = rop.role_uid AND rop.op_permission_uid=?
 		JOIN roles AS r ON r.uid = rop.role_uid AND r.stat = 0
-		WHERE mg.project_uid = ? and mgu.user_uid = ?`, userUid, projectUid, permissionId, permissionId, projectUid, userUid).Scan(&results).Error; err != nil {
+		WHERE mg.project_uid = ? and ` + memberGroupUserIs + memberGroupNotExpired, userUid, projectUid, permissionId, permissionId, projectUid, userUid, userUid).Scan(&results).Error; err != nil {
 			return fmt.Errorf("failed to get user op permission in project: %v", err)
 		}
 		return nil
@@ -217,6 +241,7 @@ func (o *OpPermissionVerifyRepo) GetOneOpPermissionInProject(ctx context.Context
 			OpPermissionUID: r.OpPermissionUid,
 			OpRangeType:     typ,
 			RangeUIDs:       convertModelRangeUIDs(r.RangeUids),
+			ProjectUID:      projectUid,
 		})
 	}
 
@@ -236,14 +261,13 @@ func (o *OpPermissionVerifyRepo) GetUserProjectOpPermissionInProject(ctx context
 		SELECT 
 		    mop.op_permission_uid, 'project' as op_range_type, m.project_uid as range_uids
 		FROM members AS m 
-		JOIN member_op_permissions AS mop ON m.uid=mop.member_uid AND m.user_uid=? AND m.project_uid=? 
+		JOIN member_op_permissions AS mop ON m.uid=mop.member_uid AND m.user_uid=? AND m.project_uid=?` + memberNotExpired + `
 		UNION 
 		SELECT
 			DISTINCT mgop.op_permission_uid, 'project' as op_range_type, mg.project_uid as range_uids
-		FROM member_groups mg
-		JOIN member_group_users mgu ON mg.uid = mgu.member_group_uid
+		FROM member_groups mg` + memberGroupUsersJoin + `
 		JOIN member_group_op_permissions AS mgop ON mg.uid = mgop.member_group_uid
-		WHERE mg.project_uid = ? and mgu.user_uid = ?`, userUid, projectUid, projectUid, userUid).Scan(&results).Error; err != nil {
+		WHERE mg.project_uid = ? and ` + memberGroupUserIs + memberGroupNotExpired, userUid, projectUid, projectUid, userUid, userUid).Scan(&results).Error; err != nil {
 			return fmt.Errorf("failed to get user op permission in project: %v", err)
 		}
 		return nil
@@ -273,22 +297,23 @@ func (o *OpPermissionVerifyRepo) GetUserOpPermission(ctx context.Context, userUi
 		OpPermissionUid string
 		OpRangeType     string
 		RangeUids       string
+		ProjectUid      string
 	}
 	var results []result
 	if err = transaction(o.log, ctx, o.db, func(tx *gorm.DB) error {
 		if err = tx.WithContext(ctx).Raw(`
 		SELECT 
-			p.op_permission_uid, r.op_range_type, r.range_uids 
+			p.op_permission_uid, r.op_range_type, r.range_uids, m.project_uid 
 		FROM members AS m 
-		JOIN member_role_op_ranges AS r ON m.uid=r.member_uid AND m.user_uid = ?
+		JOIN member_role_op_ranges AS r ON m.uid=r.member_uid AND m.user_uid = ?` + memberNotExpired + `
 		JOIN role_op_permissions AS p ON r.role_uid = p.role_uid
 		UNION 
 		select 
-			distinct rop.op_permission_uid, mgror.op_range_type, mgror.range_uids 
-		from member_group_users mgu
-		join member_group_role_op_ranges mgror on mgu.member_group_uid = mgror.member_group_uid
+			distinct rop.op_permission_uid, mgror.op_range_type, mgror.range_uids, mg.project_uid 
+		from member_groups mg` + memberGroupUsersJoin + `
+		join member_group_role_op_ranges mgror on mg.uid = mgror.member_group_uid
 		join role_op_permissions rop on mgror.role_uid = rop.role_uid
-		where mgu.user_uid = ?`, userUid, userUid).Scan(&results).Error; err != nil {
+		where ` + memberGroupUserIs + memberGroupNotExpired, userUid, userUid, userUid).Scan(&results).Error; err != nil {
 			return fmt.Errorf("failed to get user op permission: %v", err)
 		}
 		return nil
@@ -307,6 +332,7 @@ func (o *OpPermissionVerifyRepo) GetUserOpPermission(ctx context.Context, userUi
 			OpPermissionUID: r.OpPermissionUid,
 			OpRangeType:     typ,
 			RangeUIDs:       convertModelRangeUIDs(r.RangeUids),
+			ProjectUID:      r.ProjectUid,
 		})
 	}
 
@@ -325,14 +351,13 @@ func (o *OpPermissionVerifyRepo) GetUserProjectOpPermission(ctx context.Context,
 		SELECT 
 		    mop.op_permission_uid, 'project' as op_range_type, m.project_uid as range_uids 
 		FROM members AS m 
-		JOIN member_op_permissions AS mop ON m.uid=mop.member_uid AND m.user_uid=?
+		JOIN member_op_permissions AS mop ON m.uid=mop.member_uid AND m.user_uid=?` + memberNotExpired + `
 		UNION 
 		SELECT
 			DISTINCT mgop.op_permission_uid, 'project' as op_range_type, mg.project_uid as range_uids
-		FROM member_groups mg
-		JOIN member_group_users mgu ON mg.uid = mgu.member_group_uid
+		FROM member_groups mg` + memberGroupUsersJoin + `
 		JOIN member_group_op_permissions AS mgop ON mg.uid = mgop.member_group_uid
-		WHERE mgu.user_uid = ?`, userUid, userUid).Scan(&results).Error; err != nil {
+		WHERE ` + memberGroupUserIs + memberGroupNotExpired, userUid, userUid, userUid).Scan(&results).Error; err != nil {
 			return fmt.Errorf("failed to get user op permission: %v", err)
 		}
 		return nil
@@ -401,14 +426,14 @@ func (o *OpPermissionVerifyRepo) ListUsersOpPermissionInProject(ctx context.Cont
 				SELECT 
 					m.user_uid, u.name AS user_name 
 				FROM
-					members AS m JOIN users AS u ON m.user_uid = u.uid AND m.project_uid = ?
+					members AS m JOIN users AS u ON m.user_uid = u.uid AND m.project_uid = ?` + memberNotExpired + `
 				UNION
 				SELECT 
 					DISTINCT u.uid AS user_uid, u.name AS user_name
 				FROM 
-					member_groups AS mg
-					JOIN member_group_users mgu on mg.uid = mgu.member_group_uid AND mg.project_uid = ?
-					JOIN users AS u ON mgu.user_uid = u.uid
+					member_groups AS mg` + memberGroupUsersJoin + `
+					JOIN users AS u ON ` + memberGroupUserIsUser + memberGroupNotExpired + `
+					WHERE mg.project_uid = ?
 			) TEMP ORDER BY user_uid LIMIT ? OFFSET ?`,
 				projectUid, projectUid, opt.LimitPerPage, opt.LimitPerPage*(uint32(fixPageIndices(opt.PageNumber)))).Scan(&results).Error; err != nil {
 				return fmt.Errorf("failed to list user op permission in project: %v", err)
@@ -422,13 +447,13 @@ func (o *OpPermissionVerifyRepo) ListUsersOpPermissionInProject(ctx context.Cont
 				SELECT 
 					m.user_uid, u.name AS user_name
 				FROM members AS m 
-				JOIN users AS u ON m.user_uid = u.uid AND m.project_uid=?
+				JOIN users AS u ON m.user_uid = u.uid AND m.project_uid=?` + memberNotExpired + `
 				UNION
 				SELECT 
 					DISTINCT u.uid AS user_uid, u.name AS user_name 
-				FROM member_groups AS mg
-				JOIN member_group_users mgu on mg.uid = mgu.member_group_uid AND mg.project_uid = ?
-				JOIN users AS u ON mgu.user_uid = u.uid
+				FROM member_groups AS mg` + memberGroupUsersJoin + `
+				JOIN users AS u ON ` + memberGroupUserIsUser + memberGroupNotExpired + `
+				WHERE mg.project_uid = ?
 			) TEMP`,
 				projectUid, projectUid).Scan(&total).Error; err != nil {
 				return fmt.Errorf("failed to list total user op permission in project: %v", err)
@@ -447,29 +472,27 @@ func (o *OpPermissionVerifyRepo) ListUsersOpPermissionInProject(ctx context.Cont
 				SELECT 
 					m.user_uid, p.op_permission_uid, r.op_range_type, r.range_uids 
 				FROM members AS m 
-				JOIN member_role_op_ranges AS r ON m.uid=r.member_uid AND m.user_uid in (?) AND m.project_uid=? 
+				JOIN member_role_op_ranges AS r ON m.uid=r.member_uid AND m.user_uid in (?) AND m.project_uid=?` + memberNotExpired + `
 				JOIN role_op_permissions AS p ON r.role_uid = p.role_uid
 				UNION 
 				SELECT
-					DISTINCT mgu.user_uid, rop.op_permission_uid, mgror.op_range_type, mgror.range_uids 
-				FROM member_groups mg
-				JOIN member_group_users mgu ON mg.uid = mgu.member_group_uid
-				JOIN member_group_role_op_ranges mgror ON mgu.member_group_uid = mgror.member_group_uid
+					DISTINCT ` + memberGroupUserUid + ` AS user_uid, rop.op_permission_uid, mgror.op_range_type, mgror.range_uids 
+				FROM member_groups mg` + memberGroupUsersJoin + `
+				JOIN member_group_role_op_ranges mgror ON mg.uid = mgror.member_group_uid
 				JOIN role_op_permissions rop ON mgror.role_uid = rop.role_uid
-				WHERE mg.project_uid = ? and mgu.user_uid in (?)
+				WHERE mg.project_uid = ? and ` + memberGroupUserIn + memberGroupNotExpired + `
 				UNION
 				SELECT
 					m.user_uid, mop.op_permission_uid, 'project' AS op_range_type, m.project_uid AS range_uids
 				FROM members AS m
-				JOIN member_op_permissions AS mop ON m.uid = mop.member_uid AND m.user_uid IN (?) AND m.project_uid = ?
+				JOIN member_op_permissions AS mop ON m.uid = mop.member_uid AND m.user_uid IN (?) AND m.project_uid = ?` + memberNotExpired + `
 				UNION
 				SELECT
-					DISTINCT mgu.user_uid, mgop.op_permission_uid, 'project' AS op_range_type, mg.project_uid AS range_uids
-				FROM member_groups mg
-				JOIN member_group_users mgu ON mg.uid = mgu.member_group_uid
+					DISTINCT ` + memberGroupUserUid + ` AS user_uid, mgop.op_permission_uid, 'project' AS op_range_type, mg.project_uid AS range_uids
+				FROM member_groups mg` + memberGroupUsersJoin + `
 				JOIN member_group_op_permissions AS mgop ON mg.uid = mgop.member_group_uid
-				WHERE mg.project_uid = ? AND mgu.user_uid IN (?)`,
-					userIds, projectUid, projectUid, userIds, userIds, projectUid, projectUid, userIds).Scan(&permissionResults).Error; err != nil {
+				WHERE mg.project_uid = ? AND ` + memberGroupUserIn + memberGroupNotExpired,
+					userIds, projectUid, projectUid, userIds, userIds, userIds, projectUid, projectUid, userIds, userIds).Scan(&permissionResults).Error; err != nil {
 					return fmt.Errorf("failed to get user op permission in project: %v", err)
 				}
 			}
@@ -503,6 +526,7 @@ func (o *OpPermissionVerifyRepo) ListUsersOpPermissionInProject(ctx context.Cont
 					OpPermissionUID: r.OpPermissionUid,
 					OpRangeType:     typ,
 					RangeUIDs:       convertModelRangeUIDs(r.RangeUids),
+					ProjectUID:      projectUid,
 				})
 			}
 		}
@@ -531,14 +555,14 @@ func (o *OpPermissionVerifyRepo) ListUsersInProject(ctx context.Context, project
 				SELECT 
 					m.user_uid, u.name AS user_name 
 				FROM
-					members AS m JOIN users AS u ON m.user_uid = u.uid AND m.project_uid = ?
+					members AS m JOIN users AS u ON m.user_uid = u.uid AND m.project_uid = ?` + memberNotExpired + `
 				UNION
 				SELECT 
 					DISTINCT u.uid AS user_uid, u.name AS user_name
 				FROM 
-					member_groups AS mg
-					JOIN member_group_users mgu on mg.uid = mgu.member_group_uid AND mg.project_uid = ?
-					JOIN users AS u ON mgu.user_uid = u.uid
+					member_groups AS mg` + memberGroupUsersJoin + `
+					JOIN users AS u ON ` + memberGroupUserIsUser + memberGroupNotExpired + `
+					WHERE mg.project_uid = ?
 			) TEMP`,
 				projectUid, projectUid).Scan(&results).Error; err != nil {
 				return fmt.Errorf("failed to list users in project: %v", err)
@@ -568,16 +592,15 @@ func (d *OpPermissionVerifyRepo) GetUserProject(ctx context.Context, userUid str
 			n.*
 		FROM
 			projects n
-			JOIN members m ON n.uid = m.project_uid
+			JOIN members m ON n.uid = m.project_uid` + memberNotExpired + `
 			JOIN users u ON m.user_uid = u.uid AND u.uid = ?
 		UNION
 		SELECT 
 			DISTINCT n.*
 		FROM 
 			projects n
-			JOIN member_groups mg on n.uid = mg.project_uid
-			JOIN member_group_users mgu ON mgu.member_group_uid = mg.uid
-			JOIN users u ON mgu.user_uid = u.uid AND u.uid = ?
+			JOIN member_groups mg on n.uid = mg.project_uid` + memberGroupUsersJoin + `
+			JOIN users u ON ` + memberGroupUserIsUser + ` AND u.uid = ?` + memberGroupNotExpired + `
 			`, userUid, userUid).Scan(&models).Error; err != nil {
 			return fmt.Errorf("failed to list user project: %v", err)
 		}
@@ -595,3 +618,110 @@ func (d *OpPermissionVerifyRepo) GetUserProject(ctx context.Context, userUid str
 	}
 	return projects, nil
 }
+
+func (o *OpPermissionVerifyRepo) ListDBServiceUIDsBySelector(ctx context.Context, projectUid string, selector *biz.DBServiceSelector) (dbServiceUids []string, err error) {
+	if err := transaction(o.log, ctx, o.db, func(tx *gorm.DB) error {
+		db := tx.WithContext(ctx).Table("db_services AS ds").Where("ds.project_uid = ?", projectUid)
+		switch selector.Key {
+		case biz.DBServiceSelectorKeyEnvironmentTag:
+			db = db.Joins("JOIN environment_tags AS et ON ds.environment_tag_uid = et.uid").
+				Where("et.environment_name = ?", selector.Value)
+		case biz.DBServiceSelectorKeyBusinessTag:
+			// 业务标签保存在 JSON 格式的附加参数中，查出项目内的数据源后按参数值匹配
+			var models []*model.DBService
+			if err := db.Select("ds.uid, ds.additional_params").Order("ds.uid").Find(&models).Error; err != nil {
+				return fmt.Errorf("failed to list db service by selector: %v", err)
+			}
+			for _, m := range models {
+				if m.AdditionalParams.GetParam(biz.DBServiceAdditionalParam_BusinessTag).String() == selector.Value {
+					dbServiceUids = append(dbServiceUids, m.UID)
+				}
+			}
+			return nil
+		default:
+			return fmt.Errorf("unsupported db service selector key: %v", selector.Key)
+		}
+		if err := db.Order("ds.uid").Pluck("ds.uid", &dbServiceUids).Error; err != nil {
+			return fmt.Errorf("failed to list db service by selector: %v", err)
+		}
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+	return dbServiceUids, nil
+}
+
+func (o *OpPermissionVerifyRepo) ListUserOpPermissionSourcesInProject(ctx context.Context, userUid, projectUid string) (sources []biz.OpPermissionSource, err error) {
+	type result struct {
+		SourceType      string
+		SourceUid       string
+		SourceName      string
+		RoleUid         string
+		RoleName        string
+		OpPermissionUid string
+		OpRangeType     string
+		RangeUids       string
+	}
+	var results []result
+	if err := transaction(o.log, ctx, o.db, func(tx *gorm.DB) error {
+		// 与 GetUserOpPermissionInProject、GetUserProjectOpPermissionInProject 的权限来源一致，额外返回来源及角色
+		if err := tx.WithContext(ctx).Raw(`
+		SELECT
+			'member' AS source_type, m.uid AS source_uid, '' AS source_name, r.uid AS role_uid, r.name AS role_name,
+			p.op_permission_uid, mror.op_range_type, mror.range_uids
+		FROM members AS m
+		JOIN member_role_op_ranges AS mror ON m.uid = mror.member_uid AND m.user_uid = ? AND m.project_uid = ?` + memberNotExpired + `
+		JOIN role_op_permissions AS p ON mror.role_uid = p.role_uid
+		JOIN roles AS r ON r.uid = p.role_uid AND r.stat = 0
+		UNION ALL
+		SELECT
+			'member_group' AS source_type, mg.uid AS source_uid, mg.name AS source_name, r.uid AS role_uid, r.name AS role_name,
+			rop.op_permission_uid, mgror.op_range_type, mgror.range_uids
+		FROM member_groups AS mg` + memberGroupUsersJoin + `
+		JOIN member_group_role_op_ranges AS mgror ON mg.uid = mgror.member_group_uid
+		JOIN role_op_permissions AS rop ON mgror.role_uid = rop.role_uid
+		JOIN roles AS r ON r.uid = rop.role_uid AND r.stat = 0
+		WHERE ` + memberGroupUserIs + ` AND mg.project_uid = ?` + memberGroupNotExpired + `
+		UNION ALL
+		SELECT
+			'member' AS source_type, m.uid AS source_uid, '' AS source_name, '' AS role_uid, '' AS role_name,
+			mop.op_permission_uid, 'project' AS op_range_type, m.project_uid AS range_uids
+		FROM members AS m
+		JOIN member_op_permissions AS mop ON m.uid = mop.member_uid AND m.user_uid = ? AND m.project_uid = ?` + memberNotExpired + `
+		UNION ALL
+		SELECT
+			'member_group' AS source_type, mg.uid AS source_uid, mg.name AS source_name, '' AS role_uid, '' AS role_name,
+			mgop.op_permission_uid, 'project' AS op_range_type, mg.project_uid AS range_uids
+		FROM member_groups AS mg` + memberGroupUsersJoin + `
+		JOIN member_group_op_permissions AS mgop ON mg.uid = mgop.member_group_uid
+		WHERE ` + memberGroupUserIs + ` AND mg.project_uid = ?` + memberGroupNotExpired,
+			userUid, projectUid, userUid, userUid, projectUid, userUid, projectUid, userUid, userUid, projectUid).Scan(&results).Error; err != nil {
+			return fmt.Errorf("failed to list user op permission sources in project: %v", err)
+		}
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+
+	sources = make([]biz.OpPermissionSource, 0, len(results))
+	for _, r := range results {
+		typ, err := biz.ParseOpRangeType(r.OpRangeType)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse op range type: %v", err)
+		}
+		sources = append(sources, biz.OpPermissionSource{
+			SourceType: biz.OpPermissionSourceType(r.SourceType),
+			SourceUID:  r.SourceUid,
+			SourceName: r.SourceName,
+			RoleUID:    r.RoleUid,
+			RoleName:   r.RoleName,
+			OpPermissionWithOpRange: biz.OpPermissionWithOpRange{
+				OpPermissionUID: r.OpPermissionUid,
+				OpRangeType:     typ,
+				RangeUIDs:       convertModelRangeUIDs(r.RangeUids),
+				ProjectUID:      projectUid,
+			},
+		})
+	}
+	return sources, nil
+}
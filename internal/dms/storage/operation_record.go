@@ -58,6 +58,9 @@ func applyOperationRecordFilters(db *gorm.DB, opt *biz.ListOperationRecordOption
 	if opt.FuzzySearchOperateUserName != "" {
 		db = db.Where("operation_user_name LIKE ?", "%"+opt.FuzzySearchOperateUserName+"%")
 	}
+	if opt.FilterOperateUserName != "" {
+		db = db.Where("operation_user_name = ?", opt.FilterOperateUserName)
+	}
 	if opt.FilterOperateTypeName != "" {
 		db = db.Where("operation_type_name = ?", opt.FilterOperateTypeName)
 	}
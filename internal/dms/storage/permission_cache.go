@@ -0,0 +1,52 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/actiontech/dms/internal/dms/biz"
+	pkgErr "github.com/actiontech/dms/internal/dms/pkg/errors"
+	"github.com/actiontech/dms/internal/dms/storage/model"
+	utilLog "github.com/actiontech/dms/pkg/dms-common/pkg/log"
+	"gorm.io/gorm"
+)
+
+var _ biz.PermissionCacheVersionRepo = (*PermissionCacheVersionRepo)(nil)
+
+type PermissionCacheVersionRepo struct {
+	*Storage
+	log *utilLog.Helper
+}
+
+func NewPermissionCacheVersionRepo(log utilLog.Logger, s *Storage) *PermissionCacheVersionRepo {
+	return &PermissionCacheVersionRepo{Storage: s, log: utilLog.NewHelper(log, utilLog.WithMessageKey("storage.permission_cache"))}
+}
+
+const permissionCacheVersionAnchor = 1
+
+func (d *PermissionCacheVersionRepo) GetPermissionCacheVersion(ctx context.Context) (uint64, error) {
+	var items []*model.PermissionCacheVersion
+	if err := transaction(d.log, ctx, d.db, func(tx *gorm.DB) error {
+		if err := tx.WithContext(ctx).Where("anchor = ?", permissionCacheVersionAnchor).Find(&items).Error; err != nil {
+			return pkgErr.WrapStorageErr(d.log, fmt.Errorf("failed to get permission cache version: %v", err))
+		}
+		return nil
+	}); err != nil {
+		return 0, err
+	}
+	if len(items) == 0 {
+		return 0, nil
+	}
+	return items[0].Version, nil
+}
+
+func (d *PermissionCacheVersionRepo) IncrPermissionCacheVersion(ctx context.Context) error {
+	return transaction(d.log, ctx, d.db, func(tx *gorm.DB) error {
+		if err := tx.WithContext(ctx).Exec(
+			"INSERT INTO permission_cache_versions (anchor, version) VALUES (?, 1) ON DUPLICATE KEY UPDATE version = version + 1",
+			permissionCacheVersionAnchor).Error; err != nil {
+			return pkgErr.WrapStorageErr(d.log, fmt.Errorf("failed to increase permission cache version: %v", err))
+		}
+		return nil
+	})
+}
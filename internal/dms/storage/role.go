@@ -60,10 +60,10 @@ func (d *RoleRepo) CheckRoleExist(ctx context.Context, roleUids []string) (exist
 	return true, nil
 }
 
-func (d *RoleRepo) CheckRoleExistByRoleName(ctx context.Context, name string) (exists bool, err error) {
+func (d *RoleRepo) CheckRoleExistByRoleName(ctx context.Context, projectUid, name string) (exists bool, err error) {
 	var count int64
 	if err := transaction(d.log, ctx, d.db, func(tx *gorm.DB) error {
-		if err := tx.WithContext(ctx).Model(&model.Role{}).Where("name = ?", name).Count(&count).Error; err != nil {
+		if err := tx.WithContext(ctx).Model(&model.Role{}).Where("project_uid = ? AND name = ?", projectUid, name).Count(&count).Error; err != nil {
 			return fmt.Errorf("failed to check role exist: %v", err)
 		}
 		return nil
@@ -281,3 +281,27 @@ func (d *RoleRepo) DelAllOpPermissionsFromRole(ctx context.Context, roleUid stri
 		return nil
 	})
 }
+
+// ListChildRoles 列出直接继承该角色的角色
+func (d *RoleRepo) ListChildRoles(ctx context.Context, roleUid string) ([]*biz.Role, error) {
+	var roles []*model.Role
+	if err := transaction(d.log, ctx, d.db, func(tx *gorm.DB) error {
+		if err := tx.WithContext(ctx).Preload("OpPermissions").
+			Where("JSON_CONTAINS(parent_role_uids, JSON_QUOTE(?))", roleUid).Find(&roles).Error; err != nil {
+			return fmt.Errorf("failed to list child roles: %v", err)
+		}
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+
+	ret := make([]*biz.Role, 0, len(roles))
+	for _, role := range roles {
+		r, err := convertModelRole(role)
+		if err != nil {
+			return nil, pkgErr.WrapStorageErr(d.log, fmt.Errorf("failed to convert model role: %v", err))
+		}
+		ret = append(ret, r)
+	}
+	return ret, nil
+}
@@ -0,0 +1,135 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/actiontech/dms/internal/dms/biz"
+	pkgErr "github.com/actiontech/dms/internal/dms/pkg/errors"
+	"github.com/actiontech/dms/internal/dms/storage/model"
+	utilLog "github.com/actiontech/dms/pkg/dms-common/pkg/log"
+
+	"gorm.io/gorm"
+)
+
+var _ biz.SeparationOfDutiesRepo = (*SeparationOfDutiesRepo)(nil)
+
+type SeparationOfDutiesRepo struct {
+	*Storage
+	log *utilLog.Helper
+}
+
+func NewSeparationOfDutiesRepo(log utilLog.Logger, s *Storage) *SeparationOfDutiesRepo {
+	return &SeparationOfDutiesRepo{Storage: s, log: utilLog.NewHelper(log, utilLog.WithMessageKey("storage.separation_of_duties"))}
+}
+
+func (d *SeparationOfDutiesRepo) SaveSeparationOfDutiesRule(ctx context.Context, rule *biz.SeparationOfDutiesRule) error {
+	return transaction(d.log, ctx, d.db, func(tx *gorm.DB) error {
+		if err := tx.WithContext(ctx).Create(convertBizSeparationOfDutiesRule(rule)).Error; err != nil {
+			return pkgErr.WrapStorageErr(d.log, fmt.Errorf("failed to save separation of duties rule: %v", err))
+		}
+		return nil
+	})
+}
+
+func (d *SeparationOfDutiesRepo) DelSeparationOfDutiesRule(ctx context.Context, ruleUid string) error {
+	return transaction(d.log, ctx, d.db, func(tx *gorm.DB) error {
+		if err := tx.WithContext(ctx).Where("uid = ?", ruleUid).Delete(&model.SeparationOfDutiesRule{}).Error; err != nil {
+			return pkgErr.WrapStorageErr(d.log, fmt.Errorf("failed to delete separation of duties rule: %v", err))
+		}
+		return nil
+	})
+}
+
+func (d *SeparationOfDutiesRepo) GetSeparationOfDutiesRule(ctx context.Context, ruleUid string) (*biz.SeparationOfDutiesRule, error) {
+	var m model.SeparationOfDutiesRule
+	if err := transaction(d.log, ctx, d.db, func(tx *gorm.DB) error {
+		if err := tx.WithContext(ctx).Where("uid = ?", ruleUid).First(&m).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return pkgErr.ErrStorageNoData
+			}
+			return pkgErr.WrapStorageErr(d.log, fmt.Errorf("failed to get separation of duties rule: %v", err))
+		}
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+	return convertModelSeparationOfDutiesRule(&m), nil
+}
+
+func (d *SeparationOfDutiesRepo) ListSeparationOfDutiesRules(ctx context.Context, projectUid string) ([]*biz.SeparationOfDutiesRule, error) {
+	var models []*model.SeparationOfDutiesRule
+	if err := transaction(d.log, ctx, d.db, func(tx *gorm.DB) error {
+		if err := tx.WithContext(ctx).Where("project_uid = ?", projectUid).Order("created_at").Find(&models).Error; err != nil {
+			return pkgErr.WrapStorageErr(d.log, fmt.Errorf("failed to list separation of duties rules: %v", err))
+		}
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+
+	ret := make([]*biz.SeparationOfDutiesRule, 0, len(models))
+	for _, m := range models {
+		ret = append(ret, convertModelSeparationOfDutiesRule(m))
+	}
+	return ret, nil
+}
+
+func (d *SeparationOfDutiesRepo) ListProjectUIDsByRole(ctx context.Context, roleUid string) ([]string, error) {
+	projectUids := make([]string, 0)
+	if err := transaction(d.log, ctx, d.db, func(tx *gorm.DB) error {
+		if err := tx.WithContext(ctx).Raw(`
+		SELECT m.project_uid
+		FROM members AS m
+		JOIN member_role_op_ranges AS mror ON m.uid = mror.member_uid AND mror.role_uid = ?
+		UNION
+		SELECT mg.project_uid
+		FROM member_groups AS mg
+		JOIN member_group_role_op_ranges AS mgror ON mg.uid = mgror.member_group_uid AND mgror.role_uid = ?
+		`, roleUid, roleUid).Scan(&projectUids).Error; err != nil {
+			return pkgErr.WrapStorageErr(d.log, fmt.Errorf("failed to list projects by role: %v", err))
+		}
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+	return projectUids, nil
+}
+
+func (d *SeparationOfDutiesRepo) ListSelfApprovedWorkflows(ctx context.Context, projectUid, workflowType string) ([]*biz.SelfApprovedWorkflow, error) {
+	type result struct {
+		WorkflowUid  string
+		WorkflowName string
+		UserUid      string
+		OperateAt    *time.Time
+	}
+	var results []result
+	if err := transaction(d.log, ctx, d.db, func(tx *gorm.DB) error {
+		if err := tx.WithContext(ctx).Raw(`
+		SELECT w.uid AS workflow_uid, w.name AS workflow_name, w.create_user_uid AS user_uid, ws.operate_at
+		FROM workflows AS w
+		JOIN workflow_records AS wr ON w.workflow_record_uid = wr.uid
+		JOIN workflow_steps AS ws ON wr.uid = ws.workflow_record_uid
+		WHERE w.project_uid = ? AND w.workflow_type = ? AND ws.operation_user_uid = w.create_user_uid AND ws.state = 'finish'
+		ORDER BY ws.operate_at DESC
+		`, projectUid, workflowType).Scan(&results).Error; err != nil {
+			return pkgErr.WrapStorageErr(d.log, fmt.Errorf("failed to list self approved workflows: %v", err))
+		}
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+
+	ret := make([]*biz.SelfApprovedWorkflow, 0, len(results))
+	for _, r := range results {
+		ret = append(ret, &biz.SelfApprovedWorkflow{
+			WorkflowUID:  r.WorkflowUid,
+			WorkflowName: r.WorkflowName,
+			UserUID:      r.UserUid,
+			OperateAt:    r.OperateAt,
+		})
+	}
+	return ret, nil
+}
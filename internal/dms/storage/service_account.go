@@ -0,0 +1,97 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/actiontech/dms/internal/dms/biz"
+	pkgErr "github.com/actiontech/dms/internal/dms/pkg/errors"
+	"github.com/actiontech/dms/internal/dms/storage/model"
+	utilLog "github.com/actiontech/dms/pkg/dms-common/pkg/log"
+
+	"gorm.io/gorm"
+)
+
+var _ biz.ServiceAccountRepo = (*ServiceAccountRepo)(nil)
+
+type ServiceAccountRepo struct {
+	*Storage
+	log *utilLog.Helper
+}
+
+func NewServiceAccountRepo(log utilLog.Logger, s *Storage) *ServiceAccountRepo {
+	return &ServiceAccountRepo{Storage: s, log: utilLog.NewHelper(log, utilLog.WithMessageKey("storage.service_account"))}
+}
+
+func (d *ServiceAccountRepo) SaveServiceAccount(ctx context.Context, sa *biz.ServiceAccount) error {
+	return transaction(d.log, ctx, d.db, func(tx *gorm.DB) error {
+		if err := tx.WithContext(ctx).Create(convertBizServiceAccount(sa)).Error; err != nil {
+			return pkgErr.WrapStorageErr(d.log, fmt.Errorf("failed to save service account: %v", err))
+		}
+		return nil
+	})
+}
+
+func (d *ServiceAccountRepo) UpdateServiceAccount(ctx context.Context, sa *biz.ServiceAccount) error {
+	return transaction(d.log, ctx, d.db, func(tx *gorm.DB) error {
+		if err := tx.WithContext(ctx).Model(&model.ServiceAccount{}).Where("uid = ?", sa.UID).Updates(map[string]interface{}{
+			"client_secret_hash": sa.ClientSecretHash,
+			"secret_rotated_at":  sa.SecretRotatedAt,
+		}).Error; err != nil {
+			return pkgErr.WrapStorageErr(d.log, fmt.Errorf("failed to update service account: %v", err))
+		}
+		return nil
+	})
+}
+
+func (d *ServiceAccountRepo) getServiceAccount(ctx context.Context, query string, arg interface{}) (*biz.ServiceAccount, error) {
+	var sa model.ServiceAccount
+	if err := transaction(d.log, ctx, d.db, func(tx *gorm.DB) error {
+		if err := tx.WithContext(ctx).Where(query, arg).First(&sa).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return pkgErr.ErrStorageNoData
+			}
+			return pkgErr.WrapStorageErr(d.log, fmt.Errorf("failed to get service account: %v", err))
+		}
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+	return convertModelServiceAccount(&sa), nil
+}
+
+func (d *ServiceAccountRepo) GetServiceAccount(ctx context.Context, uid string) (*biz.ServiceAccount, error) {
+	return d.getServiceAccount(ctx, "uid = ?", uid)
+}
+
+func (d *ServiceAccountRepo) GetServiceAccountByClientID(ctx context.Context, clientID string) (*biz.ServiceAccount, error) {
+	return d.getServiceAccount(ctx, "client_id = ?", clientID)
+}
+
+func (d *ServiceAccountRepo) ListServiceAccounts(ctx context.Context) ([]*biz.ServiceAccount, error) {
+	var models []*model.ServiceAccount
+	if err := transaction(d.log, ctx, d.db, func(tx *gorm.DB) error {
+		if err := tx.WithContext(ctx).Order("created_at DESC").Find(&models).Error; err != nil {
+			return pkgErr.WrapStorageErr(d.log, fmt.Errorf("failed to list service accounts: %v", err))
+		}
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+
+	ret := make([]*biz.ServiceAccount, 0, len(models))
+	for _, m := range models {
+		ret = append(ret, convertModelServiceAccount(m))
+	}
+	return ret, nil
+}
+
+func (d *ServiceAccountRepo) DelServiceAccount(ctx context.Context, uid string) error {
+	return transaction(d.log, ctx, d.db, func(tx *gorm.DB) error {
+		if err := tx.WithContext(ctx).Where("uid = ?", uid).Delete(&model.ServiceAccount{}).Error; err != nil {
+			return pkgErr.WrapStorageErr(d.log, fmt.Errorf("failed to delete service account: %v", err))
+		}
+		return nil
+	})
+}
@@ -0,0 +1,50 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/actiontech/dms/internal/dms/biz"
+	pkgErr "github.com/actiontech/dms/internal/dms/pkg/errors"
+	"github.com/actiontech/dms/internal/dms/storage/model"
+	utilLog "github.com/actiontech/dms/pkg/dms-common/pkg/log"
+
+	"gorm.io/gorm"
+)
+
+var _ biz.ServiceOpPermissionRepo = (*ServiceOpPermissionRepo)(nil)
+
+type ServiceOpPermissionRepo struct {
+	*Storage
+	log *utilLog.Helper
+}
+
+func NewServiceOpPermissionRepo(log utilLog.Logger, s *Storage) *ServiceOpPermissionRepo {
+	return &ServiceOpPermissionRepo{Storage: s, log: utilLog.NewHelper(log, utilLog.WithMessageKey("storage.service_op_permission"))}
+}
+
+func (d *ServiceOpPermissionRepo) GetServiceOpPermissionManifest(ctx context.Context, serviceName string) (*biz.ServiceOpPermissionManifest, error) {
+	var m model.ServiceOpPermissionManifest
+	if err := transaction(d.log, ctx, d.db, func(tx *gorm.DB) error {
+		if err := tx.WithContext(ctx).Where("service_name = ?", serviceName).First(&m).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return pkgErr.ErrStorageNoData
+			}
+			return pkgErr.WrapStorageErr(d.log, fmt.Errorf("failed to get service op permission manifest: %v", err))
+		}
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+	return convertModelServiceOpPermissionManifest(&m)
+}
+
+func (d *ServiceOpPermissionRepo) SaveServiceOpPermissionManifest(ctx context.Context, manifest *biz.ServiceOpPermissionManifest) error {
+	return transaction(d.log, ctx, d.db, func(tx *gorm.DB) error {
+		if err := tx.WithContext(ctx).Save(convertBizServiceOpPermissionManifest(manifest)).Error; err != nil {
+			return pkgErr.WrapStorageErr(d.log, fmt.Errorf("failed to save service op permission manifest: %v", err))
+		}
+		return nil
+	})
+}
@@ -80,6 +80,18 @@ func (s *Storage) AutoMigrate(logger pkgLog.Logger) error {
 		return pkgErr.WrapStorageErr(log, err)
 	}
 
+	// 需在新索引建立后再删除旧唯一索引，避免外键列上没有可用索引
+	if s.db.Migrator().HasIndex(&model.UserAccessToken{}, model.UserAccessTokenLegacyUniqueIndex) {
+		if err := s.db.Migrator().DropIndex(&model.UserAccessToken{}, model.UserAccessTokenLegacyUniqueIndex); err != nil {
+			return pkgErr.WrapStorageErr(log, err)
+		}
+	}
+	if s.db.Migrator().HasIndex(&model.Role{}, model.RoleLegacyNameUniqueIndex) {
+		if err := s.db.Migrator().DropIndex(&model.Role{}, model.RoleLegacyNameUniqueIndex); err != nil {
+			return pkgErr.WrapStorageErr(log, err)
+		}
+	}
+
 	return nil
 }
 
@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"time"
 
 	"github.com/actiontech/dms/internal/dms/biz"
 	"github.com/actiontech/dms/internal/dms/pkg/constant"
@@ -354,33 +355,25 @@ func (d *UserRepo) GetUserByThirdPartyUserID(ctx context.Context, thirdPartyUser
 }
 
 func (d *UserRepo) SaveAccessToken(ctx context.Context, tokenInfo *biz.AccessTokenInfo) error {
-	userAccessToekn := &model.UserAccessToken{
-		Model: model.Model{
-			UID: tokenInfo.UID,
-		},
-		UserID:      tokenInfo.UserID,
-		Token:       tokenInfo.Token,
-		ExpiredTime: tokenInfo.ExpiredTime,
-	}
-
-	tx := d.db.Clauses(clause.OnConflict{
-		Columns:   []clause.Column{{Name: "user_id"}},
-		DoUpdates: clause.Assignments(map[string]interface{}{"token": tokenInfo.Token, "expired_time": tokenInfo.ExpiredTime}),
-	}).Create(userAccessToekn)
-
-	if tx.Error != nil {
-		return fmt.Errorf("failed to save access token: %v", tx.Error)
+	if err := transaction(d.log, ctx, d.db, func(tx *gorm.DB) error {
+		if err := tx.Create(convertBizAccessToken(tokenInfo)).Error; err != nil {
+			return fmt.Errorf("failed to save access token: %v", err)
+		}
+		return nil
+	}); err != nil {
+		return err
 	}
-
 	return nil
 }
 
+// GetAccessTokenByUser 获取用户最近生成的 access token
 func (d *UserRepo) GetAccessTokenByUser(ctx context.Context, userUid string) (*biz.AccessTokenInfo, error) {
 	var userToken *model.UserAccessToken
 	if err := transaction(d.log, ctx, d.db, func(tx *gorm.DB) error {
-		if err := tx.First(&userToken, "user_id = ?", userUid).Error; err != nil {
+		if err := tx.Order("created_at DESC").First(&userToken, "user_id = ?", userUid).Error; err != nil {
 			// 未找到记录返回空，不影响获取用户信息的功能
 			if errors.Is(err, gorm.ErrRecordNotFound) {
+				userToken = &model.UserAccessToken{}
 				return nil
 			}
 			return fmt.Errorf("failed to get user access token: %v", err)
@@ -390,7 +383,81 @@ func (d *UserRepo) GetAccessTokenByUser(ctx context.Context, userUid string) (*b
 		return nil, err
 	}
 
-	return &biz.AccessTokenInfo{Token: userToken.Token, ExpiredTime: userToken.ExpiredTime}, nil
+	return convertModelAccessToken(userToken), nil
+}
+
+func (d *UserRepo) GetAccessToken(ctx context.Context, accessTokenUid string) (*biz.AccessTokenInfo, error) {
+	var userToken *model.UserAccessToken
+	if err := transaction(d.log, ctx, d.db, func(tx *gorm.DB) error {
+		if err := tx.First(&userToken, "uid = ?", accessTokenUid).Error; err != nil {
+			return fmt.Errorf("failed to get access token: %v", err)
+		}
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+
+	return convertModelAccessToken(userToken), nil
+}
+
+// GetAccessTokenByToken 用于校验未携带 token id 的旧版本 access token
+func (d *UserRepo) GetAccessTokenByToken(ctx context.Context, userUid, token string) (*biz.AccessTokenInfo, error) {
+	var userToken *model.UserAccessToken
+	if err := transaction(d.log, ctx, d.db, func(tx *gorm.DB) error {
+		if err := tx.First(&userToken, "user_id = ? AND token = ?", userUid, token).Error; err != nil {
+			return fmt.Errorf("failed to get access token: %v", err)
+		}
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+
+	return convertModelAccessToken(userToken), nil
+}
+
+func (d *UserRepo) ListAccessTokensByUser(ctx context.Context, userUid string) ([]*biz.AccessTokenInfo, error) {
+	var userTokens []*model.UserAccessToken
+	if err := transaction(d.log, ctx, d.db, func(tx *gorm.DB) error {
+		if err := tx.Order("created_at DESC").Find(&userTokens, "user_id = ?", userUid).Error; err != nil {
+			return fmt.Errorf("failed to list access tokens: %v", err)
+		}
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+
+	ret := make([]*biz.AccessTokenInfo, 0, len(userTokens))
+	for _, userToken := range userTokens {
+		ret = append(ret, convertModelAccessToken(userToken))
+	}
+	return ret, nil
+}
+
+func (d *UserRepo) UpdateAccessTokenLastUsed(ctx context.Context, accessTokenUid string, lastUsedAt time.Time, lastUsedIP string) error {
+	if err := transaction(d.log, ctx, d.db, func(tx *gorm.DB) error {
+		if err := tx.Model(&model.UserAccessToken{}).Where("uid = ?", accessTokenUid).Updates(map[string]interface{}{
+			"last_used_at": lastUsedAt,
+			"last_used_ip": lastUsedIP,
+		}).Error; err != nil {
+			return fmt.Errorf("failed to update access token last used: %v", err)
+		}
+		return nil
+	}); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (d *UserRepo) DelAccessToken(ctx context.Context, accessTokenUid string) error {
+	if err := transaction(d.log, ctx, d.db, func(tx *gorm.DB) error {
+		if err := tx.Where("uid = ?", accessTokenUid).Delete(&model.UserAccessToken{}).Error; err != nil {
+			return fmt.Errorf("failed to delete access token: %v", err)
+		}
+		return nil
+	}); err != nil {
+		return err
+	}
+	return nil
 }
 
 func (d *UserRepo) RecordLoginSession(ctx context.Context, userUID, sessionID string) error {
@@ -425,3 +492,158 @@ func (d *UserRepo) GetLatestLoginSession(ctx context.Context, userUID string) (s
 	}
 	return loginSession.SessionID, true, nil
 }
+
+func (d *UserRepo) SaveLoginSession(ctx context.Context, session *biz.LoginSession) error {
+	return transaction(d.log, ctx, d.db, func(tx *gorm.DB) error {
+		if err := tx.WithContext(ctx).Create(convertBizLoginSession(session)).Error; err != nil {
+			return fmt.Errorf("failed to save login session: %v", err)
+		}
+		return nil
+	})
+}
+
+func (d *UserRepo) GetLoginSession(ctx context.Context, sessionID string) (*biz.LoginSession, error) {
+	var session model.LoginSession
+	if err := transaction(d.log, ctx, d.db, func(tx *gorm.DB) error {
+		if err := tx.WithContext(ctx).First(&session, "uid = ?", sessionID).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return pkgErr.ErrStorageNoData
+			}
+			return fmt.Errorf("failed to get login session: %v", err)
+		}
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+	return convertModelLoginSession(&session), nil
+}
+
+func (d *UserRepo) ListActiveLoginSessions(ctx context.Context, userUID string, now time.Time) ([]*biz.LoginSession, error) {
+	var sessions []*model.LoginSession
+	if err := transaction(d.log, ctx, d.db, func(tx *gorm.DB) error {
+		db := tx.WithContext(ctx).Where("revoked_at IS NULL AND expired_at > ?", now)
+		if userUID != "" {
+			db = db.Where("user_uid = ?", userUID)
+		}
+		if err := db.Order("created_at DESC").Find(&sessions).Error; err != nil {
+			return fmt.Errorf("failed to list login sessions: %v", err)
+		}
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+
+	ret := make([]*biz.LoginSession, 0, len(sessions))
+	for _, s := range sessions {
+		ret = append(ret, convertModelLoginSession(s))
+	}
+	return ret, nil
+}
+
+func (d *UserRepo) UpdateLoginSessionLastSeen(ctx context.Context, sessionID string, lastSeenAt time.Time, lastSeenIP string) error {
+	return transaction(d.log, ctx, d.db, func(tx *gorm.DB) error {
+		updates := map[string]interface{}{"last_seen_at": lastSeenAt}
+		if lastSeenIP != "" {
+			updates["ip"] = lastSeenIP
+		}
+		if err := tx.WithContext(ctx).Model(&model.LoginSession{}).Where("uid = ?", sessionID).Updates(updates).Error; err != nil {
+			return fmt.Errorf("failed to update login session last seen: %v", err)
+		}
+		return nil
+	})
+}
+
+func (d *UserRepo) UpdateLoginSessionExpiredAt(ctx context.Context, sessionID string, expiredAt time.Time) error {
+	return transaction(d.log, ctx, d.db, func(tx *gorm.DB) error {
+		if err := tx.WithContext(ctx).Model(&model.LoginSession{}).Where("uid = ?", sessionID).Update("expired_at", expiredAt).Error; err != nil {
+			return fmt.Errorf("failed to update login session expired time: %v", err)
+		}
+		return nil
+	})
+}
+
+func (d *UserRepo) RevokeLoginSession(ctx context.Context, sessionID string, revokedAt time.Time) error {
+	return transaction(d.log, ctx, d.db, func(tx *gorm.DB) error {
+		if err := tx.WithContext(ctx).Model(&model.LoginSession{}).Where("uid = ? AND revoked_at IS NULL", sessionID).Update("revoked_at", revokedAt).Error; err != nil {
+			return fmt.Errorf("failed to revoke login session: %v", err)
+		}
+		return nil
+	})
+}
+
+func (d *UserRepo) RevokeUserLoginSessions(ctx context.Context, userUID string, revokedAt time.Time) error {
+	return transaction(d.log, ctx, d.db, func(tx *gorm.DB) error {
+		if err := tx.WithContext(ctx).Model(&model.LoginSession{}).Where("user_uid = ? AND revoked_at IS NULL", userUID).Update("revoked_at", revokedAt).Error; err != nil {
+			return fmt.Errorf("failed to revoke login sessions of user: %v", err)
+		}
+		return nil
+	})
+}
+
+func (d *UserRepo) SavePasswordHistory(ctx context.Context, userUid, passwordHash string) error {
+	uid, err := pkgRand.GenStrUid()
+	if err != nil {
+		return fmt.Errorf("failed to generate password history uid: %v", err)
+	}
+	return transaction(d.log, ctx, d.db, func(tx *gorm.DB) error {
+		if err := tx.WithContext(ctx).Create(&model.UserPasswordHistory{
+			Model:        model.Model{UID: uid},
+			UserUID:      userUid,
+			PasswordHash: passwordHash,
+		}).Error; err != nil {
+			return fmt.Errorf("failed to save password history: %v", err)
+		}
+		return nil
+	})
+}
+
+func (d *UserRepo) IncreaseUserLoginFailedCount(ctx context.Context, userUid string, now, windowStart time.Time) (count uint, failedAt time.Time, err error) {
+	var user model.User
+	if err := transaction(d.log, ctx, d.db, func(tx *gorm.DB) error {
+		// MySQL 按顺序赋值，login_failed_count 需先于 login_failed_at 计算，判断时使用的是上次失败的时间
+		if err := tx.WithContext(ctx).Exec(`
+			UPDATE users SET
+				login_failed_count = CASE WHEN login_failed_at IS NULL OR login_failed_at < ? THEN 1 ELSE login_failed_count + 1 END,
+				login_failed_at = CASE WHEN login_failed_at IS NULL OR login_failed_at < ? THEN ? ELSE login_failed_at END
+			WHERE uid = ?`, windowStart, windowStart, now, userUid).Error; err != nil {
+			return fmt.Errorf("failed to increase user login failed count: %v", err)
+		}
+		// 同一事务内读取，行锁保证读到的是本次累计后的值
+		if err := tx.WithContext(ctx).Model(&model.User{}).Select("login_failed_count", "login_failed_at").Where("uid = ?", userUid).Take(&user).Error; err != nil {
+			return fmt.Errorf("failed to get user login failed count: %v", err)
+		}
+		return nil
+	}); err != nil {
+		return 0, time.Time{}, err
+	}
+	return user.LoginFailedCount, convertModelTimeToBiz(user.LoginFailedAt), nil
+}
+
+func (d *UserRepo) LockUserLogin(ctx context.Context, userUid string, minFailedCount uint, lockedUntil time.Time) error {
+	return transaction(d.log, ctx, d.db, func(tx *gorm.DB) error {
+		if err := tx.WithContext(ctx).Exec(`
+			UPDATE users SET locked_until = ?, login_failed_count = 0, login_failed_at = NULL
+			WHERE uid = ? AND login_failed_count >= ?`, lockedUntil, userUid, minFailedCount).Error; err != nil {
+			return fmt.Errorf("failed to lock user login: %v", err)
+		}
+		return nil
+	})
+}
+
+func (d *UserRepo) ListPasswordHistories(ctx context.Context, userUid string, limit uint) ([]string, error) {
+	var histories []*model.UserPasswordHistory
+	if err := transaction(d.log, ctx, d.db, func(tx *gorm.DB) error {
+		if err := tx.WithContext(ctx).Where("user_uid = ?", userUid).Order("created_at DESC").Limit(int(limit)).Find(&histories).Error; err != nil {
+			return fmt.Errorf("failed to list password histories: %v", err)
+		}
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+
+	hashes := make([]string, 0, len(histories))
+	for _, h := range histories {
+		hashes = append(hashes, h.PasswordHash)
+	}
+	return hashes, nil
+}
@@ -209,3 +209,25 @@ func (d *UserGroupRepo) GetUsersInUserGroup(ctx context.Context, userGroupUid st
 	}
 	return ret, nil
 }
+
+func (d *UserGroupRepo) ListMemberGroupsBoundToUserGroup(ctx context.Context, userGroupUid string) ([]*biz.MemberGroup, error) {
+	var models []*model.MemberGroup
+	if err := transaction(d.log, ctx, d.db, func(tx *gorm.DB) error {
+		if err := tx.WithContext(ctx).Preload("RoleWithOpRanges").Preload("OpPermissions").Where("user_group_uid = ?", userGroupUid).Find(&models).Error; err != nil {
+			return fmt.Errorf("failed to list member groups bound to user group: %v", err)
+		}
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+
+	ret := make([]*biz.MemberGroup, 0, len(models))
+	for _, mg := range models {
+		r, err := convertModelMemberGroup(mg)
+		if err != nil {
+			return nil, pkgErr.WrapStorageErr(d.log, fmt.Errorf("failed to convert model member group: %v", err))
+		}
+		ret = append(ret, r)
+	}
+	return ret, nil
+}
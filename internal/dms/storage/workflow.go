@@ -3,6 +3,7 @@ package storage
 import (
 	"bytes"
 	"context"
+	"errors"
 	"fmt"
 	"reflect"
 	"regexp"
@@ -14,6 +15,7 @@ import (
 	pkgErr "github.com/actiontech/dms/internal/dms/pkg/errors"
 	"github.com/actiontech/dms/internal/dms/storage/model"
 	dmsCommonV1 "github.com/actiontech/dms/pkg/dms-common/api/dms/v1"
+	pkgAes "github.com/actiontech/dms/pkg/dms-common/pkg/aes"
 	utilLog "github.com/actiontech/dms/pkg/dms-common/pkg/log"
 	"gorm.io/gorm"
 )
@@ -67,6 +69,20 @@ func (d *WorkflowRepo) UpdateWorkflowColumns(ctx context.Context, workflowUID st
 	})
 }
 
+// UpdateWorkflowExportPassword 解压密码与数据源密码一样加密存储
+func (d *WorkflowRepo) UpdateWorkflowExportPassword(ctx context.Context, workflowUID, password string) error {
+	encrypted, err := pkgAes.AesEncrypt(password)
+	if err != nil {
+		return pkgErr.WrapStorageErr(d.log, fmt.Errorf("failed to encrypt export password: %v", err))
+	}
+	return transaction(d.log, ctx, d.db, func(tx *gorm.DB) error {
+		if err := tx.WithContext(ctx).Model(&model.Workflow{}).Where("uid = ?", workflowUID).Update("export_password", encrypted).Error; err != nil {
+			return fmt.Errorf("failed to update workflow export password: %v", err)
+		}
+		return nil
+	})
+}
+
 func (d *WorkflowRepo) IsDataExportWorkflowNameDuplicate(ctx context.Context, projectUID, workflowName string) (bool, error) {
 	var count int64
 	if err := transaction(d.log, ctx, d.db, func(tx *gorm.DB) error {
@@ -398,6 +414,30 @@ func (d *WorkflowRepo) GetDataExportWorkflowsByIds(ctx context.Context, ids []st
 	return ret, nil
 }
 
+func (d *WorkflowRepo) GetDataExportWorkflowByTask(ctx context.Context, dataExportTaskUid string) (*biz.Workflow, error) {
+	var workflow *model.Workflow
+	if err := transaction(d.log, ctx, d.db, func(tx *gorm.DB) error {
+		if err := tx.WithContext(ctx).Preload("WorkflowRecord").
+			Joins("JOIN workflow_records wr ON workflows.workflow_record_uid = wr.uid").
+			Where("JSON_CONTAINS(wr.task_ids, JSON_QUOTE(?))", dataExportTaskUid).
+			First(&workflow).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return pkgErr.WrapStorageErr(d.log, pkgErr.ErrStorageNoData)
+			}
+			return pkgErr.WrapStorageErr(d.log, fmt.Errorf("failed to get workflow by task: %v", err))
+		}
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+
+	ret, err := convertModelWorkflow(workflow)
+	if err != nil {
+		return nil, pkgErr.WrapStorageErr(d.log, fmt.Errorf("failed to convert model workflow: %v", err))
+	}
+	return ret, nil
+}
+
 func (d *WorkflowRepo) CountDataExportWorkflowsByTemplateId(ctx context.Context, projectUID string, templateID uint) (int64, error) {
 	var count int64
 	unfinishedStatuses := []string{
@@ -0,0 +1,108 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/actiontech/dms/internal/dms/biz"
+	pkgErr "github.com/actiontech/dms/internal/dms/pkg/errors"
+	"github.com/actiontech/dms/internal/dms/storage/model"
+	utilLog "github.com/actiontech/dms/pkg/dms-common/pkg/log"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var _ biz.WorkflowApprovalSLARepo = (*WorkflowApprovalSLARepo)(nil)
+
+type WorkflowApprovalSLARepo struct {
+	*Storage
+	log *utilLog.Helper
+}
+
+func NewWorkflowApprovalSLARepo(log utilLog.Logger, s *Storage) *WorkflowApprovalSLARepo {
+	return &WorkflowApprovalSLARepo{Storage: s, log: utilLog.NewHelper(log, utilLog.WithMessageKey("storage.workflow_approval_sla"))}
+}
+
+func (d *WorkflowApprovalSLARepo) SaveWorkflowApprovalSLA(ctx context.Context, sla *biz.WorkflowApprovalSLA) error {
+	return transaction(d.log, ctx, d.db, func(tx *gorm.DB) error {
+		if err := tx.WithContext(ctx).Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "project_uid"}},
+			DoUpdates: clause.AssignmentColumns([]string{"remind_after_hours", "escalate_after_hours", "auto_reject_after_hours", "updated_at"}),
+		}).Create(convertBizWorkflowApprovalSLA(sla)).Error; err != nil {
+			return pkgErr.WrapStorageErr(d.log, fmt.Errorf("failed to save workflow approval sla: %v", err))
+		}
+		return nil
+	})
+}
+
+func (d *WorkflowApprovalSLARepo) DeleteWorkflowApprovalSLA(ctx context.Context, projectUid string) error {
+	return transaction(d.log, ctx, d.db, func(tx *gorm.DB) error {
+		if err := tx.WithContext(ctx).Where("project_uid = ?", projectUid).Delete(&model.WorkflowApprovalSLA{}).Error; err != nil {
+			return pkgErr.WrapStorageErr(d.log, fmt.Errorf("failed to delete workflow approval sla: %v", err))
+		}
+		return nil
+	})
+}
+
+func (d *WorkflowApprovalSLARepo) GetWorkflowApprovalSLA(ctx context.Context, projectUid string) (*biz.WorkflowApprovalSLA, error) {
+	var sla *model.WorkflowApprovalSLA
+	if err := transaction(d.log, ctx, d.db, func(tx *gorm.DB) error {
+		if err := tx.WithContext(ctx).First(&sla, "project_uid = ?", projectUid).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return pkgErr.WrapStorageErr(d.log, pkgErr.ErrStorageNoData)
+			}
+			return pkgErr.WrapStorageErr(d.log, fmt.Errorf("failed to get workflow approval sla: %v", err))
+		}
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+	return convertModelWorkflowApprovalSLA(sla), nil
+}
+
+func (d *WorkflowApprovalSLARepo) ListWorkflowApprovalSLAs(ctx context.Context) ([]*biz.WorkflowApprovalSLA, error) {
+	var models []*model.WorkflowApprovalSLA
+	if err := transaction(d.log, ctx, d.db, func(tx *gorm.DB) error {
+		if err := tx.WithContext(ctx).Find(&models).Error; err != nil {
+			return pkgErr.WrapStorageErr(d.log, fmt.Errorf("failed to list workflow approval slas: %v", err))
+		}
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+	ret := make([]*biz.WorkflowApprovalSLA, 0, len(models))
+	for _, m := range models {
+		ret = append(ret, convertModelWorkflowApprovalSLA(m))
+	}
+	return ret, nil
+}
+
+func (d *WorkflowApprovalSLARepo) GetWorkflowApprovalSLARecord(ctx context.Context, workflowUid string, stepId uint64) (*biz.WorkflowApprovalSLARecord, error) {
+	var record *model.WorkflowApprovalSLARecord
+	if err := transaction(d.log, ctx, d.db, func(tx *gorm.DB) error {
+		if err := tx.WithContext(ctx).First(&record, "workflow_uid = ? AND step_id = ?", workflowUid, stepId).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return pkgErr.WrapStorageErr(d.log, pkgErr.ErrStorageNoData)
+			}
+			return pkgErr.WrapStorageErr(d.log, fmt.Errorf("failed to get workflow approval sla record: %v", err))
+		}
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+	return convertModelWorkflowApprovalSLARecord(record), nil
+}
+
+func (d *WorkflowApprovalSLARepo) SaveWorkflowApprovalSLARecord(ctx context.Context, record *biz.WorkflowApprovalSLARecord) error {
+	return transaction(d.log, ctx, d.db, func(tx *gorm.DB) error {
+		if err := tx.WithContext(ctx).Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "workflow_uid"}, {Name: "step_id"}},
+			DoUpdates: clause.AssignmentColumns([]string{"reminded_at", "escalated_at", "updated_at"}),
+		}).Create(convertBizWorkflowApprovalSLARecord(record)).Error; err != nil {
+			return pkgErr.WrapStorageErr(d.log, fmt.Errorf("failed to save workflow approval sla record: %v", err))
+		}
+		return nil
+	})
+}
@@ -212,6 +212,7 @@ var (
 	SqlWorkbenchAuditGetDBServiceErr             = &i18n.Message{ID: "SqlWorkbenchAuditGetDBServiceErr", Other: "未找到当前数据源配置，请确认数据源存在后重试。"}
 	SqlWorkbenchAuditNotEnabledErr               = &i18n.Message{ID: "SqlWorkbenchAuditNotEnabledErr", Other: "该数据源尚未开启 SQL 审核，请先在数据源配置中开启“SQL 审核”后再执行。"}
 	SqlWorkbenchAuditCallSQLEErr                 = &i18n.Message{ID: "SqlWorkbenchAuditCallSQLEErr", Other: "审核服务当前繁忙或不可用，请稍后重试。"}
+	SqlWorkbenchObjectRangeDeniedErr             = &i18n.Message{ID: "SqlWorkbenchObjectRangeDeniedErr", Other: "您仅拥有该数据源部分库、模式或表的查询权限，当前所在的库不在授权范围内，请切换到已授权的库后重试。"}
 )
 
 // SQL Workbench Maintenance Time
@@ -256,23 +257,56 @@ var (
 
 // Data Export Workflow
 var (
-	DataExportWorkflowNameDuplicateErr      = &i18n.Message{ID: "DataExportWorkflowNameDuplicateErr", Other: "工单名称重复了，请您修改工单名称后重新提交工单。"}
-	DataWorkflowDefault                     = &i18n.Message{ID: "DataWorkflowDefault", Other: "❓数据导出工单未知请求"}
-	DataWorkflowExportFailed                = &i18n.Message{ID: "DataWorkflowExportFailed", Other: "⚠️ 数据导出失败"}
-	DataWorkflowExportSuccess               = &i18n.Message{ID: "DataWorkflowExportSuccess", Other: "✅ 数据导出成功"}
-	DataWorkflowReject                      = &i18n.Message{ID: "DataWorkflowReject", Other: "❌ 数据导出工单被驳回"}
-	DataWorkflowWaitExporting               = &i18n.Message{ID: "DataWorkflowWaitExporting", Other: "⏳ 数据导出工单待导出"}
-	DataWorkflowWaiting                     = &i18n.Message{ID: "DataWorkflowWaiting", Other: "🔍 数据导出工单待审批"}
-	NotifyDataWorkflowBodyConfigUrl         = &i18n.Message{ID: "NotifyDataWorkflowBodyConfigUrl", Other: "请在系统设置-全局配置中补充全局url"}
-	NotifyDataWorkflowBodyHead              = &i18n.Message{ID: "NotifyDataWorkflowBodyHead", Other: "\n📋 数据导出工单主题: %v\n📍 所属项目： %v\n🆔 数据导出工单ID: %v\n📝 数据导出工单描述: %v\n👤 申请人: %v\n⏰ 创建时间: %v\n"}
-	NotifyDataWorkflowBodyInstanceAndSchema = &i18n.Message{ID: "NotifyDataWorkflowBodyInstanceAndSchema", Other: "🗄️ 数据源: %v\n📊 schema: %v\n"}
-	NotifyDataWorkflowBodyLink              = &i18n.Message{ID: "NotifyDataWorkflowBodyLink", Other: "🔗 数据导出工单链接: %v"}
-	NotifyDataWorkflowBodyReason            = &i18n.Message{ID: "NotifyDataWorkflowBodyReason", Other: "❌ 驳回原因: %v"}
-	NotifyDataWorkflowBodyExportFailReason  = &i18n.Message{ID: "NotifyDataWorkflowBodyExportFailReason", Other: "❌ 失败原因: %v"}
-	NotifyDataWorkflowBodyReport            = &i18n.Message{ID: "NotifyDataWorkflowBodyReport", Other: "⭐ 数据导出工单审核得分: %v"}
-	NotifyDataWorkflowBodyStartEnd          = &i18n.Message{ID: "NotifyDataWorkflowBodyStartEnd", Other: "▶️ 数据导出开始时间: %v\n◀️ 数据导出结束时间: %v"}
-	NotifyDataWorkflowBodyWorkFlowErr       = &i18n.Message{ID: "NotifyDataWorkflowBodyWorkFlowErr", Other: "❌ 读取工单任务内容失败，请通过SQLE界面确认工单状态"}
-	NotifyDataWorkflowBodyApprovalReminder  = &i18n.Message{ID: "NotifyDataWorkflowBodyApprovalReminder", Other: "⏰ 导出工单已审批通过，请在1天内完成导出，过期后将无法执行"}
+	DataExportQuotaFileSizeExceeded             = &i18n.Message{ID: "DataExportQuotaFileSizeExceeded", Other: "导出文件大小超过限制（%d 字节）"}
+	DataExportQuotaProjectBytesExceeded         = &i18n.Message{ID: "DataExportQuotaProjectBytesExceeded", Other: "项目本月导出数据量已达上限（%d 字节）"}
+	DataExportQuotaRowsExceeded                 = &i18n.Message{ID: "DataExportQuotaRowsExceeded", Other: "单条 SQL 导出行数超过限制（%d 行）"}
+	DataExportQuotaUserExportsExceeded          = &i18n.Message{ID: "DataExportQuotaUserExportsExceeded", Other: "今日导出次数已达上限（%d 次）"}
+	DataExportWorkflowNameDuplicateErr          = &i18n.Message{ID: "DataExportWorkflowNameDuplicateErr", Other: "工单名称重复了，请您修改工单名称后重新提交工单。"}
+	DataWorkflowApprovalTimeoutRejectReason     = &i18n.Message{ID: "DataWorkflowApprovalTimeoutRejectReason", Other: "审批超过 %v 小时未处理，已自动驳回"}
+	DataWorkflowDefault                         = &i18n.Message{ID: "DataWorkflowDefault", Other: "❓数据导出工单未知请求"}
+	DataWorkflowExportFailed                    = &i18n.Message{ID: "DataWorkflowExportFailed", Other: "⚠️ 数据导出失败"}
+	DataWorkflowExportSuccess                   = &i18n.Message{ID: "DataWorkflowExportSuccess", Other: "✅ 数据导出成功"}
+	DataWorkflowReject                          = &i18n.Message{ID: "DataWorkflowReject", Other: "❌ 数据导出工单被驳回"}
+	DataWorkflowWaitExporting                   = &i18n.Message{ID: "DataWorkflowWaitExporting", Other: "⏳ 数据导出工单待导出"}
+	DataWorkflowWaiting                         = &i18n.Message{ID: "DataWorkflowWaiting", Other: "🔍 数据导出工单待审批"}
+	NotifyDataWorkflowBodyConfigUrl             = &i18n.Message{ID: "NotifyDataWorkflowBodyConfigUrl", Other: "请在系统设置-全局配置中补充全局url"}
+	NotifyDataWorkflowBodyHead                  = &i18n.Message{ID: "NotifyDataWorkflowBodyHead", Other: "\n📋 数据导出工单主题: %v\n📍 所属项目： %v\n🆔 数据导出工单ID: %v\n📝 数据导出工单描述: %v\n👤 申请人: %v\n⏰ 创建时间: %v\n"}
+	NotifyDataWorkflowBodyInstanceAndSchema     = &i18n.Message{ID: "NotifyDataWorkflowBodyInstanceAndSchema", Other: "🗄️ 数据源: %v\n📊 schema: %v\n"}
+	NotifyDataWorkflowBodyLink                  = &i18n.Message{ID: "NotifyDataWorkflowBodyLink", Other: "🔗 数据导出工单链接: %v"}
+	NotifyDataWorkflowBodyReason                = &i18n.Message{ID: "NotifyDataWorkflowBodyReason", Other: "❌ 驳回原因: %v"}
+	NotifyDataWorkflowBodyExportFailReason      = &i18n.Message{ID: "NotifyDataWorkflowBodyExportFailReason", Other: "❌ 失败原因: %v"}
+	NotifyDataWorkflowBodyReport                = &i18n.Message{ID: "NotifyDataWorkflowBodyReport", Other: "⭐ 数据导出工单审核得分: %v"}
+	NotifyDataWorkflowBodyStartEnd              = &i18n.Message{ID: "NotifyDataWorkflowBodyStartEnd", Other: "▶️ 数据导出开始时间: %v\n◀️ 数据导出结束时间: %v"}
+	NotifyDataWorkflowBodyWorkFlowErr           = &i18n.Message{ID: "NotifyDataWorkflowBodyWorkFlowErr", Other: "❌ 读取工单任务内容失败，请通过SQLE界面确认工单状态"}
+	NotifyDataWorkflowBodyApprovalReminder      = &i18n.Message{ID: "NotifyDataWorkflowBodyApprovalReminder", Other: "⏰ 导出工单已审批通过，请在1天内完成导出，过期后将无法执行"}
+	NotifyDataWorkflowExportPasswordSubject     = &i18n.Message{ID: "NotifyDataWorkflowExportPasswordSubject", Other: "🔑 数据导出文件解压密码"}
+	NotifyDataWorkflowExportPasswordBody        = &i18n.Message{ID: "NotifyDataWorkflowExportPasswordBody", Other: "📋 数据导出工单主题: %v\n📍 所属项目: %v\n🔑 解压密码: %v\n⚠️ 导出文件已加密，请在 DMS 中下载后使用该密码解压，请勿转发此消息"}
+	NotifyDataExportScheduleRunSubject          = &i18n.Message{ID: "NotifyDataExportScheduleRunSubject", Other: "🔁 定时数据导出已执行"}
+	NotifyDataExportScheduleRunBody             = &i18n.Message{ID: "NotifyDataExportScheduleRunBody", Other: "📋 数据导出工单主题: %v\n📍 所属项目: %v\n🆔 本次导出工单ID: %v"}
+	NotifyDataExportScheduleSuspendedSubject    = &i18n.Message{ID: "NotifyDataExportScheduleSuspendedSubject", Other: "⏸️ 定时数据导出已暂停"}
+	NotifyDataExportScheduleSuspendedBody       = &i18n.Message{ID: "NotifyDataExportScheduleSuspendedBody", Other: "📋 数据导出工单主题: %v\n📍 所属项目: %v\n❌ 暂停原因: %v"}
+	NotifyDataExportApprovalReminderSubject     = &i18n.Message{ID: "NotifyDataExportApprovalReminderSubject", Other: "⏰ 数据导出工单待您审批"}
+	NotifyDataExportApprovalReminderBody        = &i18n.Message{ID: "NotifyDataExportApprovalReminderBody", Other: "📋 数据导出工单主题: %v\n📍 所属项目: %v\n⏳ 已等待审批: %v 小时，请尽快处理"}
+	NotifyDataExportApprovalEscalatedSubject    = &i18n.Message{ID: "NotifyDataExportApprovalEscalatedSubject", Other: "🚨 数据导出工单审批超时"}
+	NotifyDataExportApprovalEscalatedBody       = &i18n.Message{ID: "NotifyDataExportApprovalEscalatedBody", Other: "📋 数据导出工单主题: %v\n📍 所属项目: %v\n⏳ 已等待审批: %v 小时\n👤 当前审批人: %v"}
+	NotifyDataExportApprovalAutoRejectedSubject = &i18n.Message{ID: "NotifyDataExportApprovalAutoRejectedSubject", Other: "❌ 数据导出工单审批超时已自动驳回"}
+	NotifyDataExportApprovalAutoRejectedBody    = &i18n.Message{ID: "NotifyDataExportApprovalAutoRejectedBody", Other: "📋 数据导出工单主题: %v\n📍 所属项目: %v\n⏳ 已等待审批: %v 小时，超过审批时限已自动驳回"}
+	NotifyDataExportDeliveredSubject            = &i18n.Message{ID: "NotifyDataExportDeliveredSubject", Other: "📦 数据导出文件已投递"}
+	NotifyDataExportDeliveredBody               = &i18n.Message{ID: "NotifyDataExportDeliveredBody", Other: "📋 数据导出工单主题: %v\n📍 所属项目: %v\n📦 投递位置: %v"}
+	NotifyDataExportDeliveryFailedSubject       = &i18n.Message{ID: "NotifyDataExportDeliveryFailedSubject", Other: "❌ 数据导出文件投递失败"}
+	NotifyDataExportDeliveryFailedBody          = &i18n.Message{ID: "NotifyDataExportDeliveryFailedBody", Other: "📋 数据导出工单主题: %v\n📍 所属项目: %v\n❌ 失败原因: %v"}
+)
+
+// Member Access Request
+var (
+	NotifyMemberAccessRequestSubject         = &i18n.Message{ID: "NotifyMemberAccessRequestSubject", Other: "🔐 项目临时权限申请待审批"}
+	NotifyMemberAccessRequestBody            = &i18n.Message{ID: "NotifyMemberAccessRequestBody", Other: "👤 申请人: %v\n📍 所属项目: %v\n⏰ 申请时长: %v 小时\n📝 申请原因: %v"}
+	NotifyMemberAccessRequestApprovedSubject = &i18n.Message{ID: "NotifyMemberAccessRequestApprovedSubject", Other: "✅ 项目临时权限申请已通过"}
+	NotifyMemberAccessRequestApprovedBody    = &i18n.Message{ID: "NotifyMemberAccessRequestApprovedBody", Other: "📍 所属项目: %v\n⏰ 授权时长: %v 小时，到期后将自动收回\n📝 审批意见: %v"}
+	NotifyMemberAccessRequestRejectedSubject = &i18n.Message{ID: "NotifyMemberAccessRequestRejectedSubject", Other: "❌ 项目临时权限申请被驳回"}
+	NotifyMemberAccessRequestRejectedBody    = &i18n.Message{ID: "NotifyMemberAccessRequestRejectedBody", Other: "📍 所属项目: %v\n⏰ 申请时长: %v 小时\n📝 驳回原因: %v"}
+	NotifyAccessReviewCampaignSubject        = &i18n.Message{ID: "NotifyAccessReviewCampaignSubject", Other: "📋 项目成员权限复核待处理"}
+	NotifyAccessReviewCampaignBody           = &i18n.Message{ID: "NotifyAccessReviewCampaignBody", Other: "📝 复核活动: %v\n📍 所属项目: %v\n👥 待复核授权: %v 项\n⏰ 截止时间: %v"}
 )
 
 // Operation Record
@@ -286,6 +320,14 @@ var (
 	OpRecordUserLogoutWithName                       = &i18n.Message{ID: "OpRecordUserLogoutWithName", Other: "用户 %s 登出系统"}
 	OpRecordUserOAuth2LoginWithName                  = &i18n.Message{ID: "OpRecordUserOAuth2LoginWithName", Other: "用户 %s 通过OAuth2登入系统"}
 	OpRecordUserOAuth2BindLoginWithName              = &i18n.Message{ID: "OpRecordUserOAuth2BindLoginWithName", Other: "用户 %s 通过OAuth2绑定登入系统"}
+	OpRecordUserLoginLockedWithName                  = &i18n.Message{ID: "OpRecordUserLoginLockedWithName", Other: "用户 %s 因登录失败次数过多被锁定，拒绝登录"}
+	OpRecordUserUnlockWithName                       = &i18n.Message{ID: "OpRecordUserUnlockWithName", Other: "解锁用户 %s"}
+	OpRecordUserRevokeLoginSessionWithName           = &i18n.Message{ID: "OpRecordUserRevokeLoginSessionWithName", Other: "吊销用户 %s 的登录会话"}
+	OpRecordServiceAccountCreateWithName             = &i18n.Message{ID: "OpRecordServiceAccountCreateWithName", Other: "创建服务账号 %s"}
+	OpRecordServiceAccountUpdateWithName             = &i18n.Message{ID: "OpRecordServiceAccountUpdateWithName", Other: "编辑服务账号 %s"}
+	OpRecordServiceAccountRotateSecretWithName       = &i18n.Message{ID: "OpRecordServiceAccountRotateSecretWithName", Other: "重置服务账号 %s 的 client secret"}
+	OpRecordServiceAccountDeleteWithName             = &i18n.Message{ID: "OpRecordServiceAccountDeleteWithName", Other: "删除服务账号 %s"}
+	OpRecordServiceAccountIssueToken                 = &i18n.Message{ID: "OpRecordServiceAccountIssueToken", Other: "服务账号获取访问 token"}
 	OpRecordMemberCreate                             = &i18n.Message{ID: "OpRecordMemberCreate", Other: "添加成员"}
 	OpRecordMemberCreateWithName                     = &i18n.Message{ID: "OpRecordMemberCreateWithName", Other: "添加成员 %s"}
 	OpRecordMemberUpdate                             = &i18n.Message{ID: "OpRecordMemberUpdate", Other: "更新成员 %s"}
@@ -294,6 +336,10 @@ var (
 	OpRecordMemberGroupCreateWithName                = &i18n.Message{ID: "OpRecordMemberGroupCreateWithName", Other: "添加成员组 %s"}
 	OpRecordMemberGroupUpdate                        = &i18n.Message{ID: "OpRecordMemberGroupUpdate", Other: "更新成员组 %s"}
 	OpRecordMemberGroupDelete                        = &i18n.Message{ID: "OpRecordMemberGroupDelete", Other: "删除成员组 %s"}
+	OpRecordMemberExpiredWithName                    = &i18n.Message{ID: "OpRecordMemberExpiredWithName", Other: "成员 %s 的授权已到期，自动移除"}
+	OpRecordMemberGroupExpiredWithName               = &i18n.Message{ID: "OpRecordMemberGroupExpiredWithName", Other: "成员组 %s 的授权已到期，自动移除"}
+	OpRecordAccessReviewMemberRevokedWithName        = &i18n.Message{ID: "OpRecordAccessReviewMemberRevokedWithName", Other: "权限复核移除成员 %s"}
+	OpRecordAccessReviewGroupUserRevokedWithName     = &i18n.Message{ID: "OpRecordAccessReviewGroupUserRevokedWithName", Other: "权限复核将用户 %s 移出成员组 %s"}
 	OpRecordRoleCreate                               = &i18n.Message{ID: "OpRecordRoleCreate", Other: "创建角色"}
 	OpRecordRoleCreateWithName                       = &i18n.Message{ID: "OpRecordRoleCreateWithName", Other: "创建角色 %s"}
 	OpRecordRoleUpdate                               = &i18n.Message{ID: "OpRecordRoleUpdate", Other: "更新角色 %s"}
@@ -34,6 +34,7 @@ import (
 	_const "github.com/actiontech/dms/pkg/dms-common/pkg/const"
 	pkgHttp "github.com/actiontech/dms/pkg/dms-common/pkg/http"
 	utilLog "github.com/actiontech/dms/pkg/dms-common/pkg/log"
+	sqlop "github.com/actiontech/dms/pkg/dms-common/sql_op"
 	pkgRand "github.com/actiontech/dms/pkg/rand"
 	"github.com/labstack/echo/v4"
 	"github.com/labstack/echo/v4/middleware"
@@ -776,6 +777,13 @@ func (sqlWorkbenchService *SqlWorkbenchService) filterDBServicesByPermissions(ct
 				dbServiceIdMap[rangeUid] = struct{}{}
 			}
 		}
+
+		// 库、模式、表级别的权限，执行时再由 AuditMiddleware 按会话所在的库判定
+		if opPermission.OpRangeType.IsDBObject() && opPermission.OpPermissionUID == pkgConst.UIDOfOpPermissionSQLQuery {
+			for _, dbServiceUid := range biz.ObjectRangeDBServiceUIDs(opPermission) {
+				dbServiceIdMap[dbServiceUid] = struct{}{}
+			}
+		}
 	}
 
 	var filteredDBServices []*biz.DBService
@@ -1438,21 +1446,23 @@ func (sqlWorkbenchService *SqlWorkbenchService) AuditMiddleware() echo.Middlewar
 				return errors.New(locale.Bundle.LocalizeMsgByCtx(c.Request().Context(), locale.SqlWorkbenchAuditGetDBServiceErr))
 			}
 
+			// 只拥有库、模式、表级别查询权限的用户，须在授权范围内的库中执行
+			allowed, err := sqlWorkbenchService.checkObjectRangeQueryPermission(c, dmsUserId, dbService, sidInfo)
+			if err != nil {
+				sqlWorkbenchService.log.Errorf("failed to check object range permission: %v", err)
+				return errors.New(locale.Bundle.LocalizeMsgByCtx(c.Request().Context(), locale.SqlWorkbenchAuditGetDBServiceErr))
+			}
+			if !allowed {
+				return errors.New(locale.Bundle.LocalizeMsgByCtx(c.Request().Context(), locale.SqlWorkbenchObjectRangeDeniedErr))
+			}
+
 			// 未开启 SQL 审核时直接放行，由 ODC 执行 SQL
 			if !sqlWorkbenchService.isEnableSQLAudit(dbService) {
 				sqlWorkbenchService.log.Debugf("SQL audit is not enabled for DBService: %s", dmsDBServiceID)
 				return next(c)
 			}
 
-			schemaName := sidInfo.schemaName
-			if schemaName == "" && sidInfo.dbID > 0 {
-				resolved, resolveErr := sqlWorkbenchService.getODCDatabaseName(c.Request().Context(), c, sidInfo.dbID)
-				if resolveErr != nil {
-					sqlWorkbenchService.log.Warnf("failed to resolve schema from ODC database id %d: %v", sidInfo.dbID, resolveErr)
-				} else {
-					schemaName = resolved
-				}
-			}
+			schemaName := sqlWorkbenchService.resolveStreamExecuteSchemaName(c, sidInfo)
 
 			// 调用 SQLE 审核接口
 			auditResult, err := sqlWorkbenchService.callSQLEAudit(c.Request().Context(), sql, dbService, schemaName)
@@ -1589,6 +1599,52 @@ func (sqlWorkbenchService *SqlWorkbenchService) fillStreamExecuteSidFromBase64(e
 	return nil
 }
 
+// resolveStreamExecuteSchemaName 返回会话所在的库名，sid 中未携带时通过 ODC 的 databaseId 查询
+func (sqlWorkbenchService *SqlWorkbenchService) resolveStreamExecuteSchemaName(c echo.Context, sidInfo *streamExecuteSidInfo) string {
+	schemaName := sidInfo.schemaName
+	if schemaName == "" && sidInfo.dbID > 0 {
+		resolved, err := sqlWorkbenchService.getODCDatabaseName(c.Request().Context(), c, sidInfo.dbID)
+		if err != nil {
+			sqlWorkbenchService.log.Warnf("failed to resolve schema from ODC database id %d: %v", sidInfo.dbID, err)
+		} else {
+			schemaName = resolved
+		}
+	}
+	return schemaName
+}
+
+// checkObjectRangeQueryPermission 判断只以库、模式、表级别范围拥有查询权限的用户能否在当前会话中执行 SQL。
+// DMS 不解析 SQL 涉及的表，只按会话所在的库（SQL Server 为库和模式）判定，因此仅授予表级别范围的用户无法在工作台中执行；
+// 数据源上没有对象范围权限的用户不在此处判定，仍按数据源列表的过滤结果执行
+func (sqlWorkbenchService *SqlWorkbenchService) checkObjectRangeQueryPermission(c echo.Context, userUid string, dbService *biz.DBService, sidInfo *streamExecuteSidInfo) (bool, error) {
+	ctx := c.Request().Context()
+	opPermissions, err := sqlWorkbenchService.opPermissionVerifyUsecase.GetUserOpPermissionInProject(ctx, userUid, dbService.ProjectUID)
+	if err != nil {
+		return false, err
+	}
+	need := []string{pkgConst.UIDOfOpPermissionSQLQuery}
+	if !sqlWorkbenchService.opPermissionVerifyUsecase.UserHasDBObjectRange(opPermissions, need, dbService.UID) {
+		return true, nil
+	}
+
+	objects := streamExecuteSessionObjects(dbService.DBType, sqlWorkbenchService.resolveStreamExecuteSchemaName(c, sidInfo))
+	allowed, _, err := sqlWorkbenchService.opPermissionVerifyUsecase.CheckUserCanOpDBObjects(ctx, userUid, dbService.ProjectUID, dbService.UID, need, objects)
+	return allowed, err
+}
+
+// streamExecuteSessionObjects 将 ODC 会话所在的库转换为权限判定使用的对象，SQL Server 的 database.schema 转换为模式对象
+func streamExecuteSessionObjects(dbType, schemaName string) []*sqlop.SQLObject {
+	if schemaName == "" {
+		return nil
+	}
+	if dbType == string(pkgConst.DBTypeSQLServer) {
+		if idx := strings.Index(schemaName, "."); idx > 0 {
+			return []*sqlop.SQLObject{{Type: sqlop.SQLObjectTypeSchema, DatabaseName: schemaName[:idx], SchemaName: schemaName[idx+1:]}}
+		}
+	}
+	return []*sqlop.SQLObject{{Type: sqlop.SQLObjectTypeDatabase, DatabaseName: schemaName}}
+}
+
 // getODCDatabaseName 通过 ODC MetaDB 中的 databaseId 查询库名，作为 SQLE 审核的 schema 上下文
 func (sqlWorkbenchService *SqlWorkbenchService) getODCDatabaseName(ctx context.Context, c echo.Context, dbID int) (string, error) {
 	if dbID <= 0 {
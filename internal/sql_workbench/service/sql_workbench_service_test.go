@@ -1,6 +1,7 @@
 package sql_workbench
 
 import (
+	"context"
 	"encoding/json"
 	"strings"
 	"testing"
@@ -10,6 +11,7 @@ import (
 	dbmodel "github.com/actiontech/dms/internal/dms/storage/model"
 	"github.com/actiontech/dms/internal/pkg/cloudbeaver"
 	"github.com/actiontech/dms/internal/sql_workbench/client"
+	sqlop "github.com/actiontech/dms/pkg/dms-common/sql_op"
 	pkgParams "github.com/actiontech/dms/pkg/params"
 )
 
@@ -662,3 +664,34 @@ func Test_normalizeSQLEAuditSchemaName(t *testing.T) {
 		})
 	}
 }
+
+func Test_streamExecuteSessionObjects(t *testing.T) {
+	if objects := streamExecuteSessionObjects(string(pkgConst.DBTypeMySQL), ""); objects != nil {
+		t.Fatalf("expected no object without schema, got %v", objects)
+	}
+	objects := streamExecuteSessionObjects(string(pkgConst.DBTypeMySQL), "sales")
+	if len(objects) != 1 || objects[0].Type != sqlop.SQLObjectTypeDatabase || objects[0].DatabaseName != "sales" {
+		t.Fatalf("unexpected MySQL session objects: %+v", objects[0])
+	}
+	objects = streamExecuteSessionObjects(string(pkgConst.DBTypeSQLServer), "TestDB.dbo")
+	if len(objects) != 1 || objects[0].Type != sqlop.SQLObjectTypeSchema || objects[0].DatabaseName != "TestDB" || objects[0].SchemaName != "dbo" {
+		t.Fatalf("unexpected SQL Server session objects: %+v", objects[0])
+	}
+}
+
+func Test_filterDBServicesByPermissions_objectRange(t *testing.T) {
+	s := &SqlWorkbenchService{}
+	dbServices := []*biz.DBService{{UID: "db_1", ProjectUID: "project_1"}, {UID: "db_2", ProjectUID: "project_1"}}
+	dbRange := sqlop.EncodeObjectRange(&sqlop.ObjectRange{DBServiceUID: "db_1", DatabaseName: "report"})
+	opPermissions := []biz.OpPermissionWithOpRange{
+		{OpPermissionUID: pkgConst.UIDOfOpPermissionSQLQuery, OpRangeType: biz.OpRangeTypeDatabase, RangeUIDs: []string{dbRange}},
+		{OpPermissionUID: pkgConst.UIDOfOpPermissionExportCreate, OpRangeType: biz.OpRangeTypeDBService, RangeUIDs: []string{"db_2"}},
+	}
+	filtered, err := s.filterDBServicesByPermissions(context.Background(), dbServices, opPermissions)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(filtered) != 1 || filtered[0].UID != "db_1" {
+		t.Fatalf("expected only db_1, got %v", filtered)
+	}
+}
@@ -10,16 +10,24 @@ import (
 	"github.com/labstack/echo/v4"
 )
 
-const AccessTokenLogin = "access_token_login"
+const (
+	AccessTokenLogin    = "access_token_login"
+	ServiceAccountLogin = "service_account_login"
+)
 
+// Deprecated: 每个用户支持多个 access token 后不再只校验最新的 token，请使用 CheckAccessToken
 func CheckLatestAccessToken(dmsAddress string, getTokenDetail func(c jwtPkg.EchoContextGetter) (*jwtPkg.TokenDetail, error)) echo.MiddlewareFunc {
+	return CheckAccessToken(dmsAddress, getTokenDetail)
+}
+
+// CheckAccessToken 通过 DMS 校验 access token 或服务账号 token 是否有效，并对只读及项目受限的 token 做访问限制，
+// 项目通过路由参数 project_name 或 project_uid 识别，项目受限的 token 不能访问路由中没有项目参数的接口
+func CheckAccessToken(dmsAddress string, getTokenDetail func(c jwtPkg.EchoContextGetter) (*jwtPkg.TokenDetail, error)) echo.MiddlewareFunc {
 	return func(next echo.HandlerFunc) echo.HandlerFunc {
 		return func(c echo.Context) error {
 			tokenDetail, err := getTokenDetail(c)
-
 			if err != nil {
-				echo.NewHTTPError(http.StatusUnauthorized, fmt.Sprintf("get token detail failed, err:%v", err))
-				return err
+				return echo.NewHTTPError(http.StatusUnauthorized, fmt.Sprintf("get token detail failed, err:%v", err))
 			}
 
 			if tokenDetail.TokenStr == "" {
@@ -31,22 +39,53 @@ func CheckLatestAccessToken(dmsAddress string, getTokenDetail func(c jwtPkg.Echo
 				return next(c)
 			}
 
-			if tokenDetail.LoginType != AccessTokenLogin {
+			if tokenDetail.LoginType != AccessTokenLogin && tokenDetail.LoginType != ServiceAccountLogin {
 				return echo.NewHTTPError(http.StatusUnauthorized, "access token login type is error")
 			}
 
-			userInfo, err := dmsobject.GetUser(context.TODO(), tokenDetail.UID, dmsAddress)
+			reply, err := dmsobject.VerifyAccessToken(context.TODO(), tokenDetail.TokenStr, dmsAddress)
 			if err != nil {
 				return err
 			}
-			if userInfo == nil {
-				return echo.NewHTTPError(http.StatusNotFound, "access token: cannot get user info")
+			if reply.Data.VerifyFailedMsg != "" {
+				return echo.NewHTTPError(http.StatusUnauthorized, reply.Data.VerifyFailedMsg)
 			}
 
-			if userInfo.AccessTokenInfo.AccessToken != tokenDetail.TokenStr {
-				return echo.NewHTTPError(http.StatusUnauthorized, "access token is not latest")
+			if reply.Data.ReadOnly && !isReadOnlyMethod(c.Request().Method) {
+				return echo.NewHTTPError(http.StatusForbidden, "access token is read-only")
+			}
+			if len(reply.Data.ProjectUids) > 0 {
+				projectName, projectUid := c.Param("project_name"), c.Param("project_uid")
+				// 路由中无法识别项目时无法确认访问范围，项目受限的 token 不允许访问
+				if projectName == "" && projectUid == "" {
+					return echo.NewHTTPError(http.StatusForbidden, "access token is restricted to projects and is not allowed to access this api")
+				}
+				if projectName != "" && !contains(reply.Data.ProjectNames, projectName) {
+					return echo.NewHTTPError(http.StatusForbidden, "access token is not allowed to access this project")
+				}
+				if projectUid != "" && !contains(reply.Data.ProjectUids, projectUid) {
+					return echo.NewHTTPError(http.StatusForbidden, "access token is not allowed to access this project")
+				}
 			}
 			return next(c)
 		}
 	}
 }
+
+func isReadOnlyMethod(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions:
+		return true
+	default:
+		return false
+	}
+}
+
+func contains(items []string, target string) bool {
+	for _, item := range items {
+		if item == target {
+			return true
+		}
+	}
+	return false
+}
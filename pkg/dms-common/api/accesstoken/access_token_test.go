@@ -0,0 +1,51 @@
+package accesstoken
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	dmsV1 "github.com/actiontech/dms/pkg/dms-common/api/dms/v1"
+	jwtPkg "github.com/actiontech/dms/pkg/dms-common/api/jwt"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCheckAccessTokenProjectRestricted(t *testing.T) {
+	dms := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		reply := &dmsV1.VerifyAccessTokenReply{}
+		reply.Data.UserUid = "user_1"
+		reply.Data.ProjectUids = []string{"p1"}
+		reply.Data.ProjectNames = []string{"project_1"}
+		_ = json.NewEncoder(w).Encode(reply)
+	}))
+	defer dms.Close()
+
+	getTokenDetail := func(jwtPkg.EchoContextGetter) (*jwtPkg.TokenDetail, error) {
+		return &jwtPkg.TokenDetail{TokenStr: "token", UID: "user_1", LoginType: AccessTokenLogin}, nil
+	}
+	handler := CheckAccessToken(dms.URL, getTokenDetail)(func(c echo.Context) error {
+		return c.NoContent(http.StatusOK)
+	})
+
+	check := func(paramName, paramValue string) error {
+		c := echo.New().NewContext(httptest.NewRequest(http.MethodGet, "/", nil), httptest.NewRecorder())
+		if paramName != "" {
+			c.SetParamNames(paramName)
+			c.SetParamValues(paramValue)
+		}
+		return handler(c)
+	}
+	forbidden := func(err error) bool {
+		httpErr, ok := err.(*echo.HTTPError)
+		return ok && httpErr.Code == http.StatusForbidden
+	}
+
+	assert.NoError(t, check("project_uid", "p1"))
+	assert.NoError(t, check("project_name", "project_1"))
+	assert.True(t, forbidden(check("project_uid", "p2")))
+	assert.True(t, forbidden(check("project_name", "project_2")))
+	// 路由中没有项目参数时无法确认访问范围
+	assert.True(t, forbidden(check("", "")))
+}
@@ -0,0 +1,28 @@
+package v1
+
+// OpPermissionManifest is the op permissions defined by a registered service
+type OpPermissionManifest struct {
+	// manifest version, the op permissions are synced only when the version changes
+	// Required: true
+	Version string `json:"version" validate:"required"`
+	// op permissions provided by the service, permissions missing from a newer version are removed
+	OpPermissions []*ServiceOpPermission `json:"op_permissions" validate:"dive,required"`
+}
+
+type ServiceOpPermission struct {
+	// op permission uid, must not conflict with built-in op permissions or those of other services
+	// Required: true
+	Uid string `json:"uid" validate:"required,max=32"`
+	// op permission name by language, eg: {"zh": "执行作业", "en": "Execute job"}, zh is required
+	// Required: true
+	Name map[string]string `json:"name" validate:"required"`
+	// op permission description by language
+	Desc map[string]string `json:"desc"`
+	// module the op permission belongs to
+	Module string `json:"module"`
+	// range type of the op permission, global op permissions can only be defined by dms
+	// Required: true
+	RangeType string `json:"range_type" validate:"required,oneof=project db_service"`
+	// built-in roles that are granted the op permission, eg: 700403 (dev engineer), 700404 (dev manager), 700405 (ops engineer)
+	BuiltinRoleUids []string `json:"builtin_role_uids"`
+}
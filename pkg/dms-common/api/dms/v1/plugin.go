@@ -39,6 +39,8 @@ type Plugin struct {
 	OperateDataResourceHandleUrl string `json:"operate_data_resource_handle_url"`
 	GetDatabaseDriverOptionsUrl  string `json:"get_database_driver_options_url"`
 	GetDatabaseDriverLogosUrl    string `json:"get_database_driver_logos_url"`
+	// op permissions defined by the plugin
+	OpPermissionManifest *OpPermissionManifest `json:"op_permission_manifest,omitempty"`
 }
 
 // swagger:model
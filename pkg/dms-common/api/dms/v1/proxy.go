@@ -27,6 +27,8 @@ type DMSProxyTarget struct {
 	ProxyUrlPrefixs []string `json:"proxy_url_prefixs" validate:"required"`
 	// the scenario is used to differentiate scenarios
 	Scenario ProxyScenario `json:"scenario"`
+	// op permissions defined by the target service
+	OpPermissionManifest *OpPermissionManifest `json:"op_permission_manifest,omitempty"`
 }
 
 func (s *DMSProxyTarget) String() string {
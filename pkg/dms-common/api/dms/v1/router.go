@@ -27,6 +27,7 @@ var (
 	MemberForInternalRouterSuffix = "/internal"
 	InternalDBServiceRouterGroup  = "/internal/db_services"
 	LicenseRouterGroup            = "/dms/license"
+	ServiceAccountRouterGroup     = "/dms/service_accounts"
 )
 
 // api group
@@ -50,6 +51,15 @@ func GetUserOpPermissionRouterWithoutPrefix(userUid string) string {
 	return strings.TrimPrefix(strings.TrimPrefix(router, CurrentGroupVersion), UserRouterGroup)
 }
 
+func CheckUserDBObjectsOpPermissionRouter(userUid string) string {
+	return fmt.Sprintf("%s%s/%s/db_objects_op_permission/check", CurrentGroupVersion, UserRouterGroup, userUid)
+}
+
+func CheckUserDBObjectsOpPermissionRouterWithoutPrefix(userUid string) string {
+	router := CheckUserDBObjectsOpPermissionRouter(userUid)
+	return strings.TrimPrefix(strings.TrimPrefix(router, CurrentGroupVersion), UserRouterGroup)
+}
+
 func GetDBServiceRouter(projectUid string) string {
 	return fmt.Sprintf("%s%s", CurrentGroupVersion, strings.Replace(DBServiceRouterGroup, ":project_uid", projectUid, 1))
 }
@@ -62,6 +72,14 @@ func GetMemberGroupRouter(memberGroupUid, projectUid string) string {
 	return fmt.Sprintf("%s%s/%s", CurrentGroupVersion, strings.Replace(MemberGroupRouterGroup, ":project_uid", projectUid, 1), memberGroupUid)
 }
 
+func GetVerifyAccessTokenRouter() string {
+	return fmt.Sprintf("%s%s/verify_access_token", CurrentGroupVersion, UserRouterGroup)
+}
+
+func GetServiceAccountTokenRouter() string {
+	return fmt.Sprintf("%s%s/token", CurrentGroupVersion, ServiceAccountRouterGroup)
+}
+
 func GetUsersRouter() string {
 	return fmt.Sprintf("%s%s", CurrentGroupVersion, UserRouterGroup)
 }
@@ -0,0 +1,44 @@
+package v1
+
+import (
+	"fmt"
+
+	base "github.com/actiontech/dms/pkg/dms-common/api/base/v1"
+)
+
+// swagger:model
+type IssueServiceAccountTokenReq struct {
+	// service account client id
+	// Required: true
+	ClientID string `json:"client_id" validate:"required"`
+	// service account client secret
+	// Required: true
+	ClientSecret string `json:"client_secret" validate:"required"`
+}
+
+func (r *IssueServiceAccountTokenReq) String() string {
+	if r == nil {
+		return "IssueServiceAccountTokenReq{nil}"
+	}
+	return fmt.Sprintf("IssueServiceAccountTokenReq{ClientID:%s}", r.ClientID)
+}
+
+// swagger:model IssueServiceAccountTokenReply
+type IssueServiceAccountTokenReply struct {
+	Data struct {
+		// short-lived token, use it as "Bearer <token>" in the Authorization header
+		Token string `json:"token"`
+		// token lifetime in seconds
+		ExpiresIn int64 `json:"expires_in"`
+	} `json:"data"`
+
+	// Generic reply
+	base.GenericResp
+}
+
+func (r *IssueServiceAccountTokenReply) String() string {
+	if r == nil {
+		return "IssueServiceAccountTokenReply{nil}"
+	}
+	return fmt.Sprintf("IssueServiceAccountTokenReply{ExpiresIn:%d}", r.Data.ExpiresIn)
+}
@@ -4,6 +4,7 @@ import (
 	"fmt"
 
 	base "github.com/actiontech/dms/pkg/dms-common/api/base/v1"
+	sqlop "github.com/actiontech/dms/pkg/dms-common/sql_op"
 )
 
 // swagger:parameters GetUser
@@ -54,6 +55,10 @@ type AccessTokenInfo struct {
 	AccessToken string `json:"access_token"`
 	ExpiredTime string `json:"token_expired_timestamp" example:"RFC3339"`
 	IsExpired   bool   `json:"is_expired"`
+	// access token uid
+	AccessTokenUid string `json:"access_token_uid"`
+	// access token name
+	Name string `json:"name"`
 }
 
 type UserBindProject struct {
@@ -83,10 +88,11 @@ type UidWithName struct {
 type UserAuthenticationType string
 
 const (
-	UserAuthenticationTypeLDAP    UserAuthenticationType = "ldap"   // user verify through ldap
-	UserAuthenticationTypeDMS     UserAuthenticationType = "dms"    // user verify through dms
-	UserAuthenticationTypeOAUTH2  UserAuthenticationType = "oauth2" // user verify through oauth2
-	UserAuthenticationTypeUnknown UserAuthenticationType = "unknown"
+	UserAuthenticationTypeLDAP           UserAuthenticationType = "ldap"            // user verify through ldap
+	UserAuthenticationTypeDMS            UserAuthenticationType = "dms"             // user verify through dms
+	UserAuthenticationTypeOAUTH2         UserAuthenticationType = "oauth2"          // user verify through oauth2
+	UserAuthenticationTypeServiceAccount UserAuthenticationType = "service_account" // service account, can not login
+	UserAuthenticationTypeUnknown        UserAuthenticationType = "unknown"
 )
 
 // swagger:enum UserSystem
@@ -148,6 +154,39 @@ type GetUserOpPermissionReply struct {
 	base.GenericResp
 }
 
+// swagger:parameters CheckUserDBObjectsOpPermission
+type CheckUserDBObjectsOpPermissionReq struct {
+	// user uid
+	// in:path
+	UserUid string `param:"user_uid" json:"user_uid" validate:"required"`
+	// objects to check
+	// in:body
+	DBObjectsOpPermission *DBObjectsOpPermission `json:"db_objects_op_permission" validate:"required"`
+}
+
+type DBObjectsOpPermission struct {
+	// project uid
+	ProjectUid string `json:"project_uid" validate:"required"`
+	// db service uid
+	DBServiceUid string `json:"db_service_uid" validate:"required"`
+	// op permission type
+	OpPermissionType OpPermissionType `json:"op_permission_type" validate:"required"`
+	// objects accessed by the sql, parsed as sqlop.SQLObjectOps
+	Objects []*sqlop.SQLObject `json:"objects"`
+}
+
+// swagger:model CheckUserDBObjectsOpPermissionReply
+type CheckUserDBObjectsOpPermissionReply struct {
+	Data struct {
+		// whether the user can op all the objects
+		Allowed bool `json:"allowed"`
+		// objects not in the op permission range of the user
+		DeniedObjects []*sqlop.SQLObject `json:"denied_objects"`
+	} `json:"data"`
+	// Generic reply
+	base.GenericResp
+}
+
 type OpPermissionItem struct {
 	// object uids, object type is defined by RangeType
 	RangeUids []string `json:"range_uids"`
@@ -168,12 +207,28 @@ const (
 	OpRangeTypeProject OpRangeType = "project"
 	// 项目内的数据源权限: 该权限只能被成员使用
 	OpRangeTypeDBService OpRangeType = "db_service"
+	// 数据源内的库权限: 将数据源权限收窄到指定库，范围格式见 sqlop.EncodeObjectRange
+	OpRangeTypeDatabase OpRangeType = "database"
+	// 数据源内的模式权限: 将数据源权限收窄到指定模式
+	OpRangeTypeSchema OpRangeType = "schema"
+	// 数据源内的表权限: 将数据源权限收窄到指定表
+	OpRangeTypeTable OpRangeType = "table"
+	// 数据源选择器: 范围为 environment_tag=xxx 或 business_tag=xxx，校验时解析为项目内匹配的数据源
+	OpRangeTypeDBServiceSelector OpRangeType = "db_service_selector"
 )
 
 func ParseOpRangeType(typ string) (OpRangeType, error) {
 	switch typ {
 	case string(OpRangeTypeDBService):
 		return OpRangeTypeDBService, nil
+	case string(OpRangeTypeDatabase):
+		return OpRangeTypeDatabase, nil
+	case string(OpRangeTypeSchema):
+		return OpRangeTypeSchema, nil
+	case string(OpRangeTypeTable):
+		return OpRangeTypeTable, nil
+	case string(OpRangeTypeDBServiceSelector):
+		return OpRangeTypeDBServiceSelector, nil
 	case string(OpRangeTypeProject):
 		return OpRangeTypeProject, nil
 	case string(OpRangeTypeGlobal):
@@ -479,6 +534,12 @@ type ListUserReply struct {
 // swagger:model
 type GenAccessToken struct {
 	ExpirationDays string `param:"expiration_days" json:"expiration_days" validate:"required"`
+	// access token name, used to distinguish tokens of different usages
+	Name string `json:"name" validate:"max=200"`
+	// read-only access token can only be used for query requests
+	ReadOnly bool `json:"read_only"`
+	// restrict the access token to these projects, empty means no restriction
+	ProjectUids []string `json:"project_uids"`
 }
 
 // swagger:model GenAccessTokenReply
@@ -489,3 +550,28 @@ type GenAccessTokenReply struct {
 	// Generic reply
 	base.GenericResp
 }
+
+// swagger:model
+type VerifyAccessTokenReq struct {
+	// access token
+	Token string `json:"token" validate:"required"`
+}
+
+// swagger:model VerifyAccessTokenReply
+type VerifyAccessTokenReply struct {
+	Data struct {
+		// If verify Successful, return empty string, otherwise return error message
+		VerifyFailedMsg string `json:"verify_failed_msg"`
+		// If verify Successful, return user uid
+		UserUid string `json:"user_uid"`
+		// read-only access token can only be used for query requests
+		ReadOnly bool `json:"read_only"`
+		// the projects the access token is restricted to, empty means no restriction
+		ProjectUids []string `json:"project_uids"`
+		// names of the restricted projects, for services that locate projects by name
+		ProjectNames []string `json:"project_names"`
+	} `json:"data"`
+
+	// Generic reply
+	base.GenericResp
+}
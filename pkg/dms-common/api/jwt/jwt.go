@@ -28,6 +28,7 @@ const (
 	JWTLoginType       = "loginType"
 	JWTType            = "typ"
 	JWTLoginSessionID  = "jti"
+	JWTAccessTokenID   = "atid"
 
 	DefaultDmsTokenExpHours        = 2
 	DefaultDmsRefreshTokenExpHours = 24
@@ -159,6 +160,12 @@ func WithAccessTokenMark(loginType string) CustomClaimFunc {
 	}
 }
 
+func WithAccessTokenID(id string) CustomClaimFunc {
+	return func(claims jwt.MapClaims) {
+		claims[JWTAccessTokenID] = id
+	}
+}
+
 func WithSub(sub string) CustomClaimFunc {
 	return func(claims jwt.MapClaims) {
 		claims["sub"] = sub
@@ -341,6 +348,7 @@ type TokenDetail struct {
 	UID             string
 	LoginType       string
 	LoginSessionID  string
+	AccessTokenID   string
 }
 
 // 由于sqle使用的github.com/golang-jwt/jwt，本方法为sqle兼容
@@ -382,6 +390,9 @@ func GetTokenDetailFromContextWithOldJwt(c EchoContextGetter) (tokenDetail *Toke
 	if loginSessionID, ok := claims[JWTLoginSessionID]; ok {
 		tokenDetail.LoginSessionID = fmt.Sprint(loginSessionID)
 	}
+	if accessTokenID, ok := claims[JWTAccessTokenID]; ok {
+		tokenDetail.AccessTokenID = fmt.Sprint(accessTokenID)
+	}
 	return tokenDetail, nil
 }
 
@@ -396,6 +407,20 @@ func GetTokenDetailFromContext(c EchoContextGetter) (tokenDetail *TokenDetail, e
 	if !ok {
 		return nil, fmt.Errorf("failed to convert user from jwt token")
 	}
+	return parseTokenDetail(u)
+}
+
+// ParseTokenDetailFromJwtTokenStr 从 token 字符串中解析 token 信息，会校验签名及有效期
+func ParseTokenDetailFromJwtTokenStr(tokenStr string) (*TokenDetail, error) {
+	token, err := parseJwtTokenStr(tokenStr)
+	if err != nil {
+		return nil, err
+	}
+	return parseTokenDetail(token)
+}
+
+func parseTokenDetail(u *jwt.Token) (tokenDetail *TokenDetail, err error) {
+	tokenDetail = &TokenDetail{}
 	tokenDetail.TokenStr = u.Raw
 
 	// get uid from token
@@ -422,5 +447,8 @@ func GetTokenDetailFromContext(c EchoContextGetter) (tokenDetail *TokenDetail, e
 	if loginSessionID, ok := claims[JWTLoginSessionID]; ok {
 		tokenDetail.LoginSessionID = fmt.Sprint(loginSessionID)
 	}
+	if accessTokenID, ok := claims[JWTAccessTokenID]; ok {
+		tokenDetail.AccessTokenID = fmt.Sprint(accessTokenID)
+	}
 	return tokenDetail, nil
 }
@@ -8,6 +8,7 @@ import (
 
 	dmsV1 "github.com/actiontech/dms/pkg/dms-common/api/dms/v1"
 	pkgHttp "github.com/actiontech/dms/pkg/dms-common/pkg/http"
+	sqlop "github.com/actiontech/dms/pkg/dms-common/sql_op"
 )
 
 func GetUser(ctx context.Context, userUid string, dmsAddr string) (*dmsV1.GetUser, error) {
@@ -75,6 +76,32 @@ func GetUserOpPermission(ctx context.Context, projectUid, userUid, dmsAddr strin
 }
 
 // GetUserOpPermissionWithBWP is like GetUserOpPermission but also returns the BusinessWritePermission field.
+// CheckUserDBObjectsOpPermission 校验用户能否对数据源上的对象执行操作，返回不在用户权限范围内的对象
+func CheckUserDBObjectsOpPermission(ctx context.Context, userUid, dmsAddr string, check *dmsV1.DBObjectsOpPermission) (allowed bool, deniedObjects []*sqlop.SQLObject, err error) {
+	header := map[string]string{
+		"Authorization": pkgHttp.DefaultDMSToken,
+	}
+
+	reqBody := struct {
+		DBObjectsOpPermission *dmsV1.DBObjectsOpPermission `json:"db_objects_op_permission"`
+	}{
+		DBObjectsOpPermission: check,
+	}
+
+	reply := &dmsV1.CheckUserDBObjectsOpPermissionReply{}
+
+	url := fmt.Sprintf("%v%v", dmsAddr, dmsV1.CheckUserDBObjectsOpPermissionRouter(userUid))
+
+	if err := pkgHttp.POST(ctx, url, header, reqBody, reply); err != nil {
+		return false, nil, fmt.Errorf("failed to check user db objects op permission from %v: %v", url, err)
+	}
+	if reply.Code != 0 {
+		return false, nil, fmt.Errorf("http reply code(%v) error: %v", reply.Code, reply.Message)
+	}
+
+	return reply.Data.Allowed, reply.Data.DeniedObjects, nil
+}
+
 func GetUserOpPermissionWithBWP(ctx context.Context, projectUid, userUid, dmsAddr string) (ret []dmsV1.OpPermissionItem, isAdmin bool, businessWritePermission bool, err error) {
 	header := map[string]string{
 		"Authorization": pkgHttp.DefaultDMSToken,
@@ -175,3 +202,22 @@ func ListUsers(ctx context.Context, dmsAddr string, req dmsV1.ListUserReq) (ret
 	return reply.Data, reply.Total, nil
 
 }
+
+func VerifyAccessToken(ctx context.Context, token string, dmsAddr string) (*dmsV1.VerifyAccessTokenReply, error) {
+	header := map[string]string{
+		"Authorization": pkgHttp.DefaultDMSToken,
+	}
+
+	reply := &dmsV1.VerifyAccessTokenReply{}
+
+	url := fmt.Sprintf("%v%v", dmsAddr, dmsV1.GetVerifyAccessTokenRouter())
+
+	if err := pkgHttp.POST(ctx, url, header, &dmsV1.VerifyAccessTokenReq{Token: token}, reply); err != nil {
+		return nil, fmt.Errorf("failed to verify access token from %v: %v", url, err)
+	}
+	if reply.Code != 0 {
+		return nil, fmt.Errorf("http reply code(%v) error: %v", reply.Code, reply.Message)
+	}
+
+	return reply, nil
+}
@@ -0,0 +1,69 @@
+package register
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	dmsV1 "github.com/actiontech/dms/pkg/dms-common/api/dms/v1"
+	pkgHttp "github.com/actiontech/dms/pkg/dms-common/pkg/http"
+)
+
+// GetServiceAccountToken 使用服务账号的 client id 和 client secret 向 DMS 换取短期 token，
+// 返回的 token 可直接作为 Authorization 请求头的值
+func GetServiceAccountToken(ctx context.Context, dmsAddr, clientID, clientSecret string) (token string, expiredTime time.Time, err error) {
+	reqBody := &dmsV1.IssueServiceAccountTokenReq{
+		ClientID:     clientID,
+		ClientSecret: clientSecret,
+	}
+	reply := &dmsV1.IssueServiceAccountTokenReply{}
+
+	dmsUrl := fmt.Sprintf("%s%s", dmsAddr, dmsV1.GetServiceAccountTokenRouter())
+
+	if err := pkgHttp.POST(ctx, dmsUrl, nil, reqBody, reply); err != nil {
+		return "", time.Time{}, fmt.Errorf("failed to get service account token from %v: %v", dmsUrl, err)
+	}
+	if reply.Code != 0 {
+		return "", time.Time{}, fmt.Errorf("http reply code(%v) error: %v", reply.Code, reply.Message)
+	}
+
+	return fmt.Sprintf("Bearer %s", reply.Data.Token), time.Now().Add(time.Duration(reply.Data.ExpiresIn) * time.Second), nil
+}
+
+// serviceAccountTokenRefreshAhead token 过期前提前刷新，避免请求途中过期
+const serviceAccountTokenRefreshAhead = 5 * time.Minute
+
+// ServiceAccountTokenSource 缓存服务账号 token，并在即将过期时自动重新获取，可并发使用
+type ServiceAccountTokenSource struct {
+	dmsAddr      string
+	clientID     string
+	clientSecret string
+
+	mu          sync.Mutex
+	token       string
+	expiredTime time.Time
+}
+
+func NewServiceAccountTokenSource(dmsAddr, clientID, clientSecret string) *ServiceAccountTokenSource {
+	return &ServiceAccountTokenSource{
+		dmsAddr:      dmsAddr,
+		clientID:     clientID,
+		clientSecret: clientSecret,
+	}
+}
+
+func (s *ServiceAccountTokenSource) Token(ctx context.Context) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.token != "" && time.Now().Add(serviceAccountTokenRefreshAhead).Before(s.expiredTime) {
+		return s.token, nil
+	}
+	token, expiredTime, err := GetServiceAccountToken(ctx, s.dmsAddr, s.clientID, s.clientSecret)
+	if err != nil {
+		return "", err
+	}
+	s.token, s.expiredTime = token, expiredTime
+	return s.token, nil
+}
@@ -0,0 +1,90 @@
+package sqlop
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// objectRangeSeparator 用于分隔对象范围编码中的各级名称，各级名称本身会被转义，
+// 转义后不包含该分隔符及权限范围列表使用的逗号
+const objectRangeSeparator = "/"
+
+// ObjectRange 描述数据源内库、模式或表级别的权限范围，
+// 编码后存储在成员角色的 RangeUIDs 中，并通过 GetUserOpPermission 透出给各业务服务
+type ObjectRange struct {
+	DBServiceUID string
+	// DatabaseName 为空表示不限定database
+	DatabaseName string
+	// SchemaName 为空表示不限定schema，对于MySQL等不区分schema的数据库类型应为空
+	SchemaName string
+	// TableName 为空表示不限定表
+	TableName string
+}
+
+// EncodeObjectRange 将对象范围编码为 RangeUID，格式为 dbServiceUid/database/schema/table
+func EncodeObjectRange(r *ObjectRange) string {
+	return strings.Join([]string{
+		url.QueryEscape(r.DBServiceUID),
+		url.QueryEscape(r.DatabaseName),
+		url.QueryEscape(r.SchemaName),
+		url.QueryEscape(r.TableName),
+	}, objectRangeSeparator)
+}
+
+// DecodeObjectRange 解析由 EncodeObjectRange 编码的 RangeUID
+func DecodeObjectRange(s string) (*ObjectRange, error) {
+	parts := strings.Split(s, objectRangeSeparator)
+	if len(parts) != 4 {
+		return nil, fmt.Errorf("invalid object range: %s", s)
+	}
+	for i, part := range parts {
+		unescaped, err := url.QueryUnescape(part)
+		if err != nil {
+			return nil, fmt.Errorf("invalid object range %s: %v", s, err)
+		}
+		parts[i] = unescaped
+	}
+	if parts[0] == "" {
+		return nil, fmt.Errorf("invalid object range %s: db service uid is empty", s)
+	}
+	return &ObjectRange{
+		DBServiceUID: parts[0],
+		DatabaseName: parts[1],
+		SchemaName:   parts[2],
+		TableName:    parts[3],
+	}, nil
+}
+
+// Contains 判断 dbServiceUid 上的对象 o 是否在权限范围内。
+// 范围中非空的各级名称都需要与对象一致，且对象不能比范围更宽：
+// 例如表级范围不覆盖其所在的database，也不覆盖无法解析出所属database的对象。
+func (r *ObjectRange) Contains(dbServiceUid string, o *SQLObject) bool {
+	if o == nil || r.DBServiceUID != dbServiceUid {
+		return false
+	}
+	switch o.Type {
+	case SQLObjectTypeTable:
+	case SQLObjectTypeSchema:
+		if r.TableName != "" {
+			return false
+		}
+	case SQLObjectTypeDatabase:
+		if r.TableName != "" || r.SchemaName != "" {
+			return false
+		}
+	default:
+		// 实例及服务器级别的对象只能由数据源级别的权限覆盖
+		return false
+	}
+	if r.DatabaseName != "" && r.DatabaseName != o.DatabaseName {
+		return false
+	}
+	if r.SchemaName != "" && r.SchemaName != o.SchemaName {
+		return false
+	}
+	if r.TableName != "" && r.TableName != o.TableName {
+		return false
+	}
+	return true
+}